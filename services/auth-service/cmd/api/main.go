@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,38 +14,56 @@ import (
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/config"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/db"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/handlers"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/middleware"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/lifecycle"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
-	log.Println("Configuration loaded successfully.")
+	appLogger := logger.Init(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	appLogger.Info("configuration loaded")
 
 	// Connect to DB
 	database, err := db.NewConnection(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to the database: %v", err)
+		fatal(appLogger, "failed to connect to the database", err)
 	}
 	defer database.Close()
-	log.Println("✅ Database connection established successfully.")
+	appLogger.Info("database connection established")
 
 	// Run migrations
 	migrationsPath := "migrations"
 	if err := db.RunMigrations(database, migrationsPath); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+		fatal(appLogger, "failed to run migrations", err)
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(database)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(database)
 	roleRepo := repository.NewRoleRepository(database)
+	passwordResetRepo := repository.NewPasswordResetRepository(database)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(database)
 
 	// Initialize services
-	jwtService := services.NewJWTService(cfg.JWTSecret, cfg.JWTRefreshSecret)
-	authService := services.NewAuthService(userRepo, refreshTokenRepo, roleRepo, jwtService)
-	userService := services.NewUserService(userRepo, authService)
+	if cfg.BcryptCost < services.MinBcryptCost {
+		appLogger.Warn("bcrypt cost below minimum, using minimum", "configured", cfg.BcryptCost, "minimum", services.MinBcryptCost)
+	}
+	jwtService := services.NewJWTServiceWithKeys(
+		services.KeySet{CurrentID: cfg.JWTKeyID, CurrentSecret: cfg.JWTSecret, Previous: verificationKeys(cfg.JWTPreviousKeys),
+			TTL: cfg.JWTAccessTokenTTL},
+		services.KeySet{CurrentID: cfg.JWTRefreshKeyID, CurrentSecret: cfg.JWTRefreshSecret, Previous: verificationKeys(cfg.JWTPreviousRefreshKeys),
+			TTL: cfg.JWTRefreshTokenTTL},
+	)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, roleRepo, passwordResetRepo, emailVerificationRepo, jwtService,
+		services.NewLogAuthMailer(cfg.Environment == "development"),
+		services.AuthPolicy{BcryptCost: cfg.BcryptCost, RequireEmailVerification: cfg.RequireEmailVerification},
+	)
+	userService := services.NewUserService(userRepo, authService, cfg.BcryptCost)
 	roleService := services.NewRoleService(roleRepo, userRepo)
 
 	// Initialize handlers
@@ -53,7 +71,18 @@ func main() {
 	roleHandler := handlers.NewRoleHandler(roleService)
 
 	// Initialize router
-	appRouter := router.NewRouter(authHandler, authService, roleHandler)
+	readiness := lifecycle.NewReadiness()
+	readiness.AddCheck("database", database.PingContext)
+	migrationsCheck, err := db.MigrationsCheck(database, migrationsPath)
+	if err != nil {
+		fatal(appLogger, "failed to set up migrations check", err)
+	}
+	readiness.AddCheck("migrations", migrationsCheck)
+	rateLimiter := middleware.NewMemoryRateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	body := httpx.BodyPolicy{MaxBytes: cfg.MaxBodyBytes, DisallowUnknownFields: cfg.StrictJSON}
+	clientIP := middleware.ClientIP(cfg.TrustedProxyHeader)
+	failedLogins := middleware.NewFailedLogins(cfg.RateLimitWindow)
+	appRouter := router.NewRouter(authHandler, authService, jwtService.GetAccessTokenExpiry(), roleHandler, readiness, rateLimiter, clientIP, failedLogins, body)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -64,11 +93,14 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// Coordinates readiness, background workers and the server during shutdown
+	coordinator := lifecycle.NewCoordinator(server, readiness, 5*time.Second)
+
 	// Start server in a goroutine
 	go func() {
-		log.Printf("🚀 Auth Service Running on port: %s", cfg.Port)
+		appLogger.Info("auth service running", "port", cfg.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal(appLogger, "failed to start server", err)
 		}
 	}()
 
@@ -76,15 +108,30 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	appLogger.Info("shutting down server")
 
-	// Give outstanding requests 30 seconds to complete
+	// Flip readiness, stop background workers, then give outstanding requests 30 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := coordinator.Shutdown(ctx); err != nil {
+		fatal(appLogger, "server forced to shutdown", err)
 	}
 
-	log.Println("Server exited")
+	appLogger.Info("server exited")
+}
+
+// verificationKeys converts configured retired JWT keys for the JWT service
+func verificationKeys(keys []config.JWTKey) []services.VerificationKey {
+	converted := make([]services.VerificationKey, len(keys))
+	for i, key := range keys {
+		converted[i] = services.VerificationKey{ID: key.ID, Secret: key.Secret, RetiredAt: key.RetiredAt}
+	}
+	return converted
+}
+
+// fatal logs err at error level and exits
+func fatal(l *slog.Logger, msg string, err error) {
+	l.Error(msg, "error", err)
+	os.Exit(1)
 }
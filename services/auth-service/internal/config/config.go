@@ -1,11 +1,16 @@
 package config
 
 import (
+	"encoding/json"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
@@ -14,6 +19,43 @@ type Config struct {
 	JWTSecret        string
 	JWTRefreshSecret string
 	Environment      string
+	BcryptCost       int
+	LogLevel         string
+	LogFormat        string
+
+	// RequireEmailVerification blocks login until the user has verified their email
+	RequireEmailVerification bool
+
+	// Key rotation: IDs of the current secrets and retired secrets still trusted
+	JWTKeyID               string
+	JWTRefreshKeyID        string
+	JWTPreviousKeys        []JWTKey
+	JWTPreviousRefreshKeys []JWTKey
+
+	// Lifetimes of newly issued tokens
+	JWTAccessTokenTTL  time.Duration
+	JWTRefreshTokenTTL time.Duration
+
+	// Login, refresh and password reset requests allowed per client IP, and per username
+	// for login, within each window
+	RateLimitRequests int
+	RateLimitWindow   time.Duration
+
+	// Header a trusted proxy in front of the service sets to the client's IP, such as
+	// X-Forwarded-For; empty uses the connection's peer address
+	TrustedProxyHeader string
+
+	// Largest request body accepted, and whether JSON fields a request type does not
+	// declare are rejected
+	MaxBodyBytes int64
+	StrictJSON   bool
+}
+
+// JWTKey is a retired JWT signing secret that still verifies the tokens it signed
+type JWTKey struct {
+	ID        string    `json:"kid"`
+	Secret    string    `json:"secret"`
+	RetiredAt time.Time `json:"retired_at"`
 }
 
 var (
@@ -60,13 +102,147 @@ func loadConfig() {
 		environment = "development"
 	}
 
+	bcryptCost := bcrypt.DefaultCost
+	if value := os.Getenv("BCRYPT_COST"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < bcrypt.MinCost || parsed > bcrypt.MaxCost {
+			slog.Warn("invalid BCRYPT_COST, using default", "value", value, "default", bcrypt.DefaultCost)
+		} else {
+			bcryptCost = parsed
+		}
+	}
+
+	jwtKeyID := os.Getenv("JWT_KEY_ID")
+	if jwtKeyID == "" {
+		jwtKeyID = "v1"
+	}
+
+	jwtRefreshKeyID := os.Getenv("JWT_REFRESH_KEY_ID")
+	if jwtRefreshKeyID == "" {
+		jwtRefreshKeyID = "v1"
+	}
+
+	jwtPreviousKeys := parseJWTKeys("JWT_PREVIOUS_KEYS")
+	jwtPreviousRefreshKeys := parseJWTKeys("JWT_PREVIOUS_REFRESH_KEYS")
+
+	jwtAccessTokenTTL := parseDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute)
+	jwtRefreshTokenTTL := parseDuration("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour)
+
+	rateLimitRequests := 10
+	if value := os.Getenv("AUTH_RATE_LIMIT_REQUESTS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			slog.Warn("invalid AUTH_RATE_LIMIT_REQUESTS, using default", "value", value, "default", rateLimitRequests)
+		} else {
+			rateLimitRequests = parsed
+		}
+	}
+	rateLimitWindow := parseDuration("AUTH_RATE_LIMIT_WINDOW", time.Minute)
+	trustedProxyHeader := os.Getenv("TRUSTED_PROXY_HEADER")
+
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	logFormat := os.Getenv("LOG_FORMAT")
+	if logFormat == "" {
+		logFormat = "json"
+	}
+
+	requireEmailVerification := false
+	if value := os.Getenv("REQUIRE_EMAIL_VERIFICATION"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			slog.Warn("invalid REQUIRE_EMAIL_VERIFICATION, using default", "value", value, "default", false)
+		} else {
+			requireEmailVerification = parsed
+		}
+	}
+
+	maxBodyBytes := int64(1 << 20)
+	if value := os.Getenv("MAX_BODY_BYTES"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 {
+			slog.Warn("invalid MAX_BODY_BYTES, using default", "value", value, "default", maxBodyBytes)
+		} else {
+			maxBodyBytes = parsed
+		}
+	}
+
+	strictJSON := false
+	if value := os.Getenv("STRICT_JSON"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			slog.Warn("invalid STRICT_JSON, using default", "value", value, "default", false)
+		} else {
+			strictJSON = parsed
+		}
+	}
+
 	cfg = &Config{
 		Port:             port,
 		DatabaseURL:      databaseURL,
 		JWTSecret:        jwtSecret,
 		JWTRefreshSecret: jwtRefreshSecret,
 		Environment:      environment,
+		BcryptCost:       bcryptCost,
+		LogLevel:         logLevel,
+		LogFormat:        logFormat,
+
+		RequireEmailVerification: requireEmailVerification,
+
+		JWTKeyID:               jwtKeyID,
+		JWTRefreshKeyID:        jwtRefreshKeyID,
+		JWTPreviousKeys:        jwtPreviousKeys,
+		JWTPreviousRefreshKeys: jwtPreviousRefreshKeys,
+
+		JWTAccessTokenTTL:  jwtAccessTokenTTL,
+		JWTRefreshTokenTTL: jwtRefreshTokenTTL,
+
+		RateLimitRequests:  rateLimitRequests,
+		RateLimitWindow:    rateLimitWindow,
+		TrustedProxyHeader: trustedProxyHeader,
+
+		MaxBodyBytes: maxBodyBytes,
+		StrictJSON:   strictJSON,
+	}
+}
+
+// parseDuration reads a positive duration such as "15m" or "168h", falling back to def
+func parseDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		slog.Warn("invalid "+name+", using default", "value", value, "default", def.String())
+		return def
 	}
+	return parsed
+}
+
+// parseJWTKeys reads retired keys from a JSON array such as
+// [{"kid":"v1","secret":"...","retired_at":"2025-01-01T00:00:00Z"}]
+func parseJWTKeys(name string) []JWTKey {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	var keys []JWTKey
+	if err := json.Unmarshal([]byte(value), &keys); err != nil {
+		log.Fatalf("Error: invalid %s: %v", name, err)
+	}
+	for _, key := range keys {
+		if key.ID == "" || key.Secret == "" || key.RetiredAt.IsZero() {
+			log.Fatalf("Error: every key in %s needs kid, secret and retired_at.", name)
+		}
+	}
+
+	return keys
 }
 
 // ResetConfig resets the singleton for testing purposes
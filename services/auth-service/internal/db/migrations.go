@@ -1,9 +1,10 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -42,19 +43,43 @@ func RunMigrations(db *sqlx.DB, migrationsPath string) error {
 	// Apply pending migrations
 	for _, migration := range migrations {
 		if !isMigrationApplied(appliedMigrations, migration.Version) {
-			log.Printf("Applying migration %d: %s", migration.Version, migration.Name)
+			slog.Info("applying migration", "version", migration.Version, "name", migration.Name)
 
 			if err := applyMigration(db, migration); err != nil {
 				return fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
 			}
 
-			log.Printf("Successfully applied migration %d: %s", migration.Version, migration.Name)
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
 		}
 	}
 
 	return nil
 }
 
+// MigrationsCheck returns a readiness check that fails while the schema is behind the
+// newest migration in migrationsPath. The directory is read once, here.
+func MigrationsCheck(db *sqlx.DB, migrationsPath string) (func(ctx context.Context) error, error) {
+	migrations, err := loadMigrations(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	return func(ctx context.Context) error {
+		var version int
+		if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if version < latest {
+			return fmt.Errorf("schema at version %d, expected %d", version, latest)
+		}
+		return nil
+	}, nil
+}
+
 // createMigrationsTable creates the migrations tracking table
 func createMigrationsTable(db *sqlx.DB) error {
 	query := `
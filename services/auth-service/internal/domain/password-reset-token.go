@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// PasswordResetToken is a single-use token letting a user set a new password.
+// Only the hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" db:"id"`
+	UserID    uint       `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
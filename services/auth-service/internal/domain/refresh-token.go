@@ -12,5 +12,6 @@ type RefreshToken struct {
 	LastUsedAt   *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	IsRevoked    bool      `json:"is_revoked" db:"is_revoked"`
+	ReplacedBy   *uint     `json:"replaced_by,omitempty" db:"replaced_by"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
\ No newline at end of file
@@ -18,6 +18,11 @@ type User struct {
 	IsDeleted   bool      `json:"is_deleted" db:"is_deleted"`
 	IsActive    bool      `json:"is_active" db:"is_active"`
 
+	// DeactivatedAt is set while the account is deactivated; a deactivated user cannot log in
+	DeactivatedAt *time.Time `json:"deactivated_at" db:"deactivated_at"`
+
+	IsEmailVerified bool `json:"is_email_verified" db:"is_email_verified"`
+
 	// Role information
 	RoleID uint   `json:"role_id" db:"role_id"`
 	Role   string `json:"role" db:"-"` // Role name, populated when needed
@@ -1,18 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/middleware"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/validation"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 )
 
 type IAuthHandler interface {
@@ -21,11 +25,21 @@ type IAuthHandler interface {
 	RefreshToken(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
 	LogoutAll(w http.ResponseWriter, r *http.Request)
+	GetSessions(w http.ResponseWriter, r *http.Request)
+	RequestPasswordReset(w http.ResponseWriter, r *http.Request)
+	SendVerificationEmail(w http.ResponseWriter, r *http.Request)
+	VerifyEmail(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
 	GetUserByID(w http.ResponseWriter, r *http.Request)
+	GetMe(w http.ResponseWriter, r *http.Request)
 	GetAllUsers(w http.ResponseWriter, r *http.Request)
 	UpdateUser(w http.ResponseWriter, r *http.Request)
 	ChangePassword(w http.ResponseWriter, r *http.Request)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
+	DeactivateMe(w http.ResponseWriter, r *http.Request)
+	DeactivateUser(w http.ResponseWriter, r *http.Request)
+	ReactivateUser(w http.ResponseWriter, r *http.Request)
 	CleanupExpiredTokens(w http.ResponseWriter, r *http.Request)
 }
 
@@ -69,6 +83,20 @@ func (h *authHandler) setRefreshTokenCookie(w http.ResponseWriter, token string)
 	})
 }
 
+// addTokenExpiry adds when the new access and refresh tokens expire to a token response,
+// as seconds from now and as absolute times, so clients can refresh before they do
+func (h *authHandler) addTokenExpiry(data map[string]any, refreshToken *domain.RefreshToken) map[string]any {
+	now := time.Now()
+	// Signed expiry times are rounded down to the second
+	accessExpiresAt := now.Add(h.jwtService.GetAccessTokenExpiry()).Truncate(time.Second)
+
+	data["expires_in"] = int64(accessExpiresAt.Sub(now) / time.Second)
+	data["expires_at"] = accessExpiresAt.UTC()
+	data["refresh_expires_in"] = int64(refreshToken.ExpiresAt.Sub(now) / time.Second)
+	data["refresh_expires_at"] = refreshToken.ExpiresAt.UTC()
+	return data
+}
+
 // clearAuthCookies clears both access and refresh token cookies
 func (h *authHandler) clearAuthCookies(w http.ResponseWriter) {
 	http.SetCookie(w, &http.Cookie{
@@ -117,7 +145,7 @@ func (h *authHandler) extractClientIP(r *http.Request) string {
 
 func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSON(r, &req); err != nil {
 		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
@@ -141,16 +169,25 @@ func (h *authHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.userService.RegisterNewUser(r.Context(), user); err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			httpx.Error(w, http.StatusConflict, "email already registered", nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to register user", err)
 		return
 	}
 
+	// The account exists either way; the user can ask for another email later
+	if err := h.authService.SendVerificationEmail(r.Context(), user.ID); err != nil {
+		logger.FromContext(r.Context()).Warn("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	httpx.Created(w, "user registered", map[string]any{"id": user.ID, "username": user.Username, "email": user.Email})
 }
 
 func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSON(r, &req); err != nil {
 		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
@@ -167,6 +204,14 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Authenticate user and generate tokens
 	user, refreshToken, accessToken, err := h.authService.Login(r.Context(), req.Username, req.Password, userAgent, ipAddress)
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		httpx.Error(w, http.StatusForbidden, "email not verified", nil)
+		return
+	}
+	if errors.Is(err, services.ErrAccountDeactivated) {
+		httpx.Error(w, http.StatusForbidden, "account deactivated", nil)
+		return
+	}
 	if err != nil {
 		httpx.Error(w, http.StatusUnauthorized, "invalid credentials", err)
 		return
@@ -177,7 +222,7 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 	h.setRefreshTokenCookie(w, refreshToken.RefreshToken)
 
 	// Return success response
-	httpx.OK(w, "login successful", map[string]any{
+	httpx.OK(w, "login successful", h.addTokenExpiry(map[string]any{
 		"user": map[string]any{
 			"id":        user.ID,
 			"username":  user.Username,
@@ -188,7 +233,7 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 			"role":      user.Role,
 		},
 		"message": "Login successful. Tokens stored in HTTP-only cookies.",
-	})
+	}, refreshToken))
 }
 
 func (h *authHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
@@ -201,6 +246,21 @@ func (h *authHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	// Refresh tokens
 	user, newRefreshToken, accessToken, err := h.authService.RefreshToken(r.Context(), refreshToken)
+	if errors.Is(err, services.ErrTokenReuseDetected) {
+		logger.FromContext(r.Context()).Warn("security event: refresh token reuse detected, all sessions revoked",
+			"ip_address", h.extractClientIP(r),
+			"user_agent", r.UserAgent(),
+			"error", err,
+		)
+		h.clearAuthCookies(w)
+		httpx.Error(w, http.StatusUnauthorized, "refresh token reuse detected", nil)
+		return
+	}
+	if errors.Is(err, services.ErrAccountDeactivated) {
+		h.clearAuthCookies(w)
+		httpx.Error(w, http.StatusForbidden, "account deactivated", nil)
+		return
+	}
 	if err != nil {
 		httpx.Error(w, http.StatusUnauthorized, "invalid refresh token", err)
 		return
@@ -211,14 +271,14 @@ func (h *authHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	h.setRefreshTokenCookie(w, newRefreshToken.RefreshToken)
 
 	// Return success response
-	httpx.OK(w, "tokens refreshed successfully", map[string]any{
+	httpx.OK(w, "tokens refreshed successfully", h.addTokenExpiry(map[string]any{
 		"user": map[string]any{
 			"id":       user.ID,
 			"username": user.Username,
 			"email":    user.Email,
 		},
 		"message": "Tokens refreshed successfully. New tokens stored in HTTP-only cookies.",
-	})
+	}, newRefreshToken))
 }
 
 func (h *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
@@ -264,6 +324,161 @@ func (h *authHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// RequestPasswordReset sends a password reset token to the account's email. It reports
+// success whether or not an account uses the email.
+func (h *authHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req dto.PasswordResetRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.Error(w, http.StatusBadRequest, "validation failed", validationErrors)
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to request password reset", err)
+		return
+	}
+
+	httpx.OK(w, "if an account exists for this email, a password reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password using a reset token and logs out every session
+func (h *authHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.Error(w, http.StatusBadRequest, "validation failed", validationErrors)
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrPasswordResetTokenInvalid) ||
+			errors.Is(err, services.ErrPasswordResetTokenExpired) ||
+			errors.Is(err, services.ErrPasswordResetTokenUsed) {
+			httpx.Error(w, http.StatusBadRequest, "invalid or expired password reset token", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to reset password", err)
+		return
+	}
+
+	h.clearAuthCookies(w)
+
+	httpx.OK(w, "password reset successfully", nil)
+}
+
+// SendVerificationEmail sends the current user a new email verification token
+func (h *authHandler) SendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	c, ok := middleware.GetClaimsFromContext(r.Context()).(*services.Claims)
+	if !ok || c == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(r.Context(), c.UserID); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			httpx.Error(w, http.StatusConflict, "email already verified", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to send verification email", err)
+		return
+	}
+
+	httpx.OK(w, "verification email sent", nil)
+}
+
+// VerifyEmail confirms a user's email address with a verification token
+func (h *authHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.Error(w, http.StatusBadRequest, "validation failed", validationErrors)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(r.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrEmailVerificationTokenInvalid) ||
+			errors.Is(err, services.ErrEmailVerificationTokenExpired) ||
+			errors.Is(err, services.ErrEmailVerificationTokenUsed) {
+			httpx.Error(w, http.StatusBadRequest, "invalid or expired email verification token", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to verify email", err)
+		return
+	}
+
+	httpx.OK(w, "email verified successfully", nil)
+}
+
+// GetSessions lists the devices the current user is logged in on
+func (h *authHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	c, ok := middleware.GetClaimsFromContext(r.Context()).(*services.Claims)
+	if !ok || c == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	tokens, err := h.authService.GetSessions(r.Context(), c.UserID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to get sessions", err)
+		return
+	}
+
+	currentToken := services.ExtractTokenFromCookie(r, "refresh_token")
+	sessions := make([]dto.SessionResponse, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, dto.SessionResponse{
+			ID:         token.ID,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+			Current:    currentToken != "" && token.RefreshToken == currentToken,
+		})
+	}
+
+	httpx.OK(w, "sessions retrieved successfully", sessions)
+}
+
+// RevokeSession logs the current user out of one of their sessions
+func (h *authHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	c, ok := middleware.GetClaimsFromContext(r.Context()).(*services.Claims)
+	if !ok || c == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid session id", err)
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), c.UserID, uint(id)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "session not found", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to revoke session", err)
+		return
+	}
+
+	httpx.OK(w, "session revoked successfully", nil)
+}
+
 func (h *authHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
@@ -286,6 +501,56 @@ func (h *authHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "user found", user)
 }
 
+// GetMe returns the profile of the user the request is authenticated as
+func (h *authHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	c, ok := middleware.GetClaimsFromContext(r.Context()).(*services.Claims)
+	if !ok || c == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), int(c.UserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to get user", err)
+		return
+	}
+	if user.IsDeleted {
+		httpx.Error(w, http.StatusNotFound, "user not found", nil)
+		return
+	}
+
+	httpx.OK(w, "profile retrieved successfully", toUserProfileResponse(user))
+}
+
+// toUserProfileResponse maps a user to its profile, leaving out credentials
+func toUserProfileResponse(user *domain.User) dto.UserProfileResponse {
+	roles := []string{}
+	if user.Role != "" {
+		roles = append(roles, user.Role)
+	}
+
+	return dto.UserProfileResponse{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		MiddleName:      user.MiddleName,
+		LastName:        user.LastName,
+		Avatar:          user.Avatar,
+		Gender:          user.Gender,
+		DateOfBirth:     user.DateOfBirth,
+		Roles:           roles,
+		IsActive:        user.IsActive,
+		IsEmailVerified: user.IsEmailVerified,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
 func (h *authHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	limitStr := r.URL.Query().Get("limit")
 	if limitStr == "" {
@@ -332,7 +597,7 @@ func (h *authHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSON(r, &req); err != nil {
 		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
@@ -387,7 +652,7 @@ func (h *authHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.ChangePasswordRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSON(r, &req); err != nil {
 		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
@@ -423,10 +688,65 @@ func (h *authHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Revoke http-only cookies
+	httpx.OK(w, "user deleted successfully", nil)
+}
+
+// DeactivateMe deactivates the current user's own account and logs them out everywhere
+func (h *authHandler) DeactivateMe(w http.ResponseWriter, r *http.Request) {
+	c, ok := middleware.GetClaimsFromContext(r.Context()).(*services.Claims)
+	if !ok || c == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	if err := h.userService.DeactivateUser(r.Context(), int(c.UserID)); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to deactivate account", err)
+		return
+	}
+
 	h.clearAuthCookies(w)
 
-	httpx.OK(w, "user deleted successfully", nil)
+	httpx.OK(w, "account deactivated successfully", nil)
+}
+
+// DeactivateUser deactivates another user's account without deleting it
+func (h *authHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid id", err)
+		return
+	}
+
+	if err := h.userService.DeactivateUser(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to deactivate user", err)
+		return
+	}
+
+	httpx.OK(w, "user deactivated successfully", nil)
+}
+
+// ReactivateUser lets a deactivated account log in again
+func (h *authHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid id", err)
+		return
+	}
+
+	if err := h.userService.ReactivateUser(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "user not found", nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to reactivate user", err)
+		return
+	}
+
+	httpx.OK(w, "user reactivated successfully", nil)
 }
 
 func (h *authHandler) CleanupExpiredTokens(w http.ResponseWriter, r *http.Request) {
@@ -3,14 +3,18 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/middleware"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -61,6 +65,16 @@ func (m *MockUserService) DeleteUser(ctx context.Context, id int) error {
 	return args.Error(0)
 }
 
+func (m *MockUserService) DeactivateUser(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ReactivateUser(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // MockAuthService is a mock implementation of IAuthService
 type MockAuthService struct {
 	mock.Mock
@@ -106,6 +120,39 @@ func (m *MockAuthService) LogoutAll(ctx context.Context, userID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthService) GetSessions(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) SendVerificationEmail(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*services.Claims, error) {
 	args := m.Called(ctx, tokenString)
 	if args.Get(0) == nil {
@@ -140,7 +187,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create test user and refresh token
@@ -190,6 +237,17 @@ func TestAuthHandler_Login(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "login successful", response["message"])
 
+		// A 15 minute access token expires in about 900 seconds
+		data := response["data"].(map[string]interface{})
+		assert.InDelta(t, 900, data["expires_in"], 1)
+		expiresAt, err := time.Parse(time.RFC3339, data["expires_at"].(string))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, 2*time.Second)
+		assert.InDelta(t, 24*60*60, data["refresh_expires_in"], 1)
+		refreshExpiresAt, err := time.Parse(time.RFC3339, data["refresh_expires_at"].(string))
+		require.NoError(t, err)
+		assert.True(t, refreshToken.ExpiresAt.Equal(refreshExpiresAt))
+
 		// Check cookies
 		cookies := w.Result().Cookies()
 		accessTokenCookie := findCookie(cookies, "access_token")
@@ -208,7 +266,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create invalid request
@@ -238,7 +296,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create request with missing fields
@@ -273,7 +331,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// 🎭 Mock Expectations: Auth service should return error
@@ -309,6 +367,37 @@ func TestAuthHandler_Login(t *testing.T) {
 		// Verify service was called correctly
 		mockAuthService.AssertExpectations(t)
 	})
+
+	t.Run("should reject a deactivated account with forbidden", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		// 🎭 Mock Expectations: Auth service should report the account as deactivated
+		mockAuthService.On("Login", mock.Anything, "testuser", "password123", "test-agent", "127.0.0.1").
+			Return(nil, nil, "", services.ErrAccountDeactivated)
+
+		// Create request
+		reqBody, _ := json.Marshal(dto.LoginRequest{Username: "testuser", Password: "password123"})
+		req := httptest.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "test-agent")
+		req.RemoteAddr = "127.0.0.1:12345"
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call login handler
+		handler.Login(w, req)
+
+		// ✅ Assertions: Should be forbidden without setting cookies
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "account deactivated", response["message"])
+		assert.Nil(t, findCookie(w.Result().Cookies(), "access_token"))
+		mockAuthService.AssertExpectations(t)
+	})
 }
 
 // TestAuthHandler_RefreshToken tests the refresh token handler
@@ -319,7 +408,7 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create test user and refresh token
@@ -362,6 +451,9 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
 		assert.Equal(t, "tokens refreshed successfully", response["message"])
+		data := response["data"].(map[string]interface{})
+		assert.InDelta(t, 900, data["expires_in"], 1)
+		assert.Contains(t, data, "refresh_expires_at")
 
 		// Check cookies
 		cookies := w.Result().Cookies()
@@ -374,6 +466,42 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 		// Verify service was called correctly
 		mockAuthService.AssertExpectations(t)
 	})
+
+	t.Run("should reject a replayed token and clear cookies", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		// 🎭 Mock Expectations: Auth service detects the replay
+		mockAuthService.On("RefreshToken", mock.Anything, "rotated-refresh-token").
+			Return(nil, nil, "", services.ErrTokenReuseDetected)
+
+		req := httptest.NewRequest("POST", "/refresh", nil)
+		req.AddCookie(&http.Cookie{
+			Name:  "refresh_token",
+			Value: "rotated-refresh-token",
+		})
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call refresh handler
+		handler.RefreshToken(w, req)
+
+		// ✅ Assertions: Should be unauthorized with cookies cleared
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, "refresh token reuse detected", response["message"])
+
+		refreshTokenCookie := findCookie(w.Result().Cookies(), "refresh_token")
+		require.NotNil(t, refreshTokenCookie)
+		assert.Equal(t, -1, refreshTokenCookie.MaxAge)
+
+		mockAuthService.AssertExpectations(t)
+	})
 }
 
 // TestAuthHandler_Logout tests the logout handler
@@ -384,7 +512,7 @@ func TestAuthHandler_Logout(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// 🎭 Mock Expectations: Auth service should handle logout
@@ -432,7 +560,7 @@ func TestAuthHandler_Logout(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create request without refresh token cookie
@@ -466,7 +594,7 @@ func TestAuthHandler_LogoutAll(t *testing.T) {
 		// 🔧 Setup: Create mock services and handler
 		mockUserService := &MockUserService{}
 		mockAuthService := &MockAuthService{}
-		jwtService := services.NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
 		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
 
 		// Create test user and access token
@@ -519,6 +647,333 @@ func TestAuthHandler_LogoutAll(t *testing.T) {
 	})
 }
 
+// TestAuthHandler_GetMe tests the current user profile handler
+func TestAuthHandler_GetMe(t *testing.T) {
+	// 🎯 Test Strategy: Test profile handler with claims set as the auth middleware would
+
+	t.Run("should return profile with roles and without password", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		user := &domain.User{
+			ID:        1,
+			Username:  "testuser",
+			Password:  "$2a$12$hashedpasswordvalue",
+			Email:     "test@example.com",
+			FirstName: "Test",
+			RoleID:    domain.RoleIDEditor,
+			Role:      domain.RoleEditor,
+			IsActive:  true,
+		}
+
+		// 🎭 Mock Expectations: User service should load the authenticated user
+		mockUserService.On("GetUserByID", mock.Anything, 1).Return(user, nil)
+
+		req := httptest.NewRequest("GET", "/me", nil)
+		claims := &services.Claims{UserID: 1, Username: "testuser", Email: "test@example.com", Role: domain.RoleEditor}
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call profile handler
+		handler.GetMe(w, req)
+
+		// ✅ Assertions: Should return the profile
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "password")
+		assert.NotContains(t, w.Body.String(), user.Password)
+
+		var response struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "testuser", response.Data["username"])
+		assert.Equal(t, []interface{}{domain.RoleEditor}, response.Data["roles"])
+
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("should fail without claims", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		req := httptest.NewRequest("GET", "/me", nil)
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call profile handler
+		handler.GetMe(w, req)
+
+		// ✅ Assertions: Should be unauthorized
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuthHandler_Sessions tests listing and revoking the current user's sessions
+func TestAuthHandler_Sessions(t *testing.T) {
+	// 🎯 Test Strategy: Test session handlers with claims set as the auth middleware would
+
+	claims := &services.Claims{UserID: 1, Username: "testuser", Email: "test@example.com"}
+
+	t.Run("should list sessions without exposing tokens", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		tokens := []domain.RefreshToken{
+			{ID: 7, UserID: 1, RefreshToken: "phone-refresh-token", UserAgent: "Mobile Safari", IPAddress: "10.0.0.2", ExpiresAt: time.Now().Add(time.Hour)},
+			{ID: 5, UserID: 1, RefreshToken: "laptop-refresh-token", UserAgent: "Firefox", IPAddress: "10.0.0.1", ExpiresAt: time.Now().Add(time.Hour)},
+		}
+
+		// 🎭 Mock Expectations: Auth service should return the user's tokens
+		mockAuthService.On("GetSessions", mock.Anything, uint(1)).Return(tokens, nil)
+
+		req := httptest.NewRequest("GET", "/sessions", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "laptop-refresh-token"})
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call sessions handler
+		handler.GetSessions(w, req)
+
+		// ✅ Assertions: Should list metadata only and mark the calling session
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "refresh-token")
+
+		var response struct {
+			Data []dto.SessionResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, uint(7), response.Data[0].ID)
+		assert.Equal(t, "Mobile Safari", response.Data[0].UserAgent)
+		assert.False(t, response.Data[0].Current)
+		assert.True(t, response.Data[1].Current)
+
+		mockAuthService.AssertExpectations(t)
+	})
+
+	t.Run("should revoke a session of the caller", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		// 🎭 Mock Expectations: Auth service should revoke the session for the caller
+		mockAuthService.On("RevokeSession", mock.Anything, uint(1), uint(7)).Return(nil)
+
+		req := sessionRequest(claims, "7")
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call revoke handler
+		handler.RevokeSession(w, req)
+
+		// ✅ Assertions: Should succeed
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAuthService.AssertExpectations(t)
+	})
+
+	t.Run("should not revoke another user's session", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		// 🎭 Mock Expectations: The session does not belong to the caller
+		mockAuthService.On("RevokeSession", mock.Anything, uint(1), uint(9)).
+			Return(fmt.Errorf("refresh token not found: %w", sql.ErrNoRows))
+
+		req := sessionRequest(claims, "9")
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call revoke handler
+		handler.RevokeSession(w, req)
+
+		// ✅ Assertions: Should be not found
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockAuthService.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid session id", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		req := sessionRequest(claims, "abc")
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call revoke handler
+		handler.RevokeSession(w, req)
+
+		// ✅ Assertions: Should be a bad request
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockAuthService.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuthHandler_ResetPassword tests the password reset confirmation handler
+func TestAuthHandler_ResetPassword(t *testing.T) {
+	// 🎯 Test Strategy: Test token error mapping with mocked services
+
+	for _, tokenErr := range []error{services.ErrPasswordResetTokenExpired, services.ErrPasswordResetTokenUsed, services.ErrPasswordResetTokenInvalid} {
+		t.Run("should reject token: "+tokenErr.Error(), func(t *testing.T) {
+			// 🔧 Setup: Create mock services and handler
+			mockUserService := &MockUserService{}
+			mockAuthService := &MockAuthService{}
+			jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+			handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+			// 🎭 Mock Expectations: Auth service rejects the token
+			mockAuthService.On("ResetPassword", mock.Anything, "reset-token", "NewSecure123").Return(tokenErr)
+
+			body, _ := json.Marshal(dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "NewSecure123"})
+			req := httptest.NewRequest("POST", "/password-reset/confirm", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			// 🚀 Action: Call reset handler
+			handler.ResetPassword(w, req)
+
+			// ✅ Assertions: Should be a bad request
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("should reset password and clear cookies", func(t *testing.T) {
+		// 🔧 Setup: Create mock services and handler
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		jwtService := services.NewJWTService("test-secret", "test-refresh-secret", services.DefaultAccessTokenTTL, services.DefaultRefreshTokenTTL)
+		handler := NewAuthHandler(mockUserService, mockAuthService, jwtService)
+
+		mockAuthService.On("ResetPassword", mock.Anything, "reset-token", "NewSecure123").Return(nil)
+
+		body, _ := json.Marshal(dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "NewSecure123"})
+		req := httptest.NewRequest("POST", "/password-reset/confirm", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		// 🚀 Action: Call reset handler
+		handler.ResetPassword(w, req)
+
+		// ✅ Assertions: Should succeed and log out this browser
+		assert.Equal(t, http.StatusOK, w.Code)
+		refreshTokenCookie := findCookie(w.Result().Cookies(), "refresh_token")
+		require.NotNil(t, refreshTokenCookie)
+		assert.Equal(t, -1, refreshTokenCookie.MaxAge)
+	})
+}
+
+func TestAuthHandler_DeactivateUser(t *testing.T) {
+	// 🎯 Test Strategy: unknown users get 404 from both deactivation and reactivation
+
+	userRequest := func(id string) *http.Request {
+		req := httptest.NewRequest("POST", "/users/"+id+"/deactivate", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("should return 404 for an unknown user", func(t *testing.T) {
+		// 🔧 Setup
+		mockUserService := &MockUserService{}
+		handler := NewAuthHandler(mockUserService, &MockAuthService{}, nil)
+		notFound := fmt.Errorf("no user found with id 999: %w", sql.ErrNoRows)
+		mockUserService.On("DeactivateUser", mock.Anything, 999).Return(notFound)
+		mockUserService.On("ReactivateUser", mock.Anything, 999).Return(notFound)
+
+		// 🚀 Action
+		deactivate := httptest.NewRecorder()
+		handler.DeactivateUser(deactivate, userRequest("999"))
+		reactivate := httptest.NewRecorder()
+		handler.ReactivateUser(reactivate, userRequest("999"))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusNotFound, deactivate.Code)
+		assert.Equal(t, http.StatusNotFound, reactivate.Code)
+	})
+
+	t.Run("should return 500 when the update fails", func(t *testing.T) {
+		// 🔧 Setup
+		mockUserService := &MockUserService{}
+		handler := NewAuthHandler(mockUserService, &MockAuthService{}, nil)
+		mockUserService.On("DeactivateUser", mock.Anything, 1).Return(fmt.Errorf("connection reset"))
+
+		// 🚀 Action
+		w := httptest.NewRecorder()
+		handler.DeactivateUser(w, userRequest("1"))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestAuthHandler_DeactivateMe(t *testing.T) {
+	// 🎯 Test Strategy: call the endpoint through AuthMiddleware, as the router does
+
+	t.Run("should deactivate the signed-in user and clear the auth cookies", func(t *testing.T) {
+		// 🔧 Setup
+		mockUserService := &MockUserService{}
+		mockAuthService := &MockAuthService{}
+		handler := NewAuthHandler(mockUserService, mockAuthService, nil)
+		claims := &services.Claims{UserID: 1, Username: "testuser", Email: "test@example.com"}
+		mockAuthService.On("ValidateAccessToken", mock.Anything, "access-token").Return(claims, nil)
+		mockUserService.On("DeactivateUser", mock.Anything, 1).Return(nil)
+
+		req := httptest.NewRequest("POST", "/me/deactivate", nil)
+		req.Header.Set("Authorization", "Bearer access-token")
+		w := httptest.NewRecorder()
+
+		// 🚀 Action
+		middleware.AuthMiddleware(mockAuthService, services.DefaultAccessTokenTTL)(http.HandlerFunc(handler.DeactivateMe)).ServeHTTP(w, req)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUserService.AssertExpectations(t)
+
+		cookies := w.Result().Cookies()
+		for _, name := range []string{"access_token", "refresh_token"} {
+			cookie := findCookie(cookies, name)
+			require.NotNil(t, cookie, name)
+			assert.Equal(t, "", cookie.Value)
+			assert.Equal(t, -1, cookie.MaxAge)
+		}
+	})
+
+	t.Run("should fail without claims", func(t *testing.T) {
+		// 🔧 Setup
+		mockUserService := &MockUserService{}
+		handler := NewAuthHandler(mockUserService, &MockAuthService{}, nil)
+
+		// 🚀 Action
+		w := httptest.NewRecorder()
+		handler.DeactivateMe(w, httptest.NewRequest("POST", "/me/deactivate", nil))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockUserService.AssertNotCalled(t, "DeactivateUser", mock.Anything, mock.Anything)
+	})
+}
+
+// sessionRequest builds a DELETE /sessions/{id} request authenticated with claims
+func sessionRequest(claims *services.Claims, id string) *http.Request {
+	req := httptest.NewRequest("DELETE", "/sessions/"+id, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	return req.WithContext(context.WithValue(ctx, middleware.ClaimsContextKey, claims))
+}
+
 // Helper function to find a cookie by name
 func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
 	for _, cookie := range cookies {
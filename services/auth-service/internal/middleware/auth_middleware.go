@@ -3,10 +3,12 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 )
 
 type contextKey string
@@ -18,8 +20,9 @@ const (
 
 // AuthMiddleware validates JWT tokens and extracts user information
 // Implements smart token refresh: if access token is expired but refresh token is valid,
-// it will automatically refresh the access token
-func AuthMiddleware(authService services.IAuthService) func(http.Handler) http.Handler {
+// it will automatically refresh the access token. accessTokenTTL is the configured access
+// token lifetime, which the refreshed token's cookie expires with.
+func AuthMiddleware(authService services.IAuthService, accessTokenTTL time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract access token from Authorization header or cookie
@@ -36,7 +39,7 @@ func AuthMiddleware(authService services.IAuthService) func(http.Handler) http.H
 				claims, err = authService.ValidateAccessToken(r.Context(), accessToken)
 				if err == nil {
 					// Access token is valid, proceed normally
-					ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+					ctx := withClaims(r.Context(), claims)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -79,19 +82,20 @@ func AuthMiddleware(authService services.IAuthService) func(http.Handler) http.H
 				HttpOnly: true,
 				Secure:   true,
 				SameSite: http.SameSiteStrictMode,
-				MaxAge:   900, // 15 minutes
+				MaxAge:   int(accessTokenTTL.Seconds()),
 			})
 
 			// Use the claims from the minimal user object
 			claims = &services.Claims{
-				UserID:   minimalUser.ID,
-				Username: minimalUser.Username,
-				Email:    minimalUser.Email,
-				Role:     minimalUser.Role,
+				UserID:    minimalUser.ID,
+				Username:  minimalUser.Username,
+				Email:     minimalUser.Email,
+				Role:      minimalUser.Role,
+				TokenType: services.TokenTypeAccess,
 			}
 
 			// Add claims to context (no user DB query needed)
-			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			ctx := withClaims(r.Context(), claims)
 
 			// Call next handler with updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -113,7 +117,7 @@ func OptionalAuthMiddleware(authService services.IAuthService) func(http.Handler
 				// Try to validate token
 				if claims, err := authService.ValidateAccessToken(r.Context(), token); err == nil {
 					// Only add claims to context (no user DB query needed)
-					ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+					ctx := withClaims(r.Context(), claims)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -125,6 +129,13 @@ func OptionalAuthMiddleware(authService services.IAuthService) func(http.Handler
 	}
 }
 
+// withClaims stores validated claims in ctx along with the user ID, which services
+// read through userctx to attribute writes
+func withClaims(ctx context.Context, claims *services.Claims) context.Context {
+	ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+	return userctx.WithUserID(ctx, int64(claims.UserID))
+}
+
 // GetUserFromContext extracts user from request context
 func GetUserFromContext(ctx context.Context) interface{} {
 	return ctx.Value(UserContextKey)
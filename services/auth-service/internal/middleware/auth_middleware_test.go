@@ -3,12 +3,15 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -51,6 +54,39 @@ func (m *MockAuthService) LogoutAll(ctx context.Context, userID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthService) GetSessions(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) SendVerificationEmail(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*services.Claims, error) {
 	args := m.Called(ctx, tokenString)
 	if args.Get(0) == nil {
@@ -92,7 +128,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("should allow access with valid access token in cookie", func(t *testing.T) {
 		// 🔧 Setup: Create mock auth service and middleware
 		mockAuthService := &MockAuthService{}
-		middleware := AuthMiddleware(mockAuthService)
+		middleware := AuthMiddleware(mockAuthService, services.DefaultAccessTokenTTL)
 
 		// Create test claims (no user object needed)
 		claims := &services.Claims{UserID: 1, Username: "testuser", Email: "test@example.com"}
@@ -119,6 +155,11 @@ func TestAuthMiddleware(t *testing.T) {
 
 			assert.Equal(t, claims, ctxClaims)
 
+			// The user ID is available to services that attribute writes
+			userID, ok := userctx.UserID(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, int64(1), userID)
+
 			w.WriteHeader(http.StatusOK)
 		})
 
@@ -136,7 +177,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("should allow access with valid access token in Authorization header", func(t *testing.T) {
 		// 🔧 Setup: Create mock auth service and middleware
 		mockAuthService := &MockAuthService{}
-		middleware := AuthMiddleware(mockAuthService)
+		middleware := AuthMiddleware(mockAuthService, services.DefaultAccessTokenTTL)
 
 		// Create test claims (no user object needed)
 		claims := &services.Claims{UserID: 1, Username: "testuser", Email: "test@example.com"}
@@ -177,7 +218,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("should reject access without token", func(t *testing.T) {
 		// 🔧 Setup: Create mock auth service and middleware
 		mockAuthService := &MockAuthService{}
-		middleware := AuthMiddleware(mockAuthService)
+		middleware := AuthMiddleware(mockAuthService, services.DefaultAccessTokenTTL)
 
 		// Create request without token
 		req := httptest.NewRequest("GET", "/protected", nil)
@@ -206,7 +247,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("should reject access with invalid token", func(t *testing.T) {
 		// 🔧 Setup: Create mock auth service and middleware
 		mockAuthService := &MockAuthService{}
-		middleware := AuthMiddleware(mockAuthService)
+		middleware := AuthMiddleware(mockAuthService, services.DefaultAccessTokenTTL)
 
 		// 🎭 Mock Expectations: Auth service should reject invalid token
 		mockAuthService.On("ValidateAccessToken", mock.Anything, "invalid-token").Return(nil, assert.AnError)
@@ -240,6 +281,46 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+// TestAuthMiddleware_RefreshedCookie tests the cookie set when the access token is refreshed
+func TestAuthMiddleware_RefreshedCookie(t *testing.T) {
+	t.Run("should expire the refreshed access token cookie with the configured TTL", func(t *testing.T) {
+		// 🔧 Setup: Create middleware with a non-default access token TTL
+		mockAuthService := &MockAuthService{}
+		middleware := AuthMiddleware(mockAuthService, 30*time.Minute)
+
+		// 🎭 Mock Expectations: The access token has expired but the refresh token is valid
+		refreshClaims := &services.RefreshTokenClaims{UserID: 1, Username: "testuser", Email: "test@example.com"}
+		mockAuthService.On("ValidateAccessToken", mock.Anything, "expired-token").Return(nil, errors.New("token expired"))
+		mockAuthService.On("ValidateRefreshToken", mock.Anything, "refresh-token").Return(refreshClaims, nil)
+		mockAuthService.On("GenerateAccessTokenFromUser", mock.Anything, mock.Anything).Return("new-access-token", nil)
+
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: "expired-token"})
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-token"})
+		w := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		// 🚀 Action: Call middleware
+		middleware(handler).ServeHTTP(w, req)
+
+		// ✅ Assertions: The new cookie lives as long as the token in it
+		assert.Equal(t, http.StatusOK, w.Code)
+		var cookie *http.Cookie
+		for _, c := range w.Result().Cookies() {
+			if c.Name == "access_token" {
+				cookie = c
+			}
+		}
+		require.NotNil(t, cookie)
+		assert.Equal(t, "new-access-token", cookie.Value)
+		assert.Equal(t, int((30 * time.Minute).Seconds()), cookie.MaxAge)
+		mockAuthService.AssertExpectations(t)
+	})
+}
+
 // TestOptionalAuthMiddleware tests the optional authentication middleware
 func TestOptionalAuthMiddleware(t *testing.T) {
 	// 🎯 Test Strategy: Test optional authentication middleware with mocked auth service
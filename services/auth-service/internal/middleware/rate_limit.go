@@ -0,0 +1,301 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// RateLimiter decides whether another request may be made under key. When it may not,
+// retryAfter is how long until it may. Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitKeyFunc picks the key a request is counted under. An empty key skips the limit.
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimit rejects requests over the limiter's limit with 429 Too Many Requests and a
+// Retry-After header. A limiter error lets the request through, so an outage of the
+// limiter's store does not lock everyone out. A nil limiter disables the limit.
+func RateLimit(limiter RateLimiter, key RateLimitKeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter, err := limiter.Allow(r.Context(), k)
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("rate limiter failed, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				httpx.Error(w, http.StatusTooManyRequests, "too many requests", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIPFunc reports the IP address a request came from
+type ClientIPFunc func(r *http.Request) string
+
+// ClientIP finds a request's client IP. With no trustedHeader it is the connection's
+// peer address. Behind a proxy, trustedHeader names the header the proxy sets to the
+// address it received the request from, such as X-Real-IP or X-Forwarded-For. Only the
+// last X-Forwarded-For entry is used: the proxy appends it, while earlier entries come
+// from the client, who could rotate them to dodge the limit. Requests without the
+// header fall back to the peer address.
+func ClientIP(trustedHeader string) ClientIPFunc {
+	return func(r *http.Request) string {
+		if trustedHeader != "" {
+			if value := r.Header.Get(trustedHeader); value != "" {
+				if i := strings.LastIndex(value, ","); i != -1 {
+					value = value[i+1:]
+				}
+				if ip := strings.TrimSpace(value); ip != "" {
+					return ip
+				}
+			}
+		}
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return ip
+	}
+}
+
+// ClientIPKey counts requests per route and client IP
+func ClientIPKey(clientIP ClientIPFunc) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + r.URL.Path + ":" + clientIP(r)
+	}
+}
+
+// maxLoginBodyBytes bounds how much of a login body LoginUsernameKey reads
+const maxLoginBodyBytes = 1 << 20
+
+// LoginUsernameKey counts login attempts per username, or per email address for
+// email logins, from IPs that have failed a login recently. Logins from other IPs are
+// not counted, so guessing a user's password elsewhere cannot lock them out. The body
+// is put back for the handler; requests without a username are not counted.
+func LoginUsernameKey(failures *FailedLogins, clientIP ClientIPFunc) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		if !failures.Failed(clientIP(r)) {
+			return ""
+		}
+		return loginUsername(r)
+	}
+}
+
+// loginUsername reads the username a login request is for
+func loginUsername(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLoginBodyBytes))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var login struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(body, &login); err != nil {
+		return ""
+	}
+	username := strings.ToLower(strings.TrimSpace(login.Username))
+	if username == "" {
+		return ""
+	}
+	return "user:" + username
+}
+
+// FailedLogins remembers the client IPs that failed a login within the last window.
+// It is safe for concurrent use and kept in process memory, so it is per instance.
+type FailedLogins struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	failedAt  map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewFailedLogins creates a tracker remembering each failed login for window
+func NewFailedLogins(window time.Duration) *FailedLogins {
+	return &FailedLogins{
+		window:   window,
+		now:      time.Now,
+		failedAt: make(map[string]time.Time),
+	}
+}
+
+// Record notes a failed login from ip
+func (f *FailedLogins) Record(ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.sweep(now)
+	f.failedAt[ip] = now
+}
+
+// Failed reports whether ip failed a login within the last window
+func (f *FailedLogins) Failed(ip string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	failedAt, ok := f.failedAt[ip]
+	return ok && f.now().Sub(failedAt) < f.window
+}
+
+// sweep drops failures older than a window, at most once per window
+func (f *FailedLogins) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < f.window {
+		return
+	}
+	f.lastSweep = now
+	for ip, failedAt := range f.failedAt {
+		if now.Sub(failedAt) >= f.window {
+			delete(f.failedAt, ip)
+		}
+	}
+}
+
+// RecordFailedLogins records the client IP of every login answered 401 Unauthorized
+func RecordFailedLogins(failures *FailedLogins, clientIP ClientIPFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == http.StatusUnauthorized {
+				failures.Record(clientIP(r))
+			}
+		})
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// bucket is a token bucket: tokens refill continuously and each request spends one
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimiter is a token bucket limiter kept in process memory. Each key may make
+// limit requests at once and regains them evenly over window. Limits are per instance.
+type MemoryRateLimiter struct {
+	limit  float64
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimiter creates a limiter allowing limit requests per window for each key
+func NewMemoryRateLimiter(limit int, window time.Duration) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		limit:   float64(limit),
+		window:  window,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow spends a token from key's bucket if one is left
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time since the bucket was last used
+	perToken := l.window / time.Duration(l.limit)
+	b.tokens = math.Min(l.limit, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets that have been idle for a whole window, and so are full again, at
+// most once per window
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLimiter is a limiter whose store is unavailable
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	return false, 0, errors.New("connection refused")
+}
+
+// newTestLimiter returns a limiter of limit requests per minute on a clock the test moves
+func newTestLimiter(limit int) (*MemoryRateLimiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter(limit, time.Minute)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+// TestMemoryRateLimiter tests the token bucket
+func TestMemoryRateLimiter(t *testing.T) {
+	// 🎯 Test Strategy: spend a key's requests, check the next is refused with the time
+	// until a request is regained, then move the clock
+
+	t.Run("should allow the limit then block", func(t *testing.T) {
+		// 🔧 Setup: 3 requests a minute, so one is regained every 20 seconds
+		limiter, now := newTestLimiter(3)
+		ctx := context.Background()
+
+		// 🚀 Action + ✅ Assertions
+		for i := 0; i < 3; i++ {
+			allowed, _, err := limiter.Allow(ctx, "ip:1.2.3.4")
+			require.NoError(t, err)
+			assert.True(t, allowed, "request %d", i+1)
+		}
+
+		allowed, retryAfter, err := limiter.Allow(ctx, "ip:1.2.3.4")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 20*time.Second, retryAfter)
+
+		// Other keys have their own bucket
+		allowed, _, _ = limiter.Allow(ctx, "ip:5.6.7.8")
+		assert.True(t, allowed)
+
+		// Half way to the next request it is still refused
+		*now = now.Add(10 * time.Second)
+		allowed, retryAfter, _ = limiter.Allow(ctx, "ip:1.2.3.4")
+		assert.False(t, allowed)
+		assert.Equal(t, 10*time.Second, retryAfter)
+
+		// Once it is regained exactly one more request goes through
+		*now = now.Add(10 * time.Second)
+		allowed, _, _ = limiter.Allow(ctx, "ip:1.2.3.4")
+		assert.True(t, allowed)
+		allowed, _, _ = limiter.Allow(ctx, "ip:1.2.3.4")
+		assert.False(t, allowed)
+	})
+
+	t.Run("should drop idle buckets", func(t *testing.T) {
+		// 🔧 Setup
+		limiter, now := newTestLimiter(2)
+		_, _, _ = limiter.Allow(context.Background(), "ip:1.2.3.4")
+
+		// 🚀 Action: another key arrives a window later
+		*now = now.Add(time.Minute)
+		_, _, _ = limiter.Allow(context.Background(), "ip:5.6.7.8")
+
+		// ✅ Assertions
+		assert.Len(t, limiter.buckets, 1)
+		assert.Contains(t, limiter.buckets, "ip:5.6.7.8")
+	})
+}
+
+// TestFailedLogins tests remembering failed logins for a window
+func TestFailedLogins(t *testing.T) {
+	// 🔧 Setup
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	failures := NewFailedLogins(time.Minute)
+	failures.now = func() time.Time { return now }
+
+	// 🚀 Action
+	failures.Record("1.2.3.4")
+
+	// ✅ Assertions
+	assert.True(t, failures.Failed("1.2.3.4"))
+	assert.False(t, failures.Failed("5.6.7.8"))
+
+	now = now.Add(time.Minute)
+	assert.False(t, failures.Failed("1.2.3.4"))
+}
+
+// loginLimit wraps a login handler the way the router does
+func loginLimit(limiter RateLimiter, failures *FailedLogins, clientIP ClientIPFunc, login http.HandlerFunc) http.Handler {
+	return RateLimit(limiter, LoginUsernameKey(failures, clientIP))(RecordFailedLogins(failures, clientIP)(login))
+}
+
+// TestClientIP tests finding the client IP with and without a trusted proxy header
+func TestClientIP(t *testing.T) {
+	request := func(remoteAddr string, header http.Header) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		return req
+	}
+
+	tests := []struct {
+		name          string
+		trustedHeader string
+		req           *http.Request
+		want          string
+	}{
+		{"peer address", "", request("1.2.3.4:5000", nil), "1.2.3.4"},
+		{"forwarding headers ignored unless trusted", "", request("10.0.0.1:5000", http.Header{"X-Forwarded-For": {"9.9.9.9"}}), "10.0.0.1"},
+		{"last forwarded entry", "X-Forwarded-For", request("10.0.0.1:5000", http.Header{"X-Forwarded-For": {"9.9.9.9, 1.2.3.4"}}), "1.2.3.4"},
+		{"real IP header", "X-Real-IP", request("10.0.0.1:5000", http.Header{"X-Real-Ip": {"1.2.3.4"}}), "1.2.3.4"},
+		{"missing header falls back to the peer", "X-Forwarded-For", request("1.2.3.4:5000", nil), "1.2.3.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClientIP(tt.trustedHeader)(tt.req))
+		})
+	}
+}
+
+// TestRateLimit tests the rate limiting middleware
+func TestRateLimit(t *testing.T) {
+	// 🎯 Test Strategy: send login attempts through the middleware and check which reach the handler
+
+	serve := func(handler http.Handler, remoteAddr, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should allow then block an IP with Retry-After", func(t *testing.T) {
+		// 🔧 Setup
+		limiter, _ := newTestLimiter(2)
+		handler := RateLimit(limiter, ClientIPKey(ClientIP("")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		// 🚀 Action + ✅ Assertions
+		assert.Equal(t, http.StatusOK, serve(handler, "1.2.3.4:5000", "").Code)
+		assert.Equal(t, http.StatusOK, serve(handler, "1.2.3.4:5001", "").Code)
+
+		blocked := serve(handler, "1.2.3.4:5002", "")
+		assert.Equal(t, http.StatusTooManyRequests, blocked.Code)
+		assert.Equal(t, "30", blocked.Header().Get("Retry-After"))
+
+		// Another client is unaffected
+		assert.Equal(t, http.StatusOK, serve(handler, "5.6.7.8:5000", "").Code)
+	})
+
+	t.Run("should block a username across IPs that failed a login and keep the body for the handler", func(t *testing.T) {
+		// 🔧 Setup: every attempt fails
+		limiter, _ := newTestLimiter(2)
+		failures := NewFailedLogins(time.Minute)
+		clientIP := ClientIP("")
+		var bodies []string
+		handler := loginLimit(limiter, failures, clientIP, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		login := `{"username":"Alice","password":"guess"}`
+		for _, ip := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+			failures.Record(ip)
+		}
+
+		// 🚀 Action
+		first := serve(handler, "1.1.1.1:5000", login)
+		second := serve(handler, "2.2.2.2:5000", `{"username":"alice ","password":"guess"}`)
+		third := serve(handler, "3.3.3.3:5000", login)
+		other := serve(handler, "3.3.3.3:5000", `{"username":"bob","password":"guess"}`)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusUnauthorized, first.Code)
+		assert.Equal(t, http.StatusUnauthorized, second.Code)
+		assert.Equal(t, http.StatusTooManyRequests, third.Code)
+		assert.Equal(t, http.StatusUnauthorized, other.Code)
+		require.Len(t, bodies, 3)
+		assert.Equal(t, login, bodies[0])
+	})
+
+	t.Run("should not count a username from IPs without a failed login", func(t *testing.T) {
+		// 🔧 Setup
+		limiter, _ := newTestLimiter(1)
+		failures := NewFailedLogins(time.Minute)
+		handler := loginLimit(limiter, failures, ClientIP(""), func(w http.ResponseWriter, r *http.Request) {
+			if r.RemoteAddr == "6.6.6.6:5000" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		login := `{"username":"alice","password":"guess"}`
+
+		// 🚀 Action: an attacker fails, spends the username's limit and is blocked
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, "6.6.6.6:5000", login).Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, "6.6.6.6:5000", login).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(handler, "6.6.6.6:5000", login).Code)
+
+		// ✅ Assertions: the user still logs in from their own IP
+		assert.Equal(t, http.StatusOK, serve(handler, "1.2.3.4:5000", login).Code)
+		assert.Equal(t, http.StatusOK, serve(handler, "1.2.3.4:5000", login).Code)
+	})
+
+	t.Run("should not count requests without a key", func(t *testing.T) {
+		// 🔧 Setup
+		limiter, _ := newTestLimiter(1)
+		failures := NewFailedLogins(time.Minute)
+		failures.Record("1.2.3.4")
+		handler := RateLimit(limiter, LoginUsernameKey(failures, ClientIP("")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+
+		// 🚀 Action + ✅ Assertions: malformed bodies reach the handler's validation
+		assert.Equal(t, http.StatusBadRequest, serve(handler, "1.2.3.4:5000", "not json").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(handler, "1.2.3.4:5000", "not json").Code)
+	})
+
+	t.Run("should let requests through when the limiter fails", func(t *testing.T) {
+		// 🔧 Setup
+		handler := RateLimit(failingLimiter{}, ClientIPKey(ClientIP("")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		// 🚀 Action + ✅ Assertions
+		assert.Equal(t, http.StatusOK, serve(handler, "1.2.3.4:5000", "").Code)
+	})
+}
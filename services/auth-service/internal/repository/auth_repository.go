@@ -2,18 +2,28 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrEmailTaken is returned when registering an email an existing user already has,
+// in any letter case
+var ErrEmailTaken = errors.New("email already registered")
+
 type IUserRepository interface {
 	RegisterNewUser(ctx context.Context, u *domain.User) error
 	GetUserByID(ctx context.Context, id int) (*domain.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetAllUsers(ctx context.Context, limit int, offset int) ([]domain.User, error)
 	UpdateUser(ctx context.Context, id int, u *domain.User) error
+	MarkEmailVerified(ctx context.Context, id uint) error
+	SetUserActive(ctx context.Context, id int, active bool) error
 	DeleteUser(ctx context.Context, id int) error
 }
 
@@ -40,7 +50,7 @@ func (r *userRepository) RegisterNewUser(ctx context.Context, u *domain.User) er
 	// use NamedQueryRowx to bind struct fields by db tags
 	rows, err := r.db.NamedQueryContext(ctx, query, u)
 	if err != nil {
-		return err
+		return emailTaken(err)
 	}
 	defer rows.Close()
 	if rows.Next() {
@@ -48,7 +58,17 @@ func (r *userRepository) RegisterNewUser(ctx context.Context, u *domain.User) er
 			return err
 		}
 	}
-	return rows.Err()
+	return emailTaken(rows.Err())
+}
+
+// emailTaken turns a unique violation on the users' email into ErrEmailTaken
+func emailTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" &&
+		(pqErr.Constraint == "idx_users_email_lower" || pqErr.Constraint == "users_email_key") {
+		return ErrEmailTaken
+	}
+	return err
 }
 
 func (r *userRepository) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
@@ -91,6 +111,27 @@ func (r *userRepository) GetUserByUsername(ctx context.Context, username string)
 	return &user, nil
 }
 
+// GetUserByEmail retrieves a user by email address, ignoring case
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `
+		SELECT * FROM users WHERE LOWER(email) = LOWER($1) AND is_deleted = false;
+	`
+
+	var user domain.User
+	if err := r.db.GetContext(ctx, &user, query, email); err != nil {
+		return nil, err
+	}
+
+	roleName, ok := domain.RoleNames[int(user.RoleID)]
+	if !ok {
+		user.RoleID, user.Role = domain.GetDefaultRole()
+	} else {
+		user.Role = roleName
+	}
+
+	return &user, nil
+}
+
 func (r *userRepository) UpdateUser(ctx context.Context, id int, u *domain.User) error {
 	query := `
 		UPDATE users SET 
@@ -149,6 +190,54 @@ func (r *userRepository) GetAllUsers(ctx context.Context, limit int, offset int)
 	return users, nil
 }
 
+// MarkEmailVerified records that a user has confirmed their email address
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	query := `
+		UPDATE users SET is_email_verified = true, updated_at = NOW() WHERE id = $1;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no user found with id %d", id)
+	}
+
+	return nil
+}
+
+// SetUserActive deactivates or reactivates an account. deactivated_at records when it
+// was deactivated and is cleared on reactivation.
+func (r *userRepository) SetUserActive(ctx context.Context, id int, active bool) error {
+	query := `
+		UPDATE users SET is_active = $1, deactivated_at = CASE WHEN $1 THEN NULL ELSE NOW() END, updated_at = NOW()
+		WHERE id = $2 AND is_deleted = false;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, active, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no user found with id %d: %w", id, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
 func (r *userRepository) DeleteUser(ctx context.Context, id int) error {
 	query := `
 		DELETE FROM users WHERE id = $1;
@@ -9,6 +9,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -111,6 +112,23 @@ func TestUserRepository_RegisterNewUser_DatabaseError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUserRepository_RegisterNewUser_EmailTaken(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(db)
+
+	// alice@example.com is held by another user in different letter case
+	user := &domain.User{Username: "alice2", Password: "hashedpassword", Email: "alice@example.com"}
+	mock.ExpectQuery(`INSERT INTO users`).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_users_email_lower"})
+
+	err := repo.RegisterNewUser(context.Background(), user)
+
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_RegisterNewUser_ScanError(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -210,6 +228,26 @@ func TestUserRepository_GetUserByID_NotFound(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUserRepository_GetUserByEmail_IgnoresCase(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(db)
+
+	// Both sides are lowered so the stored casing of the address does not matter
+	mock.ExpectQuery(`SELECT \* FROM users WHERE LOWER\(email\) = LOWER\(\$1\) AND is_deleted = false;`).
+		WithArgs("Test@Example.COM").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "role_id"}).
+			AddRow(1, "testuser", "test@example.com", 1))
+
+	user, err := repo.GetUserByEmail(context.Background(), "Test@Example.COM")
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+	assert.Equal(t, "test@example.com", user.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_GetUserByID_DatabaseError(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -229,6 +267,27 @@ func TestUserRepository_GetUserByID_DatabaseError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUserRepository_SetUserActive(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(db)
+
+	// Deactivation stamps deactivated_at and skips deleted users
+	mock.ExpectExec(`UPDATE users SET is_active = \$1, deactivated_at = CASE WHEN \$1 THEN NULL ELSE NOW\(\) END, updated_at = NOW\(\)\s+WHERE id = \$2 AND is_deleted = false;`).
+		WithArgs(false, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE users SET is_active = \$1`).
+		WithArgs(true, 999).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.SetUserActive(context.Background(), 1, false))
+	err := repo.SetUserActive(context.Background(), 999, true)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_GetAllUsers_Success(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
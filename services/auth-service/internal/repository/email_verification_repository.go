@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrEmailVerificationTokenUsed is returned when marking a verification token used that was already used
+var ErrEmailVerificationTokenUsed = errors.New("email verification token already used")
+
+type IEmailVerificationRepository interface {
+	CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error
+	GetEmailVerificationToken(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error)
+	MarkEmailVerificationTokenUsed(ctx context.Context, id uint) error
+	InvalidateUserEmailVerificationTokens(ctx context.Context, userID uint) error
+}
+
+type emailVerificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewEmailVerificationRepository(db *sqlx.DB) IEmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// CreateEmailVerificationToken stores a new email verification token
+func (r *emailVerificationRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;
+	`
+
+	if err := r.db.QueryRowxContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt).Scan(&token.ID); err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailVerificationToken retrieves a verification token by its hash, whether or not it is used or expired
+func (r *emailVerificationRepository) GetEmailVerificationToken(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1;
+	`
+
+	var token domain.EmailVerificationToken
+	if err := r.db.GetContext(ctx, &token, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("email verification token not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get email verification token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// MarkEmailVerificationTokenUsed records that a verification token has been used. It
+// returns ErrEmailVerificationTokenUsed if the token was already used.
+func (r *emailVerificationRepository) MarkEmailVerificationTokenUsed(ctx context.Context, id uint) error {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verification token used: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrEmailVerificationTokenUsed
+	}
+
+	return nil
+}
+
+// InvalidateUserEmailVerificationTokens marks every outstanding verification token of a user used
+func (r *emailVerificationRepository) InvalidateUserEmailVerificationTokens(ctx context.Context, userID uint) error {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate email verification tokens: %w", err)
+	}
+
+	return nil
+}
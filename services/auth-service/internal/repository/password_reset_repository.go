@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrPasswordResetTokenUsed is returned when marking a reset token used that was already used
+var ErrPasswordResetTokenUsed = errors.New("password reset token already used")
+
+type IPasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(ctx context.Context, id uint) error
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID uint) error
+}
+
+type passwordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) IPasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (r *passwordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;
+	`
+
+	if err := r.db.QueryRowxContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt).Scan(&token.ID); err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// GetPasswordResetToken retrieves a reset token by its hash, whether or not it is used or expired
+func (r *passwordResetRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1;
+	`
+
+	var token domain.PasswordResetToken
+	if err := r.db.GetContext(ctx, &token, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("password reset token not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// MarkPasswordResetTokenUsed records that a reset token has been used. It returns
+// ErrPasswordResetTokenUsed if the token was already used, so each token resets a
+// password at most once even when presented concurrently.
+func (r *passwordResetRepository) MarkPasswordResetTokenUsed(ctx context.Context, id uint) error {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark password reset token used: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrPasswordResetTokenUsed
+	}
+
+	return nil
+}
+
+// InvalidateUserPasswordResetTokens marks every outstanding reset token of a user used
+func (r *passwordResetRepository) InvalidateUserPasswordResetTokens(ctx context.Context, userID uint) error {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
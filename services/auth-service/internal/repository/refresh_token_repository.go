@@ -3,17 +3,24 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrRefreshTokenReused is returned when a token being rotated was already revoked
+var ErrRefreshTokenReused = errors.New("refresh token already revoked")
+
 type IRefreshTokenRepository interface {
 	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
 	GetRefreshTokenByToken(ctx context.Context, refreshToken string) (*domain.RefreshToken, error)
+	FindRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldToken string, newToken *domain.RefreshToken) error
 	GetRefreshTokensByUserID(ctx context.Context, userID uint) ([]domain.RefreshToken, error)
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeUserRefreshToken(ctx context.Context, userID, tokenID uint) error
 	RevokeAllUserTokens(ctx context.Context, userID uint) error
 	CleanupExpiredTokens(ctx context.Context) error
 	DeleteRefreshToken(ctx context.Context, refreshToken string) error
@@ -70,6 +77,77 @@ func (r *refreshTokenRepository) GetRefreshTokenByToken(ctx context.Context, ref
 	return &token, nil
 }
 
+// FindRefreshToken retrieves a refresh token by its token string, even when it is
+// revoked or expired
+func (r *refreshTokenRepository) FindRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT * FROM refresh_tokens 
+		WHERE refresh_token = $1;
+	`
+
+	var token domain.RefreshToken
+	if err := r.db.GetContext(ctx, &token, query, refreshToken); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken stores newToken and revokes oldToken, recording newToken as its
+// replacement. It returns ErrRefreshTokenReused if oldToken was already revoked, which
+// happens when the same token is presented twice.
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, oldToken string, newToken *domain.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (
+			user_id, refresh_token, user_agent, ip_address, expires_at, created_at, is_revoked
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) RETURNING id;
+	`
+
+	if err := tx.QueryRowxContext(ctx, insertQuery,
+		newToken.UserID, newToken.RefreshToken, newToken.UserAgent, newToken.IPAddress,
+		newToken.ExpiresAt, newToken.CreatedAt, newToken.IsRevoked,
+	).Scan(&newToken.ID); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	revokeQuery := `
+		UPDATE refresh_tokens 
+		SET is_revoked = true, replaced_by = $1, last_used_at = NOW()
+		WHERE refresh_token = $2 AND is_revoked = false;
+	`
+
+	result, err := tx.ExecContext(ctx, revokeQuery, newToken.ID, oldToken)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // GetRefreshTokensByUserID retrieves all refresh tokens for a specific user
 func (r *refreshTokenRepository) GetRefreshTokensByUserID(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
 	query := `
@@ -111,6 +189,32 @@ func (r *refreshTokenRepository) RevokeRefreshToken(ctx context.Context, refresh
 	return nil
 }
 
+// RevokeUserRefreshToken revokes the active refresh token with tokenID if it belongs to
+// userID. A token of another user is reported as not found.
+func (r *refreshTokenRepository) RevokeUserRefreshToken(ctx context.Context, userID, tokenID uint) error {
+	query := `
+		UPDATE refresh_tokens 
+		SET is_revoked = true
+		WHERE id = $1 AND user_id = $2 AND is_revoked = false;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("refresh token not found: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
 // RevokeAllUserTokens revokes all refresh tokens for a specific user
 func (r *refreshTokenRepository) RevokeAllUserTokens(ctx context.Context, userID uint) error {
 	query := `
@@ -127,11 +231,12 @@ func (r *refreshTokenRepository) RevokeAllUserTokens(ctx context.Context, userID
 	return nil
 }
 
-// CleanupExpiredTokens removes expired tokens from the database
+// CleanupExpiredTokens removes expired tokens from the database. Revoked tokens are kept
+// until they expire so that a replayed rotated token is still recognised.
 func (r *refreshTokenRepository) CleanupExpiredTokens(ctx context.Context) error {
 	query := `
 		DELETE FROM refresh_tokens 
-		WHERE expires_at < NOW() OR is_revoked = true;
+		WHERE expires_at < NOW();
 	`
 
 	_, err := r.db.ExecContext(ctx, query)
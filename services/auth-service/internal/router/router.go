@@ -2,16 +2,25 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/handlers"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/middleware"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/lifecycle"
 )
 
-func NewRouter(authHandler handlers.IAuthHandler, authService services.IAuthService, roleHandler handlers.IRoleHandler) *chi.Mux {
+func NewRouter(authHandler handlers.IAuthHandler, authService services.IAuthService, accessTokenTTL time.Duration, roleHandler handlers.IRoleHandler, readiness *lifecycle.Readiness, limiter middleware.RateLimiter, clientIP middleware.ClientIPFunc, failedLogins *middleware.FailedLogins, body httpx.BodyPolicy) *chi.Mux {
 	router := chi.NewRouter()
 
+	// Global middleware
+	router.Use(httpx.RequestID)
+	router.Use(httpx.AccessLog)
+	router.Use(httpx.Recover)
+	router.Use(httpx.JSONBody(body))
+
 	// Global CORS middleware
 	router.Use(middleware.CORSMiddleware([]string{"*"}))
 
@@ -22,6 +31,13 @@ func NewRouter(authHandler handlers.IAuthHandler, authService services.IAuthServ
 		w.Write([]byte(`{"status":"ok","service":"auth-service"}`))
 	})
 
+	// Liveness endpoint, answers for as long as the process is up
+	router.Get("/healthz", lifecycle.LivenessHandler)
+
+	// Readiness endpoint, fails once shutdown has started or while the database or its
+	// migrations are not ready
+	router.Get("/readyz", readiness.Handler())
+
 	// Auth routes
 	router.Route("/api/v1/auth", func(r chi.Router) {
 		// Service health endpoint
@@ -32,28 +48,53 @@ func NewRouter(authHandler handlers.IAuthHandler, authService services.IAuthServ
 		})
 
 		// Public authentication routes (no auth required)
-		r.Post("/login", authHandler.Login)
 		r.Post("/register", authHandler.RegisterUser)
-		r.Post("/refresh", authHandler.RefreshToken)
+		r.Post("/verify-email", authHandler.VerifyEmail)
+
+		// Credential routes are rate limited per client IP to make credential stuffing and
+		// token guessing expensive. Login is also limited per username for IPs that have
+		// failed a login, so guesses from elsewhere cannot lock the user out.
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RateLimit(limiter, middleware.ClientIPKey(clientIP)))
+
+			r.With(
+				middleware.RateLimit(limiter, middleware.LoginUsernameKey(failedLogins, clientIP)),
+				middleware.RecordFailedLogins(failedLogins, clientIP),
+			).Post("/login", authHandler.Login)
+			r.Post("/refresh", authHandler.RefreshToken)
+			r.Post("/password-reset/request", authHandler.RequestPasswordReset)
+			r.Post("/password-reset/confirm", authHandler.ResetPassword)
+		})
 
 		// Protected routes (require authentication)
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(authService))
+			r.Use(middleware.AuthMiddleware(authService, accessTokenTTL))
 
 			// Authentication routes
 			r.Post("/logout", authHandler.Logout)
 
+			// Current user's profile
+			r.Get("/me", authHandler.GetMe)
+			r.Post("/verify-email/send", authHandler.SendVerificationEmail)
+			r.Post("/me/deactivate", authHandler.DeactivateMe)
+
 			// User management routes
 			r.Get("/user/{id}", authHandler.GetUserByID)
 			r.Put("/user/{id}", authHandler.UpdateUser)
-			r.Delete("/user/{id}", authHandler.DeleteUser)
 			r.Post("/user/{id}/change-password", authHandler.ChangePassword)
 			r.Post("/logout-all", authHandler.LogoutAll)
 
-			// Admin-only user listing + cleanup
+			// Current user's login sessions
+			r.Get("/sessions", authHandler.GetSessions)
+			r.Delete("/sessions/{id}", authHandler.RevokeSession)
+
+			// Admin-only user listing, account management + cleanup
 			r.Group(func(r chi.Router) {
 				r.Use(middleware.RequireAdmin())
 				r.Get("/users", authHandler.GetAllUsers)
+				r.Delete("/user/{id}", authHandler.DeleteUser)
+				r.Post("/user/{id}/deactivate", authHandler.DeactivateUser)
+				r.Post("/user/{id}/reactivate", authHandler.ReactivateUser)
 				r.Post("/cleanup-expired-tokens", authHandler.CleanupExpiredTokens)
 			})
 
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestUserService_DeactivateUser tests soft deactivation and reactivation of accounts
+func TestUserService_DeactivateUser(t *testing.T) {
+	// 🎯 Test Strategy: Drive the real auth service through deactivate and reactivate
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), MinBcryptCost)
+	require.NoError(t, err)
+
+	setup := func() (*MockUserRepository, *MockRefreshTokenRepository, IAuthService, IUserService, *domain.User) {
+		userRepo := &MockUserRepository{}
+		refreshTokenRepo := &MockRefreshTokenRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		authService := NewAuthService(userRepo, refreshTokenRepo, &MockRoleRepository{}, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+		userService := NewUserService(userRepo, authService, MinBcryptCost)
+
+		user := &domain.User{ID: 1, Username: "testuser", Password: string(hash), IsActive: true}
+		userRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil)
+		userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+		userRepo.On("SetUserActive", mock.Anything, 1, mock.AnythingOfType("bool")).
+			Run(func(args mock.Arguments) { user.IsActive = args.Bool(2) }).
+			Return(nil)
+		return userRepo, refreshTokenRepo, authService, userService, user
+	}
+
+	t.Run("should reject login while deactivated and allow it after reactivation", func(t *testing.T) {
+		// 🔧 Setup: Create services around an active user
+		userRepo, refreshTokenRepo, authService, userService, user := setup()
+
+		// 🎭 Mock Expectations: Deactivation ends every session
+		refreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(nil)
+		refreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Deactivate and try to log in
+		require.NoError(t, userService.DeactivateUser(context.Background(), 1))
+		loggedInUser, _, _, err := authService.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Login is refused and no session is created
+		assert.ErrorIs(t, err, ErrAccountDeactivated)
+		assert.Nil(t, loggedInUser)
+		assert.False(t, user.IsActive)
+		refreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+
+		// 🚀 Action: Reactivate and log in again
+		require.NoError(t, userService.ReactivateUser(context.Background(), 1))
+		loggedInUser, refreshToken, _, err := authService.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Login succeeds
+		require.NoError(t, err)
+		assert.Equal(t, user, loggedInUser)
+		assert.NotNil(t, refreshToken)
+		userRepo.AssertCalled(t, "SetUserActive", mock.Anything, 1, false)
+		userRepo.AssertCalled(t, "SetUserActive", mock.Anything, 1, true)
+		refreshTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject refreshing a session of a deactivated user", func(t *testing.T) {
+		// 🔧 Setup: Create services and a session issued before deactivation
+		_, refreshTokenRepo, authService, _, user := setup()
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		refreshToken, err := jwtService.GenerateRefreshToken(user)
+		require.NoError(t, err)
+		user.IsActive = false
+
+		// 🎭 Mock Expectations: The stored token is still valid
+		refreshTokenRepo.On("FindRefreshToken", mock.Anything, refreshToken.RefreshToken).Return(refreshToken, nil)
+
+		// 🚀 Action: Refresh token
+		_, newRefreshToken, _, err := authService.RefreshToken(context.Background(), refreshToken.RefreshToken)
+
+		// ✅ Assertions: No new session is issued
+		assert.ErrorIs(t, err, ErrAccountDeactivated)
+		assert.Nil(t, newRefreshToken)
+		refreshTokenRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not deactivate an unknown user", func(t *testing.T) {
+		// 🔧 Setup: Create service
+		userRepo := &MockUserRepository{}
+		mockAuthService := &MockAuthService{}
+		service := NewUserService(userRepo, mockAuthService, MinBcryptCost)
+
+		// 🎭 Mock Expectations: Repository should not find the user
+		userRepo.On("GetUserByID", mock.Anything, 999).Return(nil, errors.New("no user found with id 999"))
+
+		// 🚀 Action: Deactivate user
+		err := service.DeactivateUser(context.Background(), 999)
+
+		// ✅ Assertions: Should fail without touching the account or its sessions
+		assert.Error(t, err)
+		userRepo.AssertNotCalled(t, "SetUserActive", mock.Anything, mock.Anything, mock.Anything)
+		mockAuthService.AssertNotCalled(t, "LogoutAll", mock.Anything, mock.Anything)
+	})
+}
@@ -2,21 +2,42 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/validation"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrEmailNotVerified is returned by Login when email verification is required and the
+// user has not verified their email yet
+var ErrEmailNotVerified = errors.New("email not verified")
+
+// ErrAccountDeactivated is returned by Login and RefreshToken when the user's account
+// has been deactivated
+var ErrAccountDeactivated = errors.New("account deactivated")
+
+// ErrTokenReuseDetected is returned when a refresh token that was already rotated is
+// presented again. Every refresh token of its user has been revoked.
+var ErrTokenReuseDetected = errors.New("refresh token reuse detected")
+
 type IAuthService interface {
-	Login(ctx context.Context, username, password, userAgent, ipAddress string) (*domain.User, *domain.RefreshToken, string, error)
+	Login(ctx context.Context, identifier, password, userAgent, ipAddress string) (*domain.User, *domain.RefreshToken, string, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*domain.User, *domain.RefreshToken, string, error)
 	Logout(ctx context.Context, refreshToken string) error
 	LogoutAll(ctx context.Context, userID uint) error
+	GetSessions(ctx context.Context, userID uint) ([]domain.RefreshToken, error)
+	RevokeSession(ctx context.Context, userID, sessionID uint) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	SendVerificationEmail(ctx context.Context, userID uint) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
 	ValidateAccessToken(ctx context.Context, tokenString string) (*Claims, error)
 	ValidateRefreshToken(ctx context.Context, refreshTokenString string) (*RefreshTokenClaims, error)
 	GetUserFromToken(ctx context.Context, tokenString string) (*domain.User, error)
@@ -24,37 +45,71 @@ type IAuthService interface {
 	CleanupExpiredTokens(ctx context.Context) error
 }
 
+// AuthPolicy configures how the auth service treats passwords and logins
+type AuthPolicy struct {
+	// BcryptCost is the cost new password hashes are made with. Stored hashes below it
+	// are rehashed on login; costs below MinBcryptCost are raised to it.
+	BcryptCost int
+	// RequireEmailVerification blocks login until the user has verified their email
+	RequireEmailVerification bool
+}
+
 type authService struct {
-	userRepo         repository.IUserRepository
-	refreshTokenRepo repository.IRefreshTokenRepository
-	roleRepo         repository.IRoleRepository
-	jwtService       *JWTService
+	userRepo              repository.IUserRepository
+	refreshTokenRepo      repository.IRefreshTokenRepository
+	roleRepo              repository.IRoleRepository
+	passwordResetRepo     repository.IPasswordResetRepository
+	emailVerificationRepo repository.IEmailVerificationRepository
+	jwtService            *JWTService
+	mailer                AuthMailer
+	bcryptCost            int
+	requireEmailVerified  bool
 }
 
-func NewAuthService(userRepo repository.IUserRepository, refreshTokenRepo repository.IRefreshTokenRepository, roleRepo repository.IRoleRepository, jwtService *JWTService) IAuthService {
+func NewAuthService(userRepo repository.IUserRepository, refreshTokenRepo repository.IRefreshTokenRepository, roleRepo repository.IRoleRepository, passwordResetRepo repository.IPasswordResetRepository, emailVerificationRepo repository.IEmailVerificationRepository, jwtService *JWTService, mailer AuthMailer, policy AuthPolicy) IAuthService {
 	return &authService{
-		userRepo:         userRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		roleRepo:         roleRepo,
-		jwtService:       jwtService,
+		userRepo:              userRepo,
+		refreshTokenRepo:      refreshTokenRepo,
+		roleRepo:              roleRepo,
+		passwordResetRepo:     passwordResetRepo,
+		emailVerificationRepo: emailVerificationRepo,
+		jwtService:            jwtService,
+		mailer:                mailer,
+		bcryptCost:            passwordCost(policy.BcryptCost),
+		requireEmailVerified:  policy.RequireEmailVerification,
 	}
 }
 
-// Login authenticates a user and generates access and refresh tokens
-func (a *authService) Login(ctx context.Context, username, password, userAgent, ipAddress string) (*domain.User, *domain.RefreshToken, string, error) {
-	// Get user by username
-	user, err := a.userRepo.GetUserByUsername(ctx, username)
+// Login authenticates a user by username or email address and generates access and
+// refresh tokens. An unknown user and a wrong password fail with the same error.
+func (a *authService) Login(ctx context.Context, identifier, password, userAgent, ipAddress string) (*domain.User, *domain.RefreshToken, string, error) {
+	user, err := a.getLoginUser(ctx, identifier)
 	if err != nil {
-		log.Println("Invalid credentials", err)
+		logger.FromContext(ctx).Info("login failed: unknown user", "identifier", identifier, "error", err)
 		return nil, nil, "", fmt.Errorf("invalid credentials")
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		log.Println("Invalid credentials", err)
+		logger.FromContext(ctx).Info("login failed: wrong password", "user_id", user.ID)
 		return nil, nil, "", fmt.Errorf("invalid credentials")
 	}
 
+	if a.requireEmailVerified && !user.IsEmailVerified {
+		logger.FromContext(ctx).Info("login failed: email not verified", "user_id", user.ID)
+		return nil, nil, "", ErrEmailNotVerified
+	}
+
+	if !user.IsActive {
+		logger.FromContext(ctx).Info("login failed: account deactivated", "user_id", user.ID)
+		return nil, nil, "", ErrAccountDeactivated
+	}
+
+	// Upgrade hashes made with an older, cheaper cost while we have the plaintext
+	if needsRehash(user.Password, a.bcryptCost) {
+		a.rehashPassword(ctx, user, password)
+	}
+
 	// Generate access token (stored in cookie by handler)
 	accessToken, err := a.jwtService.GenerateAccessToken(user)
 	if err != nil {
@@ -79,6 +134,32 @@ func (a *authService) Login(ctx context.Context, username, password, userAgent,
 	return user, refreshToken, accessToken, nil
 }
 
+// getLoginUser looks up the user logging in. Identifiers that are valid email addresses
+// are matched against emails, ignoring case, and anything else against usernames.
+func (a *authService) getLoginUser(ctx context.Context, identifier string) (*domain.User, error) {
+	if validation.ValidateEmail(identifier) == nil {
+		return a.userRepo.GetUserByEmail(ctx, identifier)
+	}
+	return a.userRepo.GetUserByUsername(ctx, identifier)
+}
+
+// rehashPassword stores a new hash of password at the configured cost.
+// Failures are logged and do not fail the login; the upgrade is retried next time.
+func (a *authService) rehashPassword(ctx context.Context, user *domain.User, password string) {
+	hash, err := hashPassword(password, a.bcryptCost)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+
+	previous := user.Password
+	user.Password = hash
+	if err := a.userRepo.UpdateUser(ctx, int(user.ID), user); err != nil {
+		logger.FromContext(ctx).Warn("failed to store rehashed password", "user_id", user.ID, "error", err)
+		user.Password = previous
+	}
+}
+
 // RefreshToken validates a refresh token and generates new access and refresh tokens
 func (a *authService) RefreshToken(ctx context.Context, refreshTokenString string) (*domain.User, *domain.RefreshToken, string, error) {
 	// Validate refresh token JWT
@@ -87,8 +168,8 @@ func (a *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 		return nil, nil, "", fmt.Errorf("invalid refresh token: %w", err)
 	}
 
-	// Get refresh token from database
-	dbToken, err := a.refreshTokenRepo.GetRefreshTokenByToken(ctx, refreshTokenString)
+	// Get refresh token from database, including revoked ones so a replay can be caught
+	dbToken, err := a.refreshTokenRepo.FindRefreshToken(ctx, refreshTokenString)
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("refresh token not found or expired: %w", err)
 	}
@@ -98,12 +179,26 @@ func (a *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 		return nil, nil, "", fmt.Errorf("token mismatch")
 	}
 
+	// A rotated token presented again may have been stolen, since its rightful holder
+	// already has the replacement, so end every session of the user
+	if dbToken.IsRevoked && dbToken.ReplacedBy != nil {
+		return nil, nil, "", a.revokeReusedToken(ctx, dbToken.UserID)
+	}
+
+	if dbToken.IsRevoked || !dbToken.ExpiresAt.After(time.Now()) {
+		return nil, nil, "", fmt.Errorf("refresh token not found or expired")
+	}
+
 	// Get user details
 	user, err := a.userRepo.GetUserByID(ctx, int(claims.UserID))
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("user not found: %w", err)
 	}
 
+	if !user.IsActive {
+		return nil, nil, "", ErrAccountDeactivated
+	}
+
 	roleName, ok := domain.RoleNames[int(user.RoleID)]
 	if !ok {
 		user.RoleID, user.Role = domain.GetDefaultRole()
@@ -111,11 +206,6 @@ func (a *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 		user.Role = roleName
 	}
 	
-	// Revoke old refresh token
-	if err := a.refreshTokenRepo.RevokeRefreshToken(ctx, refreshTokenString); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to revoke old token: %w", err)
-	}
-
 	// Generate new access token (stored in cookie by handler)
 	accessToken, err := a.jwtService.GenerateAccessToken(user)
 	if err != nil {
@@ -132,14 +222,26 @@ func (a *authService) RefreshToken(ctx context.Context, refreshTokenString strin
 	newRefreshToken.UserAgent = dbToken.UserAgent
 	newRefreshToken.IPAddress = dbToken.IPAddress
 
-	// Store new refresh token in database
-	if err := a.refreshTokenRepo.CreateRefreshToken(ctx, newRefreshToken); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to store new refresh token: %w", err)
+	// Store new refresh token and revoke the old one in its favour
+	if err := a.refreshTokenRepo.RotateRefreshToken(ctx, refreshTokenString, newRefreshToken); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			return nil, nil, "", a.revokeReusedToken(ctx, dbToken.UserID)
+		}
+		return nil, nil, "", fmt.Errorf("failed to rotate refresh token: %w", err)
 	}
 
 	return user, newRefreshToken, accessToken, nil
 }
 
+// revokeReusedToken revokes every refresh token of a user whose rotated token was
+// presented again and returns ErrTokenReuseDetected
+func (a *authService) revokeReusedToken(ctx context.Context, userID uint) error {
+	if err := a.refreshTokenRepo.RevokeAllUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("%w: failed to revoke user tokens: %v", ErrTokenReuseDetected, err)
+	}
+	return ErrTokenReuseDetected
+}
+
 // Logout revokes a specific refresh token
 func (a *authService) Logout(ctx context.Context, refreshToken string) error {
 	return a.refreshTokenRepo.RevokeRefreshToken(ctx, refreshToken)
@@ -150,6 +252,16 @@ func (a *authService) LogoutAll(ctx context.Context, userID uint) error {
 	return a.refreshTokenRepo.RevokeAllUserTokens(ctx, userID)
 }
 
+// GetSessions returns the user's active refresh tokens, newest first
+func (a *authService) GetSessions(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
+	return a.refreshTokenRepo.GetRefreshTokensByUserID(ctx, userID)
+}
+
+// RevokeSession revokes one of the user's refresh tokens by its ID
+func (a *authService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	return a.refreshTokenRepo.RevokeUserRefreshToken(ctx, userID, sessionID)
+}
+
 // ValidateAccessToken validates an access token and returns claims
 func (a *authService) ValidateAccessToken(ctx context.Context, tokenString string) (*Claims, error) {
 	return a.jwtService.ValidateAccessToken(tokenString)
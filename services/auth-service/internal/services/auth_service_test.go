@@ -2,11 +2,13 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -61,6 +63,39 @@ func (m *MockAuthService) LogoutAll(ctx context.Context, userID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthService) GetSessions(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) SendVerificationEmail(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*Claims, error) {
 	args := m.Called(ctx, tokenString)
 	if args.Get(0) == nil {
@@ -120,6 +155,15 @@ func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username str
 }
 
 // GetAllUsers mocks the GetAllUsers method
+// GetUserByEmail mocks the GetUserByEmail method
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetAllUsers(ctx context.Context, limit int, offset int) ([]domain.User, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -134,6 +178,17 @@ func (m *MockUserRepository) UpdateUser(ctx context.Context, id int, u *domain.U
 	return args.Error(0)
 }
 
+// MarkEmailVerified mocks the MarkEmailVerified method
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetUserActive(ctx context.Context, id int, active bool) error {
+	args := m.Called(ctx, id, active)
+	return args.Error(0)
+}
+
 // DeleteUser mocks the DeleteUser method
 func (m *MockUserRepository) DeleteUser(ctx context.Context, id int) error {
 	args := m.Called(ctx, id)
@@ -164,6 +219,21 @@ func (m *MockRefreshTokenRepository) GetRefreshTokenByToken(ctx context.Context,
 	return args.Get(0).(*domain.RefreshToken), args.Error(1)
 }
 
+// FindRefreshToken mocks the FindRefreshToken method
+func (m *MockRefreshTokenRepository) FindRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+// RotateRefreshToken mocks the RotateRefreshToken method
+func (m *MockRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldToken string, newToken *domain.RefreshToken) error {
+	args := m.Called(ctx, oldToken, newToken)
+	return args.Error(0)
+}
+
 // GetRefreshTokensByUserID mocks the GetRefreshTokensByUserID method
 func (m *MockRefreshTokenRepository) GetRefreshTokensByUserID(ctx context.Context, userID uint) ([]domain.RefreshToken, error) {
 	args := m.Called(ctx, userID)
@@ -179,6 +249,12 @@ func (m *MockRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, ref
 	return args.Error(0)
 }
 
+// RevokeUserRefreshToken mocks the RevokeUserRefreshToken method
+func (m *MockRefreshTokenRepository) RevokeUserRefreshToken(ctx context.Context, userID, tokenID uint) error {
+	args := m.Called(ctx, userID, tokenID)
+	return args.Error(0)
+}
+
 // RevokeAllUserTokens mocks the RevokeAllUserTokens method
 func (m *MockRefreshTokenRepository) RevokeAllUserTokens(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
@@ -274,7 +350,7 @@ func TestUserService(t *testing.T) {
 		mockRepo := &MockUserRepository{}
 
 		// 🚀 Action: Create service
-		service := NewUserService(mockRepo, nil)
+		service := NewUserService(mockRepo, nil, MinBcryptCost)
 
 		// ✅ Assertions: Service should be created
 		assert.NotNil(t, service)
@@ -292,7 +368,7 @@ func TestUserService_RegisterNewUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create test user
 		user := &domain.User{
@@ -324,11 +400,46 @@ func TestUserService_RegisterNewUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should hash at the configured cost", func(t *testing.T) {
+		// 🔧 Setup: Create service with a cost above the default
+		mockRepo := &MockUserRepository{}
+		service := NewUserService(mockRepo, nil, 12)
+
+		user := &domain.User{Username: "john_doe", Password: "SecurePass123", Email: "john@example.com"}
+		mockRepo.On("RegisterNewUser", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Register user
+		require.NoError(t, service.RegisterNewUser(context.Background(), user))
+
+		// ✅ Assertions: Stored hash encodes cost 12
+		cost, err := bcrypt.Cost([]byte(user.Password))
+		require.NoError(t, err)
+		assert.Equal(t, 12, cost)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("SecurePass123")))
+	})
+
+	t.Run("should not hash below the minimum cost", func(t *testing.T) {
+		// 🔧 Setup: Create service with a cost below the floor
+		mockRepo := &MockUserRepository{}
+		service := NewUserService(mockRepo, nil, bcrypt.MinCost)
+
+		user := &domain.User{Username: "john_doe", Password: "SecurePass123", Email: "john@example.com"}
+		mockRepo.On("RegisterNewUser", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Register user
+		require.NoError(t, service.RegisterNewUser(context.Background(), user))
+
+		// ✅ Assertions: Cost was raised to the floor
+		cost, err := bcrypt.Cost([]byte(user.Password))
+		require.NoError(t, err)
+		assert.Equal(t, MinBcryptCost, cost)
+	})
+
 	t.Run("should handle repository error", func(t *testing.T) {
 		// 🔧 Setup: Create mock repository that returns error
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		user := &domain.User{
 			Username: "john_doe",
@@ -351,11 +462,29 @@ func TestUserService_RegisterNewUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should reject a case variant of an existing email", func(t *testing.T) {
+		// 🔧 Setup: alice@example.com is already registered
+		mockRepo := &MockUserRepository{}
+		service := NewUserService(mockRepo, nil, MinBcryptCost)
+
+		user := &domain.User{Username: "alice2", Password: "SecurePass123", Email: " Alice@Example.COM "}
+		mockRepo.On("RegisterNewUser", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.Email == "alice@example.com"
+		})).Return(repository.ErrEmailTaken)
+
+		// 🚀 Action: Register user
+		err := service.RegisterNewUser(context.Background(), user)
+
+		// ✅ Assertions: The lower-cased email clashes
+		assert.ErrorIs(t, err, ErrEmailTaken)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should hash password with different costs", func(t *testing.T) {
 		// 🔧 Setup: Create mock repository
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		user1 := &domain.User{
 			Username: "user1",
@@ -398,7 +527,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		expectedUser := &domain.User{
 			ID:        1,
@@ -426,7 +555,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 		// 🔧 Setup: Create mock repository that returns error
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should return error
 		expectedError := errors.New("user not found")
@@ -453,7 +582,7 @@ func TestUserService_GetAllUsers(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		expectedUsers := []domain.User{
 			{ID: 1, Username: "john_doe", Email: "john@example.com"},
@@ -479,7 +608,7 @@ func TestUserService_GetAllUsers(t *testing.T) {
 		// 🔧 Setup: Create mock repository that returns error
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should return error
 		expectedError := errors.New("database connection failed")
@@ -506,7 +635,7 @@ func TestUserService_Context(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create a context with a specific value
 		ctx := context.WithValue(context.Background(), "user_id", "123")
@@ -536,7 +665,7 @@ func TestUserService_PasswordHashing(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		plainPassword := "MySecurePassword123"
 		user := &domain.User{
@@ -569,7 +698,7 @@ func TestUserService_PasswordHashing(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create user with extremely long password that might cause bcrypt issues
 		// (This is a theoretical test - bcrypt is very robust)
@@ -603,7 +732,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create existing user
 		existingUser := &domain.User{
@@ -649,7 +778,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should return error
 		mockRepo.On("GetUserByID", mock.Anything, 999).Return(nil, errors.New("user not found"))
@@ -670,7 +799,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create existing user
 		existingUser := &domain.User{
@@ -706,7 +835,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create existing user with hashed password
 		hashedOldPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.DefaultCost)
@@ -740,7 +869,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should return error
 		mockRepo.On("GetUserByID", mock.Anything, 999).Return(nil, errors.New("user not found"))
@@ -760,7 +889,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create existing user with hashed password
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct_password"), bcrypt.DefaultCost)
@@ -789,7 +918,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// Create existing user with hashed password
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.DefaultCost)
@@ -826,7 +955,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should check if user exists and then delete
 		mockRepo.On("GetUserByID", mock.Anything, 1).Return(&domain.User{ID: 1}, nil)
@@ -846,7 +975,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// 🔧 Setup: Create mock repository and service
 		mockRepo := &MockUserRepository{}
 		mockAuthService := &MockAuthService{}
-		service := NewUserService(mockRepo, mockAuthService)
+		service := NewUserService(mockRepo, mockAuthService, MinBcryptCost)
 
 		// 🎭 Mock Expectations: Repository should check if user exists and then return error
 		mockRepo.On("GetUserByID", mock.Anything, 999).Return(&domain.User{ID: 999}, nil)
@@ -872,11 +1001,11 @@ func TestAuthService(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and JWT service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 		// 🚀 Action: Create service
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// ✅ Assertions: Service should be created
 		assert.NotNil(t, service)
@@ -895,8 +1024,8 @@ func TestAuthService_Login(t *testing.T) {
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
 		mockRoleRepo := &MockRoleRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user with hashed password
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
@@ -905,6 +1034,7 @@ func TestAuthService_Login(t *testing.T) {
 			Username: "testuser",
 			Password: string(hashedPassword),
 			Email:    "test@example.com",
+			IsActive: true,
 		}
 
 		// 🎭 Mock Expectations: Repository should return user
@@ -935,9 +1065,9 @@ func TestAuthService_Login(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🎭 Mock Expectations: Repository should return error
 		mockUserRepo.On("GetUserByUsername", mock.Anything, "nonexistent").Return(nil, errors.New("user not found"))
@@ -955,13 +1085,67 @@ func TestAuthService_Login(t *testing.T) {
 		mockUserRepo.AssertExpectations(t)
 	})
 
+	t.Run("should login user by email", func(t *testing.T) {
+		// 🔧 Setup: Create mock repositories and service
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		mockRoleRepo := &MockRoleRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), MinBcryptCost)
+		user := &domain.User{
+			ID:       1,
+			Username: "testuser",
+			Password: string(hashedPassword),
+			Email:    "test@example.com",
+			IsActive: true,
+		}
+
+		// 🎭 Mock Expectations: the email is looked up as typed; the repository ignores case
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "Test@Example.com").Return(user, nil)
+		mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Login with the email address
+		loggedInUser, refreshToken, _, err := service.Login(context.Background(), "Test@Example.com", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Should succeed without a username lookup
+		assert.NoError(t, err)
+		assert.Equal(t, user, loggedInUser)
+		assert.NotNil(t, refreshToken)
+		mockUserRepo.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("should fail with unknown email like an unknown username", func(t *testing.T) {
+		// 🔧 Setup: Create mock repositories and service
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		mockRoleRepo := &MockRoleRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		// 🎭 Mock Expectations: No account has the email
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(nil, sql.ErrNoRows)
+
+		// 🚀 Action: Login with the unknown email
+		user, refreshToken, _, err := service.Login(context.Background(), "nobody@example.com", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Should fail with the generic error
+		assert.EqualError(t, err, "invalid credentials")
+		assert.Nil(t, user)
+		assert.Nil(t, refreshToken)
+		mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+		mockUserRepo.AssertExpectations(t)
+	})
+
 	t.Run("should fail with wrong password", func(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user with hashed password
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.DefaultCost)
@@ -987,6 +1171,85 @@ func TestAuthService_Login(t *testing.T) {
 		// Verify repositories were called correctly
 		mockUserRepo.AssertExpectations(t)
 	})
+
+	t.Run("should upgrade a low-cost hash on login", func(t *testing.T) {
+		// 🔧 Setup: Target cost is above the stored hash's cost
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		mockRoleRepo := &MockRoleRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		oldHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		user := &domain.User{ID: 1, Username: "testuser", IsActive: true, Password: string(oldHash)}
+
+		// 🎭 Mock Expectations: The user is saved with a new hash at the target cost
+		var storedHash string
+		mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+		mockUserRepo.On("UpdateUser", mock.Anything, 1, mock.MatchedBy(func(u *domain.User) bool {
+			storedHash = u.Password
+			return true
+		})).Return(nil)
+		mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Login user
+		_, _, _, err := service.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: New hash has the target cost and still verifies
+		require.NoError(t, err)
+		cost, err := bcrypt.Cost([]byte(storedHash))
+		require.NoError(t, err)
+		assert.Equal(t, MinBcryptCost, cost)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(storedHash), []byte("password123")))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not rehash a hash at the target cost", func(t *testing.T) {
+		// 🔧 Setup: Stored hash already matches the target cost
+
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		mockRoleRepo := &MockRoleRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), MinBcryptCost)
+		user := &domain.User{ID: 1, Username: "testuser", IsActive: true, Password: string(hash)}
+
+		mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+		mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Login user
+		_, _, _, err := service.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Password is left untouched
+		require.NoError(t, err)
+		mockUserRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should still login when storing the rehash fails", func(t *testing.T) {
+		// 🔧 Setup: Repository update fails
+
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		mockRoleRepo := &MockRoleRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		oldHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		user := &domain.User{ID: 1, Username: "testuser", IsActive: true, Password: string(oldHash)}
+
+		mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+		mockUserRepo.On("UpdateUser", mock.Anything, 1, mock.Anything).Return(errors.New("db down"))
+		mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+		// 🚀 Action: Login user
+		loggedInUser, _, _, err := service.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+		// ✅ Assertions: Login succeeds and the in-memory hash is restored
+		require.NoError(t, err)
+		assert.Equal(t, string(oldHash), loggedInUser.Password)
+	})
 }
 
 // TestAuthService_RefreshToken tests token refresh functionality
@@ -997,15 +1260,16 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user
 		user := &domain.User{
 			ID:       1,
 			Username: "testuser",
 			Email:    "test@example.com",
+			IsActive: true,
 		}
 
 		// Generate refresh token
@@ -1013,16 +1277,13 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		require.NoError(t, err)
 
 		// 🎭 Mock Expectations: Refresh token repository should return token
-		mockRefreshTokenRepo.On("GetRefreshTokenByToken", mock.Anything, refreshToken.RefreshToken).Return(refreshToken, nil)
+		mockRefreshTokenRepo.On("FindRefreshToken", mock.Anything, refreshToken.RefreshToken).Return(refreshToken, nil)
 
 		// Mock Expectations: User repository should return user
 		mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil)
 
-		// Mock Expectations: Old token should be revoked
-		mockRefreshTokenRepo.On("RevokeRefreshToken", mock.Anything, refreshToken.RefreshToken).Return(nil)
-
-		// Mock Expectations: New refresh token should be stored
-		mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(token *domain.RefreshToken) bool {
+		// Mock Expectations: Old token should be replaced by the new one
+		mockRefreshTokenRepo.On("RotateRefreshToken", mock.Anything, refreshToken.RefreshToken, mock.MatchedBy(func(token *domain.RefreshToken) bool {
 			return token.UserID == user.ID && !token.IsRevoked
 		})).Return(nil)
 
@@ -1044,9 +1305,9 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🚀 Action: Refresh with invalid token
 		user, refreshToken, _, err := service.RefreshToken(context.Background(), "invalid-token")
@@ -1059,7 +1320,99 @@ func TestAuthService_RefreshToken(t *testing.T) {
 
 		// Verify no repositories were called
 		mockUserRepo.AssertNotCalled(t, "GetUserByID")
-		mockRefreshTokenRepo.AssertNotCalled(t, "GetRefreshTokenByToken")
+		mockRefreshTokenRepo.AssertNotCalled(t, "FindRefreshToken")
+	})
+
+	t.Run("should revoke all user tokens when a rotated token is replayed", func(t *testing.T) {
+		// 🔧 Setup: Create mock repositories and service
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		mockRoleRepo := &MockRoleRepository{}
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
+		oldToken, err := jwtService.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		// 🎭 Mock Expectations: First refresh rotates the token
+		mockRefreshTokenRepo.On("FindRefreshToken", mock.Anything, oldToken.RefreshToken).Return(oldToken, nil).Once()
+		mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil).Once()
+		mockRefreshTokenRepo.On("RotateRefreshToken", mock.Anything, oldToken.RefreshToken, mock.Anything).Return(nil).Once()
+
+		_, _, _, err = service.RefreshToken(context.Background(), oldToken.RefreshToken)
+		require.NoError(t, err)
+
+		// Mock Expectations: The old token is now revoked and points at its replacement
+		replacedBy := uint(2)
+		rotated := *oldToken
+		rotated.IsRevoked = true
+		rotated.ReplacedBy = &replacedBy
+		mockRefreshTokenRepo.On("FindRefreshToken", mock.Anything, oldToken.RefreshToken).Return(&rotated, nil).Once()
+		mockRefreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, user.ID).Return(nil).Once()
+
+		// 🚀 Action: Replay the old token
+		replayedUser, replayedToken, accessToken, err := service.RefreshToken(context.Background(), oldToken.RefreshToken)
+
+		// ✅ Assertions: Replay should be rejected and every session revoked
+		assert.ErrorIs(t, err, ErrTokenReuseDetected)
+		assert.Nil(t, replayedUser)
+		assert.Nil(t, replayedToken)
+		assert.Empty(t, accessToken)
+
+		mockUserRepo.AssertExpectations(t)
+		mockRefreshTokenRepo.AssertExpectations(t)
+		mockRefreshTokenRepo.AssertNumberOfCalls(t, "RotateRefreshToken", 1)
+	})
+
+	t.Run("should revoke all user tokens when a rotation races a replay", func(t *testing.T) {
+		// 🔧 Setup: Create mock repositories and service
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		mockRoleRepo := &MockRoleRepository{}
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
+		oldToken, err := jwtService.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		// 🎭 Mock Expectations: Another request rotated the token after it was read
+		mockRefreshTokenRepo.On("FindRefreshToken", mock.Anything, oldToken.RefreshToken).Return(oldToken, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil)
+		mockRefreshTokenRepo.On("RotateRefreshToken", mock.Anything, oldToken.RefreshToken, mock.Anything).Return(repository.ErrRefreshTokenReused)
+		mockRefreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, user.ID).Return(nil)
+
+		// 🚀 Action: Refresh token
+		_, _, _, err = service.RefreshToken(context.Background(), oldToken.RefreshToken)
+
+		// ✅ Assertions: Should be treated as reuse
+		assert.ErrorIs(t, err, ErrTokenReuseDetected)
+		mockRefreshTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a logged out token without revoking other sessions", func(t *testing.T) {
+		// 🔧 Setup: Create mock repositories and service
+		mockUserRepo := &MockUserRepository{}
+		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		mockRoleRepo := &MockRoleRepository{}
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
+		token, err := jwtService.GenerateRefreshToken(user)
+		require.NoError(t, err)
+		token.IsRevoked = true
+
+		mockRefreshTokenRepo.On("FindRefreshToken", mock.Anything, token.RefreshToken).Return(token, nil)
+
+		// 🚀 Action: Refresh with a logged out token
+		_, _, _, err = service.RefreshToken(context.Background(), token.RefreshToken)
+
+		// ✅ Assertions: Should fail as a plain invalid token
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrTokenReuseDetected)
+		mockRefreshTokenRepo.AssertNotCalled(t, "RevokeAllUserTokens", mock.Anything, mock.Anything)
 	})
 }
 
@@ -1071,9 +1424,9 @@ func TestAuthService_Logout(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🎭 Mock Expectations: Refresh token should be revoked
 		mockRefreshTokenRepo.On("RevokeRefreshToken", mock.Anything, "test-refresh-token").Return(nil)
@@ -1092,9 +1445,9 @@ func TestAuthService_Logout(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🎭 Mock Expectations: Repository should return error
 		mockRefreshTokenRepo.On("RevokeRefreshToken", mock.Anything, "test-refresh-token").Return(errors.New("database error"))
@@ -1119,9 +1472,9 @@ func TestAuthService_LogoutAll(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🎭 Mock Expectations: All user tokens should be revoked
 		mockRefreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(nil)
@@ -1140,9 +1493,9 @@ func TestAuthService_LogoutAll(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🎭 Mock Expectations: Repository should return error
 		mockRefreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(errors.New("database error"))
@@ -1167,12 +1520,12 @@ func TestAuthService_ValidateAccessToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user and generate token
-		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
 		token, err := jwtService.GenerateAccessToken(user)
 		require.NoError(t, err)
 
@@ -1191,9 +1544,9 @@ func TestAuthService_ValidateAccessToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🚀 Action: Validate invalid token
 		claims, err := service.ValidateAccessToken(context.Background(), "invalid-token")
@@ -1213,12 +1566,12 @@ func TestAuthService_GetUserFromToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user and generate token
-		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
 		token, err := jwtService.GenerateAccessToken(user)
 		require.NoError(t, err)
 
@@ -1243,9 +1596,9 @@ func TestAuthService_GetUserFromToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// 🚀 Action: Get user from invalid token
 		user, err := service.GetUserFromToken(context.Background(), "invalid-token")
@@ -1263,12 +1616,12 @@ func TestAuthService_GetUserFromToken(t *testing.T) {
 		// 🔧 Setup: Create mock repositories and service
 		mockUserRepo := &MockUserRepository{}
 		mockRefreshTokenRepo := &MockRefreshTokenRepository{}
-		jwtService := NewJWTService("test-secret", "test-refresh-secret")
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		mockRoleRepo := &MockRoleRepository{}
-		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, jwtService)
+		service := NewAuthService(mockUserRepo, mockRefreshTokenRepo, mockRoleRepo, nil, nil, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
 
 		// Create test user and generate token
-		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", IsActive: true}
 		token, err := jwtService.GenerateAccessToken(user)
 		require.NoError(t, err)
 
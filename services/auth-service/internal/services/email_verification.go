@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
+)
+
+// emailVerificationTTL is how long an email verification token can be used after it is issued
+const emailVerificationTTL = 24 * time.Hour
+
+var (
+	// ErrEmailAlreadyVerified is returned when requesting verification of a verified email
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	// ErrEmailVerificationTokenInvalid is returned for a verification token that was never issued
+	ErrEmailVerificationTokenInvalid = errors.New("invalid email verification token")
+	// ErrEmailVerificationTokenExpired is returned for a verification token past its expiry
+	ErrEmailVerificationTokenExpired = errors.New("email verification token expired")
+	// ErrEmailVerificationTokenUsed is returned for a verification token that was already
+	// used or was superseded by a newer one
+	ErrEmailVerificationTokenUsed = errors.New("email verification token already used")
+)
+
+// SendVerificationEmail issues a verification token for the user's email address and
+// sends it to them, invalidating any token issued before
+func (a *authService) SendVerificationEmail(ctx context.Context, userID uint) error {
+	user, err := a.userRepo.GetUserByID(ctx, int(userID))
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if user.IsEmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate email verification token: %w", err)
+	}
+
+	if err := a.emailVerificationRepo.InvalidateUserEmailVerificationTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := a.emailVerificationRepo.CreateEmailVerificationToken(ctx, &domain.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(emailVerificationTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	if err := a.mailer.SendEmailVerification(ctx, user, token); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the email of the user a verification token was issued to as
+// verified and uses up the token
+func (a *authService) VerifyEmail(ctx context.Context, token string) error {
+	verificationToken, err := a.emailVerificationRepo.GetEmailVerificationToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEmailVerificationTokenInvalid
+		}
+		return err
+	}
+
+	if verificationToken.UsedAt != nil {
+		return ErrEmailVerificationTokenUsed
+	}
+	if !verificationToken.ExpiresAt.After(time.Now()) {
+		return ErrEmailVerificationTokenExpired
+	}
+
+	if err := a.emailVerificationRepo.MarkEmailVerificationTokenUsed(ctx, verificationToken.ID); err != nil {
+		if errors.Is(err, repository.ErrEmailVerificationTokenUsed) {
+			return ErrEmailVerificationTokenUsed
+		}
+		return err
+	}
+
+	if err := a.userRepo.MarkEmailVerified(ctx, verificationToken.UserID); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockEmailVerificationRepository is a mock implementation of IEmailVerificationRepository
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) GetEmailVerificationToken(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) MarkEmailVerificationTokenUsed(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) InvalidateUserEmailVerificationTokens(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// TestAuthService_VerifyEmail tests sending and confirming email verification tokens
+func TestAuthService_VerifyEmail(t *testing.T) {
+	// 🎯 Test Strategy: Round-trip a token from the sent email back into VerifyEmail
+
+	t.Run("should verify the email with the sent token", func(t *testing.T) {
+		// 🔧 Setup: Create service with an unverified user
+		userRepo := &MockUserRepository{}
+		verificationRepo := &MockEmailVerificationRepository{}
+		mailer := &MockAuthMailer{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(userRepo, &MockRefreshTokenRepository{}, &MockRoleRepository{}, nil, verificationRepo, jwtService, mailer, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+
+		// 🎭 Mock Expectations: A hashed token is stored and the raw one emailed
+		var stored *domain.EmailVerificationToken
+		var sent string
+		userRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil)
+		verificationRepo.On("InvalidateUserEmailVerificationTokens", mock.Anything, uint(1)).Return(nil)
+		verificationRepo.On("CreateEmailVerificationToken", mock.Anything, mock.MatchedBy(func(token *domain.EmailVerificationToken) bool {
+			stored = token
+			return true
+		})).Return(nil)
+		mailer.On("SendEmailVerification", mock.Anything, user, mock.MatchedBy(func(token string) bool {
+			sent = token
+			return true
+		})).Return(nil)
+
+		require.NoError(t, service.SendVerificationEmail(context.Background(), 1))
+		require.NotNil(t, stored)
+		assert.Equal(t, hashToken(sent), stored.TokenHash)
+		stored.ID = 9
+
+		// Mock Expectations: The emailed token finds the stored one and verifies the user
+		verificationRepo.On("GetEmailVerificationToken", mock.Anything, hashToken(sent)).Return(stored, nil)
+		verificationRepo.On("MarkEmailVerificationTokenUsed", mock.Anything, uint(9)).Return(nil)
+		userRepo.On("MarkEmailVerified", mock.Anything, uint(1)).Return(nil)
+
+		// 🚀 Action: Verify with the emailed token
+		err := service.VerifyEmail(context.Background(), sent)
+
+		// ✅ Assertions: Token used up and user verified
+		require.NoError(t, err)
+		userRepo.AssertExpectations(t)
+		verificationRepo.AssertExpectations(t)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("should reject a used token", func(t *testing.T) {
+		// 🔧 Setup: Create service with a used token
+		userRepo := &MockUserRepository{}
+		verificationRepo := &MockEmailVerificationRepository{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(userRepo, &MockRefreshTokenRepository{}, &MockRoleRepository{}, nil, verificationRepo, jwtService, nil, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		usedAt := time.Now()
+		verificationRepo.On("GetEmailVerificationToken", mock.Anything, hashToken("verify-token")).
+			Return(&domain.EmailVerificationToken{ID: 9, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+
+		// 🚀 Action: Verify again
+		err := service.VerifyEmail(context.Background(), "verify-token")
+
+		// ✅ Assertions: Rejected
+		assert.ErrorIs(t, err, ErrEmailVerificationTokenUsed)
+		userRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not send a token to a verified user", func(t *testing.T) {
+		// 🔧 Setup: Create service with a verified user
+		userRepo := &MockUserRepository{}
+		mailer := &MockAuthMailer{}
+		jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+		service := NewAuthService(userRepo, &MockRefreshTokenRepository{}, &MockRoleRepository{}, nil, &MockEmailVerificationRepository{}, jwtService, mailer, AuthPolicy{BcryptCost: MinBcryptCost})
+
+		userRepo.On("GetUserByID", mock.Anything, 1).Return(&domain.User{ID: 1, IsEmailVerified: true}, nil)
+
+		// 🚀 Action: Request verification
+		err := service.SendVerificationEmail(context.Background(), 1)
+
+		// ✅ Assertions: Nothing is sent
+		assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+		mailer.AssertNotCalled(t, "SendEmailVerification", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuthService_Login_EmailVerification tests gating login on a verified email
+func TestAuthService_Login_EmailVerification(t *testing.T) {
+	// 🎯 Test Strategy: Log in an unverified user with the policy on and off
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), MinBcryptCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		require  bool
+		verified bool
+		wantErr  error
+	}{
+		{name: "should block an unverified user when required", require: true, verified: false, wantErr: ErrEmailNotVerified},
+		{name: "should allow a verified user when required", require: true, verified: true},
+		{name: "should allow an unverified user when not required", require: false, verified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 🔧 Setup: Create service with the policy under test
+			userRepo := &MockUserRepository{}
+			refreshTokenRepo := &MockRefreshTokenRepository{}
+			jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+			service := NewAuthService(userRepo, refreshTokenRepo, &MockRoleRepository{}, nil, nil, jwtService, nil,
+				AuthPolicy{BcryptCost: MinBcryptCost, RequireEmailVerification: tt.require})
+
+			user := &domain.User{ID: 1, Username: "testuser", Password: string(hash), IsActive: true, IsEmailVerified: tt.verified}
+			userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+			refreshTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+			// 🚀 Action: Login user
+			loggedInUser, refreshToken, _, err := service.Login(context.Background(), "testuser", "password123", "test-agent", "127.0.0.1")
+
+			// ✅ Assertions: Blocked or allowed per the policy
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, loggedInUser)
+				refreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, refreshToken)
+		})
+	}
+}
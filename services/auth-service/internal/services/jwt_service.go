@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,11 +9,58 @@ import (
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 )
 
+// ErrUnknownSigningKey is returned when a token's kid header names no configured key
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// ErrWrongTokenType is returned when a validly signed token of one type is presented as
+// the other, such as a refresh token used as an access token
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// Token lifetimes used when a KeySet does not set its own TTL
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// Token types written to the token_type claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 type JWTService struct {
 	accessTokenSecret  string
 	refreshTokenSecret string
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+
+	// Key IDs written to the kid header of new tokens, and retired keys still trusted
+	accessKeyID         string
+	refreshKeyID        string
+	previousAccessKeys  []VerificationKey
+	previousRefreshKeys []VerificationKey
+	now                 func() time.Time
+
+	// startedAt is when the service was created. Tokens issued before it may come from a
+	// version that did not write token_type; see legacyTokenType.
+	startedAt time.Time
+}
+
+// VerificationKey is a retired signing secret. Tokens it signed keep validating until
+// they could have expired on their own: RetiredAt plus the maximum token lifetime.
+type VerificationKey struct {
+	ID        string    `json:"kid"`
+	Secret    string    `json:"secret"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+// KeySet is the key new tokens are signed with plus the retired keys still trusted, and
+// how long the tokens it signs are valid for
+type KeySet struct {
+	CurrentID     string
+	CurrentSecret string
+	Previous      []VerificationKey
+	TTL           time.Duration
 }
 
 type Claims struct {
@@ -20,55 +68,88 @@ type Claims struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Role     string `json:"role"` // User's role for authorization
+	// TokenType is always TokenTypeAccess; it stops other tokens passing as access tokens
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
 type RefreshTokenClaims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	TokenType string `json:"token_type"` // Always TokenTypeRefresh
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(accessSecret, refreshSecret string) *JWTService {
+// NewJWTService creates a JWT service with one secret and token lifetime per token type
+func NewJWTService(accessSecret, refreshSecret string, accessTTL, refreshTTL time.Duration) *JWTService {
+	return NewJWTServiceWithKeys(
+		KeySet{CurrentSecret: accessSecret, TTL: accessTTL},
+		KeySet{CurrentSecret: refreshSecret, TTL: refreshTTL},
+	)
+}
+
+// NewJWTServiceWithKeys creates a JWT service that signs with each set's current key and
+// also verifies tokens signed by its retired keys, so rotating a secret does not log everyone out
+func NewJWTServiceWithKeys(accessKeys, refreshKeys KeySet) *JWTService {
+	if accessKeys.TTL <= 0 {
+		accessKeys.TTL = DefaultAccessTokenTTL
+	}
+	if refreshKeys.TTL <= 0 {
+		refreshKeys.TTL = DefaultRefreshTokenTTL
+	}
+
 	return &JWTService{
-		accessTokenSecret:  accessSecret,
-		refreshTokenSecret: refreshSecret,
-		accessTokenExpiry:  15 * time.Minute,   // 15 minutes
-		refreshTokenExpiry: 7 * 24 * time.Hour, // 7 days
+		accessTokenSecret:   accessKeys.CurrentSecret,
+		refreshTokenSecret:  refreshKeys.CurrentSecret,
+		accessTokenExpiry:   accessKeys.TTL,
+		refreshTokenExpiry:  refreshKeys.TTL,
+		accessKeyID:         accessKeys.CurrentID,
+		refreshKeyID:        refreshKeys.CurrentID,
+		previousAccessKeys:  accessKeys.Previous,
+		previousRefreshKeys: refreshKeys.Previous,
+		now:                 time.Now,
+		startedAt:           time.Now(),
 	}
 }
 
 // GenerateAccessToken creates a new access token for a user
 func (j *JWTService) GenerateAccessToken(user *domain.User) (string, error) {
+	now := j.clock()
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Role:     user.Role, // Include user's role in claims
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role, // Include user's role in claims
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.accessTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "auth-service",
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if j.accessKeyID != "" {
+		token.Header["kid"] = j.accessKeyID
+	}
 	return token.SignedString([]byte(j.accessTokenSecret))
 }
 
 // GenerateRefreshToken creates a new refresh token and returns both token and claims
 func (j *JWTService) GenerateRefreshToken(user *domain.User) (*domain.RefreshToken, error) {
+	now := j.clock()
 	claims := &RefreshTokenClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.refreshTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "auth-service",
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
@@ -76,17 +157,21 @@ func (j *JWTService) GenerateRefreshToken(user *domain.User) (*domain.RefreshTok
 
 	// Sign the refresh token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if j.refreshKeyID != "" {
+		token.Header["kid"] = j.refreshKeyID
+	}
 	refreshTokenJWT, err := token.SignedString([]byte(j.refreshTokenSecret))
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
-	// Create refresh token domain object
+	// Create refresh token domain object. It expires exactly when the signed token does,
+	// which is rounded down to the second.
 	refreshToken := &domain.RefreshToken{
 		UserID:       user.ID,
 		RefreshToken: refreshTokenJWT,
-		ExpiresAt:    time.Now().Add(j.refreshTokenExpiry),
-		CreatedAt:    time.Now(),
+		ExpiresAt:    claims.ExpiresAt.Time,
+		CreatedAt:    now,
 		IsRevoked:    false,
 	}
 
@@ -95,19 +180,19 @@ func (j *JWTService) GenerateRefreshToken(user *domain.User) (*domain.RefreshTok
 
 // ValidateAccessToken validates and parses an access token
 func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.accessTokenSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{},
+		j.keyFunc(j.accessKeyID, j.accessTokenSecret, j.previousAccessKeys, j.accessTokenExpiry),
+		jwt.WithTimeFunc(j.clock))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Checked even though the secrets differ, in case they are ever configured the same
+		if j.tokenType(claims.TokenType, claims.RegisteredClaims) != TokenTypeAccess {
+			return nil, fmt.Errorf("%w: expected an access token", ErrWrongTokenType)
+		}
 		return claims, nil
 	}
 
@@ -116,25 +201,84 @@ func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
 
 // ValidateRefreshToken validates and parses a refresh token
 func (j *JWTService) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.refreshTokenSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{},
+		j.keyFunc(j.refreshKeyID, j.refreshTokenSecret, j.previousRefreshKeys, j.refreshTokenExpiry),
+		jwt.WithTimeFunc(j.clock))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if claims, ok := token.Claims.(*RefreshTokenClaims); ok && token.Valid {
+		if j.tokenType(claims.TokenType, claims.RegisteredClaims) != TokenTypeRefresh {
+			return nil, fmt.Errorf("%w: expected a refresh token", ErrWrongTokenType)
+		}
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
 
+// tokenType returns a token's token_type claim, or for a token without one the type
+// legacyTokenType works out
+func (j *JWTService) tokenType(claimed string, registered jwt.RegisteredClaims) string {
+	if claimed != "" {
+		return claimed
+	}
+	return j.legacyTokenType(registered)
+}
+
+// legacyTokenType works out the type of a token issued before token_type was written, so
+// sessions survive the upgrade. Only tokens issued before the service started qualify,
+// which ends the grace once the tokens issued by the previous version have expired.
+// Their type is told by lifetime: no longer than an access token's is an access token.
+func (j *JWTService) legacyTokenType(registered jwt.RegisteredClaims) string {
+	if registered.IssuedAt == nil || registered.ExpiresAt == nil || !registered.IssuedAt.Before(j.startedAt.Truncate(time.Second)) {
+		return ""
+	}
+	if registered.ExpiresAt.Sub(registered.IssuedAt.Time) <= j.accessTokenExpiry {
+		return TokenTypeAccess
+	}
+	return TokenTypeRefresh
+}
+
+// keyFunc picks the secret matching a token's kid header. Tokens without a kid are checked
+// against the current key; retired keys are trusted for lifetime after they were retired.
+func (j *JWTService) keyFunc(currentID, currentSecret string, previous []VerificationKey, lifetime time.Duration) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" || kid == currentID {
+			return []byte(currentSecret), nil
+		}
+
+		now := j.clock()
+		for _, key := range previous {
+			if key.ID != kid {
+				continue
+			}
+			if !now.Before(key.RetiredAt.Add(lifetime)) {
+				return nil, fmt.Errorf("signing key %q is no longer trusted", kid)
+			}
+			return []byte(key.Secret), nil
+		}
+
+		return nil, fmt.Errorf("%w %q", ErrUnknownSigningKey, kid)
+	}
+}
+
+// clock returns the current time, which tests can move by setting now
+func (j *JWTService) clock() time.Time {
+	if j.now != nil {
+		return j.now()
+	}
+	return time.Now()
+}
+
 // GetAccessTokenExpiry returns the access token expiry duration
 func (j *JWTService) GetAccessTokenExpiry() time.Duration {
 	return j.accessTokenExpiry
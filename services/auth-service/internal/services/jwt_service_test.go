@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +15,7 @@ func TestNewJWTService(t *testing.T) {
 		accessSecret := "test-access-secret-key"
 		refreshSecret := "test-refresh-secret-key"
 
-		service := NewJWTService(accessSecret, refreshSecret)
+		service := NewJWTService(accessSecret, refreshSecret, DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 		assert.NotNil(t, service)
 		assert.Equal(t, accessSecret, service.accessTokenSecret)
@@ -25,7 +26,7 @@ func TestNewJWTService(t *testing.T) {
 }
 
 func TestJWTService_GenerateAccessToken(t *testing.T) {
-	service := NewJWTService("test-access-secret", "test-refresh-secret")
+	service := NewJWTService("test-access-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 	t.Run("should generate valid access token", func(t *testing.T) {
 		user := &domain.User{
@@ -86,7 +87,7 @@ func TestJWTService_GenerateAccessToken(t *testing.T) {
 }
 
 func TestJWTService_GenerateRefreshToken(t *testing.T) {
-	service := NewJWTService("test-access-secret", "test-refresh-secret")
+	service := NewJWTService("test-access-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 	t.Run("should generate valid refresh token", func(t *testing.T) {
 		user := &domain.User{
@@ -123,7 +124,7 @@ func TestJWTService_GenerateRefreshToken(t *testing.T) {
 func TestJWTService_ValidateAccessToken(t *testing.T) {
 	accessSecret := "test-access-secret-key"
 	refreshSecret := "test-refresh-secret-key"
-	service := NewJWTService(accessSecret, refreshSecret)
+	service := NewJWTService(accessSecret, refreshSecret, DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 	t.Run("should validate valid access token", func(t *testing.T) {
 		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
@@ -151,7 +152,7 @@ func TestJWTService_ValidateAccessToken(t *testing.T) {
 	})
 
 	t.Run("should reject token with wrong secret", func(t *testing.T) {
-		wrongService := NewJWTService("wrong-secret", "wrong-refresh-secret")
+		wrongService := NewJWTService("wrong-secret", "wrong-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
 		token, err := wrongService.GenerateAccessToken(user)
 		require.NoError(t, err)
@@ -190,7 +191,7 @@ func TestJWTService_ValidateAccessToken(t *testing.T) {
 func TestJWTService_ValidateRefreshToken(t *testing.T) {
 	accessSecret := "test-access-secret-key"
 	refreshSecret := "test-refresh-secret-key"
-	service := NewJWTService(accessSecret, refreshSecret)
+	service := NewJWTService(accessSecret, refreshSecret, DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 	t.Run("should validate valid refresh token", func(t *testing.T) {
 		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
@@ -216,7 +217,7 @@ func TestJWTService_ValidateRefreshToken(t *testing.T) {
 	})
 
 	t.Run("should reject refresh token with wrong secret", func(t *testing.T) {
-		wrongService := NewJWTService("wrong-secret", "wrong-refresh-secret")
+		wrongService := NewJWTService("wrong-secret", "wrong-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
 		refreshToken, err := wrongService.GenerateRefreshToken(user)
 		require.NoError(t, err)
@@ -229,8 +230,138 @@ func TestJWTService_ValidateRefreshToken(t *testing.T) {
 	})
 }
 
+func TestJWTService_TokenTypeBinding(t *testing.T) {
+	// One secret for both token types, so only the token_type claim tells them apart
+	service := NewJWTService("shared-secret", "shared-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+	user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+
+	t.Run("should reject a refresh token used as an access token", func(t *testing.T) {
+		refreshToken, err := service.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateAccessToken(refreshToken.RefreshToken)
+
+		assert.ErrorIs(t, err, ErrWrongTokenType)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should reject an access token used as a refresh token", func(t *testing.T) {
+		accessToken, err := service.GenerateAccessToken(user)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateRefreshToken(accessToken)
+
+		assert.ErrorIs(t, err, ErrWrongTokenType)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should accept each token as its own type", func(t *testing.T) {
+		accessToken, err := service.GenerateAccessToken(user)
+		require.NoError(t, err)
+		refreshToken, err := service.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateAccessToken(accessToken)
+		require.NoError(t, err)
+		assert.Equal(t, TokenTypeAccess, claims.TokenType)
+
+		refreshClaims, err := service.ValidateRefreshToken(refreshToken.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, TokenTypeRefresh, refreshClaims.TokenType)
+	})
+}
+
+func TestJWTService_LegacyTokensWithoutType(t *testing.T) {
+	// One secret for both token types, as in TestJWTService_TokenTypeBinding
+	service := NewJWTService("shared-secret", "shared-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.startedAt = now
+	service.now = func() time.Time { return now.Add(5 * time.Second) }
+
+	// untypedToken signs a token the way the version before token_type did
+	untypedToken := func(t *testing.T, issuedAt time.Time, ttl time.Duration) string {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &RefreshTokenClaims{
+			UserID: 1, Username: "testuser", Email: "test@example.com",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(issuedAt.Add(ttl)),
+				IssuedAt:  jwt.NewNumericDate(issuedAt),
+				NotBefore: jwt.NewNumericDate(issuedAt),
+				Issuer:    "auth-service",
+			},
+		})
+		signed, err := token.SignedString([]byte("shared-secret"))
+		require.NoError(t, err)
+		return signed
+	}
+	beforeStart := now.Add(-time.Minute)
+
+	t.Run("should accept tokens issued before the service started as their own type", func(t *testing.T) {
+		claims, err := service.ValidateAccessToken(untypedToken(t, beforeStart, DefaultAccessTokenTTL))
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), claims.UserID)
+
+		refreshClaims, err := service.ValidateRefreshToken(untypedToken(t, beforeStart, DefaultRefreshTokenTTL))
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), refreshClaims.UserID)
+	})
+
+	t.Run("should not accept one legacy type as the other", func(t *testing.T) {
+		_, err := service.ValidateAccessToken(untypedToken(t, beforeStart, DefaultRefreshTokenTTL))
+		assert.ErrorIs(t, err, ErrWrongTokenType)
+
+		_, err = service.ValidateRefreshToken(untypedToken(t, beforeStart, DefaultAccessTokenTTL))
+		assert.ErrorIs(t, err, ErrWrongTokenType)
+	})
+
+	t.Run("should reject untyped tokens issued after the service started", func(t *testing.T) {
+		_, err := service.ValidateAccessToken(untypedToken(t, now.Add(time.Second), DefaultAccessTokenTTL))
+		assert.ErrorIs(t, err, ErrWrongTokenType)
+	})
+}
+
+func TestJWTService_TTL(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+	issuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service := NewJWTService("test-access-secret", "test-refresh-secret", 15*time.Minute, 24*time.Hour)
+	service.now = func() time.Time { return issuedAt }
+
+	t.Run("should expire access tokens after the configured TTL", func(t *testing.T) {
+		token, err := service.GenerateAccessToken(user)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateAccessToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, 900.0, claims.ExpiresAt.Sub(issuedAt).Seconds())
+
+		service.now = func() time.Time { return issuedAt.Add(15*time.Minute + time.Second) }
+		defer func() { service.now = func() time.Time { return issuedAt } }()
+
+		claims, err = service.ValidateAccessToken(token)
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should store the signed expiry on refresh tokens", func(t *testing.T) {
+		refreshToken, err := service.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		claims, err := service.ValidateRefreshToken(refreshToken.RefreshToken)
+		require.NoError(t, err)
+		assert.True(t, refreshToken.ExpiresAt.Equal(claims.ExpiresAt.Time))
+		assert.True(t, refreshToken.ExpiresAt.Equal(issuedAt.Add(24*time.Hour)))
+	})
+
+	t.Run("should fall back to default TTLs", func(t *testing.T) {
+		service := NewJWTService("test-access-secret", "test-refresh-secret", 0, 0)
+
+		assert.Equal(t, DefaultAccessTokenTTL, service.GetAccessTokenExpiry())
+		assert.Equal(t, DefaultRefreshTokenTTL, service.GetRefreshTokenExpiry())
+	})
+}
+
 func TestJWTService_GetExpiry(t *testing.T) {
-	service := NewJWTService("test-access-secret", "test-refresh-secret")
+	service := NewJWTService("test-access-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
 
 	t.Run("should return correct access token expiry", func(t *testing.T) {
 		expiry := service.GetAccessTokenExpiry()
@@ -242,3 +373,97 @@ func TestJWTService_GetExpiry(t *testing.T) {
 		assert.Equal(t, 7*24*time.Hour, expiry)
 	})
 }
+
+func TestJWTService_KeyRotation(t *testing.T) {
+	user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+	retiredAt := time.Now().Add(-5 * time.Minute)
+
+	// Tokens issued before the rotation, while v1 was current
+	before := NewJWTServiceWithKeys(
+		KeySet{CurrentID: "v1", CurrentSecret: "old-access-secret"},
+		KeySet{CurrentID: "v1", CurrentSecret: "old-refresh-secret"},
+	)
+	accessToken, err := before.GenerateAccessToken(user)
+	require.NoError(t, err)
+	refreshToken, err := before.GenerateRefreshToken(user)
+	require.NoError(t, err)
+
+	t.Run("should sign new tokens with the current key", func(t *testing.T) {
+		service := NewJWTServiceWithKeys(
+			KeySet{CurrentID: "v2", CurrentSecret: "new-access-secret"},
+			KeySet{CurrentID: "v2", CurrentSecret: "new-refresh-secret"},
+		)
+
+		token, err := service.GenerateAccessToken(user)
+		require.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, "v2", parsed.Header["kid"])
+
+		claims, err := service.ValidateAccessToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+	})
+
+	t.Run("should validate tokens signed with a retired but trusted key", func(t *testing.T) {
+		service := NewJWTServiceWithKeys(
+			KeySet{CurrentID: "v2", CurrentSecret: "new-access-secret", Previous: []VerificationKey{
+				{ID: "v1", Secret: "old-access-secret", RetiredAt: retiredAt},
+			}},
+			KeySet{CurrentID: "v2", CurrentSecret: "new-refresh-secret", Previous: []VerificationKey{
+				{ID: "v1", Secret: "old-refresh-secret", RetiredAt: retiredAt},
+			}},
+		)
+
+		claims, err := service.ValidateAccessToken(accessToken)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+
+		refreshClaims, err := service.ValidateRefreshToken(refreshToken.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, refreshClaims.UserID)
+	})
+
+	t.Run("should reject tokens signed with a removed key", func(t *testing.T) {
+		service := NewJWTServiceWithKeys(
+			KeySet{CurrentID: "v2", CurrentSecret: "new-access-secret"},
+			KeySet{CurrentID: "v2", CurrentSecret: "new-refresh-secret"},
+		)
+
+		claims, err := service.ValidateAccessToken(accessToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+		assert.Contains(t, err.Error(), `unknown signing key "v1"`)
+		assert.ErrorIs(t, err, ErrUnknownSigningKey)
+	})
+
+	t.Run("should reject retired keys once the token lifetime has passed", func(t *testing.T) {
+		service := NewJWTServiceWithKeys(
+			KeySet{CurrentID: "v2", CurrentSecret: "new-access-secret", Previous: []VerificationKey{
+				{ID: "v1", Secret: "old-access-secret", RetiredAt: retiredAt},
+			}},
+			KeySet{CurrentID: "v2", CurrentSecret: "new-refresh-secret"},
+		)
+		service.now = func() time.Time { return retiredAt.Add(service.accessTokenExpiry) }
+
+		claims, err := service.ValidateAccessToken(accessToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+		assert.Contains(t, err.Error(), "no longer trusted")
+	})
+
+	t.Run("should reject a token whose kid points at the wrong secret", func(t *testing.T) {
+		service := NewJWTServiceWithKeys(
+			KeySet{CurrentID: "v1", CurrentSecret: "new-access-secret"},
+			KeySet{CurrentID: "v1", CurrentSecret: "new-refresh-secret"},
+		)
+
+		claims, err := service.ValidateAccessToken(accessToken)
+
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+}
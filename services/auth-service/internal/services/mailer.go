@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// AuthMailer delivers account emails carrying single-use tokens
+type AuthMailer interface {
+	SendPasswordReset(ctx context.Context, user *domain.User, token string) error
+	SendEmailVerification(ctx context.Context, user *domain.User, token string) error
+}
+
+type logAuthMailer struct {
+	includeToken bool
+}
+
+// NewLogAuthMailer returns a mailer that only logs the emails it would send. It is the
+// default until an email provider is wired in. Tokens are logged only when includeToken
+// is set, which must never be the case in production.
+func NewLogAuthMailer(includeToken bool) AuthMailer {
+	return &logAuthMailer{includeToken: includeToken}
+}
+
+// SendPasswordReset logs the reset email that would be sent
+func (m *logAuthMailer) SendPasswordReset(ctx context.Context, user *domain.User, token string) error {
+	logger.FromContext(ctx).Info("password reset requested", m.attrs(user, token)...)
+	return nil
+}
+
+// SendEmailVerification logs the verification email that would be sent
+func (m *logAuthMailer) SendEmailVerification(ctx context.Context, user *domain.User, token string) error {
+	logger.FromContext(ctx).Info("email verification requested", m.attrs(user, token)...)
+	return nil
+}
+
+func (m *logAuthMailer) attrs(user *domain.User, token string) []any {
+	attrs := []any{"user_id", user.ID, "email", user.Email}
+	if m.includeToken {
+		attrs = append(attrs, "token", token)
+	}
+	return attrs
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinBcryptCost is the lowest work factor passwords are hashed at, whatever is configured
+const MinBcryptCost = 10
+
+// passwordCost returns the bcrypt cost to hash with for a configured cost, raised to
+// MinBcryptCost and capped at bcrypt.MaxCost
+func passwordCost(cost int) int {
+	return min(max(cost, MinBcryptCost), bcrypt.MaxCost)
+}
+
+// hashPassword hashes a plaintext password at cost
+func hashPassword(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// needsRehash reports whether a stored hash was made with a lower cost than cost.
+// Such hashes are upgraded on the next successful login.
+func needsRehash(hash string, cost int) bool {
+	stored, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return stored < cost
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/validation"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// passwordResetTTL is how long a password reset token can be used after it is issued
+const passwordResetTTL = time.Hour
+
+var (
+	// ErrPasswordResetTokenInvalid is returned for a reset token that was never issued
+	ErrPasswordResetTokenInvalid = errors.New("invalid password reset token")
+	// ErrPasswordResetTokenExpired is returned for a reset token past its expiry
+	ErrPasswordResetTokenExpired = errors.New("password reset token expired")
+	// ErrPasswordResetTokenUsed is returned for a reset token that already reset a password
+	// or was superseded by a newer one
+	ErrPasswordResetTokenUsed = errors.New("password reset token already used")
+)
+
+// RequestPasswordReset issues a reset token for the account with email and sends it to
+// the user, invalidating any token issued before. An unknown email is not an error so
+// callers cannot probe which addresses have accounts.
+func (a *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.FromContext(ctx).Info("password reset requested for unknown email")
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	if err := a.passwordResetRepo.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := a.passwordResetRepo.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(passwordResetTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	if err := a.mailer.SendPasswordReset(ctx, user, token); err != nil {
+		return fmt.Errorf("failed to send password reset: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password for the user a reset token was issued to. The token
+// is used up, and every refresh token of the user is revoked so other sessions must
+// log in again with the new password.
+func (a *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validation.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	resetToken, err := a.passwordResetRepo.GetPasswordResetToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPasswordResetTokenInvalid
+		}
+		return err
+	}
+
+	if resetToken.UsedAt != nil {
+		return ErrPasswordResetTokenUsed
+	}
+	if !resetToken.ExpiresAt.After(time.Now()) {
+		return ErrPasswordResetTokenExpired
+	}
+
+	// Use up the token before changing anything, so two concurrent resets cannot both succeed
+	if err := a.passwordResetRepo.MarkPasswordResetTokenUsed(ctx, resetToken.ID); err != nil {
+		if errors.Is(err, repository.ErrPasswordResetTokenUsed) {
+			return ErrPasswordResetTokenUsed
+		}
+		return err
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, int(resetToken.UserID))
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	hash, err := hashPassword(newPassword, a.bcryptCost)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+
+	if err := a.userRepo.UpdateUser(ctx, int(user.ID), user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := a.refreshTokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// generateToken returns a random, URL-safe token for emailing to a user
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the hash a single-use token, such as a password reset or email
+// verification token, is stored under
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockPasswordResetRepository is a mock implementation of IPasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) MarkPasswordResetTokenUsed(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) InvalidateUserPasswordResetTokens(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// MockAuthMailer is a mock implementation of AuthMailer
+type MockAuthMailer struct {
+	mock.Mock
+}
+
+func (m *MockAuthMailer) SendPasswordReset(ctx context.Context, user *domain.User, token string) error {
+	args := m.Called(ctx, user, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthMailer) SendEmailVerification(ctx context.Context, user *domain.User, token string) error {
+	args := m.Called(ctx, user, token)
+	return args.Error(0)
+}
+
+// newPasswordResetTestService creates an auth service with mocked repositories for reset tests
+func newPasswordResetTestService() (IAuthService, *MockUserRepository, *MockRefreshTokenRepository, *MockPasswordResetRepository, *MockAuthMailer) {
+	userRepo := &MockUserRepository{}
+	refreshTokenRepo := &MockRefreshTokenRepository{}
+	resetRepo := &MockPasswordResetRepository{}
+	sender := &MockAuthMailer{}
+	jwtService := NewJWTService("test-secret", "test-refresh-secret", DefaultAccessTokenTTL, DefaultRefreshTokenTTL)
+	service := NewAuthService(userRepo, refreshTokenRepo, &MockRoleRepository{}, resetRepo, nil, jwtService, sender, AuthPolicy{BcryptCost: MinBcryptCost})
+	return service, userRepo, refreshTokenRepo, resetRepo, sender
+}
+
+// TestAuthService_RequestPasswordReset tests issuing password reset tokens
+func TestAuthService_RequestPasswordReset(t *testing.T) {
+	// 🎯 Test Strategy: Test token issuing with mocked repositories and sender
+
+	t.Run("should store a hashed token and send the raw one", func(t *testing.T) {
+		// 🔧 Setup: Create service with a known user
+		service, userRepo, _, resetRepo, sender := newPasswordResetTestService()
+		user := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+
+		// 🎭 Mock Expectations: Older tokens are invalidated and a new one is stored and sent
+		var stored *domain.PasswordResetToken
+		var sent string
+		userRepo.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
+		resetRepo.On("InvalidateUserPasswordResetTokens", mock.Anything, uint(1)).Return(nil)
+		resetRepo.On("CreatePasswordResetToken", mock.Anything, mock.MatchedBy(func(token *domain.PasswordResetToken) bool {
+			stored = token
+			return token.UserID == 1
+		})).Return(nil)
+		sender.On("SendPasswordReset", mock.Anything, user, mock.MatchedBy(func(token string) bool {
+			sent = token
+			return token != ""
+		})).Return(nil)
+
+		// 🚀 Action: Request a reset
+		err := service.RequestPasswordReset(context.Background(), "test@example.com")
+
+		// ✅ Assertions: Only the hash is stored and the token expires
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.NotEqual(t, sent, stored.TokenHash)
+		assert.Equal(t, hashToken(sent), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(passwordResetTTL), stored.ExpiresAt, time.Minute)
+		resetRepo.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("should not reveal unknown emails", func(t *testing.T) {
+		// 🔧 Setup: Create service without the user
+		service, userRepo, _, resetRepo, sender := newPasswordResetTestService()
+		userRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(nil, sql.ErrNoRows)
+
+		// 🚀 Action: Request a reset
+		err := service.RequestPasswordReset(context.Background(), "nobody@example.com")
+
+		// ✅ Assertions: Succeeds without issuing a token
+		assert.NoError(t, err)
+		resetRepo.AssertNotCalled(t, "CreatePasswordResetToken", mock.Anything, mock.Anything)
+		sender.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuthService_ResetPassword tests resetting a password with a reset token
+func TestAuthService_ResetPassword(t *testing.T) {
+	// 🎯 Test Strategy: Test token checks and password update with mocked repositories
+
+	const token = "reset-token"
+
+	t.Run("should update the password and revoke all sessions", func(t *testing.T) {
+		// 🔧 Setup: Create service with a valid token
+		service, userRepo, refreshTokenRepo, resetRepo, _ := newPasswordResetTestService()
+		user := &domain.User{ID: 1, Username: "testuser", Password: "old-hash"}
+		resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, TokenHash: hashToken(token), ExpiresAt: time.Now().Add(time.Hour)}
+
+		// 🎭 Mock Expectations: Token is used up, password stored and sessions revoked
+		var storedHash string
+		resetRepo.On("GetPasswordResetToken", mock.Anything, hashToken(token)).Return(resetToken, nil)
+		resetRepo.On("MarkPasswordResetTokenUsed", mock.Anything, uint(5)).Return(nil)
+		userRepo.On("GetUserByID", mock.Anything, 1).Return(user, nil)
+		userRepo.On("UpdateUser", mock.Anything, 1, mock.MatchedBy(func(u *domain.User) bool {
+			storedHash = u.Password
+			return true
+		})).Return(nil)
+		refreshTokenRepo.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(nil)
+
+		// 🚀 Action: Reset password
+		err := service.ResetPassword(context.Background(), token, "NewSecure123")
+
+		// ✅ Assertions: New password verifies against the stored hash
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(storedHash), []byte("NewSecure123")))
+		resetRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		refreshTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject an expired token", func(t *testing.T) {
+		// 🔧 Setup: Create service with an expired token
+		service, userRepo, _, resetRepo, _ := newPasswordResetTestService()
+		resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, TokenHash: hashToken(token), ExpiresAt: time.Now().Add(-time.Minute)}
+		resetRepo.On("GetPasswordResetToken", mock.Anything, hashToken(token)).Return(resetToken, nil)
+
+		// 🚀 Action: Reset password
+		err := service.ResetPassword(context.Background(), token, "NewSecure123")
+
+		// ✅ Assertions: Rejected without touching the user
+		assert.ErrorIs(t, err, ErrPasswordResetTokenExpired)
+		resetRepo.AssertNotCalled(t, "MarkPasswordResetTokenUsed", mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject a token that was already used", func(t *testing.T) {
+		// 🔧 Setup: Create service with a used token
+		service, userRepo, _, resetRepo, _ := newPasswordResetTestService()
+		usedAt := time.Now().Add(-time.Minute)
+		resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, TokenHash: hashToken(token), ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}
+		resetRepo.On("GetPasswordResetToken", mock.Anything, hashToken(token)).Return(resetToken, nil)
+
+		// 🚀 Action: Reset password again
+		err := service.ResetPassword(context.Background(), token, "NewSecure123")
+
+		// ✅ Assertions: Rejected without touching the user
+		assert.ErrorIs(t, err, ErrPasswordResetTokenUsed)
+		userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject a token used concurrently", func(t *testing.T) {
+		// 🔧 Setup: Another reset uses the token between the read and the update
+		service, userRepo, _, resetRepo, _ := newPasswordResetTestService()
+		resetToken := &domain.PasswordResetToken{ID: 5, UserID: 1, TokenHash: hashToken(token), ExpiresAt: time.Now().Add(time.Hour)}
+		resetRepo.On("GetPasswordResetToken", mock.Anything, hashToken(token)).Return(resetToken, nil)
+		resetRepo.On("MarkPasswordResetTokenUsed", mock.Anything, uint(5)).Return(repository.ErrPasswordResetTokenUsed)
+
+		// 🚀 Action: Reset password
+		err := service.ResetPassword(context.Background(), token, "NewSecure123")
+
+		// ✅ Assertions: Rejected without touching the user
+		assert.ErrorIs(t, err, ErrPasswordResetTokenUsed)
+		userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject an unknown token", func(t *testing.T) {
+		// 🔧 Setup: Create service without the token
+		service, _, _, resetRepo, _ := newPasswordResetTestService()
+		resetRepo.On("GetPasswordResetToken", mock.Anything, hashToken(token)).
+			Return(nil, fmt.Errorf("password reset token not found: %w", sql.ErrNoRows))
+
+		// 🚀 Action: Reset password
+		err := service.ResetPassword(context.Background(), token, "NewSecure123")
+
+		// ✅ Assertions: Rejected as invalid
+		assert.ErrorIs(t, err, ErrPasswordResetTokenInvalid)
+	})
+
+	t.Run("should enforce the password rules", func(t *testing.T) {
+		// 🔧 Setup: Create service
+		service, _, _, resetRepo, _ := newPasswordResetTestService()
+
+		// 🚀 Action: Reset to a weak password
+		err := service.ResetPassword(context.Background(), token, "weak")
+
+		// ✅ Assertions: Rejected before the token is looked up
+		assert.Error(t, err)
+		resetRepo.AssertNotCalled(t, "GetPasswordResetToken", mock.Anything, mock.Anything)
+	})
+}
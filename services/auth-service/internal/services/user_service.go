@@ -2,12 +2,18 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/auth-service/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrEmailTaken is returned when registering an email that another user already has,
+// in any letter case
+var ErrEmailTaken = errors.New("email already registered")
+
 type IUserService interface {
 	RegisterNewUser(ctx context.Context, u *domain.User) error
 	GetUserByID(ctx context.Context, id int) (*domain.User, error)
@@ -15,27 +21,40 @@ type IUserService interface {
 	UpdateUser(ctx context.Context, id int, u *domain.User) (*domain.User, error)
 	ChangePassword(ctx context.Context, id int, currentPassword, newPassword string) error
 	DeleteUser(ctx context.Context, id int) error
+	DeactivateUser(ctx context.Context, id int) error
+	ReactivateUser(ctx context.Context, id int) error
 }
 
 type userService struct {
 	userRepo    repository.IUserRepository
 	authService IAuthService
+	bcryptCost  int
 }
 
-func NewUserService(userRepo repository.IUserRepository, authService IAuthService) IUserService {
-	return &userService{userRepo: userRepo, authService: authService}
+// NewUserService creates the user service, hashing new passwords at bcryptCost.
+// Costs below MinBcryptCost are raised to it.
+func NewUserService(userRepo repository.IUserRepository, authService IAuthService, bcryptCost int) IUserService {
+	return &userService{userRepo: userRepo, authService: authService, bcryptCost: passwordCost(bcryptCost)}
 }
 
 func (s *userService) RegisterNewUser(ctx context.Context, u *domain.User) error {
 	// Hash the password
-	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hash, err := hashPassword(u.Password, s.bcryptCost)
 	if err != nil {
 		return err
 	}
 
-	u.Password = string(hash)
+	u.Password = hash
+	// Emails are unique and looked up ignoring case, so they are stored lower-cased
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
 
-	return s.userRepo.RegisterNewUser(ctx, u)
+	if err := s.userRepo.RegisterNewUser(ctx, u); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return ErrEmailTaken
+		}
+		return err
+	}
+	return nil
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
@@ -97,16 +116,39 @@ func (s *userService) ChangePassword(ctx context.Context, id int, currentPasswor
 		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hash, err := hashPassword(newPassword, s.bcryptCost)
 	if err != nil {
 		return err
 	}
 
-	user.Password = string(hash)
+	user.Password = hash
 
 	return s.userRepo.UpdateUser(ctx, id, user)
 }
 
+// DeactivateUser blocks the account from logging in without deleting it and ends all of
+// its sessions
+func (s *userService) DeactivateUser(ctx context.Context, id int) error {
+	if _, err := s.userRepo.GetUserByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetUserActive(ctx, id, false); err != nil {
+		return err
+	}
+
+	return s.authService.LogoutAll(ctx, uint(id))
+}
+
+// ReactivateUser lets a deactivated account log in again
+func (s *userService) ReactivateUser(ctx context.Context, id int) error {
+	if _, err := s.userRepo.GetUserByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.userRepo.SetUserActive(ctx, id, true)
+}
+
 func (s *userService) DeleteUser(ctx context.Context, id int) error {
 	// Check if user exists
 	_, err := s.userRepo.GetUserByID(ctx, id)
@@ -0,0 +1,6 @@
+-- =====================================================
+-- Migration: 000002_refresh_token_rotation.down.sql
+-- Description: Rollback refresh token rotation tracking
+-- =====================================================
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS replaced_by;
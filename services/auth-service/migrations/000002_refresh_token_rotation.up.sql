@@ -0,0 +1,10 @@
+-- =====================================================
+-- Migration: 000002_refresh_token_rotation.up.sql
+-- Description: Track which token replaced a rotated refresh token
+-- Tables: refresh_tokens
+-- =====================================================
+
+-- A rotated token points at its successor, so presenting it again can be told
+-- apart from a plain logout and treated as token theft
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS replaced_by BIGINT REFERENCES refresh_tokens(id) ON DELETE SET NULL;
@@ -0,0 +1,8 @@
+-- =====================================================
+-- Migration: 000004_email_verification.down.sql
+-- Description: Rollback email verification
+-- =====================================================
+
+DROP INDEX IF EXISTS idx_email_verification_tokens_user_id;
+DROP TABLE IF EXISTS email_verification_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS is_email_verified;
@@ -0,0 +1,22 @@
+-- =====================================================
+-- Migration: 000004_email_verification.up.sql
+-- Description: Track verified email addresses and store verification tokens
+-- Tables: users, email_verification_tokens
+-- =====================================================
+
+-- Accounts that exist before verification was introduced are treated as verified, so
+-- turning on REQUIRE_EMAIL_VERIFICATION does not lock them out; new accounts start unverified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN is_email_verified SET DEFAULT FALSE;
+
+-- Only a SHA-256 hash of each token is stored
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
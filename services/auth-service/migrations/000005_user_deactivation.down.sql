@@ -0,0 +1,7 @@
+-- =====================================================
+-- Migration: 000005_user_deactivation.down.sql
+-- Description: Rollback soft account deactivation
+-- =====================================================
+
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
+ALTER TABLE users ALTER COLUMN is_active DROP NOT NULL;
@@ -0,0 +1,11 @@
+-- =====================================================
+-- Migration: 000005_user_deactivation.up.sql
+-- Description: Soft account deactivation, kept apart from hard deletion
+-- Tables: users
+-- =====================================================
+
+UPDATE users SET is_active = TRUE WHERE is_active IS NULL;
+ALTER TABLE users ALTER COLUMN is_active SET NOT NULL;
+
+-- Set while an account is deactivated, cleared when it is reactivated
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
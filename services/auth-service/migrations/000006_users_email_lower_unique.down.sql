@@ -0,0 +1,6 @@
+-- =====================================================
+-- Migration: 000006_users_email_lower_unique.down.sql
+-- Description: Rollback case-insensitive email uniqueness
+-- =====================================================
+
+DROP INDEX IF EXISTS idx_users_email_lower;
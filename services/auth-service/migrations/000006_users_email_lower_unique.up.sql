@@ -0,0 +1,30 @@
+-- =====================================================
+-- Migration: 000006_users_email_lower_unique.up.sql
+-- Description: Make active users' emails unique regardless of letter case
+-- Tables: users
+-- =====================================================
+
+-- Emails were only unique case-sensitively, so Alice@x.com and alice@x.com may both be
+-- active. Which account keeps the address is for an operator to decide: list them and
+-- stop rather than pick one.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(LOWER(email) || ' (user IDs ' || ids || ')', ', ')
+    INTO duplicates
+    FROM (
+        SELECT LOWER(email) AS email, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+        FROM users
+        WHERE is_deleted = FALSE
+        GROUP BY LOWER(email)
+        HAVING COUNT(*) > 1
+    ) AS clashes;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'active users share an email in different letter case; deactivate or rename all but one before migrating: %', duplicates;
+    END IF;
+END $$;
+
+-- Also serves the case-insensitive lookup by email
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email)) WHERE is_deleted = FALSE;
@@ -3,18 +3,25 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/cache"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/config"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/db"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/handlers"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/jobs"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/router"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/lifecycle"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 )
 
 func main() {
@@ -24,17 +31,23 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Structured logging for the rest of the process
+	appLogger := logger.Init(cfg.Log)
+
+	// Apply the page size policy for all list endpoints
+	httpx.SetPageSizeLimits(cfg.Paging.DefaultPageSize, cfg.Paging.MaxPageSize)
+
 	// Initialize database
 	database, err := db.NewDB(&cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal(appLogger, "failed to connect to database", err)
 	}
 	defer database.Close()
 
 	// Run migrations
 	migrationsPath := "migrations"
 	if err := database.RunMigrations(migrationsPath); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+		fatal(appLogger, "failed to run migrations", err)
 	}
 
 	// Initialize repositories
@@ -42,21 +55,101 @@ func main() {
 	productRepo := repository.NewProductRepository(database.DB)
 	cartRepo := repository.NewCartRepository(database.DB)
 	inventoryRepo := repository.NewInventoryRepository(database.DB)
+	webhookRepo := repository.NewWebhookRepository(database.DB)
+	couponRepo := repository.NewCouponRepository(database.DB)
+	idempotencyRepo := repository.NewIdempotencyRepository(database.DB)
+	orderRepo := repository.NewOrderRepository(database.DB)
+
+	// Webhook deliveries are sent in the background by the dispatcher
+	webhookDispatcher := jobs.NewWebhookDispatcher(jobs.WebhookDispatcherConfig{
+		Workers:     cfg.Webhooks.Workers,
+		QueueSize:   cfg.Webhooks.QueueSize,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseBackoff: cfg.Webhooks.BaseBackoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		Timeout:     cfg.Webhooks.Timeout,
+	}, webhookRepo)
+
+	// Stock changes are pushed to live storefront streams
+	stockBroadcaster := jobs.NewStockBroadcaster(cfg.Inventory.StreamMaxSubscribersPerProduct)
 
 	// Initialize services
+	// Inventory, product and order events go to the registered webhook subscriptions
+	webhookService := services.NewWebhookService(webhookRepo, webhookDispatcher)
 	categoryService := services.NewCategoryService(categoryRepo, productRepo)
-	productService := services.NewProductService(productRepo)
-	cartService := services.NewCartService(cartRepo, productRepo)
-	inventoryService := services.NewInventoryService(inventoryRepo, productRepo)
+	// Product reads by ID and SKU go through a cache; the no-op cache keeps them uncached
+	productCache := cache.NewNoop[*domain.Product]()
+	if cfg.Cache.ProductsEnabled {
+		productCache = cache.NewLRU[*domain.Product](cfg.Cache.ProductsSize, cfg.Cache.ProductsTTL)
+	}
+	productService := services.NewCachedProductService(services.NewProductService(productRepo, inventoryRepo, services.CategoryPolicy{
+		AllowUncategorized: cfg.Catalog.AllowUncategorized,
+	}, webhookService), productCache)
+	taxService := services.NewStaticTaxService(taxJurisdictions(cfg.Tax.Jurisdictions))
+	couponRedemption := services.CouponRedemptionPolicy{
+		RedeemAtCheckout: cfg.Coupons.RedeemAtCheckout,
+	}
+	cartService := services.NewCartService(cartRepo, productRepo, inventoryRepo, couponRepo, services.CartPricingPolicy{
+		CatalogCurrency:         cfg.Cart.CatalogCurrency,
+		TaxRate:                 cfg.Cart.TaxRate,
+		DiscountBeforeTax:       cfg.Cart.DiscountBeforeTax,
+		RejectNonPositivePrices: cfg.Cart.RejectNonPositivePrices,
+		FreeShippingThresholds:  cfg.Cart.FreeShippingThresholds,
+		DimensionalDivisor:      cfg.Shipping.DimensionalDivisor,
+		MinimumOrderAmounts:     cfg.Cart.MinimumOrderAmounts,
+	}, couponRedemption, services.NewTieredShippingRateProvider(shippingMethods(cfg.Shipping.Methods)), taxService, services.CartExpiryPolicy{
+		MaxExpiry: cfg.Cart.MaxExpiry,
+	})
+	inventoryService := services.NewInventoryService(inventoryRepo, productRepo, services.ReservationPolicy{
+		DefaultTTL: cfg.Inventory.ReservationDefaultTTL,
+		MaxTTL:     cfg.Inventory.ReservationMaxTTL,
+	}, jobs.NewLogStockNotifier(), stockBroadcaster, webhookService)
+	checkoutService := services.NewCheckoutService(orderRepo, cartService, inventoryService, couponRedemption, webhookService)
 
 	// Initialize handlers
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	productHandler := handlers.NewProductHandler(productService)
 	cartHandler := handlers.NewCartHandler(cartService)
+	checkoutHandler := handlers.NewCheckoutHandler(checkoutService)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	// Live stock streams are closed when the server starts shutting down
+	stockStreamShutdown := make(chan struct{})
+	stockStreamHandler := handlers.NewStockStreamHandler(stockBroadcaster, cfg.Inventory.StreamHeartbeat, stockStreamShutdown)
 
 	// Initialize router
-	appRouter := router.NewRouter(categoryHandler, productHandler, cartHandler, inventoryHandler)
+	readiness := lifecycle.NewReadiness()
+	readiness.AddCheck("database", database.Health)
+	migrationsCheck, err := database.MigrationsCheck(migrationsPath)
+	if err != nil {
+		fatal(appLogger, "failed to set up migrations check", err)
+	}
+	readiness.AddCheck("migrations", migrationsCheck)
+	guestSession := handlers.GuestSessionPolicy{
+		CookieName: cfg.Cart.SessionCookieName,
+		Domain:     cfg.Cart.SessionCookieDomain,
+		Secure:     cfg.Cart.SessionCookieSecure,
+		MaxAge:     cfg.Cart.SessionCookieMaxAge,
+		Secret:     []byte(cfg.Cart.SessionSecret),
+	}
+	auth := handlers.AuthPolicy{
+		Secret:       []byte(cfg.Auth.JWTSecret),
+		KeyID:        cfg.Auth.JWTKeyID,
+		PreviousKeys: authKeys(cfg.Auth.JWTPreviousKeys),
+		TokenTTL:     cfg.Auth.AccessTokenTTL,
+		Issuer:       cfg.Auth.JWTIssuer,
+		StartedAt:    time.Now(),
+	}
+	idempotency := handlers.IdempotencyPolicy{
+		Store:          idempotencyRepo,
+		TTL:            cfg.Idempotency.KeyTTL,
+		PendingTimeout: cfg.Idempotency.PendingTimeout,
+	}
+	body := httpx.BodyPolicy{
+		MaxBytes:              int64(cfg.Server.MaxBodyBytes),
+		DisallowUnknownFields: cfg.Server.StrictJSON,
+	}
+	appRouter := router.NewRouter(categoryHandler, productHandler, cartHandler, checkoutHandler, inventoryHandler, webhookHandler, stockStreamHandler, guestSession, auth, idempotency, body, readiness)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -66,12 +159,67 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
+	server.RegisterOnShutdown(func() { close(stockStreamShutdown) })
+
+	// Coordinates readiness, background workers and the server during shutdown
+	coordinator := lifecycle.NewCoordinator(server, readiness, cfg.Server.ShutdownDrainDelay)
+
+	// Start background jobs
+	coordinator.Go(func(ctx context.Context) {
+		webhookDispatcher.Run(logger.WithContext(ctx, appLogger.With("job", "webhook_dispatcher")))
+	})
+
+	if cfg.Jobs.AbandonedCartEnabled {
+		abandonedCartJob := jobs.NewAbandonedCartJob(cartRepo, jobs.NewLogCartReminderNotifier(), cfg.Jobs.AbandonedCartInactivity)
+		coordinator.Go(func(ctx context.Context) {
+			abandonedCartJob.Start(logger.WithContext(ctx, appLogger.With("job", "abandoned_cart")), cfg.Jobs.AbandonedCartInterval)
+		})
+	}
+
+	// Periodic maintenance: expired reservations hand their stock back, expired carts are
+	// removed, low stock alerts are raised, expired idempotency keys are dropped and
+	// reserved stock counts that drifted from their reservations are corrected
+	maintenance := jobs.NewScheduler(
+		jobs.ScheduledJob{
+			Name:     "reservation_cleanup",
+			Interval: cfg.Jobs.ReservationCleanupInterval,
+			Run:      inventoryService.ReleaseExpiredReservations,
+		},
+		jobs.ScheduledJob{
+			Name:     "expired_cart_cleanup",
+			Interval: cfg.Jobs.ExpiredCartCleanupInterval,
+			Run: func(ctx context.Context) error {
+				return cartRepo.DeleteExpiredCarts(ctx, time.Now())
+			},
+		},
+		jobs.ScheduledJob{
+			Name:     "low_stock_check",
+			Interval: cfg.Jobs.LowStockCheckInterval,
+			Run:      inventoryService.CheckLowStockAlerts,
+		},
+		jobs.ScheduledJob{
+			Name:     "idempotency_key_cleanup",
+			Interval: cfg.Jobs.IdempotencyCleanupInterval,
+			Run: func(ctx context.Context) error {
+				_, err := idempotencyRepo.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+				return err
+			},
+		},
+		jobs.ScheduledJob{
+			Name:     "inventory_repair",
+			Interval: cfg.Jobs.InventoryRepairInterval,
+			Run:      inventoryService.RepairAvailableQuantities,
+		},
+	)
+	coordinator.Go(func(ctx context.Context) {
+		maintenance.Start(logger.WithContext(ctx, appLogger.With("job", "maintenance")))
+	})
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s", server.Addr)
+		appLogger.Info("starting server", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal(appLogger, "failed to start server", err)
 		}
 	}()
 
@@ -79,15 +227,68 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	appLogger.Info("shutting down server")
 
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Flip readiness, stop background workers, then drain outstanding requests
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := coordinator.Shutdown(ctx); err != nil {
+		fatal(appLogger, "server forced to shutdown", err)
+	}
+
+	appLogger.Info("server exited")
+}
+
+// fatal logs err at error level and exits
+func fatal(l *slog.Logger, msg string, err error) {
+	l.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// shippingMethods converts configured shipping methods to the rate provider's form
+func shippingMethods(methods []config.ShippingMethod) []services.ShippingMethod {
+	converted := make([]services.ShippingMethod, 0, len(methods))
+	for _, method := range methods {
+		tiers := make([]services.ShippingRateTier, 0, len(method.Tiers))
+		for _, tier := range method.Tiers {
+			tiers = append(tiers, services.ShippingRateTier{MaxWeight: tier.MaxWeight, Cost: tier.Cost})
+		}
+		converted = append(converted, services.ShippingMethod{
+			ID:            method.ID,
+			Name:          method.Name,
+			EstimatedDays: method.EstimatedDays,
+			Countries:     method.Countries,
+			Tiers:         tiers,
+		})
 	}
+	return converted
+}
 
-	log.Println("Server exited")
+// authKeys converts configured retired JWT keys to the form access tokens are verified with
+func authKeys(keys []config.JWTKey) []handlers.AuthKey {
+	converted := make([]handlers.AuthKey, 0, len(keys))
+	for _, k := range keys {
+		converted = append(converted, handlers.AuthKey{
+			ID:        k.ID,
+			Secret:    []byte(k.Secret),
+			RetiredAt: k.RetiredAt,
+		})
+	}
+	return converted
+}
+
+// taxJurisdictions converts configured tax jurisdictions to the tax service's form
+func taxJurisdictions(jurisdictions []config.TaxJurisdiction) []services.TaxJurisdiction {
+	converted := make([]services.TaxJurisdiction, 0, len(jurisdictions))
+	for _, j := range jurisdictions {
+		converted = append(converted, services.TaxJurisdiction{
+			Name:         j.Name,
+			Country:      j.Country,
+			State:        j.State,
+			PostalPrefix: j.PostalPrefix,
+			Rate:         j.Rate,
+		})
+	}
+	return converted
 }
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key. Implementations must be safe for concurrent use; the
+// context lets remote implementations such as Redis honour deadlines.
+type Cache[V any] interface {
+	Get(ctx context.Context, key string) (V, bool)
+	Set(ctx context.Context, key string, value V)
+	Delete(ctx context.Context, keys ...string)
+}
+
+type noop[V any] struct{}
+
+// NewNoop returns a cache that stores nothing, so every Get misses
+func NewNoop[V any]() Cache[V] {
+	return noop[V]{}
+}
+
+func (noop[V]) Get(context.Context, string) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+func (noop[V]) Set(context.Context, string, V) {}
+
+func (noop[V]) Delete(context.Context, ...string) {}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is an in-memory cache holding at most size entries, each for at most ttl.
+// When full, the least recently used entry is evicted.
+type LRU[V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewLRU creates an LRU cache. A size below one is treated as one.
+func NewLRU[V any](size int, ttl time.Duration) *LRU[V] {
+	if size < 1 {
+		size = 1
+	}
+	return &LRU[V]{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value for key if it is present and has not expired
+func (c *LRU[V]) Get(_ context.Context, key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[V])
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry if the cache is full
+func (c *LRU[V]) Set(_ context.Context, key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete removes keys from the cache
+func (c *LRU[V]) Delete(_ context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hit and miss", func(t *testing.T) {
+		c := NewLRU[int](2, time.Minute)
+		c.Set(ctx, "a", 1)
+
+		value, ok := c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		_, ok = c.Get(ctx, "b")
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		c := NewLRU[int](2, time.Minute)
+		c.Set(ctx, "a", 1)
+		c.Set(ctx, "b", 2)
+		c.Get(ctx, "a") // "b" is now least recently used
+		c.Set(ctx, "c", 3)
+
+		_, ok := c.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok = c.Get(ctx, "a")
+		assert.True(t, ok)
+		_, ok = c.Get(ctx, "c")
+		assert.True(t, ok)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		now := time.Now()
+		c := NewLRU[int](2, time.Minute)
+		c.now = func() time.Time { return now }
+		c.Set(ctx, "a", 1)
+
+		c.now = func() time.Time { return now.Add(time.Minute) }
+		_, ok := c.Get(ctx, "a")
+
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := NewLRU[int](2, time.Minute)
+		c.Set(ctx, "a", 1)
+		c.Set(ctx, "b", 2)
+		c.Delete(ctx, "a", "missing")
+
+		_, ok := c.Get(ctx, "a")
+		assert.False(t, ok)
+		_, ok = c.Get(ctx, "b")
+		assert.True(t, ok)
+	})
+}
+
+func TestNoop(t *testing.T) {
+	c := NewNoop[int]()
+	c.Set(context.Background(), "a", 1)
+
+	_, ok := c.Get(context.Background(), "a")
+
+	assert.False(t, ok)
+}
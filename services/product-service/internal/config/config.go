@@ -1,19 +1,36 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 	"github.com/joho/godotenv"
 )
 
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Jobs      JobsConfig
+	Paging    PagingConfig
+	Inventory InventoryConfig
+	Webhooks  WebhooksConfig
+	Cart      CartConfig
+	Coupons   CouponsConfig
+	Log       logger.Config
+	Cache     CacheConfig
+	Catalog   CatalogConfig
+	Shipping  ShippingConfig
+	Tax       TaxConfig
+	Auth      AuthConfig
+
+	Idempotency IdempotencyConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -23,6 +40,13 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	ShutdownTimeout    time.Duration
+	ShutdownDrainDelay time.Duration
+
+	// MaxBodyBytes bounds request bodies; StrictJSON rejects fields a request type does not declare
+	MaxBodyBytes int
+	StrictJSON   bool
 }
 
 // DatabaseConfig holds database-related configuration
@@ -37,6 +61,154 @@ type DatabaseConfig struct {
 	MinConns int
 }
 
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	AbandonedCartEnabled    bool
+	AbandonedCartInterval   time.Duration
+	AbandonedCartInactivity time.Duration
+	// Maintenance job intervals; zero disables a job
+	ReservationCleanupInterval time.Duration
+	ExpiredCartCleanupInterval time.Duration
+	LowStockCheckInterval      time.Duration
+	IdempotencyCleanupInterval time.Duration
+	InventoryRepairInterval    time.Duration
+}
+
+// PagingConfig holds the page size policy for list endpoints
+type PagingConfig struct {
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+// InventoryConfig holds stock reservation policy and live stock stream limits
+type InventoryConfig struct {
+	ReservationDefaultTTL time.Duration
+	ReservationMaxTTL     time.Duration
+
+	StreamHeartbeat                time.Duration
+	StreamMaxSubscribersPerProduct int
+}
+
+// WebhooksConfig holds webhook delivery configuration
+type WebhooksConfig struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// CartConfig holds cart pricing and expiry policy and the guest session cookie settings
+type CartConfig struct {
+	// CatalogCurrency is the ISO currency code product prices are in
+	CatalogCurrency         string
+	TaxRate                 float64
+	DiscountBeforeTax       bool
+	RejectNonPositivePrices bool
+	MaxExpiry               time.Duration
+
+	// Per-currency amounts keyed by ISO currency code
+	FreeShippingThresholds map[string]float64
+	MinimumOrderAmounts    map[string]float64
+
+	SessionCookieName   string
+	SessionCookieDomain string
+	SessionCookieSecure bool
+	SessionCookieMaxAge time.Duration
+	SessionSecret       string
+}
+
+// AuthConfig holds the settings for verifying access tokens issued by auth-service. The
+// keys and token lifetime must match auth-service's access token settings.
+type AuthConfig struct {
+	JWTSecret       string
+	JWTKeyID        string
+	JWTPreviousKeys []JWTKey
+	AccessTokenTTL  time.Duration
+	JWTIssuer       string
+}
+
+// JWTKey is a retired JWT signing secret that still verifies the tokens it signed
+type JWTKey struct {
+	ID        string    `json:"kid"`
+	Secret    string    `json:"secret"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+// IdempotencyConfig holds how long Idempotency-Key responses are kept for replay
+type IdempotencyConfig struct {
+	KeyTTL         time.Duration
+	PendingTimeout time.Duration
+}
+
+// CouponsConfig holds coupon redemption policy
+type CouponsConfig struct {
+	RedeemAtCheckout bool
+}
+
+// CacheConfig holds the product read cache settings
+type CacheConfig struct {
+	ProductsEnabled bool
+	ProductsSize    int
+	ProductsTTL     time.Duration
+}
+
+// CatalogConfig holds rules for how products are organised
+type CatalogConfig struct {
+	// AllowUncategorized lets a product's category list be emptied
+	AllowUncategorized bool
+}
+
+// ShippingConfig holds the shipping methods offered to carts
+type ShippingConfig struct {
+	Methods []ShippingMethod
+	// DimensionalDivisor is the cubic centimetres billed as one unit of weight for bulky
+	// products. The default of 5000 assumes product weights are in kg; use about 2268 for lb.
+	DimensionalDivisor float64
+}
+
+// ShippingMethod is a configured shipping method priced by cart weight
+type ShippingMethod struct {
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	EstimatedDays int            `json:"estimated_days"`
+	Countries     []string       `json:"countries"`
+	Tiers         []ShippingTier `json:"tiers"`
+}
+
+// ShippingTier is the cost of a shipping method up to a cart weight; max_weight 0 has no limit
+type ShippingTier struct {
+	MaxWeight float64 `json:"max_weight"`
+	Cost      float64 `json:"cost"`
+}
+
+// TaxConfig holds the tax rates charged per jurisdiction. Carts shipping outside every
+// jurisdiction, or without an address, pay the flat CART_TAX_RATE.
+type TaxConfig struct {
+	Jurisdictions []TaxJurisdiction
+}
+
+// TaxJurisdiction is a configured tax rate for a country, optionally narrowed to a state
+// and a postal code prefix
+type TaxJurisdiction struct {
+	Name         string  `json:"name"`
+	Country      string  `json:"country"`
+	State        string  `json:"state"`
+	PostalPrefix string  `json:"postal_prefix"`
+	Rate         float64 `json:"rate"`
+}
+
+// defaultShippingMethods are offered when SHIPPING_METHODS is not set
+var defaultShippingMethods = []ShippingMethod{
+	{ID: 1, Name: "Standard", EstimatedDays: 5, Tiers: []ShippingTier{
+		{MaxWeight: 1, Cost: 5}, {MaxWeight: 5, Cost: 9}, {MaxWeight: 20, Cost: 15}, {Cost: 25},
+	}},
+	{ID: 2, Name: "Express", EstimatedDays: 2, Tiers: []ShippingTier{
+		{MaxWeight: 1, Cost: 12}, {MaxWeight: 5, Cost: 20}, {MaxWeight: 20, Cost: 35},
+	}},
+}
+
 // Load loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
@@ -48,6 +220,12 @@ func LoadConfig() (*Config, error) {
 			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
 			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
+
+			ShutdownTimeout:    getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownDrainDelay: getDurationEnv("SERVER_SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+
+			MaxBodyBytes: getIntEnv("SERVER_MAX_BODY_BYTES", 1<<20),
+			StrictJSON:   getBoolEnv("SERVER_STRICT_JSON", false),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -59,14 +237,122 @@ func LoadConfig() (*Config, error) {
 			MaxConns: getIntEnv("DB_MAX_CONNS", 25),
 			MinConns: getIntEnv("DB_MIN_CONNS", 5),
 		},
+		Jobs: JobsConfig{
+			AbandonedCartEnabled:       getBoolEnv("JOB_ABANDONED_CART_ENABLED", true),
+			AbandonedCartInterval:      getDurationEnv("JOB_ABANDONED_CART_INTERVAL", 1*time.Hour),
+			AbandonedCartInactivity:    getDurationEnv("JOB_ABANDONED_CART_INACTIVITY", 24*time.Hour),
+			ReservationCleanupInterval: getDurationEnv("JOB_RESERVATION_CLEANUP_INTERVAL", 1*time.Minute),
+			ExpiredCartCleanupInterval: getDurationEnv("JOB_EXPIRED_CART_CLEANUP_INTERVAL", 1*time.Hour),
+			LowStockCheckInterval:      getDurationEnv("JOB_LOW_STOCK_CHECK_INTERVAL", 15*time.Minute),
+			IdempotencyCleanupInterval: getDurationEnv("JOB_IDEMPOTENCY_CLEANUP_INTERVAL", 1*time.Hour),
+			InventoryRepairInterval:    getDurationEnv("JOB_INVENTORY_REPAIR_INTERVAL", 1*time.Hour),
+		},
+		Paging: PagingConfig{
+			DefaultPageSize: getIntEnv("PAGING_DEFAULT_PAGE_SIZE", 20),
+			MaxPageSize:     getIntEnv("PAGING_MAX_PAGE_SIZE", 100),
+		},
+		Inventory: InventoryConfig{
+			ReservationDefaultTTL: getDurationEnv("INVENTORY_RESERVATION_DEFAULT_TTL", 15*time.Minute),
+			ReservationMaxTTL:     getDurationEnv("INVENTORY_RESERVATION_MAX_TTL", 2*time.Hour),
+
+			StreamHeartbeat:                getDurationEnv("STOCK_STREAM_HEARTBEAT", 15*time.Second),
+			StreamMaxSubscribersPerProduct: getIntEnv("STOCK_STREAM_MAX_SUBSCRIBERS_PER_PRODUCT", 500),
+		},
+		Webhooks: WebhooksConfig{
+			Workers:     getIntEnv("WEBHOOK_WORKERS", 4),
+			QueueSize:   getIntEnv("WEBHOOK_QUEUE_SIZE", 1000),
+			MaxAttempts: getIntEnv("WEBHOOK_MAX_ATTEMPTS", 5),
+			BaseBackoff: getDurationEnv("WEBHOOK_BASE_BACKOFF", 1*time.Second),
+			MaxBackoff:  getDurationEnv("WEBHOOK_MAX_BACKOFF", 1*time.Minute),
+			Timeout:     getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Cart: CartConfig{
+			CatalogCurrency:         strings.ToUpper(getEnv("CART_CATALOG_CURRENCY", "USD")),
+			TaxRate:                 getFloatEnv("CART_TAX_RATE", 0.1),
+			DiscountBeforeTax:       getBoolEnv("CART_DISCOUNT_BEFORE_TAX", false),
+			RejectNonPositivePrices: getBoolEnv("CART_REJECT_NON_POSITIVE_PRICES", true),
+			MaxExpiry:               getDurationEnv("CART_MAX_EXPIRY", 90*24*time.Hour),
+
+			SessionCookieName:   getEnv("CART_SESSION_COOKIE_NAME", "cart_session"),
+			SessionCookieDomain: getEnv("CART_SESSION_COOKIE_DOMAIN", ""),
+			SessionCookieSecure: getBoolEnv("CART_SESSION_COOKIE_SECURE", true),
+			SessionCookieMaxAge: getDurationEnv("CART_SESSION_COOKIE_MAX_AGE", 30*24*time.Hour),
+			SessionSecret:       os.Getenv("CART_SESSION_SECRET"),
+		},
+		Coupons: CouponsConfig{
+			RedeemAtCheckout: getBoolEnv("COUPON_REDEEM_AT_CHECKOUT", false),
+		},
+		Cache: CacheConfig{
+			ProductsEnabled: getBoolEnv("PRODUCT_CACHE_ENABLED", false),
+			ProductsSize:    getIntEnv("PRODUCT_CACHE_SIZE", 1000),
+			ProductsTTL:     getDurationEnv("PRODUCT_CACHE_TTL", 1*time.Minute),
+		},
+		Catalog: CatalogConfig{
+			AllowUncategorized: getBoolEnv("PRODUCT_ALLOW_UNCATEGORIZED", false),
+		},
+		Auth: AuthConfig{
+			JWTSecret:      os.Getenv("JWT_SECRET"),
+			JWTKeyID:       getEnv("JWT_KEY_ID", "v1"),
+			AccessTokenTTL: getDurationEnv("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+			JWTIssuer:      getEnv("JWT_ISSUER", "auth-service"),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL:         getDurationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			PendingTimeout: getDurationEnv("IDEMPOTENCY_PENDING_TIMEOUT", 2*time.Minute),
+		},
+		Log: logger.Config{
+			Level:  getEnv("LOG_LEVEL", "info"),
+			Format: getEnv("LOG_FORMAT", "json"),
+		},
+	}
+
+	if config.Cart.SessionSecret == "" {
+		return nil, fmt.Errorf("CART_SESSION_SECRET environment variable is not set")
+	}
+	if config.Auth.JWTSecret == "" {
+		return nil, fmt.Errorf("JWT_SECRET environment variable is not set")
+	}
+
+	freeShippingThresholds, err := getCurrencyAmountsEnv("CART_FREE_SHIPPING_THRESHOLDS")
+	if err != nil {
+		return nil, err
+	}
+	config.Cart.FreeShippingThresholds = freeShippingThresholds
+
+	minimumOrderAmounts, err := getCurrencyAmountsEnv("CART_MINIMUM_ORDER_AMOUNTS")
+	if err != nil {
+		return nil, err
+	}
+	config.Cart.MinimumOrderAmounts = minimumOrderAmounts
+
+	shippingMethods, err := getShippingMethodsEnv("SHIPPING_METHODS", defaultShippingMethods)
+	if err != nil {
+		return nil, err
+	}
+	config.Shipping = ShippingConfig{
+		Methods:            shippingMethods,
+		DimensionalDivisor: getFloatEnv("SHIPPING_DIMENSIONAL_DIVISOR", 5000),
 	}
 
+	jwtPreviousKeys, err := getJWTKeysEnv("JWT_PREVIOUS_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	config.Auth.JWTPreviousKeys = jwtPreviousKeys
+
+	taxJurisdictions, err := getTaxJurisdictionsEnv("TAX_RATES")
+	if err != nil {
+		return nil, err
+	}
+	config.Tax = TaxConfig{Jurisdictions: taxJurisdictions}
+
 	return config, nil
 }
 
-// GetDSN returns the database connection string
+// GetDSN returns the database connection string. The session time zone is
+// pinned to UTC so timestamps scanned from the database are in UTC.
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
@@ -100,3 +386,99 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getShippingMethodsEnv reads shipping methods from a JSON array such as
+// [{"id":1,"name":"Standard","estimated_days":5,"tiers":[{"max_weight":1,"cost":5},{"cost":9}]}]
+func getShippingMethodsEnv(key string, defaultValue []ShippingMethod) ([]ShippingMethod, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	var methods []ShippingMethod
+	if err := json.Unmarshal([]byte(value), &methods); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return methods, nil
+}
+
+// getTaxJurisdictionsEnv reads tax jurisdictions from a JSON array such as
+// [{"name":"CA state tax","country":"US","state":"CA","rate":0.0725}]
+func getTaxJurisdictionsEnv(key string) ([]TaxJurisdiction, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var jurisdictions []TaxJurisdiction
+	if err := json.Unmarshal([]byte(value), &jurisdictions); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for _, j := range jurisdictions {
+		if j.Country == "" || j.Rate < 0 {
+			return nil, fmt.Errorf("invalid %s: %q needs a country and a non-negative rate", key, j.Name)
+		}
+	}
+	return jurisdictions, nil
+}
+
+// getJWTKeysEnv reads retired JWT keys from a JSON array such as
+// [{"kid":"v1","secret":"...","retired_at":"2025-01-01T00:00:00Z"}]
+func getJWTKeysEnv(key string) ([]JWTKey, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var keys []JWTKey
+	if err := json.Unmarshal([]byte(value), &keys); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for _, k := range keys {
+		if k.ID == "" || k.Secret == "" || k.RetiredAt.IsZero() {
+			return nil, fmt.Errorf("invalid %s: every key needs kid, secret and retired_at", key)
+		}
+	}
+	return keys, nil
+}
+
+// getCurrencyAmountsEnv parses a JSON object of amounts keyed by currency code, e.g.
+// {"USD":50,"EUR":45}. Codes are upper-cased; unset means no amounts.
+func getCurrencyAmountsEnv(key string) (map[string]float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var raw map[string]float64
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	amounts := make(map[string]float64, len(raw))
+	for currency, amount := range raw {
+		if amount < 0 {
+			return nil, fmt.Errorf("invalid %s: amount for %s cannot be negative", key, currency)
+		}
+		amounts[strings.ToUpper(currency)] = amount
+	}
+	return amounts, nil
+}
@@ -3,7 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/config"
 	"github.com/jmoiron/sqlx"
@@ -33,7 +33,7 @@ func NewDB(cfg *config.DatabaseConfig) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Successfully connected to database")
+	slog.Info("connected to database", "max_conns", cfg.MaxConns)
 
 	return &DB{db}, nil
 }
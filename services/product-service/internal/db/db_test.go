@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaVersionQuery = `SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`
+
+func setupMockDB(t *testing.T) (*DB, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+	return &DB{sqlx.NewDb(mockDB, "postgres")}, mock
+}
+
+// writeMigrations creates up migrations for the given versions in a temporary directory
+func writeMigrations(t *testing.T, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644))
+	}
+	return dir
+}
+
+func TestDB_Health(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		database, mock := setupMockDB(t)
+		mock.ExpectPing()
+
+		assert.NoError(t, database.Health(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		database, mock := setupMockDB(t)
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		assert.EqualError(t, database.Health(context.Background()), "connection refused")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDB_MigrationsCheck(t *testing.T) {
+	migrationsPath := writeMigrations(t, "000001_initial.up.sql", "000002_products.up.sql", "000002_products.down.sql")
+
+	tests := []struct {
+		name    string
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr string
+	}{
+		{
+			name: "up to date",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(schemaVersionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+			},
+		},
+		{
+			name: "behind",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(schemaVersionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+			},
+			wantErr: "schema at version 1, expected 2",
+		},
+		{
+			name: "unreachable",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(schemaVersionQuery).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: "failed to read schema version: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, mock := setupMockDB(t)
+			check, err := database.MigrationsCheck(migrationsPath)
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			err = check(context.Background())
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -13,6 +13,14 @@ type Cart struct {
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	TaxExempt bool       `json:"tax_exempt" db:"tax_exempt"`
+
+	// Where the cart ships to; empty until the customer gives an address
+	ShipCountry    string `json:"ship_country" db:"ship_country"`
+	ShipState      string `json:"ship_state" db:"ship_state"`
+	ShipPostalCode string `json:"ship_postal_code" db:"ship_postal_code"`
+
+	LastReminderAt *time.Time `json:"last_reminder_at,omitempty" db:"last_reminder_at"`
 }
 
 // CartItem represents items in a shopping cart
@@ -26,29 +34,68 @@ type CartItem struct {
 	TotalPrice       float64   `json:"total_price" db:"total_price"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	Version          int       `json:"version" db:"version"`
+}
+
+// CartItemDetail is a cart item with the product details shown next to it. SKU is the
+// variant's SKU for variant items and the product's otherwise.
+type CartItemDetail struct {
+	CartItem
+	ProductName string `json:"product_name" db:"product_name"`
+	SKU         string `json:"sku" db:"sku"`
 }
 
 // CartSummary represents a summary of cart contents
 type CartSummary struct {
-	CartID         int64      `json:"cart_id"`
-	ItemCount      int        `json:"item_count"`
-	Subtotal       float64    `json:"subtotal"`
-	TaxAmount      float64    `json:"tax_amount"`
-	ShippingAmount float64    `json:"shipping_amount"`
-	DiscountAmount float64    `json:"discount_amount"`
-	TotalAmount    float64    `json:"total_amount"`
-	Currency       string     `json:"currency"`
-	Items          []CartItem `json:"items"`
+	CartID          int64      `json:"cart_id"`
+	ItemCount       int        `json:"item_count"`
+	Subtotal        float64    `json:"subtotal"`
+	TaxableSubtotal float64    `json:"taxable_subtotal"`
+	TaxRate         float64    `json:"tax_rate"`
+	TaxExempt       bool       `json:"tax_exempt"`
+	TaxAmount       float64    `json:"tax_amount"`
+	ShippingAmount  float64    `json:"shipping_amount"`
+	FreeShipping    bool       `json:"free_shipping"`
+	DiscountAmount  float64    `json:"discount_amount"`
+	TotalAmount     float64    `json:"total_amount"`
+	Currency        string     `json:"currency"`
+	Items           []CartItem `json:"items"`
+
+	// Coupons are the coupons applied to the cart, in the order they were applied
+	Coupons []Coupon `json:"-"`
+	// ShippingMethodID is the cart's chosen shipping method, 0 when none is set
+	ShippingMethodID int64 `json:"-"`
+	// Destination is where the cart ships to, used to resolve its tax rates
+	Destination ShippingDestination `json:"destination"`
+	// TaxRates are the rates charged on the taxable subtotal; nil uses the flat default rate
+	TaxRates []TaxRate `json:"-"`
+	// TaxLines break the tax amount out per rate
+	TaxLines []TaxLine `json:"tax_lines"`
+}
+
+// TaxRate is a tax charged in a jurisdiction, e.g. 0.0725 for 7.25%
+type TaxRate struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
 }
 
-// Wishlist represents a user's wishlist
+// TaxLine is the tax a cart owes at one rate
+type TaxLine struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// Wishlist represents a user's wishlist. A public wishlist has a ShareToken through
+// which anyone can view it; the token is cleared when the wishlist is made private.
 type Wishlist struct {
-	ID        int64     `json:"id" db:"id"`
-	UserID    int64     `json:"user_id" db:"user_id"`
-	Name      string    `json:"name" db:"name"`
-	IsPublic  bool      `json:"is_public" db:"is_public"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         int64     `json:"id" db:"id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	Name       string    `json:"name" db:"name"`
+	IsPublic   bool      `json:"is_public" db:"is_public"`
+	ShareToken *string   `json:"share_token" db:"share_token"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // WishlistItem represents items in a wishlist
@@ -81,6 +128,21 @@ type CartShipping struct {
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 }
 
+// ShippingDestination is where a cart would be shipped
+type ShippingDestination struct {
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+}
+
+// ShippingRate is a shipping method available for a cart and what it would cost
+type ShippingRate struct {
+	MethodID      int64   `json:"method_id"`
+	Method        string  `json:"method"`
+	Amount        float64 `json:"amount"`
+	EstimatedDays int     `json:"estimated_days"`
+}
+
 // CartAnalytics represents analytics data for carts
 type CartAnalytics struct {
 	TotalCarts          int64   `json:"total_carts"`
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// Shipping classes a product falls into for one unit
+const (
+	// ShippingClassDigital products are delivered electronically and never shipped
+	ShippingClassDigital = "digital"
+	// ShippingClassStandard products are billed on their actual weight
+	ShippingClassStandard = "standard"
+	// ShippingClassDimensional products are bulky for their weight and billed on their
+	// dimensional weight instead
+	ShippingClassDimensional = "dimensional"
+)
+
+// DefaultDimensionalDivisor is the cubic centimetres that count as one unit of
+// dimensional weight. 5000 is the common carrier figure for kilograms, so it assumes
+// product weights are in kg; catalogues weighed in pounds need about 2268.
+const DefaultDimensionalDivisor = 5000
+
+// ErrInvalidDimensions is returned for dimensions not written as length x width x height
+var ErrInvalidDimensions = errors.New("dimensions must be length x width x height")
+
+// dimensionsPattern matches "10x20x30", "10 x 20 x 30 cm", "10cm x 20cm x 30cm" or
+// "4*6*2.5in"; sides are in centimetres unless a unit follows them
+var dimensionsPattern = regexp.MustCompile(`^\s*` +
+	`(\d+(?:\.\d+)?)\s*(mm|cm|m|in)?\s*[xX×*]\s*` +
+	`(\d+(?:\.\d+)?)\s*(mm|cm|m|in)?\s*[xX×*]\s*` +
+	`(\d+(?:\.\d+)?)\s*(mm|cm|m|in)?\s*$`)
+
+// centimetresPer converts a dimensions unit to centimetres
+var centimetresPer = map[string]float64{"": 1, "cm": 1, "mm": 0.1, "m": 100, "in": 2.54}
+
+// ParcelDimensions are the length, width and height of one unit, in centimetres
+type ParcelDimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+}
+
+// ParseDimensions reads a product's dimensions string. Every side must be positive, and
+// sides given their own unit must all use the same one.
+func ParseDimensions(dimensions string) (ParcelDimensions, error) {
+	match := dimensionsPattern.FindStringSubmatch(dimensions)
+	if match == nil {
+		return ParcelDimensions{}, ErrInvalidDimensions
+	}
+
+	unit := ""
+	for _, sideUnit := range []string{match[2], match[4], match[6]} {
+		if sideUnit == "" {
+			continue
+		}
+		if unit != "" && sideUnit != unit {
+			return ParcelDimensions{}, ErrInvalidDimensions
+		}
+		unit = sideUnit
+	}
+
+	scale := centimetresPer[unit]
+	sides := make([]float64, 3)
+	for i := range sides {
+		side, err := strconv.ParseFloat(match[2*i+1], 64)
+		if err != nil || side <= 0 {
+			return ParcelDimensions{}, ErrInvalidDimensions
+		}
+		sides[i] = side * scale
+	}
+
+	return ParcelDimensions{Length: sides[0], Width: sides[1], Height: sides[2]}, nil
+}
+
+// DimensionalWeight is the weight carriers bill a parcel of this size at: its volume in
+// cubic centimetres over divisor. A divisor of zero or less uses DefaultDimensionalDivisor.
+func (d ParcelDimensions) DimensionalWeight(divisor float64) float64 {
+	if divisor <= 0 {
+		divisor = DefaultDimensionalDivisor
+	}
+	return d.Length * d.Width * d.Height / divisor
+}
+
+// ShippingProfile is how one unit of a product is charged for shipping
+type ShippingProfile struct {
+	Class string
+	// Weight is what shipping is billed on, the greater of the actual and dimensional weight
+	Weight float64
+	// DimensionalWeight is zero when the product has no usable dimensions
+	DimensionalWeight float64
+}
+
+// ShippingProfile derives the shipping class and billable weight of one unit. weight is
+// the unit's actual weight, which a variant may override; divisor is passed on to
+// DimensionalWeight. Digital products weigh nothing for shipping, and dimensions that do
+// not parse are ignored so older records still ship on their weight.
+func (p *Product) ShippingProfile(weight, divisor float64) ShippingProfile {
+	if p.IsDigital {
+		return ShippingProfile{Class: ShippingClassDigital}
+	}
+
+	profile := ShippingProfile{Class: ShippingClassStandard, Weight: weight}
+	if dimensions, err := ParseDimensions(p.Dimensions); err == nil {
+		profile.DimensionalWeight = dimensions.DimensionalWeight(divisor)
+		if profile.DimensionalWeight > weight {
+			profile.Class = ShippingClassDimensional
+			profile.Weight = profile.DimensionalWeight
+		}
+	}
+	return profile
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// IdempotencyKey remembers the response to a request sent with an Idempotency-Key header.
+// StatusCode is nil while the first request holding the key is still being handled.
+type IdempotencyKey struct {
+	ID           int64     `json:"id" db:"id"`
+	Scope        string    `json:"scope" db:"scope"`
+	Key          string    `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash  string    `json:"request_hash" db:"request_hash"`
+	StatusCode   *int      `json:"status_code,omitempty" db:"status_code"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	ResponseBody []byte    `json:"-" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
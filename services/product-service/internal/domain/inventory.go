@@ -18,6 +18,8 @@ type Inventory struct {
 	LastRestocked     time.Time `json:"last_restocked" db:"last_restocked"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy         *int64    `json:"updated_by" db:"updated_by"`
+	Version           int       `json:"version" db:"version"`
 }
 
 // InventoryMovement represents inventory movements (stock in/out)
@@ -33,7 +35,7 @@ type InventoryMovement struct {
 	ReferenceType    string    `json:"reference_type" db:"reference_type"`
 	Reason           string    `json:"reason" db:"reason"`
 	Notes            string    `json:"notes" db:"notes"`
-	CreatedBy        int64     `json:"created_by" db:"created_by"`
+	CreatedBy        *int64    `json:"created_by" db:"created_by"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -54,7 +56,7 @@ type InventoryAlert struct {
 	ProductID        int64  `json:"product_id" db:"product_id"`
 	ProductVariantID *int64 `json:"product_variant_id" db:"product_variant_id"`
 
-	AlertType         string     `json:"alert_type" db:"alert_type"` // low_stock, out_of_stock, reorder_point
+	AlertType         string     `json:"alert_type" db:"alert_type"` // low_stock, out_of_stock, restock_recommended, reorder_point
 	CurrentQuantity   int        `json:"current_quantity" db:"current_quantity"`
 	ThresholdQuantity int        `json:"threshold_quantity" db:"threshold_quantity"`
 	IsResolved        bool       `json:"is_resolved" db:"is_resolved"`
@@ -62,6 +64,76 @@ type InventoryAlert struct {
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 }
 
+// Alert types raised by the stock check. An item with no available stock is out of stock;
+// one at or below its reorder point is low on stock; and one that has also fallen to its
+// minimum stock level should be restocked.
+const (
+	AlertTypeLowStock           = "low_stock"
+	AlertTypeOutOfStock         = "out_of_stock"
+	AlertTypeRestockRecommended = "restock_recommended"
+)
+
+// StockNotification is a request to be told when an out-of-stock item is available again
+type StockNotification struct {
+	ID               int64      `json:"id" db:"id"`
+	UserID           *int64     `json:"user_id" db:"user_id"`
+	Email            string     `json:"email" db:"email"`
+	ProductID        int64      `json:"product_id" db:"product_id"`
+	ProductVariantID *int64     `json:"product_variant_id" db:"product_variant_id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	NotifiedAt       *time.Time `json:"notified_at" db:"notified_at"`
+}
+
+// RestockCandidate is an active product's stock record that is out of stock or at or
+// below its reorder point. InventoryID is nil for a product with no stock record.
+type RestockCandidate struct {
+	InventoryID       *int64 `json:"inventory_id" db:"inventory_id"`
+	ProductID         int64  `json:"product_id" db:"product_id"`
+	ProductVariantID  *int64 `json:"product_variant_id" db:"product_variant_id"`
+	ProductName       string `json:"product_name" db:"product_name"`
+	SKU               string `json:"sku" db:"sku"`
+	AvailableQuantity int    `json:"available_quantity" db:"available_quantity"`
+	ReorderPoint      int    `json:"reorder_point" db:"reorder_point"`
+}
+
+// InventoryDiscrepancy is a stock record whose reserved and available counts had drifted
+// from its reservations, with the counts it had before and after being corrected
+type InventoryDiscrepancy struct {
+	InventoryID               int64  `json:"inventory_id" db:"inventory_id"`
+	ProductID                 int64  `json:"product_id" db:"product_id"`
+	ProductVariantID          *int64 `json:"product_variant_id" db:"product_variant_id"`
+	Quantity                  int    `json:"quantity" db:"quantity"`
+	PreviousReservedQuantity  int    `json:"previous_reserved_quantity" db:"previous_reserved_quantity"`
+	PreviousAvailableQuantity int    `json:"previous_available_quantity" db:"previous_available_quantity"`
+	ReservedQuantity          int    `json:"reserved_quantity" db:"reserved_quantity"`
+	AvailableQuantity         int    `json:"available_quantity" db:"available_quantity"`
+}
+
+// StockChange reports the stock now available for a product or variant after a change
+type StockChange struct {
+	ProductID         int64     `json:"product_id" db:"product_id"`
+	ProductVariantID  *int64    `json:"product_variant_id" db:"product_variant_id"`
+	AvailableQuantity int       `json:"available_quantity" db:"available_quantity"`
+	OccurredAt        time.Time `json:"occurred_at" db:"-"`
+}
+
+// Inventory event types published to webhook subscriptions. A stock reserved event carries the
+// StockReservation, a stock released event the StockRelease, a low stock alert event the
+// InventoryAlert raised (of any alert type) and an inventory adjusted event the
+// InventoryMovement recorded.
+const (
+	EventStockReserved     = "inventory.stock_reserved"
+	EventStockReleased     = "inventory.stock_released"
+	EventLowStockAlert     = "inventory.low_stock_alert"
+	EventInventoryAdjusted = "inventory.adjusted"
+)
+
+// StockRelease identifies the reservation released, or the order whose reservations were
+type StockRelease struct {
+	ReservationID *int64 `json:"reservation_id,omitempty"`
+	OrderID       *int64 `json:"order_id,omitempty"`
+}
+
 // InventorySummary represents inventory summary statistics
 type InventorySummary struct {
 	TotalProducts     int64   `json:"total_products"`
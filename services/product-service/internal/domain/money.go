@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// currencyMinorDigits lists currencies whose minor unit is not hundredths
+var currencyMinorDigits = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// MinorUnitDigits returns the number of decimal places in a currency's minor unit
+func MinorUnitDigits(currency string) int {
+	if digits, ok := currencyMinorDigits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// ToMinorUnits converts an amount to integer minor units of the currency (cents for USD),
+// rounding half away from zero. The shortest decimal form of amount is used, so 0.1 is
+// exactly ten cents rather than its binary approximation.
+func ToMinorUnits(amount float64, currency string) int64 {
+	value, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(MinorUnitDigits(currency))), nil)
+	return roundHalfUp(value.Mul(value, new(big.Rat).SetInt(scale)))
+}
+
+// FromMinorUnits converts integer minor units back to an amount in the currency's major unit
+func FromMinorUnits(units int64, currency string) float64 {
+	return float64(units) / math.Pow10(MinorUnitDigits(currency))
+}
+
+// ApplyRate multiplies minor units by a rate such as a tax rate, rounding half away from zero
+func ApplyRate(units int64, rate float64) int64 {
+	value, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	return roundHalfUp(value.Mul(value, new(big.Rat).SetInt64(units)))
+}
+
+// ProRate returns units * part / whole, rounding half away from zero. A zero whole yields zero.
+func ProRate(units, part, whole int64) int64 {
+	if whole == 0 {
+		return 0
+	}
+	return roundHalfUp(new(big.Rat).SetFrac(big.NewInt(units*part), big.NewInt(whole)))
+}
+
+// roundHalfUp rounds a rational to the nearest integer, with halves rounded away from zero
+func roundHalfUp(value *big.Rat) int64 {
+	num := new(big.Int).Abs(value.Num())
+	den := value.Denom()
+
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient.Int64()
+}
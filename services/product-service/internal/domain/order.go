@@ -8,7 +8,8 @@ import (
 type Order struct {
 	ID                int64      `json:"id" db:"id"`
 	OrderNumber       string     `json:"order_number" db:"order_number"`
-	UserID            int64      `json:"user_id" db:"user_id"`
+	CartID            int64      `json:"cart_id" db:"cart_id"`                       // the cart the order was placed from
+	UserID            *int64     `json:"user_id" db:"user_id"`                       // null for guest orders
 	Status            string     `json:"status" db:"status"`                         // pending, confirmed, processing, shipped, delivered, cancelled, refunded
 	PaymentStatus     string     `json:"payment_status" db:"payment_status"`         // pending, paid, failed, refunded, partially_refunded
 	FulfillmentStatus string     `json:"fulfillment_status" db:"fulfillment_status"` // unfulfilled, partial, fulfilled
@@ -28,6 +29,17 @@ type Order struct {
 	CancelledAt       *time.Time `json:"cancelled_at" db:"cancelled_at"`
 }
 
+// Order statuses set at checkout. An order is pending while its stock is being reserved
+// and confirmed once the stock has been committed.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+)
+
+// EventOrderPlaced is published to other services once an order has been placed. It
+// carries the order as returned by the API.
+const EventOrderPlaced = "order.placed"
+
 // OrderItem represents individual items in an order
 type OrderItem struct {
 	ID               int64   `json:"id" db:"id"`
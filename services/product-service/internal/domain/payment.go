@@ -85,6 +85,7 @@ type Coupon struct {
 	MinOrderAmount    float64    `json:"min_order_amount" db:"min_order_amount"`
 	MaxDiscountAmount float64    `json:"max_discount_amount" db:"max_discount_amount"`
 	UsageLimit        int        `json:"usage_limit" db:"usage_limit"`
+	PerUserLimit      int        `json:"per_user_limit" db:"per_user_limit"`
 	UsedCount         int        `json:"used_count" db:"used_count"`
 	IsActive          bool       `json:"is_active" db:"is_active"`
 	StartsAt          time.Time  `json:"starts_at" db:"starts_at"`
@@ -93,6 +94,15 @@ type Coupon struct {
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// CouponRedemption records a coupon being used by a cart
+type CouponRedemption struct {
+	ID         int64     `json:"id" db:"id"`
+	CouponID   int64     `json:"coupon_id" db:"coupon_id"`
+	UserID     *int64    `json:"user_id" db:"user_id"`
+	CartID     int64     `json:"cart_id" db:"cart_id"`
+	RedeemedAt time.Time `json:"redeemed_at" db:"redeemed_at"`
+}
+
 // CouponUsage represents coupon usage tracking
 type CouponUsage struct {
 	ID        int64     `json:"id" db:"id"`
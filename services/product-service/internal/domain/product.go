@@ -2,6 +2,8 @@ package domain
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Product represents a product in the ecommerce system
@@ -12,26 +14,40 @@ type Product struct {
 	ShortDesc   string `json:"short_description" db:"short_description"`
 	SKU         string `json:"sku" db:"sku"`
 
-	Price            float64   `json:"price" db:"price"`
-	ComparePrice     float64   `json:"compare_price" db:"compare_price"`
-	CostPrice        float64   `json:"cost_price" db:"cost_price"`
-	Weight           float64   `json:"weight" db:"weight"`
-	Dimensions       string    `json:"dimensions" db:"dimensions"`
-	IsActive         bool      `json:"is_active" db:"is_active"`
-	IsDigital        bool      `json:"is_digital" db:"is_digital"`
-	RequiresShipping bool      `json:"requires_shipping" db:"requires_shipping"`
-	Taxable          bool      `json:"taxable" db:"taxable"`
-	TrackQuantity    bool      `json:"track_quantity" db:"track_quantity"`
-	Quantity         int       `json:"quantity" db:"quantity"`
-	MinQuantity      int       `json:"min_quantity" db:"min_quantity"`
-	MaxQuantity      int       `json:"max_quantity" db:"max_quantity"`
-	MetaTitle        string    `json:"meta_title" db:"meta_title"`
-	MetaDesc         string    `json:"meta_description" db:"meta_description"`
-	Tags             string    `json:"tags" db:"tags"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	Price            float64        `json:"price" db:"price"`
+	ComparePrice     float64        `json:"compare_price" db:"compare_price"`
+	CostPrice        float64        `json:"cost_price" db:"cost_price"`
+	Weight           float64        `json:"weight" db:"weight"`
+	Dimensions       string         `json:"dimensions" db:"dimensions"`
+	IsActive         bool           `json:"is_active" db:"is_active"`
+	IsDigital        bool           `json:"is_digital" db:"is_digital"`
+	IsFree           bool           `json:"is_free" db:"is_free"`
+	RequiresShipping bool           `json:"requires_shipping" db:"requires_shipping"`
+	Taxable          bool           `json:"taxable" db:"taxable"`
+	TrackQuantity    bool           `json:"track_quantity" db:"track_quantity"`
+	Quantity         int            `json:"quantity" db:"quantity"`
+	MinQuantity      int            `json:"min_quantity" db:"min_quantity"`
+	MaxQuantity      int            `json:"max_quantity" db:"max_quantity"`
+	MetaTitle        string         `json:"meta_title" db:"meta_title"`
+	MetaDesc         string         `json:"meta_description" db:"meta_description"`
+	Tags             pq.StringArray `json:"tags" db:"tags"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+	UpdatedBy        *int64         `json:"updated_by" db:"updated_by"`
+	Version          int            `json:"version" db:"version"`
+
+	// Images in display order and the primary one's URL; loaded separately from the row
+	Images          []ProductImage `json:"images" db:"-"`
+	PrimaryImageURL *string        `json:"primary_image_url" db:"-"`
 }
 
+// Product search modes. Full-text matches stemmed words and ranks by relevance; trigram
+// matches substrings, which suits prefixes too short for full-text search.
+const (
+	ProductSearchFullText = "fulltext"
+	ProductSearchTrigram  = "trigram"
+)
+
 // ProductVariant represents different variations of a product (size, color, etc.)
 type ProductVariant struct {
 	ID           int64   `json:"id" db:"id"`
@@ -48,6 +64,29 @@ type ProductVariant struct {
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
 }
+
+// VariantAttribute represents a single attribute (size, color, etc.) of a product variant
+type VariantAttribute struct {
+	ID            int64     `json:"id" db:"id"`
+	VariantID     int64     `json:"variant_id" db:"variant_id"`
+	AttributeName string    `json:"attribute_name" db:"attribute_name"`
+	Value         string    `json:"value" db:"value"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ProductSuggestion is a lightweight product match used for search autocomplete
+type ProductSuggestion struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	SKU  string `json:"sku" db:"sku"`
+}
+
+// VariantAttributeOption lists the selectable values of an attribute across a product's variants
+type VariantAttributeOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
 // ProductAttribute represents product attributes (color, size, material, etc.)
 type ProductAttribute struct {
 	ID    int64  `json:"id" db:"id"`
@@ -64,14 +103,17 @@ type ProductAttributeValue struct {
 	Value       string `json:"value" db:"value"`
 }
 
-// ProductImage represents product images
+// ProductImage represents product images. A product with images has exactly one
+// primary image; Position orders them for display.
 type ProductImage struct {
-	ID        int64  `json:"id" db:"id"`
-	ProductID int64  `json:"product_id" db:"product_id"`
-	URL       string `json:"url" db:"url"`
-	Alt       string `json:"alt" db:"alt"`
-	Position  int    `json:"position" db:"position"`
-	IsPrimary bool   `json:"is_primary" db:"is_primary"`
+	ID        int64     `json:"id" db:"id"`
+	ProductID int64     `json:"product_id" db:"product_id"`
+	URL       string    `json:"url" db:"url"`
+	Alt       string    `json:"alt" db:"alt"`
+	Position  int       `json:"position" db:"position"`
+	IsPrimary bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // ProductCategory represents the many-to-many relationship between products and categories
@@ -92,6 +134,30 @@ type ProductFilter struct {
 	InStock    *bool    `json:"in_stock"`
 	Search     string   `json:"search"`
 	Tags       []string `json:"tags"`
-	SortBy     string   `json:"sort_by"`    // name, price, created_at, etc.
+	SortBy     string   `json:"sort_by"`    // one of ProductSortFields
 	SortOrder  string   `json:"sort_order"` // asc, desc
 }
+
+// ProductCursor marks the last product of a page listed newest first, by created_at
+// then id. The next page starts after it.
+type ProductCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// ProductSortFields lists the fields products can be sorted by. Each is also the
+// column name used in the ORDER BY clause.
+var ProductSortFields = []string{"name", "price", "created_at", "updated_at", "sku"}
+
+// Product event types published to other services. Created and updated events carry
+// the Product as saved and a deleted event the ProductDeleted.
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+)
+
+// ProductDeleted identifies a deleted product
+type ProductDeleted struct {
+	ProductID int64 `json:"product_id"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookSubscription represents a partner endpoint subscribed to events
+type WebhookSubscription struct {
+	ID         int64          `json:"id" db:"id"`
+	URL        string         `json:"url" db:"url"`
+	EventTypes pq.StringArray `json:"event_types" db:"event_types"`
+	Secret     string         `json:"-" db:"secret"`
+	IsActive   bool           `json:"is_active" db:"is_active"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookEvent is the JSON body delivered to subscribers
+type WebhookEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookDelivery is a signed-and-sent unit of work: one event for one subscription
+type WebhookDelivery struct {
+	Subscription *WebhookSubscription
+	EventID      string
+	EventType    string
+	Payload      []byte
+}
+
+// WebhookDeliveryAttempt records the outcome of a single delivery attempt
+type WebhookDeliveryAttempt struct {
+	ID             int64     `json:"id" db:"id"`
+	SubscriptionID int64     `json:"subscription_id" db:"subscription_id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	Attempt        int       `json:"attempt" db:"attempt"`
+	StatusCode     *int      `json:"status_code" db:"status_code"`
+	Success        bool      `json:"success" db:"success"`
+	Error          *string   `json:"error" db:"error"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
@@ -4,12 +4,24 @@ package dto
 type CreateCartRequest struct {
 	UserID    *int64 `json:"user_id" validate:"omitempty"`
 	SessionID string `json:"session_id" validate:"omitempty"`
-	Currency  string `json:"currency" validate:"required,len=3"`
+	Currency  string `json:"currency" validate:"required,currency"`
 }
 
 // UpdateCartRequest represents the request to update an existing cart
 type UpdateCartRequest struct {
-	Currency *string `json:"currency" validate:"omitempty,len=3"`
+	Currency *string `json:"currency" validate:"omitempty,currency"`
+}
+
+// SetCartTaxExemptRequest represents the request, made by staff, to mark a cart tax exempt
+type SetCartTaxExemptRequest struct {
+	TaxExempt bool `json:"tax_exempt"`
+}
+
+// SetCartExpiryRequest represents the request to override a cart's expiry.
+// Exactly one of ExpiresAt (RFC3339) or NoExpiry must be set.
+type SetCartExpiryRequest struct {
+	ExpiresAt string `json:"expires_at" validate:"required_without=NoExpiry,excluded_with=NoExpiry"`
+	NoExpiry  bool   `json:"no_expiry"`
 }
 
 // CartResponse represents the response for cart data
@@ -21,6 +33,7 @@ type CartResponse struct {
 	CreatedAt string  `json:"created_at"`
 	UpdatedAt string  `json:"updated_at"`
 	ExpiresAt *string `json:"expires_at"`
+	TaxExempt bool    `json:"tax_exempt"`
 }
 
 // AddToCartRequest represents the request to add an item to cart
@@ -28,6 +41,19 @@ type AddToCartRequest struct {
 	ProductID        int64  `json:"product_id" validate:"required"`
 	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
 	Quantity         int    `json:"quantity" validate:"required,min=1"`
+	// ClampToStock adds as many units as are in stock instead of rejecting the request
+	ClampToStock bool `json:"clamp_to_stock"`
+}
+
+// InsufficientStockResponse is the error body of a request asking for more units than
+// are in stock
+type InsufficientStockResponse struct {
+	Message          string `json:"message"`
+	ProductID        int64  `json:"product_id"`
+	ProductVariantID *int64 `json:"product_variant_id"`
+	Requested        int    `json:"requested"`
+	Available        int    `json:"available"`
+	MaxAddable       int    `json:"max_addable"`
 }
 
 // UpdateCartItemRequest represents the request to update a cart item
@@ -35,6 +61,15 @@ type UpdateCartItemRequest struct {
 	Quantity *int `json:"quantity" validate:"omitempty,min=1"`
 }
 
+// AdjustCartItemQuantityRequest moves a cart item's quantity up or down by Amount units
+type AdjustCartItemQuantityRequest struct {
+	// Amount defaults to 1
+	Amount int `json:"amount" validate:"omitempty,min=1"`
+	// ClampToStock increments by as many units as are in stock instead of rejecting the
+	// request. Decrements ignore it.
+	ClampToStock bool `json:"clamp_to_stock"`
+}
+
 // CartItemResponse represents the response for cart item data
 type CartItemResponse struct {
 	ID               int64   `json:"id"`
@@ -50,15 +85,56 @@ type CartItemResponse struct {
 
 // CartSummaryResponse represents the response for cart summary
 type CartSummaryResponse struct {
-	CartID         int64              `json:"cart_id"`
-	ItemCount      int                `json:"item_count"`
-	Subtotal       float64            `json:"subtotal"`
-	TaxAmount      float64            `json:"tax_amount"`
-	ShippingAmount float64            `json:"shipping_amount"`
-	DiscountAmount float64            `json:"discount_amount"`
-	TotalAmount    float64            `json:"total_amount"`
-	Currency       string             `json:"currency"`
-	Items          []CartItemResponse `json:"items"`
+	CartID int64 `json:"cart_id"`
+	CartTotalsResponse
+	Items []CartItemResponse `json:"items"`
+}
+
+// CartTotalsResponse represents the computed amounts of a cart
+type CartTotalsResponse struct {
+	ItemCount      int          `json:"item_count"`
+	Subtotal       float64      `json:"subtotal"`
+	TaxAmount      float64      `json:"tax_amount"`
+	Tax            TaxBreakdown `json:"tax"`
+	ShippingAmount float64      `json:"shipping_amount"`
+	FreeShipping   bool         `json:"free_shipping"`
+	DiscountAmount float64      `json:"discount_amount"`
+	TotalAmount    float64      `json:"total_amount"`
+	Currency       string       `json:"currency"`
+}
+
+// FullCartResponse represents everything needed to render a cart page in one response
+type FullCartResponse struct {
+	Cart     CartResponse           `json:"cart"`
+	Items    []FullCartItemResponse `json:"items"`
+	Coupons  []CartCouponResponse   `json:"coupons"`
+	Shipping *CartShippingResponse  `json:"shipping"`
+	Summary  CartTotalsResponse     `json:"summary"`
+}
+
+// FullCartItemResponse represents a cart item with the product details shown next to it
+type FullCartItemResponse struct {
+	CartItemResponse
+	ProductName  string  `json:"product_name"`
+	SKU          string  `json:"sku"`
+	ProductImage *string `json:"product_image"`
+}
+
+// TaxBreakdown itemizes how a cart's tax was calculated
+type TaxBreakdown struct {
+	TaxableSubtotal float64 `json:"taxable_subtotal"`
+	TaxRate         float64 `json:"tax_rate"`
+	TaxAmount       float64 `json:"tax_amount"`
+	TaxExempt       bool    `json:"tax_exempt"`
+	// Lines break the tax amount out per rate for receipts
+	Lines []TaxLineResponse `json:"lines"`
+}
+
+// TaxLineResponse represents the tax charged at one rate
+type TaxLineResponse struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
 }
 
 // ApplyCouponRequest represents the request to apply a coupon to cart
@@ -80,20 +156,36 @@ type CartCouponResponse struct {
 	CreatedAt      string  `json:"created_at"`
 }
 
+// ListCartItemsResponse represents the response for listing cart items
+type ListCartItemsResponse struct {
+	Items      []CartItemResponse `json:"items"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// ListCartCouponsResponse represents the response for listing cart coupons
+type ListCartCouponsResponse struct {
+	Coupons    []CartCouponResponse `json:"coupons"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	Limit      int                  `json:"limit"`
+	TotalPages int                  `json:"total_pages"`
+}
+
 // SetShippingRequest represents the request to set shipping for cart
 type SetShippingRequest struct {
-	ShippingMethodID int64   `json:"shipping_method_id" validate:"required"`
-	ShippingMethod   string  `json:"shipping_method" validate:"required,min=1,max=100"`
-	ShippingAmount   float64 `json:"shipping_amount" validate:"required,min=0"`
-	EstimatedDays    int     `json:"estimated_days" validate:"required,min=1"`
+	ShippingMethodID int64  `json:"shipping_method_id" validate:"required"`
+	ShippingMethod   string `json:"shipping_method" validate:"required,min=1,max=100"`
+	EstimatedDays    int    `json:"estimated_days" validate:"required,min=1"`
 }
 
 // UpdateShippingRequest represents the request to update shipping for cart
 type UpdateShippingRequest struct {
-	ShippingMethodID *int64   `json:"shipping_method_id" validate:"omitempty"`
-	ShippingMethod   *string  `json:"shipping_method" validate:"omitempty,min=1,max=100"`
-	ShippingAmount   *float64 `json:"shipping_amount" validate:"omitempty,min=0"`
-	EstimatedDays    *int     `json:"estimated_days" validate:"omitempty,min=1"`
+	ShippingMethodID *int64  `json:"shipping_method_id" validate:"omitempty"`
+	ShippingMethod   *string `json:"shipping_method" validate:"omitempty,min=1,max=100"`
+	EstimatedDays    *int    `json:"estimated_days" validate:"omitempty,min=1"`
 }
 
 // CartShippingResponse represents the response for cart shipping data
@@ -107,6 +199,27 @@ type CartShippingResponse struct {
 	CreatedAt        string  `json:"created_at"`
 }
 
+// ShippingOptionsRequest represents the destination to quote shipping options for
+type ShippingOptionsRequest struct {
+	Country    string `json:"country" validate:"required,len=2,alpha"`
+	PostalCode string `json:"postal_code" validate:"omitempty,max=20"`
+}
+
+// SetCartAddressRequest represents where a cart ships to, which decides the tax it pays
+type SetCartAddressRequest struct {
+	Country    string `json:"country" validate:"required,len=2,alpha"`
+	State      string `json:"state" validate:"omitempty,max=100"`
+	PostalCode string `json:"postal_code" validate:"omitempty,max=20"`
+}
+
+// ShippingOptionResponse represents a shipping method available for a cart
+type ShippingOptionResponse struct {
+	ShippingMethodID int64   `json:"shipping_method_id"`
+	ShippingMethod   string  `json:"shipping_method"`
+	ShippingAmount   float64 `json:"shipping_amount"`
+	EstimatedDays    int     `json:"estimated_days"`
+}
+
 // Wishlist Management DTOs
 
 // CreateWishlistRequest represents the request to create a new wishlist
@@ -121,14 +234,16 @@ type UpdateWishlistRequest struct {
 	IsPublic *bool   `json:"is_public"`
 }
 
-// WishlistResponse represents the response for wishlist data
+// WishlistResponse represents the response for wishlist data. ShareToken is set while
+// the wishlist is public.
 type WishlistResponse struct {
-	ID        int64  `json:"id"`
-	UserID    int64  `json:"user_id"`
-	Name      string `json:"name"`
-	IsPublic  bool   `json:"is_public"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID         int64   `json:"id"`
+	UserID     int64   `json:"user_id"`
+	Name       string  `json:"name"`
+	IsPublic   bool    `json:"is_public"`
+	ShareToken *string `json:"share_token"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
 }
 
 // AddToWishlistRequest represents the request to add an item to wishlist
@@ -171,6 +286,32 @@ type ListWishlistItemsResponse struct {
 	TotalPages int                    `json:"total_pages"`
 }
 
+// SharedWishlistResponse is the read-only view of a public wishlist opened through its
+// share link. It leaves out the owner and the wishlist's IDs.
+type SharedWishlistResponse struct {
+	Name       string                       `json:"name"`
+	Items      []SharedWishlistItemResponse `json:"items"`
+	Total      int64                        `json:"total"`
+	Page       int                          `json:"page"`
+	Limit      int                          `json:"limit"`
+	TotalPages int                          `json:"total_pages"`
+	UpdatedAt  string                       `json:"updated_at"`
+}
+
+// SharedWishlistItemResponse represents a shared wishlist item with the product's
+// current details. Available is false once the product or variant is deactivated or
+// removed, in which case only the IDs are set.
+type SharedWishlistItemResponse struct {
+	ProductID        int64   `json:"product_id"`
+	ProductVariantID *int64  `json:"product_variant_id"`
+	ProductName      string  `json:"product_name"`
+	SKU              string  `json:"sku"`
+	Price            float64 `json:"price"`
+	ProductImage     *string `json:"product_image"`
+	Available        bool    `json:"available"`
+	Notes            string  `json:"notes"`
+}
+
 // Cart Operations DTOs
 
 // MergeCartRequest represents the request to merge carts
@@ -193,3 +334,45 @@ type CartAnalyticsResponse struct {
 	ConversionRate      float64 `json:"conversion_rate"`
 	AverageItemsPerCart float64 `json:"average_items_per_cart"`
 }
+
+// CartItemIssue describes why a cart item cannot be checked out
+type CartItemIssue struct {
+	CartItemID       int64  `json:"cart_item_id"`
+	ProductID        int64  `json:"product_id"`
+	ProductVariantID *int64 `json:"product_variant_id"`
+	Reason           string `json:"reason"`
+}
+
+// CartCouponIssue reports an applied coupon that no longer applies to the cart
+type CartCouponIssue struct {
+	CouponCode string `json:"coupon_code"`
+	Reason     string `json:"reason"`
+}
+
+// CartValidationResponse reports whether a cart can proceed to checkout
+type CartValidationResponse struct {
+	CartID       int64             `json:"cart_id"`
+	Valid        bool              `json:"valid"`
+	Issues       []CartItemIssue   `json:"issues"`
+	CouponIssues []CartCouponIssue `json:"coupon_issues"`
+
+	// Set when the item subtotal is below the minimum order for the cart's currency
+	MinimumOrderAmount    float64 `json:"minimum_order_amount,omitempty"`
+	MinimumOrderShortfall float64 `json:"minimum_order_shortfall,omitempty"`
+	// Set when the cart's shipping method can no longer carry its items
+	ShippingUnavailable bool `json:"shipping_unavailable,omitempty"`
+}
+
+// DeduplicateCartResponse reports the outcome of collapsing duplicate items in a cart
+type DeduplicateCartResponse struct {
+	CartID       int64 `json:"cart_id"`
+	ItemsMerged  int   `json:"items_merged"`
+	ItemsRemoved int   `json:"items_removed"`
+}
+
+// RecalculateCartsResponse reports the outcome of repricing all active carts
+type RecalculateCartsResponse struct {
+	CartsScanned int `json:"carts_scanned"`
+	CartsUpdated int `json:"carts_updated"`
+	ItemsChanged int `json:"items_changed"`
+}
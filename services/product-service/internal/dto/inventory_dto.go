@@ -18,6 +18,9 @@ type UpdateInventoryRequest struct {
 	MinStockLevel *int `json:"min_stock_level" validate:"omitempty,min=0"`
 	MaxStockLevel *int `json:"max_stock_level" validate:"omitempty,min=0"`
 	ReorderPoint  *int `json:"reorder_point" validate:"omitempty,min=0"`
+
+	// Version is the inventory version the client last read; stale versions are rejected
+	Version *int `json:"version" validate:"required,min=1"`
 }
 
 // InventoryResponse represents the response for inventory data
@@ -34,6 +37,7 @@ type InventoryResponse struct {
 	LastRestocked     string `json:"last_restocked"`
 	CreatedAt         string `json:"created_at"`
 	UpdatedAt         string `json:"updated_at"`
+	Version           int    `json:"version"`
 }
 
 // StockMovementRequest represents the request to record stock movement
@@ -46,7 +50,51 @@ type StockMovementRequest struct {
 	ReferenceType    *string `json:"reference_type" validate:"omitempty,max=50"`
 	Reason           *string `json:"reason" validate:"omitempty,max=255"`
 	Notes            *string `json:"notes" validate:"omitempty,max=1000"`
-	CreatedBy        *int64  `json:"created_by" validate:"omitempty"`
+}
+
+// SetInventoryQuantityRequest represents the request to set inventory to an exact quantity
+type SetInventoryQuantityRequest struct {
+	ProductID        int64  `json:"product_id" validate:"required"`
+	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
+	Quantity         int    `json:"quantity" validate:"min=0"`
+	Reason           string `json:"reason" validate:"required,max=255"`
+}
+
+// RestockRequest represents the request to receive new stock for a product or variant
+type RestockRequest struct {
+	ProductID        int64  `json:"product_id" validate:"required"`
+	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
+	Quantity         int    `json:"quantity" validate:"required,min=1"`
+	Reference        string `json:"reference" validate:"omitempty,max=255"`
+}
+
+// AdjustStockRequest represents the request to change stock by a signed amount, such as
+// -2 for damaged units or +3 for units found
+type AdjustStockRequest struct {
+	ProductID        int64  `json:"product_id" validate:"required"`
+	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
+	Delta            int    `json:"delta" validate:"required"`
+	Reason           string `json:"reason" validate:"required,max=255"`
+	Reference        string `json:"reference" validate:"omitempty,max=255"`
+}
+
+// StockNotificationRequest represents the request to be notified when an item is back in stock.
+// The subscriber is the signed-in user, or the email given by a guest.
+type StockNotificationRequest struct {
+	ProductID        int64  `json:"product_id" validate:"required"`
+	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
+	Email            string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+// StockNotificationResponse represents the response for stock notification data
+type StockNotificationResponse struct {
+	ID               int64   `json:"id"`
+	UserID           *int64  `json:"user_id"`
+	Email            string  `json:"email"`
+	ProductID        int64   `json:"product_id"`
+	ProductVariantID *int64  `json:"product_variant_id"`
+	CreatedAt        string  `json:"created_at"`
+	NotifiedAt       *string `json:"notified_at"`
 }
 
 // StockMovementResponse represents the response for stock movement data
@@ -72,7 +120,7 @@ type ReserveStockRequest struct {
 	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
 	OrderID          int64  `json:"order_id" validate:"required"`
 	Quantity         int    `json:"quantity" validate:"required,min=1"`
-	ExpiresAt        string `json:"expires_at" validate:"required"`
+	ExpiresAt        string `json:"expires_at" validate:"omitempty"`
 }
 
 // ReserveStockResponse represents the response for stock reservation
@@ -86,6 +134,11 @@ type ReserveStockResponse struct {
 	CreatedAt        string `json:"created_at"`
 }
 
+// ExtendReservationRequest represents the request to extend a stock reservation
+type ExtendReservationRequest struct {
+	ExtendBySeconds int `json:"extend_by_seconds" validate:"required,min=1"`
+}
+
 // ReleaseStockRequest represents the request to release reserved stock
 type ReleaseStockRequest struct {
 	ReservationID *int64 `json:"reservation_id" validate:"omitempty"`
@@ -124,6 +177,27 @@ type ListInventoryResponse struct {
 	TotalPages int                 `json:"total_pages"`
 }
 
+// RestockCandidateResponse represents a stock record that needs restocking
+type RestockCandidateResponse struct {
+	InventoryID       *int64 `json:"inventory_id"`
+	ProductID         int64  `json:"product_id"`
+	ProductVariantID  *int64 `json:"product_variant_id"`
+	ProductName       string `json:"product_name"`
+	SKU               string `json:"sku"`
+	AvailableQuantity int    `json:"available_quantity"`
+	ReorderPoint      int    `json:"reorder_point"`
+	OutOfStock        bool   `json:"out_of_stock"`
+}
+
+// ListRestockCandidatesResponse represents the response for listing products needing restock
+type ListRestockCandidatesResponse struct {
+	Items      []RestockCandidateResponse `json:"items"`
+	Total      int64                      `json:"total"`
+	Page       int                        `json:"page"`
+	Limit      int                        `json:"limit"`
+	TotalPages int                        `json:"total_pages"`
+}
+
 // ListStockMovementsRequest represents the request to list stock movements
 type ListStockMovementsRequest struct {
 	ProductID        *int64  `json:"product_id" validate:"omitempty"`
@@ -159,14 +233,16 @@ type InventorySummaryResponse struct {
 
 // BulkStockUpdateRequest represents the request for bulk stock updates
 type BulkStockUpdateRequest struct {
-	Updates []StockUpdateItem `json:"updates" validate:"required,min=1"`
+	Updates []StockUpdateItem `json:"updates" validate:"required,min=1,dive"`
+	// Atomic rolls back the whole batch when any item fails instead of skipping that item
+	Atomic bool `json:"atomic"`
 }
 
 // StockUpdateItem represents a single stock update item
 type StockUpdateItem struct {
 	ProductID        int64  `json:"product_id" validate:"required"`
 	ProductVariantID *int64 `json:"product_variant_id" validate:"omitempty"`
-	Quantity         int    `json:"quantity" validate:"required"`
+	Quantity         int    `json:"quantity" validate:"min=0"`
 	MovementType     string `json:"movement_type" validate:"required,oneof=in out adjustment"`
 	Reason           string `json:"reason" validate:"required,max=255"`
 	Notes            string `json:"notes" validate:"omitempty,max=1000"`
@@ -0,0 +1,32 @@
+package dto
+
+// OrderResponse represents an order placed from a cart, with the amounts the cart was
+// priced at when the order was placed
+type OrderResponse struct {
+	ID             int64               `json:"id"`
+	OrderNumber    string              `json:"order_number"`
+	CartID         int64               `json:"cart_id"`
+	UserID         *int64              `json:"user_id"`
+	Status         string              `json:"status"`
+	Subtotal       float64             `json:"subtotal"`
+	TaxAmount      float64             `json:"tax_amount"`
+	ShippingAmount float64             `json:"shipping_amount"`
+	DiscountAmount float64             `json:"discount_amount"`
+	TotalAmount    float64             `json:"total_amount"`
+	Currency       string              `json:"currency"`
+	Items          []OrderItemResponse `json:"items"`
+	CreatedAt      string              `json:"created_at"`
+	ConfirmedAt    *string             `json:"confirmed_at"`
+}
+
+// OrderItemResponse represents an order line as it was in the cart
+type OrderItemResponse struct {
+	ID               int64   `json:"id"`
+	ProductID        int64   `json:"product_id"`
+	ProductVariantID *int64  `json:"product_variant_id"`
+	ProductName      string  `json:"product_name"`
+	ProductSKU       string  `json:"product_sku"`
+	Quantity         int     `json:"quantity"`
+	UnitPrice        float64 `json:"unit_price"`
+	TotalPrice       float64 `json:"total_price"`
+}
@@ -2,27 +2,31 @@ package dto
 
 // CreateProductRequest represents the request to create a new product
 type CreateProductRequest struct {
-	Name             string  `json:"name" validate:"required,min=1,max=255"`
-	Description      string  `json:"description" validate:"required,min=1,max=5000"`
-	ShortDesc        string  `json:"short_description" validate:"omitempty,max=500"`
-	SKU              string  `json:"sku" validate:"required,sku"`
-	Price            float64 `json:"price" validate:"required,price"`
-	ComparePrice     float64 `json:"compare_price" validate:"omitempty,min=0"`
-	CostPrice        float64 `json:"cost_price" validate:"omitempty,min=0"`
-	Weight           float64 `json:"weight" validate:"omitempty,weight"`
-	Dimensions       string  `json:"dimensions" validate:"omitempty,dimensions"`
-	IsActive         bool    `json:"is_active"`
-	IsDigital        bool    `json:"is_digital"`
-	RequiresShipping bool    `json:"requires_shipping"`
-	Taxable          bool    `json:"taxable"`
-	TrackQuantity    bool    `json:"track_quantity"`
-	Quantity         int     `json:"quantity" validate:"omitempty,min=0"`
-	MinQuantity      int     `json:"min_quantity" validate:"omitempty,min=0"`
-	MaxQuantity      int     `json:"max_quantity" validate:"omitempty,min=0"`
-	MetaTitle        string  `json:"meta_title" validate:"omitempty,meta_title"`
-	MetaDescription  string  `json:"meta_description" validate:"omitempty,meta_description"`
-	Tags             string  `json:"tags" validate:"omitempty,tags"`
-	CategoryIDs      []int64 `json:"category_ids" validate:"omitempty"`
+	Name             string   `json:"name" validate:"required,min=1,max=255"`
+	Description      string   `json:"description" validate:"required,min=1,max=5000"`
+	ShortDesc        string   `json:"short_description" validate:"omitempty,max=500"`
+	SKU              string   `json:"sku" validate:"required,sku"`
+	Price            float64  `json:"price" validate:"required,price"`
+	ComparePrice     float64  `json:"compare_price" validate:"omitempty,min=0"`
+	CostPrice        float64  `json:"cost_price" validate:"omitempty,min=0"`
+	Weight           float64  `json:"weight" validate:"omitempty,weight"`
+	Dimensions       string   `json:"dimensions" validate:"omitempty,dimensions"`
+	IsActive         bool     `json:"is_active"`
+	IsDigital        bool     `json:"is_digital"`
+	IsFree           bool     `json:"is_free"`
+	RequiresShipping bool     `json:"requires_shipping"`
+	Taxable          bool     `json:"taxable"`
+	TrackQuantity    bool     `json:"track_quantity"`
+	Quantity         int      `json:"quantity" validate:"omitempty,min=0"`
+	MinQuantity      int      `json:"min_quantity" validate:"omitempty,min=0"`
+	MaxQuantity      int      `json:"max_quantity" validate:"omitempty,min=0"`
+	MetaTitle        string   `json:"meta_title" validate:"omitempty,meta_title"`
+	MetaDescription  string   `json:"meta_description" validate:"omitempty,meta_description"`
+	Tags             []string `json:"tags" validate:"omitempty,max=50,dive,tag"`
+	CategoryIDs      []int64  `json:"category_ids" validate:"omitempty"`
+
+	// CreateInventory also creates the product's inventory record, seeded with Quantity
+	CreateInventory bool `json:"create_inventory"`
 }
 
 // UpdateProductRequest represents the request to update an existing product
@@ -38,6 +42,7 @@ type UpdateProductRequest struct {
 	Dimensions       *string  `json:"dimensions" validate:"omitempty,dimensions"`
 	IsActive         *bool    `json:"is_active"`
 	IsDigital        *bool    `json:"is_digital"`
+	IsFree           *bool    `json:"is_free"`
 	RequiresShipping *bool    `json:"requires_shipping"`
 	Taxable          *bool    `json:"taxable"`
 	TrackQuantity    *bool    `json:"track_quantity"`
@@ -46,36 +51,71 @@ type UpdateProductRequest struct {
 	MaxQuantity      *int     `json:"max_quantity" validate:"omitempty,min=0"`
 	MetaTitle        *string  `json:"meta_title" validate:"omitempty,meta_title"`
 	MetaDescription  *string  `json:"meta_description" validate:"omitempty,meta_description"`
-	Tags             *string  `json:"tags" validate:"omitempty,tags"`
+	Tags             []string `json:"tags" validate:"omitempty,max=50,dive,tag"`
 	CategoryIDs      []int64  `json:"category_ids" validate:"omitempty"`
+
+	// Version is the product version the client last read; stale versions are rejected
+	Version *int `json:"version" validate:"required,min=1"`
+
+	// ClearFields lists optional fields to reset to their empty value.
+	// A field cannot be both set and cleared in the same request.
+	ClearFields []string `json:"clear_fields" validate:"omitempty,dive,oneof=short_description compare_price cost_price weight dimensions meta_title meta_description tags"`
 }
 
 // ProductResponse represents the response for product data
 type ProductResponse struct {
-	ID               int64   `json:"id"`
-	Name             string  `json:"name"`
-	Description      string  `json:"description"`
-	ShortDesc        string  `json:"short_description"`
-	SKU              string  `json:"sku"`
-	Price            float64 `json:"price"`
-	ComparePrice     float64 `json:"compare_price"`
-	CostPrice        float64 `json:"cost_price"`
-	Weight           float64 `json:"weight"`
-	Dimensions       string  `json:"dimensions"`
-	IsActive         bool    `json:"is_active"`
-	IsDigital        bool    `json:"is_digital"`
-	RequiresShipping bool    `json:"requires_shipping"`
-	Taxable          bool    `json:"taxable"`
-	TrackQuantity    bool    `json:"track_quantity"`
-	Quantity         int     `json:"quantity"`
-	MinQuantity      int     `json:"min_quantity"`
-	MaxQuantity      int     `json:"max_quantity"`
-	MetaTitle        string  `json:"meta_title"`
-	MetaDescription  string  `json:"meta_description"`
-	Tags             string  `json:"tags"`
-	CategoryIDs      []int64 `json:"category_ids"`
-	CreatedAt        string  `json:"created_at"`
-	UpdatedAt        string  `json:"updated_at"`
+	ID               int64    `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	ShortDesc        string   `json:"short_description"`
+	SKU              string   `json:"sku"`
+	Price            float64  `json:"price"`
+	ComparePrice     float64  `json:"compare_price"`
+	CostPrice        float64  `json:"cost_price"`
+	Weight           float64  `json:"weight"`
+	Dimensions       string   `json:"dimensions"`
+	IsActive         bool     `json:"is_active"`
+	IsDigital        bool     `json:"is_digital"`
+	IsFree           bool     `json:"is_free"`
+	RequiresShipping bool     `json:"requires_shipping"`
+	Taxable          bool     `json:"taxable"`
+	TrackQuantity    bool     `json:"track_quantity"`
+	Quantity         int      `json:"quantity"`
+	MinQuantity      int      `json:"min_quantity"`
+	MaxQuantity      int      `json:"max_quantity"`
+	MetaTitle        string   `json:"meta_title"`
+	MetaDescription  string   `json:"meta_description"`
+	Tags             []string `json:"tags"`
+	CategoryIDs      []int64  `json:"category_ids"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+	Version          int      `json:"version"`
+
+	// Images in display order; PrimaryImageURL is null for products without images
+	Images          []ProductImageResponse `json:"images"`
+	PrimaryImageURL *string                `json:"primary_image_url"`
+}
+
+// ProductImageResponse represents one image of a product
+type ProductImageResponse struct {
+	ID        int64  `json:"id"`
+	URL       string `json:"url"`
+	Alt       string `json:"alt"`
+	Position  int    `json:"position"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// AddProductImageRequest represents the request to add an image to a product. The
+// product's first image is always primary.
+type AddProductImageRequest struct {
+	URL       string `json:"url" validate:"required,image_url"`
+	Alt       string `json:"alt" validate:"max=255"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// ReorderProductImagesRequest lists every image ID of a product in the new display order
+type ReorderProductImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids" validate:"required,min=1,dive,min=1"`
 }
 
 // ListProductsRequest represents the request to list products with filters
@@ -92,6 +132,9 @@ type ListProductsRequest struct {
 	SortOrder  string   `json:"sort_order"`
 	Page       int      `json:"page"`
 	Limit      int      `json:"limit"`
+	// After is a next_cursor from an earlier response; when set it selects cursor
+	// pagination and Page is ignored
+	After string `json:"after"`
 }
 
 // ListProductsResponse represents the response for listing products
@@ -101,6 +144,44 @@ type ListProductsResponse struct {
 	Page       int               `json:"page"`
 	Limit      int               `json:"limit"`
 	TotalPages int               `json:"total_pages"`
+	// NextCursor fetches the following page through ?after=; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetProductsByIDsRequest represents the request to fetch several products at once
+type GetProductsByIDsRequest struct {
+	IDs []int64 `json:"ids" validate:"required,min=1,max=100,dive,min=1"`
+}
+
+// BulkSetActiveRequest represents the request to activate or deactivate several products
+type BulkSetActiveRequest struct {
+	IDs      []int64 `json:"ids" validate:"required,min=1,max=500,dive,min=1"`
+	IsActive *bool   `json:"is_active" validate:"required"`
+}
+
+// BulkDeleteProductsRequest represents the request to delete several products
+type BulkDeleteProductsRequest struct {
+	IDs []int64 `json:"ids" validate:"required,min=1,max=500,dive,min=1"`
+}
+
+// Bulk product result statuses
+const (
+	BulkProductUpdated  = "updated"
+	BulkProductDeleted  = "deleted"
+	BulkProductNotFound = "not_found"
+)
+
+// BulkProductResult represents the outcome for a single product in a bulk operation
+type BulkProductResult struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// BulkProductResponse represents the response for a bulk product operation
+type BulkProductResponse struct {
+	Results  []BulkProductResult `json:"results"`
+	Affected int                 `json:"affected"`
+	NotFound int                 `json:"not_found"`
 }
 
 // CreateProductVariantRequest represents the request to create a product variant
@@ -115,6 +196,11 @@ type CreateProductVariantRequest struct {
 	Quantity     int     `json:"quantity" validate:"omitempty,min=0"`
 	IsActive     bool    `json:"is_active"`
 	Position     int     `json:"position" validate:"omitempty,min=0"`
+
+	Attributes map[string]string `json:"attributes" validate:"omitempty,dive,keys,min=1,max=100,endkeys,min=1,max=255"`
+
+	// CreateInventory also creates the variant's inventory record, seeded with Quantity
+	CreateInventory bool `json:"create_inventory"`
 }
 
 // UpdateProductVariantRequest represents the request to update a product variant
@@ -128,6 +214,8 @@ type UpdateProductVariantRequest struct {
 	Quantity     *int     `json:"quantity" validate:"omitempty,min=0"`
 	IsActive     *bool    `json:"is_active"`
 	Position     *int     `json:"position" validate:"omitempty,min=0"`
+
+	Attributes map[string]string `json:"attributes" validate:"omitempty,dive,keys,min=1,max=100,endkeys,min=1,max=255"`
 }
 
 // ProductVariantResponse represents the response for product variant data
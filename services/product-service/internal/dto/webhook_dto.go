@@ -0,0 +1,24 @@
+package dto
+
+// CreateWebhookSubscriptionRequest represents the request to register a webhook subscription
+type CreateWebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,required,max=100"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+// WebhookSubscriptionResponse represents a webhook subscription
+type WebhookSubscriptionResponse struct {
+	ID         int64    `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	IsActive   bool     `json:"is_active"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+// CreateWebhookSubscriptionResponse includes the signing secret, which is only returned once
+type CreateWebhookSubscriptionResponse struct {
+	WebhookSubscriptionResponse
+	Secret string `json:"secret"`
+}
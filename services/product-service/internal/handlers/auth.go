@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+)
+
+// Roles that auth-service assigns to users
+const (
+	RoleUser   = userctx.RoleUser
+	RoleEditor = userctx.RoleEditor
+	RoleAdmin  = userctx.RoleAdmin
+)
+
+// accessTokenType is the token_type claim of access tokens; refresh tokens carry another
+const accessTokenType = "access"
+
+// defaultAccessTokenTTL is auth-service's default access token lifetime
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// AuthPolicy configures how access tokens issued by auth-service are verified. The keys
+// must be the access token key set auth-service signs with.
+type AuthPolicy struct {
+	// Secret is auth-service's JWT_SECRET, used to check the HS256 signature of tokens
+	// whose kid header is KeyID or missing
+	Secret []byte
+	// KeyID is auth-service's JWT_KEY_ID, which defaults to "v1" there too
+	KeyID string
+	// PreviousKeys are auth-service's JWT_PREVIOUS_KEYS, the retired secrets it still trusts
+	PreviousKeys []AuthKey
+	// TokenTTL is auth-service's JWT_ACCESS_TOKEN_TTL. A retired key is trusted for this
+	// long after it was retired, when the tokens it signed have all expired.
+	TokenTTL time.Duration
+	// Issuer, when set, must match the token's iss claim
+	Issuer string
+	// StartedAt is when the service started. Tokens without a token_type claim are
+	// accepted as access tokens only if issued before it; see legacyTokenType.
+	StartedAt time.Time
+}
+
+// AuthKey is a retired auth-service signing secret, named by the kid header of the
+// tokens it signed
+type AuthKey struct {
+	ID        string
+	Secret    []byte
+	RetiredAt time.Time
+}
+
+// AccessClaims are the claims auth-service signs into an access token
+type AccessClaims struct {
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+type claimsKey struct{}
+
+// Authenticate rejects requests without a valid bearer access token with 401. The
+// token's claims are available to handlers through ClaimsFromContext, and its user ID
+// and role through userctx so repositories can attribute writes and services can
+// tailor reads.
+func Authenticate(policy AuthPolicy) func(http.Handler) http.Handler {
+	return authenticate(policy, true)
+}
+
+// OptionalAuthenticate is Authenticate for routes open to everyone: requests without a
+// bearer token pass through anonymously, while a token that is sent must be valid.
+func OptionalAuthenticate(policy AuthPolicy) func(http.Handler) http.Handler {
+	return authenticate(policy, false)
+}
+
+func authenticate(policy AuthPolicy, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok && !required && r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
+				return
+			}
+
+			claims, err := policy.parse(token)
+			if err != nil {
+				httpx.Error(w, http.StatusUnauthorized, "invalid or expired token", nil)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey{}, claims)
+			ctx = userctx.WithUserID(ctx, int64(claims.UserID))
+			ctx = userctx.WithRole(ctx, claims.Role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole rejects requests whose user has none of roles with 403. It must run
+// after Authenticate.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			httpx.Error(w, http.StatusForbidden, "insufficient permissions", nil)
+		})
+	}
+}
+
+// ClaimsFromContext returns the access token claims set by Authenticate
+func ClaimsFromContext(ctx context.Context) (*AccessClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*AccessClaims)
+	return claims, ok
+}
+
+// bearerToken returns the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// parse verifies an access token's signature and expiry and returns its claims
+func (p AuthPolicy) parse(tokenString string) (*AccessClaims, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired()}
+	if p.Issuer != "" {
+		options = append(options, jwt.WithIssuer(p.Issuer))
+	}
+
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, p.keyFunc, options...)
+	if err != nil {
+		return nil, err
+	}
+	if p.tokenType(claims) != accessTokenType {
+		return nil, errors.New("not an access token")
+	}
+	if claims.UserID == 0 {
+		return nil, errors.New("token has no user")
+	}
+	return claims, nil
+}
+
+// tokenType returns a token's token_type claim, or for a token without one the type
+// legacyTokenType works out
+func (p AuthPolicy) tokenType(claims *AccessClaims) string {
+	if claims.TokenType != "" {
+		return claims.TokenType
+	}
+	return p.legacyTokenType(claims.RegisteredClaims)
+}
+
+// legacyTokenType works out the type of a token issued before auth-service wrote
+// token_type, the same way auth-service does: only tokens issued before the service
+// started qualify, and one that lives no longer than an access token is an access token
+func (p AuthPolicy) legacyTokenType(registered jwt.RegisteredClaims) string {
+	if registered.IssuedAt == nil || registered.ExpiresAt == nil || !registered.IssuedAt.Before(p.StartedAt.Truncate(time.Second)) {
+		return ""
+	}
+	if registered.ExpiresAt.Sub(registered.IssuedAt.Time) <= p.tokenTTL() {
+		return accessTokenType
+	}
+	return ""
+}
+
+// tokenTTL is TokenTTL, or auth-service's default when it is not set
+func (p AuthPolicy) tokenTTL() time.Duration {
+	if p.TokenTTL <= 0 {
+		return defaultAccessTokenTTL
+	}
+	return p.TokenTTL
+}
+
+// keyFunc picks the secret matching a token's kid header the way auth-service does:
+// tokens without a kid are checked against the current key, and retired keys are
+// trusted for TokenTTL after they were retired
+func (p AuthPolicy) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" || kid == p.KeyID {
+		return p.Secret, nil
+	}
+
+	lifetime := p.tokenTTL()
+	for _, key := range p.PreviousKeys {
+		if key.ID != kid {
+			continue
+		}
+		if !time.Now().Before(key.RetiredAt.Add(lifetime)) {
+			return nil, fmt.Errorf("signing key %q is no longer trusted", kid)
+		}
+		return key.Secret, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
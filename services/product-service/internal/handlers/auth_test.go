@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAuthPolicy = AuthPolicy{Secret: []byte("test-secret"), Issuer: "auth-service"}
+
+// createProductService records the product creation it is asked for
+type createProductService struct {
+	services.ProductService
+	called bool
+	userID int64
+}
+
+func (s *createProductService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*domain.Product, error) {
+	s.called = true
+	s.userID, _ = userctx.UserID(ctx)
+	return &domain.Product{ID: 1, Name: req.Name, SKU: req.SKU}, nil
+}
+
+// newCreateProductServer serves CreateProduct behind the same middleware as the router
+func newCreateProductServer(service *createProductService) http.Handler {
+	handler := http.Handler(http.HandlerFunc(NewProductHandler(service).CreateProduct))
+	return Authenticate(testAuthPolicy)(RequireRole(RoleAdmin, RoleEditor)(handler))
+}
+
+func signAccessToken(t *testing.T, secret string, claims AccessClaims) string {
+	t.Helper()
+
+	return signAccessTokenWithKey(t, "", secret, claims)
+}
+
+// signAccessTokenWithKey signs claims with secret, naming it kid in the header if set
+func signAccessTokenWithKey(t *testing.T, kid, secret string, claims AccessClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return signed
+}
+
+func accessClaims(userID uint, role string) AccessClaims {
+	return AccessClaims{
+		UserID:    userID,
+		Role:      role,
+		TokenType: accessTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "auth-service",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+		},
+	}
+}
+
+func createProductRequest(token string) *http.Request {
+	body := `{"name":"Gear","description":"A gear","sku":"GEAR-001","price":9.99}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestCreateProduct_AdminAllowed(t *testing.T) {
+	service := &createProductService{}
+	token := signAccessToken(t, "test-secret", accessClaims(7, RoleAdmin))
+
+	rr := httptest.NewRecorder()
+	newCreateProductServer(service).ServeHTTP(rr, createProductRequest(token))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.True(t, service.called)
+	assert.Equal(t, int64(7), service.userID)
+}
+
+func TestCreateProduct_CustomerDenied(t *testing.T) {
+	service := &createProductService{}
+	token := signAccessToken(t, "test-secret", accessClaims(8, RoleUser))
+
+	rr := httptest.NewRecorder()
+	newCreateProductServer(service).ServeHTTP(rr, createProductRequest(token))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.False(t, service.called)
+}
+
+func TestCreateProduct_RejectsMissingOrInvalidToken(t *testing.T) {
+	expired := accessClaims(7, RoleAdmin)
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	refresh := accessClaims(7, RoleAdmin)
+	refresh.TokenType = "refresh"
+	untyped := accessClaims(7, RoleAdmin)
+	untyped.TokenType = ""
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "missing", token: ""},
+		{name: "malformed", token: "not-a-jwt"},
+		{name: "wrong secret", token: signAccessToken(t, "other-secret", accessClaims(7, RoleAdmin))},
+		{name: "expired", token: signAccessToken(t, "test-secret", expired)},
+		{name: "refresh token", token: signAccessToken(t, "test-secret", refresh)},
+		{name: "no token type", token: signAccessToken(t, "test-secret", untyped)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &createProductService{}
+
+			rr := httptest.NewRecorder()
+			newCreateProductServer(service).ServeHTTP(rr, createProductRequest(tt.token))
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.False(t, service.called)
+		})
+	}
+}
+
+func TestAuthenticate_KeyRotation(t *testing.T) {
+	policy := AuthPolicy{
+		Secret: []byte("current-secret"),
+		KeyID:  "v2",
+		PreviousKeys: []AuthKey{
+			{ID: "v1", Secret: []byte("recent-secret"), RetiredAt: time.Now().Add(-time.Minute)},
+			{ID: "v0", Secret: []byte("old-secret"), RetiredAt: time.Now().Add(-time.Hour)},
+		},
+		TokenTTL: 15 * time.Minute,
+		Issuer:   "auth-service",
+	}
+	server := func(service *createProductService) http.Handler {
+		handler := http.Handler(http.HandlerFunc(NewProductHandler(service).CreateProduct))
+		return Authenticate(policy)(RequireRole(RoleAdmin, RoleEditor)(handler))
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "current key", token: signAccessTokenWithKey(t, "v2", "current-secret", accessClaims(7, RoleAdmin)), status: http.StatusCreated},
+		{name: "no kid uses the current key", token: signAccessTokenWithKey(t, "", "current-secret", accessClaims(7, RoleAdmin)), status: http.StatusCreated},
+		{name: "recently retired key", token: signAccessTokenWithKey(t, "v1", "recent-secret", accessClaims(7, RoleAdmin)), status: http.StatusCreated},
+		{name: "key retired longer than a token lifetime", token: signAccessTokenWithKey(t, "v0", "old-secret", accessClaims(7, RoleAdmin)), status: http.StatusUnauthorized},
+		{name: "retired key named by the wrong kid", token: signAccessTokenWithKey(t, "v2", "recent-secret", accessClaims(7, RoleAdmin)), status: http.StatusUnauthorized},
+		{name: "unknown kid", token: signAccessTokenWithKey(t, "v9", "current-secret", accessClaims(7, RoleAdmin)), status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &createProductService{}
+
+			rr := httptest.NewRecorder()
+			server(service).ServeHTTP(rr, createProductRequest(tt.token))
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.status == http.StatusCreated, service.called)
+		})
+	}
+}
+
+func TestAuthenticate_LegacyTokensWithoutTokenType(t *testing.T) {
+	startedAt := time.Now()
+	policy := AuthPolicy{Secret: []byte("test-secret"), TokenTTL: 15 * time.Minute, Issuer: "auth-service", StartedAt: startedAt}
+	untyped := func(issuedAt time.Time, lifetime time.Duration) AccessClaims {
+		claims := accessClaims(7, RoleAdmin)
+		claims.TokenType = ""
+		claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+		claims.ExpiresAt = jwt.NewNumericDate(issuedAt.Add(lifetime))
+		return claims
+	}
+
+	tests := []struct {
+		name   string
+		claims AccessClaims
+		status int
+	}{
+		{name: "access token issued before the service started", claims: untyped(startedAt.Add(-5*time.Minute), 15*time.Minute), status: http.StatusCreated},
+		{name: "refresh token issued before the service started", claims: untyped(startedAt.Add(-time.Hour), 7*24*time.Hour), status: http.StatusUnauthorized},
+		{name: "issued after the service started", claims: untyped(startedAt.Add(time.Second), 15*time.Minute), status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &createProductService{}
+			handler := Authenticate(policy)(RequireRole(RoleAdmin, RoleEditor)(http.HandlerFunc(NewProductHandler(service).CreateProduct)))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, createProductRequest(signAccessToken(t, "test-secret", tt.claims)))
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.status == http.StatusCreated, service.called)
+		})
+	}
+}
+
+// listProductsService records the role of the caller listing products
+type listProductsService struct {
+	services.ProductService
+	called bool
+	role   string
+}
+
+func (s *listProductsService) ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ListProductsResponse, error) {
+	s.called = true
+	s.role = userctx.Role(ctx)
+	return &dto.ListProductsResponse{Products: []dto.ProductResponse{}}, nil
+}
+
+func TestListProducts_OptionalAuthentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantRole   string
+	}{
+		{name: "anonymous", header: "", wantStatus: http.StatusOK, wantRole: ""},
+		{name: "admin token", header: "Bearer " + signAccessToken(t, "test-secret", accessClaims(7, RoleAdmin)), wantStatus: http.StatusOK, wantRole: RoleAdmin},
+		{name: "customer token", header: "Bearer " + signAccessToken(t, "test-secret", accessClaims(8, RoleUser)), wantStatus: http.StatusOK, wantRole: RoleUser},
+		{name: "invalid token", header: "Bearer not-a-jwt", wantStatus: http.StatusUnauthorized},
+		{name: "malformed header", header: "Basic abc", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &listProductsService{}
+			handler := OptionalAuthenticate(testAuthPolicy)(http.HandlerFunc(NewProductHandler(service).ListProducts))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantStatus == http.StatusOK, service.called)
+			assert.Equal(t, tt.wantRole, service.role)
+		})
+	}
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 )
 
 type ICartHandler interface {
@@ -19,6 +24,8 @@ type ICartHandler interface {
 	GetCartBySession(w http.ResponseWriter, r *http.Request)
 	UpdateCart(w http.ResponseWriter, r *http.Request)
 	DeleteCart(w http.ResponseWriter, r *http.Request)
+	SetCartExpiry(w http.ResponseWriter, r *http.Request)
+	SetCartTaxExempt(w http.ResponseWriter, r *http.Request)
 	GetOrCreateCart(w http.ResponseWriter, r *http.Request)
 
 	// Cart Items
@@ -26,11 +33,14 @@ type ICartHandler interface {
 	GetCartItem(w http.ResponseWriter, r *http.Request)
 	UpdateCartItem(w http.ResponseWriter, r *http.Request)
 	DeleteCartItem(w http.ResponseWriter, r *http.Request)
+	IncrementCartItem(w http.ResponseWriter, r *http.Request)
+	DecrementCartItem(w http.ResponseWriter, r *http.Request)
 	GetCartItems(w http.ResponseWriter, r *http.Request)
 	ClearCartItems(w http.ResponseWriter, r *http.Request)
 
 	// Cart Summary & Calculations
 	GetCartSummary(w http.ResponseWriter, r *http.Request)
+	GetFullCart(w http.ResponseWriter, r *http.Request)
 	GetCartTotal(w http.ResponseWriter, r *http.Request)
 	GetCartItemCount(w http.ResponseWriter, r *http.Request)
 
@@ -44,15 +54,22 @@ type ICartHandler interface {
 	UpdateCartShipping(w http.ResponseWriter, r *http.Request)
 	GetCartShipping(w http.ResponseWriter, r *http.Request)
 	DeleteCartShipping(w http.ResponseWriter, r *http.Request)
+	GetShippingOptions(w http.ResponseWriter, r *http.Request)
+	SetCartAddress(w http.ResponseWriter, r *http.Request)
 
 	// Cart Operations
 	MergeCarts(w http.ResponseWriter, r *http.Request)
+	MergeGuestCart(w http.ResponseWriter, r *http.Request)
 	ClearCart(w http.ResponseWriter, r *http.Request)
+	DeduplicateCartItems(w http.ResponseWriter, r *http.Request)
 	GetCartAnalytics(w http.ResponseWriter, r *http.Request)
+	RecalculateActiveCarts(w http.ResponseWriter, r *http.Request)
+	ValidateCartForCheckout(w http.ResponseWriter, r *http.Request)
 
 	// Wishlist Management
 	CreateWishlist(w http.ResponseWriter, r *http.Request)
 	GetWishlist(w http.ResponseWriter, r *http.Request)
+	GetSharedWishlist(w http.ResponseWriter, r *http.Request)
 	GetWishlists(w http.ResponseWriter, r *http.Request)
 	UpdateWishlist(w http.ResponseWriter, r *http.Request)
 	DeleteWishlist(w http.ResponseWriter, r *http.Request)
@@ -78,15 +95,6 @@ func NewCartHandler(cartService services.CartService) ICartHandler {
 
 // Cart Management
 
-// getSessionIDFromCookie extracts session ID from the cart_session cookie
-func (h *cartHandler) getSessionIDFromCookie(r *http.Request) string {
-	cookie, err := r.Cookie("cart_session")
-	if err != nil {
-		return ""
-	}
-	return cookie.Value
-}
-
 func (h *cartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -97,7 +105,10 @@ func (h *cartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := h.cartService.GetCartByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Cart not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart", err)
 		return
 	}
 
@@ -106,20 +117,21 @@ func (h *cartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		UserID:    cart.UserID,
 		SessionID: cart.SessionID,
 		Currency:  cart.Currency,
-		CreatedAt: cart.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: cart.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
 	}
 	if cart.ExpiresAt != nil {
-		expiresAt := cart.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
 		response.ExpiresAt = &expiresAt
 	}
 
 	httpx.OK(w, "Cart retrieved successfully", response)
 }
 
-// GetCartBySession retrieves a cart by session ID from cookie
+// GetCartBySession retrieves a cart by the guest session ID from the signed session cookie
 func (h *cartHandler) GetCartBySession(w http.ResponseWriter, r *http.Request) {
-	sessionID := h.getSessionIDFromCookie(r)
+	sessionID := sessionIDFromContext(r.Context())
 	if sessionID == "" {
 		httpx.Error(w, http.StatusBadRequest, "No cart session found", nil)
 		return
@@ -127,7 +139,10 @@ func (h *cartHandler) GetCartBySession(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := h.cartService.GetCartBySessionID(r.Context(), sessionID)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Cart not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart", err)
 		return
 	}
 
@@ -136,11 +151,12 @@ func (h *cartHandler) GetCartBySession(w http.ResponseWriter, r *http.Request) {
 		UserID:    cart.UserID,
 		SessionID: cart.SessionID,
 		Currency:  cart.Currency,
-		CreatedAt: cart.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: cart.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
 	}
 	if cart.ExpiresAt != nil {
-		expiresAt := cart.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
 		response.ExpiresAt = &expiresAt
 	}
 
@@ -156,13 +172,25 @@ func (h *cartHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.UpdateCartRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	cart, err := h.cartService.UpdateCart(r.Context(), id, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update cart", err)
 		return
 	}
@@ -172,11 +200,12 @@ func (h *cartHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
 		UserID:    cart.UserID,
 		SessionID: cart.SessionID,
 		Currency:  cart.Currency,
-		CreatedAt: cart.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: cart.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
 	}
 	if cart.ExpiresAt != nil {
-		expiresAt := cart.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
 		response.ExpiresAt = &expiresAt
 	}
 
@@ -193,6 +222,9 @@ func (h *cartHandler) DeleteCart(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.DeleteCart(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete cart", err)
 		return
 	}
@@ -200,6 +232,109 @@ func (h *cartHandler) DeleteCart(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "Cart deleted successfully", nil)
 }
 
+// SetCartExpiry handles PUT /api/v1/carts/{id}/expiry, letting support staff expire,
+// extend or remove the expiry of a cart
+func (h *cartHandler) SetCartExpiry(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	var req dto.SetCartExpiryRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, "Provide either expires_at or no_expiry", validationErrors.FieldErrors())
+		return
+	}
+
+	var expiresAt *time.Time
+	if !req.NoExpiry {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, "expires_at must be an RFC3339 timestamp", err)
+			return
+		}
+		expiresAt = &parsed
+	}
+
+	cart, err := h.cartService.SetCartExpiry(r.Context(), id, expiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCartExpiryTooFar):
+			httpx.Error(w, http.StatusBadRequest, services.ErrCartExpiryTooFar.Error(), nil)
+		case errors.Is(err, repository.ErrNotFound):
+			httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+		default:
+			httpx.Error(w, http.StatusInternalServerError, "Failed to set cart expiry", nil)
+		}
+		return
+	}
+
+	response := dto.CartResponse{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		SessionID: cart.SessionID,
+		Currency:  cart.Currency,
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
+	}
+	if cart.ExpiresAt != nil {
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+
+	httpx.OK(w, "Cart expiry updated successfully", response)
+}
+
+// SetCartTaxExempt handles PUT /api/v1/carts/{id}/tax-exempt, letting staff mark a cart
+// tax exempt once they have checked the customer's exemption
+func (h *cartHandler) SetCartTaxExempt(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	var req dto.SetCartTaxExemptRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	cart, err := h.cartService.SetCartTaxExempt(r.Context(), id, req.TaxExempt)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to set cart tax exemption", err)
+		return
+	}
+
+	response := dto.CartResponse{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		SessionID: cart.SessionID,
+		Currency:  cart.Currency,
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
+	}
+	if cart.ExpiresAt != nil {
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+
+	httpx.OK(w, "Cart tax exemption updated successfully", response)
+}
+
 func (h *cartHandler) GetOrCreateCart(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.URL.Query().Get("user_id")
 	currency := r.URL.Query().Get("currency")
@@ -208,12 +343,18 @@ func (h *cartHandler) GetOrCreateCart(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "Missing required parameter", errors.New("currency is required"))
 		return
 	}
+	if !validation.IsCurrencyCode(currency) {
+		httpx.ValidationError(w, "currency must be a valid ISO 4217 currency code", []httpx.FieldError{
+			{Field: "currency", Message: "currency must be a valid ISO 4217 currency code", Tag: "currency"},
+		})
+		return
+	}
 
-	// Get session ID from cookie
-	sessionID := h.getSessionIDFromCookie(r)
+	// Guest session ID from the signed session cookie, issued by GuestSession
+	sessionID := sessionIDFromContext(r.Context())
 	if sessionID == "" {
-		// Generate a new session ID if none exists
-		sessionID = uuid.New().String()
+		httpx.Error(w, http.StatusBadRequest, "No cart session found", nil)
+		return
 	}
 
 	var userID *int64
@@ -228,6 +369,10 @@ func (h *cartHandler) GetOrCreateCart(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := h.cartService.GetOrCreateCart(r.Context(), userID, sessionID, currency)
 	if err != nil {
+		if errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get or create cart", err)
 		return
 	}
@@ -237,36 +382,15 @@ func (h *cartHandler) GetOrCreateCart(w http.ResponseWriter, r *http.Request) {
 		UserID:    cart.UserID,
 		SessionID: cart.SessionID,
 		Currency:  cart.Currency,
-		CreatedAt: cart.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: cart.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
 	}
 	if cart.ExpiresAt != nil {
-		expiresAt := cart.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
 		response.ExpiresAt = &expiresAt
 	}
 
-	// Set secure cookie for cart session (only if it's a new cart or session)
-	existingCookie, _ := r.Cookie("cart_session")
-	if existingCookie == nil || existingCookie.Value != cart.SessionID {
-		cookie := &http.Cookie{
-			Name:     "cart_session",
-			Value:    cart.SessionID,
-			Path:     "/",
-			Secure:   true,                 // Only send over HTTPS
-			SameSite: http.SameSiteLaxMode, // Allow cross-site requests for better UX
-			HttpOnly: true,                 // Prevent XSS attacks
-		}
-
-		// Use cart's expiration time if available, otherwise use 30 days
-		if cart.ExpiresAt != nil {
-			cookie.Expires = *cart.ExpiresAt
-		} else {
-			cookie.MaxAge = 30 * 24 * 60 * 60 // 30 days fallback
-		}
-
-		http.SetCookie(w, cookie)
-	}
-
 	httpx.OK(w, "Cart retrieved or created successfully", response)
 }
 
@@ -281,13 +405,40 @@ func (h *cartHandler) AddItemToCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.AddToCartRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	item, err := h.cartService.AddItemToCart(r.Context(), cartID, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		var unavailableErr *services.ProductUnavailableError
+		if errors.As(err, &unavailableErr) {
+			httpx.Error(w, http.StatusUnprocessableEntity, unavailableErr.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPrice) || errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+		var stockErr *services.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			writeInsufficientStock(w, stockErr)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			httpx.Error(w, http.StatusConflict, repository.ErrVersionConflict.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to add item to cart", err)
 		return
 	}
@@ -300,8 +451,8 @@ func (h *cartHandler) AddItemToCart(w http.ResponseWriter, r *http.Request) {
 		Quantity:         item.Quantity,
 		UnitPrice:        item.UnitPrice,
 		TotalPrice:       item.TotalPrice,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:        item.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
 	}
 
 	httpx.Created(w, "Item added to cart successfully", response)
@@ -317,7 +468,10 @@ func (h *cartHandler) GetCartItem(w http.ResponseWriter, r *http.Request) {
 
 	item, err := h.cartService.GetCartItemByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Cart item not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart item", err)
 		return
 	}
 
@@ -329,8 +483,8 @@ func (h *cartHandler) GetCartItem(w http.ResponseWriter, r *http.Request) {
 		Quantity:         item.Quantity,
 		UnitPrice:        item.UnitPrice,
 		TotalPrice:       item.TotalPrice,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:        item.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
 	}
 
 	httpx.OK(w, "Cart item retrieved successfully", response)
@@ -345,13 +499,28 @@ func (h *cartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.UpdateCartItemRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	item, err := h.cartService.UpdateCartItem(r.Context(), id, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		var stockErr *services.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			writeInsufficientStock(w, stockErr)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			httpx.Error(w, http.StatusConflict, repository.ErrVersionConflict.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update cart item", err)
 		return
 	}
@@ -364,8 +533,8 @@ func (h *cartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 		Quantity:         item.Quantity,
 		UnitPrice:        item.UnitPrice,
 		TotalPrice:       item.TotalPrice,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:        item.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
 	}
 
 	httpx.OK(w, "Cart item updated successfully", response)
@@ -381,6 +550,9 @@ func (h *cartHandler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.DeleteCartItem(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete cart item", err)
 		return
 	}
@@ -388,6 +560,111 @@ func (h *cartHandler) DeleteCartItem(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "Cart item deleted successfully", nil)
 }
 
+// IncrementCartItem handles POST /carts/items/{id}/increment
+func (h *cartHandler) IncrementCartItem(w http.ResponseWriter, r *http.Request) {
+	id, req, ok := decodeCartItemAdjustment(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.cartService.IncrementCartItem(r.Context(), id, req)
+	if err != nil {
+		writeCartItemAdjustmentError(w, err)
+		return
+	}
+
+	httpx.OK(w, "Cart item updated successfully", cartItemResponse(item))
+}
+
+// DecrementCartItem handles POST /carts/items/{id}/decrement. Decrementing the last
+// units removes the item.
+func (h *cartHandler) DecrementCartItem(w http.ResponseWriter, r *http.Request) {
+	id, req, ok := decodeCartItemAdjustment(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.cartService.DecrementCartItem(r.Context(), id, req)
+	if err != nil {
+		writeCartItemAdjustmentError(w, err)
+		return
+	}
+	if item == nil {
+		httpx.OK(w, "Cart item removed", nil)
+		return
+	}
+
+	httpx.OK(w, "Cart item updated successfully", cartItemResponse(item))
+}
+
+// decodeCartItemAdjustment reads the item ID and the optional adjustment body, writing a
+// 400 and returning false when either is invalid
+func decodeCartItemAdjustment(w http.ResponseWriter, r *http.Request) (int64, *dto.AdjustCartItemQuantityRequest, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid item ID", err)
+		return 0, nil, false
+	}
+
+	// An empty body adjusts by one unit
+	var req dto.AdjustCartItemQuantityRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.InvalidBody(w, err)
+		return 0, nil, false
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return 0, nil, false
+	}
+
+	return id, &req, true
+}
+
+// writeCartItemAdjustmentError maps an increment or decrement failure to its response
+func writeCartItemAdjustmentError(w http.ResponseWriter, err error) {
+	if writeInvalidQuantity(w, err) {
+		return
+	}
+	var stockErr *services.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		writeInsufficientStock(w, stockErr)
+		return
+	}
+	var unavailableErr *services.ProductUnavailableError
+	if errors.As(err, &unavailableErr) {
+		httpx.Error(w, http.StatusUnprocessableEntity, unavailableErr.Error(), nil)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidPrice) {
+		httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+		return
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		httpx.Error(w, http.StatusConflict, repository.ErrVersionConflict.Error(), nil)
+		return
+	}
+	if writeNotFound(w, err) {
+		return
+	}
+	httpx.Error(w, http.StatusInternalServerError, "Failed to update cart item", err)
+}
+
+// cartItemResponse converts a cart item to its response
+func cartItemResponse(item *domain.CartItem) dto.CartItemResponse {
+	return dto.CartItemResponse{
+		ID:               item.ID,
+		CartID:           item.CartID,
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		Quantity:         item.Quantity,
+		UnitPrice:        item.UnitPrice,
+		TotalPrice:       item.TotalPrice,
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
+	}
+}
+
 func (h *cartHandler) GetCartItems(w http.ResponseWriter, r *http.Request) {
 	cartIDStr := chi.URLParam(r, "id")
 	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
@@ -396,28 +673,18 @@ func (h *cartHandler) GetCartItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := h.cartService.GetCartItems(r.Context(), cartID)
+	page, limit := httpx.PaginationFromRequest(r)
+
+	response, err := h.cartService.GetCartItems(r.Context(), cartID, page, limit)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart items", err)
 		return
 	}
 
-	responses := make([]dto.CartItemResponse, len(items))
-	for i, item := range items {
-		responses[i] = dto.CartItemResponse{
-			ID:               item.ID,
-			CartID:           item.CartID,
-			ProductID:        item.ProductID,
-			ProductVariantID: item.ProductVariantID,
-			Quantity:         item.Quantity,
-			UnitPrice:        item.UnitPrice,
-			TotalPrice:       item.TotalPrice,
-			CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:        item.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
-	}
-
-	httpx.OK(w, "Cart items retrieved successfully", responses)
+	httpx.OK(w, "Cart items retrieved successfully", response)
 }
 
 func (h *cartHandler) ClearCartItems(w http.ResponseWriter, r *http.Request) {
@@ -430,6 +697,9 @@ func (h *cartHandler) ClearCartItems(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.ClearCartItems(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to clear cart items", err)
 		return
 	}
@@ -449,6 +719,9 @@ func (h *cartHandler) GetCartSummary(w http.ResponseWriter, r *http.Request) {
 
 	summary, err := h.cartService.GetCartSummary(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart summary", err)
 		return
 	}
@@ -456,6 +729,26 @@ func (h *cartHandler) GetCartSummary(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "Cart summary retrieved successfully", summary)
 }
 
+func (h *cartHandler) GetFullCart(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	cart, err := h.cartService.GetFullCart(r.Context(), cartID)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart", nil)
+		return
+	}
+
+	httpx.OK(w, "Cart retrieved successfully", cart)
+}
+
 func (h *cartHandler) GetCartTotal(w http.ResponseWriter, r *http.Request) {
 	cartIDStr := chi.URLParam(r, "id")
 	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
@@ -466,6 +759,9 @@ func (h *cartHandler) GetCartTotal(w http.ResponseWriter, r *http.Request) {
 
 	total, err := h.cartService.CalculateCartTotal(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to calculate cart total", err)
 		return
 	}
@@ -483,6 +779,9 @@ func (h *cartHandler) GetCartItemCount(w http.ResponseWriter, r *http.Request) {
 
 	count, err := h.cartService.GetCartItemCount(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart item count", err)
 		return
 	}
@@ -501,14 +800,35 @@ func (h *cartHandler) ApplyCouponToCart(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req dto.ApplyCouponRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	coupon, err := h.cartService.ApplyCouponToCart(r.Context(), cartID, &req)
 	if err != nil {
-		httpx.Error(w, http.StatusInternalServerError, "Failed to apply coupon", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, repository.ErrCouponNotFound):
+			httpx.Error(w, http.StatusNotFound, repository.ErrCouponNotFound.Error(), nil)
+		case errors.Is(err, repository.ErrCouponLimitReached):
+			httpx.Error(w, http.StatusUnprocessableEntity, repository.ErrCouponLimitReached.Error(), nil)
+		case errors.Is(err, services.ErrCouponRequiresAccount):
+			httpx.Error(w, http.StatusUnprocessableEntity, services.ErrCouponRequiresAccount.Error(), nil)
+		case errors.Is(err, services.ErrCouponInactive):
+			httpx.Error(w, http.StatusUnprocessableEntity, services.ErrCouponInactive.Error(), nil)
+		case errors.Is(err, services.ErrCouponExpired):
+			httpx.Error(w, http.StatusUnprocessableEntity, services.ErrCouponExpired.Error(), nil)
+		case errors.Is(err, services.ErrCouponMinNotMet):
+			httpx.Error(w, http.StatusUnprocessableEntity, services.ErrCouponMinNotMet.Error(), nil)
+		default:
+			httpx.Error(w, http.StatusInternalServerError, "Failed to apply coupon", err)
+		}
 		return
 	}
 
@@ -517,7 +837,7 @@ func (h *cartHandler) ApplyCouponToCart(w http.ResponseWriter, r *http.Request)
 		CartID:         coupon.CartID,
 		CouponCode:     coupon.CouponCode,
 		DiscountAmount: coupon.DiscountAmount,
-		CreatedAt:      coupon.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:      httpx.FormatTime(coupon.CreatedAt),
 	}
 
 	httpx.Created(w, "Coupon applied successfully", response)
@@ -532,13 +852,16 @@ func (h *cartHandler) RemoveCouponFromCart(w http.ResponseWriter, r *http.Reques
 	}
 
 	var req dto.RemoveCouponRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	err = h.cartService.RemoveCouponFromCart(r.Context(), cartID, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to remove coupon", err)
 		return
 	}
@@ -554,24 +877,18 @@ func (h *cartHandler) GetCartCoupons(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	coupons, err := h.cartService.GetCartCoupons(r.Context(), cartID)
+	page, limit := httpx.PaginationFromRequest(r)
+
+	response, err := h.cartService.GetCartCoupons(r.Context(), cartID, page, limit)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart coupons", err)
 		return
 	}
 
-	responses := make([]dto.CartCouponResponse, len(coupons))
-	for i, coupon := range coupons {
-		responses[i] = dto.CartCouponResponse{
-			ID:             coupon.ID,
-			CartID:         coupon.CartID,
-			CouponCode:     coupon.CouponCode,
-			DiscountAmount: coupon.DiscountAmount,
-			CreatedAt:      coupon.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
-	}
-
-	httpx.OK(w, "Cart coupons retrieved successfully", responses)
+	httpx.OK(w, "Cart coupons retrieved successfully", response)
 }
 
 // Cart Shipping
@@ -585,13 +902,20 @@ func (h *cartHandler) SetCartShipping(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.SetShippingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	shipping, err := h.cartService.SetCartShipping(r.Context(), cartID, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrShippingMethodUnavailable) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to set cart shipping", err)
 		return
 	}
@@ -603,7 +927,7 @@ func (h *cartHandler) SetCartShipping(w http.ResponseWriter, r *http.Request) {
 		ShippingMethod:   shipping.ShippingMethod,
 		ShippingAmount:   shipping.ShippingAmount,
 		EstimatedDays:    shipping.EstimatedDays,
-		CreatedAt:        shipping.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(shipping.CreatedAt),
 	}
 
 	httpx.Created(w, "Cart shipping set successfully", response)
@@ -618,13 +942,20 @@ func (h *cartHandler) UpdateCartShipping(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req dto.UpdateShippingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	shipping, err := h.cartService.UpdateCartShipping(r.Context(), cartID, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrShippingMethodUnavailable) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update cart shipping", err)
 		return
 	}
@@ -636,7 +967,7 @@ func (h *cartHandler) UpdateCartShipping(w http.ResponseWriter, r *http.Request)
 		ShippingMethod:   shipping.ShippingMethod,
 		ShippingAmount:   shipping.ShippingAmount,
 		EstimatedDays:    shipping.EstimatedDays,
-		CreatedAt:        shipping.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(shipping.CreatedAt),
 	}
 
 	httpx.OK(w, "Cart shipping updated successfully", response)
@@ -652,6 +983,9 @@ func (h *cartHandler) GetCartShipping(w http.ResponseWriter, r *http.Request) {
 
 	shipping, err := h.cartService.GetCartShipping(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get cart shipping", err)
 		return
 	}
@@ -668,7 +1002,7 @@ func (h *cartHandler) GetCartShipping(w http.ResponseWriter, r *http.Request) {
 		ShippingMethod:   shipping.ShippingMethod,
 		ShippingAmount:   shipping.ShippingAmount,
 		EstimatedDays:    shipping.EstimatedDays,
-		CreatedAt:        shipping.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(shipping.CreatedAt),
 	}
 
 	httpx.OK(w, "Cart shipping retrieved successfully", response)
@@ -684,6 +1018,9 @@ func (h *cartHandler) DeleteCartShipping(w http.ResponseWriter, r *http.Request)
 
 	err = h.cartService.DeleteCartShipping(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete cart shipping", err)
 		return
 	}
@@ -691,6 +1028,90 @@ func (h *cartHandler) DeleteCartShipping(w http.ResponseWriter, r *http.Request)
 	httpx.OK(w, "Cart shipping deleted successfully", nil)
 }
 
+// GetShippingOptions handles GET /api/v1/carts/{id}/shipping/options?country=US&postal_code=94105
+func (h *cartHandler) GetShippingOptions(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	req := dto.ShippingOptionsRequest{
+		Country:    strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("country"))),
+		PostalCode: strings.TrimSpace(r.URL.Query().Get("postal_code")),
+	}
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	rates, err := h.cartService.GetShippingOptions(r.Context(), cartID, domain.ShippingDestination{
+		Country:    req.Country,
+		PostalCode: req.PostalCode,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCartEmpty):
+			httpx.Error(w, http.StatusUnprocessableEntity, repository.ErrCartEmpty.Error(), nil)
+		case errors.Is(err, repository.ErrNotFound):
+			httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+		default:
+			httpx.Error(w, http.StatusInternalServerError, "Failed to get shipping options", err)
+		}
+		return
+	}
+
+	options := make([]dto.ShippingOptionResponse, 0, len(rates))
+	for _, rate := range rates {
+		options = append(options, dto.ShippingOptionResponse{
+			ShippingMethodID: rate.MethodID,
+			ShippingMethod:   rate.Method,
+			ShippingAmount:   rate.Amount,
+			EstimatedDays:    rate.EstimatedDays,
+		})
+	}
+
+	httpx.OKList(w, "Shipping options retrieved successfully", options)
+}
+
+// SetCartAddress handles PUT /api/v1/carts/{id}/shipping/address and returns the cart
+// summary taxed for the new address
+func (h *cartHandler) SetCartAddress(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	var req dto.SetCartAddressRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	summary, err := h.cartService.SetCartAddress(r.Context(), cartID, domain.ShippingDestination{
+		Country:    strings.ToUpper(strings.TrimSpace(req.Country)),
+		State:      strings.TrimSpace(req.State),
+		PostalCode: strings.TrimSpace(req.PostalCode),
+	})
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to set cart address", err)
+		return
+	}
+
+	httpx.OK(w, "Cart address set successfully", summary)
+}
+
 // Cart Operations
 
 func (h *cartHandler) MergeCarts(w http.ResponseWriter, r *http.Request) {
@@ -702,13 +1123,20 @@ func (h *cartHandler) MergeCarts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.MergeCartRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	err = h.cartService.MergeCarts(r.Context(), req.SourceCartID, targetCartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to merge carts", err)
 		return
 	}
@@ -716,6 +1144,53 @@ func (h *cartHandler) MergeCarts(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "Carts merged successfully", nil)
 }
 
+// MergeGuestCart handles POST /api/v1/carts/merge-guest, called once a user has logged
+// in. The guest cart of the signed session cookie is merged into the signed-in user's
+// cart, which is returned; data is null when neither cart exists.
+func (h *cartHandler) MergeGuestCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userctx.UserID(r.Context())
+	if !ok {
+		httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
+		return
+	}
+
+	sessionID := sessionIDFromContext(r.Context())
+	if sessionID == "" {
+		httpx.Error(w, http.StatusBadRequest, "No cart session found", nil)
+		return
+	}
+
+	cart, err := h.cartService.MergeGuestCartOnLogin(r.Context(), sessionID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to merge guest cart", err)
+		return
+	}
+	if cart == nil {
+		httpx.OK(w, "No guest cart to merge", nil)
+		return
+	}
+
+	response := dto.CartResponse{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		SessionID: cart.SessionID,
+		Currency:  cart.Currency,
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
+	}
+	if cart.ExpiresAt != nil {
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+
+	httpx.OK(w, "Guest cart merged successfully", response)
+}
+
 func (h *cartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	cartIDStr := chi.URLParam(r, "id")
 	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
@@ -725,8 +1200,8 @@ func (h *cartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.ClearCartRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
@@ -737,6 +1212,9 @@ func (h *cartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.ClearCart(r.Context(), cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to clear cart", err)
 		return
 	}
@@ -754,19 +1232,72 @@ func (h *cartHandler) GetCartAnalytics(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "Cart analytics retrieved successfully", analytics)
 }
 
+// ValidateCartForCheckout reports cart items that cannot be checked out
+func (h *cartHandler) ValidateCartForCheckout(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	result, err := h.cartService.ValidateCartForCheckout(r.Context(), cartID)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to validate cart", err)
+		return
+	}
+
+	httpx.OK(w, "Cart validated successfully", result)
+}
+
+// DeduplicateCartItems merges a cart's items for the same product and variant into one line
+func (h *cartHandler) DeduplicateCartItems(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	result, err := h.cartService.DeduplicateCartItems(r.Context(), cartID)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to deduplicate cart items", err)
+		return
+	}
+
+	httpx.OK(w, "Cart items deduplicated successfully", result)
+}
+
+// RecalculateActiveCarts reprices all active carts from current product prices
+func (h *cartHandler) RecalculateActiveCarts(w http.ResponseWriter, r *http.Request) {
+	result, err := h.cartService.RecalculateAllActiveCarts(r.Context())
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "Failed to recalculate carts", err)
+		return
+	}
+
+	httpx.OK(w, "Carts recalculated successfully", result)
+}
+
 // Wishlist Management
 
+// CreateWishlist handles POST /api/v1/wishlists, creating a wishlist for the signed-in user
 func (h *cartHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
-	userIDStr := r.URL.Query().Get("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+	userID, ok := userctx.UserID(r.Context())
+	if !ok {
+		httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
 		return
 	}
 
 	var req dto.CreateWishlistRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
@@ -777,12 +1308,13 @@ func (h *cartHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := dto.WishlistResponse{
-		ID:        wishlist.ID,
-		UserID:    wishlist.UserID,
-		Name:      wishlist.Name,
-		IsPublic:  wishlist.IsPublic,
-		CreatedAt: wishlist.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: wishlist.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:         wishlist.ID,
+		UserID:     wishlist.UserID,
+		Name:       wishlist.Name,
+		IsPublic:   wishlist.IsPublic,
+		ShareToken: wishlist.ShareToken,
+		CreatedAt:  httpx.FormatTime(wishlist.CreatedAt),
+		UpdatedAt:  httpx.FormatTime(wishlist.UpdatedAt),
 	}
 
 	httpx.Created(w, "Wishlist created successfully", response)
@@ -798,47 +1330,53 @@ func (h *cartHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
 
 	wishlist, err := h.cartService.GetWishlistByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Wishlist not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get wishlist", err)
 		return
 	}
 
 	response := dto.WishlistResponse{
-		ID:        wishlist.ID,
-		UserID:    wishlist.UserID,
-		Name:      wishlist.Name,
-		IsPublic:  wishlist.IsPublic,
-		CreatedAt: wishlist.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: wishlist.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:         wishlist.ID,
+		UserID:     wishlist.UserID,
+		Name:       wishlist.Name,
+		IsPublic:   wishlist.IsPublic,
+		ShareToken: wishlist.ShareToken,
+		CreatedAt:  httpx.FormatTime(wishlist.CreatedAt),
+		UpdatedAt:  httpx.FormatTime(wishlist.UpdatedAt),
 	}
 
 	httpx.OK(w, "Wishlist retrieved successfully", response)
 }
 
-func (h *cartHandler) GetWishlists(w http.ResponseWriter, r *http.Request) {
-	userIDStr := r.URL.Query().Get("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+// GetSharedWishlist handles GET /api/v1/wishlists/shared/{token}, the read-only view
+// of a public wishlist. Unknown tokens and wishlists made private are not found.
+func (h *cartHandler) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	page, limit := httpx.PaginationFromRequest(r)
+
+	response, err := h.cartService.GetSharedWishlist(r.Context(), token, page, limit)
 	if err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get shared wishlist", err)
 		return
 	}
 
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
-
-	page := 1
-	limit := 20
+	httpx.OK(w, "Wishlist retrieved successfully", response)
+}
 
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+// GetWishlists handles GET /api/v1/wishlists, listing the signed-in user's wishlists
+func (h *cartHandler) GetWishlists(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userctx.UserID(r.Context())
+	if !ok {
+		httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
+		return
 	}
 
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
+	page, limit := httpx.PaginationFromRequest(r)
 
 	response, err := h.cartService.GetWishlistsByUserID(r.Context(), userID, page, limit)
 	if err != nil {
@@ -858,24 +1396,28 @@ func (h *cartHandler) UpdateWishlist(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.UpdateWishlistRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	wishlist, err := h.cartService.UpdateWishlist(r.Context(), id, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update wishlist", err)
 		return
 	}
 
 	response := dto.WishlistResponse{
-		ID:        wishlist.ID,
-		UserID:    wishlist.UserID,
-		Name:      wishlist.Name,
-		IsPublic:  wishlist.IsPublic,
-		CreatedAt: wishlist.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: wishlist.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:         wishlist.ID,
+		UserID:     wishlist.UserID,
+		Name:       wishlist.Name,
+		IsPublic:   wishlist.IsPublic,
+		ShareToken: wishlist.ShareToken,
+		CreatedAt:  httpx.FormatTime(wishlist.CreatedAt),
+		UpdatedAt:  httpx.FormatTime(wishlist.UpdatedAt),
 	}
 
 	httpx.OK(w, "Wishlist updated successfully", response)
@@ -891,6 +1433,9 @@ func (h *cartHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.DeleteWishlist(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete wishlist", err)
 		return
 	}
@@ -909,13 +1454,24 @@ func (h *cartHandler) AddItemToWishlist(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req dto.AddToWishlistRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	item, err := h.cartService.AddItemToWishlist(r.Context(), wishlistID, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		var unavailableErr *services.ProductUnavailableError
+		if errors.As(err, &unavailableErr) {
+			httpx.Error(w, http.StatusUnprocessableEntity, unavailableErr.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to add item to wishlist", err)
 		return
 	}
@@ -926,7 +1482,7 @@ func (h *cartHandler) AddItemToWishlist(w http.ResponseWriter, r *http.Request)
 		ProductID:        item.ProductID,
 		ProductVariantID: item.ProductVariantID,
 		Notes:            item.Notes,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
 	}
 
 	httpx.Created(w, "Item added to wishlist successfully", response)
@@ -942,7 +1498,10 @@ func (h *cartHandler) GetWishlistItem(w http.ResponseWriter, r *http.Request) {
 
 	item, err := h.cartService.GetWishlistItemByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Wishlist item not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get wishlist item", err)
 		return
 	}
 
@@ -952,7 +1511,7 @@ func (h *cartHandler) GetWishlistItem(w http.ResponseWriter, r *http.Request) {
 		ProductID:        item.ProductID,
 		ProductVariantID: item.ProductVariantID,
 		Notes:            item.Notes,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
 	}
 
 	httpx.OK(w, "Wishlist item retrieved successfully", response)
@@ -966,26 +1525,13 @@ func (h *cartHandler) GetWishlistItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
-
-	page := 1
-	limit := 20
-
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
+	page, limit := httpx.PaginationFromRequest(r)
 
 	response, err := h.cartService.GetWishlistItems(r.Context(), wishlistID, page, limit)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to get wishlist items", err)
 		return
 	}
@@ -1002,13 +1548,16 @@ func (h *cartHandler) UpdateWishlistItem(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req dto.UpdateWishlistItemRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	item, err := h.cartService.UpdateWishlistItem(r.Context(), id, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update wishlist item", err)
 		return
 	}
@@ -1019,7 +1568,7 @@ func (h *cartHandler) UpdateWishlistItem(w http.ResponseWriter, r *http.Request)
 		ProductID:        item.ProductID,
 		ProductVariantID: item.ProductVariantID,
 		Notes:            item.Notes,
-		CreatedAt:        item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
 	}
 
 	httpx.OK(w, "Wishlist item updated successfully", response)
@@ -1035,6 +1584,9 @@ func (h *cartHandler) DeleteWishlistItem(w http.ResponseWriter, r *http.Request)
 
 	err = h.cartService.DeleteWishlistItem(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete wishlist item", err)
 		return
 	}
@@ -1059,9 +1611,39 @@ func (h *cartHandler) MoveItemToCart(w http.ResponseWriter, r *http.Request) {
 
 	err = h.cartService.MoveItemToCart(r.Context(), itemID, cartID)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		var unavailableErr *services.ProductUnavailableError
+		if errors.As(err, &unavailableErr) {
+			httpx.Error(w, http.StatusUnprocessableEntity, unavailableErr.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPrice) || errors.Is(err, services.ErrCurrencyMismatch) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+		var stockErr *services.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			writeInsufficientStock(w, stockErr)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to move item to cart", err)
 		return
 	}
 
 	httpx.OK(w, "Item moved to cart successfully", nil)
 }
+
+// writeInsufficientStock writes a 409 carrying the stock figures, so clients can offer
+// the quantity that still fits
+func writeInsufficientStock(w http.ResponseWriter, stockErr *services.InsufficientStockError) {
+	httpx.WriteJSON(w, http.StatusConflict, false, stockErr.Error(), nil, dto.InsufficientStockResponse{
+		Message:          stockErr.Error(),
+		ProductID:        stockErr.ProductID,
+		ProductVariantID: stockErr.ProductVariantID,
+		Requested:        stockErr.Requested,
+		Available:        stockErr.Available,
+		MaxAddable:       stockErr.MaxAddable,
+	})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateItemCartService records the cart item update it receives
+type updateItemCartService struct {
+	services.CartService
+	req *dto.UpdateCartItemRequest
+}
+
+func (s *updateItemCartService) UpdateCartItem(ctx context.Context, id int64, req *dto.UpdateCartItemRequest) (*domain.CartItem, error) {
+	s.req = req
+	return &domain.CartItem{ID: id, Quantity: 1}, nil
+}
+
+func TestUpdateCartItem_UnknownFields(t *testing.T) {
+	serve := func(policy httpx.BodyPolicy, body string) (*httptest.ResponseRecorder, *updateItemCartService) {
+		service := &updateItemCartService{}
+		router := chi.NewRouter()
+		router.Use(httpx.JSONBody(policy))
+		router.Put("/api/v1/carts/items/{id}", NewCartHandler(service).UpdateCartItem)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/carts/items/3", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr, service
+	}
+
+	t.Run("a misspelt field is rejected naming it", func(t *testing.T) {
+		rr, service := serve(httpx.BodyPolicy{DisallowUnknownFields: true}, `{"quantites":5}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Nil(t, service.req)
+
+		var response httpx.APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, `Invalid request body: unknown field "quantites"`, response.Message)
+	})
+
+	t.Run("known fields are accepted", func(t *testing.T) {
+		rr, service := serve(httpx.BodyPolicy{DisallowUnknownFields: true}, `{"quantity":5}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		require.NotNil(t, service.req)
+		assert.Equal(t, 5, *service.req.Quantity)
+	})
+
+	t.Run("unknown fields are ignored unless strict decoding is on", func(t *testing.T) {
+		rr, service := serve(httpx.BodyPolicy{}, `{"quantites":5}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		require.NotNil(t, service.req)
+		assert.Nil(t, service.req.Quantity)
+	})
+}
+
+// sharedWishlistCartService serves one public wishlist by its share token
+type sharedWishlistCartService struct {
+	services.CartService
+	token string
+}
+
+func (s *sharedWishlistCartService) GetSharedWishlist(ctx context.Context, token string, page, limit int) (*dto.SharedWishlistResponse, error) {
+	if token != s.token {
+		return nil, fmt.Errorf("failed to get shared wishlist: %w", repository.ErrWishlistNotFound)
+	}
+	return &dto.SharedWishlistResponse{Name: "Birthday", Items: []dto.SharedWishlistItemResponse{}, Page: page, Limit: limit}, nil
+}
+
+func TestGetSharedWishlist(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/api/v1/wishlists/shared/{token}", NewCartHandler(&sharedWishlistCartService{token: "abc123"}).GetSharedWishlist)
+
+	get := func(token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/shared/"+token, nil))
+		return rr
+	}
+
+	t.Run("a public wishlist is served by its token", func(t *testing.T) {
+		rr := get("abc123")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"name":"Birthday"`)
+	})
+
+	t.Run("a revoked or unknown token is not found", func(t *testing.T) {
+		rr := get("revoked")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+// wishlistsCartService records whose wishlists are listed
+type wishlistsCartService struct {
+	services.CartService
+	userID int64
+}
+
+func (s *wishlistsCartService) GetWishlistsByUserID(ctx context.Context, userID int64, page, limit int) (*dto.ListWishlistsResponse, error) {
+	s.userID = userID
+	return &dto.ListWishlistsResponse{Wishlists: []dto.WishlistResponse{}, Page: page, Limit: limit}, nil
+}
+
+func TestGetWishlists_SignedInUser(t *testing.T) {
+	get := func(ctx context.Context) (*httptest.ResponseRecorder, *wishlistsCartService) {
+		service := &wishlistsCartService{}
+		rr := httptest.NewRecorder()
+		// A user_id in the query is ignored; only the token decides whose wishlists these are
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists?user_id=99", nil).WithContext(ctx)
+		NewCartHandler(service).GetWishlists(rr, req)
+		return rr, service
+	}
+
+	t.Run("lists the signed-in user's wishlists", func(t *testing.T) {
+		rr, service := get(userctx.WithUserID(context.Background(), 7))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, int64(7), service.userID)
+	})
+
+	t.Run("requires a signed-in user", func(t *testing.T) {
+		rr, service := get(context.Background())
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Zero(t, service.userID)
+	})
+}
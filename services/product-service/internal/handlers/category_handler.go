@@ -1,15 +1,15 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
@@ -24,6 +24,8 @@ type ICategoryHandler interface {
 	ListCategories(w http.ResponseWriter, r *http.Request)
 	GetCategoryHierarchy(w http.ResponseWriter, r *http.Request)
 	GetCategoryChildren(w http.ResponseWriter, r *http.Request)
+	GetCategoryTree(w http.ResponseWriter, r *http.Request)
+	GetCategoryBreadcrumb(w http.ResponseWriter, r *http.Request)
 }
 
 type categoryHandler struct {
@@ -39,14 +41,14 @@ func NewCategoryHandler(categoryService services.CategoryService) ICategoryHandl
 // CreateCategory handles POST /api/v1/categories
 func (h *categoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	// Validate Request
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
@@ -65,6 +67,9 @@ func (h *categoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 
 	category, err := h.categoryService.CreateCategory(r.Context(), cat)
 	if err != nil {
+		if writeAlreadyExists(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to create category", err)
 		return
 	}
@@ -84,8 +89,7 @@ func (h *categoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 
 	category, err := h.categoryService.GetCategory(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -106,8 +110,7 @@ func (h *categoryHandler) GetCategoryBySlug(w http.ResponseWriter, r *http.Reque
 
 	category, err := h.categoryService.GetCategoryBySlug(r.Context(), slug)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), err)
@@ -128,20 +131,26 @@ func (h *categoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req dto.UpdateCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	category, err := h.categoryService.UpdateCategory(r.Context(), id, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrCategoryCycle) {
+			httpx.Error(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -163,8 +172,7 @@ func (h *categoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 
 	err = h.categoryService.DeleteCategory(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -193,17 +201,7 @@ func (h *categoryHandler) ListCategories(w http.ResponseWriter, r *http.Request)
 
 	req.Search = r.URL.Query().Get("search")
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			req.Page = page
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			req.Limit = limit
-		}
-	}
+	req.Page, req.Limit = httpx.PaginationFromRequest(r)
 
 	response, err := h.categoryService.ListCategories(r.Context(), req)
 	if err != nil {
@@ -222,7 +220,7 @@ func (h *categoryHandler) GetCategoryHierarchy(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	httpx.OK(w, "category hierarchy retrieved", hierarchy)
+	httpx.OKList(w, "category hierarchy retrieved", hierarchy)
 }
 
 // GetCategoryChildren handles GET /api/v1/categories/{id}/children
@@ -241,5 +239,38 @@ func (h *categoryHandler) GetCategoryChildren(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	httpx.OK(w, "category children retrieved", children)
+	httpx.OKList(w, "category children retrieved", children)
+}
+
+// GetCategoryTree handles GET /api/v1/categories/tree
+func (h *categoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryService.GetCategoryTree(r.Context())
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to get category tree", err)
+		return
+	}
+
+	httpx.OKList(w, "category tree retrieved", tree)
+}
+
+// GetCategoryBreadcrumb handles GET /api/v1/categories/{id}/breadcrumb
+func (h *categoryHandler) GetCategoryBreadcrumb(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid category ID", err)
+		return
+	}
+
+	breadcrumb, err := h.categoryService.GetCategoryBreadcrumb(r.Context(), id)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to get category breadcrumb", err)
+		return
+	}
+
+	httpx.OKList(w, "category breadcrumb retrieved", breadcrumb)
 }
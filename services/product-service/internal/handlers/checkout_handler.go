@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+)
+
+type ICheckoutHandler interface {
+	CreateOrderFromCart(w http.ResponseWriter, r *http.Request)
+}
+
+type checkoutHandler struct {
+	checkoutService services.CheckoutService
+}
+
+func NewCheckoutHandler(checkoutService services.CheckoutService) ICheckoutHandler {
+	return &checkoutHandler{
+		checkoutService: checkoutService,
+	}
+}
+
+// CreateOrderFromCart handles POST /api/v1/carts/{id}/checkout. It places an order for
+// the cart's items, takes them out of stock and empties the cart. The caller must own the
+// cart, through their access token or the guest session cookie.
+func (h *checkoutHandler) CreateOrderFromCart(w http.ResponseWriter, r *http.Request) {
+	cartIDStr := chi.URLParam(r, "id")
+	cartID, err := strconv.ParseInt(cartIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid cart ID", err)
+		return
+	}
+
+	order, err := h.checkoutService.CreateOrderFromCart(r.Context(), cartID, sessionIDFromContext(r.Context()))
+	if err != nil {
+		var checkoutErr *services.CartNotCheckoutableError
+		var stockErr *repository.InsufficientInventoryError
+		switch {
+		case errors.As(err, &checkoutErr):
+			httpx.WriteJSON(w, http.StatusUnprocessableEntity, false, checkoutErr.Error(), nil, checkoutErr.Validation)
+		case errors.As(err, &stockErr):
+			httpx.Error(w, http.StatusConflict, stockErr.Error(), nil)
+		case errors.Is(err, repository.ErrCartEmpty):
+			httpx.Error(w, http.StatusUnprocessableEntity, repository.ErrCartEmpty.Error(), nil)
+		case errors.Is(err, repository.ErrCartChanged):
+			httpx.Error(w, http.StatusConflict, repository.ErrCartChanged.Error(), nil)
+		case errors.Is(err, repository.ErrCouponLimitReached):
+			httpx.Error(w, http.StatusUnprocessableEntity, repository.ErrCouponLimitReached.Error(), nil)
+		case errors.Is(err, repository.ErrNotFound):
+			httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+		default:
+			httpx.Error(w, http.StatusInternalServerError, "Failed to place order", err)
+		}
+		return
+	}
+
+	httpx.Created(w, "Order placed successfully", order)
+}
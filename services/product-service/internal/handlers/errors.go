@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+)
+
+// writeAlreadyExists writes a 409 naming the conflicting resource when err came from a
+// unique constraint, and reports whether it did. The database error is never exposed.
+func writeAlreadyExists(w http.ResponseWriter, err error) bool {
+	var existsErr *repository.AlreadyExistsError
+	if !errors.As(err, &existsErr) {
+		return false
+	}
+	httpx.Error(w, http.StatusConflict, existsErr.Error(), nil)
+	return true
+}
+
+// writeInvalidQuantity writes a 400 when err is a service quantity guard, and reports
+// whether it did
+func writeInvalidQuantity(w http.ResponseWriter, err error) bool {
+	var quantityErr *services.QuantityError
+	if !errors.As(err, &quantityErr) {
+		return false
+	}
+	httpx.Error(w, http.StatusBadRequest, err.Error(), nil)
+	return true
+}
+
+// writeNotFound writes a 404 describing the missing resource when err is a lookup that
+// matched no row, and reports whether it did
+func writeNotFound(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+	return true
+}
+
+// notFoundMessage describes the missing resource in err without the wrapping added by
+// the service layer
+func notFoundMessage(err error) string {
+	var notFoundErr *repository.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return notFoundErr.Error()
+	}
+	// A resource sentinel such as ErrCartNotFound wraps ErrNotFound directly
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if errors.Unwrap(e) == repository.ErrNotFound {
+			return e.Error()
+		}
+	}
+	return repository.ErrNotFound.Error()
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupCartService fails cart lookups with err
+type lookupCartService struct {
+	services.CartService
+	err error
+}
+
+func (s *lookupCartService) GetCartByID(ctx context.Context, id int64) (*domain.Cart, error) {
+	return nil, s.err
+}
+
+func (s *lookupCartService) GetCartSummary(ctx context.Context, cartID int64) (*dto.CartSummaryResponse, error) {
+	return nil, s.err
+}
+
+// lookupInventoryService fails inventory lookups with err
+type lookupInventoryService struct {
+	services.InventoryService
+	err error
+}
+
+func (s *lookupInventoryService) GetInventoryByID(ctx context.Context, id int64) (*domain.Inventory, error) {
+	return nil, s.err
+}
+
+// missingProductRepository has no products
+type missingProductRepository struct {
+	repository.ProductRepository
+}
+
+func (r *missingProductRepository) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
+	return nil, &repository.NotFoundError{Message: fmt.Sprintf("product with ID %d not found", id), Kind: repository.ErrProductNotFound}
+}
+
+func TestNotFoundResponses(t *testing.T) {
+	missingCart := fmt.Errorf("failed to get cart: %w", &repository.NotFoundError{Message: "cart with ID 9 not found", Kind: repository.ErrCartNotFound})
+	missingInventory := fmt.Errorf("failed to get inventory: %w", &repository.NotFoundError{Message: "inventory with ID 9 not found", Kind: repository.ErrInventoryNotFound})
+	dbDown := errors.New("failed to get cart: connection refused")
+
+	tests := []struct {
+		name        string
+		route       string
+		handler     http.HandlerFunc
+		path        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "missing cart",
+			route:       "/carts/{id}",
+			handler:     NewCartHandler(&lookupCartService{err: missingCart}).GetCart,
+			path:        "/carts/9",
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "cart with ID 9 not found",
+		},
+		{
+			name:        "summary of a missing cart",
+			route:       "/carts/{id}/summary",
+			handler:     NewCartHandler(&lookupCartService{err: missingCart}).GetCartSummary,
+			path:        "/carts/9/summary",
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "cart with ID 9 not found",
+		},
+		{
+			name:        "cart lookup failure",
+			route:       "/carts/{id}",
+			handler:     NewCartHandler(&lookupCartService{err: dbDown}).GetCart,
+			path:        "/carts/9",
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to get cart",
+		},
+		{
+			name:        "missing inventory",
+			route:       "/inventory/{id}",
+			handler:     NewInventoryHandler(&lookupInventoryService{err: missingInventory}).GetInventoryByID,
+			path:        "/inventory/9",
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "inventory with ID 9 not found",
+		},
+		{
+			name:        "missing product",
+			route:       "/products/{id}",
+			handler:     NewProductHandler(services.NewProductService(&missingProductRepository{}, nil, services.CategoryPolicy{}, nil)).GetProduct,
+			path:        "/products/9",
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "product with ID 9 not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Get(tt.route, tt.handler)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			var response struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantMessage, response.Error.Message)
+		})
+	}
+}
+
+func TestNotFoundMessage(t *testing.T) {
+	assert.Equal(t, "coupon not found", notFoundMessage(fmt.Errorf("failed to apply coupon: %w", repository.ErrCouponNotFound)))
+	assert.Equal(t, "category not found", notFoundMessage(fmt.Errorf("%w: ID 4", repository.ErrCategoryNotFound)))
+	assert.Equal(t, "not found", notFoundMessage(repository.ErrNotFound))
+}
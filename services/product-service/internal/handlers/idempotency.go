@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// IdempotencyKeyHeader is the request header that makes a POST or PATCH safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed from an earlier request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength matches the idempotency_keys.idempotency_key column
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyPolicy configures where idempotency keys are kept and for how long
+type IdempotencyPolicy struct {
+	Store repository.IdempotencyRepository
+	TTL   time.Duration
+	// PendingTimeout is how long a key is held for a request that has not finished. A
+	// key left behind by a request that crashed can be claimed again once it passes, so
+	// it should be longer than the server's write timeout. Zero holds keys for TTL.
+	PendingTimeout time.Duration
+}
+
+// Idempotency makes POST and PATCH requests that carry an Idempotency-Key header run at
+// most once per caller and key within the policy's TTL. A retry with the same key and
+// body gets the first response replayed; the same key with a different request gets 422,
+// and a retry while the first request is still running gets 409. Server errors release
+// the key so the request can be retried. Requests without the header are not affected.
+//
+// Keys are scoped to the signed-in user, or else the guest session, so it must run after
+// Authenticate or GuestSession where a route has them. The header is ignored on requests
+// with neither, since there is no one to scope the key to.
+func Idempotency(policy IdempotencyPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			scope := idempotencyScope(r.Context())
+			if key == "" || scope == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				httpx.Error(w, http.StatusBadRequest, "Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters", nil)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httpx.Error(w, http.StatusBadRequest, "Failed to read request body", err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// The key is held for the pending timeout until the response is saved
+			pendingTimeout := policy.PendingTimeout
+			if pendingTimeout <= 0 {
+				pendingTimeout = policy.TTL
+			}
+			now := time.Now()
+			claim := &domain.IdempotencyKey{
+				Scope:       scope,
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(pendingTimeout),
+			}
+
+			existing, claimed, err := policy.Store.ClaimIdempotencyKey(r.Context(), claim)
+			if err != nil {
+				httpx.Error(w, http.StatusInternalServerError, "Failed to check idempotency key", err)
+				return
+			}
+			if !claimed {
+				replayIdempotentResponse(w, existing, claim.RequestHash)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// The key outlives the request's context, which is cancelled once it returns
+				ctx := context.WithoutCancel(r.Context())
+				if !completed || rec.status >= http.StatusInternalServerError {
+					if err := policy.Store.ReleaseIdempotencyKey(ctx, claim.ID); err != nil {
+						logger.FromContext(ctx).Warn("failed to release idempotency key", "key", key, "error", err)
+					}
+					return
+				}
+				if err := policy.Store.SaveIdempotencyResponse(ctx, claim.ID, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes(), now.Add(policy.TTL)); err != nil {
+					logger.FromContext(ctx).Warn("failed to save idempotent response", "key", key, "error", err)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+			completed = true
+		})
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already held by an earlier one
+func replayIdempotentResponse(w http.ResponseWriter, existing *domain.IdempotencyKey, requestHash string) {
+	if existing.RequestHash != requestHash {
+		httpx.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", nil)
+		return
+	}
+	if existing.StatusCode == nil {
+		httpx.Error(w, http.StatusConflict, "A request with this Idempotency-Key is still being processed", nil)
+		return
+	}
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(*existing.StatusCode)
+	w.Write(existing.ResponseBody)
+}
+
+// idempotencyScope returns who a key belongs to, so callers cannot replay each other's
+// responses. It returns "" when the request has neither a user nor a guest session.
+func idempotencyScope(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return "user:" + strconv.FormatUint(uint64(claims.UserID), 10)
+	}
+	if sessionID := sessionIDFromContext(ctx); sessionID != "" {
+		return "session:" + sessionID
+	}
+	return ""
+}
+
+// requestHash fingerprints a request so a reused key can be told apart from a retry
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy for replays
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
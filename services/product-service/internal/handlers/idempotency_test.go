@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyStore keeps idempotency keys in memory, the way the table does
+type memoryIdempotencyStore struct {
+	repository.IdempotencyRepository
+	mu     sync.Mutex
+	nextID int64
+	keys   map[string]*domain.IdempotencyKey
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]*domain.IdempotencyKey)}
+}
+
+func (s *memoryIdempotencyStore) ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := key.Scope + "|" + key.Key
+	if existing, ok := s.keys[id]; ok && existing.ExpiresAt.After(key.CreatedAt) {
+		copied := *existing
+		return &copied, false, nil
+	}
+	s.nextID++
+	key.ID = s.nextID
+	stored := *key
+	s.keys[id] = &stored
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) SaveIdempotencyResponse(ctx context.Context, id int64, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.ID == id {
+			key.StatusCode = &statusCode
+			key.ContentType = contentType
+			key.ResponseBody = append([]byte(nil), body...)
+			key.ExpiresAt = expiresAt
+		}
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, key := range s.keys {
+		if key.ID == id {
+			delete(s.keys, name)
+		}
+	}
+	return nil
+}
+
+// addItemCartService adds every requested item as a new cart item
+type addItemCartService struct {
+	services.CartService
+	items []*domain.CartItem
+}
+
+func (s *addItemCartService) AddItemToCart(ctx context.Context, cartID int64, req *dto.AddToCartRequest) (*domain.CartItem, error) {
+	item := &domain.CartItem{
+		ID:         int64(len(s.items) + 1),
+		CartID:     cartID,
+		ProductID:  req.ProductID,
+		Quantity:   req.Quantity,
+		UnitPrice:  9.99,
+		TotalPrice: 9.99 * float64(req.Quantity),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	s.items = append(s.items, item)
+	return item, nil
+}
+
+// newIdempotentCartServer serves AddItemToCart behind the cart routes' middleware
+func newIdempotentCartServer(service *addItemCartService) http.Handler {
+	return newIdempotentCartServerWithStore(service, newMemoryIdempotencyStore())
+}
+
+func newIdempotentCartServerWithStore(service *addItemCartService, store *memoryIdempotencyStore) http.Handler {
+	r := chi.NewRouter()
+	r.Use(Authenticate(testAuthPolicy))
+	r.Use(Idempotency(IdempotencyPolicy{Store: store, TTL: time.Hour, PendingTimeout: time.Minute}))
+	r.Post("/carts/{id}/items", NewCartHandler(service).AddItemToCart)
+	return r
+}
+
+func addItemRequest(t *testing.T, key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/carts/3/items", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+signAccessToken(t, "test-secret", accessClaims(7, RoleUser)))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotency_RetryAddsItemOnce(t *testing.T) {
+	service := &addItemCartService{}
+	server := newIdempotentCartServer(service)
+	body := `{"product_id":11,"quantity":2}`
+
+	first := httptest.NewRecorder()
+	server.ServeHTTP(first, addItemRequest(t, "retry-key", body))
+	// Responses carry a timestamp, so an identical body means the first one was replayed
+	time.Sleep(10 * time.Millisecond)
+	second := httptest.NewRecorder()
+	server.ServeHTTP(second, addItemRequest(t, "retry-key", body))
+
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Len(t, service.items, 1)
+	assert.Equal(t, first.Code, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_RejectsKeyReusedForDifferentRequest(t *testing.T) {
+	service := &addItemCartService{}
+	server := newIdempotentCartServer(service)
+
+	first := httptest.NewRecorder()
+	server.ServeHTTP(first, addItemRequest(t, "reused-key", `{"product_id":11,"quantity":2}`))
+	second := httptest.NewRecorder()
+	server.ServeHTTP(second, addItemRequest(t, "reused-key", `{"product_id":11,"quantity":5}`))
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, second.Code)
+	assert.Len(t, service.items, 1)
+}
+
+func TestIdempotency_WithoutKeyRunsEveryRequest(t *testing.T) {
+	service := &addItemCartService{}
+	server := newIdempotentCartServer(service)
+	body := `{"product_id":11,"quantity":2}`
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, addItemRequest(t, "", body))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	}
+	assert.Len(t, service.items, 2)
+}
+
+func TestIdempotency_IgnoresKeyWithoutCaller(t *testing.T) {
+	service := &addItemCartService{}
+	store := newMemoryIdempotencyStore()
+	r := chi.NewRouter()
+	r.Use(Idempotency(IdempotencyPolicy{Store: store, TTL: time.Hour}))
+	r.Post("/carts/{id}/items", NewCartHandler(service).AddItemToCart)
+	body := `{"product_id":11,"quantity":2}`
+
+	// With no user or session to scope the key to, anonymous callers must not share it
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/carts/3/items", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "shared-key")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+	}
+	assert.Len(t, service.items, 2)
+	assert.Empty(t, store.keys)
+}
+
+func TestIdempotency_PendingKeyExpires(t *testing.T) {
+	body := `{"product_id":11,"quantity":2}`
+	pending := func(expiresAt time.Time) *memoryIdempotencyStore {
+		store := newMemoryIdempotencyStore()
+		req := addItemRequest(t, "crashed-key", body)
+		store.keys["user:7|crashed-key"] = &domain.IdempotencyKey{
+			ID: 99, Scope: "user:7", Key: "crashed-key", RequestHash: requestHash(req, []byte(body)),
+			CreatedAt: time.Now().Add(-5 * time.Minute), ExpiresAt: expiresAt,
+		}
+		return store
+	}
+
+	t.Run("a request still within the pending timeout holds the key", func(t *testing.T) {
+		service := &addItemCartService{}
+		server := newIdempotentCartServerWithStore(service, pending(time.Now().Add(time.Minute)))
+
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, addItemRequest(t, "crashed-key", body))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Empty(t, service.items)
+	})
+
+	t.Run("a request that never finished frees the key once it lapses", func(t *testing.T) {
+		service := &addItemCartService{}
+		store := pending(time.Now().Add(-time.Second))
+		server := newIdempotentCartServerWithStore(service, store)
+
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, addItemRequest(t, "crashed-key", body))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Len(t, service.items, 1)
+		// Once the response is saved the key is kept for the full TTL
+		assert.WithinDuration(t, time.Now().Add(time.Hour), store.keys["user:7|crashed-key"].ExpiresAt, time.Minute)
+	})
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 )
 
 type IInventoryHandler interface {
@@ -21,16 +25,25 @@ type IInventoryHandler interface {
 	DeleteInventory(w http.ResponseWriter, r *http.Request)
 	ListInventory(w http.ResponseWriter, r *http.Request)
 	GetInventorySummary(w http.ResponseWriter, r *http.Request)
+	ListProductsNeedingRestock(w http.ResponseWriter, r *http.Request)
 
 	// Stock Movements
 	RecordStockMovement(w http.ResponseWriter, r *http.Request)
 	GetStockMovements(w http.ResponseWriter, r *http.Request)
 	GetStockMovementByID(w http.ResponseWriter, r *http.Request)
+	SetInventoryQuantity(w http.ResponseWriter, r *http.Request)
+	Restock(w http.ResponseWriter, r *http.Request)
+	AdjustStock(w http.ResponseWriter, r *http.Request)
 
 	// Stock Reservations
 	ReserveStock(w http.ResponseWriter, r *http.Request)
 	ReleaseStock(w http.ResponseWriter, r *http.Request)
 	GetStockReservations(w http.ResponseWriter, r *http.Request)
+	ExtendReservation(w http.ResponseWriter, r *http.Request)
+
+	// Stock Notifications
+	SubscribeStockNotification(w http.ResponseWriter, r *http.Request)
+	UnsubscribeStockNotification(w http.ResponseWriter, r *http.Request)
 
 	// Inventory Alerts
 	GetInventoryAlerts(w http.ResponseWriter, r *http.Request)
@@ -56,18 +69,28 @@ func NewInventoryHandler(inventoryService services.InventoryService) IInventoryH
 // CreateInventory creates a new inventory record
 func (h *inventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateInventoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	inventory, err := h.inventoryService.CreateInventory(r.Context(), &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrInventoryExists) {
+			httpx.Error(w, http.StatusConflict, err.Error(), err)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to create inventory", err)
 		return
 	}
@@ -82,9 +105,10 @@ func (h *inventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Reques
 		MinStockLevel:     inventory.MinStockLevel,
 		MaxStockLevel:     getIntPointer(inventory.MaxStockLevel),
 		ReorderPoint:      inventory.ReorderPoint,
-		LastRestocked:     inventory.LastRestocked.Format("2006-01-02T15:04:05Z07:00"),
-		CreatedAt:         inventory.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:         inventory.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastRestocked:     httpx.FormatTime(inventory.LastRestocked),
+		CreatedAt:         httpx.FormatTime(inventory.CreatedAt),
+		UpdatedAt:         httpx.FormatTime(inventory.UpdatedAt),
+		Version:           inventory.Version,
 	}
 
 	httpx.Created(w, "Inventory created successfully", response)
@@ -101,7 +125,10 @@ func (h *inventoryHandler) GetInventoryByID(w http.ResponseWriter, r *http.Reque
 
 	inventory, err := h.inventoryService.GetInventoryByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Inventory not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get inventory", err)
 		return
 	}
 
@@ -115,9 +142,10 @@ func (h *inventoryHandler) GetInventoryByID(w http.ResponseWriter, r *http.Reque
 		MinStockLevel:     inventory.MinStockLevel,
 		MaxStockLevel:     getIntPointer(inventory.MaxStockLevel),
 		ReorderPoint:      inventory.ReorderPoint,
-		LastRestocked:     inventory.LastRestocked.Format("2006-01-02T15:04:05Z07:00"),
-		CreatedAt:         inventory.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:         inventory.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastRestocked:     httpx.FormatTime(inventory.LastRestocked),
+		CreatedAt:         httpx.FormatTime(inventory.CreatedAt),
+		UpdatedAt:         httpx.FormatTime(inventory.UpdatedAt),
+		Version:           inventory.Version,
 	}
 
 	httpx.OK(w, "Inventory retrieved successfully", response)
@@ -145,7 +173,10 @@ func (h *inventoryHandler) GetInventoryByProduct(w http.ResponseWriter, r *http.
 
 	inventory, err := h.inventoryService.GetInventoryByProduct(r.Context(), productID, variantID)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Inventory not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get inventory", err)
 		return
 	}
 
@@ -159,9 +190,10 @@ func (h *inventoryHandler) GetInventoryByProduct(w http.ResponseWriter, r *http.
 		MinStockLevel:     inventory.MinStockLevel,
 		MaxStockLevel:     getIntPointer(inventory.MaxStockLevel),
 		ReorderPoint:      inventory.ReorderPoint,
-		LastRestocked:     inventory.LastRestocked.Format("2006-01-02T15:04:05Z07:00"),
-		CreatedAt:         inventory.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:         inventory.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastRestocked:     httpx.FormatTime(inventory.LastRestocked),
+		CreatedAt:         httpx.FormatTime(inventory.CreatedAt),
+		UpdatedAt:         httpx.FormatTime(inventory.UpdatedAt),
+		Version:           inventory.Version,
 	}
 
 	httpx.OK(w, "Inventory retrieved successfully", response)
@@ -177,18 +209,28 @@ func (h *inventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Reques
 	}
 
 	var req dto.UpdateInventoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	inventory, err := h.inventoryService.UpdateInventory(r.Context(), id, &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			httpx.Error(w, http.StatusConflict, repository.ErrVersionConflict.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to update inventory", err)
 		return
 	}
@@ -203,9 +245,10 @@ func (h *inventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Reques
 		MinStockLevel:     inventory.MinStockLevel,
 		MaxStockLevel:     getIntPointer(inventory.MaxStockLevel),
 		ReorderPoint:      inventory.ReorderPoint,
-		LastRestocked:     inventory.LastRestocked.Format("2006-01-02T15:04:05Z07:00"),
-		CreatedAt:         inventory.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:         inventory.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastRestocked:     httpx.FormatTime(inventory.LastRestocked),
+		CreatedAt:         httpx.FormatTime(inventory.CreatedAt),
+		UpdatedAt:         httpx.FormatTime(inventory.UpdatedAt),
+		Version:           inventory.Version,
 	}
 
 	httpx.OK(w, "Inventory updated successfully", response)
@@ -222,6 +265,9 @@ func (h *inventoryHandler) DeleteInventory(w http.ResponseWriter, r *http.Reques
 
 	err = h.inventoryService.DeleteInventory(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to delete inventory", err)
 		return
 	}
@@ -231,24 +277,10 @@ func (h *inventoryHandler) DeleteInventory(w http.ResponseWriter, r *http.Reques
 
 // ListInventory lists inventory with filters
 func (h *inventoryHandler) ListInventory(w http.ResponseWriter, r *http.Request) {
-	req := &dto.ListInventoryRequest{
-		Page:  1,
-		Limit: 20,
-	}
+	req := &dto.ListInventoryRequest{}
+	req.Page, req.Limit = httpx.PaginationFromRequest(r)
 
 	// Parse query parameters
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			req.Page = page
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
-			req.Limit = limit
-		}
-	}
-
 	if productIDStr := r.URL.Query().Get("product_id"); productIDStr != "" {
 		if productID, err := strconv.ParseInt(productIDStr, 10, 64); err == nil {
 			req.ProductID = &productID
@@ -293,23 +325,42 @@ func (h *inventoryHandler) GetInventorySummary(w http.ResponseWriter, r *http.Re
 	httpx.OK(w, "Inventory summary retrieved successfully", summary)
 }
 
+// ListProductsNeedingRestock lists products that are out of stock or at their reorder point
+func (h *inventoryHandler) ListProductsNeedingRestock(w http.ResponseWriter, r *http.Request) {
+	page, limit := httpx.PaginationFromRequest(r)
+
+	response, err := h.inventoryService.ListProductsNeedingRestock(r.Context(), page, limit)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "Failed to list products needing restock", err)
+		return
+	}
+
+	httpx.OK(w, "Products needing restock listed successfully", response)
+}
+
 // Stock Movements
 
 // RecordStockMovement records a stock movement
 func (h *inventoryHandler) RecordStockMovement(w http.ResponseWriter, r *http.Request) {
 	var req dto.StockMovementRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	movement, err := h.inventoryService.RecordStockMovement(r.Context(), &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if writeInvalidQuantity(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to record stock movement", err)
 		return
 	}
@@ -326,8 +377,8 @@ func (h *inventoryHandler) RecordStockMovement(w http.ResponseWriter, r *http.Re
 		ReferenceType:    movement.ReferenceType,
 		Reason:           movement.Reason,
 		Notes:            movement.Notes,
-		CreatedBy:        getInt64Pointer(movement.CreatedBy),
-		CreatedAt:        movement.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedBy:        movement.CreatedBy,
+		CreatedAt:        httpx.FormatTime(movement.CreatedAt),
 	}
 
 	httpx.Created(w, "Stock movement recorded successfully", response)
@@ -335,24 +386,10 @@ func (h *inventoryHandler) RecordStockMovement(w http.ResponseWriter, r *http.Re
 
 // GetStockMovements retrieves stock movements with filters
 func (h *inventoryHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
-	req := &dto.ListStockMovementsRequest{
-		Page:  1,
-		Limit: 20,
-	}
+	req := &dto.ListStockMovementsRequest{}
+	req.Page, req.Limit = httpx.PaginationFromRequest(r)
 
 	// Parse query parameters
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			req.Page = page
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
-			req.Limit = limit
-		}
-	}
-
 	if productIDStr := r.URL.Query().Get("product_id"); productIDStr != "" {
 		if productID, err := strconv.ParseInt(productIDStr, 10, 64); err == nil {
 			req.ProductID = &productID
@@ -397,7 +434,10 @@ func (h *inventoryHandler) GetStockMovementByID(w http.ResponseWriter, r *http.R
 
 	movement, err := h.inventoryService.GetStockMovementByID(r.Context(), id)
 	if err != nil {
-		httpx.Error(w, http.StatusNotFound, "Stock movement not found", err)
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to get stock movement", err)
 		return
 	}
 
@@ -413,30 +453,181 @@ func (h *inventoryHandler) GetStockMovementByID(w http.ResponseWriter, r *http.R
 		ReferenceType:    movement.ReferenceType,
 		Reason:           movement.Reason,
 		Notes:            movement.Notes,
-		CreatedBy:        getInt64Pointer(movement.CreatedBy),
-		CreatedAt:        movement.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedBy:        movement.CreatedBy,
+		CreatedAt:        httpx.FormatTime(movement.CreatedAt),
 	}
 
 	httpx.OK(w, "Stock movement retrieved successfully", response)
 }
 
+// SetInventoryQuantity sets inventory to an exact counted quantity
+func (h *inventoryHandler) SetInventoryQuantity(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetInventoryQuantityRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	movement, err := h.inventoryService.SetInventoryQuantity(r.Context(), &req)
+	if err != nil {
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		var conflictErr *repository.ReservedStockConflictError
+		if errors.As(err, &conflictErr) {
+			httpx.Error(w, http.StatusConflict, conflictErr.Error(), err)
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to set inventory quantity", err)
+		return
+	}
+
+	response := dto.StockMovementResponse{
+		ID:               movement.ID,
+		ProductID:        movement.ProductID,
+		ProductVariantID: movement.ProductVariantID,
+		MovementType:     movement.MovementType,
+		Quantity:         movement.Quantity,
+		PreviousQuantity: movement.PreviousQuantity,
+		NewQuantity:      movement.NewQuantity,
+		Reference:        movement.Reference,
+		ReferenceType:    movement.ReferenceType,
+		Reason:           movement.Reason,
+		Notes:            movement.Notes,
+		CreatedBy:        movement.CreatedBy,
+		CreatedAt:        httpx.FormatTime(movement.CreatedAt),
+	}
+
+	httpx.OK(w, "Inventory quantity set successfully", response)
+}
+
+// Restock receives new stock for a product or variant
+func (h *inventoryHandler) Restock(w http.ResponseWriter, r *http.Request) {
+	var req dto.RestockRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	movement, err := h.inventoryService.Restock(r.Context(), &req)
+	if err != nil {
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to restock inventory", err)
+		return
+	}
+
+	response := dto.StockMovementResponse{
+		ID:               movement.ID,
+		ProductID:        movement.ProductID,
+		ProductVariantID: movement.ProductVariantID,
+		MovementType:     movement.MovementType,
+		Quantity:         movement.Quantity,
+		PreviousQuantity: movement.PreviousQuantity,
+		NewQuantity:      movement.NewQuantity,
+		Reference:        movement.Reference,
+		ReferenceType:    movement.ReferenceType,
+		Reason:           movement.Reason,
+		Notes:            movement.Notes,
+		CreatedBy:        movement.CreatedBy,
+		CreatedAt:        httpx.FormatTime(movement.CreatedAt),
+	}
+
+	httpx.OK(w, "Inventory restocked successfully", response)
+}
+
+// AdjustStock changes stock by a signed delta, such as after finding damaged units
+func (h *inventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	var req dto.AdjustStockRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	movement, err := h.inventoryService.AdjustStock(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrZeroAdjustment) {
+			httpx.Error(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		var conflictErr *repository.ReservedStockConflictError
+		if errors.As(err, &conflictErr) {
+			httpx.Error(w, http.StatusConflict, conflictErr.Error(), err)
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to adjust stock", err)
+		return
+	}
+
+	response := dto.StockMovementResponse{
+		ID:               movement.ID,
+		ProductID:        movement.ProductID,
+		ProductVariantID: movement.ProductVariantID,
+		MovementType:     movement.MovementType,
+		Quantity:         movement.Quantity,
+		PreviousQuantity: movement.PreviousQuantity,
+		NewQuantity:      movement.NewQuantity,
+		Reference:        movement.Reference,
+		ReferenceType:    movement.ReferenceType,
+		Reason:           movement.Reason,
+		Notes:            movement.Notes,
+		CreatedBy:        movement.CreatedBy,
+		CreatedAt:        httpx.FormatTime(movement.CreatedAt),
+	}
+
+	httpx.OK(w, "Stock adjusted successfully", response)
+}
+
 // Stock Reservations
 
 // ReserveStock reserves stock for an order
 func (h *inventoryHandler) ReserveStock(w http.ResponseWriter, r *http.Request) {
 	var req dto.ReserveStockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	reservation, err := h.inventoryService.ReserveStock(r.Context(), &req)
 	if err != nil {
+		var stockErr *repository.InsufficientInventoryError
+		if errors.As(err, &stockErr) {
+			httpx.Error(w, http.StatusConflict, stockErr.Error(), nil)
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to reserve stock", err)
 		return
 	}
@@ -447,8 +638,8 @@ func (h *inventoryHandler) ReserveStock(w http.ResponseWriter, r *http.Request)
 		ProductVariantID: reservation.ProductVariantID,
 		OrderID:          reservation.OrderID,
 		Quantity:         reservation.Quantity,
-		ExpiresAt:        reservation.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-		CreatedAt:        reservation.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ExpiresAt:        httpx.FormatTime(reservation.ExpiresAt),
+		CreatedAt:        httpx.FormatTime(reservation.CreatedAt),
 	}
 
 	httpx.Created(w, "Stock reserved successfully", response)
@@ -457,18 +648,21 @@ func (h *inventoryHandler) ReserveStock(w http.ResponseWriter, r *http.Request)
 // ReleaseStock releases reserved stock
 func (h *inventoryHandler) ReleaseStock(w http.ResponseWriter, r *http.Request) {
 	var req dto.ReleaseStockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	err := h.inventoryService.ReleaseStock(r.Context(), &req)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to release stock", err)
 		return
 	}
@@ -491,7 +685,7 @@ func (h *inventoryHandler) GetStockReservations(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	var responses []dto.ReserveStockResponse
+	responses := make([]dto.ReserveStockResponse, 0, len(reservations))
 	for _, reservation := range reservations {
 		response := dto.ReserveStockResponse{
 			ID:               reservation.ID,
@@ -499,13 +693,134 @@ func (h *inventoryHandler) GetStockReservations(w http.ResponseWriter, r *http.R
 			ProductVariantID: reservation.ProductVariantID,
 			OrderID:          reservation.OrderID,
 			Quantity:         reservation.Quantity,
-			ExpiresAt:        reservation.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-			CreatedAt:        reservation.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ExpiresAt:        httpx.FormatTime(reservation.ExpiresAt),
+			CreatedAt:        httpx.FormatTime(reservation.CreatedAt),
 		}
 		responses = append(responses, response)
 	}
 
-	httpx.OK(w, "Stock reservations retrieved successfully", responses)
+	httpx.OKList(w, "Stock reservations retrieved successfully", responses)
+}
+
+// ExtendReservation pushes a stock reservation's expiry forward
+func (h *inventoryHandler) ExtendReservation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid reservation ID", err)
+		return
+	}
+
+	var req dto.ExtendReservationRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	reservation, err := h.inventoryService.ExtendReservation(r.Context(), id, time.Duration(req.ExtendBySeconds)*time.Second)
+	if err != nil {
+		if errors.Is(err, services.ErrReservationExpired) {
+			httpx.Error(w, http.StatusConflict, err.Error(), err)
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to extend stock reservation", err)
+		return
+	}
+
+	response := dto.ReserveStockResponse{
+		ID:               reservation.ID,
+		ProductID:        reservation.ProductID,
+		ProductVariantID: reservation.ProductVariantID,
+		OrderID:          reservation.OrderID,
+		Quantity:         reservation.Quantity,
+		ExpiresAt:        httpx.FormatTime(reservation.ExpiresAt),
+		CreatedAt:        httpx.FormatTime(reservation.CreatedAt),
+	}
+
+	httpx.OK(w, "Stock reservation extended successfully", response)
+}
+
+// Stock Notifications
+
+// SubscribeStockNotification subscribes the signed-in user, or a guest's email, to an item
+// coming back in stock
+func (h *inventoryHandler) SubscribeStockNotification(w http.ResponseWriter, r *http.Request) {
+	var req dto.StockNotificationRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	notification, err := h.inventoryService.SubscribeStockNotification(r.Context(), userctx.UserIDPtr(r.Context()), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductInStock):
+			httpx.Error(w, http.StatusConflict, "Item is in stock", err)
+		case errors.Is(err, repository.ErrStockNotificationExists):
+			httpx.Error(w, http.StatusConflict, "Already subscribed to this item", err)
+		case errors.Is(err, repository.ErrNotFound):
+			httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+		case strings.Contains(err.Error(), "must be provided"):
+			httpx.Error(w, http.StatusBadRequest, err.Error(), err)
+		default:
+			httpx.Error(w, http.StatusInternalServerError, "Failed to subscribe to stock notifications", err)
+		}
+		return
+	}
+
+	response := dto.StockNotificationResponse{
+		ID:               notification.ID,
+		UserID:           notification.UserID,
+		Email:            notification.Email,
+		ProductID:        notification.ProductID,
+		ProductVariantID: notification.ProductVariantID,
+		CreatedAt:        httpx.FormatTime(notification.CreatedAt),
+	}
+
+	httpx.Created(w, "Subscribed to stock notifications successfully", response)
+}
+
+// UnsubscribeStockNotification cancels one of the signed-in user's stock notification
+// subscriptions
+func (h *inventoryHandler) UnsubscribeStockNotification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userctx.UserID(r.Context())
+	if !ok {
+		httpx.Error(w, http.StatusUnauthorized, "authorization token required", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid stock notification ID", err)
+		return
+	}
+
+	if err := h.inventoryService.UnsubscribeStockNotification(r.Context(), id, userID); err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		if errors.Is(err, repository.ErrStockNotificationNotFound) {
+			httpx.Error(w, http.StatusNotFound, "Stock notification not found", err)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to unsubscribe from stock notifications", err)
+		return
+	}
+
+	httpx.OK(w, "Unsubscribed from stock notifications successfully", nil)
 }
 
 // Inventory Alerts
@@ -525,7 +840,7 @@ func (h *inventoryHandler) GetInventoryAlerts(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	var responses []dto.InventoryAlertResponse
+	responses := make([]dto.InventoryAlertResponse, 0, len(alerts))
 	for _, alert := range alerts {
 		response := dto.InventoryAlertResponse{
 			ID:                alert.ID,
@@ -535,18 +850,18 @@ func (h *inventoryHandler) GetInventoryAlerts(w http.ResponseWriter, r *http.Req
 			CurrentQuantity:   alert.CurrentQuantity,
 			ThresholdQuantity: alert.ThresholdQuantity,
 			IsResolved:        alert.IsResolved,
-			CreatedAt:         alert.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			CreatedAt:         httpx.FormatTime(alert.CreatedAt),
 		}
 
 		if alert.ResolvedAt != nil {
-			resolvedAt := alert.ResolvedAt.Format("2006-01-02T15:04:05Z07:00")
+			resolvedAt := httpx.FormatTime(*alert.ResolvedAt)
 			response.ResolvedAt = &resolvedAt
 		}
 
 		responses = append(responses, response)
 	}
 
-	httpx.OK(w, "Inventory alerts retrieved successfully", responses)
+	httpx.OKList(w, "Inventory alerts retrieved successfully", responses)
 }
 
 // ResolveInventoryAlert resolves an inventory alert
@@ -560,6 +875,9 @@ func (h *inventoryHandler) ResolveInventoryAlert(w http.ResponseWriter, r *http.
 
 	err = h.inventoryService.ResolveInventoryAlert(r.Context(), id)
 	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to resolve inventory alert", err)
 		return
 	}
@@ -583,23 +901,33 @@ func (h *inventoryHandler) CheckLowStockAlerts(w http.ResponseWriter, r *http.Re
 // BulkUpdateStock performs bulk stock updates
 func (h *inventoryHandler) BulkUpdateStock(w http.ResponseWriter, r *http.Request) {
 	var req dto.BulkStockUpdateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	response, err := h.inventoryService.BulkUpdateStock(r.Context(), &req)
 	if err != nil {
+		if writeInvalidQuantity(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "Failed to perform bulk stock update", err)
 		return
 	}
 
-	httpx.OK(w, "Bulk stock update completed", response)
+	switch {
+	case response.Success:
+		httpx.OK(w, "Bulk stock update completed", response)
+	case req.Atomic:
+		httpx.OK(w, "Bulk stock update rolled back; no items were updated", response)
+	default:
+		httpx.OK(w, "Bulk stock update completed with failures", response)
+	}
 }
 
 // Helper functions
@@ -609,10 +937,3 @@ func getIntPointer(i int) *int {
 	}
 	return &i
 }
-
-func getInt64Pointer(i int64) *int64 {
-	if i == 0 {
-		return nil
-	}
-	return &i
-}
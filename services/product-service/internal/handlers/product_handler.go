@@ -1,13 +1,15 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
 	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
@@ -17,13 +19,19 @@ type IProductHandler interface {
 	CreateProduct(w http.ResponseWriter, r *http.Request)
 	GetProduct(w http.ResponseWriter, r *http.Request)
 	GetProductBySKU(w http.ResponseWriter, r *http.Request)
+	IsSKUAvailable(w http.ResponseWriter, r *http.Request)
 	UpdateProduct(w http.ResponseWriter, r *http.Request)
 	DeleteProduct(w http.ResponseWriter, r *http.Request)
+	GetProductsByIDs(w http.ResponseWriter, r *http.Request)
+	BulkSetActive(w http.ResponseWriter, r *http.Request)
+	BulkDeleteProducts(w http.ResponseWriter, r *http.Request)
 	ListProducts(w http.ResponseWriter, r *http.Request)
 	GetProductsByCategory(w http.ResponseWriter, r *http.Request)
 	SearchProducts(w http.ResponseWriter, r *http.Request)
+	SuggestProducts(w http.ResponseWriter, r *http.Request)
 	UpdateProductQuantity(w http.ResponseWriter, r *http.Request)
 	GetProductsByTags(w http.ResponseWriter, r *http.Request)
+	CloneProduct(w http.ResponseWriter, r *http.Request)
 
 	// Product Variants
 	CreateProductVariant(w http.ResponseWriter, r *http.Request)
@@ -31,12 +39,21 @@ type IProductHandler interface {
 	GetProductVariants(w http.ResponseWriter, r *http.Request)
 	UpdateProductVariant(w http.ResponseWriter, r *http.Request)
 	DeleteProductVariant(w http.ResponseWriter, r *http.Request)
+	GetVariantByAttributes(w http.ResponseWriter, r *http.Request)
+	GetVariantAttributeOptions(w http.ResponseWriter, r *http.Request)
 
 	// Product Categories
 	AddProductToCategory(w http.ResponseWriter, r *http.Request)
 	RemoveProductFromCategory(w http.ResponseWriter, r *http.Request)
 	GetProductCategories(w http.ResponseWriter, r *http.Request)
 	UpdateProductCategories(w http.ResponseWriter, r *http.Request)
+
+	// Product Images
+	AddProductImage(w http.ResponseWriter, r *http.Request)
+	ListProductImages(w http.ResponseWriter, r *http.Request)
+	SetPrimaryImage(w http.ResponseWriter, r *http.Request)
+	ReorderImages(w http.ResponseWriter, r *http.Request)
+	DeleteProductImage(w http.ResponseWriter, r *http.Request)
 }
 
 type productHandler struct {
@@ -52,19 +69,25 @@ func NewProductHandler(productService services.ProductService) IProductHandler {
 // CreateProduct handles POST /api/v1/products
 func (h *productHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	// Validate Request
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	product, err := h.productService.CreateProduct(r.Context(), &req)
 	if err != nil {
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if writeAlreadyExists(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "already exists") {
 			httpx.Error(w, http.StatusConflict, err.Error(), err)
 			return
@@ -88,8 +111,7 @@ func (h *productHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 
 	product, err := h.productService.GetProductByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -110,8 +132,7 @@ func (h *productHandler) GetProductBySKU(w http.ResponseWriter, r *http.Request)
 
 	product, err := h.productService.GetProductBySKU(r.Context(), sku)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), err)
@@ -121,6 +142,27 @@ func (h *productHandler) GetProductBySKU(w http.ResponseWriter, r *http.Request)
 	httpx.OK(w, "product retrieved", product)
 }
 
+// IsSKUAvailable handles GET /api/v1/products/sku-available?sku=...
+func (h *productHandler) IsSKUAvailable(w http.ResponseWriter, r *http.Request) {
+	sku := strings.TrimSpace(r.URL.Query().Get("sku"))
+
+	if sku == "" {
+		httpx.Error(w, http.StatusBadRequest, "SKU is required", nil)
+		return
+	}
+
+	available, err := h.productService.IsSKUAvailable(r.Context(), sku)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check SKU availability", err)
+		return
+	}
+
+	httpx.OK(w, "SKU availability checked", map[string]interface{}{
+		"sku":       sku,
+		"available": available,
+	})
+}
+
 // UpdateProduct handles PUT /api/v1/products/{id}
 func (h *productHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
@@ -132,20 +174,33 @@ func (h *productHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.UpdateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	product, err := h.productService.UpdateProduct(r.Context(), id, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidProductUpdate) || errors.Is(err, services.ErrCategoriesRequired) {
+			httpx.Error(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			httpx.Error(w, http.StatusConflict, repository.ErrVersionConflict.Error(), nil)
+			return
+		}
+		if writeNotFound(w, err) {
 			return
 		}
 		if strings.Contains(err.Error(), "already exists") {
@@ -171,8 +226,7 @@ func (h *productHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 
 	err = h.productService.DeleteProduct(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -182,6 +236,72 @@ func (h *productHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	httpx.OK(w, "product deleted", nil)
 }
 
+// GetProductsByIDs handles POST /api/v1/products/batch
+func (h *productHandler) GetProductsByIDs(w http.ResponseWriter, r *http.Request) {
+	var req dto.GetProductsByIDsRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	products, err := h.productService.GetProductsByIDs(r.Context(), req.IDs)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to get products", err)
+		return
+	}
+
+	httpx.OKList(w, "products retrieved", products)
+}
+
+// BulkSetActive handles POST /api/v1/products/bulk/active
+func (h *productHandler) BulkSetActive(w http.ResponseWriter, r *http.Request) {
+	var req dto.BulkSetActiveRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	response, err := h.productService.BulkSetActive(r.Context(), req.IDs, *req.IsActive)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update products", err)
+		return
+	}
+
+	httpx.OK(w, "products updated", response)
+}
+
+// BulkDeleteProducts handles POST /api/v1/products/bulk/delete
+func (h *productHandler) BulkDeleteProducts(w http.ResponseWriter, r *http.Request) {
+	var req dto.BulkDeleteProductsRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	response, err := h.productService.BulkDeleteProducts(r.Context(), req.IDs)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete products", err)
+		return
+	}
+
+	httpx.OK(w, "products deleted", response)
+}
+
 // ListProducts handles GET /api/v1/products
 func (h *productHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	req := &dto.ListProductsRequest{}
@@ -226,6 +346,10 @@ func (h *productHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	req.Search = r.URL.Query().Get("search")
 	req.SortBy = r.URL.Query().Get("sort_by")
 	req.SortOrder = r.URL.Query().Get("sort_order")
+	if validationErrors := validation.ValidateSort(req.SortBy, req.SortOrder, domain.ProductSortFields); len(validationErrors) > 0 {
+		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		return
+	}
 
 	// Parse tags (comma-separated)
 	if tagsStr := r.URL.Query().Get("tags"); tagsStr != "" {
@@ -235,20 +359,15 @@ func (h *productHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			req.Page = page
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			req.Limit = limit
-		}
-	}
+	req.Page, req.Limit = httpx.PaginationFromRequest(r)
+	req.After = r.URL.Query().Get("after")
 
 	response, err := h.productService.ListProducts(r.Context(), req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductCursor) || errors.Is(err, services.ErrProductCursorSort) {
+			httpx.Error(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to list products", err)
 		return
 	}
@@ -267,20 +386,7 @@ func (h *productHandler) GetProductsByCategory(w http.ResponseWriter, r *http.Re
 	}
 
 	// Parse pagination parameters
-	page := 1
-	limit := 20
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
-	}
+	page, limit := httpx.PaginationFromRequest(r)
 
 	response, err := h.productService.GetProductsByCategory(r.Context(), categoryID, page, limit)
 	if err != nil {
@@ -299,29 +405,46 @@ func (h *productHandler) SearchProducts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != domain.ProductSearchFullText && mode != domain.ProductSearchTrigram {
+		httpx.Error(w, http.StatusBadRequest, "mode must be fulltext or trigram", nil)
+		return
+	}
+
 	// Parse pagination parameters
-	page := 1
-	limit := 20
+	page, limit := httpx.PaginationFromRequest(r)
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	response, err := h.productService.SearchProducts(r.Context(), query, mode, page, limit)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to search products", err)
+		return
 	}
 
+	httpx.OK(w, "search results retrieved", response)
+}
+
+// SuggestProducts handles GET /api/v1/products/suggest?q=...
+func (h *productHandler) SuggestProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		httpx.Error(w, http.StatusBadRequest, "search query is required", nil)
+		return
+	}
+
+	limit := 0
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
 			limit = l
 		}
 	}
 
-	response, err := h.productService.SearchProducts(r.Context(), query, page, limit)
+	suggestions, err := h.productService.SuggestProducts(r.Context(), query, limit)
 	if err != nil {
-		httpx.Error(w, http.StatusInternalServerError, "failed to search products", err)
+		httpx.Error(w, http.StatusInternalServerError, "failed to suggest products", err)
 		return
 	}
 
-	httpx.OK(w, "search results retrieved", response)
+	httpx.OKList(w, "suggestions retrieved", suggestions)
 }
 
 // UpdateProductQuantity handles PATCH /api/v1/products/{id}/quantity
@@ -334,24 +457,27 @@ func (h *productHandler) UpdateProductQuantity(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// The service rejects negative quantities; zero marks the product out of stock
 	var req struct {
-		Quantity int `json:"quantity" validate:"required,min=0"`
+		Quantity *int `json:"quantity" validate:"required"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
-	err = h.productService.UpdateProductQuantity(r.Context(), id, req.Quantity)
+	err = h.productService.UpdateProductQuantity(r.Context(), id, *req.Quantity)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeInvalidQuantity(w, err) {
+			return
+		}
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -360,7 +486,7 @@ func (h *productHandler) UpdateProductQuantity(w http.ResponseWriter, r *http.Re
 
 	httpx.OK(w, "product quantity updated", map[string]interface{}{
 		"product_id": id,
-		"quantity":   req.Quantity,
+		"quantity":   *req.Quantity,
 	})
 }
 
@@ -378,28 +504,49 @@ func (h *productHandler) GetProductsByTags(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Parse pagination parameters
-	page := 1
-	limit := 20
+	page, limit := httpx.PaginationFromRequest(r)
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	response, err := h.productService.GetProductsByTags(r.Context(), tags, page, limit)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to get products by tags", err)
+		return
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+	httpx.OK(w, "products retrieved", response)
+}
+
+// CloneProduct handles POST /api/v1/products/{id}/clone?include_images=true
+func (h *productHandler) CloneProduct(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	includeImages := false
+	if includeStr := r.URL.Query().Get("include_images"); includeStr != "" {
+		includeImages, err = strconv.ParseBool(includeStr)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, "invalid include_images value", err)
+			return
 		}
 	}
 
-	response, err := h.productService.GetProductsByTags(r.Context(), tags, page, limit)
+	product, err := h.productService.CloneProduct(r.Context(), id, includeImages)
 	if err != nil {
-		httpx.Error(w, http.StatusInternalServerError, "failed to get products by tags", err)
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to clone product", err)
 		return
 	}
 
-	httpx.OK(w, "products retrieved", response)
+	httpx.Created(w, "product cloned", product)
 }
 
 // Product Variant handlers
@@ -415,20 +562,23 @@ func (h *productHandler) CreateProductVariant(w http.ResponseWriter, r *http.Req
 	}
 
 	var req dto.CreateProductVariantRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	req.ProductID = productID
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	variant, err := h.productService.CreateProductVariant(r.Context(), &req)
 	if err != nil {
+		if writeAlreadyExists(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to create product variant", err)
 		return
 	}
@@ -448,8 +598,7 @@ func (h *productHandler) GetProductVariant(w http.ResponseWriter, r *http.Reques
 
 	variant, err := h.productService.GetProductVariantByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -475,7 +624,61 @@ func (h *productHandler) GetProductVariants(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	httpx.OK(w, "product variants retrieved", variants)
+	httpx.OKList(w, "product variants retrieved", variants)
+}
+
+// GetVariantByAttributes handles GET /api/v1/products/{id}/variants/resolve?size=M&color=red
+func (h *productHandler) GetVariantByAttributes(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+
+	productID, err := strconv.ParseInt(productIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	attrs := make(map[string]string)
+	for name, values := range r.URL.Query() {
+		if len(values) > 0 && values[0] != "" {
+			attrs[name] = values[0]
+		}
+	}
+
+	if len(attrs) == 0 {
+		httpx.Error(w, http.StatusBadRequest, "at least one attribute is required", nil)
+		return
+	}
+
+	variant, err := h.productService.GetVariantByAttributes(r.Context(), productID, attrs)
+	if err != nil {
+		if errors.Is(err, repository.ErrVariantCombinationNotFound) {
+			httpx.Error(w, http.StatusNotFound, repository.ErrVariantCombinationNotFound.Error(), nil)
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to resolve product variant", err)
+		return
+	}
+
+	httpx.OK(w, "product variant retrieved", variant)
+}
+
+// GetVariantAttributeOptions handles GET /api/v1/products/{id}/variants/options
+func (h *productHandler) GetVariantAttributeOptions(w http.ResponseWriter, r *http.Request) {
+	productIDStr := chi.URLParam(r, "id")
+
+	productID, err := strconv.ParseInt(productIDStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	options, err := h.productService.GetVariantAttributeOptions(r.Context(), productID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to get variant attribute options", err)
+		return
+	}
+
+	httpx.OKList(w, "variant attribute options retrieved", options)
 }
 
 // UpdateProductVariant handles PUT /api/v1/products/variants/{id}
@@ -489,20 +692,22 @@ func (h *productHandler) UpdateProductVariant(w http.ResponseWriter, r *http.Req
 	}
 
 	var req dto.UpdateProductVariantRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	variant, err := h.productService.UpdateProductVariant(r.Context(), id, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -524,8 +729,7 @@ func (h *productHandler) DeleteProductVariant(w http.ResponseWriter, r *http.Req
 
 	err = h.productService.DeleteProductVariant(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			httpx.Error(w, http.StatusNotFound, err.Error(), nil)
+		if writeNotFound(w, err) {
 			return
 		}
 		httpx.Error(w, http.StatusInternalServerError, err.Error(), nil)
@@ -552,18 +756,21 @@ func (h *productHandler) AddProductToCategory(w http.ResponseWriter, r *http.Req
 		IsPrimary  bool  `json:"is_primary"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
 	err = h.productService.AddProductToCategory(r.Context(), productID, req.CategoryID, req.IsPrimary)
 	if err != nil {
+		if writeAlreadyExists(w, err) {
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to add product to category", err)
 		return
 	}
@@ -620,7 +827,7 @@ func (h *productHandler) GetProductCategories(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	httpx.OK(w, "product categories retrieved", categories)
+	httpx.OKList(w, "product categories retrieved", categories)
 }
 
 // UpdateProductCategories handles PUT /api/v1/products/{id}/categories
@@ -635,20 +842,37 @@ func (h *productHandler) UpdateProductCategories(w http.ResponseWriter, r *http.
 
 	var req struct {
 		CategoryIDs []int64 `json:"category_ids" validate:"required"`
+		// PrimaryCategoryID picks the primary category; it must be one of CategoryIDs
+		PrimaryCategoryID *int64 `json:"primary_category_id" validate:"omitempty,gt=0"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request body", err)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
 		return
 	}
 
 	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
-		httpx.Error(w, http.StatusBadRequest, validationErrors.Error(), validationErrors)
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
 		return
 	}
 
-	err = h.productService.UpdateProductCategories(r.Context(), productID, req.CategoryIDs)
+	err = h.productService.UpdateProductCategories(r.Context(), productID, req.CategoryIDs, req.PrimaryCategoryID)
 	if err != nil {
+		if writeAlreadyExists(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrCategoriesRequired) || errors.Is(err, services.ErrPrimaryCategoryNotListed) {
+			httpx.Error(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if writeNotFound(w, err) {
+			return
+		}
+		var invalidErr *repository.InvalidCategoryIDsError
+		if errors.As(err, &invalidErr) {
+			httpx.Error(w, http.StatusBadRequest, invalidErr.Error(), invalidErr)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to update product categories", err)
 		return
 	}
@@ -658,3 +882,145 @@ func (h *productHandler) UpdateProductCategories(w http.ResponseWriter, r *http.
 		"category_ids": req.CategoryIDs,
 	})
 }
+
+// AddProductImage handles POST /api/v1/products/{id}/images
+func (h *productHandler) AddProductImage(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	var req dto.AddProductImageRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	image, err := h.productService.AddProductImage(r.Context(), productID, &req)
+	if err != nil {
+		writeProductImageError(w, err, "failed to add product image")
+		return
+	}
+
+	httpx.Created(w, "product image added", image)
+}
+
+// ListProductImages handles GET /api/v1/products/{id}/images
+func (h *productHandler) ListProductImages(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	images, err := h.productService.ListProductImages(r.Context(), productID)
+	if err != nil {
+		writeProductImageError(w, err, "failed to list product images")
+		return
+	}
+
+	httpx.OKList(w, "product images retrieved", images)
+}
+
+// SetPrimaryImage handles PUT /api/v1/products/{id}/images/{image_id}/primary
+func (h *productHandler) SetPrimaryImage(w http.ResponseWriter, r *http.Request) {
+	productID, imageID, ok := productImageIDs(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.productService.SetPrimaryImage(r.Context(), productID, imageID)
+	if err != nil {
+		writeProductImageError(w, err, "failed to set primary image")
+		return
+	}
+
+	httpx.OK(w, "primary image set", map[string]interface{}{
+		"product_id": productID,
+		"image_id":   imageID,
+	})
+}
+
+// ReorderImages handles PUT /api/v1/products/{id}/images/order
+func (h *productHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return
+	}
+
+	var req dto.ReorderProductImagesRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	err = h.productService.ReorderImages(r.Context(), productID, req.ImageIDs)
+	if err != nil {
+		writeProductImageError(w, err, "failed to reorder product images")
+		return
+	}
+
+	httpx.OK(w, "product images reordered", map[string]interface{}{
+		"product_id": productID,
+		"image_ids":  req.ImageIDs,
+	})
+}
+
+// DeleteProductImage handles DELETE /api/v1/products/{id}/images/{image_id}
+func (h *productHandler) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+	productID, imageID, ok := productImageIDs(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.productService.DeleteProductImage(r.Context(), productID, imageID)
+	if err != nil {
+		writeProductImageError(w, err, "failed to delete product image")
+		return
+	}
+
+	httpx.OK(w, "product image deleted", nil)
+}
+
+// productImageIDs parses the product and image IDs of an image route, writing a 400 when
+// either is invalid
+func productImageIDs(w http.ResponseWriter, r *http.Request) (productID, imageID int64, ok bool) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid product ID", err)
+		return 0, 0, false
+	}
+
+	imageID, err = strconv.ParseInt(chi.URLParam(r, "image_id"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid image ID", err)
+		return 0, 0, false
+	}
+
+	return productID, imageID, true
+}
+
+// writeProductImageError maps product image errors to responses: 404 for a missing
+// product or image, 422 for an incomplete reorder and 500 otherwise
+func writeProductImageError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrImageOrderMismatch):
+		httpx.Error(w, http.StatusUnprocessableEntity, repository.ErrImageOrderMismatch.Error(), nil)
+	case errors.Is(err, repository.ErrNotFound):
+		httpx.Error(w, http.StatusNotFound, notFoundMessage(err), nil)
+	default:
+		httpx.Error(w, http.StatusInternalServerError, message, err)
+	}
+}
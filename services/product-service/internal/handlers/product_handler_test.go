@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProduct_ValidationErrors(t *testing.T) {
+	service := &createProductService{}
+	body := `{"name":"Gear","sku":"GEAR 001","price":9.99,"tags":["ok","no,commas"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+
+	rr := httptest.NewRecorder()
+	NewProductHandler(service).CreateProduct(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.False(t, service.called)
+
+	var response struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Message string             `json:"message"`
+			Fields  []httpx.FieldError `json:"fields"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	assert.False(t, response.Success)
+	assert.NotEmpty(t, response.Error.Message)
+	assert.ElementsMatch(t, []httpx.FieldError{
+		{Field: "description", Message: "description is required", Tag: "required"},
+		{Field: "sku", Message: "sku must be 1 to 100 letters, numbers, hyphens or underscores", Tag: "sku"},
+		{Field: "tags[1]", Message: "tags[1] must be 1 to 50 letters, numbers, spaces or hyphens", Tag: "tag"},
+	}, response.Error.Fields)
+}
+
+// quantityProductRepository records whether a product quantity was written
+type quantityProductRepository struct {
+	repository.ProductRepository
+	updated bool
+}
+
+func (r *quantityProductRepository) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
+	return &domain.Product{ID: id, Quantity: 5}, nil
+}
+
+func (r *quantityProductRepository) UpdateProductQuantity(ctx context.Context, id int64, quantity int) error {
+	r.updated = true
+	return nil
+}
+
+func TestUpdateProductQuantity(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantUpdated bool
+	}{
+		{name: "negative quantity is rejected", body: `{"quantity":-3}`, wantStatus: http.StatusBadRequest},
+		{name: "missing quantity is rejected", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "zero marks the product out of stock", body: `{"quantity":0}`, wantStatus: http.StatusOK, wantUpdated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &quantityProductRepository{}
+			router := chi.NewRouter()
+			router.Patch("/api/v1/products/{id}/quantity", NewProductHandler(services.NewProductService(repo, nil, services.CategoryPolicy{}, nil)).UpdateProductQuantity)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/7/quantity", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantUpdated, repo.updated)
+		})
+	}
+}
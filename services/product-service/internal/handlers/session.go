@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GuestSessionPolicy configures the signed cookie that identifies a guest's cart session
+type GuestSessionPolicy struct {
+	CookieName string
+	Domain     string
+	Secure     bool
+	MaxAge     time.Duration
+	Secret     []byte
+}
+
+type sessionIDKey struct{}
+
+// GuestSession issues a signed, HTTP-only session cookie to clients that do not present a
+// valid one and makes the session ID available to cart handlers through the request
+// context. Cookies with a missing or bad signature are replaced, so clients cannot pick
+// another guest's session ID.
+func GuestSession(policy GuestSessionPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sessionID := ""
+			if cookie, err := r.Cookie(policy.CookieName); err == nil {
+				sessionID = policy.verify(cookie.Value)
+			}
+
+			if sessionID == "" {
+				sessionID = uuid.New().String()
+				http.SetCookie(w, &http.Cookie{
+					Name:     policy.CookieName,
+					Value:    policy.sign(sessionID),
+					Path:     "/",
+					Domain:   policy.Domain,
+					MaxAge:   int(policy.MaxAge.Seconds()),
+					Secure:   policy.Secure,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionIDKey{}, sessionID)))
+		})
+	}
+}
+
+// sessionIDFromContext returns the guest session ID set by GuestSession, or "" outside it
+func sessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey{}).(string)
+	return sessionID
+}
+
+// sign returns the cookie value for sessionID: the ID and its HMAC-SHA256 joined by a dot
+func (p GuestSessionPolicy) sign(sessionID string) string {
+	return sessionID + "." + base64.RawURLEncoding.EncodeToString(p.mac(sessionID))
+}
+
+// verify returns the session ID in a signed cookie value, or "" when the signature does not match
+func (p GuestSessionPolicy) verify(value string) string {
+	sessionID, signature, ok := strings.Cut(value, ".")
+	if !ok || sessionID == "" {
+		return ""
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, p.mac(sessionID)) {
+		return ""
+	}
+	return sessionID
+}
+
+func (p GuestSessionPolicy) mac(sessionID string) []byte {
+	h := hmac.New(sha256.New, p.Secret)
+	h.Write([]byte(sessionID))
+	return h.Sum(nil)
+}
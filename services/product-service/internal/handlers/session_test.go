@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGuestSessionServer(t *testing.T) (http.Handler, *string) {
+	t.Helper()
+
+	var seen string
+	policy := GuestSessionPolicy{
+		CookieName: "cart_session",
+		Domain:     "shop.example.com",
+		Secure:     true,
+		MaxAge:     24 * time.Hour,
+		Secret:     []byte("test-secret"),
+	}
+	handler := GuestSession(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = sessionIDFromContext(r.Context())
+	}))
+	return handler, &seen
+}
+
+func TestGuestSession_IssuesCookieOnFirstRequest(t *testing.T) {
+	handler, seen := newGuestSessionServer(t)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/carts/get-or-create", nil))
+
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	assert.Equal(t, "cart_session", cookie.Name)
+	assert.Equal(t, "shop.example.com", cookie.Domain)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, 86400, cookie.MaxAge)
+
+	require.NotEmpty(t, *seen)
+	assert.Contains(t, cookie.Value, *seen+".")
+}
+
+func TestGuestSession_ReusesCookieOnLaterRequests(t *testing.T) {
+	handler, seen := newGuestSessionServer(t)
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/api/v1/carts/get-or-create", nil))
+	cookie := first.Result().Cookies()[0]
+	sessionID := *seen
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/carts/session", nil)
+	req.AddCookie(cookie)
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, req)
+
+	assert.Equal(t, sessionID, *seen)
+	assert.Empty(t, second.Result().Cookies())
+}
+
+func TestGuestSession_ReplacesTamperedCookie(t *testing.T) {
+	handler, seen := newGuestSessionServer(t)
+
+	for _, value := range []string{"victim-session-id", "victim-session-id.forged", "victim-session-id."} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/carts/session", nil)
+		req.AddCookie(&http.Cookie{Name: "cart_session", Value: value})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.NotEqual(t, "victim-session-id", *seen, value)
+		assert.Len(t, rr.Result().Cookies(), 1, value)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+type IStockStreamHandler interface {
+	StreamProductStock(w http.ResponseWriter, r *http.Request)
+}
+
+// StockSubscriber hands out live stock changes for a product
+type StockSubscriber interface {
+	Subscribe(productID int64) (<-chan domain.StockChange, func(), error)
+}
+
+type stockStreamHandler struct {
+	subscriber StockSubscriber
+	heartbeat  time.Duration
+	shutdown   <-chan struct{}
+}
+
+// NewStockStreamHandler creates a handler streaming stock changes, writing a heartbeat
+// comment whenever a stream has been idle for the heartbeat interval. Closing shutdown
+// ends every open stream, since the server waits for them before it can stop.
+func NewStockStreamHandler(subscriber StockSubscriber, heartbeat time.Duration, shutdown <-chan struct{}) IStockStreamHandler {
+	return &stockStreamHandler{
+		subscriber: subscriber,
+		heartbeat:  heartbeat,
+		shutdown:   shutdown,
+	}
+}
+
+// StreamProductStock handles GET /api/v1/products/{id}/stock/stream as Server-Sent Events,
+// sending a "stock" event with the available quantity each time the product or one of its
+// variants changes. The stream ends when the client disconnects or the server shuts down.
+func (h *stockStreamHandler) StreamProductStock(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	productID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	changes, unsubscribe, err := h.subscriber.Subscribe(productID)
+	if err != nil {
+		httpx.Error(w, http.StatusServiceUnavailable, "Too many live stock subscribers for this product", nil)
+		return
+	}
+	defer unsubscribe()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logger.FromContext(r.Context()).Warn("stock stream not supported by response writer", "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.shutdown:
+			return
+		case change := <-changes:
+			data, err := json.Marshal(change)
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("failed to encode stock change", "product_id", productID, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: stock\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		heartbeat.Reset(h.heartbeat)
+	}
+}
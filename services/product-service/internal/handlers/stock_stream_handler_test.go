@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStockStreamServer(t *testing.T, broadcaster *jobs.StockBroadcaster, heartbeat time.Duration) *httptest.Server {
+	t.Helper()
+	return newStockStreamServerWithShutdown(t, broadcaster, heartbeat, nil)
+}
+
+func newStockStreamServerWithShutdown(t *testing.T, broadcaster *jobs.StockBroadcaster, heartbeat time.Duration, shutdown <-chan struct{}) *httptest.Server {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Get("/products/{id}/stock/stream", NewStockStreamHandler(broadcaster, heartbeat, shutdown).StreamProductStock)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// openStockStream connects to the stream and waits until the server has subscribed
+func openStockStream(t *testing.T, ctx context.Context, server *httptest.Server, broadcaster *jobs.StockBroadcaster, productID int64) *bufio.Reader {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/products/%d/stock/stream", server.URL, productID), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Eventually(t, func() bool { return broadcaster.Subscribers(productID) == 1 }, time.Second, 5*time.Millisecond)
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readEvent reads lines up to the blank line ending the next event
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestStockStream_SendsStockChanges(t *testing.T) {
+	broadcaster := jobs.NewStockBroadcaster(10)
+	server := newStockStreamServer(t, broadcaster, time.Hour)
+	reader := openStockStream(t, context.Background(), server, broadcaster, 7)
+
+	// Changes to other products are not sent
+	broadcaster.PublishStockChange(context.Background(), domain.StockChange{ProductID: 8, AvailableQuantity: 1})
+	broadcaster.PublishStockChange(context.Background(), domain.StockChange{ProductID: 7, AvailableQuantity: 3})
+
+	lines := readEvent(t, reader)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "event: stock", lines[0])
+
+	var change domain.StockChange
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &change))
+	assert.Equal(t, int64(7), change.ProductID)
+	assert.Equal(t, 3, change.AvailableQuantity)
+}
+
+func TestStockStream_SendsHeartbeats(t *testing.T) {
+	broadcaster := jobs.NewStockBroadcaster(10)
+	server := newStockStreamServer(t, broadcaster, 10*time.Millisecond)
+	reader := openStockStream(t, context.Background(), server, broadcaster, 7)
+
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, reader))
+}
+
+func TestStockStream_UnsubscribesOnDisconnect(t *testing.T) {
+	broadcaster := jobs.NewStockBroadcaster(10)
+	server := newStockStreamServer(t, broadcaster, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	openStockStream(t, ctx, server, broadcaster, 7)
+
+	cancel()
+
+	assert.Eventually(t, func() bool { return broadcaster.Subscribers(7) == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestStockStream_EndsOnShutdown(t *testing.T) {
+	broadcaster := jobs.NewStockBroadcaster(10)
+	shutdown := make(chan struct{})
+	server := newStockStreamServerWithShutdown(t, broadcaster, time.Hour, shutdown)
+	reader := openStockStream(t, context.Background(), server, broadcaster, 7)
+
+	close(shutdown)
+
+	_, err := reader.ReadString('\n')
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Eventually(t, func() bool { return broadcaster.Subscribers(7) == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestStockStream_RejectsSubscribersOverLimit(t *testing.T) {
+	broadcaster := jobs.NewStockBroadcaster(1)
+	server := newStockStreamServer(t, broadcaster, time.Hour)
+	productID := int64(7)
+	openStockStream(t, context.Background(), server, broadcaster, productID)
+
+	resp, err := http.Get(fmt.Sprintf("%s/products/%d/stock/stream", server.URL, productID))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
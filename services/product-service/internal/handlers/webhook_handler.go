@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/services"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+)
+
+type IWebhookHandler interface {
+	RegisterSubscription(w http.ResponseWriter, r *http.Request)
+	ListSubscriptions(w http.ResponseWriter, r *http.Request)
+	DisableSubscription(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookHandler struct {
+	webhookService services.WebhookService
+}
+
+func NewWebhookHandler(webhookService services.WebhookService) IWebhookHandler {
+	return &webhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// RegisterSubscription registers a partner URL for webhook events
+func (h *webhookHandler) RegisterSubscription(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookSubscriptionRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.InvalidBody(w, err)
+		return
+	}
+
+	if validationErrors := validation.ValidateStruct(req); len(validationErrors) > 0 {
+		httpx.ValidationError(w, validationErrors.Error(), validationErrors.FieldErrors())
+		return
+	}
+
+	subscription, err := h.webhookService.RegisterSubscription(r.Context(), &req)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "Failed to register webhook subscription", err)
+		return
+	}
+
+	// The secret is only returned here so the partner can verify signatures
+	response := dto.CreateWebhookSubscriptionResponse{
+		WebhookSubscriptionResponse: toWebhookSubscriptionResponse(subscription),
+		Secret:                      subscription.Secret,
+	}
+
+	httpx.Created(w, "Webhook subscription registered successfully", response)
+}
+
+// ListSubscriptions lists all webhook subscriptions
+func (h *webhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.webhookService.ListSubscriptions(r.Context())
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "Failed to list webhook subscriptions", err)
+		return
+	}
+
+	responses := make([]dto.WebhookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = toWebhookSubscriptionResponse(subscription)
+	}
+
+	httpx.OKList(w, "Webhook subscriptions retrieved successfully", responses)
+}
+
+// DisableSubscription stops deliveries to a subscription
+func (h *webhookHandler) DisableSubscription(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+
+	err = h.webhookService.DisableSubscription(r.Context(), id)
+	if err != nil {
+		if writeNotFound(w, err) {
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "Failed to disable webhook subscription", err)
+		return
+	}
+
+	httpx.OK(w, "Webhook subscription disabled successfully", nil)
+}
+
+func toWebhookSubscriptionResponse(subscription *domain.WebhookSubscription) dto.WebhookSubscriptionResponse {
+	return dto.WebhookSubscriptionResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		IsActive:   subscription.IsActive,
+		CreatedAt:  httpx.FormatTime(subscription.CreatedAt),
+		UpdatedAt:  httpx.FormatTime(subscription.UpdatedAt),
+	}
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// AbandonedCartJob finds inactive carts and sends a single reminder per idle period
+type AbandonedCartJob struct {
+	cartRepo   repository.CartRepository
+	notifier   CartReminderNotifier
+	inactivity time.Duration
+	now        func() time.Time
+}
+
+// NewAbandonedCartJob creates a job that reminds users about carts idle for longer than inactivity
+func NewAbandonedCartJob(cartRepo repository.CartRepository, notifier CartReminderNotifier, inactivity time.Duration) *AbandonedCartJob {
+	return &AbandonedCartJob{
+		cartRepo:   cartRepo,
+		notifier:   notifier,
+		inactivity: inactivity,
+		now:        time.Now,
+	}
+}
+
+// Run performs a single pass and returns the number of reminders sent
+func (j *AbandonedCartJob) Run(ctx context.Context) (int, error) {
+	now := j.now()
+
+	carts, err := j.cartRepo.GetAbandonedCarts(ctx, now.Add(-j.inactivity))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get abandoned carts: %w", err)
+	}
+
+	sent := 0
+	for _, cart := range carts {
+		if err := j.notifier.SendCartReminder(ctx, cart); err != nil {
+			// Leave last_reminder_at untouched so the cart is retried on the next run
+			logger.FromContext(ctx).Warn("failed to send cart reminder", "cart_id", cart.ID, "error", err)
+			continue
+		}
+
+		if err := j.cartRepo.MarkCartReminderSent(ctx, cart.ID, now); err != nil {
+			logger.FromContext(ctx).Warn("failed to mark cart reminder sent", "cart_id", cart.ID, "error", err)
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// Start runs the job on every interval until ctx is cancelled
+func (j *AbandonedCartJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := j.Run(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error("abandoned cart job failed", "error", err)
+				continue
+			}
+			if sent > 0 {
+				logger.FromContext(ctx).Info("abandoned cart job sent reminders", "sent", sent)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCartRepository is a mock implementation of CartRepository.
+// Methods not overridden here fall through to the embedded nil interface.
+type MockCartRepository struct {
+	mock.Mock
+	repository.CartRepository
+}
+
+func (m *MockCartRepository) GetAbandonedCarts(ctx context.Context, inactiveSince time.Time) ([]*domain.Cart, error) {
+	args := m.Called(ctx, inactiveSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) MarkCartReminderSent(ctx context.Context, cartID int64, sentAt time.Time) error {
+	args := m.Called(ctx, cartID, sentAt)
+	return args.Error(0)
+}
+
+// MockNotifier is a mock implementation of CartReminderNotifier
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) SendCartReminder(ctx context.Context, cart *domain.Cart) error {
+	args := m.Called(ctx, cart)
+	return args.Error(0)
+}
+
+func TestAbandonedCartJob_Run(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	userID := int64(42)
+
+	t.Run("sends reminder and records it", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		notifier := new(MockNotifier)
+		cart := &domain.Cart{ID: 1, UserID: &userID}
+
+		cartRepo.On("GetAbandonedCarts", mock.Anything, now.Add(-24*time.Hour)).Return([]*domain.Cart{cart}, nil)
+		notifier.On("SendCartReminder", mock.Anything, cart).Return(nil)
+		cartRepo.On("MarkCartReminderSent", mock.Anything, int64(1), now).Return(nil)
+
+		job := NewAbandonedCartJob(cartRepo, notifier, 24*time.Hour)
+		job.now = func() time.Time { return now }
+
+		sent, err := job.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		cartRepo.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("already reminded carts are not sent again", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		notifier := new(MockNotifier)
+		cart := &domain.Cart{ID: 1, UserID: &userID}
+
+		// First run reminds the cart; the second run sees it filtered out by last_reminder_at
+		cartRepo.On("GetAbandonedCarts", mock.Anything, mock.Anything).Return([]*domain.Cart{cart}, nil).Once()
+		cartRepo.On("GetAbandonedCarts", mock.Anything, mock.Anything).Return([]*domain.Cart{}, nil).Once()
+		notifier.On("SendCartReminder", mock.Anything, cart).Return(nil).Once()
+		cartRepo.On("MarkCartReminderSent", mock.Anything, int64(1), now).Return(nil).Once()
+
+		job := NewAbandonedCartJob(cartRepo, notifier, 24*time.Hour)
+		job.now = func() time.Time { return now }
+
+		sent, err := job.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		sent, err = job.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		notifier.AssertNumberOfCalls(t, "SendCartReminder", 1)
+		cartRepo.AssertNumberOfCalls(t, "MarkCartReminderSent", 1)
+	})
+
+	t.Run("failed notification is not recorded", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		notifier := new(MockNotifier)
+		cart := &domain.Cart{ID: 1, UserID: &userID}
+
+		cartRepo.On("GetAbandonedCarts", mock.Anything, mock.Anything).Return([]*domain.Cart{cart}, nil)
+		notifier.On("SendCartReminder", mock.Anything, cart).Return(errors.New("smtp unavailable"))
+
+		job := NewAbandonedCartJob(cartRepo, notifier, 24*time.Hour)
+		job.now = func() time.Time { return now }
+
+		sent, err := job.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		cartRepo.AssertNotCalled(t, "MarkCartReminderSent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		notifier := new(MockNotifier)
+
+		cartRepo.On("GetAbandonedCarts", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		job := NewAbandonedCartJob(cartRepo, notifier, 24*time.Hour)
+
+		_, err := job.Run(context.Background())
+
+		assert.Error(t, err)
+	})
+}
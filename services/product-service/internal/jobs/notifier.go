@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// CartReminderNotifier sends abandoned cart reminders to users
+type CartReminderNotifier interface {
+	SendCartReminder(ctx context.Context, cart *domain.Cart) error
+}
+
+type logCartReminderNotifier struct{}
+
+// NewLogCartReminderNotifier returns a notifier that only logs reminders.
+// It is the default until an email provider is wired in.
+func NewLogCartReminderNotifier() CartReminderNotifier {
+	return &logCartReminderNotifier{}
+}
+
+// SendCartReminder logs the reminder that would be sent
+func (n *logCartReminderNotifier) SendCartReminder(ctx context.Context, cart *domain.Cart) error {
+	var userID int64
+	if cart.UserID != nil {
+		userID = *cart.UserID
+	}
+	logger.FromContext(ctx).Info("abandoned cart reminder", "cart_id", cart.ID, "user_id", userID)
+	return nil
+}
+
+// LogStockNotifier only logs back-in-stock notifications.
+// It is the default until an email provider is wired in.
+type LogStockNotifier struct{}
+
+// NewLogStockNotifier returns a notifier that only logs back-in-stock notifications
+func NewLogStockNotifier() *LogStockNotifier {
+	return &LogStockNotifier{}
+}
+
+// SendBackInStock logs the notification that would be sent
+func (n *LogStockNotifier) SendBackInStock(ctx context.Context, notification *domain.StockNotification) error {
+	logger.FromContext(ctx).Info("back in stock notification",
+		"notification_id", notification.ID, "product_id", notification.ProductID,
+		"variant_id", notification.ProductVariantID, "user_id", notification.UserID, "email", notification.Email)
+	return nil
+}
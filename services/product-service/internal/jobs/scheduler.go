@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// ScheduledJob is a maintenance task run by a Scheduler on a fixed interval
+type ScheduledJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Ticker delivers ticks until stopped. It lets tests drive a Scheduler without waiting
+// on real time.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Scheduler runs maintenance jobs, each on its own interval, until its context is cancelled
+type Scheduler struct {
+	jobs      []ScheduledJob
+	newTicker func(interval time.Duration) Ticker
+}
+
+// NewScheduler creates a scheduler for jobs. Jobs with an interval of zero or less are
+// disabled and never run.
+func NewScheduler(jobs ...ScheduledJob) *Scheduler {
+	return &Scheduler{
+		jobs: jobs,
+		newTicker: func(interval time.Duration) Ticker {
+			return realTicker{time.NewTicker(interval)}
+		},
+	}
+}
+
+// Start runs every enabled job after each of its intervals and blocks until ctx is
+// cancelled and all jobs have returned. A failed run is logged and retried on the next tick.
+func (s *Scheduler) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(job ScheduledJob) {
+			defer wg.Done()
+			s.loop(logger.WithContext(ctx, logger.FromContext(ctx).With("scheduled_job", job.Name)), job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// loop runs job on every tick until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, job ScheduledJob) {
+	ticker := s.newTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if err := job.Run(ctx); err != nil {
+				logger.FromContext(ctx).Error("scheduled job failed", "error", err)
+			}
+		}
+	}
+}
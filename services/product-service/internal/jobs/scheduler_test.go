@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTicker is a Ticker that only ticks when the test sends on ch
+type fakeTicker struct {
+	ch      chan time.Time
+	stopped chan struct{}
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+func (t *fakeTicker) Stop()               { close(t.stopped) }
+
+// fakeClock hands out a fakeTicker per interval
+type fakeClock struct {
+	mu      sync.Mutex
+	tickers map[time.Duration]*fakeTicker
+	created chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{tickers: map[time.Duration]*fakeTicker{}, created: make(chan struct{}, 10)}
+}
+
+func (c *fakeClock) newTicker(interval time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{ch: make(chan time.Time), stopped: make(chan struct{})}
+	c.tickers[interval] = ticker
+	c.created <- struct{}{}
+	return ticker
+}
+
+func (c *fakeClock) ticker(interval time.Duration) *fakeTicker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tickers[interval]
+}
+
+func TestScheduler_Start(t *testing.T) {
+	runs := make(chan string, 10)
+	job := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			runs <- name
+			return err
+		}
+	}
+
+	clock := newFakeClock()
+	scheduler := NewScheduler(
+		ScheduledJob{Name: "reservations", Interval: time.Minute, Run: job("reservations", nil)},
+		ScheduledJob{Name: "carts", Interval: time.Hour, Run: job("carts", errors.New("database unavailable"))},
+		ScheduledJob{Name: "disabled", Interval: 0, Run: job("disabled", nil)},
+	)
+	scheduler.newTicker = clock.newTicker
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Start(ctx)
+		close(done)
+	}()
+
+	// Only the two enabled jobs get a ticker
+	for i := 0; i < 2; i++ {
+		<-clock.created
+	}
+	assert.Nil(t, clock.ticker(0))
+
+	clock.ticker(time.Minute).ch <- time.Now()
+	assert.Equal(t, "reservations", <-runs)
+
+	// A failed run does not stop the job from running again
+	clock.ticker(time.Hour).ch <- time.Now()
+	assert.Equal(t, "carts", <-runs)
+	clock.ticker(time.Hour).ch <- time.Now()
+	assert.Equal(t, "carts", <-runs)
+
+	clock.ticker(time.Minute).ch <- time.Now()
+	assert.Equal(t, "reservations", <-runs)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "scheduler did not stop after cancellation")
+	}
+
+	<-clock.ticker(time.Minute).stopped
+	<-clock.ticker(time.Hour).stopped
+	assert.Empty(t, runs)
+}
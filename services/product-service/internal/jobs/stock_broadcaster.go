@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// ErrTooManyStockSubscribers is returned when a product already has the maximum number of
+// live stock subscribers
+var ErrTooManyStockSubscribers = errors.New("too many stock subscribers for product")
+
+// stockSubscriberBuffer is how many unread stock changes a subscriber holds before the
+// oldest is dropped; only the latest level matters to a live display
+const stockSubscriberBuffer = 8
+
+// StockBroadcaster fans stock changes out to in-process subscribers of each product.
+// Publishing never blocks: a subscriber that falls behind loses its oldest updates.
+type StockBroadcaster struct {
+	maxPerProduct int
+
+	mu          sync.Mutex
+	subscribers map[int64]map[chan domain.StockChange]struct{}
+}
+
+// NewStockBroadcaster creates a broadcaster allowing up to maxPerProduct subscribers per
+// product; zero or less means no limit
+func NewStockBroadcaster(maxPerProduct int) *StockBroadcaster {
+	return &StockBroadcaster{
+		maxPerProduct: maxPerProduct,
+		subscribers:   make(map[int64]map[chan domain.StockChange]struct{}),
+	}
+}
+
+// Subscribe registers for stock changes of a product and its variants. The returned
+// function unsubscribes and must be called once the subscriber is done.
+func (b *StockBroadcaster) Subscribe(productID int64) (<-chan domain.StockChange, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subscribers[productID]
+	if b.maxPerProduct > 0 && len(subs) >= b.maxPerProduct {
+		return nil, nil, ErrTooManyStockSubscribers
+	}
+	if subs == nil {
+		subs = make(map[chan domain.StockChange]struct{})
+		b.subscribers[productID] = subs
+	}
+
+	ch := make(chan domain.StockChange, stockSubscriberBuffer)
+	subs[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(b.subscribers, productID)
+			}
+		})
+	}
+	return ch, unsubscribe, nil
+}
+
+// Subscribers returns how many subscribers a product currently has
+func (b *StockBroadcaster) Subscribers(productID int64) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[productID])
+}
+
+// PublishStockChange sends change to every subscriber of its product
+func (b *StockBroadcaster) PublishStockChange(ctx context.Context, change domain.StockChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[change.ProductID] {
+		select {
+		case ch <- change:
+		default:
+			// Full: drop the oldest update to make room for the latest
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- change:
+			default:
+			}
+		}
+	}
+}
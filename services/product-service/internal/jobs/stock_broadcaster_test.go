@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockBroadcaster_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers changes only to the product's subscribers", func(t *testing.T) {
+		b := NewStockBroadcaster(0)
+		seven, unsubscribe, err := b.Subscribe(7)
+		require.NoError(t, err)
+		defer unsubscribe()
+		eight, unsubscribeEight, err := b.Subscribe(8)
+		require.NoError(t, err)
+		defer unsubscribeEight()
+
+		b.PublishStockChange(ctx, domain.StockChange{ProductID: 7, AvailableQuantity: 2})
+
+		assert.Equal(t, 2, (<-seven).AvailableQuantity)
+		assert.Empty(t, eight)
+	})
+
+	t.Run("caps subscribers per product", func(t *testing.T) {
+		b := NewStockBroadcaster(1)
+		_, unsubscribe, err := b.Subscribe(7)
+		require.NoError(t, err)
+
+		_, _, err = b.Subscribe(7)
+		assert.ErrorIs(t, err, ErrTooManyStockSubscribers)
+
+		_, unsubscribeOther, err := b.Subscribe(8)
+		require.NoError(t, err)
+		unsubscribeOther()
+
+		unsubscribe()
+		unsubscribe()
+		assert.Equal(t, 0, b.Subscribers(7))
+		_, _, err = b.Subscribe(7)
+		assert.NoError(t, err)
+	})
+
+	t.Run("keeps the latest changes for a slow subscriber", func(t *testing.T) {
+		b := NewStockBroadcaster(0)
+		changes, unsubscribe, err := b.Subscribe(7)
+		require.NoError(t, err)
+		defer unsubscribe()
+
+		for i := 1; i <= stockSubscriberBuffer+3; i++ {
+			b.PublishStockChange(ctx, domain.StockChange{ProductID: 7, AvailableQuantity: i})
+		}
+
+		require.Len(t, changes, stockSubscriberBuffer)
+		assert.Equal(t, 4, (<-changes).AvailableQuantity)
+	})
+}
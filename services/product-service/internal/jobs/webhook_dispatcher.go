@@ -0,0 +1,189 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-Signature"
+
+// ErrWebhookQueueFull is returned when the dispatcher cannot accept more deliveries
+var ErrWebhookQueueFull = errors.New("webhook delivery queue is full")
+
+// DeliveryAttemptRecorder stores the outcome of each delivery attempt
+type DeliveryAttemptRecorder interface {
+	RecordDeliveryAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error
+}
+
+// WebhookDispatcherConfig controls delivery concurrency and retry behaviour
+type WebhookDispatcherConfig struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// WebhookDispatcher sends signed webhook deliveries in the background,
+// retrying non-2xx responses with exponential backoff
+type WebhookDispatcher struct {
+	cfg      WebhookDispatcherConfig
+	client   *http.Client
+	recorder DeliveryAttemptRecorder
+	queue    chan *domain.WebhookDelivery
+	now      func() time.Time
+}
+
+// NewWebhookDispatcher creates a dispatcher; call Run to start delivering
+func NewWebhookDispatcher(cfg WebhookDispatcherConfig, recorder DeliveryAttemptRecorder) *WebhookDispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &WebhookDispatcher{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		recorder: recorder,
+		queue:    make(chan *domain.WebhookDelivery, cfg.QueueSize),
+		now:      time.Now,
+	}
+}
+
+// SignPayload returns the hex-encoded HMAC-SHA256 of payload keyed by secret, prefixed with "sha256="
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue schedules a delivery without blocking the caller
+func (d *WebhookDispatcher) Enqueue(delivery *domain.WebhookDelivery) error {
+	select {
+	case d.queue <- delivery:
+		return nil
+	default:
+		return ErrWebhookQueueFull
+	}
+}
+
+// Run delivers queued webhooks until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-d.queue:
+					if err := d.Deliver(ctx, delivery); err != nil {
+						logger.FromContext(ctx).Warn("webhook delivery failed",
+							"event_id", delivery.EventID, "subscription_id", delivery.Subscription.ID, "error", err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Deliver sends one delivery, retrying until it succeeds, attempts run out or ctx is cancelled
+func (d *WebhookDispatcher) Deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	var lastErr error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		statusCode, err := d.send(ctx, delivery)
+		d.record(ctx, delivery, attempt, statusCode, err)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt == d.cfg.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.backoff(attempt)):
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", d.cfg.MaxAttempts, lastErr)
+}
+
+// backoff returns the wait before the next attempt: BaseBackoff doubled per attempt, capped at MaxBackoff
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseBackoff << (attempt - 1)
+	if d.cfg.MaxBackoff > 0 && (delay > d.cfg.MaxBackoff || delay <= 0) {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set(SignatureHeader, SignPayload(delivery.Subscription.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain a bounded amount so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *WebhookDispatcher) record(ctx context.Context, delivery *domain.WebhookDelivery, attempt, statusCode int, sendErr error) {
+	entry := &domain.WebhookDeliveryAttempt{
+		SubscriptionID: delivery.Subscription.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Attempt:        attempt,
+		Success:        sendErr == nil,
+		CreatedAt:      d.now(),
+	}
+	if statusCode != 0 {
+		entry.StatusCode = &statusCode
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		entry.Error = &msg
+	}
+
+	if err := d.recorder.RecordDeliveryAttempt(ctx, entry); err != nil {
+		logger.FromContext(ctx).Warn("failed to record webhook delivery attempt",
+			"event_id", delivery.EventID, "subscription_id", delivery.Subscription.ID, "error", err)
+	}
+}
@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAttemptRecorder keeps delivery attempts in memory
+type recordingAttemptRecorder struct {
+	mu       sync.Mutex
+	attempts []*domain.WebhookDeliveryAttempt
+}
+
+func (r *recordingAttemptRecorder) RecordDeliveryAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func newTestDispatcher(recorder DeliveryAttemptRecorder, maxAttempts int) *WebhookDispatcher {
+	return NewWebhookDispatcher(WebhookDispatcherConfig{
+		Workers:     1,
+		QueueSize:   1,
+		MaxAttempts: maxAttempts,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		Timeout:     time.Second,
+	}, recorder)
+}
+
+func TestSignPayload(t *testing.T) {
+	// Known HMAC-SHA256 test vector (RFC 4231 style key/message)
+	signature := SignPayload("key", []byte("The quick brown fox jumps over the lazy dog"))
+
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", signature)
+	assert.NotEqual(t, signature, SignPayload("other-key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestWebhookDispatcher_Deliver(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"product.created"}`)
+
+	t.Run("signs the payload", func(t *testing.T) {
+		var gotSignature, gotEvent string
+		var gotBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotSignature = r.Header.Get(SignatureHeader)
+			gotEvent = r.Header.Get("X-Webhook-Event")
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		recorder := &recordingAttemptRecorder{}
+		dispatcher := newTestDispatcher(recorder, 3)
+		delivery := &domain.WebhookDelivery{
+			Subscription: &domain.WebhookSubscription{ID: 1, URL: server.URL, Secret: "s3cret"},
+			EventID:      "evt_1",
+			EventType:    "product.created",
+			Payload:      payload,
+		}
+
+		err := dispatcher.Deliver(context.Background(), delivery)
+
+		require.NoError(t, err)
+		assert.Equal(t, SignPayload("s3cret", payload), gotSignature)
+		assert.Equal(t, "product.created", gotEvent)
+		assert.Equal(t, payload, gotBody)
+		require.Len(t, recorder.attempts, 1)
+		assert.True(t, recorder.attempts[0].Success)
+		assert.Equal(t, http.StatusNoContent, *recorder.attempts[0].StatusCode)
+	})
+
+	t.Run("retries non-2xx responses", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		recorder := &recordingAttemptRecorder{}
+		dispatcher := newTestDispatcher(recorder, 5)
+		delivery := &domain.WebhookDelivery{
+			Subscription: &domain.WebhookSubscription{ID: 1, URL: server.URL, Secret: "s3cret"},
+			EventID:      "evt_2",
+			EventType:    "cart.abandoned",
+			Payload:      payload,
+		}
+
+		err := dispatcher.Deliver(context.Background(), delivery)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+		require.Len(t, recorder.attempts, 3)
+		assert.False(t, recorder.attempts[0].Success)
+		assert.Equal(t, http.StatusServiceUnavailable, *recorder.attempts[0].StatusCode)
+		assert.Equal(t, 2, recorder.attempts[1].Attempt)
+		assert.True(t, recorder.attempts[2].Success)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		recorder := &recordingAttemptRecorder{}
+		dispatcher := newTestDispatcher(recorder, 2)
+		delivery := &domain.WebhookDelivery{
+			Subscription: &domain.WebhookSubscription{ID: 1, URL: server.URL, Secret: "s3cret"},
+			EventID:      "evt_3",
+			EventType:    "product.updated",
+			Payload:      payload,
+		}
+
+		err := dispatcher.Deliver(context.Background(), delivery)
+
+		assert.Error(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		require.Len(t, recorder.attempts, 2)
+		assert.False(t, recorder.attempts[1].Success)
+		assert.NotNil(t, recorder.attempts[1].Error)
+	})
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(WebhookDispatcherConfig{
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Second,
+	}, &recordingAttemptRecorder{})
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(4))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(80))
+}
+
+func TestWebhookDispatcher_EnqueueFull(t *testing.T) {
+	dispatcher := newTestDispatcher(&recordingAttemptRecorder{}, 1)
+	delivery := &domain.WebhookDelivery{Subscription: &domain.WebhookSubscription{ID: 1}}
+
+	require.NoError(t, dispatcher.Enqueue(delivery))
+	assert.ErrorIs(t, dispatcher.Enqueue(delivery), ErrWebhookQueueFull)
+}
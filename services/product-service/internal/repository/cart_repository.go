@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type CartRepository interface {
@@ -17,9 +19,11 @@ type CartRepository interface {
 	GetCartByUserID(ctx context.Context, userID int64) (*domain.Cart, error)
 	GetCartBySessionID(ctx context.Context, sessionID string) (*domain.Cart, error)
 	GetCartBySessionOrUser(ctx context.Context, sessionID string, userID *int64) (*domain.Cart, error)
+	GetGuestCartBySession(ctx context.Context, sessionID string) (*domain.Cart, error)
 	UpdateCart(ctx context.Context, cart *domain.Cart) error
 	DeleteCart(ctx context.Context, id int64) error
 	GetOrCreateCart(ctx context.Context, userID *int64, sessionID string, currency string) (*domain.Cart, error)
+	DeleteExpiredCartForOwner(ctx context.Context, userID *int64, sessionID string) (bool, error)
 
 	// Cart Items
 	AddItemToCart(ctx context.Context, item *domain.CartItem) error
@@ -27,18 +31,19 @@ type CartRepository interface {
 	GetCartItemByProduct(ctx context.Context, cartID, productID int64, variantID *int64) (*domain.CartItem, error)
 	UpdateCartItem(ctx context.Context, id int64, item *domain.CartItem) error
 	DeleteCartItem(ctx context.Context, id int64) error
-	GetCartItems(ctx context.Context, cartID int64) ([]*domain.CartItem, error)
+	DeleteCartItemAtVersion(ctx context.Context, id int64, version int) error
+	GetCartItems(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartItem, int64, error)
+	GetCartItemDetails(ctx context.Context, cartID int64) ([]*domain.CartItemDetail, error)
 	ClearCartItems(ctx context.Context, cartID int64) error
 
 	// Cart Summary & Calculations
 	GetCartSummary(ctx context.Context, cartID int64) (*domain.CartSummary, error)
-	CalculateCartTotal(ctx context.Context, cartID int64) (float64, error)
 	GetCartItemCount(ctx context.Context, cartID int64) (int, error)
 
 	// Cart Coupons
 	ApplyCouponToCart(ctx context.Context, cartCoupon *domain.CartCoupon) error
 	RemoveCouponFromCart(ctx context.Context, cartID int64, couponCode string) error
-	GetCartCoupons(ctx context.Context, cartID int64) ([]*domain.CartCoupon, error)
+	GetCartCoupons(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartCoupon, int64, error)
 	GetCartCouponByCode(ctx context.Context, cartID int64, couponCode string) (*domain.CartCoupon, error)
 
 	// Cart Shipping
@@ -51,11 +56,17 @@ type CartRepository interface {
 	GetExpiredCarts(ctx context.Context, before time.Time) ([]*domain.Cart, error)
 	DeleteExpiredCarts(ctx context.Context, before time.Time) error
 	GetCartAnalytics(ctx context.Context) (*domain.CartAnalytics, error)
+	GetAbandonedCarts(ctx context.Context, inactiveSince time.Time) ([]*domain.Cart, error)
+	MarkCartReminderSent(ctx context.Context, cartID int64, sentAt time.Time) error
 	MergeCarts(ctx context.Context, sourceCartID, targetCartID int64) error
+	DeduplicateCartItems(ctx context.Context, cartID int64) (itemsMerged int, itemsRemoved int, err error)
+	ListActiveCartIDs(ctx context.Context, afterID int64, limit int) ([]int64, error)
+	RecalculateCartItemPrices(ctx context.Context, cartIDs []int64) (cartsUpdated int, itemsChanged int, err error)
 
 	// Wishlist Management
 	CreateWishlist(ctx context.Context, wishlist *domain.Wishlist) error
 	GetWishlistByID(ctx context.Context, id int64) (*domain.Wishlist, error)
+	GetPublicWishlistByShareToken(ctx context.Context, token string) (*domain.Wishlist, error)
 	GetWishlistsByUserID(ctx context.Context, userID int64, offset, limit int) ([]*domain.Wishlist, int64, error)
 	UpdateWishlist(ctx context.Context, id int64, wishlist *domain.Wishlist) error
 	DeleteWishlist(ctx context.Context, id int64) error
@@ -66,9 +77,12 @@ type CartRepository interface {
 	GetWishlistItems(ctx context.Context, wishlistID int64, offset, limit int) ([]*domain.WishlistItem, int64, error)
 	UpdateWishlistItem(ctx context.Context, id int64, item *domain.WishlistItem) error
 	DeleteWishlistItem(ctx context.Context, id int64) error
-	MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64) error
+	MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64, unitPrice float64) error
 }
 
+// cartSummaryItemLimit bounds the items embedded in a cart summary; totals still cover every item
+const cartSummaryItemLimit = 100
+
 type cartRepository struct {
 	db *sqlx.DB
 }
@@ -81,11 +95,12 @@ func NewCartRepository(db *sqlx.DB) CartRepository {
 
 // Cart Management
 
-// CreateCart creates a new cart
+// CreateCart creates a new cart. It returns ErrCartExists if the user, or the guest
+// session for carts without a user, already has a cart.
 func (r *cartRepository) CreateCart(ctx context.Context, cart *domain.Cart) error {
 	query := `
-		INSERT INTO carts (user_id, session_id, currency, created_at, updated_at, expires_at)
-		VALUES (:user_id, :session_id, :currency, :created_at, :updated_at, :expires_at)
+		INSERT INTO carts (user_id, session_id, currency, created_at, updated_at, expires_at, tax_exempt)
+		VALUES (:user_id, :session_id, :currency, :created_at, :updated_at, :expires_at, :tax_exempt)
 		RETURNING id`
 
 	cart.CreatedAt = time.Now()
@@ -93,6 +108,9 @@ func (r *cartRepository) CreateCart(ctx context.Context, cart *domain.Cart) erro
 
 	result, err := r.db.NamedQueryContext(ctx, query, cart)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrCartExists
+		}
 		return fmt.Errorf("failed to create cart: %w", err)
 	}
 	defer result.Close()
@@ -114,7 +132,7 @@ func (r *cartRepository) GetCartByID(ctx context.Context, id int64) (*domain.Car
 	err := r.db.GetContext(ctx, &cart, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("cart with ID %d not found", id)
+			return nil, notFoundf(ErrCartNotFound, "cart with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -130,7 +148,7 @@ func (r *cartRepository) GetCartByUserID(ctx context.Context, userID int64) (*do
 	err := r.db.GetContext(ctx, &cart, query, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("cart for user ID %d not found", userID)
+			return nil, notFoundf(ErrCartNotFound, "cart for user ID %d not found", userID)
 		}
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -146,7 +164,7 @@ func (r *cartRepository) GetCartBySessionID(ctx context.Context, sessionID strin
 	err := r.db.GetContext(ctx, &cart, query, sessionID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("cart for session ID %s not found", sessionID)
+			return nil, notFoundf(ErrCartNotFound, "cart for session ID %s not found", sessionID)
 		}
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -159,7 +177,8 @@ func (r *cartRepository) UpdateCart(ctx context.Context, cart *domain.Cart) erro
 	query := `
 		UPDATE carts SET
 			user_id = :user_id, session_id = :session_id, currency = :currency,
-			updated_at = :updated_at, expires_at = :expires_at
+			updated_at = :updated_at, expires_at = :expires_at, tax_exempt = :tax_exempt,
+			ship_country = :ship_country, ship_state = :ship_state, ship_postal_code = :ship_postal_code
 		WHERE id = :id`
 
 	cart.UpdatedAt = time.Now()
@@ -175,7 +194,7 @@ func (r *cartRepository) UpdateCart(ctx context.Context, cart *domain.Cart) erro
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("cart with ID %d not found", cart.ID)
+		return notFoundf(ErrCartNotFound, "cart with ID %d not found", cart.ID)
 	}
 
 	return nil
@@ -208,7 +227,7 @@ func (r *cartRepository) GetCartBySessionOrUser(ctx context.Context, sessionID s
 	err := r.db.GetContext(ctx, &cart, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, sql.ErrNoRows
+			return nil, notFoundf(ErrCartNotFound, "cart not found for this session or user")
 		}
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -216,6 +235,24 @@ func (r *cartRepository) GetCartBySessionOrUser(ctx context.Context, sessionID s
 	return &cart, nil
 }
 
+// GetGuestCartBySession retrieves the unexpired guest cart of a session. A user's cart
+// sharing the session is not returned.
+func (r *cartRepository) GetGuestCartBySession(ctx context.Context, sessionID string) (*domain.Cart, error) {
+	query := `SELECT * FROM carts
+			  WHERE session_id = $1 AND user_id IS NULL AND (expires_at IS NULL OR expires_at > NOW())`
+
+	var cart domain.Cart
+	err := r.db.GetContext(ctx, &cart, query, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrCartNotFound, "no guest cart for this session")
+		}
+		return nil, fmt.Errorf("failed to get guest cart: %w", err)
+	}
+
+	return &cart, nil
+}
+
 // DeleteCart deletes a cart
 func (r *cartRepository) DeleteCart(ctx context.Context, id int64) error {
 	// Start transaction to delete cart and all related data
@@ -225,22 +262,8 @@ func (r *cartRepository) DeleteCart(ctx context.Context, id int64) error {
 	}
 	defer tx.Rollback()
 
-	// Delete cart items
-	_, err = tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete cart items: %w", err)
-	}
-
-	// Delete cart coupons
-	_, err = tx.ExecContext(ctx, "DELETE FROM cart_coupons WHERE cart_id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete cart coupons: %w", err)
-	}
-
-	// Delete cart shipping
-	_, err = tx.ExecContext(ctx, "DELETE FROM cart_shipping WHERE cart_id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete cart shipping: %w", err)
+	if err := emptyCart(ctx, tx, id); err != nil {
+		return err
 	}
 
 	// Delete cart
@@ -255,7 +278,7 @@ func (r *cartRepository) DeleteCart(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("cart with ID %d not found", id)
+		return notFoundf(ErrCartNotFound, "cart with ID %d not found", id)
 	}
 
 	// Commit transaction
@@ -266,6 +289,29 @@ func (r *cartRepository) DeleteCart(ctx context.Context, id int64) error {
 	return nil
 }
 
+// emptyCart removes a cart's items, coupons and shipping inside tx, leaving the cart
+func emptyCart(ctx context.Context, tx *sqlx.Tx, cartID int64) error {
+	// Delete cart items
+	_, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1", cartID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart items: %w", err)
+	}
+
+	// Delete cart coupons
+	_, err = tx.ExecContext(ctx, "DELETE FROM cart_coupons WHERE cart_id = $1", cartID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart coupons: %w", err)
+	}
+
+	// Delete cart shipping
+	_, err = tx.ExecContext(ctx, "DELETE FROM cart_shipping WHERE cart_id = $1", cartID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart shipping: %w", err)
+	}
+
+	return nil
+}
+
 // GetOrCreateCart gets an existing cart or creates a new one
 func (r *cartRepository) GetOrCreateCart(ctx context.Context, userID *int64, sessionID string, currency string) (*domain.Cart, error) {
 	// Try to get existing cart
@@ -294,6 +340,13 @@ func (r *cartRepository) GetOrCreateCart(ctx context.Context, userID *int64, ses
 	}
 
 	err = r.CreateCart(ctx, newCart)
+	if err == ErrCartExists {
+		// A concurrent request created the cart first; return that one
+		if userID != nil {
+			return r.GetCartByUserID(ctx, *userID)
+		}
+		return r.GetCartBySessionID(ctx, sessionID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cart: %w", err)
 	}
@@ -301,29 +354,69 @@ func (r *cartRepository) GetOrCreateCart(ctx context.Context, userID *int64, ses
 	return newCart, nil
 }
 
+// DeleteExpiredCartForOwner deletes the expired cart of a user, or of a guest session
+// when userID is nil, so a new cart can take its place. It reports whether a cart was deleted.
+func (r *cartRepository) DeleteExpiredCartForOwner(ctx context.Context, userID *int64, sessionID string) (bool, error) {
+	var query string
+	var args []interface{}
+
+	if userID != nil {
+		query = `DELETE FROM carts WHERE user_id = $1 AND expires_at <= NOW()`
+		args = []interface{}{*userID}
+	} else {
+		query = `DELETE FROM carts WHERE user_id IS NULL AND session_id = $1 AND expires_at <= NOW()`
+		args = []interface{}{sessionID}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete expired cart: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
 // Cart Items
 
 // AddItemToCart adds an item to the cart
 func (r *cartRepository) AddItemToCart(ctx context.Context, item *domain.CartItem) error {
 	query := `
 		INSERT INTO cart_items (cart_id, product_id, product_variant_id, quantity, unit_price, total_price, created_at, updated_at)
-		VALUES (:cart_id, :product_id, :product_variant_id, :quantity, :unit_price, :total_price, :created_at, :updated_at)`
+		VALUES (:cart_id, :product_id, :product_variant_id, :quantity, :unit_price, :total_price, :created_at, :updated_at)
+		RETURNING id`
 
 	item.CreatedAt = time.Now()
 	item.UpdatedAt = time.Now()
 	item.TotalPrice = item.UnitPrice * float64(item.Quantity)
 
-	result, err := r.db.NamedExecContext(ctx, query, item)
+	rows, err := r.db.NamedQueryContext(ctx, query, item)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("cart item for product %d", item.ProductID)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to add item to cart: %w", err)
 	}
+	defer rows.Close()
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get item ID: %w", err)
+	if rows.Next() {
+		if err := rows.Scan(&item.ID); err != nil {
+			return fmt.Errorf("failed to get item ID: %w", err)
+		}
+		item.Version = 1
+	}
+	// The insert can still fail, e.g. on the unique key, while its row is being read
+	if err := rows.Err(); err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("cart item for product %d", item.ProductID)); existsErr != nil {
+			return existsErr
+		}
+		return fmt.Errorf("failed to add item to cart: %w", err)
 	}
 
-	item.ID = id
 	return nil
 }
 
@@ -335,7 +428,7 @@ func (r *cartRepository) GetCartItemByID(ctx context.Context, id int64) (*domain
 	err := r.db.GetContext(ctx, &item, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("cart item with ID %d not found", id)
+			return nil, notFoundf(ErrCartItemNotFound, "cart item with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get cart item: %w", err)
 	}
@@ -360,7 +453,7 @@ func (r *cartRepository) GetCartItemByProduct(ctx context.Context, cartID, produ
 	err := r.db.GetContext(ctx, &item, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("cart item not found")
+			return nil, notFoundf(ErrCartItemNotFound, "cart item not found")
 		}
 		return nil, fmt.Errorf("failed to get cart item: %w", err)
 	}
@@ -368,13 +461,15 @@ func (r *cartRepository) GetCartItemByProduct(ctx context.Context, cartID, produ
 	return &item, nil
 }
 
-// UpdateCartItem updates an existing cart item
+// UpdateCartItem updates an existing cart item. item.Version must be the version the
+// caller read; ErrStaleCartItem is returned if the item has changed since. On success
+// item.Version holds the new version.
 func (r *cartRepository) UpdateCartItem(ctx context.Context, id int64, item *domain.CartItem) error {
 	query := `
 		UPDATE cart_items SET
 			quantity = :quantity, unit_price = :unit_price, total_price = :total_price,
-			updated_at = :updated_at
-		WHERE id = :id`
+			updated_at = :updated_at, version = version + 1
+		WHERE id = :id AND version = :version`
 
 	item.UpdatedAt = time.Now()
 	item.TotalPrice = item.UnitPrice * float64(item.Quantity)
@@ -391,9 +486,17 @@ func (r *cartRepository) UpdateCartItem(ctx context.Context, id int64, item *dom
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("cart item with ID %d not found", id)
+		var exists bool
+		if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM cart_items WHERE id = $1)`, id); err != nil {
+			return fmt.Errorf("failed to check cart item: %w", err)
+		}
+		if exists {
+			return ErrStaleCartItem
+		}
+		return notFoundf(ErrCartItemNotFound, "cart item with ID %d not found", id)
 	}
 
+	item.Version++
 	return nil
 }
 
@@ -412,23 +515,74 @@ func (r *cartRepository) DeleteCartItem(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("cart item with ID %d not found", id)
+		return notFoundf(ErrCartItemNotFound, "cart item with ID %d not found", id)
 	}
 
 	return nil
 }
 
-// GetCartItems retrieves all items in a cart
-func (r *cartRepository) GetCartItems(ctx context.Context, cartID int64) ([]*domain.CartItem, error) {
-	query := `SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY created_at ASC`
+// DeleteCartItemAtVersion deletes a cart item only if it is still at version;
+// ErrStaleCartItem is returned if the item has changed since
+func (r *cartRepository) DeleteCartItemAtVersion(ctx context.Context, id int64, version int) error {
+	query := `DELETE FROM cart_items WHERE id = $1 AND version = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		var exists bool
+		if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM cart_items WHERE id = $1)`, id); err != nil {
+			return fmt.Errorf("failed to check cart item: %w", err)
+		}
+		if exists {
+			return ErrStaleCartItem
+		}
+		return notFoundf(ErrCartItemNotFound, "cart item with ID %d not found", id)
+	}
+
+	return nil
+}
 
+// GetCartItems retrieves a page of items in a cart with the total item rows.
+// A limit of zero or less returns every item.
+func (r *cartRepository) GetCartItems(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartItem, int64, error) {
 	var items []*domain.CartItem
-	err := r.db.SelectContext(ctx, &items, query, cartID)
+
+	if limit <= 0 {
+		query := `SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY created_at ASC, id ASC`
+
+		err := r.db.SelectContext(ctx, &items, query, cartID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get cart items: %w", err)
+		}
+
+		return items, int64(len(items)), nil
+	}
+
+	// Count query
+	countQuery := `SELECT COUNT(*) FROM cart_items WHERE cart_id = $1`
+	var total int64
+	err := r.db.GetContext(ctx, &total, countQuery, cartID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cart items: %w", err)
+		return nil, 0, fmt.Errorf("failed to count cart items: %w", err)
 	}
 
-	return items, nil
+	// List query
+	query := `SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY created_at ASC, id ASC LIMIT $2 OFFSET $3`
+
+	err = r.db.SelectContext(ctx, &items, query, cartID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	return items, total, nil
 }
 
 // ClearCartItems removes all items from a cart
@@ -443,86 +597,110 @@ func (r *cartRepository) ClearCartItems(ctx context.Context, cartID int64) error
 	return nil
 }
 
+// GetCartItemDetails retrieves a cart's items joined with their product names and SKUs,
+// in the same order and bounded like the items of a cart summary
+func (r *cartRepository) GetCartItemDetails(ctx context.Context, cartID int64) ([]*domain.CartItemDetail, error) {
+	query := `
+		SELECT ci.*, p.name AS product_name, COALESCE(pv.sku, p.sku) AS sku
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		LEFT JOIN product_variants pv ON pv.id = ci.product_variant_id
+		WHERE ci.cart_id = $1
+		ORDER BY ci.created_at ASC, ci.id ASC
+		LIMIT $2`
+
+	var items []*domain.CartItemDetail
+	if err := r.db.SelectContext(ctx, &items, query, cartID, cartSummaryItemLimit); err != nil {
+		return nil, fmt.Errorf("failed to get cart item details: %w", err)
+	}
+
+	return items, nil
+}
+
 // Cart Summary & Calculations
 
-// GetCartSummary retrieves a complete cart summary
+// GetCartSummary retrieves the amounts that make up a cart summary in two queries: one
+// aggregate row and the item list. Tax and the grand total depend on pricing policy and
+// are filled in by the service.
 func (r *cartRepository) GetCartSummary(ctx context.Context, cartID int64) (*domain.CartSummary, error) {
-	// Get cart
-	cart, err := r.GetCartByID(ctx, cartID)
+	// Cart details, item totals and shipping come back in one row. Shipping is read in
+	// subqueries so it is not multiplied by the number of items.
+	var row struct {
+		Currency         string  `db:"currency"`
+		TaxExempt        bool    `db:"tax_exempt"`
+		ShipCountry      string  `db:"ship_country"`
+		ShipState        string  `db:"ship_state"`
+		ShipPostalCode   string  `db:"ship_postal_code"`
+		Subtotal         float64 `db:"subtotal"`
+		TaxableSubtotal  float64 `db:"taxable_subtotal"`
+		ItemCount        int     `db:"item_count"`
+		ShippingAmount   float64 `db:"shipping_amount"`
+		ShippingMethodID int64   `db:"shipping_method_id"`
+	}
+	err := r.db.GetContext(ctx, &row, `
+		SELECT c.currency, c.tax_exempt, c.ship_country, c.ship_state, c.ship_postal_code,
+			totals.subtotal, totals.taxable_subtotal, totals.item_count,
+			COALESCE((SELECT cs.shipping_amount FROM cart_shipping cs WHERE cs.cart_id = c.id), 0) AS shipping_amount,
+			COALESCE((SELECT cs.shipping_method_id FROM cart_shipping cs WHERE cs.cart_id = c.id), 0) AS shipping_method_id
+		FROM carts c
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(ci.total_price), 0) AS subtotal,
+				COALESCE(SUM(ci.total_price) FILTER (WHERE p.taxable), 0) AS taxable_subtotal,
+				COALESCE(SUM(ci.quantity), 0) AS item_count
+			FROM cart_items ci
+			JOIN products p ON p.id = ci.product_id
+			WHERE ci.cart_id = c.id
+		) totals
+		WHERE c.id = $1`, cartID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cart: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrCartNotFound, "cart with ID %d not found", cartID)
+		}
+		return nil, fmt.Errorf("failed to calculate cart totals: %w", err)
 	}
 
-	// Get cart items
-	items, err := r.GetCartItems(ctx, cartID)
+	// The embedded item list is bounded; the totals above cover every item
+	var items []domain.CartItem
+	err = r.db.SelectContext(ctx, &items,
+		`SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY created_at ASC, id ASC LIMIT $2`, cartID, cartSummaryItemLimit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart items: %w", err)
 	}
 
-	// Calculate totals
-	var subtotal float64
-	var itemCount int
-
-	for _, item := range items {
-		subtotal += item.TotalPrice
-		itemCount += item.Quantity
-	}
-
-	// Get applied coupons
-	coupons, err := r.GetCartCoupons(ctx, cartID)
+	// Coupon discounts are not summed here: the service prices the applied coupons
+	// against the cart as it is now, so a discount never outlives the cart it was
+	// priced for
+	var coupons []domain.Coupon
+	err = r.db.SelectContext(ctx, &coupons, `
+		SELECT co.* FROM cart_coupons cc
+		JOIN coupons co ON co.code = cc.coupon_code
+		WHERE cc.cart_id = $1
+		ORDER BY cc.created_at ASC, cc.id ASC`, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart coupons: %w", err)
 	}
 
-	var discountAmount float64
-	for _, coupon := range coupons {
-		discountAmount += coupon.DiscountAmount
-	}
-
-	// Get shipping
-	shipping, err := r.GetCartShipping(ctx, cartID)
-	var shippingAmount float64
-	if err == nil && shipping != nil {
-		shippingAmount = shipping.ShippingAmount
-	}
-
-	// Calculate tax (simplified - in real app, this would be more complex)
-	taxAmount := subtotal * 0.1 // 10% tax rate
-
-	// Calculate total
-	totalAmount := subtotal + taxAmount + shippingAmount - discountAmount
-
-	// Convert []*domain.CartItem to []domain.CartItem
-	cartItems := make([]domain.CartItem, len(items))
-	for i, item := range items {
-		cartItems[i] = *item
-	}
-
 	summary := &domain.CartSummary{
-		CartID:         cartID,
-		ItemCount:      itemCount,
-		Subtotal:       subtotal,
-		TaxAmount:      taxAmount,
-		ShippingAmount: shippingAmount,
-		DiscountAmount: discountAmount,
-		TotalAmount:    totalAmount,
-		Currency:       cart.Currency,
-		Items:          cartItems,
+		CartID:           cartID,
+		ItemCount:        row.ItemCount,
+		Subtotal:         row.Subtotal,
+		TaxableSubtotal:  row.TaxableSubtotal,
+		TaxExempt:        row.TaxExempt,
+		ShippingAmount:   row.ShippingAmount,
+		ShippingMethodID: row.ShippingMethodID,
+		Currency:         row.Currency,
+		Items:            items,
+		Coupons:          coupons,
+		Destination: domain.ShippingDestination{
+			Country:    row.ShipCountry,
+			State:      row.ShipState,
+			PostalCode: row.ShipPostalCode,
+		},
 	}
 
 	return summary, nil
 }
 
-// CalculateCartTotal calculates the total amount for a cart
-func (r *cartRepository) CalculateCartTotal(ctx context.Context, cartID int64) (float64, error) {
-	summary, err := r.GetCartSummary(ctx, cartID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get cart summary: %w", err)
-	}
-
-	return summary.TotalAmount, nil
-}
-
 // GetCartItemCount gets the total number of items in a cart
 func (r *cartRepository) GetCartItemCount(ctx context.Context, cartID int64) (int, error) {
 	query := `SELECT COALESCE(SUM(quantity), 0) FROM cart_items WHERE cart_id = $1`
@@ -548,6 +726,9 @@ func (r *cartRepository) ApplyCouponToCart(ctx context.Context, cartCoupon *doma
 
 	_, err := r.db.NamedExecContext(ctx, query, cartCoupon)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("coupon %s on cart %d", cartCoupon.CouponCode, cartCoupon.CartID)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to apply coupon to cart: %w", err)
 	}
 
@@ -569,23 +750,45 @@ func (r *cartRepository) RemoveCouponFromCart(ctx context.Context, cartID int64,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("coupon %s not found in cart", couponCode)
+		return notFoundf(ErrCartCouponNotFound, "coupon %s not found in cart", couponCode)
 	}
 
 	return nil
 }
 
-// GetCartCoupons retrieves all coupons applied to a cart
-func (r *cartRepository) GetCartCoupons(ctx context.Context, cartID int64) ([]*domain.CartCoupon, error) {
-	query := `SELECT * FROM cart_coupons WHERE cart_id = $1 ORDER BY created_at ASC`
-
+// GetCartCoupons retrieves a page of coupons applied to a cart with the total count.
+// A limit of zero or less returns every coupon.
+func (r *cartRepository) GetCartCoupons(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartCoupon, int64, error) {
 	var coupons []*domain.CartCoupon
-	err := r.db.SelectContext(ctx, &coupons, query, cartID)
+
+	if limit <= 0 {
+		query := `SELECT * FROM cart_coupons WHERE cart_id = $1 ORDER BY created_at ASC, id ASC`
+
+		err := r.db.SelectContext(ctx, &coupons, query, cartID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get cart coupons: %w", err)
+		}
+
+		return coupons, int64(len(coupons)), nil
+	}
+
+	// Count query
+	countQuery := `SELECT COUNT(*) FROM cart_coupons WHERE cart_id = $1`
+	var total int64
+	err := r.db.GetContext(ctx, &total, countQuery, cartID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cart coupons: %w", err)
+		return nil, 0, fmt.Errorf("failed to count cart coupons: %w", err)
+	}
+
+	// List query
+	query := `SELECT * FROM cart_coupons WHERE cart_id = $1 ORDER BY created_at ASC, id ASC LIMIT $2 OFFSET $3`
+
+	err = r.db.SelectContext(ctx, &coupons, query, cartID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get cart coupons: %w", err)
 	}
 
-	return coupons, nil
+	return coupons, total, nil
 }
 
 // GetCartCouponByCode retrieves a specific coupon from a cart
@@ -596,7 +799,7 @@ func (r *cartRepository) GetCartCouponByCode(ctx context.Context, cartID int64,
 	err := r.db.GetContext(ctx, &coupon, query, cartID, couponCode)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("coupon %s not found in cart", couponCode)
+			return nil, notFoundf(ErrCartCouponNotFound, "coupon %s not found in cart", couponCode)
 		}
 		return nil, fmt.Errorf("failed to get cart coupon: %w", err)
 	}
@@ -643,7 +846,7 @@ func (r *cartRepository) UpdateCartShipping(ctx context.Context, cartID int64, s
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("shipping not found for cart ID %d", cartID)
+		return notFoundf(ErrCartShippingNotFound, "shipping not found for cart ID %d", cartID)
 	}
 
 	return nil
@@ -705,13 +908,109 @@ func (r *cartRepository) DeleteExpiredCarts(ctx context.Context, before time.Tim
 		err = r.DeleteCart(ctx, cart.ID)
 		if err != nil {
 			// Log error but continue with other carts
-			fmt.Printf("Warning: failed to delete expired cart %d: %v\n", cart.ID, err)
+			logger.FromContext(ctx).Warn("failed to delete expired cart", "cart_id", cart.ID, "error", err)
 		}
 	}
 
 	return nil
 }
 
+// GetAbandonedCarts retrieves user carts with items that have been inactive since the cutoff
+// and have not been reminded since their last activity
+func (r *cartRepository) GetAbandonedCarts(ctx context.Context, inactiveSince time.Time) ([]*domain.Cart, error) {
+	query := `
+		SELECT c.*
+		FROM carts c
+		JOIN cart_items ci ON ci.cart_id = c.id
+		WHERE c.user_id IS NOT NULL
+			AND (c.expires_at IS NULL OR c.expires_at > NOW())
+		GROUP BY c.id
+		HAVING GREATEST(c.updated_at, MAX(ci.updated_at)) < $1
+			AND (c.last_reminder_at IS NULL OR c.last_reminder_at < GREATEST(c.updated_at, MAX(ci.updated_at)))
+		ORDER BY c.id`
+
+	var carts []*domain.Cart
+	err := r.db.SelectContext(ctx, &carts, query, inactiveSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get abandoned carts: %w", err)
+	}
+
+	return carts, nil
+}
+
+// ListActiveCartIDs returns up to limit IDs of non-expired carts with an ID greater than afterID,
+// in ascending order so callers can walk all carts in batches
+func (r *cartRepository) ListActiveCartIDs(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	query := `
+		SELECT id FROM carts
+		WHERE (expires_at IS NULL OR expires_at > NOW()) AND id > $1
+		ORDER BY id ASC
+		LIMIT $2`
+
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active carts: %w", err)
+	}
+
+	return ids, nil
+}
+
+// RecalculateCartItemPrices reprices the items of the given carts from current product and variant
+// prices in a single statement. Only items whose unit price changed are written.
+func (r *cartRepository) RecalculateCartItemPrices(ctx context.Context, cartIDs []int64) (int, int, error) {
+	query := `
+		WITH current_prices AS (
+			SELECT ci.id,
+				CASE WHEN ci.product_variant_id IS NOT NULL THEN pv.price ELSE p.price END AS price
+			FROM cart_items ci
+			JOIN products p ON p.id = ci.product_id
+			LEFT JOIN product_variants pv ON pv.id = ci.product_variant_id
+			WHERE ci.cart_id = ANY($1)
+		), changed AS (
+			UPDATE cart_items ci
+			SET unit_price = cp.price, total_price = cp.price * ci.quantity, updated_at = NOW(), version = ci.version + 1
+			FROM current_prices cp
+			WHERE ci.id = cp.id AND cp.price IS NOT NULL AND ci.unit_price <> cp.price
+			RETURNING ci.cart_id
+		)
+		SELECT COUNT(DISTINCT cart_id) AS carts_updated, COUNT(*) AS items_changed FROM changed`
+
+	var result struct {
+		CartsUpdated int `db:"carts_updated"`
+		ItemsChanged int `db:"items_changed"`
+	}
+	err := r.db.GetContext(ctx, &result, query, pq.Array(cartIDs))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to recalculate cart item prices: %w", err)
+	}
+
+	return result.CartsUpdated, result.ItemsChanged, nil
+}
+
+// MarkCartReminderSent records when an abandoned cart reminder was sent. The carts
+// trigger leaves updated_at alone for this update, so the reminder does not count as
+// activity that would make the cart due for another reminder.
+func (r *cartRepository) MarkCartReminderSent(ctx context.Context, cartID int64, sentAt time.Time) error {
+	query := `UPDATE carts SET last_reminder_at = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, sentAt, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to mark cart reminder sent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundf(ErrCartNotFound, "cart with ID %d not found", cartID)
+	}
+
+	return nil
+}
+
 // GetCartAnalytics retrieves analytics data for carts
 func (r *cartRepository) GetCartAnalytics(ctx context.Context) (*domain.CartAnalytics, error) {
 	// This would be implemented with more complex queries in a real application
@@ -740,7 +1039,7 @@ func (r *cartRepository) MergeCarts(ctx context.Context, sourceCartID, targetCar
 	defer tx.Rollback()
 
 	// Get source cart items
-	sourceItems, err := r.GetCartItems(ctx, sourceCartID)
+	sourceItems, _, err := r.GetCartItems(ctx, sourceCartID, 0, 0)
 	if err != nil {
 		return fmt.Errorf("failed to get source cart items: %w", err)
 	}
@@ -756,7 +1055,8 @@ func (r *cartRepository) MergeCarts(ctx context.Context, sourceCartID, targetCar
 			existingItem.UpdatedAt = time.Now()
 
 			_, err = tx.NamedExecContext(ctx, `
-				UPDATE cart_items SET quantity = :quantity, total_price = :total_price, updated_at = :updated_at
+				UPDATE cart_items SET quantity = :quantity, total_price = :total_price, updated_at = :updated_at,
+					version = version + 1
 				WHERE id = :id`, existingItem)
 			if err != nil {
 				return fmt.Errorf("failed to update existing cart item: %w", err)
@@ -791,13 +1091,85 @@ func (r *cartRepository) MergeCarts(ctx context.Context, sourceCartID, targetCar
 	return nil
 }
 
+// cartItemKey identifies the product and variant a cart item refers to
+type cartItemKey struct {
+	productID  int64
+	variantID  int64
+	hasVariant bool
+}
+
+// DeduplicateCartItems collapses cart items sharing a product and variant into the oldest
+// of them, summing quantities and recomputing its total at the kept unit price. It reports
+// how many items absorbed duplicates and how many duplicate rows were removed.
+func (r *cartRepository) DeduplicateCartItems(ctx context.Context, cartID int64) (int, int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var items []*domain.CartItem
+	err = tx.SelectContext(ctx, &items, `SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY id FOR UPDATE`, cartID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	kept := make(map[cartItemKey]*domain.CartItem)
+	merged := make(map[int64]bool)
+	var keepers []*domain.CartItem
+	var duplicateIDs []int64
+	for _, item := range items {
+		key := cartItemKey{productID: item.ProductID}
+		if item.ProductVariantID != nil {
+			key.variantID, key.hasVariant = *item.ProductVariantID, true
+		}
+
+		keeper, ok := kept[key]
+		if !ok {
+			kept[key] = item
+			continue
+		}
+		if !merged[keeper.ID] {
+			merged[keeper.ID] = true
+			keepers = append(keepers, keeper)
+		}
+		keeper.Quantity += item.Quantity
+		duplicateIDs = append(duplicateIDs, item.ID)
+	}
+
+	if len(duplicateIDs) == 0 {
+		return 0, 0, nil
+	}
+
+	now := time.Now()
+	for _, keeper := range keepers {
+		keeper.TotalPrice = keeper.UnitPrice * float64(keeper.Quantity)
+		_, err = tx.ExecContext(ctx, `UPDATE cart_items SET quantity = $1, total_price = $2, updated_at = $3, version = version + 1 WHERE id = $4`,
+			keeper.Quantity, keeper.TotalPrice, now, keeper.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to update cart item %d: %w", keeper.ID, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE id = ANY($1)`, pq.Array(duplicateIDs))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete duplicate cart items: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(keepers), len(duplicateIDs), nil
+}
+
 // Wishlist Management
 
 // CreateWishlist creates a new wishlist
 func (r *cartRepository) CreateWishlist(ctx context.Context, wishlist *domain.Wishlist) error {
 	query := `
-		INSERT INTO wishlists (user_id, name, is_public, created_at, updated_at)
-		VALUES (:user_id, :name, :is_public, :created_at, :updated_at)`
+		INSERT INTO wishlists (user_id, name, is_public, share_token, created_at, updated_at)
+		VALUES (:user_id, :name, :is_public, :share_token, :created_at, :updated_at)`
 
 	wishlist.CreatedAt = time.Now()
 	wishlist.UpdatedAt = time.Now()
@@ -824,7 +1196,7 @@ func (r *cartRepository) GetWishlistByID(ctx context.Context, id int64) (*domain
 	err := r.db.GetContext(ctx, &wishlist, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("wishlist with ID %d not found", id)
+			return nil, notFoundf(ErrWishlistNotFound, "wishlist with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get wishlist: %w", err)
 	}
@@ -832,6 +1204,23 @@ func (r *cartRepository) GetWishlistByID(ctx context.Context, id int64) (*domain
 	return &wishlist, nil
 }
 
+// GetPublicWishlistByShareToken retrieves a public wishlist by its share token. A
+// wishlist that has been made private is not found.
+func (r *cartRepository) GetPublicWishlistByShareToken(ctx context.Context, token string) (*domain.Wishlist, error) {
+	query := `SELECT * FROM wishlists WHERE share_token = $1 AND is_public = true`
+
+	var wishlist domain.Wishlist
+	err := r.db.GetContext(ctx, &wishlist, query, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrWishlistNotFound, "shared wishlist not found")
+		}
+		return nil, fmt.Errorf("failed to get shared wishlist: %w", err)
+	}
+
+	return &wishlist, nil
+}
+
 // GetWishlistsByUserID retrieves wishlists for a user
 func (r *cartRepository) GetWishlistsByUserID(ctx context.Context, userID int64, offset, limit int) ([]*domain.Wishlist, int64, error) {
 	// Count query
@@ -858,7 +1247,7 @@ func (r *cartRepository) GetWishlistsByUserID(ctx context.Context, userID int64,
 func (r *cartRepository) UpdateWishlist(ctx context.Context, id int64, wishlist *domain.Wishlist) error {
 	query := `
 		UPDATE wishlists SET
-			name = :name, is_public = :is_public, updated_at = :updated_at
+			name = :name, is_public = :is_public, share_token = :share_token, updated_at = :updated_at
 		WHERE id = :id`
 
 	wishlist.UpdatedAt = time.Now()
@@ -875,7 +1264,7 @@ func (r *cartRepository) UpdateWishlist(ctx context.Context, id int64, wishlist
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("wishlist with ID %d not found", id)
+		return notFoundf(ErrWishlistNotFound, "wishlist with ID %d not found", id)
 	}
 
 	return nil
@@ -908,7 +1297,7 @@ func (r *cartRepository) DeleteWishlist(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("wishlist with ID %d not found", id)
+		return notFoundf(ErrWishlistNotFound, "wishlist with ID %d not found", id)
 	}
 
 	// Commit transaction
@@ -931,6 +1320,9 @@ func (r *cartRepository) AddItemToWishlist(ctx context.Context, item *domain.Wis
 
 	result, err := r.db.NamedExecContext(ctx, query, item)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("wishlist item for product %d", item.ProductID)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to add item to wishlist: %w", err)
 	}
 
@@ -951,7 +1343,7 @@ func (r *cartRepository) GetWishlistItemByID(ctx context.Context, id int64) (*do
 	err := r.db.GetContext(ctx, &item, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("wishlist item with ID %d not found", id)
+			return nil, notFoundf(ErrWishlistItemNotFound, "wishlist item with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
 	}
@@ -998,7 +1390,7 @@ func (r *cartRepository) UpdateWishlistItem(ctx context.Context, id int64, item
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("wishlist item with ID %d not found", id)
+		return notFoundf(ErrWishlistItemNotFound, "wishlist item with ID %d not found", id)
 	}
 
 	return nil
@@ -1019,14 +1411,15 @@ func (r *cartRepository) DeleteWishlistItem(ctx context.Context, id int64) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("wishlist item with ID %d not found", id)
+		return notFoundf(ErrWishlistItemNotFound, "wishlist item with ID %d not found", id)
 	}
 
 	return nil
 }
 
-// MoveItemToCart moves an item from wishlist to cart
-func (r *cartRepository) MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64) error {
+// MoveItemToCart moves an item from a wishlist into a cart at unitPrice. One unit is added
+// to the cart's existing line for the product, or a new line is created for it.
+func (r *cartRepository) MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64, unitPrice float64) error {
 	// Start transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -1035,27 +1428,57 @@ func (r *cartRepository) MoveItemToCart(ctx context.Context, wishlistItemID, car
 	defer tx.Rollback()
 
 	// Get wishlist item
-	wishlistItem, err := r.GetWishlistItemByID(ctx, wishlistItemID)
+	var wishlistItem domain.WishlistItem
+	err = tx.GetContext(ctx, &wishlistItem, `SELECT * FROM wishlist_items WHERE id = $1 FOR UPDATE`, wishlistItemID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundf(ErrWishlistItemNotFound, "wishlist item with ID %d not found", wishlistItemID)
+		}
 		return fmt.Errorf("failed to get wishlist item: %w", err)
 	}
 
-	// Create cart item (you would need to get the current price from product)
-	cartItem := &domain.CartItem{
-		CartID:           cartID,
-		ProductID:        wishlistItem.ProductID,
-		ProductVariantID: wishlistItem.ProductVariantID,
-		Quantity:         1, // Default quantity
-		UnitPrice:        0, // This should be fetched from product
-		TotalPrice:       0,
+	now := time.Now()
+
+	// Merge into the existing line for the product, repricing it at the current price
+	result, err := tx.ExecContext(ctx, `
+		UPDATE cart_items SET
+			quantity = quantity + 1, unit_price = $1, total_price = $1 * (quantity + 1),
+			updated_at = $2, version = version + 1
+		WHERE id = (
+			SELECT id FROM cart_items
+			WHERE cart_id = $3 AND product_id = $4 AND product_variant_id IS NOT DISTINCT FROM $5
+			ORDER BY id LIMIT 1
+		)`, unitPrice, now, cartID, wishlistItem.ProductID, wishlistItem.ProductVariantID)
+	if err != nil {
+		return fmt.Errorf("failed to update cart item: %w", err)
 	}
 
-	// Add to cart
-	_, err = tx.NamedExecContext(ctx, `
-		INSERT INTO cart_items (cart_id, product_id, product_variant_id, quantity, unit_price, total_price, created_at, updated_at)
-		VALUES (:cart_id, :product_id, :product_variant_id, :quantity, :unit_price, :total_price, :created_at, :updated_at)`, cartItem)
+	merged, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to add item to cart: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if merged == 0 {
+		cartItem := &domain.CartItem{
+			CartID:           cartID,
+			ProductID:        wishlistItem.ProductID,
+			ProductVariantID: wishlistItem.ProductVariantID,
+			Quantity:         1,
+			UnitPrice:        unitPrice,
+			TotalPrice:       unitPrice,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO cart_items (cart_id, product_id, product_variant_id, quantity, unit_price, total_price, created_at, updated_at)
+			VALUES (:cart_id, :product_id, :product_variant_id, :quantity, :unit_price, :total_price, :created_at, :updated_at)`, cartItem)
+		if err != nil {
+			if existsErr := classifyUniqueViolation(err, fmt.Sprintf("cart item for product %d", cartItem.ProductID)); existsErr != nil {
+				return existsErr
+			}
+			return fmt.Errorf("failed to add item to cart: %w", err)
+		}
 	}
 
 	// Remove from wishlist
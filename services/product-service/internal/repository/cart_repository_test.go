@@ -0,0 +1,805 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartRepository_GetAbandonedCarts(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := cutoff.Add(-48 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "session_id", "currency", "created_at", "updated_at", "expires_at", "last_reminder_at"}).
+		AddRow(1, 42, "session-1", "USD", updatedAt, updatedAt, nil, nil)
+
+	// Only user carts with items, not expired, idle past the cutoff and not yet reminded
+	mock.ExpectQuery(`SELECT c\.\* FROM carts c JOIN cart_items ci ON ci\.cart_id = c\.id ` +
+		`WHERE c\.user_id IS NOT NULL AND \(c\.expires_at IS NULL OR c\.expires_at > NOW\(\)\) ` +
+		`GROUP BY c\.id ` +
+		`HAVING GREATEST\(c\.updated_at, MAX\(ci\.updated_at\)\) < \$1 ` +
+		`AND \(c\.last_reminder_at IS NULL OR c\.last_reminder_at < GREATEST\(c\.updated_at, MAX\(ci\.updated_at\)\)\)`).
+		WithArgs(cutoff).
+		WillReturnRows(rows)
+
+	carts, err := repo.GetAbandonedCarts(context.Background(), cutoff)
+
+	require.NoError(t, err)
+	require.Len(t, carts, 1)
+	assert.Equal(t, int64(1), carts[0].ID)
+	require.NotNil(t, carts[0].UserID)
+	assert.Equal(t, int64(42), *carts[0].UserID)
+	assert.Nil(t, carts[0].LastReminderAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_MarkCartReminderSent(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		sentAt := time.Now()
+
+		mock.ExpectExec(`UPDATE carts SET last_reminder_at = \$1 WHERE id = \$2`).
+			WithArgs(sentAt, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkCartReminderSent(context.Background(), 1, sentAt)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cart not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		sentAt := time.Now()
+
+		mock.ExpectExec(`UPDATE carts SET last_reminder_at = \$1 WHERE id = \$2`).
+			WithArgs(sentAt, int64(99)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.MarkCartReminderSent(context.Background(), 99, sentAt)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestCartRepository_GetCartItems(t *testing.T) {
+	itemColumns := []string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price", "total_price", "created_at", "updated_at"}
+
+	t.Run("returns requested page with total", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM cart_items WHERE cart_id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+		mock.ExpectQuery(`SELECT \* FROM cart_items WHERE cart_id = \$1 ORDER BY created_at ASC, id ASC LIMIT \$2 OFFSET \$3`).
+			WithArgs(int64(1), 2, 2).
+			WillReturnRows(sqlmock.NewRows(itemColumns).
+				AddRow(3, 1, 10, nil, 1, 5.0, 5.0, now, now).
+				AddRow(4, 1, 11, nil, 2, 7.5, 15.0, now, now))
+
+		items, total, err := repo.GetCartItems(context.Background(), 1, 2, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, items, 2)
+		assert.Equal(t, int64(3), items[0].ID)
+		assert.Equal(t, int64(4), items[1].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("non-positive limit returns every item", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT \* FROM cart_items WHERE cart_id = \$1 ORDER BY created_at ASC, id ASC$`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(itemColumns).
+				AddRow(1, 1, 10, nil, 1, 5.0, 5.0, now, now).
+				AddRow(2, 1, 11, nil, 1, 5.0, 5.0, now, now).
+				AddRow(3, 1, 12, nil, 1, 5.0, 5.0, now, now))
+
+		items, total, err := repo.GetCartItems(context.Background(), 1, 0, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, items, 3)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_GetCartCoupons(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM cart_coupons WHERE cart_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	mock.ExpectQuery(`SELECT \* FROM cart_coupons WHERE cart_id = \$1 ORDER BY created_at ASC, id ASC LIMIT \$2 OFFSET \$3`).
+		WithArgs(int64(1), 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "coupon_code", "discount_amount", "created_at"}).
+			AddRow(3, 1, "SAVE10", 10.0, now))
+
+	coupons, total, err := repo.GetCartCoupons(context.Background(), 1, 2, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, coupons, 1)
+	assert.Equal(t, "SAVE10", coupons[0].CouponCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const cartSummaryTotalsQuery = `SELECT c\.currency, c\.tax_exempt, .* ` +
+	`COALESCE\(SUM\(ci\.total_price\) FILTER \(WHERE p\.taxable\), 0\) AS taxable_subtotal, .* ` +
+	`FROM cart_items ci JOIN products p ON p\.id = ci\.product_id WHERE ci\.cart_id = c\.id \) totals WHERE c\.id = \$1`
+
+const cartSummaryItemsQuery = `SELECT \* FROM cart_items WHERE cart_id = \$1 ORDER BY created_at ASC, id ASC LIMIT \$2`
+
+const cartSummaryCouponsQuery = `SELECT co\.\* FROM cart_coupons cc JOIN coupons co ON co\.code = cc\.coupon_code ` +
+	`WHERE cc\.cart_id = \$1 ORDER BY cc\.created_at ASC, cc\.id ASC`
+
+var cartSummaryTotalsColumns = []string{
+	"currency", "tax_exempt", "ship_country", "ship_state", "ship_postal_code",
+	"subtotal", "taxable_subtotal", "item_count", "shipping_amount", "shipping_method_id",
+}
+
+func TestCartRepository_GetCartSummary_TotalsCoverAllItems(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+
+	// Totals are aggregated in SQL over every item, not just the listed page
+	mock.ExpectQuery(cartSummaryTotalsQuery).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(cartSummaryTotalsColumns).
+			AddRow("USD", false, "US", "CA", "94107", 1500.0, 1500.0, 150, 12.5, 2))
+
+	mock.ExpectQuery(cartSummaryItemsQuery).
+		WithArgs(int64(1), cartSummaryItemLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price", "total_price", "created_at", "updated_at"}).
+			AddRow(1, 1, 10, nil, 1, 10.0, 10.0, now, now))
+
+	// Applied coupons come back for the service to price; no discount is summed here
+	mock.ExpectQuery(cartSummaryCouponsQuery).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "type", "value", "min_order_amount", "is_active"}).
+			AddRow(3, "SAVE10", "percentage", 10.0, 50.0, true))
+
+	summary, err := repo.GetCartSummary(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1500.0, summary.Subtotal)
+	assert.Equal(t, 150, summary.ItemCount)
+	assert.Zero(t, summary.DiscountAmount)
+	assert.Equal(t, 12.5, summary.ShippingAmount)
+	assert.Equal(t, int64(2), summary.ShippingMethodID)
+	assert.Equal(t, []domain.Coupon{{ID: 3, Code: "SAVE10", Type: "percentage", Value: 10, MinOrderAmount: 50, IsActive: true}}, summary.Coupons)
+	assert.Equal(t, "USD", summary.Currency)
+	assert.Equal(t, domain.ShippingDestination{Country: "US", State: "CA", PostalCode: "94107"}, summary.Destination)
+	assert.Len(t, summary.Items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_GetCartSummary_CartNotFound(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	mock.ExpectQuery(cartSummaryTotalsQuery).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetCartSummary(context.Background(), 1)
+
+	assert.EqualError(t, err, "cart with ID 1 not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_GetCartSummary_TaxBreakdown(t *testing.T) {
+	expectSummary := func(mock sqlmock.Sqlmock, taxExempt bool, subtotal, taxableSubtotal float64) {
+		mock.ExpectQuery(cartSummaryTotalsQuery).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(cartSummaryTotalsColumns).
+				AddRow("USD", taxExempt, "", "", "", subtotal, taxableSubtotal, 3, 0.0, 0))
+		mock.ExpectQuery(cartSummaryItemsQuery).
+			WithArgs(int64(1), cartSummaryItemLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(cartSummaryCouponsQuery).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+
+	t.Run("mixed taxable cart", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		// 200 of the 300 subtotal comes from taxable products
+		expectSummary(mock, false, 300.0, 200.0)
+
+		summary, err := repo.GetCartSummary(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 300.0, summary.Subtotal)
+		assert.Equal(t, 200.0, summary.TaxableSubtotal)
+		assert.False(t, summary.TaxExempt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("exempt customer", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		expectSummary(mock, true, 300.0, 200.0)
+
+		summary, err := repo.GetCartSummary(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200.0, summary.TaxableSubtotal)
+		assert.True(t, summary.TaxExempt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestCartRepository_GetCartSummary_QueryCount checks a full cart is summarized in a
+// fixed number of round trips: totals, the listed items and the applied coupons. sqlmock
+// rejects any query beyond the three expected, so the summary would fail.
+func TestCartRepository_GetCartSummary_QueryCount(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+
+	items := sqlmock.NewRows([]string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price", "total_price", "created_at", "updated_at", "version"})
+	for i := 1; i <= cartSummaryItemLimit; i++ {
+		items.AddRow(i, 1, i, nil, 1, 10.0, 10.0, now, now, 1)
+	}
+
+	mock.ExpectQuery(cartSummaryTotalsQuery).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(cartSummaryTotalsColumns).AddRow("USD", false, "", "", "", 1000.0, 1000.0, 100, 0.0, 0))
+	mock.ExpectQuery(cartSummaryItemsQuery).
+		WithArgs(int64(1), cartSummaryItemLimit).
+		WillReturnRows(items)
+	mock.ExpectQuery(cartSummaryCouponsQuery).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	summary, err := repo.GetCartSummary(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Len(t, summary.Items, cartSummaryItemLimit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_ListActiveCartIDs(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+
+	mock.ExpectQuery(`SELECT id FROM carts WHERE \(expires_at IS NULL OR expires_at > NOW\(\)\) AND id > \$1 ORDER BY id ASC LIMIT \$2`).
+		WithArgs(int64(10), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(14))
+
+	ids, err := repo.ListActiveCartIDs(context.Background(), 10, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{11, 14}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_RecalculateCartItemPrices(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+
+	// Prices come from the variant when set, otherwise the product; unchanged items are skipped
+	mock.ExpectQuery(`WITH current_prices AS \( SELECT ci\.id, CASE WHEN ci\.product_variant_id IS NOT NULL THEN pv\.price ELSE p\.price END AS price ` +
+		`FROM cart_items ci JOIN products p ON p\.id = ci\.product_id LEFT JOIN product_variants pv ON pv\.id = ci\.product_variant_id ` +
+		`WHERE ci\.cart_id = ANY\(\$1\) \), changed AS \( UPDATE cart_items ci ` +
+		`SET unit_price = cp\.price, total_price = cp\.price \* ci\.quantity, updated_at = NOW\(\), version = ci\.version \+ 1 ` +
+		`FROM current_prices cp WHERE ci\.id = cp\.id AND cp\.price IS NOT NULL AND ci\.unit_price <> cp\.price RETURNING ci\.cart_id \) ` +
+		`SELECT COUNT\(DISTINCT cart_id\) AS carts_updated, COUNT\(\*\) AS items_changed FROM changed`).
+		WithArgs("{1,2,3}").
+		WillReturnRows(sqlmock.NewRows([]string{"carts_updated", "items_changed"}).AddRow(2, 5))
+
+	cartsUpdated, itemsChanged, err := repo.RecalculateCartItemPrices(context.Background(), []int64{1, 2, 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, cartsUpdated)
+	assert.Equal(t, 5, itemsChanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_DeduplicateCartItems(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+	variantID := int64(9)
+
+	// Product 5 appears three times without a variant, product 6 twice with variant 9,
+	// and product 6 once without a variant, which is a distinct line
+	rows := sqlmock.NewRows([]string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price", "total_price", "created_at", "updated_at"}).
+		AddRow(1, 3, 5, nil, 1, 10.0, 10.0, now, now).
+		AddRow(2, 3, 6, variantID, 2, 4.0, 8.0, now, now).
+		AddRow(3, 3, 5, nil, 2, 12.0, 24.0, now, now).
+		AddRow(4, 3, 6, variantID, 1, 4.0, 4.0, now, now).
+		AddRow(5, 3, 6, nil, 1, 3.0, 3.0, now, now).
+		AddRow(6, 3, 5, nil, 4, 10.0, 40.0, now, now)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM cart_items WHERE cart_id = \$1 ORDER BY id FOR UPDATE`).
+		WithArgs(int64(3)).
+		WillReturnRows(rows)
+	mock.ExpectExec(`UPDATE cart_items SET quantity = \$1, total_price = \$2, updated_at = \$3, version = version \+ 1 WHERE id = \$4`).
+		WithArgs(7, 70.0, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE cart_items SET quantity = \$1, total_price = \$2, updated_at = \$3, version = version \+ 1 WHERE id = \$4`).
+		WithArgs(3, 12.0, sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM cart_items WHERE id = ANY\(\$1\)`).
+		WithArgs("{3,4,6}").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	itemsMerged, itemsRemoved, err := repo.DeduplicateCartItems(context.Background(), 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, itemsMerged)
+	assert.Equal(t, 3, itemsRemoved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_DeduplicateCartItems_NoDuplicates(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM cart_items WHERE cart_id = \$1 ORDER BY id FOR UPDATE`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price", "total_price", "created_at", "updated_at"}).
+			AddRow(1, 3, 5, nil, 1, 10.0, 10.0, now, now))
+	mock.ExpectRollback()
+
+	itemsMerged, itemsRemoved, err := repo.DeduplicateCartItems(context.Background(), 3)
+
+	require.NoError(t, err)
+	assert.Zero(t, itemsMerged)
+	assert.Zero(t, itemsRemoved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_GetOrCreateCart_ConcurrentInsert(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+
+	// No cart yet when looked up, but a concurrent request inserts one first
+	mock.ExpectQuery(`SELECT \* FROM carts WHERE session_id = \$1 ORDER BY created_at DESC LIMIT 1`).
+		WithArgs("session-1").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`INSERT INTO carts`).
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+	mock.ExpectQuery(`SELECT \* FROM carts WHERE session_id = \$1 ORDER BY created_at DESC LIMIT 1`).
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "session_id", "currency", "created_at", "updated_at", "expires_at", "tax_exempt", "last_reminder_at"}).
+			AddRow(7, nil, "session-1", "USD", now, now, nil, false, nil))
+
+	cart, err := repo.GetOrCreateCart(context.Background(), nil, "session-1", "USD")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), cart.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_CreateCart_Duplicate(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	userID := int64(42)
+
+	mock.ExpectQuery(`INSERT INTO carts`).
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	err := repo.CreateCart(context.Background(), &domain.Cart{UserID: &userID, SessionID: "session-1", Currency: "USD"})
+
+	assert.ErrorIs(t, err, ErrCartExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_DeleteExpiredCartForOwner(t *testing.T) {
+	t.Run("user cart", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		userID := int64(42)
+		mock.ExpectExec(`DELETE FROM carts WHERE user_id = \$1 AND expires_at <= NOW\(\)`).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		deleted, err := repo.DeleteExpiredCartForOwner(context.Background(), &userID, "session-1")
+
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("guest cart", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(`DELETE FROM carts WHERE user_id IS NULL AND session_id = \$1 AND expires_at <= NOW\(\)`).
+			WithArgs("session-1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		deleted, err := repo.DeleteExpiredCartForOwner(context.Background(), nil, "session-1")
+
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_DeleteExpiredCarts_LogsFailures(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	before := time.Now()
+	mock.ExpectQuery(`SELECT \* FROM carts WHERE expires_at < \$1`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM cart_items WHERE cart_id = \$1`).
+		WithArgs(int64(7)).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	var buf bytes.Buffer
+	ctx := logger.WithContext(context.Background(), logger.New(logger.Config{Level: "info"}, &buf))
+
+	err := repo.DeleteExpiredCarts(ctx, before)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "failed to delete expired cart", record["msg"])
+	assert.Equal(t, float64(7), record["cart_id"])
+	assert.Contains(t, record["error"], "connection reset")
+}
+
+func TestCartRepository_ApplyCouponToCart_Duplicate(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+
+	mock.ExpectExec(`INSERT INTO cart_coupons`).
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	err := repo.ApplyCouponToCart(context.Background(), &domain.CartCoupon{CartID: 7, CouponCode: "SAVE10", DiscountAmount: 10})
+
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	assert.Equal(t, "coupon SAVE10 on cart 7 already exists", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_AddItemToCart(t *testing.T) {
+	t.Run("returns the new item's ID", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectQuery(`INSERT INTO cart_items .* RETURNING id`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+
+		item := &domain.CartItem{CartID: 1, ProductID: 5, Quantity: 2, UnitPrice: 10}
+		err := repo.AddItemToCart(context.Background(), item)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), item.ID)
+		assert.Equal(t, 1, item.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reports an error raised while reading the row", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectQuery(`INSERT INTO cart_items .* RETURNING id`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12).RowError(0, &pq.Error{Code: "23505"}))
+
+		err := repo.AddItemToCart(context.Background(), &domain.CartItem{CartID: 1, ProductID: 5, Quantity: 2})
+
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_UpdateCartItem_Version(t *testing.T) {
+	updateQuery := `UPDATE cart_items SET .* version = version \+ 1 WHERE id = \? AND version = \?`
+	newItem := func() *domain.CartItem {
+		return &domain.CartItem{CartID: 1, ProductID: 5, Quantity: 3, UnitPrice: 10, Version: 2}
+	}
+
+	t.Run("fresh version is applied and bumped", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(updateQuery).
+			WithArgs(3, 10.0, 30.0, sqlmock.AnyArg(), int64(9), 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		item := newItem()
+		err := repo.UpdateCartItem(context.Background(), 9, item)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, item.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE id = \$1\)`).
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		item := newItem()
+		err := repo.UpdateCartItem(context.Background(), 9, item)
+
+		assert.ErrorIs(t, err, ErrStaleCartItem)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, 2, item.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing item is not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE id = \$1\)`).
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		err := repo.UpdateCartItem(context.Background(), 9, newItem())
+
+		assert.NotErrorIs(t, err, ErrStaleCartItem)
+		assert.Contains(t, err.Error(), "not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_DeleteCartItemAtVersion(t *testing.T) {
+	deleteQuery := `DELETE FROM cart_items WHERE id = \$1 AND version = \$2`
+
+	t.Run("current version removes the row", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(deleteQuery).
+			WithArgs(int64(9), 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteCartItemAtVersion(context.Background(), 9, 2)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version keeps the row", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE id = \$1\)`).
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err := repo.DeleteCartItemAtVersion(context.Background(), 9, 2)
+
+		assert.ErrorIs(t, err, ErrStaleCartItem)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing item is not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE id = \$1\)`).
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		err := repo.DeleteCartItemAtVersion(context.Background(), 9, 2)
+
+		assert.EqualError(t, err, "cart item with ID 9 not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_MoveItemToCart(t *testing.T) {
+	wishlistColumns := []string{"id", "wishlist_id", "product_id", "product_variant_id", "notes", "created_at"}
+	mergeQuery := `UPDATE cart_items SET quantity = quantity \+ 1, unit_price = \$1, total_price = \$1 \* \(quantity \+ 1\), ` +
+		`updated_at = \$2, version = version \+ 1 WHERE id = \( SELECT id FROM cart_items ` +
+		`WHERE cart_id = \$3 AND product_id = \$4 AND product_variant_id IS NOT DISTINCT FROM \$5 ORDER BY id LIMIT 1 \)`
+
+	expectWishlistItem := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM wishlist_items WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(wishlistColumns).AddRow(4, 2, 5, nil, "", time.Now()))
+	}
+
+	t.Run("creates a line at the given price", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		expectWishlistItem(mock)
+		mock.ExpectExec(mergeQuery).
+			WithArgs(19.5, sqlmock.AnyArg(), int64(3), int64(5), nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO cart_items`).
+			WithArgs(int64(3), int64(5), nil, 1, 19.5, 19.5, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM wishlist_items WHERE id = \$1`).
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.MoveItemToCart(context.Background(), 4, 3, 19.5)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("adds a unit to an existing line", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		expectWishlistItem(mock)
+		mock.ExpectExec(mergeQuery).
+			WithArgs(19.5, sqlmock.AnyArg(), int64(3), int64(5), nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM wishlist_items WHERE id = \$1`).
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.MoveItemToCart(context.Background(), 4, 3, 19.5)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCartRepository_GetCartItemDetails(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCartRepository(db)
+	now := time.Now()
+	variantID := int64(9)
+
+	mock.ExpectQuery(`SELECT ci\.\*, p\.name AS product_name, COALESCE\(pv\.sku, p\.sku\) AS sku FROM cart_items ci `+
+		`JOIN products p ON p\.id = ci\.product_id LEFT JOIN product_variants pv ON pv\.id = ci\.product_variant_id `+
+		`WHERE ci\.cart_id = \$1 ORDER BY ci\.created_at ASC, ci\.id ASC LIMIT \$2`).
+		WithArgs(int64(3), cartSummaryItemLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "product_id", "product_variant_id", "quantity", "unit_price",
+			"total_price", "created_at", "updated_at", "version", "product_name", "sku"}).
+			AddRow(1, 3, 5, nil, 2, 10.0, 20.0, now, now, 1, "Widget", "WID-1").
+			AddRow(2, 3, 6, variantID, 1, 4.0, 4.0, now, now, 3, "Gadget", "GAD-1-RED"))
+
+	items, err := repo.GetCartItemDetails(context.Background(), 3)
+
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, int64(1), items[0].ID)
+	assert.Equal(t, "Widget", items[0].ProductName)
+	assert.Equal(t, "WID-1", items[0].SKU)
+	assert.Equal(t, &variantID, items[1].ProductVariantID)
+	assert.Equal(t, "GAD-1-RED", items[1].SKU)
+	assert.Equal(t, 3, items[1].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartRepository_GetPublicWishlistByShareToken(t *testing.T) {
+	query := `SELECT \* FROM wishlists WHERE share_token = \$1 AND is_public = true`
+
+	t.Run("public wishlist", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{"id", "user_id", "name", "is_public", "share_token", "created_at", "updated_at"}).
+			AddRow(3, 7, "Birthday", true, "abc123", now, now)
+		mock.ExpectQuery(query).WithArgs("abc123").WillReturnRows(rows)
+
+		wishlist, err := repo.GetPublicWishlistByShareToken(context.Background(), "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), wishlist.ID)
+		require.NotNil(t, wishlist.ShareToken)
+		assert.Equal(t, "abc123", *wishlist.ShareToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("private or unknown", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCartRepository(db)
+		mock.ExpectQuery(query).WithArgs("revoked").WillReturnError(sql.ErrNoRows)
+
+		wishlist, err := repo.GetPublicWishlistByShareToken(context.Background(), "revoked")
+
+		assert.Nil(t, wishlist)
+		assert.ErrorIs(t, err, ErrWishlistNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
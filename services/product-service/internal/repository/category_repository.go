@@ -17,6 +17,8 @@ type CategoryRepository interface {
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter *domain.CategoryFilter) ([]*domain.Category, error)
 	GetHierarchy(ctx context.Context) ([]*domain.CategoryHierarchy, error)
+	GetTree(ctx context.Context) ([]*domain.CategoryHierarchy, error)
+	GetBreadcrumb(ctx context.Context, id int64) ([]*domain.Category, error)
 	GetChildren(ctx context.Context, parentID int64) ([]*domain.Category, error)
 	Exists(ctx context.Context, id int64) (bool, error)
 	ExistsBySlug(ctx context.Context, slug string, excludeID *int64) (bool, error)
@@ -45,6 +47,9 @@ func (r *categoryRepository) Create(ctx context.Context, category *domain.Catego
 
 	rows, err := r.db.NamedQueryContext(ctx, query, category)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("category with slug %s", category.Slug)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to create category: %w", err)
 	}
 	defer rows.Close()
@@ -113,6 +118,9 @@ func (r *categoryRepository) Update(ctx context.Context, category *domain.Catego
 
 	result, err := r.db.NamedExecContext(ctx, query, category)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("category with slug %s", category.Slug)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to update category: %w", err)
 	}
 
@@ -122,7 +130,7 @@ func (r *categoryRepository) Update(ctx context.Context, category *domain.Catego
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("category with ID %d not found", category.ID)
+		return notFoundf(ErrCategoryNotFound, "category with ID %d not found", category.ID)
 	}
 
 	return nil
@@ -142,7 +150,7 @@ func (r *categoryRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("category with ID %d not found", id)
+		return notFoundf(ErrCategoryNotFound, "category with ID %d not found", id)
 	}
 
 	return nil
@@ -205,40 +213,33 @@ func (r *categoryRepository) List(ctx context.Context, filter *domain.CategoryFi
 
 	return categories, nil
 }
+
+// GetHierarchy is GetTree
 func (r *categoryRepository) GetHierarchy(ctx context.Context) ([]*domain.CategoryHierarchy, error) {
-	// First, get all categories
-	categories, err := r.List(ctx, &domain.CategoryFilter{})
+	return r.GetTree(ctx)
+}
+
+// GetTree loads every category in one query and nests them by parent, each level ordered
+// by sort order then name. It returns ErrCategoryCycle if any category's parents loop.
+func (r *categoryRepository) GetTree(ctx context.Context) ([]*domain.CategoryHierarchy, error) {
+	categories, err := r.List(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get categories for hierarchy: %w", err)
+		return nil, fmt.Errorf("failed to get categories for tree: %w", err)
 	}
 
-	// Build a map for quick lookup
-	categoryMap := make(map[int64]*domain.CategoryHierarchy)
-	var rootCategories []*domain.CategoryHierarchy
-
-	// Convert to hierarchy structs
-	for _, cat := range categories {
-		hierarchy := &domain.CategoryHierarchy{
-			Category: *cat,
-			Children: []domain.CategoryHierarchy{},
-		}
-		categoryMap[cat.ID] = hierarchy
-
-		if cat.ParentID == nil {
-			rootCategories = append(rootCategories, hierarchy)
-		}
-	}
+	return buildCategoryTree(categories)
+}
 
-	// Build the hierarchy
-	for _, cat := range categories {
-		if cat.ParentID != nil {
-			if parent, exists := categoryMap[*cat.ParentID]; exists {
-				parent.Children = append(parent.Children, *categoryMap[cat.ID])
-			}
-		}
+// GetBreadcrumb returns the path from the root category down to the category with the
+// given ID, inclusive. The categories are loaded in one query and the path is followed in
+// Go; it returns ErrCategoryCycle if the path loops.
+func (r *categoryRepository) GetBreadcrumb(ctx context.Context, id int64) ([]*domain.Category, error) {
+	categories, err := r.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories for breadcrumb: %w", err)
 	}
 
-	return rootCategories, nil
+	return categoryBreadcrumb(categories, id)
 }
 
 func (r *categoryRepository) GetChildren(ctx context.Context, parentID int64) ([]*domain.Category, error) {
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listAllCategoriesQuery = `SELECT id, name, description, slug, parent_id, .* FROM categories WHERE 1=1 ORDER BY sort_order ASC, name ASC$`
+
+// categoryRows returns category rows for (id, name, parent_id) triples; a zero parent is
+// a root
+func categoryRows(categories ...[3]interface{}) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "slug", "parent_id", "is_active", "sort_order",
+		"image_url", "meta_title", "meta_description", "created_at", "updated_at"})
+	now := time.Now()
+	for i, category := range categories {
+		var parentID interface{}
+		if category[2] != 0 {
+			parentID = category[2]
+		}
+		rows.AddRow(category[0], category[1], "", category[1], parentID, true, i, "", "", "", now, now)
+	}
+	return rows
+}
+
+func TestCategoryRepository_GetTree(t *testing.T) {
+	t.Run("nests three levels", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCategoryRepository(db)
+		// Children are listed before their parents to show the order of rows does not matter
+		mock.ExpectQuery(listAllCategoriesQuery).
+			WillReturnRows(categoryRows(
+				[3]interface{}{4, "Helmets", 2},
+				[3]interface{}{5, "Full face", 4},
+				[3]interface{}{6, "Open face", 4},
+				[3]interface{}{2, "Gear", 0},
+				[3]interface{}{3, "Gloves", 2},
+				[3]interface{}{7, "Parts", 0},
+			))
+
+		tree, err := repo.GetTree(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, tree, 2)
+		assert.Equal(t, "Gear", tree[0].Name)
+		assert.Equal(t, "Parts", tree[1].Name)
+		assert.Empty(t, tree[1].Children)
+
+		gear := tree[0]
+		require.Len(t, gear.Children, 2)
+		assert.Equal(t, "Helmets", gear.Children[0].Name)
+		assert.Equal(t, "Gloves", gear.Children[1].Name)
+		assert.Empty(t, gear.Children[1].Children)
+
+		helmets := gear.Children[0]
+		require.Len(t, helmets.Children, 2)
+		assert.Equal(t, "Full face", helmets.Children[0].Name)
+		assert.Equal(t, "Open face", helmets.Children[1].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCategoryRepository(db)
+		// 3 and 4 are each other's parent, and 5 hangs off the loop
+		mock.ExpectQuery(listAllCategoriesQuery).
+			WillReturnRows(categoryRows(
+				[3]interface{}{1, "Gear", 0},
+				[3]interface{}{3, "Loop A", 4},
+				[3]interface{}{4, "Loop B", 3},
+				[3]interface{}{5, "Below loop", 4},
+			))
+
+		tree, err := repo.GetTree(context.Background())
+
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+		assert.Nil(t, tree)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCategoryRepository_GetBreadcrumb(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int64
+		wantIDs  []int64
+		wantErr  error
+		notFound bool
+	}{
+		{name: "third level", id: 5, wantIDs: []int64{2, 4, 5}},
+		{name: "root", id: 2, wantIDs: []int64{2}},
+		{name: "cycle", id: 8, wantErr: ErrCategoryCycle},
+		{name: "missing", id: 99, notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupTestDB(t)
+			defer cleanup()
+
+			repo := NewCategoryRepository(db)
+			mock.ExpectQuery(listAllCategoriesQuery).
+				WillReturnRows(categoryRows(
+					[3]interface{}{2, "Gear", 0},
+					[3]interface{}{4, "Helmets", 2},
+					[3]interface{}{5, "Full face", 4},
+					[3]interface{}{8, "Loop A", 9},
+					[3]interface{}{9, "Loop B", 8},
+				))
+
+			breadcrumb, err := repo.GetBreadcrumb(context.Background(), tt.id)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.notFound:
+				assert.EqualError(t, err, "category with ID 99 not found")
+			default:
+				require.NoError(t, err)
+				ids := make([]int64, len(breadcrumb))
+				for i, category := range breadcrumb {
+					ids[i] = category.ID
+				}
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// buildCategoryTree nests categories under their parents, keeping the order they are given
+// in within each level. Categories without a parent, or whose parent is missing, are roots.
+// A category never reached from a root has a cycle among its parents.
+func buildCategoryTree(categories []*domain.Category) ([]*domain.CategoryHierarchy, error) {
+	byID := make(map[int64]*domain.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	var roots []*domain.Category
+	children := make(map[int64][]*domain.Category)
+	for _, category := range categories {
+		if category.ParentID == nil || byID[*category.ParentID] == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	// Each category has one parent, so walking down from the roots visits every category
+	// at most once and cannot loop
+	visited := make(map[int64]bool, len(categories))
+	var build func(category *domain.Category) domain.CategoryHierarchy
+	build = func(category *domain.Category) domain.CategoryHierarchy {
+		visited[category.ID] = true
+		node := domain.CategoryHierarchy{
+			Category: *category,
+			Children: make([]domain.CategoryHierarchy, 0, len(children[category.ID])),
+		}
+		for _, child := range children[category.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]*domain.CategoryHierarchy, 0, len(roots))
+	for _, root := range roots {
+		node := build(root)
+		tree = append(tree, &node)
+	}
+
+	for _, category := range categories {
+		if !visited[category.ID] {
+			return nil, fmt.Errorf("%w: category %d", ErrCategoryCycle, category.ID)
+		}
+	}
+
+	return tree, nil
+}
+
+// categoryBreadcrumb follows parents from the category with the given ID up to its root
+// and returns the path root first
+func categoryBreadcrumb(categories []*domain.Category, id int64) ([]*domain.Category, error) {
+	byID := make(map[int64]*domain.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	category := byID[id]
+	if category == nil {
+		return nil, notFoundf(ErrCategoryNotFound, "category with ID %d not found", id)
+	}
+
+	var path []*domain.Category
+	seen := make(map[int64]bool)
+	for category != nil {
+		if seen[category.ID] {
+			return nil, fmt.Errorf("%w: category %d", ErrCategoryCycle, category.ID)
+		}
+		seen[category.ID] = true
+		path = append(path, category)
+
+		if category.ParentID == nil {
+			break
+		}
+		category = byID[*category.ParentID]
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, nil
+}
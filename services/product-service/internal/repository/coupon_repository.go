@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type CouponRepository interface {
+	// Coupons
+	GetCouponByCode(ctx context.Context, code string) (*domain.Coupon, error)
+
+	// Redemptions
+	CountCouponRedemptions(ctx context.Context, couponID int64, userID *int64) (total int64, byUser int64, err error)
+	RedeemCoupon(ctx context.Context, redemption *domain.CouponRedemption) error
+	ReleaseCouponRedemption(ctx context.Context, couponID, cartID int64) error
+}
+
+type couponRepository struct {
+	db *sqlx.DB
+}
+
+func NewCouponRepository(db *sqlx.DB) CouponRepository {
+	return &couponRepository{db: db}
+}
+
+// Coupons
+
+// GetCouponByCode retrieves a coupon by its code
+func (r *couponRepository) GetCouponByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	var coupon domain.Coupon
+	query := `SELECT * FROM coupons WHERE code = $1`
+
+	err := r.db.GetContext(ctx, &coupon, query, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCouponNotFound
+		}
+		return nil, fmt.Errorf("failed to get coupon: %w", err)
+	}
+
+	return &coupon, nil
+}
+
+// Redemptions
+
+// CountCouponRedemptions counts all redemptions of a coupon and those made by a user.
+// A nil userID counts no redemptions for the user.
+func (r *couponRepository) CountCouponRedemptions(ctx context.Context, couponID int64, userID *int64) (int64, int64, error) {
+	total, byUser, err := countCouponRedemptions(ctx, r.db, couponID, userID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+
+	return total, byUser, nil
+}
+
+// RedeemCoupon records a redemption if the coupon's usage and per-user limits allow it,
+// returning ErrCouponLimitReached otherwise. The coupon row is locked while the limits
+// are checked so concurrent redemptions cannot overshoot them. Redeeming a coupon that
+// the cart has already redeemed is a no-op.
+func (r *couponRepository) RedeemCoupon(ctx context.Context, redemption *domain.CouponRedemption) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := redeemCoupon(ctx, tx, redemption); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// redeemCoupon records a redemption inside tx the way RedeemCoupon does
+func redeemCoupon(ctx context.Context, tx *sqlx.Tx, redemption *domain.CouponRedemption) error {
+	var limits struct {
+		UsageLimit   int `db:"usage_limit"`
+		PerUserLimit int `db:"per_user_limit"`
+	}
+	err := tx.GetContext(ctx, &limits,
+		`SELECT usage_limit, per_user_limit FROM coupons WHERE id = $1 FOR UPDATE`, redemption.CouponID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCouponNotFound
+		}
+		return fmt.Errorf("failed to lock coupon: %w", err)
+	}
+
+	var alreadyRedeemed bool
+	err = tx.GetContext(ctx, &alreadyRedeemed,
+		`SELECT EXISTS(SELECT 1 FROM coupon_redemptions WHERE coupon_id = $1 AND cart_id = $2)`,
+		redemption.CouponID, redemption.CartID)
+	if err != nil {
+		return fmt.Errorf("failed to check coupon redemption: %w", err)
+	}
+	if alreadyRedeemed {
+		return nil
+	}
+
+	total, byUser, err := countCouponRedemptions(ctx, tx, redemption.CouponID, redemption.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	if limits.UsageLimit > 0 && total >= int64(limits.UsageLimit) {
+		return ErrCouponLimitReached
+	}
+	if limits.PerUserLimit > 0 && byUser >= int64(limits.PerUserLimit) {
+		return ErrCouponLimitReached
+	}
+
+	redemption.RedeemedAt = time.Now()
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, cart_id, redeemed_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		redemption.CouponID, redemption.UserID, redemption.CartID, redemption.RedeemedAt,
+	).Scan(&redemption.ID)
+	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("redemption of coupon %d for cart %d", redemption.CouponID, redemption.CartID)); existsErr != nil {
+			return existsErr
+		}
+		return fmt.Errorf("failed to record coupon redemption: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE coupons SET used_count = used_count + 1, updated_at = $1 WHERE id = $2`,
+		redemption.RedeemedAt, redemption.CouponID)
+	if err != nil {
+		return fmt.Errorf("failed to update coupon usage count: %w", err)
+	}
+
+	return nil
+}
+
+// redeemCartCoupons redeems every coupon applied to a cart inside tx, returning
+// ErrCouponLimitReached if any of them is used up. Coupons are locked in ID order so
+// concurrent checkouts cannot deadlock.
+func redeemCartCoupons(ctx context.Context, tx *sqlx.Tx, cartID int64, userID *int64) error {
+	var couponIDs []int64
+	err := tx.SelectContext(ctx, &couponIDs, `
+		SELECT c.id
+		FROM cart_coupons cc
+		JOIN coupons c ON c.code = cc.coupon_code
+		WHERE cc.cart_id = $1
+		ORDER BY c.id`, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart coupons: %w", err)
+	}
+
+	for _, couponID := range couponIDs {
+		redemption := &domain.CouponRedemption{CouponID: couponID, UserID: userID, CartID: cartID}
+		if err := redeemCoupon(ctx, tx, redemption); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseCouponRedemption removes a cart's redemption of a coupon, freeing it for reuse.
+// Releasing a redemption that does not exist is a no-op.
+func (r *couponRepository) ReleaseCouponRedemption(ctx context.Context, couponID, cartID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM coupon_redemptions WHERE coupon_id = $1 AND cart_id = $2`, couponID, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to release coupon redemption: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE coupons SET used_count = GREATEST(used_count - $1, 0), updated_at = $2 WHERE id = $3`,
+		rowsAffected, time.Now(), couponID)
+	if err != nil {
+		return fmt.Errorf("failed to update coupon usage count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// countCouponRedemptions counts a coupon's redemptions overall and for one user
+func countCouponRedemptions(ctx context.Context, q sqlx.QueryerContext, couponID int64, userID *int64) (int64, int64, error) {
+	var total, byUser int64
+	err := q.QueryRowxContext(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2)
+		FROM coupon_redemptions WHERE coupon_id = $1`,
+		couponID, userID,
+	).Scan(&total, &byUser)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return total, byUser, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponRepository_RedeemCoupon(t *testing.T) {
+	userID := int64(42)
+
+	expectLimits := func(mock sqlmock.Sqlmock, usageLimit, perUserLimit int, total, byUser int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT usage_limit, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"usage_limit", "per_user_limit"}).AddRow(usageLimit, perUserLimit))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM coupon_redemptions WHERE coupon_id = \$1 AND cart_id = \$2\)`).
+			WithArgs(int64(5), int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`SELECT COUNT\(\*\), COUNT\(\*\) FILTER \(WHERE user_id = \$2\) FROM coupon_redemptions WHERE coupon_id = \$1`).
+			WithArgs(int64(5), userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(total, byUser))
+	}
+
+	t.Run("records redemption under the limits", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCouponRepository(db)
+		expectLimits(mock, 100, 2, 10, 1)
+		mock.ExpectQuery(`INSERT INTO coupon_redemptions`).
+			WithArgs(int64(5), userID, int64(9), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+		mock.ExpectExec(`UPDATE coupons SET used_count = used_count \+ 1, updated_at = \$1 WHERE id = \$2`).
+			WithArgs(sqlmock.AnyArg(), int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		redemption := &domain.CouponRedemption{CouponID: 5, UserID: &userID, CartID: 9}
+		err := repo.RedeemCoupon(context.Background(), redemption)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(77), redemption.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("per-user limit reached", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCouponRepository(db)
+		expectLimits(mock, 0, 1, 10, 1)
+		mock.ExpectRollback()
+
+		err := repo.RedeemCoupon(context.Background(), &domain.CouponRedemption{CouponID: 5, UserID: &userID, CartID: 9})
+
+		assert.ErrorIs(t, err, ErrCouponLimitReached)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("global limit reached", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCouponRepository(db)
+		expectLimits(mock, 10, 0, 10, 0)
+		mock.ExpectRollback()
+
+		err := repo.RedeemCoupon(context.Background(), &domain.CouponRedemption{CouponID: 5, UserID: &userID, CartID: 9})
+
+		assert.ErrorIs(t, err, ErrCouponLimitReached)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cart already redeemed the coupon", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewCouponRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT usage_limit, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"usage_limit", "per_user_limit"}).AddRow(1, 1))
+		mock.ExpectQuery(`SELECT EXISTS`).
+			WithArgs(int64(5), int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectCommit()
+
+		err := repo.RedeemCoupon(context.Background(), &domain.CouponRedemption{CouponID: 5, UserID: &userID, CartID: 9})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ErrAlreadyExists is matched by every AlreadyExistsError
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrNotFound is matched by every error for a missing row. Each resource has its own
+// sentinel wrapping it, such as ErrCartNotFound, so callers can match either.
+var ErrNotFound = errors.New("not found")
+
+// Not found errors for each resource
+var (
+	ErrProductNotFound             = fmt.Errorf("product %w", ErrNotFound)
+	ErrProductVariantNotFound      = fmt.Errorf("product variant %w", ErrNotFound)
+	ErrCategoryNotFound            = fmt.Errorf("category %w", ErrNotFound)
+	ErrCartNotFound                = fmt.Errorf("cart %w", ErrNotFound)
+	ErrCartItemNotFound            = fmt.Errorf("cart item %w", ErrNotFound)
+	ErrCartCouponNotFound          = fmt.Errorf("cart coupon %w", ErrNotFound)
+	ErrCartShippingNotFound        = fmt.Errorf("cart shipping %w", ErrNotFound)
+	ErrWishlistNotFound            = fmt.Errorf("wishlist %w", ErrNotFound)
+	ErrWishlistItemNotFound        = fmt.Errorf("wishlist item %w", ErrNotFound)
+	ErrInventoryNotFound           = fmt.Errorf("inventory %w", ErrNotFound)
+	ErrStockMovementNotFound       = fmt.Errorf("stock movement %w", ErrNotFound)
+	ErrStockReservationNotFound    = fmt.Errorf("stock reservation %w", ErrNotFound)
+	ErrInventoryAlertNotFound      = fmt.Errorf("inventory alert %w", ErrNotFound)
+	ErrWebhookSubscriptionNotFound = fmt.Errorf("webhook subscription %w", ErrNotFound)
+	ErrOrderNotFound               = fmt.Errorf("order %w", ErrNotFound)
+)
+
+// ErrVersionConflict is returned when an update carries a version that no longer matches the row
+var ErrVersionConflict = errors.New("resource was modified by another request; reload and retry")
+
+// ErrStaleCartItem is returned when a cart item changed after it was read. It matches
+// ErrVersionConflict.
+var ErrStaleCartItem = fmt.Errorf("cart item was modified by another request: %w", ErrVersionConflict)
+
+// ErrInventoryExists is returned when an inventory row already exists for a product/variant
+var ErrInventoryExists = errors.New("inventory already exists for this product/variant combination")
+
+// ErrCartExists is returned when creating a cart for a user or guest session that already has one
+var ErrCartExists = errors.New("cart already exists for this user or session")
+
+// ErrVariantCombinationNotFound is returned when no variant matches a set of attribute values
+var ErrVariantCombinationNotFound = fmt.Errorf("variant %w for the selected attributes", ErrNotFound)
+
+// ErrInsufficientStock is matched by every InsufficientInventoryError
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrCartEmpty is returned when checking out a cart that has no items
+var ErrCartEmpty = errors.New("cart has no items")
+
+// ErrCartChanged is returned when a cart's items change while it is being checked out
+var ErrCartChanged = errors.New("cart changed during checkout; review it and try again")
+
+// ErrStockNotificationExists is returned when a subscriber is already waiting for an item
+var ErrStockNotificationExists = errors.New("already subscribed to stock notifications for this item")
+
+// ErrStockNotificationNotFound is returned when no stock notification exists for an ID
+var ErrStockNotificationNotFound = fmt.Errorf("stock notification %w", ErrNotFound)
+
+// ErrProductImageNotFound is returned when a product has no image with the given ID
+var ErrProductImageNotFound = fmt.Errorf("product image %w", ErrNotFound)
+
+// ErrImageOrderMismatch is returned when a new image order does not list each of the
+// product's images exactly once
+var ErrImageOrderMismatch = errors.New("image order must list every image of the product exactly once")
+
+// ErrCategoryCycle is returned when following categories' parents leads back to a category
+// already on the path
+var ErrCategoryCycle = errors.New("category hierarchy contains a cycle")
+
+// ErrCouponNotFound is returned when no coupon exists for a code
+var ErrCouponNotFound = fmt.Errorf("coupon %w", ErrNotFound)
+
+// ErrCouponLimitReached is returned when redeeming a coupon would exceed its
+// global usage limit or its per-user limit
+var ErrCouponLimitReached = errors.New("coupon usage limit reached")
+
+// AlreadyExistsError is returned when a write hits a unique constraint. Resource names
+// the conflicting row without exposing the database error.
+type AlreadyExistsError struct {
+	Resource string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return e.Resource + " already exists"
+}
+
+// Is lets callers match any AlreadyExistsError with errors.Is(err, ErrAlreadyExists)
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
+
+// NotFoundError is returned when a lookup matches no row. Message describes the lookup;
+// the error matches Kind, the missing resource's sentinel, and through it ErrNotFound.
+type NotFoundError struct {
+	Message string
+	Kind    error
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Kind
+}
+
+// notFoundf returns a NotFoundError of kind with a message formatted from format and args
+func notFoundf(kind error, format string, args ...any) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...), Kind: kind}
+}
+
+// InvalidCategoryIDsError is returned when one or more category IDs do not exist
+type InvalidCategoryIDsError struct {
+	IDs []int64
+}
+
+func (e *InvalidCategoryIDsError) Error() string {
+	ids := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		ids[i] = fmt.Sprintf("%d", id)
+	}
+	return fmt.Sprintf("invalid category IDs: %s", strings.Join(ids, ", "))
+}
+
+// ReservedStockConflictError is returned when a quantity change would leave less
+// stock on hand than is currently reserved
+type ReservedStockConflictError struct {
+	Requested int
+	Reserved  int
+}
+
+func (e *ReservedStockConflictError) Error() string {
+	return fmt.Sprintf("quantity %d is below reserved quantity %d", e.Requested, e.Reserved)
+}
+
+// InsufficientInventoryError is returned when an item cannot be taken from stock
+// because too few units are available
+type InsufficientInventoryError struct {
+	ProductID        int64
+	ProductVariantID *int64
+	Requested        int
+	Available        int
+}
+
+func (e *InsufficientInventoryError) Error() string {
+	if e.ProductVariantID != nil {
+		return fmt.Sprintf("insufficient stock for product %d variant %d: requested %d, available %d",
+			e.ProductID, *e.ProductVariantID, e.Requested, e.Available)
+	}
+	return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d",
+		e.ProductID, e.Requested, e.Available)
+}
+
+// Is lets callers match any InsufficientInventoryError with errors.Is(err, ErrInsufficientStock)
+func (e *InsufficientInventoryError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// classifyUniqueViolation returns an AlreadyExistsError for resource when err is a unique
+// constraint violation, and nil otherwise
+func classifyUniqueViolation(err error, resource string) error {
+	if !isUniqueViolation(err) {
+		return nil
+	}
+	return &AlreadyExistsError{Resource: resource}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyUniqueViolation(t *testing.T) {
+	t.Run("unique violation becomes AlreadyExistsError", func(t *testing.T) {
+		dbErr := fmt.Errorf("exec failed: %w", &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint \"products_sku_key\""})
+
+		err := classifyUniqueViolation(dbErr, "product with SKU WIDGET-1")
+
+		var existsErr *AlreadyExistsError
+		assert.True(t, errors.As(err, &existsErr))
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+		assert.Equal(t, "product with SKU WIDGET-1 already exists", err.Error())
+		assert.NotContains(t, err.Error(), "products_sku_key")
+	})
+
+	t.Run("other errors are not classified", func(t *testing.T) {
+		assert.Nil(t, classifyUniqueViolation(&pq.Error{Code: "23503"}, "product"))
+		assert.Nil(t, classifyUniqueViolation(errors.New("connection refused"), "product"))
+	})
+}
+
+func TestNotFoundError(t *testing.T) {
+	err := fmt.Errorf("failed to get cart: %w", notFoundf(ErrCartNotFound, "cart with ID %d not found", 9))
+
+	var notFoundErr *NotFoundError
+	assert.True(t, errors.As(err, &notFoundErr))
+	assert.Equal(t, "cart with ID 9 not found", notFoundErr.Error())
+	assert.ErrorIs(t, err, ErrCartNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrCartItemNotFound)
+
+	// Sentinels declared before ErrNotFound existed keep their messages
+	assert.ErrorIs(t, ErrCouponNotFound, ErrNotFound)
+	assert.Equal(t, "coupon not found", ErrCouponNotFound.Error())
+	assert.Equal(t, "variant not found for the selected attributes", ErrVariantCombinationNotFound.Error())
+}
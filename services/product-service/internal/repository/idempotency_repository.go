@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepository interface {
+	ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (existing *domain.IdempotencyKey, claimed bool, err error)
+	SaveIdempotencyResponse(ctx context.Context, id int64, statusCode int, contentType string, body []byte, expiresAt time.Time) error
+	ReleaseIdempotencyKey(ctx context.Context, id int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// ClaimIdempotencyKey stores key for a new request. An expired row for the same scope and
+// key is taken over, including one whose request never saved a response before its
+// pending expiry. When a live row already holds the key it is returned with claimed
+// false, so the caller can replay or reject the request.
+func (r *idempotencyRepository) ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = '',
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING id`
+
+	rows, err := r.db.QueryxContext(ctx, query, key.Scope, key.Key, key.RequestHash, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	claimed := rows.Next()
+	if claimed {
+		err = rows.Scan(&key.ID)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key ID: %w", err)
+	}
+	if claimed {
+		return nil, true, nil
+	}
+
+	existing := &domain.IdempotencyKey{}
+	err = r.db.GetContext(ctx, existing, `SELECT * FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`, key.Scope, key.Key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return existing, false, nil
+}
+
+// SaveIdempotencyResponse records the response a claimed key replays and keeps the key
+// until expiresAt
+func (r *idempotencyRepository) SaveIdempotencyResponse(ctx context.Context, id int64, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3, expires_at = $4 WHERE id = $5`
+
+	_, err := r.db.ExecContext(ctx, query, statusCode, contentType, body, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency response: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes a claimed key so the request can be retried with it
+func (r *idempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys removes keys that expired before the cutoff and returns how many
+func (r *idempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository_ClaimIdempotencyKey(t *testing.T) {
+	now := time.Now()
+	newKey := func() *domain.IdempotencyKey {
+		return &domain.IdempotencyKey{Scope: "user:7", Key: "abc", RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	}
+
+	t.Run("claims an unused or expired key", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewIdempotencyRepository(db)
+		mock.ExpectQuery(`INSERT INTO idempotency_keys .* ON CONFLICT \(scope, idempotency_key\) DO UPDATE .* WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`).
+			WithArgs("user:7", "abc", "hash", now, now.Add(time.Hour)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+
+		key := newKey()
+		existing, claimed, err := repo.ClaimIdempotencyKey(context.Background(), key)
+
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Nil(t, existing)
+		assert.Equal(t, int64(12), key.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reports a failed claim", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewIdempotencyRepository(db)
+		mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12).RowError(0, errors.New("connection reset")))
+
+		existing, claimed, err := repo.ClaimIdempotencyKey(context.Background(), newKey())
+
+		assert.ErrorContains(t, err, "connection reset")
+		assert.False(t, claimed)
+		assert.Nil(t, existing)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns the live key holding it", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewIdempotencyRepository(db)
+		mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT \* FROM idempotency_keys WHERE scope = \$1 AND idempotency_key = \$2`).
+			WithArgs("user:7", "abc").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "scope", "idempotency_key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at"}).
+				AddRow(9, "user:7", "abc", "hash", 201, "application/json", []byte(`{"ok":true}`), now, now.Add(time.Hour)))
+
+		existing, claimed, err := repo.ClaimIdempotencyKey(context.Background(), newKey())
+
+		require.NoError(t, err)
+		assert.False(t, claimed)
+		require.NotNil(t, existing.StatusCode)
+		assert.Equal(t, 201, *existing.StatusCode)
+		assert.Equal(t, []byte(`{"ok":true}`), existing.ResponseBody)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIdempotencyRepository_SaveIdempotencyResponse(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewIdempotencyRepository(db)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	mock.ExpectExec(`UPDATE idempotency_keys SET status_code = \$1, content_type = \$2, response_body = \$3, expires_at = \$4 WHERE id = \$5`).
+		WithArgs(201, "application/json", []byte(`{"ok":true}`), expiresAt, int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.SaveIdempotencyResponse(context.Background(), 12, 201, "application/json", []byte(`{"ok":true}`), expiresAt)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
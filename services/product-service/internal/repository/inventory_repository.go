@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type InventoryRepository interface {
@@ -19,28 +22,40 @@ type InventoryRepository interface {
 	DeleteInventory(ctx context.Context, id int64) error
 	ListInventory(ctx context.Context, req *ListInventoryRequest) ([]*domain.Inventory, int64, error)
 	GetInventorySummary(ctx context.Context) (*domain.InventorySummary, error)
+	ListProductsNeedingRestock(ctx context.Context, page, limit int) ([]*domain.RestockCandidate, int64, error)
 
 	// Stock Movements
 	RecordStockMovement(ctx context.Context, movement *domain.InventoryMovement) error
 	GetStockMovements(ctx context.Context, req *ListStockMovementsRequest) ([]*domain.InventoryMovement, int64, error)
 	GetStockMovementByID(ctx context.Context, id int64) (*domain.InventoryMovement, error)
+	SetInventoryQuantity(ctx context.Context, productID int64, variantID *int64, newQty int, reason string) (*domain.InventoryMovement, error)
+	Restock(ctx context.Context, productID int64, variantID *int64, qty int, reference string) (*domain.InventoryMovement, error)
+	AdjustStock(ctx context.Context, productID int64, variantID *int64, delta int, reason, reference string) (*domain.InventoryMovement, error)
 
 	// Stock Reservations
 	ReserveStock(ctx context.Context, reservation *domain.StockReservation) error
-	ReleaseStock(ctx context.Context, reservationID int64) error
-	ReleaseStockByOrderID(ctx context.Context, orderID int64) error
+	ReleaseStock(ctx context.Context, reservationID int64) ([]*domain.StockChange, error)
+	ReleaseStockByOrderID(ctx context.Context, orderID int64) ([]*domain.StockChange, error)
 	GetStockReservations(ctx context.Context, orderID int64) ([]*domain.StockReservation, error)
+	GetStockReservationByID(ctx context.Context, id int64) (*domain.StockReservation, error)
+	UpdateReservationExpiry(ctx context.Context, id int64, expiresAt time.Time) error
 	GetExpiredReservations(ctx context.Context, before time.Time) ([]*domain.StockReservation, error)
-	CleanupExpiredReservations(ctx context.Context) error
+	CleanupExpiredReservations(ctx context.Context) ([]*domain.StockChange, error)
+	RecomputeReservedQuantities(ctx context.Context, productID *int64) ([]*domain.InventoryDiscrepancy, error)
 
 	// Inventory Alerts
 	CreateInventoryAlert(ctx context.Context, alert *domain.InventoryAlert) error
 	GetInventoryAlerts(ctx context.Context, resolved *bool) ([]*domain.InventoryAlert, error)
 	ResolveInventoryAlert(ctx context.Context, alertID int64) error
-	CheckLowStockAlerts(ctx context.Context) error
+	CheckLowStockAlerts(ctx context.Context) ([]*domain.InventoryAlert, error)
+
+	// Stock Notifications
+	CreateStockNotification(ctx context.Context, notification *domain.StockNotification) error
+	DeleteStockNotification(ctx context.Context, id, userID int64) error
+	ClaimStockNotifications(ctx context.Context, productID int64, variantID *int64) ([]*domain.StockNotification, error)
 
 	// Bulk Operations
-	BulkUpdateStock(ctx context.Context, updates []StockUpdateItem) (*BulkStockUpdateResponse, error)
+	BulkUpdateStock(ctx context.Context, updates []StockUpdateItem, atomic bool) (*BulkStockUpdateResponse, error)
 }
 
 type inventoryRepository struct {
@@ -66,6 +81,9 @@ func (r *inventoryRepository) CreateInventory(ctx context.Context, inventory *do
 
 	rows, err := r.db.NamedQueryContext(ctx, query, inventory)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrInventoryExists
+		}
 		return fmt.Errorf("failed to create inventory: %w", err)
 	}
 	defer rows.Close()
@@ -74,6 +92,7 @@ func (r *inventoryRepository) CreateInventory(ctx context.Context, inventory *do
 		if err := rows.Scan(&inventory.ID); err != nil {
 			return fmt.Errorf("failed to get inventory ID: %w", err)
 		}
+		inventory.Version = 1
 	}
 
 	return nil
@@ -84,13 +103,13 @@ func (r *inventoryRepository) GetInventoryByID(ctx context.Context, id int64) (*
 	var inventory domain.Inventory
 	query := `
 		SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
-			   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at
+			   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
 		FROM inventory WHERE id = $1`
 
 	err := r.db.GetContext(ctx, &inventory, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("inventory with ID %d not found", id)
+			return nil, notFoundf(ErrInventoryNotFound, "inventory with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get inventory: %w", err)
 	}
@@ -107,13 +126,13 @@ func (r *inventoryRepository) GetInventoryByProduct(ctx context.Context, product
 	if variantID != nil {
 		query = `
 			SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
-				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at
+				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
 			FROM inventory WHERE product_id = $1 AND product_variant_id = $2`
 		args = []interface{}{productID, *variantID}
 	} else {
 		query = `
 			SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
-				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at
+				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
 			FROM inventory WHERE product_id = $1 AND product_variant_id IS NULL`
 		args = []interface{}{productID}
 	}
@@ -121,7 +140,7 @@ func (r *inventoryRepository) GetInventoryByProduct(ctx context.Context, product
 	err := r.db.GetContext(ctx, &inventory, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, sql.ErrNoRows
+			return nil, notFoundf(ErrInventoryNotFound, "inventory for product %d not found", productID)
 		}
 		return nil, fmt.Errorf("failed to get inventory: %w", err)
 	}
@@ -129,16 +148,28 @@ func (r *inventoryRepository) GetInventoryByProduct(ctx context.Context, product
 	return &inventory, nil
 }
 
-// UpdateInventory updates an existing inventory record
+// UpdateInventory updates an existing inventory record. inventory.Version must be the
+// version the caller read; ErrVersionConflict is returned if the row has changed since.
+// On success inventory.Version holds the new version, and open alerts for the item are
+// resolved if available stock is now above the reorder point.
 func (r *inventoryRepository) UpdateInventory(ctx context.Context, inventory *domain.Inventory) error {
 	query := `
 		UPDATE inventory SET
 			quantity = :quantity, reserved_quantity = :reserved_quantity, available_quantity = :available_quantity,
 			min_stock_level = :min_stock_level, max_stock_level = :max_stock_level, reorder_point = :reorder_point,
-			last_restocked = :last_restocked, updated_at = :updated_at
-		WHERE id = :id`
+			last_restocked = :last_restocked, updated_at = :updated_at, updated_by = :updated_by,
+			version = version + 1
+		WHERE id = :id AND version = :version`
+
+	inventory.UpdatedBy = userctx.UserIDPtr(ctx)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.NamedExecContext(ctx, query, inventory)
+	result, err := tx.NamedExecContext(ctx, query, inventory)
 	if err != nil {
 		return fmt.Errorf("failed to update inventory: %w", err)
 	}
@@ -149,9 +180,27 @@ func (r *inventoryRepository) UpdateInventory(ctx context.Context, inventory *do
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("inventory with ID %d not found", inventory.ID)
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM inventory WHERE id = $1)`, inventory.ID); err != nil {
+			return fmt.Errorf("failed to check inventory: %w", err)
+		}
+		if exists {
+			return ErrVersionConflict
+		}
+		return notFoundf(ErrInventoryNotFound, "inventory with ID %d not found", inventory.ID)
+	}
+
+	err = resolveRecoveredAlerts(ctx, tx, inventory.ProductID, inventory.ProductVariantID,
+		inventory.AvailableQuantity, inventory.ReorderPoint, inventory.UpdatedAt)
+	if err != nil {
+		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	inventory.Version++
 	return nil
 }
 
@@ -170,7 +219,7 @@ func (r *inventoryRepository) DeleteInventory(ctx context.Context, id int64) err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("inventory with ID %d not found", id)
+		return notFoundf(ErrInventoryNotFound, "inventory with ID %d not found", id)
 	}
 
 	return nil
@@ -215,7 +264,7 @@ func (r *inventoryRepository) ListInventory(ctx context.Context, req *ListInvent
 	offset := (req.Page - 1) * req.Limit
 	query := fmt.Sprintf(`
 		SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
-			   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at
+			   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
 		FROM inventory %s
 		ORDER BY created_at DESC
 		LIMIT $%d OFFSET $%d`, whereClause, argIndex, argIndex+1)
@@ -231,6 +280,42 @@ func (r *inventoryRepository) ListInventory(ctx context.Context, req *ListInvent
 	return inventory, total, nil
 }
 
+// ListProductsNeedingRestock lists stock records of active products that are out of stock or
+// at or below their reorder point, most urgent first: out of stock, then by how far the
+// available quantity has fallen below the reorder point. Active products that track
+// quantity but have no stock record are listed as out of stock.
+func (r *inventoryRepository) ListProductsNeedingRestock(ctx context.Context, page, limit int) ([]*domain.RestockCandidate, int64, error) {
+	whereClause := `
+		FROM products p
+		LEFT JOIN inventory i ON i.product_id = p.id
+		WHERE p.is_active = true
+		  AND (i.id IS NOT NULL OR p.track_quantity = true)
+		  AND COALESCE(i.available_quantity, 0) <= GREATEST(COALESCE(i.reorder_point, 0), 0)`
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) "+whereClause)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count products needing restock: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	query := `
+		SELECT i.id AS inventory_id, p.id AS product_id, i.product_variant_id, p.name AS product_name, p.sku,
+			   COALESCE(i.available_quantity, 0) AS available_quantity,
+			   COALESCE(i.reorder_point, 0) AS reorder_point` + whereClause + `
+		ORDER BY (COALESCE(i.available_quantity, 0) <= 0) DESC,
+			COALESCE(i.available_quantity, 0) - COALESCE(i.reorder_point, 0) ASC, p.id ASC, i.id ASC
+		LIMIT $1 OFFSET $2`
+
+	var candidates []*domain.RestockCandidate
+	err = r.db.SelectContext(ctx, &candidates, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list products needing restock: %w", err)
+	}
+
+	return candidates, total, nil
+}
+
 // GetInventorySummary gets inventory summary statistics
 func (r *inventoryRepository) GetInventorySummary(ctx context.Context) (*domain.InventorySummary, error) {
 	query := `
@@ -260,7 +345,8 @@ func (r *inventoryRepository) GetInventorySummary(ctx context.Context) (*domain.
 
 // Stock Movements
 
-// RecordStockMovement records a stock movement
+// RecordStockMovement records a stock movement. created_by is taken from the
+// authenticated user in ctx and is NULL for system work.
 func (r *inventoryRepository) RecordStockMovement(ctx context.Context, movement *domain.InventoryMovement) error {
 	query := `
 		INSERT INTO inventory_movements (
@@ -271,6 +357,8 @@ func (r *inventoryRepository) RecordStockMovement(ctx context.Context, movement
 			:reference, :reference_type, :reason, :notes, :created_by, :created_at
 		) RETURNING id`
 
+	movement.CreatedBy = userctx.UserIDPtr(ctx)
+
 	rows, err := r.db.NamedQueryContext(ctx, query, movement)
 	if err != nil {
 		return fmt.Errorf("failed to record stock movement: %w", err)
@@ -362,7 +450,7 @@ func (r *inventoryRepository) GetStockMovementByID(ctx context.Context, id int64
 	err := r.db.GetContext(ctx, &movement, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("stock movement with ID %d not found", id)
+			return nil, notFoundf(ErrStockMovementNotFound, "stock movement with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get stock movement: %w", err)
 	}
@@ -370,37 +458,399 @@ func (r *inventoryRepository) GetStockMovementByID(ctx context.Context, id int64
 	return &movement, nil
 }
 
-// Stock Reservations
+// SetInventoryQuantity sets the on-hand quantity to an exact value, typically after
+// a physical stock count, and records the change as an adjustment movement of the
+// difference. Its quantity is always positive; the previous and new quantities carry
+// the direction. A count that raises available stock above the reorder point resolves
+// the item's open alerts. A count that matches the stock on hand changes nothing; the
+// movement returned for it is not recorded and has no ID.
+func (r *inventoryRepository) SetInventoryQuantity(ctx context.Context, productID int64, variantID *int64, newQty int, reason string) (*domain.InventoryMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-// ReserveStock reserves stock for an order
-func (r *inventoryRepository) ReserveStock(ctx context.Context, reservation *domain.StockReservation) error {
-	query := `
-		INSERT INTO stock_reservations (product_id, product_variant_id, order_id, quantity, expires_at, created_at)
-		VALUES (:product_id, :product_variant_id, :order_id, :quantity, :expires_at, :created_at)
-		RETURNING id`
+	inventory, err := lockInventory(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := newQty - inventory.ReservedQuantity
+	if available < 0 {
+		return nil, &ReservedStockConflictError{Requested: newQty, Reserved: inventory.ReservedQuantity}
+	}
+
+	now := time.Now()
+	movement := &domain.InventoryMovement{
+		ProductID:        productID,
+		ProductVariantID: variantID,
+		MovementType:     "adjustment",
+		PreviousQuantity: inventory.Quantity,
+		NewQuantity:      newQty,
+		ReferenceType:    "stock_count",
+		Reason:           reason,
+		CreatedAt:        now,
+	}
+
+	_, moved, changed := stockChange(inventory.Quantity, newQty)
+	if !changed {
+		return movement, nil
+	}
+	movement.Quantity = moved
 
-	rows, err := r.db.NamedQueryContext(ctx, query, reservation)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory SET quantity = $1, available_quantity = $2, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5`, newQty, available, now, userctx.UserIDPtr(ctx), inventory.ID)
 	if err != nil {
-		return fmt.Errorf("failed to reserve stock: %w", err)
+		return nil, fmt.Errorf("failed to update inventory: %w", err)
 	}
-	defer rows.Close()
 
-	if rows.Next() {
-		if err := rows.Scan(&reservation.ID); err != nil {
-			return fmt.Errorf("failed to get reservation ID: %w", err)
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return nil, err
+	}
+
+	if newQty > inventory.Quantity {
+		if err := resolveRecoveredAlerts(ctx, tx, productID, variantID, available, inventory.ReorderPoint, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return movement, nil
+}
+
+// Restock adds received units to on-hand and available stock, stamps last_restocked and
+// records an "in" movement. Open alerts for the item are resolved once available stock
+// is back above the reorder point.
+func (r *inventoryRepository) Restock(ctx context.Context, productID int64, variantID *int64, qty int, reference string) (*domain.InventoryMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inventory, err := lockInventory(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	newQty := inventory.Quantity + qty
+	available := inventory.AvailableQuantity + qty
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory SET quantity = $1, available_quantity = $2, last_restocked = $3, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5`, newQty, available, now, userctx.UserIDPtr(ctx), inventory.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	movement := &domain.InventoryMovement{
+		ProductID:        productID,
+		ProductVariantID: variantID,
+		MovementType:     "in",
+		Quantity:         qty,
+		PreviousQuantity: inventory.Quantity,
+		NewQuantity:      newQty,
+		Reference:        reference,
+		ReferenceType:    "restock",
+		Reason:           "restock",
+		CreatedAt:        now,
+	}
+
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return nil, err
+	}
+
+	if err := resolveRecoveredAlerts(ctx, tx, productID, variantID, available, inventory.ReorderPoint, now); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return movement, nil
+}
+
+// AdjustStock adds delta, which may be negative, to on-hand and available stock and
+// records the change in the same transaction as an "in" or "out" movement of |delta|
+// units. An adjustment that would leave less on hand than is reserved fails with a
+// ReservedStockConflictError.
+func (r *inventoryRepository) AdjustStock(ctx context.Context, productID int64, variantID *int64, delta int, reason, reference string) (*domain.InventoryMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inventory, err := lockInventory(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	newQty := inventory.Quantity + delta
+	available := newQty - inventory.ReservedQuantity
+	if available < 0 {
+		return nil, &ReservedStockConflictError{Requested: newQty, Reserved: inventory.ReservedQuantity}
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory SET quantity = $1, available_quantity = $2, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5`, newQty, available, now, userctx.UserIDPtr(ctx), inventory.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	movementType, moved, _ := stockChange(inventory.Quantity, newQty)
+	movement := &domain.InventoryMovement{
+		ProductID:        productID,
+		ProductVariantID: variantID,
+		MovementType:     movementType,
+		Quantity:         moved,
+		PreviousQuantity: inventory.Quantity,
+		NewQuantity:      newQty,
+		Reference:        reference,
+		ReferenceType:    "adjustment",
+		Reason:           reason,
+		CreatedAt:        now,
+	}
+
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return nil, err
+	}
+
+	if delta > 0 {
+		if err := resolveRecoveredAlerts(ctx, tx, productID, variantID, available, inventory.ReorderPoint, now); err != nil {
+			return nil, err
 		}
 	}
 
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return movement, nil
+}
+
+// commitOrderStock takes every line of an order out of stock inside tx. Reservations the
+// order holds for a line are consumed first and the rest comes from available stock; any
+// reservation beyond the line quantity is released. If any line is short an
+// InsufficientInventoryError is returned and the caller rolls tx back.
+func commitOrderStock(ctx context.Context, tx *sqlx.Tx, orderID int64) ([]*domain.InventoryMovement, error) {
+	// Lock inventory rows in a fixed order so concurrent checkouts cannot deadlock
+	var items []*domain.OrderItem
+	err := tx.SelectContext(ctx, &items, `
+		SELECT product_id, product_variant_id, SUM(quantity) AS quantity
+		FROM order_items WHERE order_id = $1
+		GROUP BY product_id, product_variant_id
+		ORDER BY product_id, product_variant_id NULLS FIRST`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	now := time.Now()
+	reference := strconv.FormatInt(orderID, 10)
+	movements := make([]*domain.InventoryMovement, 0, len(items))
+
+	for _, item := range items {
+		inventory, err := lockInventory(ctx, tx, item.ProductID, item.ProductVariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		var reserved int
+		err = tx.GetContext(ctx, &reserved, `
+			WITH consumed AS (
+				DELETE FROM stock_reservations
+				WHERE order_id = $1 AND product_id = $2 AND product_variant_id IS NOT DISTINCT FROM $3
+				RETURNING quantity
+			)
+			SELECT COALESCE(SUM(quantity), 0) FROM consumed`,
+			orderID, item.ProductID, item.ProductVariantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to consume stock reservations: %w", err)
+		}
+
+		fromAvailable := item.Quantity - min(reserved, item.Quantity)
+		if inventory.AvailableQuantity < fromAvailable {
+			return nil, &InsufficientInventoryError{
+				ProductID:        item.ProductID,
+				ProductVariantID: item.ProductVariantID,
+				Requested:        item.Quantity,
+				Available:        inventory.AvailableQuantity + min(reserved, item.Quantity),
+			}
+		}
+
+		newQty := inventory.Quantity - item.Quantity
+		newReserved := max(inventory.ReservedQuantity-reserved, 0)
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE inventory SET quantity = $1, reserved_quantity = $2, available_quantity = $3, updated_at = $4, updated_by = $5, version = version + 1
+			WHERE id = $6`, newQty, newReserved, newQty-newReserved, now, userctx.UserIDPtr(ctx), inventory.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update inventory: %w", err)
+		}
+
+		movement := &domain.InventoryMovement{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			MovementType:     "out",
+			Quantity:         item.Quantity,
+			PreviousQuantity: inventory.Quantity,
+			NewQuantity:      newQty,
+			Reference:        reference,
+			ReferenceType:    "order",
+			Reason:           "checkout",
+			CreatedAt:        now,
+		}
+
+		if err := insertStockMovement(ctx, tx, movement); err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+// lockInventory loads the inventory row for a product/variant with a row lock so
+// concurrent movements cannot interleave
+func lockInventory(ctx context.Context, tx *sqlx.Tx, productID int64, variantID *int64) (*domain.Inventory, error) {
+	var inventory domain.Inventory
+	var query string
+	var args []interface{}
+
+	if variantID != nil {
+		query = `
+			SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
+				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
+			FROM inventory WHERE product_id = $1 AND product_variant_id = $2 FOR UPDATE`
+		args = []interface{}{productID, *variantID}
+	} else {
+		query = `
+			SELECT id, product_id, product_variant_id, quantity, reserved_quantity, available_quantity,
+				   min_stock_level, max_stock_level, reorder_point, last_restocked, created_at, updated_at, version
+			FROM inventory WHERE product_id = $1 AND product_variant_id IS NULL FOR UPDATE`
+		args = []interface{}{productID}
+	}
+
+	err := tx.GetContext(ctx, &inventory, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrInventoryNotFound, "inventory for product %d not found", productID)
+		}
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	return &inventory, nil
+}
+
+// resolveRecoveredAlerts resolves the open alerts of an item once its available stock is
+// back above the reorder point. Alerts stay open while the item is at or below it, and
+// alerts for other items are never touched.
+func resolveRecoveredAlerts(ctx context.Context, tx *sqlx.Tx, productID int64, variantID *int64, available, reorderPoint int, now time.Time) error {
+	if available <= reorderPoint {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		UPDATE inventory_alerts SET is_resolved = true, resolved_at = $1
+		WHERE product_id = $2 AND product_variant_id IS NOT DISTINCT FROM $3 AND is_resolved = false`,
+		now, productID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve inventory alerts: %w", err)
+	}
+
+	return nil
+}
+
+// stockChange describes a change of on-hand stock from previous to next as a movement.
+// Movement quantities are always positive, so the direction is in the type: "in" for
+// an increase and "out" for a decrease. changed is false when there is nothing to record.
+func stockChange(previous, next int) (movementType string, quantity int, changed bool) {
+	switch {
+	case next > previous:
+		return "in", next - previous, true
+	case next < previous:
+		return "out", previous - next, true
+	default:
+		return "", 0, false
+	}
+}
+
+// insertStockMovement records a stock movement inside a transaction and sets its ID.
+// Like RecordStockMovement, it stamps created_by from ctx.
+func insertStockMovement(ctx context.Context, tx *sqlx.Tx, movement *domain.InventoryMovement) error {
+	movement.CreatedBy = userctx.UserIDPtr(ctx)
+	err := tx.QueryRowxContext(ctx, `
+		INSERT INTO inventory_movements (
+			product_id, product_variant_id, movement_type, quantity, previous_quantity, new_quantity,
+			reference, reference_type, reason, notes, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		movement.ProductID, movement.ProductVariantID, movement.MovementType, movement.Quantity,
+		movement.PreviousQuantity, movement.NewQuantity, movement.Reference, movement.ReferenceType,
+		movement.Reason, movement.Notes, movement.CreatedBy, movement.CreatedAt,
+	).Scan(&movement.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+
 	return nil
 }
 
-// ReleaseStock releases reserved stock by reservation ID
-func (r *inventoryRepository) ReleaseStock(ctx context.Context, reservationID int64) error {
-	query := `DELETE FROM stock_reservations WHERE id = $1`
+// Stock Reservations
+
+// ReserveStock reserves stock for an order. The reservation is recorded and its quantity
+// moved from available to reserved stock in one transaction, with the inventory row
+// locked so concurrent reservations cannot oversell. An InsufficientInventoryError,
+// matching ErrInsufficientStock, is returned when too few units are available.
+func (r *inventoryRepository) ReserveStock(ctx context.Context, reservation *domain.StockReservation) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, reservationID)
+	inventory, err := lockInventory(ctx, tx, reservation.ProductID, reservation.ProductVariantID)
 	if err != nil {
-		return fmt.Errorf("failed to release stock: %w", err)
+		return err
+	}
+
+	if inventory.AvailableQuantity < reservation.Quantity {
+		return &InsufficientInventoryError{
+			ProductID:        reservation.ProductID,
+			ProductVariantID: reservation.ProductVariantID,
+			Requested:        reservation.Quantity,
+			Available:        inventory.AvailableQuantity,
+		}
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO stock_reservations (product_id, product_variant_id, order_id, quantity, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		reservation.ProductID, reservation.ProductVariantID, reservation.OrderID, reservation.Quantity,
+		reservation.ExpiresAt, reservation.CreatedAt,
+	).Scan(&reservation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	// The row lock already serialises reservations; the condition keeps the counters
+	// from going negative even if they were changed outside a lock
+	result, err := tx.ExecContext(ctx, `
+		UPDATE inventory SET
+			reserved_quantity = reserved_quantity + $1, available_quantity = available_quantity - $1,
+			updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND available_quantity >= $1`, reservation.Quantity, time.Now(), userctx.UserIDPtr(ctx), inventory.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update inventory: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -409,19 +859,81 @@ func (r *inventoryRepository) ReleaseStock(ctx context.Context, reservationID in
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("stock reservation with ID %d not found", reservationID)
+		return &InsufficientInventoryError{
+			ProductID:        reservation.ProductID,
+			ProductVariantID: reservation.ProductVariantID,
+			Requested:        reservation.Quantity,
+			Available:        inventory.AvailableQuantity,
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-// ReleaseStockByOrderID releases all reserved stock for an order
-func (r *inventoryRepository) ReleaseStockByOrderID(ctx context.Context, orderID int64) error {
-	query := `DELETE FROM stock_reservations WHERE order_id = $1`
+// ReleaseStock releases reserved stock by reservation ID and returns the stock now
+// available for its item
+func (r *inventoryRepository) ReleaseStock(ctx context.Context, reservationID int64) ([]*domain.StockChange, error) {
+	changes, err := r.releaseReservations(ctx, `id = $1`, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release stock: %w", err)
+	}
+
+	if len(changes) == 0 {
+		return nil, notFoundf(ErrStockReservationNotFound, "stock reservation with ID %d not found", reservationID)
+	}
+
+	return changes, nil
+}
 
-	_, err := r.db.ExecContext(ctx, query, orderID)
+// ReleaseStockByOrderID releases all reserved stock for an order and returns the stock
+// now available for each item it held
+func (r *inventoryRepository) ReleaseStockByOrderID(ctx context.Context, orderID int64) ([]*domain.StockChange, error) {
+	changes, err := r.releaseReservations(ctx, `order_id = $1`, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to release stock by order ID: %w", err)
+		return nil, fmt.Errorf("failed to release stock by order ID: %w", err)
+	}
+
+	return changes, nil
+}
+
+// GetStockReservationByID gets a stock reservation by ID
+func (r *inventoryRepository) GetStockReservationByID(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	var reservation domain.StockReservation
+	query := `
+		SELECT id, product_id, product_variant_id, order_id, quantity, expires_at, created_at
+		FROM stock_reservations WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &reservation, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrStockReservationNotFound, "stock reservation with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get stock reservation: %w", err)
+	}
+
+	return &reservation, nil
+}
+
+// UpdateReservationExpiry moves the expiry of a stock reservation
+func (r *inventoryRepository) UpdateReservationExpiry(ctx context.Context, id int64, expiresAt time.Time) error {
+	query := `UPDATE stock_reservations SET expires_at = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation expiry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundf(ErrStockReservationNotFound, "stock reservation with ID %d not found", id)
 	}
 
 	return nil
@@ -459,16 +971,113 @@ func (r *inventoryRepository) GetExpiredReservations(ctx context.Context, before
 	return reservations, nil
 }
 
-// CleanupExpiredReservations removes expired stock reservations
-func (r *inventoryRepository) CleanupExpiredReservations(ctx context.Context) error {
-	query := `DELETE FROM stock_reservations WHERE expires_at < NOW()`
+// CleanupExpiredReservations removes expired stock reservations, returns their stock to
+// inventory and reports the stock now available for each item they held
+func (r *inventoryRepository) CleanupExpiredReservations(ctx context.Context) ([]*domain.StockChange, error) {
+	changes, err := r.releaseReservations(ctx, `expires_at < NOW()`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cleanup expired reservations: %w", err)
+	}
+
+	return changes, nil
+}
 
-	_, err := r.db.ExecContext(ctx, query)
+// RecomputeReservedQuantities recalculates the reserved and available counts of stock
+// records from the reservations that hold them, for one product or, with a nil
+// productID, every product. Only records that had drifted are written; they are
+// returned with their counts before and after. A record whose reservations exceed its
+// quantity cannot be corrected without breaking the available_quantity check and is
+// left alone.
+//
+// The stock records are locked before their reservations are summed, so a reservation
+// being made or released waits until the correction commits. The sum is taken by a
+// later statement than the lock, so it sees every reservation committed before the lock
+// was granted; reservations still in flight adjust the corrected counts afterwards.
+func (r *inventoryRepository) RecomputeReservedQuantities(ctx context.Context, productID *int64) ([]*domain.InventoryDiscrepancy, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired reservations: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	_, err = tx.ExecContext(ctx, `
+		SELECT id FROM inventory
+		WHERE $1::BIGINT IS NULL OR product_id = $1
+		ORDER BY id
+		FOR UPDATE`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock inventory: %w", err)
+	}
+
+	query := `
+		WITH held AS (
+			SELECT i.id, i.reserved_quantity, i.available_quantity, COALESCE(SUM(s.quantity), 0) AS reserved
+			FROM inventory i
+			LEFT JOIN stock_reservations s
+				ON s.product_id = i.product_id AND s.product_variant_id IS NOT DISTINCT FROM i.product_variant_id
+			WHERE $1::BIGINT IS NULL OR i.product_id = $1
+			GROUP BY i.id
+		)
+		UPDATE inventory i SET
+			reserved_quantity = h.reserved,
+			available_quantity = i.quantity - h.reserved,
+			updated_at = NOW(),
+			version = i.version + 1
+		FROM held h
+		WHERE i.id = h.id AND h.reserved <= i.quantity
+		AND (h.reserved_quantity <> h.reserved OR h.available_quantity <> i.quantity - h.reserved)
+		RETURNING i.id AS inventory_id, i.product_id, i.product_variant_id, i.quantity,
+			h.reserved_quantity AS previous_reserved_quantity, h.available_quantity AS previous_available_quantity,
+			i.reserved_quantity, i.available_quantity`
+
+	discrepancies := []*domain.InventoryDiscrepancy{}
+	if err := tx.SelectContext(ctx, &discrepancies, query, productID); err != nil {
+		return nil, fmt.Errorf("failed to recompute reserved quantities: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return discrepancies, nil
+}
+
+// releaseReservations deletes the reservations matching condition and hands their
+// quantity back to inventory in a single statement, resolving the open alerts of items
+// whose available stock is now above the reorder point. It returns the stock now
+// available for each item that had reservations released.
+func (r *inventoryRepository) releaseReservations(ctx context.Context, condition string, args ...interface{}) ([]*domain.StockChange, error) {
+	query := `
+		WITH released AS (
+			DELETE FROM stock_reservations WHERE ` + condition + `
+			RETURNING product_id, product_variant_id, quantity
+		), totals AS (
+			SELECT product_id, product_variant_id, SUM(quantity) AS quantity
+			FROM released GROUP BY product_id, product_variant_id
+		), restored AS (
+			UPDATE inventory i SET
+				reserved_quantity = GREATEST(i.reserved_quantity - t.quantity, 0),
+				available_quantity = i.quantity - GREATEST(i.reserved_quantity - t.quantity, 0),
+				updated_at = NOW(),
+				version = i.version + 1
+			FROM totals t
+			WHERE i.product_id = t.product_id AND i.product_variant_id IS NOT DISTINCT FROM t.product_variant_id
+			RETURNING i.product_id, i.product_variant_id, i.available_quantity, i.reorder_point
+		), resolved AS (
+			UPDATE inventory_alerts a SET is_resolved = true, resolved_at = NOW()
+			FROM restored r
+			WHERE a.product_id = r.product_id AND a.product_variant_id IS NOT DISTINCT FROM r.product_variant_id
+			AND a.is_resolved = false AND r.available_quantity > r.reorder_point
+			RETURNING a.id
+		)
+		SELECT product_id, product_variant_id, available_quantity FROM restored`
+
+	changes := []*domain.StockChange{}
+	if err := r.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
 }
 
 // Inventory Alerts
@@ -534,7 +1143,7 @@ func (r *inventoryRepository) ResolveInventoryAlert(ctx context.Context, alertID
 	now := time.Now()
 	query := `
 		UPDATE inventory_alerts 
-		SET is_resolved = true, resolved_at = $1, updated_at = $1
+		SET is_resolved = true, resolved_at = $1
 		WHERE id = $2`
 
 	result, err := r.db.ExecContext(ctx, query, now, alertID)
@@ -548,57 +1157,350 @@ func (r *inventoryRepository) ResolveInventoryAlert(ctx context.Context, alertID
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("inventory alert with ID %d not found", alertID)
+		return notFoundf(ErrInventoryAlertNotFound, "inventory alert with ID %d not found", alertID)
 	}
 
 	return nil
 }
 
-// CheckLowStockAlerts checks for low stock and creates alerts
-func (r *inventoryRepository) CheckLowStockAlerts(ctx context.Context) error {
-	// This would typically be called by a background job
-	// For now, we'll implement a simple check
+// stockAlertCheck is an inventory record's stock level and the types of its open alerts
+type stockAlertCheck struct {
+	ProductID         int64          `db:"product_id"`
+	ProductVariantID  *int64         `db:"product_variant_id"`
+	AvailableQuantity int            `db:"available_quantity"`
+	MinStockLevel     int            `db:"min_stock_level"`
+	ReorderPoint      int            `db:"reorder_point"`
+	OpenAlerts        pq.StringArray `db:"open_alerts"`
+}
+
+// CheckLowStockAlerts raises and resolves stock alerts for items at or below their reorder
+// point and items with open alerts, returning the alerts raised. It is run periodically by
+// the maintenance scheduler; see stockAlertChanges for when each alert type is raised and
+// resolved.
+func (r *inventoryRepository) CheckLowStockAlerts(ctx context.Context) ([]*domain.InventoryAlert, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO inventory_alerts (product_id, product_variant_id, alert_type, current_quantity, threshold_quantity, is_resolved, created_at)
-		SELECT 
-			i.product_id, i.product_variant_id, 'low_stock', i.available_quantity, i.reorder_point, false, NOW()
+		SELECT i.product_id, i.product_variant_id, i.available_quantity, i.min_stock_level, i.reorder_point,
+			   COALESCE(array_agg(ia.alert_type) FILTER (WHERE ia.id IS NOT NULL), '{}') AS open_alerts
 		FROM inventory i
-		WHERE i.available_quantity <= i.reorder_point 
-		AND i.available_quantity > 0
-		AND NOT EXISTS (
-			SELECT 1 FROM inventory_alerts ia 
-			WHERE ia.product_id = i.product_id 
-			AND ia.product_variant_id = i.product_variant_id 
-			AND ia.alert_type = 'low_stock' 
+		LEFT JOIN inventory_alerts ia
+			ON ia.product_id = i.product_id
+			AND ia.product_variant_id IS NOT DISTINCT FROM i.product_variant_id
 			AND ia.is_resolved = false
-		)`
+		GROUP BY i.id
+		HAVING i.available_quantity <= i.reorder_point OR COUNT(ia.id) > 0`
 
-	_, err := r.db.ExecContext(ctx, query)
+	var checks []stockAlertCheck
+	err = tx.SelectContext(ctx, &checks, query)
 	if err != nil {
-		return fmt.Errorf("failed to check low stock alerts: %w", err)
+		return nil, fmt.Errorf("failed to check low stock alerts: %w", err)
+	}
+
+	now := time.Now()
+	var raised []*domain.InventoryAlert
+	for _, check := range checks {
+		raise, resolve := stockAlertChanges(check.AvailableQuantity, check.ReorderPoint, check.MinStockLevel, check.OpenAlerts)
+
+		if len(resolve) > 0 {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE inventory_alerts SET is_resolved = true, resolved_at = $1
+				WHERE product_id = $2 AND product_variant_id IS NOT DISTINCT FROM $3
+				AND alert_type = ANY($4) AND is_resolved = false`,
+				now, check.ProductID, check.ProductVariantID, pq.Array(resolve))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve inventory alerts: %w", err)
+			}
+		}
+
+		for _, alertType := range raise {
+			alert := &domain.InventoryAlert{
+				ProductID:         check.ProductID,
+				ProductVariantID:  check.ProductVariantID,
+				AlertType:         alertType,
+				CurrentQuantity:   max(check.AvailableQuantity, 0),
+				ThresholdQuantity: stockAlertThreshold(alertType, check.ReorderPoint, check.MinStockLevel),
+				CreatedAt:         now,
+			}
+
+			// The one-open-alert index turns a concurrent check's duplicate into a no-op
+			err = tx.QueryRowxContext(ctx, `
+				INSERT INTO inventory_alerts (product_id, product_variant_id, alert_type, current_quantity, threshold_quantity, is_resolved, created_at)
+				VALUES ($1, $2, $3, $4, $5, false, $6)
+				ON CONFLICT DO NOTHING
+				RETURNING id`,
+				alert.ProductID, alert.ProductVariantID, alert.AlertType, alert.CurrentQuantity, alert.ThresholdQuantity, alert.CreatedAt,
+			).Scan(&alert.ID)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to create inventory alert: %w", err)
+			}
+			raised = append(raised, alert)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return raised, nil
+}
+
+// stockAlertChanges works out which alerts to raise and which open ones to resolve for an
+// item's available stock. An item with no available stock is out of stock until any stock
+// is available again. One at or below its reorder point is low on stock, and should be
+// restocked once it has also fallen to its minimum stock level; both last until stock
+// recovers above the reorder point, so an item that runs out keeps its low stock alert.
+// Alert types already open are never raised again.
+func stockAlertChanges(available, reorderPoint, minStockLevel int, open []string) (raise, resolve []string) {
+	rules := []struct {
+		alertType string
+		triggered bool
+		recovered bool
+	}{
+		{domain.AlertTypeOutOfStock, available <= 0, available > 0},
+		{domain.AlertTypeLowStock, available > 0 && available <= reorderPoint, available > reorderPoint},
+		{domain.AlertTypeRestockRecommended, available <= reorderPoint && available <= minStockLevel, available > reorderPoint},
+	}
+
+	isOpen := make(map[string]bool, len(open))
+	for _, alertType := range open {
+		isOpen[alertType] = true
+	}
+
+	for _, rule := range rules {
+		switch {
+		case isOpen[rule.alertType] && rule.recovered:
+			resolve = append(resolve, rule.alertType)
+		case !isOpen[rule.alertType] && rule.triggered:
+			raise = append(raise, rule.alertType)
+		}
+	}
+
+	return raise, resolve
+}
+
+// stockAlertThreshold is the stock level an alert of alertType is raised at
+func stockAlertThreshold(alertType string, reorderPoint, minStockLevel int) int {
+	switch alertType {
+	case domain.AlertTypeLowStock:
+		return reorderPoint
+	case domain.AlertTypeRestockRecommended:
+		return minStockLevel
+	default:
+		return 0
+	}
+}
+
+// Stock Notifications
+
+// CreateStockNotification subscribes a user or email to an item coming back in stock.
+// It returns ErrStockNotificationExists if the subscriber is already waiting for the item.
+func (r *inventoryRepository) CreateStockNotification(ctx context.Context, notification *domain.StockNotification) error {
+	query := `
+		INSERT INTO stock_notifications (user_id, email, product_id, product_variant_id, created_at)
+		VALUES (:user_id, :email, :product_id, :product_variant_id, :created_at)
+		RETURNING id`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, notification)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrStockNotificationExists
+		}
+		return fmt.Errorf("failed to create stock notification: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&notification.ID); err != nil {
+			return fmt.Errorf("failed to get stock notification ID: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteStockNotification removes userID's stock notification subscription. It returns
+// ErrStockNotificationNotFound if there is none with the ID or it belongs to someone else.
+func (r *inventoryRepository) DeleteStockNotification(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM stock_notifications WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete stock notification: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrStockNotificationNotFound
 	}
 
 	return nil
 }
 
+// ClaimStockNotifications marks the pending subscribers of an item as notified and returns
+// them, provided the item has stock available. Claiming and marking happen in one statement
+// so each subscriber is returned to exactly one caller.
+func (r *inventoryRepository) ClaimStockNotifications(ctx context.Context, productID int64, variantID *int64) ([]*domain.StockNotification, error) {
+	query := `
+		UPDATE stock_notifications SET notified_at = NOW()
+		WHERE product_id = $1 AND product_variant_id IS NOT DISTINCT FROM $2 AND notified_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM inventory
+			WHERE product_id = $1 AND product_variant_id IS NOT DISTINCT FROM $2 AND available_quantity > 0
+		)
+		RETURNING id, user_id, email, product_id, product_variant_id, created_at, notified_at`
+
+	var notifications []*domain.StockNotification
+	if err := r.db.SelectContext(ctx, &notifications, query, productID, variantID); err != nil {
+		return nil, fmt.Errorf("failed to claim stock notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
 // Bulk Operations
 
-// BulkUpdateStock performs bulk stock updates
-func (r *inventoryRepository) BulkUpdateStock(ctx context.Context, updates []StockUpdateItem) (*BulkStockUpdateResponse, error) {
-	// This would typically use a transaction for atomicity
-	// For now, we'll implement a simple version
+// BulkUpdateStock applies a batch of stock updates in one transaction, recording a
+// movement for each one that changes stock. An item that cannot be applied is rolled back on its own and
+// reported in FailedItems while the rest of the batch commits, unless atomic is set, in
+// which case any failure rolls back the whole batch.
+func (r *inventoryRepository) BulkUpdateStock(ctx context.Context, updates []StockUpdateItem, atomic bool) (*BulkStockUpdateResponse, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	response := &BulkStockUpdateResponse{
-		UpdatedItems: 0,
-		FailedItems:  []FailedStockUpdateItem{},
-		Success:      true,
+		FailedItems: []FailedStockUpdateItem{},
+	}
+	now := time.Now()
+
+	for _, update := range updates {
+		// A savepoint lets a failed item be undone without aborting the transaction
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_stock_item"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		movement, err := applyStockUpdate(ctx, tx, update, now)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_stock_item"); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back savepoint: %w", rbErr)
+			}
+			response.FailedItems = append(response.FailedItems, FailedStockUpdateItem{
+				ProductID:        update.ProductID,
+				ProductVariantID: update.ProductVariantID,
+				Error:            err.Error(),
+			})
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_stock_item"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		if movement != nil {
+			response.Movements = append(response.Movements, movement)
+		}
+	}
+
+	response.Success = len(response.FailedItems) == 0
+	if atomic && !response.Success {
+		response.Movements = nil
+		return response, nil
 	}
 
-	// TODO: Implement bulk update logic with proper transaction handling
-	// This is a placeholder implementation
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
+	response.UpdatedItems = int64(len(response.Movements))
 	return response, nil
 }
 
+// applyStockUpdate applies one bulk stock update to its locked inventory row. "in" and
+// "out" add or remove Quantity units and "adjustment" sets on-hand stock to Quantity,
+// which may be zero. An adjustment is recorded as the "in" or "out" movement of the
+// difference; one that leaves stock as it was changes nothing and returns no movement.
+func applyStockUpdate(ctx context.Context, tx *sqlx.Tx, update StockUpdateItem, now time.Time) (*domain.InventoryMovement, error) {
+	if update.Quantity < 0 {
+		return nil, fmt.Errorf("quantity cannot be negative: %d", update.Quantity)
+	}
+
+	inventory, err := lockInventory(ctx, tx, update.ProductID, update.ProductVariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newQty int
+	switch update.MovementType {
+	case "in":
+		newQty = inventory.Quantity + update.Quantity
+	case "out":
+		if update.Quantity > inventory.AvailableQuantity {
+			return nil, &InsufficientInventoryError{
+				ProductID:        update.ProductID,
+				ProductVariantID: update.ProductVariantID,
+				Requested:        update.Quantity,
+				Available:        inventory.AvailableQuantity,
+			}
+		}
+		newQty = inventory.Quantity - update.Quantity
+	case "adjustment":
+		newQty = update.Quantity
+	default:
+		return nil, fmt.Errorf("invalid movement type: %s", update.MovementType)
+	}
+
+	movementType, moved, changed := stockChange(inventory.Quantity, newQty)
+	if !changed {
+		return nil, nil
+	}
+
+	available := newQty - inventory.ReservedQuantity
+	if available < 0 {
+		return nil, &ReservedStockConflictError{Requested: newQty, Reserved: inventory.ReservedQuantity}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory SET quantity = $1, available_quantity = $2, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5`, newQty, available, now, userctx.UserIDPtr(ctx), inventory.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	movement := &domain.InventoryMovement{
+		ProductID:        update.ProductID,
+		ProductVariantID: update.ProductVariantID,
+		MovementType:     movementType,
+		Quantity:         moved,
+		PreviousQuantity: inventory.Quantity,
+		NewQuantity:      newQty,
+		ReferenceType:    "bulk_update",
+		Reason:           update.Reason,
+		Notes:            update.Notes,
+		CreatedAt:        now,
+	}
+
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return nil, err
+	}
+
+	if err := resolveRecoveredAlerts(ctx, tx, update.ProductID, update.ProductVariantID, available, inventory.ReorderPoint, now); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
 // Additional types for repository
 type ListInventoryRequest struct {
 	ProductID        *int64
@@ -632,6 +1534,8 @@ type BulkStockUpdateResponse struct {
 	UpdatedItems int64
 	FailedItems  []FailedStockUpdateItem
 	Success      bool
+	// Movements are the movements recorded for the committed items
+	Movements []*domain.InventoryMovement
 }
 
 type FailedStockUpdateItem struct {
@@ -0,0 +1,1195 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryRepository_CreateInventory_Duplicate(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO inventory`).
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	err := repo.CreateInventory(context.Background(), &domain.Inventory{
+		ProductID:     1,
+		Quantity:      10,
+		LastRestocked: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+
+	assert.ErrorIs(t, err, ErrInventoryExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_SetInventoryQuantity(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+
+	expectLockedInventory := func(mock sqlmock.Sqlmock, quantity, reserved int) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).
+				AddRow(7, 1, nil, quantity, reserved, quantity-reserved, 5, 100, 10, now, now, now))
+	}
+
+	t.Run("increase", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock, 10, 2)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(25, 23, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "adjustment", 15, 10, 25, "", "stock_count", "cycle count", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		// 23 available is above the reorder point of 10, so open alerts are resolved
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+			`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND is_resolved = false`).
+			WithArgs(sqlmock.AnyArg(), int64(1), nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		movement, err := repo.SetInventoryQuantity(context.Background(), 1, nil, 25, "cycle count")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(99), movement.ID)
+		assert.Equal(t, "adjustment", movement.MovementType)
+		assert.Equal(t, 15, movement.Quantity)
+		assert.Equal(t, 10, movement.PreviousQuantity)
+		assert.Equal(t, 25, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("decrease", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock, 10, 2)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(4, 2, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "adjustment", 6, 10, 4, "", "stock_count", "damaged", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+		mock.ExpectCommit()
+
+		movement, err := repo.SetInventoryQuantity(context.Background(), 1, nil, 4, "damaged")
+
+		require.NoError(t, err)
+		assert.Equal(t, "adjustment", movement.MovementType)
+		assert.Equal(t, 6, movement.Quantity)
+		assert.Equal(t, 4, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("count matches stock on hand", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock, 10, 2)
+		mock.ExpectRollback()
+
+		movement, err := repo.SetInventoryQuantity(context.Background(), 1, nil, 10, "cycle count")
+
+		require.NoError(t, err)
+		assert.Zero(t, movement.ID)
+		assert.Zero(t, movement.Quantity)
+		assert.Equal(t, 10, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stamps the authenticated user", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock, 10, 2)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(25, 23, sqlmock.AnyArg(), int64(42), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "adjustment", 15, 10, 25, "", "stock_count", "cycle count", "", int64(42), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		ctx := userctx.WithUserID(context.Background(), 42)
+		movement, err := repo.SetInventoryQuantity(ctx, 1, nil, 25, "cycle count")
+
+		require.NoError(t, err)
+		require.NotNil(t, movement.CreatedBy)
+		assert.Equal(t, int64(42), *movement.CreatedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("below reserved quantity", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock, 10, 5)
+		mock.ExpectRollback()
+
+		movement, err := repo.SetInventoryQuantity(context.Background(), 1, nil, 3, "recount")
+
+		assert.Nil(t, movement)
+		var conflictErr *ReservedStockConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, 3, conflictErr.Requested)
+		assert.Equal(t, 5, conflictErr.Reserved)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_Restock(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+
+	// 10 on hand, 2 reserved, reorder point 10
+	expectLockedInventory := func(mock sqlmock.Sqlmock) {
+		lastRestocked := time.Now().Add(-30 * 24 * time.Hour)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).
+				AddRow(7, 1, nil, 10, 2, 8, 5, 100, 10, lastRestocked, lastRestocked, lastRestocked))
+	}
+
+	t.Run("updates quantities, logs movement and resolves alerts", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, last_restocked = \$3, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(15, 13, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 5, 10, 15, "PO-1001", "restock", "restock", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+			`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND is_resolved = false`).
+			WithArgs(sqlmock.AnyArg(), int64(1), nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		movement, err := repo.Restock(context.Background(), 1, nil, 5, "PO-1001")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(99), movement.ID)
+		assert.Equal(t, "in", movement.MovementType)
+		assert.Equal(t, 5, movement.Quantity)
+		assert.Equal(t, 10, movement.PreviousQuantity)
+		assert.Equal(t, 15, movement.NewQuantity)
+		assert.Equal(t, "PO-1001", movement.Reference)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps alerts open at or below reorder point", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, last_restocked = \$3, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(12, 10, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 2, 10, 12, "", "restock", "restock", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+		mock.ExpectCommit()
+
+		movement, err := repo.Restock(context.Background(), 1, nil, 2, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, 12, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("inventory not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns))
+		mock.ExpectRollback()
+
+		movement, err := repo.Restock(context.Background(), 1, nil, 5, "")
+
+		assert.Nil(t, movement)
+		assert.ErrorContains(t, err, "not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_AdjustStock(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+
+	// 10 on hand, 4 reserved, reorder point 10
+	expectLockedInventory := func(mock sqlmock.Sqlmock) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).
+				AddRow(7, 1, nil, 10, 4, 6, 5, 100, 10, now, now, now))
+	}
+
+	t.Run("positive restock", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(18, 14, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 8, 10, 18, "RMA-12", "adjustment", "returned to stock", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		// 14 available is above the reorder point, so open alerts are resolved
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+			`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND is_resolved = false`).
+			WithArgs(sqlmock.AnyArg(), int64(1), nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		movement, err := repo.AdjustStock(context.Background(), 1, nil, 8, "returned to stock", "RMA-12")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(99), movement.ID)
+		assert.Equal(t, "in", movement.MovementType)
+		assert.Equal(t, 8, movement.Quantity)
+		assert.Equal(t, 10, movement.PreviousQuantity)
+		assert.Equal(t, 18, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("negative adjustment down to reserved", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(4, 0, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "out", 6, 10, 4, "", "adjustment", "damaged", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+		mock.ExpectCommit()
+
+		movement, err := repo.AdjustStock(context.Background(), 1, nil, -6, "damaged", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "out", movement.MovementType)
+		assert.Equal(t, 6, movement.Quantity)
+		assert.Equal(t, 4, movement.NewQuantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("negative adjustment that would oversell", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+		// Nothing is written; the transaction is rolled back
+		mock.ExpectRollback()
+
+		movement, err := repo.AdjustStock(context.Background(), 1, nil, -7, "damaged", "")
+
+		assert.Nil(t, movement)
+		var conflictErr *ReservedStockConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, 3, conflictErr.Requested)
+		assert.Equal(t, 4, conflictErr.Reserved)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_CleanupExpiredReservations(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+
+	// Expired reservations are deleted, their quantity handed back to inventory and
+	// alerts resolved for items that recovered
+	mock.ExpectQuery(`WITH released AS \( DELETE FROM stock_reservations WHERE expires_at < NOW\(\) ` +
+		`RETURNING product_id, product_variant_id, quantity \).*` +
+		`UPDATE inventory i SET reserved_quantity = GREATEST\(i\.reserved_quantity - t\.quantity, 0\), ` +
+		`available_quantity = i\.quantity - GREATEST\(i\.reserved_quantity - t\.quantity, 0\).*` +
+		`UPDATE inventory_alerts a SET is_resolved = true, resolved_at = NOW\(\) FROM restored r .*` +
+		`AND a\.is_resolved = false AND r\.available_quantity > r\.reorder_point.*` +
+		`SELECT product_id, product_variant_id, available_quantity FROM restored`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_variant_id", "available_quantity"}).
+			AddRow(1, nil, 4).
+			AddRow(2, 3, 9))
+
+	changes, err := repo.CleanupExpiredReservations(context.Background())
+
+	require.NoError(t, err)
+	variantID := int64(3)
+	assert.Equal(t, []*domain.StockChange{
+		{ProductID: 1, AvailableQuantity: 4},
+		{ProductID: 2, ProductVariantID: &variantID, AvailableQuantity: 9},
+	}, changes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_ReleaseStock_NotFound(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+
+	mock.ExpectQuery(`DELETE FROM stock_reservations WHERE id = \$1`).
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_variant_id", "available_quantity"}))
+
+	_, err := repo.ReleaseStock(context.Background(), 42)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_CreateStockNotification(t *testing.T) {
+	userID := int64(42)
+
+	t.Run("creates subscription", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectQuery(`INSERT INTO stock_notifications`).
+			WithArgs(userID, "", int64(1), nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+		notification := &domain.StockNotification{UserID: &userID, ProductID: 1, CreatedAt: time.Now()}
+		err := repo.CreateStockNotification(context.Background(), notification)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), notification.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already subscribed", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectQuery(`INSERT INTO stock_notifications`).
+			WillReturnError(&pq.Error{Code: "23505"})
+
+		err := repo.CreateStockNotification(context.Background(), &domain.StockNotification{UserID: &userID, ProductID: 1})
+
+		assert.ErrorIs(t, err, ErrStockNotificationExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_DeleteStockNotification(t *testing.T) {
+	t.Run("deletes the user's subscription", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectExec(`DELETE FROM stock_notifications WHERE id = \$1 AND user_id = \$2`).
+			WithArgs(int64(9), int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteStockNotification(context.Background(), 9, 42)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("another user's subscription is not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectExec(`DELETE FROM stock_notifications WHERE id = \$1 AND user_id = \$2`).
+			WithArgs(int64(9), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteStockNotification(context.Background(), 9, 7)
+
+		assert.ErrorIs(t, err, ErrStockNotificationNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_ClaimStockNotifications(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+	variantID := int64(20)
+	now := time.Now()
+	mock.ExpectQuery(`UPDATE stock_notifications SET notified_at = NOW\(\) `+
+		`WHERE product_id = \$1 AND product_variant_id IS NOT DISTINCT FROM \$2 AND notified_at IS NULL `+
+		`AND EXISTS \( SELECT 1 FROM inventory .* available_quantity > 0 \) RETURNING`).
+		WithArgs(int64(1), variantID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "email", "product_id", "product_variant_id", "created_at", "notified_at"}).
+			AddRow(3, nil, "guest@example.com", 1, variantID, now, now))
+
+	notifications, err := repo.ClaimStockNotifications(context.Background(), 1, &variantID)
+
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "guest@example.com", notifications[0].Email)
+	assert.NotNil(t, notifications[0].NotifiedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_RecordStockMovement_CreatedBy(t *testing.T) {
+	newMovement := func() *domain.InventoryMovement {
+		return &domain.InventoryMovement{ProductID: 1, MovementType: "in", Quantity: 5, PreviousQuantity: 10, NewQuantity: 15, CreatedAt: time.Now()}
+	}
+
+	t.Run("authenticated request records the user", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 5, 10, 15, "", "", "", "", int64(42), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+
+		movement := newMovement()
+		err := repo.RecordStockMovement(userctx.WithUserID(context.Background(), 42), movement)
+
+		require.NoError(t, err)
+		require.NotNil(t, movement.CreatedBy)
+		assert.Equal(t, int64(42), *movement.CreatedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("system work records no user", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 5, 10, 15, "", "", "", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+
+		movement := newMovement()
+		err := repo.RecordStockMovement(context.Background(), movement)
+
+		require.NoError(t, err)
+		assert.Nil(t, movement.CreatedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_UpdateInventory_Version(t *testing.T) {
+	updateQuery := `UPDATE inventory SET .* version = version \+ 1 WHERE id = \? AND version = \?`
+	newInventory := func() *domain.Inventory {
+		return &domain.Inventory{ID: 7, ProductID: 1, Quantity: 20, AvailableQuantity: 20, ReorderPoint: 10, Version: 3}
+	}
+
+	t.Run("fresh version is applied and bumped", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).
+			WithArgs(20, 0, 20, 0, 0, 10, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, int64(7), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		inventory := newInventory()
+		err := repo.UpdateInventory(context.Background(), inventory)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, inventory.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM inventory WHERE id = \$1\)`).
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		inventory := newInventory()
+		err := repo.UpdateInventory(context.Background(), inventory)
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, 3, inventory.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing row is not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM inventory WHERE id = \$1\)`).
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		err := repo.UpdateInventory(context.Background(), newInventory())
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrVersionConflict)
+		assert.Contains(t, err.Error(), "not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_UpdateInventory_ResolvesAlerts(t *testing.T) {
+	updateQuery := `UPDATE inventory SET .* WHERE id = \? AND version = \?`
+	variantID := int64(3)
+
+	t.Run("above reorder point resolves the item's open alerts", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+		// Scoped to this product and variant so alerts for other items stay open
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+			`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND is_resolved = false`).
+			WithArgs(sqlmock.AnyArg(), int64(1), &variantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		inventory := &domain.Inventory{ID: 7, ProductID: 1, ProductVariantID: &variantID, Quantity: 25, AvailableQuantity: 25, ReorderPoint: 10, Version: 1}
+		err := repo.UpdateInventory(context.Background(), inventory)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("at reorder point keeps alerts open", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		inventory := &domain.Inventory{ID: 7, ProductID: 1, ProductVariantID: &variantID, Quantity: 10, AvailableQuantity: 10, ReorderPoint: 10, Version: 1}
+		err := repo.UpdateInventory(context.Background(), inventory)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStockAlertChanges_Transitions(t *testing.T) {
+	const reorderPoint, minStockLevel = 10, 3
+
+	// Walks one item from in stock down to out of stock and back, carrying its open alerts
+	// from step to step as CheckLowStockAlerts would. Steps reached by a stock count also
+	// count the item through SetInventoryQuantity, which must resolve its alerts itself.
+	steps := []struct {
+		name        string
+		available   int
+		stockCount  bool
+		wantRaise   []string
+		wantResolve []string
+		wantOpen    []string
+	}{
+		{name: "in stock", available: 25},
+		{
+			name:      "low",
+			available: 8,
+			wantRaise: []string{domain.AlertTypeLowStock},
+			wantOpen:  []string{domain.AlertTypeLowStock},
+		},
+		{
+			name:      "still low",
+			available: 6,
+			wantOpen:  []string{domain.AlertTypeLowStock},
+		},
+		{
+			name:      "down to minimum stock",
+			available: 3,
+			wantRaise: []string{domain.AlertTypeRestockRecommended},
+			wantOpen:  []string{domain.AlertTypeLowStock, domain.AlertTypeRestockRecommended},
+		},
+		{
+			name:      "out",
+			available: 0,
+			wantRaise: []string{domain.AlertTypeOutOfStock},
+			wantOpen:  []string{domain.AlertTypeLowStock, domain.AlertTypeOutOfStock, domain.AlertTypeRestockRecommended},
+		},
+		{
+			name:        "restocked",
+			available:   40,
+			wantResolve: []string{domain.AlertTypeOutOfStock, domain.AlertTypeLowStock, domain.AlertTypeRestockRecommended},
+		},
+		{name: "still in stock", available: 35},
+		{
+			name:      "low again",
+			available: 8,
+			wantRaise: []string{domain.AlertTypeLowStock},
+			wantOpen:  []string{domain.AlertTypeLowStock},
+		},
+		{
+			name:      "out again",
+			available: 0,
+			wantRaise: []string{domain.AlertTypeOutOfStock, domain.AlertTypeRestockRecommended},
+			wantOpen:  []string{domain.AlertTypeLowStock, domain.AlertTypeOutOfStock, domain.AlertTypeRestockRecommended},
+		},
+		{
+			name:        "counted back in stock",
+			available:   30,
+			stockCount:  true,
+			wantResolve: []string{domain.AlertTypeOutOfStock, domain.AlertTypeLowStock, domain.AlertTypeRestockRecommended},
+		},
+	}
+
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+	countStock := func(t *testing.T, from, to int) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).
+				AddRow(7, 1, nil, from, 0, from, minStockLevel, 100, reorderPoint, now, now, now))
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1`).
+			WithArgs(to, to, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+			`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND is_resolved = false`).
+			WithArgs(sqlmock.AnyArg(), int64(1), nil).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		_, err := NewInventoryRepository(db).SetInventoryQuantity(context.Background(), 1, nil, to, "cycle count")
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+
+	var open []string
+	previous := 0
+	for _, step := range steps {
+		if step.stockCount {
+			countStock(t, previous, step.available)
+		}
+		previous = step.available
+
+		raise, resolve := stockAlertChanges(step.available, reorderPoint, minStockLevel, open)
+
+		assert.ElementsMatch(t, step.wantRaise, raise, "raised when %s", step.name)
+		assert.ElementsMatch(t, step.wantResolve, resolve, "resolved when %s", step.name)
+
+		var next []string
+		for _, alertType := range open {
+			if !slices.Contains(resolve, alertType) {
+				next = append(next, alertType)
+			}
+		}
+		open = append(next, raise...)
+		assert.ElementsMatch(t, step.wantOpen, open, "open when %s", step.name)
+	}
+}
+
+func TestStockAlertChanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		available   int
+		open        []string
+		wantRaise   []string
+		wantResolve []string
+	}{
+		{
+			name:      "straight to out of stock",
+			available: 0,
+			wantRaise: []string{domain.AlertTypeOutOfStock, domain.AlertTypeRestockRecommended},
+		},
+		{
+			name:        "partly restocked after running out",
+			available:   5,
+			open:        []string{domain.AlertTypeOutOfStock, domain.AlertTypeRestockRecommended},
+			wantRaise:   []string{domain.AlertTypeLowStock},
+			wantResolve: []string{domain.AlertTypeOutOfStock},
+		},
+		{
+			name:      "at reorder point",
+			available: 10,
+			open:      []string{domain.AlertTypeLowStock},
+		},
+		{
+			name:      "other alert types are left alone",
+			available: 25,
+			open:      []string{"reorder_point"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raise, resolve := stockAlertChanges(tt.available, 10, 3, tt.open)
+
+			assert.ElementsMatch(t, tt.wantRaise, raise)
+			assert.ElementsMatch(t, tt.wantResolve, resolve)
+		})
+	}
+}
+
+func TestInventoryRepository_CheckLowStockAlerts(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+	variantID := int64(4)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT i.product_id, .* FROM inventory i LEFT JOIN inventory_alerts ia .* ` +
+		`HAVING i.available_quantity <= i.reorder_point OR COUNT\(ia.id\) > 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_variant_id", "available_quantity", "min_stock_level", "reorder_point", "open_alerts"}).
+			// Ran out since the last check
+			AddRow(1, nil, 0, 3, 10, "{low_stock}").
+			// Restocked since the last check
+			AddRow(2, variantID, 40, 3, 10, "{low_stock,out_of_stock}"))
+	mock.ExpectQuery(`INSERT INTO inventory_alerts .* ON CONFLICT DO NOTHING RETURNING id`).
+		WithArgs(int64(1), nil, domain.AlertTypeOutOfStock, 0, 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	// A concurrent check raised this one first
+	mock.ExpectQuery(`INSERT INTO inventory_alerts .* ON CONFLICT DO NOTHING RETURNING id`).
+		WithArgs(int64(1), nil, domain.AlertTypeRestockRecommended, 0, 3, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true, resolved_at = \$1 `+
+		`WHERE product_id = \$2 AND product_variant_id IS NOT DISTINCT FROM \$3 AND alert_type = ANY\(\$4\) AND is_resolved = false`).
+		WithArgs(sqlmock.AnyArg(), int64(2), &variantID, pq.Array([]string{domain.AlertTypeOutOfStock, domain.AlertTypeLowStock})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	raised, err := repo.CheckLowStockAlerts(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, raised, 1)
+	assert.Equal(t, int64(11), raised[0].ID)
+	assert.Equal(t, domain.AlertTypeOutOfStock, raised[0].AlertType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_ListProductsNeedingRestock(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewInventoryRepository(db)
+	variantID := int64(21)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products p LEFT JOIN inventory i ON i.product_id = p.id ` +
+		`WHERE p.is_active = true AND \(i.id IS NOT NULL OR p.track_quantity = true\) ` +
+		`AND COALESCE\(i.available_quantity, 0\) <= GREATEST\(COALESCE\(i.reorder_point, 0\), 0\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	mock.ExpectQuery(`FROM products p LEFT JOIN inventory i .* WHERE p.is_active = true .* `+
+		`ORDER BY \(COALESCE\(i.available_quantity, 0\) <= 0\) DESC, `+
+		`COALESCE\(i.available_quantity, 0\) - COALESCE\(i.reorder_point, 0\) ASC, p.id ASC, i.id ASC LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"inventory_id", "product_id", "product_variant_id", "product_name", "sku", "available_quantity", "reorder_point"}).
+			AddRow(1, 10, nil, "Helmet", "HLM-1", 0, 5).
+			AddRow(nil, 15, nil, "Pump", "PMP-1", 0, 0).
+			AddRow(2, 20, variantID, "Gloves", "GLV-1", 1, 10).
+			AddRow(3, 30, nil, "Lights", "LGT-1", 4, 5))
+
+	candidates, total, err := repo.ListProductsNeedingRestock(context.Background(), 1, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	require.Len(t, candidates, 4)
+	assert.Equal(t, "HLM-1", candidates[0].SKU)
+	// A product without a stock record has no inventory ID and nothing available
+	assert.Nil(t, candidates[1].InventoryID)
+	assert.Equal(t, int64(15), candidates[1].ProductID)
+	assert.Equal(t, 0, candidates[1].AvailableQuantity)
+	assert.Equal(t, &variantID, candidates[2].ProductVariantID)
+	assert.Equal(t, 10, candidates[2].ReorderPoint)
+	assert.Equal(t, "LGT-1", candidates[3].SKU)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepository_BulkUpdateStock(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+	updates := []StockUpdateItem{
+		{ProductID: 1, Quantity: 5, MovementType: "in", Reason: "delivery"},
+		{ProductID: 99, Quantity: 3, MovementType: "out", Reason: "damaged"},
+	}
+
+	// Product 1 has 10 on hand, 2 reserved and reorder point 10; product 99 has no inventory
+	expectMixedBatch := func(mock sqlmock.Sqlmock) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectExec(`^SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 10, 2, 8, 5, 100, 10, now, now, now))
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(15, 13, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "in", 5, 10, 15, "", "bulk_update", "delivery", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(31))
+		mock.ExpectExec(`UPDATE inventory_alerts SET is_resolved = true`).
+			WithArgs(sqlmock.AnyArg(), int64(1), nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`^RELEASE SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectExec(`^SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns))
+		mock.ExpectExec(`^ROLLBACK TO SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("commits the items that apply and reports the rest", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectMixedBatch(mock)
+		mock.ExpectCommit()
+
+		response, err := repo.BulkUpdateStock(context.Background(), updates, false)
+
+		require.NoError(t, err)
+		assert.False(t, response.Success)
+		assert.Equal(t, int64(1), response.UpdatedItems)
+		require.Len(t, response.Movements, 1)
+		assert.Equal(t, int64(31), response.Movements[0].ID)
+		require.Len(t, response.FailedItems, 1)
+		assert.Equal(t, int64(99), response.FailedItems[0].ProductID)
+		assert.Contains(t, response.FailedItems[0].Error, "not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("atomic batch is rolled back when any item fails", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectMixedBatch(mock)
+		mock.ExpectRollback()
+
+		response, err := repo.BulkUpdateStock(context.Background(), updates, true)
+
+		require.NoError(t, err)
+		assert.False(t, response.Success)
+		assert.Equal(t, int64(0), response.UpdatedItems)
+		assert.Empty(t, response.Movements)
+		require.Len(t, response.FailedItems, 1)
+		assert.Equal(t, int64(99), response.FailedItems[0].ProductID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("out beyond available stock fails the item", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectExec(`^SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 10, 2, 8, 5, 100, 10, now, now, now))
+		mock.ExpectExec(`^ROLLBACK TO SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		response, err := repo.BulkUpdateStock(context.Background(),
+			[]StockUpdateItem{{ProductID: 1, Quantity: 9, MovementType: "out", Reason: "damaged"}}, false)
+
+		require.NoError(t, err)
+		assert.False(t, response.Success)
+		assert.Equal(t, int64(0), response.UpdatedItems)
+		require.Len(t, response.FailedItems, 1)
+		assert.Contains(t, response.FailedItems[0].Error, "requested 9, available 8")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("adjustments record the unsigned difference and skip no-ops", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		now := time.Now()
+		mock.ExpectBegin()
+
+		// Product 1 is counted down from 10 to 4
+		mock.ExpectExec(`^SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 10, 2, 8, 5, 100, 10, now, now, now))
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, available_quantity = \$2, updated_at = \$3, updated_by = \$4, version = version \+ 1 WHERE id = \$5`).
+			WithArgs(4, 2, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "out", 6, 10, 4, "", "bulk_update", "recount", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(32))
+		mock.ExpectExec(`^RELEASE SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Product 2 already has 3 on hand, so nothing is written
+		mock.ExpectExec(`^SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(8, 2, nil, 3, 0, 3, 0, 100, 1, now, now, now))
+		mock.ExpectExec(`^RELEASE SAVEPOINT bulk_stock_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		response, err := repo.BulkUpdateStock(context.Background(), []StockUpdateItem{
+			{ProductID: 1, Quantity: 4, MovementType: "adjustment", Reason: "recount"},
+			{ProductID: 2, Quantity: 3, MovementType: "adjustment", Reason: "recount"},
+		}, false)
+
+		require.NoError(t, err)
+		assert.True(t, response.Success)
+		require.Len(t, response.Movements, 1)
+		assert.Equal(t, "out", response.Movements[0].MovementType)
+		assert.Equal(t, 6, response.Movements[0].Quantity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_ReserveStock(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+	expiresAt := time.Now().Add(15 * time.Minute)
+	reserveUpdate := `UPDATE inventory SET reserved_quantity = reserved_quantity \+ \$1, available_quantity = available_quantity - \$1, ` +
+		`updated_at = \$2, updated_by = \$3, version = version \+ 1 WHERE id = \$4 AND available_quantity >= \$1`
+
+	// 5 on hand, 4 reserved, so a single unit is available
+	expectLockedInventory := func(mock sqlmock.Sqlmock) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 5, 4, 1, 0, 100, 2, now, now, now))
+	}
+
+	t.Run("records the reservation and moves stock to reserved", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+		mock.ExpectQuery(`INSERT INTO stock_reservations`).
+			WithArgs(int64(1), nil, int64(9), 1, expiresAt, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+		mock.ExpectExec(reserveUpdate).
+			WithArgs(1, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reservation := &domain.StockReservation{ProductID: 1, OrderID: 9, Quantity: 1, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+		err := repo.ReserveStock(context.Background(), reservation)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(41), reservation.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("last unit is not reserved twice", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+
+		// The first order takes the last unit under the row lock
+		expectLockedInventory(mock)
+		mock.ExpectQuery(`INSERT INTO stock_reservations`).
+			WithArgs(int64(1), nil, int64(9), 1, expiresAt, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+		mock.ExpectExec(reserveUpdate).
+			WithArgs(1, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// The second order waited on the lock and now reads nothing available
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 5, 5, 0, 0, 100, 2, now, now, now))
+		mock.ExpectRollback()
+
+		first := &domain.StockReservation{ProductID: 1, OrderID: 9, Quantity: 1, ExpiresAt: expiresAt, CreatedAt: now}
+		require.NoError(t, repo.ReserveStock(context.Background(), first))
+
+		second := &domain.StockReservation{ProductID: 1, OrderID: 10, Quantity: 1, ExpiresAt: expiresAt, CreatedAt: now}
+		err := repo.ReserveStock(context.Background(), second)
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Zero(t, second.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("update matching no available stock rolls back the reservation", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+		mock.ExpectQuery(`INSERT INTO stock_reservations`).
+			WithArgs(int64(1), nil, int64(9), 1, expiresAt, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41))
+		mock.ExpectExec(reserveUpdate).
+			WithArgs(1, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		reservation := &domain.StockReservation{ProductID: 1, OrderID: 9, Quantity: 1, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+		err := repo.ReserveStock(context.Background(), reservation)
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("more than available is rejected without reserving", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		expectLockedInventory(mock)
+		mock.ExpectRollback()
+
+		reservation := &domain.StockReservation{ProductID: 1, OrderID: 9, Quantity: 2, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+		err := repo.ReserveStock(context.Background(), reservation)
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		var stockErr *InsufficientInventoryError
+		require.ErrorAs(t, err, &stockErr)
+		assert.Equal(t, 1, stockErr.Available)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepository_RecomputeReservedQuantities(t *testing.T) {
+	lock := `SELECT id FROM inventory WHERE \$1::BIGINT IS NULL OR product_id = \$1 ORDER BY id FOR UPDATE`
+	recompute := `WITH held AS \( SELECT i.id, .* COALESCE\(SUM\(s.quantity\), 0\) AS reserved .* ` +
+		`UPDATE inventory i SET reserved_quantity = h.reserved, available_quantity = i.quantity - h.reserved`
+	columns := []string{"inventory_id", "product_id", "product_variant_id", "quantity",
+		"previous_reserved_quantity", "previous_available_quantity", "reserved_quantity", "available_quantity"}
+
+	t.Run("corrects a drifted product", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		productID := int64(1)
+
+		// The stock records are locked before the reservations are summed. 3 units are
+		// reserved but the counters still hold a released reservation of 2.
+		mock.ExpectBegin()
+		mock.ExpectExec(lock).WithArgs(&productID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(recompute).
+			WithArgs(&productID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(5, 1, nil, 10, 5, 5, 3, 7))
+		mock.ExpectCommit()
+
+		discrepancies, err := repo.RecomputeReservedQuantities(context.Background(), &productID)
+
+		require.NoError(t, err)
+		require.Len(t, discrepancies, 1)
+		assert.Equal(t, domain.InventoryDiscrepancy{
+			InventoryID: 5, ProductID: 1, Quantity: 10,
+			PreviousReservedQuantity: 5, PreviousAvailableQuantity: 5,
+			ReservedQuantity: 3, AvailableQuantity: 7,
+		}, *discrepancies[0])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("every product with nothing drifted", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(lock).WithArgs(nil).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectQuery(recompute).
+			WithArgs(nil).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectCommit()
+
+		discrepancies, err := repo.RecomputeReservedQuantities(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, discrepancies)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed correction is rolled back", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewInventoryRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(lock).WithArgs(nil).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectQuery(recompute).WithArgs(nil).WillReturnError(&pq.Error{Code: "40P01", Message: "deadlock detected"})
+		mock.ExpectRollback()
+
+		_, err := repo.RecomputeReservedQuantities(context.Background(), nil)
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
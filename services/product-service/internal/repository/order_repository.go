@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *domain.Order, items []*domain.OrderItem) error
+	PlaceOrder(ctx context.Context, order *domain.Order, redeemCoupons bool, confirmedAt time.Time) ([]*domain.InventoryMovement, error)
+	DeleteOrder(ctx context.Context, id int64) error
+}
+
+type orderRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrderRepository(db *sqlx.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+// CreateOrder records an order and its items in one transaction and sets their IDs
+func (r *orderRepository) CreateOrder(ctx context.Context, order *domain.Order, items []*domain.OrderItem) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO orders (
+			order_number, cart_id, user_id, status, payment_status, fulfillment_status,
+			subtotal, tax_amount, shipping_amount, discount_amount, total_amount, currency,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+		order.OrderNumber, order.CartID, order.UserID, order.Status, order.PaymentStatus, order.FulfillmentStatus,
+		order.Subtotal, order.TaxAmount, order.ShippingAmount, order.DiscountAmount, order.TotalAmount, order.Currency,
+		order.CreatedAt, order.UpdatedAt,
+	).Scan(&order.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return &AlreadyExistsError{Resource: fmt.Sprintf("order %s", order.OrderNumber)}
+		}
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for _, item := range items {
+		item.OrderID = order.ID
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO order_items (
+				order_id, product_id, product_variant_id, product_name, product_sku, quantity, unit_price, total_price
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			item.OrderID, item.ProductID, item.ProductVariantID, item.ProductName, item.ProductSKU,
+			item.Quantity, item.UnitPrice, item.TotalPrice,
+		).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PlaceOrder completes a pending order in one transaction. The order's cart is locked so
+// checkouts of the same cart run one at a time; the order's lines are taken out of
+// stock, the cart's coupons are redeemed when redeemCoupons is set, the cart is emptied
+// of its items, coupons and shipping and the order is confirmed. ErrCartEmpty is
+// returned when the cart has no items left, as after another checkout of it, and
+// ErrCartChanged when its items no longer match the order's lines, so nothing added
+// after the order was recorded is emptied out unordered. On any error nothing is changed.
+func (r *orderRepository) PlaceOrder(ctx context.Context, order *domain.Order, redeemCoupons bool, confirmedAt time.Time) ([]*domain.InventoryMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var cartID int64
+	err = tx.GetContext(ctx, &cartID, `SELECT id FROM carts WHERE id = $1 FOR UPDATE`, order.CartID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrCartNotFound, "cart with ID %d not found", order.CartID)
+		}
+		return nil, fmt.Errorf("failed to lock cart: %w", err)
+	}
+
+	var hasItems bool
+	err = tx.GetContext(ctx, &hasItems, `SELECT EXISTS(SELECT 1 FROM cart_items WHERE cart_id = $1)`, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cart items: %w", err)
+	}
+	if !hasItems {
+		return nil, ErrCartEmpty
+	}
+
+	// The order's lines were copied from the cart before it was locked
+	var changed bool
+	err = tx.GetContext(ctx, &changed, `
+		SELECT EXISTS (
+			(SELECT product_id, product_variant_id, quantity FROM cart_items WHERE cart_id = $1
+			 EXCEPT ALL
+			 SELECT product_id, product_variant_id, quantity FROM order_items WHERE order_id = $2)
+			UNION ALL
+			(SELECT product_id, product_variant_id, quantity FROM order_items WHERE order_id = $2
+			 EXCEPT ALL
+			 SELECT product_id, product_variant_id, quantity FROM cart_items WHERE cart_id = $1)
+		)`, cartID, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare cart items with the order: %w", err)
+	}
+	if changed {
+		return nil, ErrCartChanged
+	}
+
+	movements, err := commitOrderStock(ctx, tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if redeemCoupons {
+		if err := redeemCartCoupons(ctx, tx, cartID, order.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := emptyCart(ctx, tx, cartID); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE orders SET status = $1, confirmed_at = $2, updated_at = $2 WHERE id = $3`,
+		domain.OrderStatusConfirmed, confirmedAt, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm order: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, notFoundf(ErrOrderNotFound, "order with ID %d not found", order.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return movements, nil
+}
+
+// DeleteOrder deletes an order and, through the foreign key, its items
+func (r *orderRepository) DeleteOrder(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM orders WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete order: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundf(ErrOrderNotFound, "order with ID %d not found", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRepository_CreateOrder(t *testing.T) {
+	now := time.Now()
+	variantID := int64(3)
+
+	newOrder := func() (*domain.Order, []*domain.OrderItem) {
+		order := &domain.Order{
+			OrderNumber: "ORD-1", CartID: 9, Status: domain.OrderStatusPending,
+			PaymentStatus: "pending", FulfillmentStatus: "unfulfilled",
+			Subtotal: 30, TotalAmount: 30, Currency: "USD", CreatedAt: now, UpdatedAt: now,
+		}
+		items := []*domain.OrderItem{
+			{ProductID: 1, ProductName: "Chain", ProductSKU: "CH-1", Quantity: 2, UnitPrice: 10, TotalPrice: 20},
+			{ProductID: 2, ProductVariantID: &variantID, ProductName: "Gear", ProductSKU: "GR-1-L", Quantity: 1, UnitPrice: 10, TotalPrice: 10},
+		}
+		return order, items
+	}
+
+	t.Run("records the order and its items", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO orders`).
+			WithArgs("ORD-1", int64(9), nil, "pending", "pending", "unfulfilled",
+				30.0, 0.0, 0.0, 0.0, 30.0, "USD", now, now).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+		mock.ExpectQuery(`INSERT INTO order_items`).
+			WithArgs(int64(100), int64(1), nil, "Chain", "CH-1", 2, 10.0, 20.0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO order_items`).
+			WithArgs(int64(100), int64(2), &variantID, "Gear", "GR-1-L", 1, 10.0, 10.0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectCommit()
+
+		order, items := newOrder()
+		err := repo.CreateOrder(context.Background(), order, items)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), order.ID)
+		assert.Equal(t, int64(100), items[1].OrderID)
+		assert.Equal(t, int64(2), items[1].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("item failure rolls back the order", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO orders`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+		mock.ExpectQuery(`INSERT INTO order_items`).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		order, items := newOrder()
+		err := repo.CreateOrder(context.Background(), order, items)
+
+		assert.ErrorContains(t, err, "failed to create order item")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOrderRepository_DeleteOrder(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewOrderRepository(db)
+	mock.ExpectExec(`DELETE FROM orders WHERE id = \$1`).
+		WithArgs(int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteOrder(context.Background(), 100)
+
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.EqualError(t, err, "order with ID 100 not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderRepository_PlaceOrder(t *testing.T) {
+	inventoryColumns := []string{"id", "product_id", "product_variant_id", "quantity", "reserved_quantity", "available_quantity",
+		"min_stock_level", "max_stock_level", "reorder_point", "last_restocked", "created_at", "updated_at"}
+	userID := int64(42)
+	variantID := int64(20)
+	now := time.Now()
+	order := &domain.Order{ID: 900, CartID: 5, UserID: &userID}
+
+	expectCartMatches := func(mock sqlmock.Sqlmock, changed bool) {
+		mock.ExpectQuery(`SELECT EXISTS \( \(SELECT product_id, product_variant_id, quantity FROM cart_items WHERE cart_id = \$1 EXCEPT ALL`).
+			WithArgs(int64(5), int64(900)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(changed))
+	}
+
+	// Cart 5 is locked and still holds items; order 900 has product 1 (no variant) x2
+	// and product 2 variant 20 x3
+	expectOrderLines := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM carts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE cart_id = \$1\)`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		expectCartMatches(mock, false)
+		mock.ExpectQuery(`SELECT product_id, product_variant_id, SUM\(quantity\) AS quantity FROM order_items WHERE order_id = \$1`).
+			WithArgs(int64(900)).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_variant_id", "quantity"}).
+				AddRow(1, nil, 2).
+				AddRow(2, variantID, 3))
+	}
+	expectConsumed := func(mock sqlmock.Sqlmock, productID int64, variantID interface{}, reserved int) {
+		mock.ExpectQuery(`WITH consumed AS \( DELETE FROM stock_reservations`).
+			WithArgs(int64(900), productID, variantID).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(reserved))
+	}
+	expectStockCommitted := func(mock sqlmock.Sqlmock) {
+		// Product 1: 10 on hand, nothing reserved
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 10, 0, 10, 0, 100, 5, now, now, now))
+		expectConsumed(mock, 1, nil, 0)
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, reserved_quantity = \$2, available_quantity = \$3, updated_at = \$4, updated_by = \$5, version = version \+ 1 WHERE id = \$6`).
+			WithArgs(8, 0, 8, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(1), nil, "out", 2, 10, 8, "900", "order", "checkout", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(101))
+
+		// Variant 20: 4 on hand, 3 reserved by this order, 1 available
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id = \$2 FOR UPDATE`).
+			WithArgs(int64(2), variantID).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(8, 2, variantID, 4, 3, 1, 0, 100, 5, now, now, now))
+		expectConsumed(mock, 2, variantID, 3)
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1, reserved_quantity = \$2, available_quantity = \$3, updated_at = \$4, updated_by = \$5, version = version \+ 1 WHERE id = \$6`).
+			WithArgs(1, 0, 1, sqlmock.AnyArg(), nil, int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WithArgs(int64(2), variantID, "out", 3, 4, 1, "900", "order", "checkout", "", nil, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(102))
+	}
+	expectCouponLimits := func(mock sqlmock.Sqlmock, usageLimit int, total int64) {
+		mock.ExpectQuery(`SELECT c.id FROM cart_coupons cc JOIN coupons c ON c.code = cc.coupon_code WHERE cc.cart_id = \$1 ORDER BY c.id`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(`SELECT usage_limit, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"usage_limit", "per_user_limit"}).AddRow(usageLimit, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM coupon_redemptions WHERE coupon_id = \$1 AND cart_id = \$2\)`).
+			WithArgs(int64(3), int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`SELECT COUNT\(\*\), COUNT\(\*\) FILTER \(WHERE user_id = \$2\) FROM coupon_redemptions WHERE coupon_id = \$1`).
+			WithArgs(int64(3), userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(total, 0))
+	}
+	expectCartEmptied := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(`DELETE FROM cart_items WHERE cart_id = \$1`).WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`DELETE FROM cart_coupons WHERE cart_id = \$1`).WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM cart_shipping WHERE cart_id = \$1`).WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	t.Run("commits the order's lines, redeems coupons, empties the cart and confirms", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		expectOrderLines(mock)
+		expectStockCommitted(mock)
+		expectCouponLimits(mock, 100, 10)
+		mock.ExpectQuery(`INSERT INTO coupon_redemptions`).
+			WithArgs(int64(3), userID, int64(5), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+		mock.ExpectExec(`UPDATE coupons SET used_count = used_count \+ 1, updated_at = \$1 WHERE id = \$2`).
+			WithArgs(sqlmock.AnyArg(), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectCartEmptied(mock)
+		mock.ExpectExec(`UPDATE orders SET status = \$1, confirmed_at = \$2, updated_at = \$2 WHERE id = \$3`).
+			WithArgs(domain.OrderStatusConfirmed, now, int64(900)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		movements, err := repo.PlaceOrder(context.Background(), order, true, now)
+
+		require.NoError(t, err)
+		require.Len(t, movements, 2)
+		assert.Equal(t, int64(101), movements[0].ID)
+		assert.Equal(t, int64(102), movements[1].ID)
+		assert.Equal(t, "900", movements[1].Reference)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("leaves coupons alone when they were redeemed on apply", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		expectOrderLines(mock)
+		expectStockCommitted(mock)
+		expectCartEmptied(mock)
+		mock.ExpectExec(`UPDATE orders SET status = \$1`).
+			WithArgs(domain.OrderStatusConfirmed, now, int64(900)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := repo.PlaceOrder(context.Background(), order, false, now)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when one line is short", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		expectOrderLines(mock)
+
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id IS NULL FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(7, 1, nil, 10, 0, 10, 0, 100, 5, now, now, now))
+		expectConsumed(mock, 1, nil, 0)
+		mock.ExpectExec(`UPDATE inventory SET quantity = \$1`).
+			WithArgs(8, 0, 8, sqlmock.AnyArg(), nil, int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO inventory_movements`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(101))
+
+		// Variant 20: only 2 available and nothing reserved for the order
+		mock.ExpectQuery(`SELECT .* FROM inventory WHERE product_id = \$1 AND product_variant_id = \$2 FOR UPDATE`).
+			WithArgs(int64(2), variantID).
+			WillReturnRows(sqlmock.NewRows(inventoryColumns).AddRow(8, 2, variantID, 2, 0, 2, 0, 100, 5, now, now, now))
+		expectConsumed(mock, 2, variantID, 0)
+		mock.ExpectRollback()
+
+		movements, err := repo.PlaceOrder(context.Background(), order, true, now)
+
+		var stockErr *InsufficientInventoryError
+		require.ErrorAs(t, err, &stockErr)
+		assert.Nil(t, movements)
+		assert.Equal(t, int64(2), stockErr.ProductID)
+		assert.Equal(t, 3, stockErr.Requested)
+		assert.Equal(t, 2, stockErr.Available)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back the stock when a coupon is used up", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		expectOrderLines(mock)
+		expectStockCommitted(mock)
+		expectCouponLimits(mock, 10, 10)
+		mock.ExpectRollback()
+
+		_, err := repo.PlaceOrder(context.Background(), order, true, now)
+
+		assert.ErrorIs(t, err, ErrCouponLimitReached)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a cart emptied by another checkout places nothing", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM carts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE cart_id = \$1\)`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		_, err := repo.PlaceOrder(context.Background(), order, true, now)
+
+		assert.ErrorIs(t, err, ErrCartEmpty)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a cart changed since the order was recorded places nothing", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewOrderRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM carts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM cart_items WHERE cart_id = \$1\)`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		expectCartMatches(mock, true)
+		mock.ExpectRollback()
+
+		_, err := repo.PlaceOrder(context.Background(), order, true, now)
+
+		assert.ErrorIs(t, err, ErrCartChanged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
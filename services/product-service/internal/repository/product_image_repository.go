@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Product Images
+//
+// A product with images always has exactly one primary image. Every write below locks
+// the product row first, so concurrent image changes to one product run one at a time,
+// and idx_product_images_one_primary rejects a second primary if one slips through.
+
+// AddProductImage appends an image after the product's existing ones. The first image
+// of a product becomes primary; a later one only does when IsPrimary is set, which
+// demotes the current primary.
+func (r *productRepository) AddProductImage(ctx context.Context, image *domain.ProductImage) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProductForImages(ctx, tx, image.ProductID); err != nil {
+		return err
+	}
+
+	var existing struct {
+		Count        int `db:"count"`
+		NextPosition int `db:"next_position"`
+	}
+	err = tx.GetContext(ctx, &existing, `
+		SELECT COUNT(*) AS count, COALESCE(MAX(position) + 1, 0) AS next_position
+		FROM product_images WHERE product_id = $1`, image.ProductID)
+	if err != nil {
+		return fmt.Errorf("failed to count product images: %w", err)
+	}
+
+	image.Position = existing.NextPosition
+	image.IsPrimary = image.IsPrimary || existing.Count == 0
+	if image.IsPrimary {
+		if err := clearPrimaryImage(ctx, tx, image.ProductID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	image.CreatedAt = now
+	image.UpdatedAt = now
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO product_images (product_id, url, alt, position, is_primary, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		image.ProductID, image.URL, image.Alt, image.Position, image.IsPrimary, image.CreatedAt, image.UpdatedAt,
+	).Scan(&image.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add product image: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListProductImages retrieves a product's images in display order
+func (r *productRepository) ListProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error) {
+	query := `SELECT * FROM product_images WHERE product_id = $1 ORDER BY position ASC, id ASC`
+
+	var images []*domain.ProductImage
+	err := r.db.SelectContext(ctx, &images, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+
+	return images, nil
+}
+
+// ListImagesForProducts retrieves the images of several products in one query, keyed by
+// product ID and in display order. Products without images are absent from the map.
+func (r *productRepository) ListImagesForProducts(ctx context.Context, productIDs []int64) (map[int64][]*domain.ProductImage, error) {
+	result := make(map[int64][]*domain.ProductImage, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT * FROM product_images
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, position ASC, id ASC`
+
+	var images []*domain.ProductImage
+	err := r.db.SelectContext(ctx, &images, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+
+	for _, image := range images {
+		result[image.ProductID] = append(result[image.ProductID], image)
+	}
+
+	return result, nil
+}
+
+// SetPrimaryImage makes one of a product's images its primary image
+func (r *productRepository) SetPrimaryImage(ctx context.Context, productID, imageID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProductForImages(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists,
+		`SELECT EXISTS(SELECT 1 FROM product_images WHERE id = $1 AND product_id = $2)`, imageID, productID)
+	if err != nil {
+		return fmt.Errorf("failed to get product image: %w", err)
+	}
+	if !exists {
+		return ErrProductImageNotFound
+	}
+
+	// Demote first so the one-primary index never sees two primaries
+	if err := clearPrimaryImage(ctx, tx, productID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE product_images SET is_primary = true, updated_at = $1 WHERE id = $2`, time.Now(), imageID)
+	if err != nil {
+		return fmt.Errorf("failed to set primary image: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReorderImages sets the display order of a product's images. imageIDs must list every
+// image of the product exactly once; the first becomes position 0.
+func (r *productRepository) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProductForImages(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var current []int64
+	err = tx.SelectContext(ctx, &current, `SELECT id FROM product_images WHERE product_id = $1`, productID)
+	if err != nil {
+		return fmt.Errorf("failed to list product images: %w", err)
+	}
+	if !sameIDSet(current, imageIDs) {
+		return ErrImageOrderMismatch
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE product_images pi
+		SET position = ordered.position - 1, updated_at = $3
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE pi.id = ordered.id AND pi.product_id = $1`,
+		productID, pq.Array(imageIDs), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to reorder product images: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteProductImage removes one of a product's images. Deleting the primary image
+// promotes the next image in display order.
+func (r *productRepository) DeleteProductImage(ctx context.Context, productID, imageID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProductForImages(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var wasPrimary bool
+	err = tx.GetContext(ctx, &wasPrimary,
+		`DELETE FROM product_images WHERE id = $1 AND product_id = $2 RETURNING is_primary`, imageID, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrProductImageNotFound
+		}
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+
+	if wasPrimary {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE product_images SET is_primary = true, updated_at = $2
+			WHERE id = (
+				SELECT id FROM product_images WHERE product_id = $1
+				ORDER BY position ASC, id ASC LIMIT 1
+			)`, productID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to promote primary image: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockProductForImages locks a product's row for the rest of tx, serialising changes to
+// its images
+func lockProductForImages(ctx context.Context, tx *sqlx.Tx, productID int64) error {
+	var id int64
+	err := tx.GetContext(ctx, &id, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundf(ErrProductNotFound, "product with ID %d not found", productID)
+		}
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+	return nil
+}
+
+// clearPrimaryImage demotes a product's primary image, if it has one
+func clearPrimaryImage(ctx context.Context, tx *sqlx.Tx, productID int64) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE product_images SET is_primary = false, updated_at = $2 WHERE product_id = $1 AND is_primary`,
+		productID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to clear primary image: %w", err)
+	}
+	return nil
+}
+
+// sameIDSet reports whether want lists exactly the IDs in have, each once
+func sameIDSet(have, want []int64) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	remaining := make(map[int64]bool, len(have))
+	for _, id := range have {
+		remaining[id] = true
+	}
+	for _, id := range want {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	lockProductQuery   = `SELECT id FROM products WHERE id = \$1 FOR UPDATE`
+	clearPrimaryQuery  = `UPDATE product_images SET is_primary = false, updated_at = \$2 WHERE product_id = \$1 AND is_primary`
+	countImagesQuery   = `SELECT COUNT\(\*\) AS count, COALESCE\(MAX\(position\) \+ 1, 0\) AS next_position FROM product_images WHERE product_id = \$1`
+	insertImageQuery   = `INSERT INTO product_images \(product_id, url, alt, position, is_primary, created_at, updated_at\)`
+	promoteImageQuery  = `UPDATE product_images SET is_primary = true, updated_at = \$2 WHERE id = \( SELECT id FROM product_images WHERE product_id = \$1 ORDER BY position ASC, id ASC LIMIT 1 \)`
+	imageExistsQuery   = `SELECT EXISTS\(SELECT 1 FROM product_images WHERE id = \$1 AND product_id = \$2\)`
+	setPrimaryQuery    = `UPDATE product_images SET is_primary = true, updated_at = \$1 WHERE id = \$2`
+	deleteImageQuery   = `DELETE FROM product_images WHERE id = \$1 AND product_id = \$2 RETURNING is_primary`
+	listImageIDsQuery  = `SELECT id FROM product_images WHERE product_id = \$1`
+	reorderImagesQuery = `UPDATE product_images pi SET position = ordered.position - 1`
+)
+
+func expectProductLock(mock sqlmock.Sqlmock, productID int64) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockProductQuery).
+		WithArgs(productID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(productID))
+}
+
+func TestProductRepository_AddProductImage(t *testing.T) {
+	expectCount := func(mock sqlmock.Sqlmock, count, nextPosition int) {
+		mock.ExpectQuery(countImagesQuery).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "next_position"}).AddRow(count, nextPosition))
+	}
+
+	t.Run("first image becomes primary", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		expectCount(mock, 0, 0)
+		mock.ExpectExec(clearPrimaryQuery).
+			WithArgs(int64(5), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(insertImageQuery).
+			WithArgs(int64(5), "https://cdn.example.com/a.jpg", "Front", 0, true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		image := &domain.ProductImage{ProductID: 5, URL: "https://cdn.example.com/a.jpg", Alt: "Front"}
+		err := repo.AddProductImage(context.Background(), image)
+
+		require.NoError(t, err)
+		assert.True(t, image.IsPrimary)
+		assert.Equal(t, int64(1), image.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("later image is appended and leaves the primary alone", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		expectCount(mock, 2, 4)
+		mock.ExpectQuery(insertImageQuery).
+			WithArgs(int64(5), "https://cdn.example.com/b.jpg", "", 4, false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectCommit()
+
+		image := &domain.ProductImage{ProductID: 5, URL: "https://cdn.example.com/b.jpg"}
+		err := repo.AddProductImage(context.Background(), image)
+
+		require.NoError(t, err)
+		assert.False(t, image.IsPrimary)
+		assert.Equal(t, 4, image.Position)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("new primary demotes the current one", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		expectCount(mock, 2, 2)
+		mock.ExpectExec(clearPrimaryQuery).
+			WithArgs(int64(5), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(insertImageQuery).
+			WithArgs(int64(5), "https://cdn.example.com/c.jpg", "", 2, true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectCommit()
+
+		image := &domain.ProductImage{ProductID: 5, URL: "https://cdn.example.com/c.jpg", IsPrimary: true}
+		err := repo.AddProductImage(context.Background(), image)
+
+		require.NoError(t, err)
+		assert.True(t, image.IsPrimary)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing product", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockProductQuery).
+			WithArgs(int64(5)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.AddProductImage(context.Background(), &domain.ProductImage{ProductID: 5, URL: "https://cdn.example.com/a.jpg"})
+
+		assert.EqualError(t, err, "product with ID 5 not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_SetPrimaryImage(t *testing.T) {
+	t.Run("demotes the old primary before promoting", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(imageExistsQuery).
+			WithArgs(int64(8), int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(clearPrimaryQuery).
+			WithArgs(int64(5), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(setPrimaryQuery).
+			WithArgs(sqlmock.AnyArg(), int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SetPrimaryImage(context.Background(), 5, 8)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("image of another product leaves the primary alone", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(imageExistsQuery).
+			WithArgs(int64(9), int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		err := repo.SetPrimaryImage(context.Background(), 5, 9)
+
+		assert.ErrorIs(t, err, ErrProductImageNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_DeleteProductImage(t *testing.T) {
+	t.Run("deleting the primary promotes the next image", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(deleteImageQuery).
+			WithArgs(int64(8), int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"is_primary"}).AddRow(true))
+		mock.ExpectExec(promoteImageQuery).
+			WithArgs(int64(5), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.DeleteProductImage(context.Background(), 5, 8)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("deleting another image keeps the primary", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(deleteImageQuery).
+			WithArgs(int64(8), int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"is_primary"}).AddRow(false))
+		mock.ExpectCommit()
+
+		err := repo.DeleteProductImage(context.Background(), 5, 8)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing image", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(deleteImageQuery).
+			WithArgs(int64(8), int64(5)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.DeleteProductImage(context.Background(), 5, 8)
+
+		assert.ErrorIs(t, err, ErrProductImageNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_ReorderImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		imageIDs []int64
+	}{
+		{name: "missing image", imageIDs: []int64{3, 1}},
+		{name: "image of another product", imageIDs: []int64{3, 1, 9}},
+		{name: "duplicate image", imageIDs: []int64{3, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupTestDB(t)
+			defer cleanup()
+
+			repo := NewProductRepository(db)
+			expectProductLock(mock, 5)
+			mock.ExpectQuery(listImageIDsQuery).
+				WithArgs(int64(5)).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+			mock.ExpectRollback()
+
+			err := repo.ReorderImages(context.Background(), 5, tt.imageIDs)
+
+			assert.ErrorIs(t, err, ErrImageOrderMismatch)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("every image listed once", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectProductLock(mock, 5)
+		mock.ExpectQuery(listImageIDsQuery).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+		mock.ExpectExec(reorderImagesQuery).
+			WithArgs(int64(5), pq.Array([]int64{3, 1, 2}), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		err := repo.ReorderImages(context.Background(), 5, []int64{3, 1, 2})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -4,38 +4,66 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ProductRepository interface {
 	CreateProduct(ctx context.Context, product *domain.Product) error
 	GetProductByID(ctx context.Context, id int64) (*domain.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Product, error)
 	GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	IsSKUTaken(ctx context.Context, sku string) (bool, error)
 	UpdateProduct(ctx context.Context, id int64, product *domain.Product) error
 	DeleteProduct(ctx context.Context, id int64) error
+	BulkSetProductsActive(ctx context.Context, ids []int64, active bool) ([]int64, error)
+	BulkDeleteProducts(ctx context.Context, ids []int64) ([]int64, error)
 	ListProducts(ctx context.Context, filter *domain.ProductFilter, offset, limit int) ([]*domain.Product, int64, error)
-	GetProductsByCategory(ctx context.Context, categoryID int64, offset, limit int) ([]*domain.Product, int64, error)
-	SearchProducts(ctx context.Context, query string, offset, limit int) ([]*domain.Product, int64, error)
+	ListProductsAfter(ctx context.Context, filter *domain.ProductFilter, after *domain.ProductCursor, limit int) ([]*domain.Product, error)
+	GetProductsByCategory(ctx context.Context, categoryID int64, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error)
+	SearchProducts(ctx context.Context, query, mode string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error)
+	SuggestProducts(ctx context.Context, prefix string, limit int) ([]*domain.ProductSuggestion, error)
 	UpdateProductQuantity(ctx context.Context, id int64, quantity int) error
-	GetProductsByTags(ctx context.Context, tags []string, offset, limit int) ([]*domain.Product, int64, error)
+	GetProductsByTags(ctx context.Context, tags []string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error)
+	CloneProduct(ctx context.Context, id int64, includeImages bool) (*domain.Product, error)
+	GetPrimaryImages(ctx context.Context, productIDs []int64) (map[int64]*domain.ProductImage, error)
+
+	// Product Images
+	AddProductImage(ctx context.Context, image *domain.ProductImage) error
+	ListProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error)
+	ListImagesForProducts(ctx context.Context, productIDs []int64) (map[int64][]*domain.ProductImage, error)
+	SetPrimaryImage(ctx context.Context, productID, imageID int64) error
+	ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error
+	DeleteProductImage(ctx context.Context, productID, imageID int64) error
 
 	// Product Variants
 	CreateProductVariant(ctx context.Context, variant *domain.ProductVariant) error
 	GetProductVariantByID(ctx context.Context, id int64) (*domain.ProductVariant, error)
+	GetProductVariantsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.ProductVariant, error)
 	GetProductVariantsByProductID(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
 	UpdateProductVariant(ctx context.Context, id int64, variant *domain.ProductVariant) error
 	DeleteProductVariant(ctx context.Context, id int64) error
-	GetProductVariantsByProductIDAndSKU(ctx context.Context, productID int64, sku string) ([]*domain.ProductVariant, error)
+	GetProductVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error)
+
+	// Variant Attributes
+	SetVariantAttributes(ctx context.Context, variantID int64, attrs map[string]string) error
+	GetVariantAttributes(ctx context.Context, variantID int64) ([]*domain.VariantAttribute, error)
+	GetVariantByAttributes(ctx context.Context, productID int64, attrs map[string]string) (*domain.ProductVariant, error)
+	GetVariantAttributeOptions(ctx context.Context, productID int64) ([]*domain.VariantAttributeOption, error)
 
 	// Product Categories
 	AddProductToCategory(ctx context.Context, productID, categoryID int64, isPrimary bool) error
 	RemoveProductFromCategory(ctx context.Context, productID, categoryID int64) error
 	GetProductCategories(ctx context.Context, productID int64) ([]*domain.Category, error)
-	UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64) error
+	UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64, primaryCategoryID *int64) error
 	CheckCategoryHasProducts(ctx context.Context, categoryID int64) (bool, error)
 }
 
@@ -49,16 +77,35 @@ func NewProductRepository(db *sqlx.DB) ProductRepository {
 	}
 }
 
+// productColumns are the products columns read into domain.Product. The generated
+// search_vector column is only matched against, never read.
+const productColumns = `id, name, description, short_description, sku, price, compare_price,
+	cost_price, weight, dimensions, is_active, is_digital, is_free, requires_shipping, taxable,
+	track_quantity, quantity, min_quantity, max_quantity, meta_title, meta_description, tags,
+	created_at, updated_at, updated_by, version`
+
+// qualifiedProductColumns is productColumns for queries that alias products as p
+var qualifiedProductColumns = qualifyColumns("p", productColumns)
+
+// qualifyColumns prefixes each of a comma-separated list of columns with alias
+func qualifyColumns(alias, columns string) string {
+	fields := strings.Split(columns, ",")
+	for i, field := range fields {
+		fields[i] = alias + "." + strings.TrimSpace(field)
+	}
+	return strings.Join(fields, ", ")
+}
+
 func (r *productRepository) CreateProduct(ctx context.Context, product *domain.Product) error {
 	query := `
 		INSERT INTO products (
 			name, description, short_description, sku, price, compare_price, cost_price,
-			weight, dimensions, is_active, is_digital, requires_shipping, taxable,
+			weight, dimensions, is_active, is_digital, is_free, requires_shipping, taxable,
 			track_quantity, quantity, min_quantity, max_quantity, meta_title,
 			meta_description, tags, created_at, updated_at
 		) VALUES (
 			:name, :description, :short_description, :sku, :price, :compare_price, :cost_price,
-			:weight, :dimensions, :is_active, :is_digital, :requires_shipping, :taxable,
+			:weight, :dimensions, :is_active, :is_digital, :is_free, :requires_shipping, :taxable,
 			:track_quantity, :quantity, :min_quantity, :max_quantity, :meta_title,
 			:meta_description, :tags, :created_at, :updated_at
 		)
@@ -70,6 +117,9 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 
 	rows, err := r.db.NamedQueryContext(ctx, query, product)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product with SKU %s", product.SKU)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 	defer rows.Close()
@@ -78,6 +128,7 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 		if err := rows.Scan(&product.ID); err != nil {
 			return fmt.Errorf("failed to scan product ID: %w", err)
 		}
+		product.Version = 1
 	}
 
 	return nil
@@ -85,13 +136,13 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 
 // GetProductByID retrieves a product by ID
 func (r *productRepository) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
-	query := `SELECT * FROM products WHERE id = $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1`
 
 	var product domain.Product
 	err := r.db.GetContext(ctx, &product, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product with ID %d not found", id)
+			return nil, notFoundf(ErrProductNotFound, "product with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
@@ -99,15 +150,66 @@ func (r *productRepository) GetProductByID(ctx context.Context, id int64) (*doma
 	return &product, nil
 }
 
+// GetProductsByIDs retrieves several products in one query, keyed by ID.
+// IDs that do not exist are simply absent from the result.
+func (r *productRepository) GetProductsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Product, error) {
+	result := make(map[int64]*domain.Product, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = ANY($1)`
+
+	var products []*domain.Product
+	err := r.db.SelectContext(ctx, &products, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	for _, product := range products {
+		result[product.ID] = product
+	}
+
+	return result, nil
+}
+
+// GetPrimaryImages retrieves the lead image of several products in one query, keyed by
+// product ID. A product's primary image wins, falling back to its first by position.
+// Products without images are absent from the map.
+func (r *productRepository) GetPrimaryImages(ctx context.Context, productIDs []int64) (map[int64]*domain.ProductImage, error) {
+	result := make(map[int64]*domain.ProductImage, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (product_id) id, product_id, url, alt, position, is_primary
+		FROM product_images
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, is_primary DESC, position ASC, id ASC`
+
+	var images []*domain.ProductImage
+	err := r.db.SelectContext(ctx, &images, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+
+	for _, image := range images {
+		result[image.ProductID] = image
+	}
+
+	return result, nil
+}
+
 // GetProductBySKU retrieves a product by SKU
 func (r *productRepository) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
-	query := `SELECT * FROM products WHERE sku = $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE sku = $1`
 
 	var product domain.Product
 	err := r.db.GetContext(ctx, &product, query, sku)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product with SKU %s not found", sku)
+			return nil, notFoundf(ErrProductNotFound, "product with SKU %s not found", sku)
 		}
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
@@ -115,24 +217,194 @@ func (r *productRepository) GetProductBySKU(ctx context.Context, sku string) (*d
 	return &product, nil
 }
 
-// UpdateProduct updates an existing product
+// IsSKUTaken reports whether a product or variant already uses the SKU
+func (r *productRepository) IsSKUTaken(ctx context.Context, sku string) (bool, error) {
+	taken, err := skuExists(ctx, r.db, sku)
+	if err != nil {
+		return false, fmt.Errorf("failed to check SKU: %w", err)
+	}
+
+	return taken, nil
+}
+
+// skuExists checks products and variants for a SKU using the given database or transaction
+func skuExists(ctx context.Context, q sqlx.QueryerContext, sku string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM products WHERE sku = $1
+			UNION ALL
+			SELECT 1 FROM product_variants WHERE sku = $1
+		)`
+
+	var taken bool
+	err := sqlx.GetContext(ctx, q, &taken, query, sku)
+	return taken, err
+}
+
+// nextCopySKU returns the first unused SKU of the form "<sku>-COPY", "<sku>-COPY-2", ...
+func nextCopySKU(ctx context.Context, q sqlx.QueryerContext, sku string) (string, error) {
+	base := sku + "-COPY"
+	candidate := base
+	for n := 2; ; n++ {
+		taken, err := skuExists(ctx, q, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check SKU: %w", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// CloneProduct copies a product with its variants, variant attributes, attribute values,
+// category assignments and optionally its images into a new inactive product.
+// Stock is not copied: the clone and its variants start with zero quantity and no inventory.
+func (r *productRepository) CloneProduct(ctx context.Context, id int64, includeImages bool) (*domain.Product, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var source domain.Product
+	err = tx.GetContext(ctx, &source, `SELECT `+productColumns+` FROM products WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrProductNotFound, "product with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	clone := source
+	clone.ID = 0
+	clone.Name = source.Name + " (Copy)"
+	clone.IsActive = false
+	clone.Quantity = 0
+	clone.CreatedAt = time.Now()
+	clone.UpdatedAt = clone.CreatedAt
+
+	clone.SKU, err = nextCopySKU(ctx, tx, source.SKU)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO products (
+			name, description, short_description, sku, price, compare_price, cost_price,
+			weight, dimensions, is_active, is_digital, is_free, requires_shipping, taxable,
+			track_quantity, quantity, min_quantity, max_quantity, meta_title,
+			meta_description, tags, created_at, updated_at
+		)
+		SELECT $1, description, short_description, $2, price, compare_price, cost_price,
+			weight, dimensions, false, is_digital, is_free, requires_shipping, taxable,
+			track_quantity, 0, min_quantity, max_quantity, meta_title,
+			meta_description, tags, $3, $3
+		FROM products WHERE id = $4
+		RETURNING id`,
+		clone.Name, clone.SKU, clone.CreatedAt, id,
+	).Scan(&clone.ID)
+	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product with SKU %s", clone.SKU)); existsErr != nil {
+			return nil, existsErr
+		}
+		return nil, fmt.Errorf("failed to create product copy: %w", err)
+	}
+	clone.Version = 1
+
+	// Copy variants with fresh SKUs and zero stock
+	var variants []*domain.ProductVariant
+	err = tx.SelectContext(ctx, &variants, `SELECT * FROM product_variants WHERE product_id = $1 ORDER BY position, id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+
+	for _, variant := range variants {
+		variantSKU, err := nextCopySKU(ctx, tx, variant.SKU)
+		if err != nil {
+			return nil, err
+		}
+
+		var variantID int64
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO product_variants (
+				product_id, name, sku, price, compare_price, cost_price,
+				weight, quantity, is_active, position
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9)
+			RETURNING id`,
+			clone.ID, variant.Name, variantSKU, variant.Price, variant.ComparePrice, variant.CostPrice,
+			variant.Weight, variant.IsActive, variant.Position,
+		).Scan(&variantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy product variant: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO variant_attributes (variant_id, attribute_name, value)
+			SELECT $1, attribute_name, value FROM variant_attributes WHERE variant_id = $2`,
+			variantID, variant.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy variant attributes: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_attribute_values (product_id, attribute_id, value)
+		SELECT $1, attribute_id, value FROM product_attribute_values WHERE product_id = $2`,
+		clone.ID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy product attributes: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_categories (product_id, category_id, is_primary)
+		SELECT $1, category_id, is_primary FROM product_categories WHERE product_id = $2`,
+		clone.ID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy product categories: %w", err)
+	}
+
+	if includeImages {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO product_images (product_id, url, alt, position, is_primary)
+			SELECT $1, url, alt, position, is_primary FROM product_images WHERE product_id = $2`,
+			clone.ID, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy product images: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &clone, nil
+}
+
+// UpdateProduct updates an existing product. product.Version must be the version the
+// caller read; ErrVersionConflict is returned if the row has changed since.
 func (r *productRepository) UpdateProduct(ctx context.Context, id int64, product *domain.Product) error {
 	query := `
 		UPDATE products SET
 			name = :name, description = :description, short_description = :short_description,
 			sku = :sku, price = :price, compare_price = :compare_price, cost_price = :cost_price,
 			weight = :weight, dimensions = :dimensions, is_active = :is_active,
-			is_digital = :is_digital, requires_shipping = :requires_shipping, taxable = :taxable,
+			is_digital = :is_digital, is_free = :is_free, requires_shipping = :requires_shipping, taxable = :taxable,
 			track_quantity = :track_quantity, quantity = :quantity, min_quantity = :min_quantity,
 			max_quantity = :max_quantity, meta_title = :meta_title, meta_description = :meta_description,
-			tags = :tags, updated_at = :updated_at
-		WHERE id = :id`
+			tags = :tags, updated_at = :updated_at, updated_by = :updated_by,
+			version = version + 1
+		WHERE id = :id AND version = :version`
 
 	product.UpdatedAt = time.Now()
+	product.UpdatedBy = userctx.UserIDPtr(ctx)
 	product.ID = id
 
 	result, err := r.db.NamedExecContext(ctx, query, product)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product with SKU %s", product.SKU)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to update product: %w", err)
 	}
 
@@ -142,9 +414,17 @@ func (r *productRepository) UpdateProduct(ctx context.Context, id int64, product
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product with ID %d not found", id)
+		var exists bool
+		if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, id); err != nil {
+			return fmt.Errorf("failed to check product: %w", err)
+		}
+		if exists {
+			return ErrVersionConflict
+		}
+		return notFoundf(ErrProductNotFound, "product with ID %d not found", id)
 	}
 
+	product.Version++
 	return nil
 }
 
@@ -163,12 +443,55 @@ func (r *productRepository) DeleteProduct(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product with ID %d not found", id)
+		return notFoundf(ErrProductNotFound, "product with ID %d not found", id)
 	}
 
 	return nil
 }
 
+// BulkSetProductsActive sets is_active on every listed product in one transaction and
+// returns the IDs that were updated. IDs that don't exist are skipped.
+func (r *productRepository) BulkSetProductsActive(ctx context.Context, ids []int64, active bool) ([]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var updated []int64
+	query := `UPDATE products SET is_active = $1, updated_at = $2, updated_by = $3, version = version + 1 WHERE id = ANY($4) RETURNING id`
+	if err := tx.SelectContext(ctx, &updated, query, active, time.Now(), userctx.UserIDPtr(ctx), pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to update products: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// BulkDeleteProducts deletes every listed product in one transaction and returns the IDs
+// that were deleted. IDs that don't exist are skipped; any other failure deletes nothing.
+func (r *productRepository) BulkDeleteProducts(ctx context.Context, ids []int64) ([]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deleted []int64
+	if err := tx.SelectContext(ctx, &deleted, `DELETE FROM products WHERE id = ANY($1) RETURNING id`, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to delete products: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
 // ListProducts retrieves products with filters
 func (r *productRepository) ListProducts(ctx context.Context, filter *domain.ProductFilter, offset, limit int) ([]*domain.Product, int64, error) {
 	whereClause, args := r.buildWhereClause(filter)
@@ -185,10 +508,10 @@ func (r *productRepository) ListProducts(ctx context.Context, filter *domain.Pro
 	// List query
 	orderClause := r.buildOrderClause(filter)
 	query := fmt.Sprintf(`
-    SELECT * FROM products 
+    SELECT %s FROM products 
     %s 
     %s 
-    LIMIT $%d OFFSET $%d`, whereClause, orderClause, len(args)+1, len(args)+2)
+    LIMIT $%d OFFSET $%d`, productColumns, whereClause, orderClause, len(args)+1, len(args)+2)
 
 	args = append(args, limit, offset)
 
@@ -201,14 +524,54 @@ func (r *productRepository) ListProducts(ctx context.Context, filter *domain.Pro
 	return products, total, nil
 }
 
-// GetProductsByCategory retrieves products by category
-func (r *productRepository) GetProductsByCategory(ctx context.Context, categoryID int64, offset, limit int) ([]*domain.Product, int64, error) {
+// ListProductsAfter retrieves up to limit products matching filter, newest first, that
+// come after the cursor; a nil cursor starts from the newest. Unlike ListProducts it
+// seeks rather than skips, so deep pages cost the same as the first, and it does not
+// count the matches. The filter's sort fields are ignored.
+func (r *productRepository) ListProductsAfter(ctx context.Context, filter *domain.ProductFilter, after *domain.ProductCursor, limit int) ([]*domain.Product, error) {
+	whereClause, args := r.buildWhereClause(filter)
+
+	if after != nil {
+		condition := fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)+1, len(args)+2)
+		if whereClause == "" {
+			whereClause = "WHERE " + condition
+		} else {
+			whereClause += " AND " + condition
+		}
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query := fmt.Sprintf(`
+    SELECT %s FROM products
+    %s
+    ORDER BY created_at DESC, id DESC
+    LIMIT $%d`, productColumns, whereClause, len(args)+1)
+
+	args = append(args, limit)
+
+	var products []*domain.Product
+	err := r.db.SelectContext(ctx, &products, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetProductsByCategory retrieves products by category, only active ones when activeOnly
+// is set
+func (r *productRepository) GetProductsByCategory(ctx context.Context, categoryID int64, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
+	where := "pc.category_id = ?"
+	if activeOnly {
+		where += " AND p.is_active = true"
+	}
+
 	// Count query
 	countQuery := `
 		SELECT COUNT(DISTINCT p.id) 
 		FROM products p 
 		INNER JOIN product_categories pc ON p.id = pc.product_id 
-		WHERE pc.category_id = ?`
+		WHERE ` + where
 	countQuery = r.db.Rebind(countQuery)
 	var total int64
 	err := r.db.GetContext(ctx, &total, countQuery, categoryID)
@@ -218,10 +581,10 @@ func (r *productRepository) GetProductsByCategory(ctx context.Context, categoryI
 
 	// List query
 	query := `
-		SELECT DISTINCT p.* 
+		SELECT DISTINCT ` + qualifiedProductColumns + `
 		FROM products p 
 		INNER JOIN product_categories pc ON p.id = pc.product_id 
-		WHERE pc.category_id = ? 
+		WHERE ` + where + `
 		ORDER BY p.created_at DESC 
 		LIMIT ? OFFSET ?`
 	query = r.db.Rebind(query)
@@ -234,15 +597,74 @@ func (r *productRepository) GetProductsByCategory(ctx context.Context, categoryI
 	return products, total, nil
 }
 
-// SearchProducts searches products by name, description, or SKU
-func (r *productRepository) SearchProducts(ctx context.Context, query string, offset, limit int) ([]*domain.Product, int64, error) {
+// SearchProducts searches products by name, description, tags or SKU. Full-text mode
+// lists exact name matches first and then ranks by ts_rank, so name hits outrank tag and
+// description hits. It falls back to trigram matching when the query is too short to
+// search by word. Trigram mode matches substrings. With activeOnly set inactive products
+// are left out.
+func (r *productRepository) SearchProducts(ctx context.Context, query, mode string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
+	if mode != domain.ProductSearchTrigram {
+		if tsQuery := buildTSQuery(query); tsQuery != "" {
+			return r.searchProductsFullText(ctx, query, tsQuery, activeOnly, offset, limit)
+		}
+	}
+	return r.searchProductsTrigram(ctx, query, activeOnly, offset, limit)
+}
+
+// activeOnlyCondition narrows a products WHERE clause to active products when activeOnly
+// is set
+func activeOnlyCondition(activeOnly bool) string {
+	if activeOnly {
+		return " AND is_active = true"
+	}
+	return ""
+}
+
+// searchProductsFullText matches tsQuery against the generated search_vector column
+func (r *productRepository) searchProductsFullText(ctx context.Context, query, tsQuery string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
+	where := "search_vector @@ to_tsquery('english', $1)" + activeOnlyCondition(activeOnly)
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM products
+		WHERE ` + where
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, countQuery, tsQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	searchQuery := `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE ` + where + `
+		ORDER BY
+			CASE WHEN lower(name) = lower($2) THEN 0 ELSE 1 END,
+			ts_rank(search_vector, to_tsquery('english', $1)) DESC,
+			id DESC
+		LIMIT $3 OFFSET $4`
+	var products []*domain.Product
+	err = r.db.SelectContext(ctx, &products, searchQuery, tsQuery, strings.TrimSpace(query), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	return products, total, nil
+}
+
+// searchProductsTrigram matches query as a substring; the trigram indexes keep the
+// unanchored ILIKE off a sequential scan
+func (r *productRepository) searchProductsTrigram(ctx context.Context, query string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
 	searchTerm := "%" + query + "%"
+	where := "(name ILIKE $1 OR description ILIKE $2 OR sku ILIKE $3 OR product_tags_text(tags) ILIKE $4)" +
+		activeOnlyCondition(activeOnly)
 
 	// Count query
 	countQuery := `
 		SELECT COUNT(*) 
 		FROM products 
-		WHERE name ILIKE $1 OR description ILIKE $2 OR sku ILIKE $3 OR tags ILIKE $4`
+		WHERE ` + where
 
 	var total int64
 	err := r.db.GetContext(ctx, &total, countQuery, searchTerm, searchTerm, searchTerm, searchTerm)
@@ -252,9 +674,9 @@ func (r *productRepository) SearchProducts(ctx context.Context, query string, of
 
 	// Search query
 	searchQuery := `
-		SELECT * 
+		SELECT ` + productColumns + `
 		FROM products 
-		WHERE name ILIKE $1 OR description ILIKE $2 OR sku ILIKE $3 OR tags ILIKE $4
+		WHERE ` + where + `
 		ORDER BY 
 			CASE 
 				WHEN name ILIKE $5 THEN 1
@@ -262,7 +684,8 @@ func (r *productRepository) SearchProducts(ctx context.Context, query string, of
 				WHEN description ILIKE $7 THEN 3
 				ELSE 4
 			END,
-			name
+			name,
+			id
 		LIMIT $8 OFFSET $9`
 	var products []*domain.Product
 	err = r.db.SelectContext(ctx, &products, searchQuery,
@@ -276,11 +699,65 @@ func (r *productRepository) SearchProducts(ctx context.Context, query string, of
 	return products, total, nil
 }
 
+// minFullTextQueryLength is the fewest letters and digits a query needs before it is
+// searched by word; shorter prefixes match too loosely against stemmed lexemes
+const minFullTextQueryLength = 3
+
+// buildTSQuery turns free text into a to_tsquery expression that requires every word,
+// treating the last one as a prefix since it may still be being typed. Punctuation is
+// dropped so user input cannot inject tsquery operators. It returns "" when the query is
+// too short for full-text search.
+func buildTSQuery(query string) string {
+	terms := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	length := 0
+	for _, term := range terms {
+		length += len([]rune(term))
+	}
+	if length < minFullTextQueryLength {
+		return ""
+	}
+
+	for i, term := range terms {
+		terms[i] = strings.ToLower(term)
+	}
+	return strings.Join(terms, " & ") + ":*"
+}
+
+// SuggestProducts returns active products whose name starts with prefix, case-insensitively.
+// The lower(name) text_pattern_ops index keeps the anchored LIKE an index range scan.
+func (r *productRepository) SuggestProducts(ctx context.Context, prefix string, limit int) ([]*domain.ProductSuggestion, error) {
+	query := `
+		SELECT id, name, sku
+		FROM products
+		WHERE is_active = true AND lower(name) LIKE $1
+		ORDER BY name ASC
+		LIMIT $2`
+
+	pattern := escapeLikePattern(strings.ToLower(prefix)) + "%"
+
+	var suggestions []*domain.ProductSuggestion
+	err := r.db.SelectContext(ctx, &suggestions, query, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// escapeLikePattern escapes LIKE wildcards so user input matches literally
+func escapeLikePattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(s)
+}
+
 // UpdateProductQuantity updates product quantity
 func (r *productRepository) UpdateProductQuantity(ctx context.Context, id int64, quantity int) error {
-	query := `UPDATE products SET quantity = $1, updated_at = $2 WHERE id = $3`
+	query := `UPDATE products SET quantity = $1, updated_at = $2, updated_by = $3, version = version + 1 WHERE id = $4`
 
-	result, err := r.db.ExecContext(ctx, query, quantity, time.Now(), id)
+	result, err := r.db.ExecContext(ctx, query, quantity, time.Now(), userctx.UserIDPtr(ctx), id)
 	if err != nil {
 		return fmt.Errorf("failed to update product quantity: %w", err)
 	}
@@ -291,49 +768,38 @@ func (r *productRepository) UpdateProductQuantity(ctx context.Context, id int64,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product with ID %d not found", id)
+		return notFoundf(ErrProductNotFound, "product with ID %d not found", id)
 	}
 
 	return nil
 }
 
-// GetProductsByTags retrieves products by tags
-func (r *productRepository) GetProductsByTags(ctx context.Context, tags []string, offset, limit int) ([]*domain.Product, int64, error) {
+// GetProductsByTags retrieves products carrying every one of tags. Tags match whole and
+// exactly, served by the GIN index on the tags array. With activeOnly set inactive
+// products are left out.
+func (r *productRepository) GetProductsByTags(ctx context.Context, tags []string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
 	if len(tags) == 0 {
 		return []*domain.Product{}, 0, nil
 	}
-
-	// Build tag conditions
-	var tagConditions []string
-	var args []interface{}
-
-	for _, tag := range tags {
-		tagConditions = append(tagConditions, "tags LIKE ?")
-		args = append(args, "%"+tag+"%")
-	}
-
-	whereClause := "WHERE " + strings.Join(tagConditions, " OR ")
+	where := "tags @> $1" + activeOnlyCondition(activeOnly)
 
 	// Count query
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereClause)
-	countQuery = r.db.Rebind(countQuery)
+	countQuery := `SELECT COUNT(*) FROM products WHERE ` + where
 	var total int64
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := r.db.GetContext(ctx, &total, countQuery, pq.Array(tags))
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count products by tags: %w", err)
 	}
 
 	// List query
-	query := fmt.Sprintf(`
-		SELECT * FROM products 
-		%s 
-		ORDER BY created_at DESC 
-		LIMIT ? OFFSET ?`, whereClause)
-	query = r.db.Rebind(query)
-	args = append(args, limit, offset)
+	query := `
+		SELECT ` + productColumns + ` FROM products
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
 
 	var products []*domain.Product
-	err = r.db.SelectContext(ctx, &products, query, args...)
+	err = r.db.SelectContext(ctx, &products, query, pq.Array(tags), limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get products by tags: %w", err)
 	}
@@ -358,6 +824,9 @@ func (r *productRepository) CreateProductVariant(ctx context.Context, variant *d
 	// Use NamedQueryContext to fetch the generated id
 	rows, err := r.db.NamedQueryContext(ctx, query, variant)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product variant with SKU %s", variant.SKU)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to create product variant: %w", err)
 	}
 	defer rows.Close()
@@ -379,7 +848,7 @@ func (r *productRepository) GetProductVariantByID(ctx context.Context, id int64)
 	err := r.db.GetContext(ctx, &variant, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product variant with ID %d not found", id)
+			return nil, notFoundf(ErrProductVariantNotFound, "product variant with ID %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get product variant: %w", err)
 	}
@@ -387,6 +856,29 @@ func (r *productRepository) GetProductVariantByID(ctx context.Context, id int64)
 	return &variant, nil
 }
 
+// GetProductVariantsByIDs retrieves several product variants in one query, keyed by ID.
+// IDs that do not exist are absent from the map.
+func (r *productRepository) GetProductVariantsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.ProductVariant, error) {
+	result := make(map[int64]*domain.ProductVariant, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT * FROM product_variants WHERE id = ANY($1)`
+
+	var variants []*domain.ProductVariant
+	err := r.db.SelectContext(ctx, &variants, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+
+	for _, variant := range variants {
+		result[variant.ID] = variant
+	}
+
+	return result, nil
+}
+
 // GetProductVariantsByProductID retrieves all variants for a product
 func (r *productRepository) GetProductVariantsByProductID(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
 	query := `SELECT * FROM product_variants WHERE product_id = $1 ORDER BY position, name`
@@ -413,6 +905,9 @@ func (r *productRepository) UpdateProductVariant(ctx context.Context, id int64,
 
 	result, err := r.db.NamedExecContext(ctx, query, variant)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product variant with SKU %s", variant.SKU)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to update product variant: %w", err)
 	}
 
@@ -422,7 +917,7 @@ func (r *productRepository) UpdateProductVariant(ctx context.Context, id int64,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product variant with ID %d not found", id)
+		return notFoundf(ErrProductVariantNotFound, "product variant with ID %d not found", id)
 	}
 
 	return nil
@@ -443,7 +938,7 @@ func (r *productRepository) DeleteProductVariant(ctx context.Context, id int64)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product variant with ID %d not found", id)
+		return notFoundf(ErrProductVariantNotFound, "product variant with ID %d not found", id)
 	}
 
 	return nil
@@ -459,6 +954,9 @@ func (r *productRepository) AddProductToCategory(ctx context.Context, productID,
 
 	_, err := r.db.ExecContext(ctx, query, productID, categoryID, isPrimary)
 	if err != nil {
+		if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product %d in category %d", productID, categoryID)); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to add product to category: %w", err)
 	}
 
@@ -480,7 +978,7 @@ func (r *productRepository) RemoveProductFromCategory(ctx context.Context, produ
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("product-category relationship not found")
+		return notFoundf(ErrNotFound, "product-category relationship not found")
 	}
 
 	return nil
@@ -504,8 +1002,15 @@ func (r *productRepository) GetProductCategories(ctx context.Context, productID
 	return categories, nil
 }
 
-// UpdateProductCategories updates all categories for a product
-func (r *productRepository) UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64) error {
+// UpdateProductCategories replaces the categories of a product. primaryCategoryID picks
+// the primary category; when it is nil the current primary is kept if it is still in
+// the list, and otherwise the first category becomes primary.
+func (r *productRepository) UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64, primaryCategoryID *int64) error {
+	// Validate all category IDs up front so callers learn which ones are invalid
+	if err := r.validateCategoryIDs(ctx, categoryIDs); err != nil {
+		return err
+	}
+
 	// Start transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -513,6 +1018,21 @@ func (r *productRepository) UpdateProductCategories(ctx context.Context, product
 	}
 	defer tx.Rollback()
 
+	var primaryID int64
+	if primaryCategoryID != nil {
+		primaryID = *primaryCategoryID
+	} else if len(categoryIDs) > 0 {
+		primaryID = categoryIDs[0]
+		var currentID int64
+		err = tx.GetContext(ctx, &currentID, "SELECT category_id FROM product_categories WHERE product_id = $1 AND is_primary = true", productID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get primary category: %w", err)
+		}
+		if err == nil && slices.Contains(categoryIDs, currentID) {
+			primaryID = currentID
+		}
+	}
+
 	// Remove existing categories
 	_, err = tx.ExecContext(ctx, "DELETE FROM product_categories WHERE product_id = $1", productID)
 	if err != nil {
@@ -520,12 +1040,15 @@ func (r *productRepository) UpdateProductCategories(ctx context.Context, product
 	}
 
 	// Add new categories
-	for i, categoryID := range categoryIDs {
-		isPrimary := i == 0 // First category is primary
+	for _, categoryID := range categoryIDs {
+		isPrimary := categoryID == primaryID
 		_, err = tx.ExecContext(ctx,
 			"INSERT INTO product_categories (product_id, category_id, is_primary) VALUES ($1, $2, $3)",
 			productID, categoryID, isPrimary)
 		if err != nil {
+			if existsErr := classifyUniqueViolation(err, fmt.Sprintf("product %d in category %d", productID, categoryID)); existsErr != nil {
+				return existsErr
+			}
 			return fmt.Errorf("failed to add category: %w", err)
 		}
 	}
@@ -540,6 +1063,37 @@ func (r *productRepository) UpdateProductCategories(ctx context.Context, product
 
 // Helper methods
 
+// validateCategoryIDs returns an InvalidCategoryIDsError listing any IDs that do not exist
+func (r *productRepository) validateCategoryIDs(ctx context.Context, categoryIDs []int64) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	var existingIDs []int64
+	err := r.db.SelectContext(ctx, &existingIDs, "SELECT id FROM categories WHERE id = ANY($1)", pq.Array(categoryIDs))
+	if err != nil {
+		return fmt.Errorf("failed to validate category IDs: %w", err)
+	}
+
+	existing := make(map[int64]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+
+	var invalidIDs []int64
+	for _, id := range categoryIDs {
+		if !existing[id] {
+			invalidIDs = append(invalidIDs, id)
+		}
+	}
+
+	if len(invalidIDs) > 0 {
+		return &InvalidCategoryIDsError{IDs: invalidIDs}
+	}
+
+	return nil
+}
+
 // buildWhereClause builds the WHERE clause for product filtering
 func (r *productRepository) buildWhereClause(filter *domain.ProductFilter) (string, []interface{}) {
 	var conditions []string
@@ -581,7 +1135,7 @@ func (r *productRepository) buildWhereClause(filter *domain.ProductFilter) (stri
 	}
 
 	if filter.Search != "" {
-		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d OR sku ILIKE $%d OR tags ILIKE $%d)",
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d OR sku ILIKE $%d OR product_tags_text(tags) ILIKE $%d)",
 			argIndex, argIndex+1, argIndex+2, argIndex+3))
 		searchTerm := "%" + filter.Search + "%"
 		args = append(args, searchTerm, searchTerm, searchTerm, searchTerm)
@@ -589,13 +1143,9 @@ func (r *productRepository) buildWhereClause(filter *domain.ProductFilter) (stri
 	}
 
 	if len(filter.Tags) > 0 {
-		var tagConditions []string
-		for _, tag := range filter.Tags {
-			tagConditions = append(tagConditions, fmt.Sprintf("tags ILIKE $%d", argIndex))
-			args = append(args, "%"+tag+"%")
-			argIndex++
-		}
-		conditions = append(conditions, "("+strings.Join(tagConditions, " OR ")+")")
+		conditions = append(conditions, fmt.Sprintf("tags @> $%d", argIndex))
+		args = append(args, pq.Array(filter.Tags))
+		argIndex++
 	}
 
 	whereClause := ""
@@ -611,16 +1161,10 @@ func (r *productRepository) buildOrderClause(filter *domain.ProductFilter) strin
 	sortBy := "created_at"
 	sortOrder := "DESC"
 
-	if filter.SortBy != "" {
-		validSortFields := map[string]string{
-			"name":       "name",
-			"price":      "price",
-			"created_at": "created_at",
-			"updated_at": "updated_at",
-			"sku":        "sku",
-		}
-		if field, ok := validSortFields[filter.SortBy]; ok {
+	for _, field := range domain.ProductSortFields {
+		if filter.SortBy == field {
 			sortBy = field
+			break
 		}
 	}
 
@@ -630,20 +1174,155 @@ func (r *productRepository) buildOrderClause(filter *domain.ProductFilter) strin
 		}
 	}
 
-	return fmt.Sprintf("ORDER BY %s %s", sortBy, sortOrder)
+	// id breaks ties so rows sharing a sort value keep the same order across pages
+	return fmt.Sprintf("ORDER BY %s %s, id %s", sortBy, sortOrder, sortOrder)
 }
 
-// GetProductVariantsByProductIDAndSKU retrieves all variants for a product and SKU
-func (r *productRepository) GetProductVariantsByProductIDAndSKU(ctx context.Context, productID int64, sku string) ([]*domain.ProductVariant, error) {
-	query := `SELECT * FROM product_variants WHERE product_id = $1 AND sku = $2`
+// GetProductVariantBySKU retrieves the variant with a SKU, whichever product it belongs to
+func (r *productRepository) GetProductVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	query := `SELECT * FROM product_variants WHERE sku = $1`
 
-	var variants []*domain.ProductVariant
-	err := r.db.SelectContext(ctx, &variants, query, productID, sku)
+	var variant domain.ProductVariant
+	err := r.db.GetContext(ctx, &variant, query, sku)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product variants: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, notFoundf(ErrProductVariantNotFound, "product variant with SKU %s not found", sku)
+		}
+		return nil, fmt.Errorf("failed to get product variant: %w", err)
 	}
 
-	return variants, nil
+	return &variant, nil
+}
+
+// Variant Attribute methods
+
+// SetVariantAttributes replaces all attributes of a variant
+func (r *productRepository) SetVariantAttributes(ctx context.Context, variantID int64, attrs map[string]string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM variant_attributes WHERE variant_id = $1", variantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove existing variant attributes: %w", err)
+	}
+
+	names, values := sortedAttributePairs(attrs)
+	for i := range names {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO variant_attributes (variant_id, attribute_name, value) VALUES ($1, $2, $3)",
+			variantID, names[i], values[i])
+		if err != nil {
+			return fmt.Errorf("failed to add variant attribute: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetVariantAttributes retrieves all attributes of a variant
+func (r *productRepository) GetVariantAttributes(ctx context.Context, variantID int64) ([]*domain.VariantAttribute, error) {
+	query := `
+		SELECT id, variant_id, attribute_name, value, created_at
+		FROM variant_attributes
+		WHERE variant_id = $1
+		ORDER BY attribute_name`
+
+	var attributes []*domain.VariantAttribute
+	err := r.db.SelectContext(ctx, &attributes, query, variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant attributes: %w", err)
+	}
+
+	return attributes, nil
+}
+
+// GetVariantByAttributes resolves the variant whose attributes exactly match the selection
+func (r *productRepository) GetVariantByAttributes(ctx context.Context, productID int64, attrs map[string]string) (*domain.ProductVariant, error) {
+	if len(attrs) == 0 {
+		return nil, ErrVariantCombinationNotFound
+	}
+
+	query := `
+		SELECT pv.*
+		FROM product_variants pv
+		WHERE pv.product_id = $1
+			AND pv.id IN (
+				SELECT va.variant_id
+				FROM variant_attributes va
+				GROUP BY va.variant_id
+				HAVING COUNT(*) = $2
+					AND COUNT(*) FILTER (
+						WHERE (va.attribute_name, va.value) IN (SELECT * FROM unnest($3::text[], $4::text[]))
+					) = $2
+			)
+		ORDER BY pv.position, pv.id
+		LIMIT 1`
+
+	names, values := sortedAttributePairs(attrs)
+
+	var variant domain.ProductVariant
+	err := r.db.GetContext(ctx, &variant, query, productID, len(attrs), pq.Array(names), pq.Array(values))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVariantCombinationNotFound
+		}
+		return nil, fmt.Errorf("failed to get variant by attributes: %w", err)
+	}
+
+	return &variant, nil
+}
+
+// GetVariantAttributeOptions lists each attribute and its distinct values across a product's variants
+func (r *productRepository) GetVariantAttributeOptions(ctx context.Context, productID int64) ([]*domain.VariantAttributeOption, error) {
+	query := `
+		SELECT DISTINCT va.attribute_name, va.value
+		FROM variant_attributes va
+		JOIN product_variants pv ON pv.id = va.variant_id
+		WHERE pv.product_id = $1
+		ORDER BY va.attribute_name, va.value`
+
+	var rows []struct {
+		AttributeName string `db:"attribute_name"`
+		Value         string `db:"value"`
+	}
+	err := r.db.SelectContext(ctx, &rows, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant attribute options: %w", err)
+	}
+
+	options := []*domain.VariantAttributeOption{}
+	for _, row := range rows {
+		if len(options) == 0 || options[len(options)-1].Name != row.AttributeName {
+			options = append(options, &domain.VariantAttributeOption{Name: row.AttributeName})
+		}
+		last := options[len(options)-1]
+		last.Values = append(last.Values, row.Value)
+	}
+
+	return options, nil
+}
+
+// sortedAttributePairs splits an attribute map into parallel name/value slices ordered by name
+func sortedAttributePairs(attrs map[string]string) ([]string, []string) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = attrs[name]
+	}
+
+	return names, values
 }
 
 func (r *productRepository) CheckCategoryHasProducts(ctx context.Context, categoryID int64) (bool, error) {
@@ -0,0 +1,910 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	cleanup := func() {
+		sqlxDB.Close()
+	}
+
+	return sqlxDB, mock, cleanup
+}
+
+// productColumnsPattern matches productColumns in a query, which sqlmock sees with its
+// whitespace collapsed
+var productColumnsPattern = regexp.QuoteMeta(strings.Join(strings.Fields(productColumns), " "))
+
+func TestProductRepository_UpdateProductCategories_InvalidCategoryIDs(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT id FROM categories WHERE id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]int64{1, 99})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	err := repo.UpdateProductCategories(context.Background(), 10, []int64{1, 99}, nil)
+
+	var invalidErr *InvalidCategoryIDsError
+	require.True(t, errors.As(err, &invalidErr))
+	assert.Equal(t, []int64{99}, invalidErr.IDs)
+	assert.Contains(t, err.Error(), "99")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_UpdateProductCategories_Success(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT id FROM categories WHERE id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]int64{1, 2})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT category_id FROM product_categories WHERE product_id = \$1 AND is_primary = true`).
+		WithArgs(int64(10)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`DELETE FROM product_categories WHERE product_id = \$1`).
+		WithArgs(int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO product_categories`).
+		WithArgs(int64(10), int64(1), true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO product_categories`).
+		WithArgs(int64(10), int64(2), false).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err := repo.UpdateProductCategories(context.Background(), 10, []int64{1, 2}, nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_UpdateProductCategories_Primary(t *testing.T) {
+	expectReplace := func(mock sqlmock.Sqlmock, primaryID int64) {
+		mock.ExpectExec(`DELETE FROM product_categories WHERE product_id = \$1`).
+			WithArgs(int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		for _, id := range []int64{1, 2, 3} {
+			mock.ExpectExec(`INSERT INTO product_categories`).
+				WithArgs(int64(10), id, id == primaryID).
+				WillReturnResult(sqlmock.NewResult(id, 1))
+		}
+		mock.ExpectCommit()
+	}
+
+	t.Run("explicit primary", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		mock.ExpectQuery(`SELECT id FROM categories WHERE id = ANY\(\$1\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+		mock.ExpectBegin()
+		expectReplace(mock, 3)
+
+		primaryID := int64(3)
+		err := repo.UpdateProductCategories(context.Background(), 10, []int64{1, 2, 3}, &primaryID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps the current primary when it is still listed", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		mock.ExpectQuery(`SELECT id FROM categories WHERE id = ANY\(\$1\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT category_id FROM product_categories WHERE product_id = \$1 AND is_primary = true`).
+			WithArgs(int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(2))
+		expectReplace(mock, 2)
+
+		err := repo.UpdateProductCategories(context.Background(), 10, []int64{1, 2, 3}, nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetVariantByAttributes(t *testing.T) {
+	variantQuery := `SELECT pv\.\* FROM product_variants pv WHERE pv\.product_id = \$1 AND pv\.id IN`
+
+	t.Run("valid combination", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		rows := sqlmock.NewRows([]string{"id", "product_id", "name", "sku", "price", "compare_price", "cost_price", "weight", "quantity", "is_active", "position", "created_at", "updated_at"}).
+			AddRow(7, 10, "Red / M", "TSHIRT-RED-M", 19.99, 0, 0, 0.2, 5, true, 0, time.Now(), time.Now())
+
+		// Attributes are passed sorted by name so the query is deterministic
+		mock.ExpectQuery(variantQuery).
+			WithArgs(int64(10), 2, pq.Array([]string{"color", "size"}), pq.Array([]string{"red", "M"})).
+			WillReturnRows(rows)
+
+		variant, err := repo.GetVariantByAttributes(context.Background(), 10, map[string]string{"size": "M", "color": "red"})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), variant.ID)
+		assert.Equal(t, "TSHIRT-RED-M", variant.SKU)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("impossible combination", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(variantQuery).
+			WithArgs(int64(10), 2, pq.Array([]string{"color", "size"}), pq.Array([]string{"green", "XXL"})).
+			WillReturnError(sql.ErrNoRows)
+
+		variant, err := repo.GetVariantByAttributes(context.Background(), 10, map[string]string{"size": "XXL", "color": "green"})
+
+		assert.Nil(t, variant)
+		assert.ErrorIs(t, err, ErrVariantCombinationNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty selection", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		_, err := repo.GetVariantByAttributes(context.Background(), 10, map[string]string{})
+
+		assert.ErrorIs(t, err, ErrVariantCombinationNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetVariantAttributeOptions(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	rows := sqlmock.NewRows([]string{"attribute_name", "value"}).
+		AddRow("color", "blue").
+		AddRow("color", "red").
+		AddRow("size", "L").
+		AddRow("size", "M")
+
+	mock.ExpectQuery(`SELECT DISTINCT va\.attribute_name, va\.value FROM variant_attributes va`).
+		WithArgs(int64(10)).
+		WillReturnRows(rows)
+
+	options, err := repo.GetVariantAttributeOptions(context.Background(), 10)
+
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+	assert.Equal(t, "color", options[0].Name)
+	assert.Equal(t, []string{"blue", "red"}, options[0].Values)
+	assert.Equal(t, "size", options[1].Name)
+	assert.Equal(t, []string{"L", "M"}, options[1].Values)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_SuggestProducts(t *testing.T) {
+	t.Run("matches lowercased prefix", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT id, name, sku FROM products WHERE is_active = true AND lower\(name\) LIKE \$1 ORDER BY name ASC LIMIT \$2`).
+			WithArgs("gea%", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "sku"}).
+				AddRow(1, "Gear Shifter", "GS-001").
+				AddRow(2, "Gearbox Oil", "GO-002"))
+
+		suggestions, err := repo.SuggestProducts(context.Background(), "GeA", 5)
+
+		require.NoError(t, err)
+		require.Len(t, suggestions, 2)
+		assert.Equal(t, "Gear Shifter", suggestions[0].Name)
+		assert.Equal(t, "GO-002", suggestions[1].SKU)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("escapes wildcards in prefix", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT id, name, sku FROM products`).
+			WithArgs(`100\%\_off%`, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "sku"}))
+
+		suggestions, err := repo.SuggestProducts(context.Background(), "100%_off", 8)
+
+		require.NoError(t, err)
+		assert.Empty(t, suggestions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_CloneProduct(t *testing.T) {
+	productColumns := []string{"id", "name", "description", "short_description", "sku", "price", "compare_price", "cost_price",
+		"weight", "dimensions", "is_active", "is_digital", "requires_shipping", "taxable", "track_quantity", "quantity",
+		"min_quantity", "max_quantity", "meta_title", "meta_description", "tags", "created_at", "updated_at"}
+	variantColumns := []string{"id", "product_id", "name", "sku", "price", "compare_price", "cost_price",
+		"weight", "quantity", "is_active", "position", "created_at", "updated_at"}
+
+	expectSourceProduct := func(mock sqlmock.Sqlmock) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(productColumns).
+				AddRow(1, "Gear Shifter", "desc", "short", "GS-001", 99.99, 120.0, 50.0,
+					1.5, "10x10x10", true, false, true, true, true, 42,
+					1, 10, "meta", "meta desc", "{gear,shifter}", now, now))
+	}
+
+	t.Run("creates independent inactive copy", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectSourceProduct(mock)
+
+		// "-COPY" is taken, so the next free suffix is used
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs("GS-001-COPY").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs("GS-001-COPY-2").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		mock.ExpectQuery(`INSERT INTO products .* SELECT \$1, description, short_description, \$2, .* false, .* 0, .* FROM products WHERE id = \$4 RETURNING id`).
+			WithArgs("Gear Shifter (Copy)", "GS-001-COPY-2", sqlmock.AnyArg(), int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+		now := time.Now()
+		mock.ExpectQuery(`SELECT \* FROM product_variants WHERE product_id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(variantColumns).
+				AddRow(10, 1, "Red", "GS-001-R", 99.99, 0, 0, 1.5, 7, true, 1, now, now))
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs("GS-001-R-COPY").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`INSERT INTO product_variants .* VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, 0, \$8, \$9\)`).
+			WithArgs(int64(2), "Red", "GS-001-R-COPY", 99.99, 0.0, 0.0, 1.5, true, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(20))
+		mock.ExpectExec(`INSERT INTO variant_attributes .* SELECT \$1, attribute_name, value FROM variant_attributes WHERE variant_id = \$2`).
+			WithArgs(int64(20), int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		mock.ExpectExec(`INSERT INTO product_attribute_values`).
+			WithArgs(int64(2), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO product_categories .* SELECT \$1, category_id, is_primary FROM product_categories WHERE product_id = \$2`).
+			WithArgs(int64(2), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		clone, err := repo.CloneProduct(context.Background(), 1, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), clone.ID)
+		assert.Equal(t, "GS-001-COPY-2", clone.SKU)
+		assert.Equal(t, "Gear Shifter (Copy)", clone.Name)
+		assert.False(t, clone.IsActive)
+		assert.Equal(t, 0, clone.Quantity)
+		assert.Equal(t, 99.99, clone.Price)
+		// Images were not requested, so no product_images insert is expected
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("copies images when requested", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		expectSourceProduct(mock)
+
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs("GS-001-COPY").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`INSERT INTO products`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(`SELECT \* FROM product_variants WHERE product_id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(variantColumns))
+		mock.ExpectExec(`INSERT INTO product_attribute_values`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO product_categories`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO product_images .* SELECT \$1, url, alt, position, is_primary FROM product_images WHERE product_id = \$2`).
+			WithArgs(int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		clone, err := repo.CloneProduct(context.Background(), 1, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), clone.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("source not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE id = \$1`).
+			WithArgs(int64(99)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		clone, err := repo.CloneProduct(context.Background(), 99, false)
+
+		assert.Nil(t, clone)
+		assert.Contains(t, err.Error(), "not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetProductsByIDs(t *testing.T) {
+	t.Run("returns found products keyed by ID", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		// One query for every ID; 3 does not exist
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE id = ANY\(\$1\)`).
+			WithArgs("{1,2,3}").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "sku", "price"}).
+				AddRow(1, "Brake Pad", "BP-1", 19.99).
+				AddRow(2, "Oil Filter", "OF-2", 7.5))
+
+		products, err := repo.GetProductsByIDs(context.Background(), []int64{1, 2, 3})
+
+		require.NoError(t, err)
+		assert.Len(t, products, 2)
+		assert.Equal(t, "Brake Pad", products[1].Name)
+		assert.Equal(t, "Oil Filter", products[2].Name)
+		assert.NotContains(t, products, int64(3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no IDs skips the query", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		products, err := repo.GetProductsByIDs(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetProductVariantsByIDs(t *testing.T) {
+	t.Run("returns found variants keyed by ID", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		// One query for every ID; 9 does not exist
+		mock.ExpectQuery(`SELECT \* FROM product_variants WHERE id = ANY\(\$1\)`).
+			WithArgs("{7,8,9}").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "sku"}).
+				AddRow(7, 3, "BP-1-RED").
+				AddRow(8, 3, "BP-1-BLUE"))
+
+		variants, err := repo.GetProductVariantsByIDs(context.Background(), []int64{7, 8, 9})
+
+		require.NoError(t, err)
+		assert.Len(t, variants, 2)
+		assert.Equal(t, "BP-1-RED", variants[7].SKU)
+		assert.Equal(t, "BP-1-BLUE", variants[8].SKU)
+		assert.NotContains(t, variants, int64(9))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no IDs skips the query", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		variants, err := repo.GetProductVariantsByIDs(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, variants)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetProductVariantBySKU(t *testing.T) {
+	t.Run("finds a variant of any product", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT \* FROM product_variants WHERE sku = \$1`).
+			WithArgs("BP-1-RED").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "name", "sku"}).
+				AddRow(7, 3, "Red", "BP-1-RED"))
+
+		variant, err := repo.GetProductVariantBySKU(context.Background(), "BP-1-RED")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), variant.ID)
+		assert.Equal(t, int64(3), variant.ProductID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT \* FROM product_variants WHERE sku = \$1`).
+			WithArgs("MISSING").
+			WillReturnError(sql.ErrNoRows)
+
+		variant, err := repo.GetProductVariantBySKU(context.Background(), "MISSING")
+
+		assert.Nil(t, variant)
+		assert.EqualError(t, err, "product variant with SKU MISSING not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_BulkSetProductsActive(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE products SET is_active = \$1, updated_at = \$2, updated_by = \$3, version = version \+ 1 WHERE id = ANY\(\$4\) RETURNING id`).
+		WithArgs(false, sqlmock.AnyArg(), int64(42), pq.Array([]int64{1, 2, 99})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	ctx := userctx.WithUserID(context.Background(), 42)
+	updated, err := repo.BulkSetProductsActive(ctx, []int64{1, 2, 99}, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_BulkDeleteProducts(t *testing.T) {
+	t.Run("deletes existing ids and skips missing ones", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`DELETE FROM products WHERE id = ANY\(\$1\) RETURNING id`).
+			WithArgs(pq.Array([]int64{1, 99})).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		deleted, err := repo.BulkDeleteProducts(context.Background(), []int64{1, 99})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when the delete fails", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`DELETE FROM products WHERE id = ANY\(\$1\) RETURNING id`).
+			WithArgs(pq.Array([]int64{1, 2})).
+			WillReturnError(errors.New("foreign key violation"))
+		mock.ExpectRollback()
+
+		deleted, err := repo.BulkDeleteProducts(context.Background(), []int64{1, 2})
+
+		assert.Error(t, err)
+		assert.Nil(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_UniqueViolations(t *testing.T) {
+	uniqueViolation := &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+
+	t.Run("duplicate SKU on create", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`INSERT INTO products`).WillReturnError(uniqueViolation)
+
+		err := repo.CreateProduct(context.Background(), &domain.Product{Name: "Widget", SKU: "WIDGET-1"})
+
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+		assert.Equal(t, "product with SKU WIDGET-1 already exists", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicate product category", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectExec(`INSERT INTO product_categories`).
+			WithArgs(int64(10), int64(3), false).
+			WillReturnError(uniqueViolation)
+
+		err := repo.AddProductToCategory(context.Background(), 10, 3, false)
+
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+		assert.Equal(t, "product 10 in category 3 already exists", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_UpdateProduct_Version(t *testing.T) {
+	updateQuery := `UPDATE products SET .* version = version \+ 1 WHERE id = \? AND version = \?`
+
+	t.Run("fresh version is applied and bumped", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		product := &domain.Product{Name: "Widget", SKU: "WIDGET-1", Version: 3}
+		err := repo.UpdateProduct(context.Background(), 1, product)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, product.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM products WHERE id = \$1\)`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		product := &domain.Product{Name: "Widget", SKU: "WIDGET-1", Version: 2}
+		err := repo.UpdateProduct(context.Background(), 1, product)
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, 2, product.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_ListProducts_TieBreak(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products ORDER BY price ASC, id ASC LIMIT \$1 OFFSET \$2`).
+		WithArgs(10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := repo.ListProducts(context.Background(), &domain.ProductFilter{SortBy: "price", SortOrder: "asc"}, 20, 10)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_ListProductsAfter(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("seeks past the cursor after the filters", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+		active := true
+
+		mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE is_active = \$1 AND \(created_at, id\) < \(\$2, \$3\) ORDER BY created_at DESC, id DESC LIMIT \$4`).
+			WithArgs(true, createdAt, int64(42), 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+				AddRow(41, createdAt).
+				AddRow(40, createdAt.Add(-time.Minute)))
+
+		products, err := repo.ListProductsAfter(context.Background(), &domain.ProductFilter{IsActive: &active},
+			&domain.ProductCursor{CreatedAt: createdAt, ID: 42}, 11)
+
+		require.NoError(t, err)
+		require.Len(t, products, 2)
+		assert.Equal(t, int64(41), products[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("starts from the newest without a cursor", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products ORDER BY created_at DESC, id DESC LIMIT \$1`).
+			WithArgs(6).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		products, err := repo.ListProductsAfter(context.Background(), &domain.ProductFilter{}, nil, 6)
+
+		require.NoError(t, err)
+		assert.Empty(t, products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "single word", query: "gearbox", want: "gearbox:*"},
+		{name: "multiple words", query: "Steel  Gearbox", want: "steel & gearbox:*"},
+		{name: "punctuation splits words", query: "GS-001", want: "gs & 001:*"},
+		{name: "operators are dropped", query: "gear | !box & (oil)", want: "gear & box & oil:*"},
+		{name: "short prefix", query: "ge", want: ""},
+		{name: "short words", query: "a b", want: ""},
+		{name: "no words", query: "!?", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildTSQuery(tt.query))
+		})
+	}
+}
+
+func TestProductRepository_SearchProducts(t *testing.T) {
+	t.Run("full-text ranks exact name hits above description hits", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE search_vector @@ to_tsquery\('english', \$1\)$`).
+			WithArgs("steel & gearbox:*").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE search_vector @@ to_tsquery\('english', \$1\) `+
+			`ORDER BY CASE WHEN lower\(name\) = lower\(\$2\) THEN 0 ELSE 1 END, `+
+			`ts_rank\(search_vector, to_tsquery\('english', \$1\)\) DESC, id DESC LIMIT \$3 OFFSET \$4`).
+			WithArgs("steel & gearbox:*", "Steel Gearbox", 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).
+				AddRow(7, "Steel Gearbox", "Heavy duty").
+				AddRow(3, "Shift Kit", "Fits any steel gearbox"))
+
+		products, total, err := repo.SearchProducts(context.Background(), " Steel Gearbox ", domain.ProductSearchFullText, false, 0, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, products, 2)
+		assert.Equal(t, "Steel Gearbox", products[0].Name)
+		assert.Equal(t, "Shift Kit", products[1].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("short prefixes fall back to trigram matching", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE \(name ILIKE \$1`).
+			WithArgs("%ge%", "%ge%", "%ge%", "%ge%").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE \(name ILIKE \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.SearchProducts(context.Background(), "ge", domain.ProductSearchFullText, false, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("trigram mode matches substrings", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE \(name ILIKE \$1`).
+			WithArgs("%earbo%", "%earbo%", "%earbo%", "%earbo%").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE \(name ILIKE \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.SearchProducts(context.Background(), "earbo", domain.ProductSearchTrigram, false, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("shoppers only find active products", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE search_vector @@ to_tsquery\('english', \$1\) AND is_active = true$`).
+			WithArgs("gearbox:*").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE search_vector @@ to_tsquery\('english', \$1\) AND is_active = true ORDER BY`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.SearchProducts(context.Background(), "gearbox", domain.ProductSearchFullText, true, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the active filter covers every trigram match", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		// The ORed matches are grouped so is_active applies to all of them
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE \(name ILIKE \$1 OR description ILIKE \$2 OR sku ILIKE \$3 OR product_tags_text\(tags\) ILIKE \$4\) AND is_active = true$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT ` + productColumnsPattern + ` FROM products WHERE \(.*\) AND is_active = true ORDER BY`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.SearchProducts(context.Background(), "earbo", domain.ProductSearchTrigram, true, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_GetProductsByCategory_ActiveOnly(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(DISTINCT p.id\) FROM products p INNER JOIN product_categories pc ON p.id = pc.product_id WHERE pc.category_id = \? AND p.is_active = true$`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`WHERE pc.category_id = \? AND p.is_active = true ORDER BY p.created_at DESC LIMIT \? OFFSET \?`).
+		WithArgs(int64(4), 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := repo.GetProductsByCategory(context.Background(), 4, true, 0, 20)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_GetProductsByTags(t *testing.T) {
+	t.Run("matches whole tags so pro does not match professional", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		// The tag is bound as a one-element array for containment, never as a LIKE pattern
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE tags @> \$1$`).
+			WithArgs(pq.Array([]string{"pro"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE tags @> \$1 ORDER BY created_at DESC, id DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs(pq.Array([]string{"pro"}), 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tags"}).
+				AddRow(1, "Pro Shifter", "{pro,shifter}"))
+
+		products, total, err := repo.GetProductsByTags(context.Background(), []string{"pro"}, false, 0, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, products, 1)
+		assert.Equal(t, pq.StringArray{"pro", "shifter"}, products[0].Tags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("requires every tag", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE tags @> \$1$`).
+			WithArgs(pq.Array([]string{"pro", "gear"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE tags @> \$1`).
+			WithArgs(pq.Array([]string{"pro", "gear"}), 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.GetProductsByTags(context.Background(), []string{"pro", "gear"}, false, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("shoppers only see active products", func(t *testing.T) {
+		db, mock, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo := NewProductRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE tags @> \$1 AND is_active = true$`).
+			WithArgs(pq.Array([]string{"pro"})).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE tags @> \$1 AND is_active = true ORDER BY`).
+			WithArgs(pq.Array([]string{"pro"}), 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err := repo.GetProductsByTags(context.Background(), []string{"pro"}, true, 0, 20)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_ListProducts_TagFilter(t *testing.T) {
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE tags @> \$1$`).
+		WithArgs(pq.Array([]string{"pro"})).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT `+productColumnsPattern+` FROM products WHERE tags @> \$1 ORDER BY created_at DESC, id DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs(pq.Array([]string{"pro"}), 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := repo.ListProducts(context.Background(), &domain.ProductFilter{Tags: []string{"pro"}}, 0, 20)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository interface {
+	// Subscriptions
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	GetActiveSubscriptionsForEvent(ctx context.Context, eventType string) ([]*domain.WebhookSubscription, error)
+	DisableSubscription(ctx context.Context, id int64) error
+
+	// Delivery Attempts
+	RecordDeliveryAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error
+}
+
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Subscriptions
+
+// CreateSubscription stores a new webhook subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	now := time.Now()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	err := r.db.QueryRowxContext(ctx, query,
+		subscription.URL, subscription.EventTypes, subscription.Secret, subscription.IsActive,
+		subscription.CreatedAt, subscription.UpdatedAt,
+	).Scan(&subscription.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := `SELECT * FROM webhook_subscriptions ORDER BY created_at DESC, id DESC`
+
+	var subscriptions []*domain.WebhookSubscription
+	err := r.db.SelectContext(ctx, &subscriptions, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// GetActiveSubscriptionsForEvent retrieves active subscriptions for an event type or the '*' wildcard
+func (r *webhookRepository) GetActiveSubscriptionsForEvent(ctx context.Context, eventType string) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT * FROM webhook_subscriptions
+		WHERE is_active = true AND event_types && ARRAY[$1, '*']::text[]
+		ORDER BY id`
+
+	var subscriptions []*domain.WebhookSubscription
+	err := r.db.SelectContext(ctx, &subscriptions, query, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DisableSubscription stops deliveries to a subscription
+func (r *webhookRepository) DisableSubscription(ctx context.Context, id int64) error {
+	query := `UPDATE webhook_subscriptions SET is_active = false, updated_at = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to disable webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundf(ErrWebhookSubscriptionNotFound, "webhook subscription with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// Delivery Attempts
+
+// RecordDeliveryAttempt logs the outcome of a single delivery attempt
+func (r *webhookRepository) RecordDeliveryAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error {
+	query := `
+		INSERT INTO webhook_delivery_attempts (
+			subscription_id, event_id, event_type, attempt, status_code, success, error, created_at
+		) VALUES (
+			:subscription_id, :event_id, :event_type, :attempt, :status_code, :success, :error, :created_at
+		) RETURNING id`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&attempt.ID); err != nil {
+			return fmt.Errorf("failed to get delivery attempt ID: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -7,23 +7,35 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/handlers"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/lifecycle"
 )
 
-func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handlers.IProductHandler, cartHandler handlers.ICartHandler, inventoryHandler handlers.IInventoryHandler) *chi.Mux {
+func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handlers.IProductHandler, cartHandler handlers.ICartHandler, checkoutHandler handlers.ICheckoutHandler, inventoryHandler handlers.IInventoryHandler, webhookHandler handlers.IWebhookHandler, stockStreamHandler handlers.IStockStreamHandler, guestSession handlers.GuestSessionPolicy, auth handlers.AuthPolicy, idempotency handlers.IdempotencyPolicy, body httpx.BodyPolicy, readiness *lifecycle.Readiness) *chi.Mux {
 	router := chi.NewRouter()
 
+	// Catalog and stock changes and back-office routes need a signed-in editor or admin
+	requireStaff := []func(http.Handler) http.Handler{
+		handlers.Authenticate(auth),
+		handlers.RequireRole(handlers.RoleAdmin, handlers.RoleEditor),
+	}
+
+	// POSTs and PATCHes sent with an Idempotency-Key header are replayed instead of repeated
+	idempotent := handlers.Idempotency(idempotency)
+
 	// Global middleware
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.RequestID)
+	router.Use(httpx.RequestID)
 	router.Use(middleware.RealIP)
+	router.Use(httpx.AccessLog)
+	router.Use(httpx.Recover)
+	router.Use(httpx.JSONBody(body))
 
 	// Global CORS middleware
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"}, // Configure this properly for production
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", handlers.IdempotencyKeyHeader, httpx.RequestIDHeader},
+		ExposedHeaders:   []string{"Link", handlers.IdempotentReplayedHeader, httpx.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -35,6 +47,13 @@ func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handler
 		w.Write([]byte(`{"status":"ok","service":"product-service"}`))
 	})
 
+	// Liveness endpoint, answers for as long as the process is up
+	router.Get("/healthz", lifecycle.LivenessHandler)
+
+	// Readiness endpoint, fails once shutdown has started or while the database or its
+	// migrations are not ready
+	router.Get("/readyz", readiness.Handler())
+
 	// Product service routes
 	router.Route("/api/v1", func(r chi.Router) {
 		// Service health endpoint
@@ -46,48 +65,85 @@ func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handler
 
 		// Product routes
 		r.Route("/products", func(r chi.Router) {
-			r.Post("/", productHandler.CreateProduct)
-			r.Get("/", productHandler.ListProducts)
-			r.Get("/search", productHandler.SearchProducts)
-			r.Get("/tags", productHandler.GetProductsByTags)
+			// Listings, search and batch reads hide inactive products unless a staff token is sent
+			r.With(handlers.OptionalAuthenticate(auth)).Get("/", productHandler.ListProducts)
+			r.With(handlers.OptionalAuthenticate(auth)).Get("/search", productHandler.SearchProducts)
+			r.Get("/suggest", productHandler.SuggestProducts)
+			r.With(handlers.OptionalAuthenticate(auth)).Get("/tags", productHandler.GetProductsByTags)
+			r.Get("/sku-available", productHandler.IsSKUAvailable)
 			r.Get("/sku/{sku}", productHandler.GetProductBySKU)
+			// A read taking a body of IDs, so it is open to everyone like the GETs
+			r.With(handlers.OptionalAuthenticate(auth)).Post("/batch", productHandler.GetProductsByIDs)
 			r.Get("/{id}", productHandler.GetProduct)
-			r.Put("/{id}", productHandler.UpdateProduct)
-			r.Delete("/{id}", productHandler.DeleteProduct)
-			r.Patch("/{id}/quantity", productHandler.UpdateProductQuantity)
-
-			// Product variants
-			r.Post("/{id}/variants", productHandler.CreateProductVariant)
+			r.Get("/{id}/stock/stream", stockStreamHandler.StreamProductStock)
 			r.Get("/{id}/variants", productHandler.GetProductVariants)
-			r.Put("/variants/{id}", productHandler.UpdateProductVariant)
-			r.Delete("/variants/{id}", productHandler.DeleteProductVariant)
+			r.Get("/{id}/variants/options", productHandler.GetVariantAttributeOptions)
+			r.Get("/{id}/variants/resolve", productHandler.GetVariantByAttributes)
 			r.Get("/variants/{id}", productHandler.GetProductVariant)
-
-			// Product categories
-			r.Post("/{id}/categories", productHandler.AddProductToCategory)
-			r.Put("/{id}/categories", productHandler.UpdateProductCategories)
 			r.Get("/{id}/categories", productHandler.GetProductCategories)
-			r.Delete("/{id}/categories/{category_id}", productHandler.RemoveProductFromCategory)
+			r.Get("/{id}/images", productHandler.ListProductImages)
+
+			r.Group(func(r chi.Router) {
+				r.Use(requireStaff...)
+				r.Use(idempotent)
+
+				r.Post("/", productHandler.CreateProduct)
+				r.Post("/bulk/active", productHandler.BulkSetActive)
+				r.Post("/bulk/delete", productHandler.BulkDeleteProducts)
+				r.Put("/{id}", productHandler.UpdateProduct)
+				r.Delete("/{id}", productHandler.DeleteProduct)
+				r.Patch("/{id}/quantity", productHandler.UpdateProductQuantity)
+				r.Post("/{id}/clone", productHandler.CloneProduct)
+
+				// Product variants
+				r.Post("/{id}/variants", productHandler.CreateProductVariant)
+				r.Put("/variants/{id}", productHandler.UpdateProductVariant)
+				r.Delete("/variants/{id}", productHandler.DeleteProductVariant)
+
+				// Product categories
+				r.Post("/{id}/categories", productHandler.AddProductToCategory)
+				r.Put("/{id}/categories", productHandler.UpdateProductCategories)
+				r.Delete("/{id}/categories/{category_id}", productHandler.RemoveProductFromCategory)
+
+				// Product images
+				r.Post("/{id}/images", productHandler.AddProductImage)
+				r.Put("/{id}/images/order", productHandler.ReorderImages)
+				r.Put("/{id}/images/{image_id}/primary", productHandler.SetPrimaryImage)
+				r.Delete("/{id}/images/{image_id}", productHandler.DeleteProductImage)
+			})
 		})
 
 		// Category routes
 		r.Route("/categories", func(r chi.Router) {
-			r.Post("/", categoryHandler.CreateCategory)
 			r.Get("/", categoryHandler.ListCategories)
 			r.Get("/hierarchy", categoryHandler.GetCategoryHierarchy)
+			r.Get("/tree", categoryHandler.GetCategoryTree)
 			r.Get("/slug/{slug}", categoryHandler.GetCategoryBySlug)
 			r.Get("/{id}", categoryHandler.GetCategory)
-			r.Put("/{id}", categoryHandler.UpdateCategory)
-			r.Delete("/{id}", categoryHandler.DeleteCategory)
 			r.Get("/{id}/children", categoryHandler.GetCategoryChildren)
-			r.Get("/{id}/products", productHandler.GetProductsByCategory)
+			r.Get("/{id}/breadcrumb", categoryHandler.GetCategoryBreadcrumb)
+			r.With(handlers.OptionalAuthenticate(auth)).Get("/{id}/products", productHandler.GetProductsByCategory)
+
+			r.Group(func(r chi.Router) {
+				r.Use(requireStaff...)
+				r.Use(idempotent)
+
+				r.Post("/", categoryHandler.CreateCategory)
+				r.Put("/{id}", categoryHandler.UpdateCategory)
+				r.Delete("/{id}", categoryHandler.DeleteCategory)
+			})
 		})
 
 		// Cart routes
 		r.Route("/carts", func(r chi.Router) {
+			// Guests are identified by a signed session cookie issued on first cart request
+			r.Use(handlers.GuestSession(guestSession))
+			r.Use(idempotent)
+
 			r.Get("/session", cartHandler.GetCartBySession)
 			r.Get("/get-or-create", cartHandler.GetOrCreateCart)
-			r.Get("/analytics", cartHandler.GetCartAnalytics)
+			// Called after login to fold the session's guest cart into the user's cart
+			r.With(handlers.Authenticate(auth)).Post("/merge-guest", cartHandler.MergeGuestCart)
 			r.Get("/{id}", cartHandler.GetCart)
 			r.Put("/{id}", cartHandler.UpdateCart)
 			r.Delete("/{id}", cartHandler.DeleteCart)
@@ -99,11 +155,15 @@ func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handler
 			r.Get("/items/{id}", cartHandler.GetCartItem)
 			r.Put("/items/{id}", cartHandler.UpdateCartItem)
 			r.Delete("/items/{id}", cartHandler.DeleteCartItem)
+			r.Post("/items/{id}/increment", cartHandler.IncrementCartItem)
+			r.Post("/items/{id}/decrement", cartHandler.DecrementCartItem)
 
 			// Cart summary & calculations
 			r.Get("/{id}/summary", cartHandler.GetCartSummary)
+			r.Get("/{id}/full", cartHandler.GetFullCart)
 			r.Get("/{id}/total", cartHandler.GetCartTotal)
 			r.Get("/{id}/count", cartHandler.GetCartItemCount)
+			r.Get("/{id}/validate", cartHandler.ValidateCartForCheckout)
 
 			// Cart coupons
 			r.Post("/{id}/coupons", cartHandler.ApplyCouponToCart)
@@ -115,56 +175,111 @@ func NewRouter(categoryHandler handlers.ICategoryHandler, productHandler handler
 			r.Get("/{id}/shipping", cartHandler.GetCartShipping)
 			r.Put("/{id}/shipping", cartHandler.UpdateCartShipping)
 			r.Delete("/{id}/shipping", cartHandler.DeleteCartShipping)
+			r.Get("/{id}/shipping/options", cartHandler.GetShippingOptions)
+			r.Put("/{id}/shipping/address", cartHandler.SetCartAddress)
 
 			// Cart operations
 			r.Post("/{id}/merge", cartHandler.MergeCarts)
 			r.Delete("/{id}/clear", cartHandler.ClearCart)
+
+			// Checkout
+			r.With(handlers.OptionalAuthenticate(auth)).Post("/{id}/checkout", checkoutHandler.CreateOrderFromCart)
+
+			// Reporting and maintenance across all carts, and overrides on a single cart
+			r.Group(func(r chi.Router) {
+				r.Use(requireStaff...)
+
+				r.Get("/analytics", cartHandler.GetCartAnalytics)
+				r.Post("/recalculate", cartHandler.RecalculateActiveCarts)
+				r.Put("/{id}/expiry", cartHandler.SetCartExpiry)
+				r.Put("/{id}/tax-exempt", cartHandler.SetCartTaxExempt)
+				r.Post("/{id}/deduplicate", cartHandler.DeduplicateCartItems)
+			})
 		})
 
 		// Wishlist routes
 		r.Route("/wishlists", func(r chi.Router) {
-			r.Post("/", cartHandler.CreateWishlist)
-			r.Get("/", cartHandler.GetWishlists)
-			r.Get("/{id}", cartHandler.GetWishlist)
-			r.Put("/{id}", cartHandler.UpdateWishlist)
-			r.Delete("/{id}", cartHandler.DeleteWishlist)
-
-			// Wishlist items
-			r.Post("/{id}/items", cartHandler.AddItemToWishlist)
-			r.Get("/{id}/items", cartHandler.GetWishlistItems)
-			r.Get("/items/{id}", cartHandler.GetWishlistItem)
-			r.Put("/items/{id}", cartHandler.UpdateWishlistItem)
-			r.Delete("/items/{id}", cartHandler.DeleteWishlistItem)
-			r.Post("/items/{id}/move-to-cart", cartHandler.MoveItemToCart)
+			// Anyone with a share link can view a public wishlist
+			r.Get("/shared/{token}", cartHandler.GetSharedWishlist)
+
+			// Everything else is for signed-in users
+			r.Group(func(r chi.Router) {
+				r.Use(handlers.Authenticate(auth))
+				r.Use(idempotent)
+
+				r.Post("/", cartHandler.CreateWishlist)
+				r.Get("/", cartHandler.GetWishlists)
+				r.Get("/{id}", cartHandler.GetWishlist)
+				r.Put("/{id}", cartHandler.UpdateWishlist)
+				r.Delete("/{id}", cartHandler.DeleteWishlist)
+
+				// Wishlist items
+				r.Post("/{id}/items", cartHandler.AddItemToWishlist)
+				r.Get("/{id}/items", cartHandler.GetWishlistItems)
+				r.Get("/items/{id}", cartHandler.GetWishlistItem)
+				r.Put("/items/{id}", cartHandler.UpdateWishlistItem)
+				r.Delete("/items/{id}", cartHandler.DeleteWishlistItem)
+				r.Post("/items/{id}/move-to-cart", cartHandler.MoveItemToCart)
+			})
 		})
 
 		// Inventory routes
 		r.Route("/inventory", func(r chi.Router) {
-			r.Post("/", inventoryHandler.CreateInventory)
 			r.Get("/summary", inventoryHandler.GetInventorySummary)
 			r.Get("/", inventoryHandler.ListInventory)
 			r.Get("/product", inventoryHandler.GetInventoryByProduct)
 			r.Get("/{id}", inventoryHandler.GetInventoryByID)
-			r.Put("/{id}", inventoryHandler.UpdateInventory)
-			r.Delete("/{id}", inventoryHandler.DeleteInventory)
-
-			// Stock movements
-			r.Post("/movements", inventoryHandler.RecordStockMovement)
 			r.Get("/movements", inventoryHandler.GetStockMovements)
 			r.Get("/movements/{id}", inventoryHandler.GetStockMovementByID)
+			r.Get("/alerts", inventoryHandler.GetInventoryAlerts)
 
-			// Stock reservations
-			r.Post("/reservations", inventoryHandler.ReserveStock)
-			r.Delete("/reservations", inventoryHandler.ReleaseStock)
-			r.Get("/reservations", inventoryHandler.GetStockReservations)
+			r.Group(func(r chi.Router) {
+				r.Use(requireStaff...)
+				r.Use(idempotent)
 
-			// Inventory alerts
-			r.Get("/alerts", inventoryHandler.GetInventoryAlerts)
-			r.Put("/alerts/{id}/resolve", inventoryHandler.ResolveInventoryAlert)
-			r.Post("/alerts/check", inventoryHandler.CheckLowStockAlerts)
+				r.Get("/restock-needed", inventoryHandler.ListProductsNeedingRestock)
+
+				r.Post("/", inventoryHandler.CreateInventory)
+				r.Put("/{id}", inventoryHandler.UpdateInventory)
+				r.Delete("/{id}", inventoryHandler.DeleteInventory)
+
+				// Stock movements
+				r.Post("/movements", inventoryHandler.RecordStockMovement)
+				r.Post("/set-quantity", inventoryHandler.SetInventoryQuantity)
+				r.Post("/restock", inventoryHandler.Restock)
+				r.Post("/adjust", inventoryHandler.AdjustStock)
+
+				// Inventory alerts
+				r.Put("/alerts/{id}/resolve", inventoryHandler.ResolveInventoryAlert)
+				r.Post("/alerts/check", inventoryHandler.CheckLowStockAlerts)
+
+				// Bulk operations
+				r.Post("/bulk-update", inventoryHandler.BulkUpdateStock)
+			})
+
+			// Stock reservations, held for shoppers by checkout and managed by staff
+			r.Group(func(r chi.Router) {
+				r.Use(requireStaff...)
+
+				r.With(idempotent).Post("/reservations", inventoryHandler.ReserveStock)
+				r.Delete("/reservations", inventoryHandler.ReleaseStock)
+				r.Get("/reservations", inventoryHandler.GetStockReservations)
+				r.Put("/reservations/{id}/extend", inventoryHandler.ExtendReservation)
+			})
+
+			// Stock notifications, for the signed-in user or a guest's email; only signed-in
+			// users can cancel theirs
+			r.With(handlers.OptionalAuthenticate(auth)).Post("/notifications", inventoryHandler.SubscribeStockNotification)
+			r.With(handlers.Authenticate(auth)).Delete("/notifications/{id}", inventoryHandler.UnsubscribeStockNotification)
+		})
+
+		// Webhook subscription routes; subscriptions hold signing secrets and target URLs
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(requireStaff...)
 
-			// Bulk operations
-			r.Post("/bulk-update", inventoryHandler.BulkUpdateStock)
+			r.Post("/", webhookHandler.RegisterSubscription)
+			r.Get("/", webhookHandler.ListSubscriptions)
+			r.Put("/{id}/disable", webhookHandler.DisableSubscription)
 		})
 	})
 
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// CartPricingPolicy controls how cart totals are derived from item, discount and shipping amounts
+type CartPricingPolicy struct {
+	// CatalogCurrency is the ISO 4217 currency product and variant prices are in. Items
+	// can only be added to carts in this currency. Empty skips the check.
+	CatalogCurrency string
+	// TaxRate is applied to the taxable subtotal of non-exempt carts whose tax rates are
+	// not resolved from an address, e.g. 0.1 for 10%
+	TaxRate float64
+	// DiscountBeforeTax reduces the taxable amount by the taxable share of coupon discounts.
+	// When false, tax is charged on the undiscounted taxable subtotal.
+	DiscountBeforeTax bool
+	// RejectNonPositivePrices stops items priced at zero or less from being added to a
+	// cart, since that usually means a misconfigured product. Products flagged IsFree
+	// may be priced at zero, but never below.
+	RejectNonPositivePrices bool
+	// FreeShippingThresholds waives shipping for carts whose item subtotal reaches the
+	// threshold for their currency. Currencies without a threshold always pay shipping.
+	FreeShippingThresholds map[string]float64
+	// DimensionalDivisor is the cubic centimetres counted as one unit of weight when
+	// bulky products are billed on their size; zero uses domain.DefaultDimensionalDivisor
+	DimensionalDivisor float64
+	// MinimumOrderAmounts is the item subtotal a cart must reach, per currency, before it
+	// can check out. Currencies without an amount have no minimum.
+	MinimumOrderAmounts map[string]float64
+}
+
+// Apply fills in the tax and total of a summary. All arithmetic is done in integer
+// minor units of the cart's currency with half-up rounding, so totals are exact.
+func (p CartPricingPolicy) Apply(summary *domain.CartSummary) {
+	currency := summary.Currency
+
+	subtotal := domain.ToMinorUnits(summary.Subtotal, currency)
+	taxable := domain.ToMinorUnits(summary.TaxableSubtotal, currency)
+	shipping := domain.ToMinorUnits(summary.ShippingAmount, currency)
+	discount := domain.ToMinorUnits(summary.DiscountAmount, currency)
+	if discount > subtotal {
+		discount = subtotal
+	}
+
+	summary.FreeShipping = p.reachesFreeShipping(summary)
+	if summary.FreeShipping {
+		shipping = 0
+	}
+
+	rates := summary.TaxRates
+	if rates == nil {
+		rates = []domain.TaxRate{{Name: "Tax", Rate: p.TaxRate}}
+	}
+	if summary.TaxExempt {
+		rates = nil
+	}
+
+	taxBase := taxable
+	if p.DiscountBeforeTax {
+		taxBase -= domain.ProRate(discount, taxable, subtotal)
+	}
+
+	// Each rate is rounded on its own so the lines add up to the tax charged
+	var tax int64
+	var taxRate float64
+	lines := make([]domain.TaxLine, 0, len(rates))
+	for _, rate := range rates {
+		amount := domain.ApplyRate(taxBase, rate.Rate)
+		tax += amount
+		taxRate += rate.Rate
+		lines = append(lines, domain.TaxLine{Name: rate.Name, Rate: rate.Rate, Amount: domain.FromMinorUnits(amount, currency)})
+	}
+
+	summary.Subtotal = domain.FromMinorUnits(subtotal, currency)
+	summary.TaxableSubtotal = domain.FromMinorUnits(taxable, currency)
+	summary.ShippingAmount = domain.FromMinorUnits(shipping, currency)
+	summary.DiscountAmount = domain.FromMinorUnits(discount, currency)
+	summary.TaxRate = taxRate
+	summary.TaxLines = lines
+	summary.TaxAmount = domain.FromMinorUnits(tax, currency)
+	summary.TotalAmount = domain.FromMinorUnits(subtotal-discount+tax+shipping, currency)
+}
+
+// reachesFreeShipping reports whether a summary's item subtotal reaches the free shipping
+// threshold for its currency
+func (p CartPricingPolicy) reachesFreeShipping(summary *domain.CartSummary) bool {
+	currency := summary.Currency
+	threshold, ok := p.FreeShippingThresholds[currency]
+	return ok && domain.ToMinorUnits(summary.Subtotal, currency) >= domain.ToMinorUnits(threshold, currency)
+}
+
+// MinimumOrderShortfall returns the minimum order amount for the summary's currency and
+// how much more its item subtotal needs to reach it. Both are zero when there is no
+// minimum, and the shortfall is zero once the minimum is met.
+func (p CartPricingPolicy) MinimumOrderShortfall(summary *domain.CartSummary) (minimum, shortfall float64) {
+	currency := summary.Currency
+	amount, ok := p.MinimumOrderAmounts[currency]
+	if !ok {
+		return 0, 0
+	}
+
+	missing := domain.ToMinorUnits(amount, currency) - domain.ToMinorUnits(summary.Subtotal, currency)
+	if missing <= 0 {
+		return amount, 0
+	}
+	return amount, domain.FromMinorUnits(missing, currency)
+}
+
+// checkCouponUsable returns why a coupon cannot discount a cart with the given item
+// subtotal at now, or nil if it can
+func checkCouponUsable(coupon *domain.Coupon, subtotal float64, now time.Time) error {
+	if !coupon.IsActive || now.Before(coupon.StartsAt) {
+		return ErrCouponInactive
+	}
+	if coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt) {
+		return ErrCouponExpired
+	}
+	if subtotal < coupon.MinOrderAmount {
+		return ErrCouponMinNotMet
+	}
+	return nil
+}
+
+// couponPrice is what one of a cart's coupons takes off it, or why it takes nothing
+type couponPrice struct {
+	Code     string
+	Discount float64
+	Err      error
+}
+
+// applyCoupons prices a cart's coupons against its current subtotal and shipping, in the
+// order they were applied, and sets its discount to their sum. Coupons that lapsed after
+// being applied, or whose minimum order the cart no longer reaches, take nothing off.
+// Free shipping coupons are priced after the free shipping threshold and take their
+// discount off the cart's shipping rather than its items, so it is never taxed.
+func (p CartPricingPolicy) applyCoupons(summary *domain.CartSummary, now time.Time) []couponPrice {
+	currency := summary.Currency
+	summary.DiscountAmount = 0
+	if p.reachesFreeShipping(summary) {
+		summary.ShippingAmount = 0
+	}
+
+	var total int64
+	prices := make([]couponPrice, len(summary.Coupons))
+	for i := range summary.Coupons {
+		coupon := &summary.Coupons[i]
+		prices[i] = couponPrice{Code: coupon.Code}
+		if err := checkCouponUsable(coupon, summary.Subtotal, now); err != nil {
+			prices[i].Err = err
+			continue
+		}
+
+		prices[i].Discount = couponDiscount(coupon, summary)
+		if coupon.Type == "free_shipping" {
+			shipping := domain.ToMinorUnits(summary.ShippingAmount, currency) - domain.ToMinorUnits(prices[i].Discount, currency)
+			summary.ShippingAmount = domain.FromMinorUnits(shipping, currency)
+			continue
+		}
+		total += domain.ToMinorUnits(prices[i].Discount, currency)
+		summary.DiscountAmount = domain.FromMinorUnits(total, currency)
+	}
+	return prices
+}
+
+// couponDiscount returns the discount a coupon gives a cart. Percentage coupons take
+// their value as a percent of the item subtotal and fixed amount coupons their value,
+// capped by whatever subtotal earlier coupons have left so the cart total never goes
+// negative. Free shipping coupons take the cart's shipping. Either is capped by the
+// coupon's maximum discount, when set.
+func couponDiscount(coupon *domain.Coupon, summary *domain.CartSummary) float64 {
+	currency := summary.Currency
+	subtotal := domain.ToMinorUnits(summary.Subtotal, currency)
+
+	var discount int64
+	switch coupon.Type {
+	case "percentage":
+		discount = domain.ApplyRate(subtotal, coupon.Value/100)
+	case "fixed_amount":
+		discount = domain.ToMinorUnits(coupon.Value, currency)
+	case "free_shipping":
+		discount = domain.ToMinorUnits(summary.ShippingAmount, currency)
+	}
+
+	if coupon.MaxDiscountAmount > 0 {
+		discount = min(discount, domain.ToMinorUnits(coupon.MaxDiscountAmount, currency))
+	}
+	if coupon.Type != "free_shipping" {
+		remaining := max(subtotal-domain.ToMinorUnits(summary.DiscountAmount, currency), 0)
+		discount = min(discount, remaining)
+	}
+	discount = max(discount, 0)
+
+	return domain.FromMinorUnits(discount, currency)
+}
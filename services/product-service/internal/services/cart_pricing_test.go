@@ -0,0 +1,269 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartPricingPolicy_FreeShippingThreshold(t *testing.T) {
+	policy := CartPricingPolicy{FreeShippingThresholds: map[string]float64{"USD": 50}}
+
+	tests := []struct {
+		name         string
+		currency     string
+		subtotal     float64
+		freeShipping bool
+		shipping     float64
+		total        float64
+	}{
+		{name: "just below", currency: "USD", subtotal: 49.99, shipping: 7.5, total: 57.49},
+		{name: "at threshold", currency: "USD", subtotal: 50, freeShipping: true, total: 50},
+		{name: "just above", currency: "USD", subtotal: 50.01, freeShipping: true, total: 50.01},
+		{name: "currency without threshold", currency: "EUR", subtotal: 500, shipping: 7.5, total: 507.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &domain.CartSummary{Currency: tt.currency, Subtotal: tt.subtotal, ShippingAmount: 7.5}
+
+			policy.Apply(summary)
+
+			assert.Equal(t, tt.freeShipping, summary.FreeShipping)
+			assert.Equal(t, tt.shipping, summary.ShippingAmount)
+			assert.Equal(t, tt.total, summary.TotalAmount)
+		})
+	}
+}
+
+func TestCartPricingPolicy_TaxLines(t *testing.T) {
+	policy := CartPricingPolicy{TaxRate: 0.1}
+
+	t.Run("resolved rates are charged and rounded per line", func(t *testing.T) {
+		summary := &domain.CartSummary{
+			Currency:        "USD",
+			Subtotal:        120,
+			TaxableSubtotal: 99.99,
+			TaxRates: []domain.TaxRate{
+				{Name: "CA state tax", Rate: 0.0725},
+				{Name: "SF city tax", Rate: 0.015},
+			},
+		}
+
+		policy.Apply(summary)
+
+		assert.Equal(t, []domain.TaxLine{
+			{Name: "CA state tax", Rate: 0.0725, Amount: 7.25},
+			{Name: "SF city tax", Rate: 0.015, Amount: 1.5},
+		}, summary.TaxLines)
+		assert.Equal(t, 8.75, summary.TaxAmount)
+		assert.InDelta(t, 0.0875, summary.TaxRate, 1e-9)
+		assert.Equal(t, 128.75, summary.TotalAmount)
+	})
+
+	t.Run("flat rate without resolved rates", func(t *testing.T) {
+		summary := &domain.CartSummary{Currency: "USD", Subtotal: 50, TaxableSubtotal: 50}
+
+		policy.Apply(summary)
+
+		assert.Equal(t, []domain.TaxLine{{Name: "Tax", Rate: 0.1, Amount: 5}}, summary.TaxLines)
+		assert.Equal(t, 5.0, summary.TaxAmount)
+	})
+
+	t.Run("exempt carts have no tax lines", func(t *testing.T) {
+		summary := &domain.CartSummary{
+			Currency:        "USD",
+			Subtotal:        50,
+			TaxableSubtotal: 50,
+			TaxExempt:       true,
+			TaxRates:        []domain.TaxRate{{Name: "CA state tax", Rate: 0.0725}},
+		}
+
+		policy.Apply(summary)
+
+		assert.Empty(t, summary.TaxLines)
+		assert.Zero(t, summary.TaxAmount)
+		assert.Equal(t, 50.0, summary.TotalAmount)
+	})
+}
+
+func TestCartPricingPolicy_MinimumOrderShortfall(t *testing.T) {
+	policy := CartPricingPolicy{MinimumOrderAmounts: map[string]float64{"USD": 25, "JPY": 3000}}
+
+	tests := []struct {
+		name      string
+		currency  string
+		subtotal  float64
+		minimum   float64
+		shortfall float64
+	}{
+		{name: "just below", currency: "USD", subtotal: 24.99, minimum: 25, shortfall: 0.01},
+		{name: "at minimum", currency: "USD", subtotal: 25, minimum: 25},
+		{name: "just above", currency: "USD", subtotal: 25.01, minimum: 25},
+		{name: "zero-decimal currency", currency: "JPY", subtotal: 2999, minimum: 3000, shortfall: 1},
+		{name: "currency without minimum", currency: "EUR", subtotal: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minimum, shortfall := policy.MinimumOrderShortfall(&domain.CartSummary{Currency: tt.currency, Subtotal: tt.subtotal})
+
+			assert.Equal(t, tt.minimum, minimum)
+			assert.Equal(t, tt.shortfall, shortfall)
+		})
+	}
+}
+
+func TestCouponDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   domain.Coupon
+		summary  domain.CartSummary
+		discount float64
+	}{
+		{name: "percentage", coupon: domain.Coupon{Type: "percentage", Value: 10}, summary: domain.CartSummary{Subtotal: 45.55}, discount: 4.56},
+		{name: "percentage capped by max discount", coupon: domain.Coupon{Type: "percentage", Value: 50, MaxDiscountAmount: 20}, summary: domain.CartSummary{Subtotal: 100}, discount: 20},
+		{name: "percentage over 100 capped at subtotal", coupon: domain.Coupon{Type: "percentage", Value: 150}, summary: domain.CartSummary{Subtotal: 30}, discount: 30},
+		{name: "percentage capped by earlier discounts", coupon: domain.Coupon{Type: "percentage", Value: 100}, summary: domain.CartSummary{Subtotal: 30, DiscountAmount: 25}, discount: 5},
+		{name: "nothing left after earlier discounts", coupon: domain.Coupon{Type: "percentage", Value: 20}, summary: domain.CartSummary{Subtotal: 30, DiscountAmount: 40}, discount: 0},
+		{name: "fixed amount", coupon: domain.Coupon{Type: "fixed_amount", Value: 10}, summary: domain.CartSummary{Subtotal: 30}, discount: 10},
+		{name: "fixed amount capped at subtotal", coupon: domain.Coupon{Type: "fixed_amount", Value: 50}, summary: domain.CartSummary{Subtotal: 30}, discount: 30},
+		{name: "free shipping", coupon: domain.Coupon{Type: "free_shipping"}, summary: domain.CartSummary{Subtotal: 30, ShippingAmount: 7.5}, discount: 7.5},
+		{name: "negative value", coupon: domain.Coupon{Type: "percentage", Value: -10}, summary: domain.CartSummary{Subtotal: 30}, discount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.summary.Currency = "USD"
+			assert.Equal(t, tt.discount, couponDiscount(&tt.coupon, &tt.summary))
+		})
+	}
+}
+
+func TestApplyCoupons(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Minute)
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		subtotal float64
+		coupons  []domain.Coupon
+		discount float64
+		prices   []couponPrice
+	}{
+		{
+			// SAVE10 was applied to a 200 cart; it takes 10% of what is left in the cart now
+			name:     "percentage follows the current subtotal",
+			subtotal: 80,
+			coupons:  []domain.Coupon{{Code: "SAVE10", Type: "percentage", Value: 10, IsActive: true}},
+			discount: 8,
+			prices:   []couponPrice{{Code: "SAVE10", Discount: 8}},
+		},
+		{
+			name:     "minimum no longer met",
+			subtotal: 80,
+			coupons:  []domain.Coupon{{Code: "BIGSPEND", Type: "fixed_amount", Value: 20, MinOrderAmount: 100, IsActive: true}},
+			discount: 0,
+			prices:   []couponPrice{{Code: "BIGSPEND", Err: ErrCouponMinNotMet}},
+		},
+		{
+			name:     "expired and not yet started coupons",
+			subtotal: 80,
+			coupons: []domain.Coupon{
+				{Code: "ENDED", Type: "fixed_amount", Value: 5, IsActive: true, ExpiresAt: &ended},
+				{Code: "SOON", Type: "fixed_amount", Value: 5, IsActive: true, StartsAt: later},
+				{Code: "SAVE5", Type: "fixed_amount", Value: 5, IsActive: true},
+			},
+			discount: 5,
+			prices: []couponPrice{
+				{Code: "ENDED", Err: ErrCouponExpired},
+				{Code: "SOON", Err: ErrCouponInactive},
+				{Code: "SAVE5", Discount: 5},
+			},
+		},
+		{
+			name:     "later coupons are capped by earlier ones",
+			subtotal: 30,
+			coupons: []domain.Coupon{
+				{Code: "TWENTY", Type: "fixed_amount", Value: 20, IsActive: true},
+				{Code: "HALF", Type: "percentage", Value: 50, IsActive: true},
+			},
+			discount: 30,
+			prices:   []couponPrice{{Code: "TWENTY", Discount: 20}, {Code: "HALF", Discount: 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A stale discount from the repository is replaced, not added to
+			summary := domain.CartSummary{Currency: "USD", Subtotal: tt.subtotal, DiscountAmount: 99, Coupons: tt.coupons}
+
+			prices := CartPricingPolicy{}.applyCoupons(&summary, now)
+
+			assert.Equal(t, tt.discount, summary.DiscountAmount)
+			assert.Equal(t, tt.prices, prices)
+		})
+	}
+}
+
+func TestApplyCoupons_FreeShipping(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	freeShipping := domain.Coupon{Code: "SHIPFREE", Type: "free_shipping", IsActive: true}
+
+	tests := []struct {
+		name     string
+		policy   CartPricingPolicy
+		subtotal float64
+		shipping float64
+		discount float64
+		tax      float64
+		total    float64
+		prices   []couponPrice
+	}{
+		{
+			name:     "below the threshold waives the shipping",
+			policy:   CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true, FreeShippingThresholds: map[string]float64{"USD": 50}},
+			subtotal: 40,
+			shipping: 0,
+			discount: 0,
+			tax:      4,
+			total:    44,
+			prices:   []couponPrice{{Code: "SHIPFREE", Discount: 10}},
+		},
+		{
+			// The threshold already waives shipping, so the coupon has nothing left to take
+			name:     "above the threshold takes nothing off the items",
+			policy:   CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true, FreeShippingThresholds: map[string]float64{"USD": 50}},
+			subtotal: 100,
+			shipping: 0,
+			discount: 0,
+			tax:      10,
+			total:    110,
+			prices:   []couponPrice{{Code: "SHIPFREE", Discount: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := domain.CartSummary{
+				Currency:        "USD",
+				Subtotal:        tt.subtotal,
+				TaxableSubtotal: tt.subtotal,
+				ShippingAmount:  10,
+				Coupons:         []domain.Coupon{freeShipping},
+			}
+
+			prices := tt.policy.applyCoupons(&summary, now)
+			tt.policy.Apply(&summary)
+
+			assert.Equal(t, tt.prices, prices)
+			assert.Equal(t, tt.shipping, summary.ShippingAmount)
+			assert.Equal(t, tt.discount, summary.DiscountAmount)
+			assert.Equal(t, tt.tax, summary.TaxAmount)
+			assert.Equal(t, tt.total, summary.TotalAmount)
+		})
+	}
+}
@@ -2,14 +2,16 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 )
 
 type CartService interface {
@@ -20,6 +22,8 @@ type CartService interface {
 	GetCartBySessionID(ctx context.Context, sessionID string) (*domain.Cart, error)
 	UpdateCart(ctx context.Context, id int64, req *dto.UpdateCartRequest) (*domain.Cart, error)
 	DeleteCart(ctx context.Context, id int64) error
+	SetCartExpiry(ctx context.Context, cartID int64, expiresAt *time.Time) (*domain.Cart, error)
+	SetCartTaxExempt(ctx context.Context, cartID int64, taxExempt bool) (*domain.Cart, error)
 	GetOrCreateCart(ctx context.Context, userID *int64, sessionID string, currency string) (*domain.Cart, error)
 
 	// Cart Items
@@ -27,33 +31,43 @@ type CartService interface {
 	GetCartItemByID(ctx context.Context, id int64) (*domain.CartItem, error)
 	UpdateCartItem(ctx context.Context, id int64, req *dto.UpdateCartItemRequest) (*domain.CartItem, error)
 	DeleteCartItem(ctx context.Context, id int64) error
-	GetCartItems(ctx context.Context, cartID int64) ([]*domain.CartItem, error)
+	IncrementCartItem(ctx context.Context, id int64, req *dto.AdjustCartItemQuantityRequest) (*domain.CartItem, error)
+	DecrementCartItem(ctx context.Context, id int64, req *dto.AdjustCartItemQuantityRequest) (*domain.CartItem, error)
+	GetCartItems(ctx context.Context, cartID int64, page, limit int) (*dto.ListCartItemsResponse, error)
 	ClearCartItems(ctx context.Context, cartID int64) error
 
 	// Cart Summary & Calculations
 	GetCartSummary(ctx context.Context, cartID int64) (*dto.CartSummaryResponse, error)
+	GetFullCart(ctx context.Context, cartID int64) (*dto.FullCartResponse, error)
 	CalculateCartTotal(ctx context.Context, cartID int64) (float64, error)
 	GetCartItemCount(ctx context.Context, cartID int64) (int, error)
 
 	// Cart Coupons
 	ApplyCouponToCart(ctx context.Context, cartID int64, req *dto.ApplyCouponRequest) (*domain.CartCoupon, error)
 	RemoveCouponFromCart(ctx context.Context, cartID int64, req *dto.RemoveCouponRequest) error
-	GetCartCoupons(ctx context.Context, cartID int64) ([]*domain.CartCoupon, error)
+	GetCartCoupons(ctx context.Context, cartID int64, page, limit int) (*dto.ListCartCouponsResponse, error)
 
 	// Cart Shipping
 	SetCartShipping(ctx context.Context, cartID int64, req *dto.SetShippingRequest) (*domain.CartShipping, error)
 	UpdateCartShipping(ctx context.Context, cartID int64, req *dto.UpdateShippingRequest) (*domain.CartShipping, error)
 	GetCartShipping(ctx context.Context, cartID int64) (*domain.CartShipping, error)
+	GetShippingOptions(ctx context.Context, cartID int64, destination domain.ShippingDestination) ([]domain.ShippingRate, error)
+	SetCartAddress(ctx context.Context, cartID int64, address domain.ShippingDestination) (*dto.CartSummaryResponse, error)
 	DeleteCartShipping(ctx context.Context, cartID int64) error
 
 	// Cart Operations
 	MergeCarts(ctx context.Context, sourceCartID, targetCartID int64) error
+	MergeGuestCartOnLogin(ctx context.Context, sessionID string, userID int64) (*domain.Cart, error)
 	ClearCart(ctx context.Context, cartID int64) error
+	DeduplicateCartItems(ctx context.Context, cartID int64) (*dto.DeduplicateCartResponse, error)
+	ValidateCartForCheckout(ctx context.Context, cartID int64) (*dto.CartValidationResponse, error)
+	RecalculateAllActiveCarts(ctx context.Context) (*dto.RecalculateCartsResponse, error)
 	GetCartAnalytics(ctx context.Context) (*dto.CartAnalyticsResponse, error)
 
 	// Wishlist Management
 	CreateWishlist(ctx context.Context, userID int64, req *dto.CreateWishlistRequest) (*domain.Wishlist, error)
 	GetWishlistByID(ctx context.Context, id int64) (*domain.Wishlist, error)
+	GetSharedWishlist(ctx context.Context, token string, page, limit int) (*dto.SharedWishlistResponse, error)
 	GetWishlistsByUserID(ctx context.Context, userID int64, page, limit int) (*dto.ListWishlistsResponse, error)
 	UpdateWishlist(ctx context.Context, id int64, req *dto.UpdateWishlistRequest) (*domain.Wishlist, error)
 	DeleteWishlist(ctx context.Context, id int64) error
@@ -67,15 +81,132 @@ type CartService interface {
 	MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64) error
 }
 
+// InsufficientStockError is returned when a cart line asks for more units than
+// the matching inventory row has available
+type InsufficientStockError struct {
+	ProductID        int64
+	ProductVariantID *int64
+	Requested        int
+	Available        int
+	// MaxAddable is how many more units the cart can take on top of what it already holds
+	MaxAddable int
+}
+
+func (e *InsufficientStockError) Error() string {
+	if e.ProductVariantID != nil {
+		return fmt.Sprintf("insufficient stock for product %d variant %d: requested %d, available %d",
+			e.ProductID, *e.ProductVariantID, e.Requested, e.Available)
+	}
+	return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d",
+		e.ProductID, e.Requested, e.Available)
+}
+
+// ProductUnavailableError is returned when adding a product or variant that is
+// inactive and can no longer be purchased
+type ProductUnavailableError struct {
+	ProductID        int64
+	ProductVariantID *int64
+}
+
+func (e *ProductUnavailableError) Error() string {
+	if e.ProductVariantID != nil {
+		return fmt.Sprintf("product %d variant %d is not available", e.ProductID, *e.ProductVariantID)
+	}
+	return fmt.Sprintf("product %d is not available", e.ProductID)
+}
+
+// ErrInvalidPrice is returned when an item added to a cart resolves to a zero or
+// negative price and its product is not flagged as free
+var ErrInvalidPrice = errors.New("product has no valid price")
+
+// ErrCurrencyMismatch is returned when cart items would be priced in a currency other than
+// the cart's: adding catalog items to a cart in another currency, merging carts in
+// different currencies, or changing the currency of a cart that holds items
+var ErrCurrencyMismatch = errors.New("currency does not match the cart")
+
+// ErrCouponRequiresAccount is returned when a guest cart applies a coupon with a
+// per-user limit, which can only be enforced for signed-in customers
+var ErrCouponRequiresAccount = errors.New("coupon can only be used by signed-in customers")
+
+// ErrCouponInactive is returned when a coupon is disabled or its start date has not come
+var ErrCouponInactive = errors.New("coupon is not active")
+
+// ErrCouponExpired is returned when a coupon's expiry date has passed
+var ErrCouponExpired = errors.New("coupon has expired")
+
+// ErrCouponMinNotMet is returned when a cart's subtotal is below a coupon's minimum order amount
+var ErrCouponMinNotMet = errors.New("cart subtotal is below the coupon minimum")
+
+// ErrCartExpiryTooFar is returned when a cart expiry is set further ahead than
+// CartExpiryPolicy.MaxExpiry allows
+var ErrCartExpiryTooFar = errors.New("cart expiry is too far in the future")
+
+// ErrShippingMethodUnavailable is returned when a cart asks for a shipping method that
+// does not ship to its address or cannot carry its weight
+var ErrShippingMethodUnavailable = errors.New("shipping method is not available for this cart")
+
+// CartExpiryPolicy bounds manual overrides of a cart's expiry
+type CartExpiryPolicy struct {
+	// MaxExpiry is how far from now an expiry may be set; zero means no limit
+	MaxExpiry time.Duration
+}
+
+// CouponRedemptionPolicy controls when coupon redemptions count against a coupon's limits
+type CouponRedemptionPolicy struct {
+	// RedeemAtCheckout defers recording redemptions to checkout, where they are recorded
+	// in the same transaction that places the order. When false,
+	// a redemption is recorded as soon as the coupon is applied to a cart and released
+	// if it is removed again.
+	RedeemAtCheckout bool
+}
+
+// Reasons reported by ValidateCartForCheckout for cart items that cannot be purchased
+const (
+	CartIssueProductNotFound   = "product_not_found"
+	CartIssueProductInactive   = "product_inactive"
+	CartIssueVariantInactive   = "variant_inactive"
+	CartIssueInsufficientStock = "insufficient_stock"
+)
+
+// Reasons reported by ValidateCartForCheckout for applied coupons that no longer apply
+const (
+	CartIssueCouponInactive  = "coupon_inactive"
+	CartIssueCouponExpired   = "coupon_expired"
+	CartIssueCouponMinNotMet = "coupon_min_not_met"
+)
+
+// cartRecalculationBatchSize bounds how many carts are repriced per statement
+const cartRecalculationBatchSize = 200
+
+// cartItemMergeAttempts bounds how often adding to a cart item is retried after losing a
+// race with a concurrent write to the same item
+const cartItemMergeAttempts = 5
+
 type cartService struct {
-	cartRepo    repository.CartRepository
-	productRepo repository.ProductRepository
+	cartRepo      repository.CartRepository
+	productRepo   repository.ProductRepository
+	inventoryRepo repository.InventoryRepository
+	couponRepo    repository.CouponRepository
+	pricing       CartPricingPolicy
+	redemption    CouponRedemptionPolicy
+	shippingRates ShippingRateProvider
+	taxes         TaxService
+	expiry        CartExpiryPolicy
+	batchSize     int
 }
 
-func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository) CartService {
+func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository, inventoryRepo repository.InventoryRepository, couponRepo repository.CouponRepository, pricing CartPricingPolicy, redemption CouponRedemptionPolicy, shippingRates ShippingRateProvider, taxes TaxService, expiry CartExpiryPolicy) CartService {
 	return &cartService{
-		cartRepo:    cartRepo,
-		productRepo: productRepo,
+		cartRepo:      cartRepo,
+		productRepo:   productRepo,
+		inventoryRepo: inventoryRepo,
+		couponRepo:    couponRepo,
+		pricing:       pricing,
+		redemption:    redemption,
+		shippingRates: shippingRates,
+		taxes:         taxes,
+		expiry:        expiry,
+		batchSize:     cartRecalculationBatchSize,
 	}
 }
 
@@ -89,7 +220,7 @@ func (s *cartService) CreateCart(ctx context.Context, req *dto.CreateCartRequest
 	cart := &domain.Cart{
 		UserID:    req.UserID,
 		SessionID: req.SessionID,
-		Currency:  req.Currency,
+		Currency:  strings.ToUpper(req.Currency),
 		ExpiresAt: &expiresAt,
 	}
 
@@ -143,7 +274,9 @@ func (s *cartService) UpdateCart(ctx context.Context, id int64, req *dto.UpdateC
 	updateCart := *existingCart
 
 	if req.Currency != nil {
-		updateCart.Currency = *req.Currency
+		if err := s.changeCartCurrency(ctx, &updateCart, *req.Currency); err != nil {
+			return nil, err
+		}
 	}
 
 	updateCart.UpdatedAt = time.Now()
@@ -157,6 +290,49 @@ func (s *cartService) UpdateCart(ctx context.Context, id int64, req *dto.UpdateC
 	return &updateCart, nil
 }
 
+// SetCartExpiry overrides when a cart expires, or removes its expiry when expiresAt is nil.
+// An expiry in the past expires the cart immediately.
+func (s *cartService) SetCartExpiry(ctx context.Context, cartID int64, expiresAt *time.Time) (*domain.Cart, error) {
+	now := time.Now()
+	if expiresAt != nil && s.expiry.MaxExpiry > 0 && expiresAt.After(now.Add(s.expiry.MaxExpiry)) {
+		return nil, ErrCartExpiryTooFar
+	}
+
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	cart.ExpiresAt = expiresAt
+	cart.UpdatedAt = now
+
+	err = s.cartRepo.UpdateCart(ctx, cart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cart expiry: %w", err)
+	}
+
+	return cart, nil
+}
+
+// SetCartTaxExempt marks whether a cart is tax exempt, after staff have checked the
+// customer's exemption
+func (s *cartService) SetCartTaxExempt(ctx context.Context, cartID int64, taxExempt bool) (*domain.Cart, error) {
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	cart.TaxExempt = taxExempt
+	cart.UpdatedAt = time.Now()
+
+	err = s.cartRepo.UpdateCart(ctx, cart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cart tax exemption: %w", err)
+	}
+
+	return cart, nil
+}
+
 // DeleteCart deletes a cart
 func (s *cartService) DeleteCart(ctx context.Context, id int64) error {
 	// Check if cart exists
@@ -178,15 +354,17 @@ func (s *cartService) DeleteCart(ctx context.Context, id int64) error {
 func (s *cartService) GetOrCreateCart(ctx context.Context, userID *int64, sessionID string, currency string) (*domain.Cart, error) {
 	// Check if cart already exists for this session/user combination
 	existingCart, err := s.cartRepo.GetCartBySessionOrUser(ctx, sessionID, userID)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	if err != nil && !errors.Is(err, repository.ErrCartNotFound) {
 		return nil, fmt.Errorf("failed to check existing cart: %w", err)
 	}
 
 	// If cart exists and is not expired, return existing cart
 	if existingCart != nil && (existingCart.ExpiresAt == nil || existingCart.ExpiresAt.After(time.Now())) {
 		// Update currency if different
-		if existingCart.Currency != currency {
-			existingCart.Currency = currency
+		if !strings.EqualFold(existingCart.Currency, currency) {
+			if err := s.changeCartCurrency(ctx, existingCart, currency); err != nil {
+				return nil, err
+			}
 			existingCart.UpdatedAt = time.Now()
 			if err := s.cartRepo.UpdateCart(ctx, existingCart); err != nil {
 				return nil, fmt.Errorf("failed to update cart currency: %w", err)
@@ -203,6 +381,9 @@ func (s *cartService) GetOrCreateCart(ctx context.Context, userID *int64, sessio
 	}
 
 	cart, err := s.CreateCart(ctx, req)
+	if errors.Is(err, repository.ErrCartExists) {
+		cart, err = s.resolveCartConflict(ctx, req)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cart: %w", err)
 	}
@@ -210,27 +391,85 @@ func (s *cartService) GetOrCreateCart(ctx context.Context, userID *int64, sessio
 	return cart, nil
 }
 
+// resolveCartConflict handles a cart insert that hit the one-cart-per-owner constraint.
+// Either a concurrent request created the cart first, in which case that cart is
+// returned, or an expired cart still holds the key and is replaced by a fresh one.
+func (s *cartService) resolveCartConflict(ctx context.Context, req *dto.CreateCartRequest) (*domain.Cart, error) {
+	existingCart, err := s.cartRepo.GetCartBySessionOrUser(ctx, req.SessionID, req.UserID)
+	if err == nil {
+		return existingCart, nil
+	}
+	if !errors.Is(err, repository.ErrCartNotFound) {
+		return nil, fmt.Errorf("failed to get existing cart: %w", err)
+	}
+
+	deleted, err := s.cartRepo.DeleteExpiredCartForOwner(ctx, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, repository.ErrCartExists
+	}
+
+	cart, err := s.CreateCart(ctx, req)
+	if errors.Is(err, repository.ErrCartExists) {
+		// Lost the race to recreate the cart; the winner is active now
+		return s.cartRepo.GetCartBySessionOrUser(ctx, req.SessionID, req.UserID)
+	}
+	return cart, err
+}
+
 // Cart Items
 
 // AddItemToCart adds an item to the cart
 func (s *cartService) AddItemToCart(ctx context.Context, cartID int64, req *dto.AddToCartRequest) (*domain.CartItem, error) {
+	if err := checkCartItemQuantity(req.Quantity); err != nil {
+		return nil, err
+	}
+
 	// Check if cart exists
-	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
+	if err := s.checkCatalogCurrency(cart); err != nil {
+		return nil, err
+	}
 
-	// Get current product price
-	unitPrice, err := s.getCurrentProductPrice(ctx, req.ProductID, req.ProductVariantID)
+	// Get current product price; inactive or unpriced products and variants cannot be added
+	unitPrice, err := s.getPurchasablePrice(ctx, req.ProductID, req.ProductVariantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product price: %w", err)
+		return nil, err
+	}
+
+	// A concurrent add can change or create the item between reading and writing it;
+	// merge again from a fresh read when that happens
+	for attempt := 1; ; attempt++ {
+		item, err := s.addOrMergeCartItem(ctx, cartID, req, unitPrice)
+		if err == nil {
+			return item, nil
+		}
+		retryable := errors.Is(err, repository.ErrStaleCartItem) || errors.Is(err, repository.ErrAlreadyExists)
+		if !retryable || attempt >= cartItemMergeAttempts {
+			return nil, err
+		}
 	}
+}
 
+// addOrMergeCartItem adds req's quantity to the cart's existing item for the product, or
+// creates the item if the cart has none
+func (s *cartService) addOrMergeCartItem(ctx context.Context, cartID int64, req *dto.AddToCartRequest, unitPrice float64) (*domain.CartItem, error) {
 	// Check if item already exists in cart
 	existingItem, err := s.cartRepo.GetCartItemByProduct(ctx, cartID, req.ProductID, req.ProductVariantID)
 	if err == nil {
+		// Item exists, the combined quantity must still be in stock
+		quantity, err := s.addableQuantity(ctx, req, existingItem.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
 		// Item exists, update quantity
-		existingItem.Quantity += req.Quantity
+		existingItem.Quantity += quantity
 		existingItem.UnitPrice = unitPrice // Use current product price
 		existingItem.TotalPrice = existingItem.UnitPrice * float64(existingItem.Quantity)
 		existingItem.UpdatedAt = time.Now()
@@ -243,14 +482,19 @@ func (s *cartService) AddItemToCart(ctx context.Context, cartID int64, req *dto.
 		return existingItem, nil
 	}
 
+	quantity, err := s.addableQuantity(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new cart item
 	cartItem := &domain.CartItem{
 		CartID:           cartID,
 		ProductID:        req.ProductID,
 		ProductVariantID: req.ProductVariantID,
-		Quantity:         req.Quantity,
+		Quantity:         quantity,
 		UnitPrice:        unitPrice, // Use current product price
-		TotalPrice:       unitPrice * float64(req.Quantity),
+		TotalPrice:       unitPrice * float64(quantity),
 	}
 
 	err = s.cartRepo.AddItemToCart(ctx, cartItem)
@@ -261,6 +505,53 @@ func (s *cartService) AddItemToCart(ctx context.Context, cartID int64, req *dto.
 	return cartItem, nil
 }
 
+// addableQuantity returns how many of req's units can join the inCart units already in
+// the cart. When stock falls short the request is rejected, or with ClampToStock cut down
+// to what is left as long as at least one unit is.
+func (s *cartService) addableQuantity(ctx context.Context, req *dto.AddToCartRequest, inCart int) (int, error) {
+	err := s.checkStockAvailable(ctx, req.ProductID, req.ProductVariantID, inCart+req.Quantity)
+	var stockErr *InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		return req.Quantity, err
+	}
+
+	stockErr.MaxAddable = max(stockErr.Available-inCart, 0)
+	if req.ClampToStock && stockErr.MaxAddable > 0 {
+		return stockErr.MaxAddable, nil
+	}
+	return 0, stockErr
+}
+
+// checkCatalogCurrency returns ErrCurrencyMismatch unless catalog prices can go into the
+// cart as they are
+func (s *cartService) checkCatalogCurrency(cart *domain.Cart) error {
+	catalog := s.pricing.CatalogCurrency
+	if catalog == "" || strings.EqualFold(cart.Currency, catalog) {
+		return nil
+	}
+	return fmt.Errorf("%w: cart %d is in %s but products are priced in %s", ErrCurrencyMismatch, cart.ID, cart.Currency, catalog)
+}
+
+// changeCartCurrency sets cart's currency. Items keep the unit price they were added at,
+// so a cart holding items cannot move to another currency.
+func (s *cartService) changeCartCurrency(ctx context.Context, cart *domain.Cart, currency string) error {
+	currency = strings.ToUpper(currency)
+	if currency == cart.Currency {
+		return nil
+	}
+
+	count, err := s.cartRepo.GetCartItemCount(ctx, cart.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart item count: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: cart %d holds items priced in %s", ErrCurrencyMismatch, cart.ID, cart.Currency)
+	}
+
+	cart.Currency = currency
+	return nil
+}
+
 // GetCartItemByID retrieves a cart item by ID
 func (s *cartService) GetCartItemByID(ctx context.Context, id int64) (*domain.CartItem, error) {
 	item, err := s.cartRepo.GetCartItemByID(ctx, id)
@@ -288,8 +579,99 @@ func (s *cartService) getCurrentProductPrice(ctx context.Context, productID int6
 	return product.Price, nil
 }
 
+// getAvailableProductPrice returns the current price for a product and variant after
+// checking that both are still active
+func (s *cartService) getAvailableProductPrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+	_, price, err := s.getAvailableProduct(ctx, productID, variantID)
+	return price, err
+}
+
+// getPurchasablePrice is getAvailableProductPrice for items going into a cart. When the
+// pricing policy rejects non-positive prices, it returns ErrInvalidPrice for a negative
+// price, and for a zero price unless the product is flagged as free.
+func (s *cartService) getPurchasablePrice(ctx context.Context, productID int64, variantID *int64) (float64, error) {
+	product, price, err := s.getAvailableProduct(ctx, productID, variantID)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.pricing.RejectNonPositivePrices && (price < 0 || (price == 0 && !product.IsFree)) {
+		return 0, fmt.Errorf("%w: product %d is priced at %.2f", ErrInvalidPrice, productID, price)
+	}
+
+	return price, nil
+}
+
+// getAvailableProduct returns a product and the current price of it or its variant
+// after checking that both are still active
+func (s *cartService) getAvailableProduct(ctx context.Context, productID int64, variantID *int64) (*domain.Product, float64, error) {
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get product: %w", err)
+	}
+	if !product.IsActive {
+		return nil, 0, &ProductUnavailableError{ProductID: productID}
+	}
+
+	if variantID == nil {
+		return product, product.Price, nil
+	}
+
+	variant, err := s.productRepo.GetProductVariantByID(ctx, *variantID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get product variant: %w", err)
+	}
+	if !variant.IsActive {
+		return nil, 0, &ProductUnavailableError{ProductID: productID, ProductVariantID: variantID}
+	}
+
+	return product, variant.Price, nil
+}
+
+// checkStockAvailable verifies that quantity units are available for the product,
+// or for the variant when variantID is set. Variants are checked against their
+// own inventory row, never the parent product's. Items without an inventory row
+// pass unless their product tracks quantity, in which case none are available.
+func (s *cartService) checkStockAvailable(ctx context.Context, productID int64, variantID *int64, quantity int) error {
+	available := 0
+	inventory, err := s.inventoryRepo.GetInventoryByProduct(ctx, productID, variantID)
+	switch {
+	case err == nil:
+		available = inventory.AvailableQuantity
+	case errors.Is(err, repository.ErrInventoryNotFound):
+		// Without a stock record only products that do not track quantity can be sold
+		product, err := s.productRepo.GetProductByID(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+		if !product.TrackQuantity {
+			return nil
+		}
+	default:
+		return fmt.Errorf("failed to get inventory: %w", err)
+	}
+
+	if available < quantity {
+		return &InsufficientStockError{
+			ProductID:        productID,
+			ProductVariantID: variantID,
+			Requested:        quantity,
+			Available:        available,
+			MaxAddable:       available,
+		}
+	}
+
+	return nil
+}
+
 // UpdateCartItem updates an existing cart item
 func (s *cartService) UpdateCartItem(ctx context.Context, id int64, req *dto.UpdateCartItemRequest) (*domain.CartItem, error) {
+	if req.Quantity != nil {
+		if err := checkCartItemQuantity(*req.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get existing item
 	existingItem, err := s.cartRepo.GetCartItemByID(ctx, id)
 	if err != nil {
@@ -309,6 +691,12 @@ func (s *cartService) UpdateCartItem(ctx context.Context, id int64, req *dto.Upd
 		updateItem.Quantity = *req.Quantity
 	}
 
+	if updateItem.Quantity > existingItem.Quantity {
+		if err := s.checkStockAvailable(ctx, updateItem.ProductID, updateItem.ProductVariantID, updateItem.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
 	// Always use current product price
 	updateItem.UnitPrice = unitPrice
 	updateItem.TotalPrice = updateItem.UnitPrice * float64(updateItem.Quantity)
@@ -340,20 +728,149 @@ func (s *cartService) DeleteCartItem(ctx context.Context, id int64) error {
 	return nil
 }
 
-// GetCartItems retrieves all items in a cart
-func (s *cartService) GetCartItems(ctx context.Context, cartID int64) ([]*domain.CartItem, error) {
+// IncrementCartItem adds req.Amount units to a cart item at the current price. The new
+// quantity must be in stock; with ClampToStock the increment is cut down to what is left
+// as long as at least one unit is.
+func (s *cartService) IncrementCartItem(ctx context.Context, id int64, req *dto.AdjustCartItemQuantityRequest) (*domain.CartItem, error) {
+	amount, err := cartItemAdjustment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return retryStaleCartItem(func() (*domain.CartItem, error) {
+		item, err := s.cartRepo.GetCartItemByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart item: %w", err)
+		}
+
+		unitPrice, err := s.getPurchasablePrice(ctx, item.ProductID, item.ProductVariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		added, err := s.addableQuantity(ctx, &dto.AddToCartRequest{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         amount,
+			ClampToStock:     req.ClampToStock,
+		}, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Quantity += added
+		item.UnitPrice = unitPrice
+		item.TotalPrice = item.UnitPrice * float64(item.Quantity)
+		item.UpdatedAt = time.Now()
+
+		if err := s.cartRepo.UpdateCartItem(ctx, id, item); err != nil {
+			return nil, fmt.Errorf("failed to update cart item: %w", err)
+		}
+		return item, nil
+	})
+}
+
+// DecrementCartItem takes req.Amount units off a cart item. An item left with no units is
+// deleted, in which case the returned item is nil.
+func (s *cartService) DecrementCartItem(ctx context.Context, id int64, req *dto.AdjustCartItemQuantityRequest) (*domain.CartItem, error) {
+	amount, err := cartItemAdjustment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return retryStaleCartItem(func() (*domain.CartItem, error) {
+		item, err := s.cartRepo.GetCartItemByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart item: %w", err)
+		}
+
+		if item.Quantity <= amount {
+			if err := s.cartRepo.DeleteCartItemAtVersion(ctx, id, item.Version); err != nil {
+				return nil, fmt.Errorf("failed to delete cart item: %w", err)
+			}
+			return nil, nil
+		}
+
+		unitPrice, err := s.getCurrentProductPrice(ctx, item.ProductID, item.ProductVariantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product price: %w", err)
+		}
+
+		item.Quantity -= amount
+		item.UnitPrice = unitPrice
+		item.TotalPrice = item.UnitPrice * float64(item.Quantity)
+		item.UpdatedAt = time.Now()
+
+		if err := s.cartRepo.UpdateCartItem(ctx, id, item); err != nil {
+			return nil, fmt.Errorf("failed to update cart item: %w", err)
+		}
+		return item, nil
+	})
+}
+
+// cartItemAdjustment returns the number of units req moves a cart item by
+func cartItemAdjustment(req *dto.AdjustCartItemQuantityRequest) (int, error) {
+	if req.Amount == 0 {
+		return 1, nil
+	}
+	if err := checkQuantity(req.Amount, minCartItemQuantity); err != nil {
+		return 0, err
+	}
+	return req.Amount, nil
+}
+
+// retryStaleCartItem runs write again from a fresh read while it loses races with
+// concurrent writes to the same cart item, up to cartItemMergeAttempts times
+func retryStaleCartItem(write func() (*domain.CartItem, error)) (*domain.CartItem, error) {
+	for attempt := 1; ; attempt++ {
+		item, err := write()
+		if !errors.Is(err, repository.ErrStaleCartItem) || attempt >= cartItemMergeAttempts {
+			return item, err
+		}
+	}
+}
+
+// GetCartItems retrieves a page of items in a cart
+func (s *cartService) GetCartItems(ctx context.Context, cartID int64, page, limit int) (*dto.ListCartItemsResponse, error) {
 	// Check if cart exists
 	_, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
 
-	items, err := s.cartRepo.GetCartItems(ctx, cartID)
+	page, limit = httpx.ClampPagination(page, limit)
+	offset := (page - 1) * limit
+
+	items, total, err := s.cartRepo.GetCartItems(ctx, cartID, offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart items: %w", err)
 	}
 
-	return items, nil
+	itemResponses := make([]dto.CartItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = dto.CartItemResponse{
+			ID:               item.ID,
+			CartID:           item.CartID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			UnitPrice:        item.UnitPrice,
+			TotalPrice:       item.TotalPrice,
+			CreatedAt:        httpx.FormatTime(item.CreatedAt),
+			UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
+		}
+	}
+
+	// Calculate total pages
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &dto.ListCartItemsResponse{
+		Items:      itemResponses,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
 }
 
 // ClearCartItems removes all items from a cart
@@ -376,55 +893,222 @@ func (s *cartService) ClearCartItems(ctx context.Context, cartID int64) error {
 
 // GetCartSummary retrieves a complete cart summary
 func (s *cartService) GetCartSummary(ctx context.Context, cartID int64) (*dto.CartSummaryResponse, error) {
-	// Check if cart exists
-	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	// Get cart summary from repository, which reports missing carts, and apply tax and totals
+	summary, err := s.cartRepo.GetCartSummary(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart summary: %w", err)
+	}
+	if _, err := s.priceSummary(ctx, summary); err != nil {
+		return nil, err
+	}
+
+	// Convert to response DTO
+	itemResponses := make([]dto.CartItemResponse, len(summary.Items))
+	for i := range summary.Items {
+		itemResponses[i] = cartItemResponse(&summary.Items[i])
+	}
+
+	return &dto.CartSummaryResponse{
+		CartID:             summary.CartID,
+		CartTotalsResponse: cartTotalsResponse(summary),
+		Items:              itemResponses,
+	}, nil
+}
+
+// GetFullCart retrieves a cart with its items, coupons, shipping and totals in one
+// response. Item product names and SKUs are joined in by the repository and images
+// are looked up in batch.
+func (s *cartService) GetFullCart(ctx context.Context, cartID int64) (*dto.FullCartResponse, error) {
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
 
-	// Get cart summary from repository
 	summary, err := s.cartRepo.GetCartSummary(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart summary: %w", err)
 	}
+	pricing, err := s.priceSummary(ctx, summary)
+	if err != nil {
+		return nil, err
+	}
 
-	// Convert to response DTO
-	itemResponses := make([]dto.CartItemResponse, len(summary.Items))
-	for i, item := range summary.Items {
-		itemResponses[i] = dto.CartItemResponse{
-			ID:               item.ID,
-			CartID:           item.CartID,
-			ProductID:        item.ProductID,
-			ProductVariantID: item.ProductVariantID,
-			Quantity:         item.Quantity,
-			UnitPrice:        item.UnitPrice,
-			TotalPrice:       item.TotalPrice,
-			CreatedAt:        item.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:        item.UpdatedAt.Format(time.RFC3339),
+	coupons, _, err := s.cartRepo.GetCartCoupons(ctx, cartID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart coupons: %w", err)
+	}
+
+	shipping, err := s.cartRepo.GetCartShipping(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart shipping: %w", err)
+	}
+
+	items, err := s.cartRepo.GetCartItemDetails(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	images, err := s.productRepo.GetPrimaryImages(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+
+	response := &dto.FullCartResponse{
+		Cart:    cartResponse(cart),
+		Items:   make([]dto.FullCartItemResponse, len(items)),
+		Coupons: make([]dto.CartCouponResponse, len(coupons)),
+		Summary: cartTotalsResponse(summary),
+	}
+
+	for i, item := range items {
+		response.Items[i] = dto.FullCartItemResponse{
+			CartItemResponse: cartItemResponse(&item.CartItem),
+			ProductName:      item.ProductName,
+			SKU:              item.SKU,
+		}
+		if image, ok := images[item.ProductID]; ok {
+			response.Items[i].ProductImage = &image.URL
 		}
 	}
 
-	return &dto.CartSummaryResponse{
-		CartID:         summary.CartID,
-		ItemCount:      summary.ItemCount,
-		Subtotal:       summary.Subtotal,
-		TaxAmount:      summary.TaxAmount,
+	// Coupons show what they take off the cart now, not when they were applied
+	discounts := make(map[string]float64, len(pricing.coupons))
+	for _, price := range pricing.coupons {
+		discounts[price.Code] = price.Discount
+	}
+	for i, coupon := range coupons {
+		response.Coupons[i] = dto.CartCouponResponse{
+			ID:             coupon.ID,
+			CartID:         coupon.CartID,
+			CouponCode:     coupon.CouponCode,
+			DiscountAmount: discounts[coupon.CouponCode],
+			CreatedAt:      httpx.FormatTime(coupon.CreatedAt),
+		}
+	}
+
+	if shipping != nil {
+		response.Shipping = &dto.CartShippingResponse{
+			ID:               shipping.ID,
+			CartID:           shipping.CartID,
+			ShippingMethodID: shipping.ShippingMethodID,
+			ShippingMethod:   shipping.ShippingMethod,
+			ShippingAmount:   pricing.shippingAmount,
+			EstimatedDays:    shipping.EstimatedDays,
+			CreatedAt:        httpx.FormatTime(shipping.CreatedAt),
+		}
+	}
+
+	return response, nil
+}
+
+func cartResponse(cart *domain.Cart) dto.CartResponse {
+	response := dto.CartResponse{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		SessionID: cart.SessionID,
+		Currency:  cart.Currency,
+		CreatedAt: httpx.FormatTime(cart.CreatedAt),
+		UpdatedAt: httpx.FormatTime(cart.UpdatedAt),
+		TaxExempt: cart.TaxExempt,
+	}
+	if cart.ExpiresAt != nil {
+		expiresAt := httpx.FormatTime(*cart.ExpiresAt)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
+
+func cartItemResponse(item *domain.CartItem) dto.CartItemResponse {
+	return dto.CartItemResponse{
+		ID:               item.ID,
+		CartID:           item.CartID,
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		Quantity:         item.Quantity,
+		UnitPrice:        item.UnitPrice,
+		TotalPrice:       item.TotalPrice,
+		CreatedAt:        httpx.FormatTime(item.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(item.UpdatedAt),
+	}
+}
+
+// summaryPricing is what priceSummary worked out on the way to a summary's totals
+type summaryPricing struct {
+	// coupons is what each applied coupon took off
+	coupons []couponPrice
+	// shippingAmount is the shipping quoted for the cart, before any free shipping threshold
+	shippingAmount float64
+	// shippingUnavailable is set when the cart's shipping method can no longer carry it
+	shippingUnavailable bool
+}
+
+// priceSummary quotes the cart's shipping and prices its coupons against its current
+// contents, resolves the tax rates for its destination and fills in its tax and totals.
+// Carts without a destination, or whose destination the tax service does not cover, are
+// taxed at the flat default rate.
+func (s *cartService) priceSummary(ctx context.Context, summary *domain.CartSummary) (*summaryPricing, error) {
+	available, err := s.requoteShipping(ctx, summary)
+	if err != nil {
+		return nil, err
+	}
+	pricing := &summaryPricing{shippingAmount: summary.ShippingAmount, shippingUnavailable: !available}
+	pricing.coupons = s.pricing.applyCoupons(summary, time.Now())
+
+	if s.taxes != nil && summary.Destination.Country != "" {
+		rates, err := s.taxes.GetTaxRates(ctx, summary.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tax rates: %w", err)
+		}
+		if len(rates) > 0 {
+			summary.TaxRates = rates
+		}
+	}
+
+	s.pricing.Apply(summary)
+	return pricing, nil
+}
+
+func cartTotalsResponse(summary *domain.CartSummary) dto.CartTotalsResponse {
+	lines := make([]dto.TaxLineResponse, 0, len(summary.TaxLines))
+	for _, line := range summary.TaxLines {
+		lines = append(lines, dto.TaxLineResponse{Name: line.Name, Rate: line.Rate, Amount: line.Amount})
+	}
+
+	return dto.CartTotalsResponse{
+		ItemCount: summary.ItemCount,
+		Subtotal:  summary.Subtotal,
+		TaxAmount: summary.TaxAmount,
+		Tax: dto.TaxBreakdown{
+			TaxableSubtotal: summary.TaxableSubtotal,
+			TaxRate:         summary.TaxRate,
+			TaxAmount:       summary.TaxAmount,
+			TaxExempt:       summary.TaxExempt,
+			Lines:           lines,
+		},
 		ShippingAmount: summary.ShippingAmount,
+		FreeShipping:   summary.FreeShipping,
 		DiscountAmount: summary.DiscountAmount,
 		TotalAmount:    summary.TotalAmount,
 		Currency:       summary.Currency,
-		Items:          itemResponses,
-	}, nil
+	}
 }
 
 // CalculateCartTotal calculates the total amount for a cart
 func (s *cartService) CalculateCartTotal(ctx context.Context, cartID int64) (float64, error) {
-	total, err := s.cartRepo.CalculateCartTotal(ctx, cartID)
+	summary, err := s.cartRepo.GetCartSummary(ctx, cartID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to calculate cart total: %w", err)
 	}
+	if _, err := s.priceSummary(ctx, summary); err != nil {
+		return 0, err
+	}
 
-	return total, nil
+	return summary.TotalAmount, nil
 }
 
 // GetCartItemCount gets the total number of items in a cart
@@ -442,7 +1126,7 @@ func (s *cartService) GetCartItemCount(ctx context.Context, cartID int64) (int,
 // ApplyCouponToCart applies a coupon to a cart
 func (s *cartService) ApplyCouponToCart(ctx context.Context, cartID int64, req *dto.ApplyCouponRequest) (*domain.CartCoupon, error) {
 	// Check if cart exists
-	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -453,9 +1137,39 @@ func (s *cartService) ApplyCouponToCart(ctx context.Context, cartID int64, req *
 		return nil, fmt.Errorf("coupon %s is already applied to this cart", req.CouponCode)
 	}
 
-	// In a real application, you would validate the coupon here
-	// For now, we'll create a simple discount
-	discountAmount := 10.0 // $10 discount
+	coupon, err := s.couponRepo.GetCouponByCode(ctx, req.CouponCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coupon: %w", err)
+	}
+	if coupon.PerUserLimit > 0 && cart.UserID == nil {
+		return nil, ErrCouponRequiresAccount
+	}
+
+	// Price the coupon against the cart as it is now, after the coupons already on it.
+	// The amount stored with it is what it took off when applied; summaries price
+	// every coupon again against the cart's current contents.
+	summary, err := s.cartRepo.GetCartSummary(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart summary: %w", err)
+	}
+	now := time.Now()
+	if err := checkCouponUsable(coupon, summary.Subtotal, now); err != nil {
+		return nil, err
+	}
+	s.pricing.applyCoupons(summary, now)
+	discountAmount := couponDiscount(coupon, summary)
+
+	// Enforce usage limits, recording the redemption now unless it waits for checkout
+	if s.redemption.RedeemAtCheckout {
+		if err := s.checkCouponLimits(ctx, coupon, cart.UserID); err != nil {
+			return nil, err
+		}
+	} else {
+		redemption := &domain.CouponRedemption{CouponID: coupon.ID, UserID: cart.UserID, CartID: cartID}
+		if err := s.couponRepo.RedeemCoupon(ctx, redemption); err != nil {
+			return nil, fmt.Errorf("failed to redeem coupon: %w", err)
+		}
+	}
 
 	cartCoupon := &domain.CartCoupon{
 		CartID:         cartID,
@@ -465,12 +1179,33 @@ func (s *cartService) ApplyCouponToCart(ctx context.Context, cartID int64, req *
 
 	err = s.cartRepo.ApplyCouponToCart(ctx, cartCoupon)
 	if err != nil {
+		if !s.redemption.RedeemAtCheckout {
+			_ = s.couponRepo.ReleaseCouponRedemption(ctx, coupon.ID, cartID)
+		}
 		return nil, fmt.Errorf("failed to apply coupon to cart: %w", err)
 	}
 
 	return cartCoupon, nil
 }
 
+// checkCouponLimits returns ErrCouponLimitReached if a coupon's global or per-user
+// limit is already used up by recorded redemptions
+func (s *cartService) checkCouponLimits(ctx context.Context, coupon *domain.Coupon, userID *int64) error {
+	total, byUser, err := s.couponRepo.CountCouponRedemptions(ctx, coupon.ID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check coupon limits: %w", err)
+	}
+
+	if coupon.UsageLimit > 0 && total >= int64(coupon.UsageLimit) {
+		return repository.ErrCouponLimitReached
+	}
+	if coupon.PerUserLimit > 0 && byUser >= int64(coupon.PerUserLimit) {
+		return repository.ErrCouponLimitReached
+	}
+
+	return nil
+}
+
 // RemoveCouponFromCart removes a coupon from a cart
 func (s *cartService) RemoveCouponFromCart(ctx context.Context, cartID int64, req *dto.RemoveCouponRequest) error {
 	// Check if cart exists
@@ -484,31 +1219,69 @@ func (s *cartService) RemoveCouponFromCart(ctx context.Context, cartID int64, re
 		return fmt.Errorf("failed to remove coupon from cart: %w", err)
 	}
 
+	// Coupons redeemed on apply are freed again once removed
+	if !s.redemption.RedeemAtCheckout {
+		coupon, err := s.couponRepo.GetCouponByCode(ctx, req.CouponCode)
+		if err != nil {
+			if errors.Is(err, repository.ErrCouponNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to get coupon: %w", err)
+		}
+		if err := s.couponRepo.ReleaseCouponRedemption(ctx, coupon.ID, cartID); err != nil {
+			return fmt.Errorf("failed to release coupon redemption: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// GetCartCoupons retrieves all coupons applied to a cart
-func (s *cartService) GetCartCoupons(ctx context.Context, cartID int64) ([]*domain.CartCoupon, error) {
+// GetCartCoupons retrieves a page of coupons applied to a cart
+func (s *cartService) GetCartCoupons(ctx context.Context, cartID int64, page, limit int) (*dto.ListCartCouponsResponse, error) {
 	// Check if cart exists
 	_, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
 
-	coupons, err := s.cartRepo.GetCartCoupons(ctx, cartID)
+	page, limit = httpx.ClampPagination(page, limit)
+	offset := (page - 1) * limit
+
+	coupons, total, err := s.cartRepo.GetCartCoupons(ctx, cartID, offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart coupons: %w", err)
 	}
 
-	return coupons, nil
+	couponResponses := make([]dto.CartCouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		couponResponses[i] = dto.CartCouponResponse{
+			ID:             coupon.ID,
+			CartID:         coupon.CartID,
+			CouponCode:     coupon.CouponCode,
+			DiscountAmount: coupon.DiscountAmount,
+			CreatedAt:      httpx.FormatTime(coupon.CreatedAt),
+		}
+	}
+
+	// Calculate total pages
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &dto.ListCartCouponsResponse{
+		Coupons:    couponResponses,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
 }
 
 // Cart Shipping
 
-// SetCartShipping sets shipping information for a cart
+// SetCartShipping sets shipping information for a cart. The amount is always quoted
+// from the cart's contents and address, and once the cart holds items so are the method
+// name and estimate, see quoteShipping.
 func (s *cartService) SetCartShipping(ctx context.Context, cartID int64, req *dto.SetShippingRequest) (*domain.CartShipping, error) {
-	// Check if cart exists
-	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -517,10 +1290,13 @@ func (s *cartService) SetCartShipping(ctx context.Context, cartID int64, req *dt
 		CartID:           cartID,
 		ShippingMethodID: req.ShippingMethodID,
 		ShippingMethod:   req.ShippingMethod,
-		ShippingAmount:   req.ShippingAmount,
 		EstimatedDays:    req.EstimatedDays,
 	}
 
+	if err := s.quoteShipping(ctx, cart, shipping); err != nil {
+		return nil, err
+	}
+
 	err = s.cartRepo.SetCartShipping(ctx, shipping)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set cart shipping: %w", err)
@@ -529,10 +1305,80 @@ func (s *cartService) SetCartShipping(ctx context.Context, cartID int64, req *dt
 	return shipping, nil
 }
 
-// UpdateCartShipping updates shipping information for a cart
+// quoteShipping prices the chosen shipping method for the cart's items and address,
+// billing bulky items at their dimensional weight. Empty carts and carts of only digital
+// products ship free, as does every cart of a service without a rate provider.
+func (s *cartService) quoteShipping(ctx context.Context, cart *domain.Cart, shipping *domain.CartShipping) error {
+	shipping.ShippingAmount = 0
+	if s.shippingRates == nil {
+		return nil
+	}
+
+	items, _, err := s.cartRepo.GetCartItems(ctx, cart.ID, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get cart items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	shippingCart, err := s.shippingCart(ctx, cart, items)
+	if err != nil {
+		return err
+	}
+	if shippingCart.ShippableItems == 0 {
+		return nil
+	}
+
+	destination := domain.ShippingDestination{Country: cart.ShipCountry, State: cart.ShipState, PostalCode: cart.ShipPostalCode}
+	rates, err := s.shippingRates.GetRates(ctx, shippingCart, destination)
+	if err != nil {
+		return fmt.Errorf("failed to get shipping rates: %w", err)
+	}
+
+	for _, rate := range rates {
+		if rate.MethodID == shipping.ShippingMethodID {
+			shipping.ShippingMethod = rate.Method
+			shipping.ShippingAmount = rate.Amount
+			shipping.EstimatedDays = rate.EstimatedDays
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: method %d", ErrShippingMethodUnavailable, shipping.ShippingMethodID)
+}
+
+// requoteShipping quotes the cart's shipping method again for the items in it now, so
+// the shipping charged follows items being added or removed. When the method can no
+// longer carry the cart it returns false and leaves the amount as last quoted.
+func (s *cartService) requoteShipping(ctx context.Context, summary *domain.CartSummary) (bool, error) {
+	if s.shippingRates == nil || summary.ShippingMethodID == 0 {
+		return true, nil
+	}
+
+	cart := &domain.Cart{
+		ID:             summary.CartID,
+		Currency:       summary.Currency,
+		ShipCountry:    summary.Destination.Country,
+		ShipState:      summary.Destination.State,
+		ShipPostalCode: summary.Destination.PostalCode,
+	}
+	shipping := &domain.CartShipping{CartID: summary.CartID, ShippingMethodID: summary.ShippingMethodID}
+	err := s.quoteShipping(ctx, cart, shipping)
+	if errors.Is(err, ErrShippingMethodUnavailable) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	summary.ShippingAmount = shipping.ShippingAmount
+	return true, nil
+}
+
+// UpdateCartShipping updates shipping information for a cart. The amount is quoted again
+// for the cart as it is now, see quoteShipping.
 func (s *cartService) UpdateCartShipping(ctx context.Context, cartID int64, req *dto.UpdateShippingRequest) (*domain.CartShipping, error) {
-	// Check if cart exists
-	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart: %w", err)
 	}
@@ -556,13 +1402,14 @@ func (s *cartService) UpdateCartShipping(ctx context.Context, cartID int64, req
 	if req.ShippingMethod != nil {
 		updateShipping.ShippingMethod = *req.ShippingMethod
 	}
-	if req.ShippingAmount != nil {
-		updateShipping.ShippingAmount = *req.ShippingAmount
-	}
 	if req.EstimatedDays != nil {
 		updateShipping.EstimatedDays = *req.EstimatedDays
 	}
 
+	if err := s.quoteShipping(ctx, cart, &updateShipping); err != nil {
+		return nil, err
+	}
+
 	err = s.cartRepo.UpdateCartShipping(ctx, cartID, &updateShipping)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update cart shipping: %w", err)
@@ -587,6 +1434,104 @@ func (s *cartService) GetCartShipping(ctx context.Context, cartID int64) (*domai
 	return shipping, nil
 }
 
+// GetShippingOptions lists the shipping methods available for a cart with what each
+// would cost. Rates are priced on the cart's total billable weight; a cart holding only
+// digital products has no options since nothing ships.
+func (s *cartService) GetShippingOptions(ctx context.Context, cartID int64, destination domain.ShippingDestination) ([]domain.ShippingRate, error) {
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	items, _, err := s.cartRepo.GetCartItems(ctx, cartID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, repository.ErrCartEmpty
+	}
+
+	shippingCart, err := s.shippingCart(ctx, cart, items)
+	if err != nil {
+		return nil, err
+	}
+	if shippingCart.ShippableItems == 0 {
+		// Only digital products, nothing to ship
+		return []domain.ShippingRate{}, nil
+	}
+
+	rates, err := s.shippingRates.GetRates(ctx, shippingCart, destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipping rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// SetCartAddress records where a cart ships to and returns its summary re-priced with
+// the tax rates of that address
+func (s *cartService) SetCartAddress(ctx context.Context, cartID int64, address domain.ShippingDestination) (*dto.CartSummaryResponse, error) {
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	cart.ShipCountry = address.Country
+	cart.ShipState = address.State
+	cart.ShipPostalCode = address.PostalCode
+
+	err = s.cartRepo.UpdateCart(ctx, cart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cart address: %w", err)
+	}
+
+	return s.GetCartSummary(ctx, cartID)
+}
+
+// shippingCart totals a cart's items for a rate provider. A variant's own weight is
+// used when it has one, otherwise the product's, and bulky products count at their
+// dimensional weight. Digital products add nothing to the weight.
+func (s *cartService) shippingCart(ctx context.Context, cart *domain.Cart, items []*domain.CartItem) (*ShippingCart, error) {
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	shippingCart := &ShippingCart{CartID: cart.ID, Currency: cart.Currency}
+	for _, item := range items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: ID %d", repository.ErrProductNotFound, item.ProductID)
+		}
+
+		weight := product.Weight
+		if item.ProductVariantID != nil {
+			variant, err := s.productRepo.GetProductVariantByID(ctx, *item.ProductVariantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get product variant: %w", err)
+			}
+			if variant.Weight > 0 {
+				weight = variant.Weight
+			}
+		}
+
+		profile := product.ShippingProfile(weight, s.pricing.DimensionalDivisor)
+		if profile.Class != domain.ShippingClassDigital {
+			shippingCart.ShippableItems += item.Quantity
+		}
+		shippingCart.Weight += profile.Weight * float64(item.Quantity)
+		shippingCart.Subtotal += item.TotalPrice
+		shippingCart.ItemCount += item.Quantity
+	}
+
+	return shippingCart, nil
+}
+
 // DeleteCartShipping removes shipping information from a cart
 func (s *cartService) DeleteCartShipping(ctx context.Context, cartID int64) error {
 	// Check if cart exists
@@ -608,16 +1553,21 @@ func (s *cartService) DeleteCartShipping(ctx context.Context, cartID int64) erro
 // MergeCarts merges items from source cart to target cart
 func (s *cartService) MergeCarts(ctx context.Context, sourceCartID, targetCartID int64) error {
 	// Check if both carts exist
-	_, err := s.cartRepo.GetCartByID(ctx, sourceCartID)
+	sourceCart, err := s.cartRepo.GetCartByID(ctx, sourceCartID)
 	if err != nil {
 		return fmt.Errorf("failed to get source cart: %w", err)
 	}
 
-	_, err = s.cartRepo.GetCartByID(ctx, targetCartID)
+	targetCart, err := s.cartRepo.GetCartByID(ctx, targetCartID)
 	if err != nil {
 		return fmt.Errorf("failed to get target cart: %w", err)
 	}
 
+	// Merged items keep their unit prices, which are in the source cart's currency
+	if !strings.EqualFold(sourceCart.Currency, targetCart.Currency) {
+		return fmt.Errorf("%w: cannot merge a %s cart into a %s cart", ErrCurrencyMismatch, sourceCart.Currency, targetCart.Currency)
+	}
+
 	err = s.cartRepo.MergeCarts(ctx, sourceCartID, targetCartID)
 	if err != nil {
 		return fmt.Errorf("failed to merge carts: %w", err)
@@ -626,6 +1576,45 @@ func (s *cartService) MergeCarts(ctx context.Context, sourceCartID, targetCartID
 	return nil
 }
 
+// MergeGuestCartOnLogin folds the guest cart of a session into the cart of the user who
+// just logged in on it. Items in both carts have their quantities summed, the merged
+// cart is repriced at current catalog prices and the guest cart is deleted. A user
+// without a cart gets one in the guest cart's currency. When the session has no guest
+// cart the user's cart is returned unchanged, or nil if the user has none either.
+func (s *cartService) MergeGuestCartOnLogin(ctx context.Context, sessionID string, userID int64) (*domain.Cart, error) {
+	guestCart, err := s.cartRepo.GetGuestCartBySession(ctx, sessionID)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		userCart, err := s.cartRepo.GetCartBySessionOrUser(ctx, "", &userID)
+		if errors.Is(err, repository.ErrCartNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user cart: %w", err)
+		}
+		return userCart, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guest cart: %w", err)
+	}
+
+	// An empty user cart takes the guest cart's currency; one holding items must match it
+	userCart, err := s.GetOrCreateCart(ctx, &userID, sessionID, guestCart.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.MergeCarts(ctx, guestCart.ID, userCart.ID); err != nil {
+		return nil, fmt.Errorf("failed to merge guest cart: %w", err)
+	}
+
+	// Guest items may have been added at prices that have since changed
+	if _, _, err := s.cartRepo.RecalculateCartItemPrices(ctx, []int64{userCart.ID}); err != nil {
+		return nil, fmt.Errorf("failed to reprice merged cart: %w", err)
+	}
+
+	return userCart, nil
+}
+
 // ClearCart clears all items from a cart
 func (s *cartService) ClearCart(ctx context.Context, cartID int64) error {
 	// Check if cart exists
@@ -642,6 +1631,167 @@ func (s *cartService) ClearCart(ctx context.Context, cartID int64) error {
 	return nil
 }
 
+// DeduplicateCartItems merges cart items that refer to the same product and variant
+// into a single line with the summed quantity
+func (s *cartService) DeduplicateCartItems(ctx context.Context, cartID int64) (*dto.DeduplicateCartResponse, error) {
+	// Check if cart exists
+	_, err := s.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	itemsMerged, itemsRemoved, err := s.cartRepo.DeduplicateCartItems(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deduplicate cart items: %w", err)
+	}
+
+	return &dto.DeduplicateCartResponse{
+		CartID:       cartID,
+		ItemsMerged:  itemsMerged,
+		ItemsRemoved: itemsRemoved,
+	}, nil
+}
+
+// RecalculateAllActiveCarts reprices the items of every non-expired cart from current
+// product and variant prices, walking carts in bounded batches
+func (s *cartService) RecalculateAllActiveCarts(ctx context.Context) (*dto.RecalculateCartsResponse, error) {
+	response := &dto.RecalculateCartsResponse{}
+
+	var afterID int64
+	for {
+		cartIDs, err := s.cartRepo.ListActiveCartIDs(ctx, afterID, s.batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list active carts: %w", err)
+		}
+		if len(cartIDs) == 0 {
+			break
+		}
+
+		cartsUpdated, itemsChanged, err := s.cartRepo.RecalculateCartItemPrices(ctx, cartIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recalculate carts after ID %d: %w", afterID, err)
+		}
+
+		response.CartsScanned += len(cartIDs)
+		response.CartsUpdated += cartsUpdated
+		response.ItemsChanged += itemsChanged
+
+		if len(cartIDs) < s.batchSize {
+			break
+		}
+		afterID = cartIDs[len(cartIDs)-1]
+	}
+
+	return response, nil
+}
+
+// ValidateCartForCheckout reports cart items that can no longer be purchased because
+// their product was removed or deactivated, or they exceed available stock, and how far
+// the cart is below the minimum order for its currency
+func (s *cartService) ValidateCartForCheckout(ctx context.Context, cartID int64) (*dto.CartValidationResponse, error) {
+	items, _, err := s.cartRepo.GetCartItems(ctx, cartID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	response := &dto.CartValidationResponse{
+		CartID:       cartID,
+		Issues:       []dto.CartItemIssue{},
+		CouponIssues: []dto.CartCouponIssue{},
+	}
+	for _, item := range items {
+		issue := dto.CartItemIssue{
+			CartItemID:       item.ID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+		}
+
+		product, ok := products[item.ProductID]
+		switch {
+		case !ok:
+			issue.Reason = CartIssueProductNotFound
+		case !product.IsActive:
+			issue.Reason = CartIssueProductInactive
+		}
+
+		if issue.Reason == "" && item.ProductVariantID != nil {
+			variant, err := s.productRepo.GetProductVariantByID(ctx, *item.ProductVariantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get product variant: %w", err)
+			}
+			if !variant.IsActive {
+				issue.Reason = CartIssueVariantInactive
+			}
+		}
+
+		if issue.Reason == "" {
+			err := s.checkStockAvailable(ctx, item.ProductID, item.ProductVariantID, item.Quantity)
+			var stockErr *InsufficientStockError
+			switch {
+			case errors.As(err, &stockErr):
+				issue.Reason = CartIssueInsufficientStock
+			case err != nil:
+				return nil, err
+			}
+		}
+
+		if issue.Reason != "" {
+			response.Issues = append(response.Issues, issue)
+		}
+	}
+
+	// Coupons and shipping are checked again against the cart as it is now: a coupon may
+	// have expired or been disabled, items removed since may have taken the cart below a
+	// coupon's minimum and items added may be more than its shipping method can carry
+	summary, err := s.cartRepo.GetCartSummary(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart summary: %w", err)
+	}
+	pricing, err := s.priceSummary(ctx, summary)
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range pricing.coupons {
+		if reason := couponIssueReason(price.Err); reason != "" {
+			response.CouponIssues = append(response.CouponIssues, dto.CartCouponIssue{CouponCode: price.Code, Reason: reason})
+		}
+	}
+	response.ShippingUnavailable = pricing.shippingUnavailable
+
+	if minimum, shortfall := s.pricing.MinimumOrderShortfall(summary); shortfall > 0 {
+		response.MinimumOrderAmount = minimum
+		response.MinimumOrderShortfall = shortfall
+	}
+
+	response.Valid = len(response.Issues) == 0 && len(response.CouponIssues) == 0 &&
+		response.MinimumOrderShortfall == 0 && !response.ShippingUnavailable
+	return response, nil
+}
+
+// couponIssueReason returns the validation reason for an applied coupon that no longer
+// applies, or "" if err is nil
+func couponIssueReason(err error) string {
+	switch {
+	case errors.Is(err, ErrCouponInactive):
+		return CartIssueCouponInactive
+	case errors.Is(err, ErrCouponExpired):
+		return CartIssueCouponExpired
+	case errors.Is(err, ErrCouponMinNotMet):
+		return CartIssueCouponMinNotMet
+	}
+	return ""
+}
+
 // GetCartAnalytics retrieves analytics data for carts
 func (s *cartService) GetCartAnalytics(ctx context.Context) (*dto.CartAnalyticsResponse, error) {
 	analytics, err := s.cartRepo.GetCartAnalytics(ctx)
@@ -662,6 +1812,56 @@ func (s *cartService) GetCartAnalytics(ctx context.Context) (*dto.CartAnalyticsR
 
 // Wishlist Management
 
+// wishlistShareTokenBytes is the number of random bytes in a wishlist share token
+const wishlistShareTokenBytes = 16
+
+// shareWishlist issues a share token to a wishlist that has just been made public and
+// revokes the token of one that is private. Publishing again issues a new token, so a
+// link handed out before the wishlist was made private stays dead.
+func shareWishlist(wishlist *domain.Wishlist, wasPublic bool) error {
+	switch {
+	case !wishlist.IsPublic:
+		wishlist.ShareToken = nil
+	case !wasPublic || wishlist.ShareToken == nil:
+		token, err := randomHex(wishlistShareTokenBytes)
+		if err != nil {
+			return fmt.Errorf("failed to generate share token: %w", err)
+		}
+		wishlist.ShareToken = &token
+	}
+	return nil
+}
+
+// ownedWishlist loads a wishlist of the signed-in user. Wishlists of other users, and
+// every wishlist when no user is signed in, are reported as not found so their IDs cannot
+// be probed and their share tokens never leak.
+func (s *cartService) ownedWishlist(ctx context.Context, id int64) (*domain.Wishlist, error) {
+	wishlist, err := s.cartRepo.GetWishlistByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID, ok := userctx.UserID(ctx); !ok || wishlist.UserID != userID {
+		return nil, &repository.NotFoundError{Message: fmt.Sprintf("wishlist with ID %d not found", id), Kind: repository.ErrWishlistNotFound}
+	}
+	return wishlist, nil
+}
+
+// ownedWishlistItem loads an item on one of the signed-in user's wishlists, reporting
+// items on other users' wishlists as not found
+func (s *cartService) ownedWishlistItem(ctx context.Context, id int64) (*domain.WishlistItem, error) {
+	item, err := s.cartRepo.GetWishlistItemByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ownedWishlist(ctx, item.WishlistID); err != nil {
+		if errors.Is(err, repository.ErrWishlistNotFound) {
+			return nil, &repository.NotFoundError{Message: fmt.Sprintf("wishlist item with ID %d not found", id), Kind: repository.ErrWishlistItemNotFound}
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
 // CreateWishlist creates a new wishlist
 func (s *cartService) CreateWishlist(ctx context.Context, userID int64, req *dto.CreateWishlistRequest) (*domain.Wishlist, error) {
 	wishlist := &domain.Wishlist{
@@ -669,6 +1869,9 @@ func (s *cartService) CreateWishlist(ctx context.Context, userID int64, req *dto
 		Name:     req.Name,
 		IsPublic: req.IsPublic,
 	}
+	if err := shareWishlist(wishlist, false); err != nil {
+		return nil, err
+	}
 
 	err := s.cartRepo.CreateWishlist(ctx, wishlist)
 	if err != nil {
@@ -678,9 +1881,9 @@ func (s *cartService) CreateWishlist(ctx context.Context, userID int64, req *dto
 	return wishlist, nil
 }
 
-// GetWishlistByID retrieves a wishlist by ID
+// GetWishlistByID retrieves one of the signed-in user's wishlists by ID
 func (s *cartService) GetWishlistByID(ctx context.Context, id int64) (*domain.Wishlist, error) {
-	wishlist, err := s.cartRepo.GetWishlistByID(ctx, id)
+	wishlist, err := s.ownedWishlist(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wishlist: %w", err)
 	}
@@ -688,19 +1891,95 @@ func (s *cartService) GetWishlistByID(ctx context.Context, id int64) (*domain.Wi
 	return wishlist, nil
 }
 
-// GetWishlistsByUserID retrieves wishlists for a user
-func (s *cartService) GetWishlistsByUserID(ctx context.Context, userID int64, page, limit int) (*dto.ListWishlistsResponse, error) {
-	// Set default values
-	if page <= 0 {
-		page = 1
+// GetSharedWishlist retrieves the read-only view of a public wishlist by its share
+// token, with each item's current product name, SKU, price and image. Items whose
+// product or variant is no longer active are listed as unavailable.
+func (s *cartService) GetSharedWishlist(ctx context.Context, token string, page, limit int) (*dto.SharedWishlistResponse, error) {
+	wishlist, err := s.cartRepo.GetPublicWishlistByShareToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared wishlist: %w", err)
+	}
+
+	page, limit = httpx.ClampPagination(page, limit)
+
+	offset := (page - 1) * limit
+
+	items, total, err := s.cartRepo.GetWishlistItems(ctx, wishlist.ID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist items: %w", err)
+	}
+
+	productIDs := make([]int64, 0, len(items))
+	variantIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		}
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	variants, err := s.productRepo.GetProductVariantsByIDs(ctx, variantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+
+	images, err := s.productRepo.GetPrimaryImages(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
 	}
-	if limit <= 0 {
-		limit = 20
+
+	response := &dto.SharedWishlistResponse{
+		Name:       wishlist.Name,
+		Items:      make([]dto.SharedWishlistItemResponse, len(items)),
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		UpdatedAt:  httpx.FormatTime(wishlist.UpdatedAt),
 	}
-	if limit > 100 {
-		limit = 100
+
+	for i, item := range items {
+		response.Items[i] = dto.SharedWishlistItemResponse{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Notes:            item.Notes,
+		}
+
+		product, ok := products[item.ProductID]
+		if !ok || !product.IsActive {
+			continue
+		}
+
+		sku, price := product.SKU, product.Price
+		if item.ProductVariantID != nil {
+			variant, ok := variants[*item.ProductVariantID]
+			if !ok || !variant.IsActive {
+				continue
+			}
+			sku, price = variant.SKU, variant.Price
+		}
+
+		response.Items[i].ProductName = product.Name
+		response.Items[i].SKU = sku
+		response.Items[i].Price = price
+		response.Items[i].Available = true
+		if image, ok := images[item.ProductID]; ok {
+			response.Items[i].ProductImage = &image.URL
+		}
 	}
 
+	return response, nil
+}
+
+// GetWishlistsByUserID retrieves wishlists for a user
+func (s *cartService) GetWishlistsByUserID(ctx context.Context, userID int64, page, limit int) (*dto.ListWishlistsResponse, error) {
+	page, limit = httpx.ClampPagination(page, limit)
+
 	offset := (page - 1) * limit
 
 	wishlists, total, err := s.cartRepo.GetWishlistsByUserID(ctx, userID, offset, limit)
@@ -712,12 +1991,13 @@ func (s *cartService) GetWishlistsByUserID(ctx context.Context, userID int64, pa
 	wishlistResponses := make([]dto.WishlistResponse, len(wishlists))
 	for i, wishlist := range wishlists {
 		wishlistResponses[i] = dto.WishlistResponse{
-			ID:        wishlist.ID,
-			UserID:    wishlist.UserID,
-			Name:      wishlist.Name,
-			IsPublic:  wishlist.IsPublic,
-			CreatedAt: wishlist.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: wishlist.UpdatedAt.Format(time.RFC3339),
+			ID:         wishlist.ID,
+			UserID:     wishlist.UserID,
+			Name:       wishlist.Name,
+			IsPublic:   wishlist.IsPublic,
+			ShareToken: wishlist.ShareToken,
+			CreatedAt:  httpx.FormatTime(wishlist.CreatedAt),
+			UpdatedAt:  httpx.FormatTime(wishlist.UpdatedAt),
 		}
 	}
 
@@ -733,10 +2013,10 @@ func (s *cartService) GetWishlistsByUserID(ctx context.Context, userID int64, pa
 	}, nil
 }
 
-// UpdateWishlist updates an existing wishlist
+// UpdateWishlist updates one of the signed-in user's wishlists
 func (s *cartService) UpdateWishlist(ctx context.Context, id int64, req *dto.UpdateWishlistRequest) (*domain.Wishlist, error) {
 	// Get existing wishlist
-	existingWishlist, err := s.cartRepo.GetWishlistByID(ctx, id)
+	existingWishlist, err := s.ownedWishlist(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing wishlist: %w", err)
 	}
@@ -750,6 +2030,9 @@ func (s *cartService) UpdateWishlist(ctx context.Context, id int64, req *dto.Upd
 	if req.IsPublic != nil {
 		updateWishlist.IsPublic = *req.IsPublic
 	}
+	if err := shareWishlist(&updateWishlist, existingWishlist.IsPublic); err != nil {
+		return nil, err
+	}
 
 	updateWishlist.UpdatedAt = time.Now()
 
@@ -762,10 +2045,10 @@ func (s *cartService) UpdateWishlist(ctx context.Context, id int64, req *dto.Upd
 	return &updateWishlist, nil
 }
 
-// DeleteWishlist deletes a wishlist
+// DeleteWishlist deletes one of the signed-in user's wishlists
 func (s *cartService) DeleteWishlist(ctx context.Context, id int64) error {
 	// Check if wishlist exists
-	_, err := s.cartRepo.GetWishlistByID(ctx, id)
+	_, err := s.ownedWishlist(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get wishlist: %w", err)
 	}
@@ -780,26 +2063,17 @@ func (s *cartService) DeleteWishlist(ctx context.Context, id int64) error {
 
 // Wishlist Items
 
-// AddItemToWishlist adds an item to a wishlist
+// AddItemToWishlist adds an item to one of the signed-in user's wishlists
 func (s *cartService) AddItemToWishlist(ctx context.Context, wishlistID int64, req *dto.AddToWishlistRequest) (*domain.WishlistItem, error) {
 	// Check if wishlist exists
-	_, err := s.cartRepo.GetWishlistByID(ctx, wishlistID)
+	_, err := s.ownedWishlist(ctx, wishlistID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wishlist: %w", err)
 	}
 
-	// Check if product exists
-	_, err = s.productRepo.GetProductByID(ctx, req.ProductID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
-	}
-
-	// Check if variant exists (if provided)
-	if req.ProductVariantID != nil {
-		_, err = s.productRepo.GetProductVariantByID(ctx, *req.ProductVariantID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get product variant: %w", err)
-		}
+	// Check the product and variant (if provided) exist and are active
+	if _, err := s.getAvailableProductPrice(ctx, req.ProductID, req.ProductVariantID); err != nil {
+		return nil, err
 	}
 
 	wishlistItem := &domain.WishlistItem{
@@ -817,9 +2091,9 @@ func (s *cartService) AddItemToWishlist(ctx context.Context, wishlistID int64, r
 	return wishlistItem, nil
 }
 
-// GetWishlistItemByID retrieves a wishlist item by ID
+// GetWishlistItemByID retrieves an item on one of the signed-in user's wishlists by ID
 func (s *cartService) GetWishlistItemByID(ctx context.Context, id int64) (*domain.WishlistItem, error) {
-	item, err := s.cartRepo.GetWishlistItemByID(ctx, id)
+	item, err := s.ownedWishlistItem(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
 	}
@@ -827,19 +2101,14 @@ func (s *cartService) GetWishlistItemByID(ctx context.Context, id int64) (*domai
 	return item, nil
 }
 
-// GetWishlistItems retrieves items in a wishlist
+// GetWishlistItems retrieves items in one of the signed-in user's wishlists
 func (s *cartService) GetWishlistItems(ctx context.Context, wishlistID int64, page, limit int) (*dto.ListWishlistItemsResponse, error) {
-	// Set default values
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
+	if _, err := s.ownedWishlist(ctx, wishlistID); err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
 	}
 
+	page, limit = httpx.ClampPagination(page, limit)
+
 	offset := (page - 1) * limit
 
 	items, total, err := s.cartRepo.GetWishlistItems(ctx, wishlistID, offset, limit)
@@ -856,7 +2125,7 @@ func (s *cartService) GetWishlistItems(ctx context.Context, wishlistID int64, pa
 			ProductID:        item.ProductID,
 			ProductVariantID: item.ProductVariantID,
 			Notes:            item.Notes,
-			CreatedAt:        item.CreatedAt.Format(time.RFC3339),
+			CreatedAt:        httpx.FormatTime(item.CreatedAt),
 		}
 	}
 
@@ -872,10 +2141,10 @@ func (s *cartService) GetWishlistItems(ctx context.Context, wishlistID int64, pa
 	}, nil
 }
 
-// UpdateWishlistItem updates an existing wishlist item
+// UpdateWishlistItem updates an item on one of the signed-in user's wishlists
 func (s *cartService) UpdateWishlistItem(ctx context.Context, id int64, req *dto.UpdateWishlistItemRequest) (*domain.WishlistItem, error) {
 	// Get existing item
-	existingItem, err := s.cartRepo.GetWishlistItemByID(ctx, id)
+	existingItem, err := s.ownedWishlistItem(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing wishlist item: %w", err)
 	}
@@ -896,10 +2165,10 @@ func (s *cartService) UpdateWishlistItem(ctx context.Context, id int64, req *dto
 	return &updateItem, nil
 }
 
-// DeleteWishlistItem deletes a wishlist item
+// DeleteWishlistItem deletes an item from one of the signed-in user's wishlists
 func (s *cartService) DeleteWishlistItem(ctx context.Context, id int64) error {
 	// Check if item exists
-	_, err := s.cartRepo.GetWishlistItemByID(ctx, id)
+	_, err := s.ownedWishlistItem(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get wishlist item: %w", err)
 	}
@@ -912,21 +2181,39 @@ func (s *cartService) DeleteWishlistItem(ctx context.Context, id int64) error {
 	return nil
 }
 
-// MoveItemToCart moves an item from wishlist to cart
+// MoveItemToCart moves an item from one of the signed-in user's wishlists to a cart
 func (s *cartService) MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64) error {
 	// Check if wishlist item exists
-	_, err := s.cartRepo.GetWishlistItemByID(ctx, wishlistItemID)
+	wishlistItem, err := s.ownedWishlistItem(ctx, wishlistItemID)
 	if err != nil {
 		return fmt.Errorf("failed to get wishlist item: %w", err)
 	}
 
 	// Check if cart exists
-	_, err = s.cartRepo.GetCartByID(ctx, cartID)
+	cart, err := s.cartRepo.GetCartByID(ctx, cartID)
 	if err != nil {
 		return fmt.Errorf("failed to get cart: %w", err)
 	}
+	if err := s.checkCatalogCurrency(cart); err != nil {
+		return err
+	}
+
+	unitPrice, err := s.getPurchasablePrice(ctx, wishlistItem.ProductID, wishlistItem.ProductVariantID)
+	if err != nil {
+		return err
+	}
+
+	// Moved items add one unit, on top of any the cart already holds
+	inCart := 0
+	if existingItem, err := s.cartRepo.GetCartItemByProduct(ctx, cartID, wishlistItem.ProductID, wishlistItem.ProductVariantID); err == nil {
+		inCart = existingItem.Quantity
+	}
+	moved := &dto.AddToCartRequest{ProductID: wishlistItem.ProductID, ProductVariantID: wishlistItem.ProductVariantID, Quantity: 1}
+	if _, err := s.addableQuantity(ctx, moved, inCart); err != nil {
+		return err
+	}
 
-	err = s.cartRepo.MoveItemToCart(ctx, wishlistItemID, cartID)
+	err = s.cartRepo.MoveItemToCart(ctx, wishlistItemID, cartID, unitPrice)
 	if err != nil {
 		return fmt.Errorf("failed to move item to cart: %w", err)
 	}
@@ -0,0 +1,1756 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCartRepository is a mock implementation of CartRepository.
+// Methods not overridden here fall through to the embedded nil interface.
+type MockCartRepository struct {
+	mock.Mock
+	repository.CartRepository
+}
+
+func (m *MockCartRepository) GetCartByID(ctx context.Context, id int64) (*domain.Cart, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) UpdateCart(ctx context.Context, cart *domain.Cart) error {
+	args := m.Called(ctx, cart)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) GetCartItemByID(ctx context.Context, id int64) (*domain.CartItem, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) GetCartItemByProduct(ctx context.Context, cartID, productID int64, variantID *int64) (*domain.CartItem, error) {
+	args := m.Called(ctx, cartID, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) AddItemToCart(ctx context.Context, item *domain.CartItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) UpdateCartItem(ctx context.Context, id int64, item *domain.CartItem) error {
+	args := m.Called(ctx, id, item)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) DeleteCartItemAtVersion(ctx context.Context, id int64, version int) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) GetCartItemDetails(ctx context.Context, cartID int64) ([]*domain.CartItemDetail, error) {
+	args := m.Called(ctx, cartID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CartItemDetail), args.Error(1)
+}
+
+func (m *MockCartRepository) GetWishlistItemByID(ctx context.Context, id int64) (*domain.WishlistItem, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockCartRepository) MoveItemToCart(ctx context.Context, wishlistItemID, cartID int64, unitPrice float64) error {
+	args := m.Called(ctx, wishlistItemID, cartID, unitPrice)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) ListActiveCartIDs(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockCartRepository) DeduplicateCartItems(ctx context.Context, cartID int64) (int, int, error) {
+	args := m.Called(ctx, cartID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockCartRepository) RecalculateCartItemPrices(ctx context.Context, cartIDs []int64) (int, int, error) {
+	args := m.Called(ctx, cartIDs)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockCartRepository) GetCartItems(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartItem, int64, error) {
+	args := m.Called(ctx, cartID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.CartItem), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCartRepository) GetCartItemCount(ctx context.Context, cartID int64) (int, error) {
+	args := m.Called(ctx, cartID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCartRepository) MergeCarts(ctx context.Context, sourceCartID, targetCartID int64) error {
+	args := m.Called(ctx, sourceCartID, targetCartID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) GetCartSummary(ctx context.Context, cartID int64) (*domain.CartSummary, error) {
+	args := m.Called(ctx, cartID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartSummary), args.Error(1)
+}
+
+func (m *MockCartRepository) GetCartCouponByCode(ctx context.Context, cartID int64, couponCode string) (*domain.CartCoupon, error) {
+	args := m.Called(ctx, cartID, couponCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartCoupon), args.Error(1)
+}
+
+func (m *MockCartRepository) ApplyCouponToCart(ctx context.Context, cartCoupon *domain.CartCoupon) error {
+	args := m.Called(ctx, cartCoupon)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveCouponFromCart(ctx context.Context, cartID int64, couponCode string) error {
+	args := m.Called(ctx, cartID, couponCode)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) GetCartCoupons(ctx context.Context, cartID int64, offset, limit int) ([]*domain.CartCoupon, int64, error) {
+	args := m.Called(ctx, cartID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.CartCoupon), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCartRepository) GetCartShipping(ctx context.Context, cartID int64) (*domain.CartShipping, error) {
+	args := m.Called(ctx, cartID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartShipping), args.Error(1)
+}
+
+func (m *MockCartRepository) SetCartShipping(ctx context.Context, shipping *domain.CartShipping) error {
+	args := m.Called(ctx, shipping)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) UpdateCartShipping(ctx context.Context, cartID int64, shipping *domain.CartShipping) error {
+	args := m.Called(ctx, cartID, shipping)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) GetWishlistByID(ctx context.Context, id int64) (*domain.Wishlist, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wishlist), args.Error(1)
+}
+
+func (m *MockCartRepository) AddItemToWishlist(ctx context.Context, item *domain.WishlistItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+// MockCouponRepository is a mock implementation of CouponRepository
+type MockCouponRepository struct {
+	mock.Mock
+	repository.CouponRepository
+}
+
+func (m *MockCouponRepository) GetCouponByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) CountCouponRedemptions(ctx context.Context, couponID int64, userID *int64) (int64, int64, error) {
+	args := m.Called(ctx, couponID, userID)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCouponRepository) RedeemCoupon(ctx context.Context, redemption *domain.CouponRedemption) error {
+	args := m.Called(ctx, redemption)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) ReleaseCouponRedemption(ctx context.Context, couponID, cartID int64) error {
+	args := m.Called(ctx, couponID, cartID)
+	return args.Error(0)
+}
+
+func TestCartService_AddItemToCart_VariantStock(t *testing.T) {
+	ctx := context.Background()
+	smallID, largeID := int64(11), int64(12)
+
+	setup := func() (*MockCartRepository, *MockProductRepository, *MockInventoryRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(5), mock.Anything).Return(nil, sql.ErrNoRows)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 9, IsActive: true}, nil)
+		productRepo.On("GetProductVariantByID", ctx, smallID).Return(&domain.ProductVariant{ID: smallID, Price: 10, IsActive: true}, nil)
+		productRepo.On("GetProductVariantByID", ctx, largeID).Return(&domain.ProductVariant{ID: largeID, Price: 12, IsActive: true}, nil)
+
+		// Same product, two variants with very different stock
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), &smallID).Return(&domain.Inventory{ProductID: 5, ProductVariantID: &smallID, AvailableQuantity: 1}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), &largeID).Return(&domain.Inventory{ProductID: 5, ProductVariantID: &largeID, AvailableQuantity: 50}, nil)
+
+		return cartRepo, productRepo, inventoryRepo, NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+
+	t.Run("variant with stock is accepted", func(t *testing.T) {
+		cartRepo, _, inventoryRepo, service := setup()
+		cartRepo.On("AddItemToCart", ctx, mock.AnythingOfType("*domain.CartItem")).Return(nil)
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, ProductVariantID: &largeID, Quantity: 5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 60.0, item.TotalPrice)
+		inventoryRepo.AssertCalled(t, "GetInventoryByProduct", ctx, int64(5), &largeID)
+		inventoryRepo.AssertNotCalled(t, "GetInventoryByProduct", ctx, int64(5), &smallID)
+	})
+
+	t.Run("variant without stock is rejected", func(t *testing.T) {
+		cartRepo, _, inventoryRepo, service := setup()
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, ProductVariantID: &smallID, Quantity: 5})
+
+		assert.Nil(t, item)
+		var stockErr *InsufficientStockError
+		assert.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, &smallID, stockErr.ProductVariantID)
+		assert.Equal(t, 1, stockErr.Available)
+		inventoryRepo.AssertNotCalled(t, "GetInventoryByProduct", ctx, int64(5), &largeID)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("combined quantity is checked for existing line", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 9, IsActive: true}, nil)
+		productRepo.On("GetProductVariantByID", ctx, largeID).Return(&domain.ProductVariant{ID: largeID, Price: 12, IsActive: true}, nil)
+		cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(5), &largeID).Return(&domain.CartItem{ID: 3, ProductID: 5, ProductVariantID: &largeID, Quantity: 48}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), &largeID).Return(&domain.Inventory{AvailableQuantity: 50}, nil)
+
+		_, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, ProductVariantID: &largeID, Quantity: 3})
+
+		var stockErr *InsufficientStockError
+		assert.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, 51, stockErr.Requested)
+		assert.Equal(t, 2, stockErr.MaxAddable)
+		cartRepo.AssertNotCalled(t, "UpdateCartItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("untracked item is accepted", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		productRepo.On("GetProductByID", ctx, int64(7)).Return(&domain.Product{ID: 7, Price: 3, IsActive: true}, nil)
+		cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(7), (*int64)(nil)).Return(nil, sql.ErrNoRows)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(7), (*int64)(nil)).Return(nil, repository.ErrInventoryNotFound)
+		cartRepo.On("AddItemToCart", ctx, mock.AnythingOfType("*domain.CartItem")).Return(nil)
+
+		_, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 7, Quantity: 2})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestCartService_AddItemToCart_StockLimit(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(product *domain.Product, inventory *domain.Inventory, inventoryErr error) (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(5), (*int64)(nil)).Return(nil, sql.ErrNoRows)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(product, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), (*int64)(nil)).Return(inventory, inventoryErr)
+
+		return cartRepo, NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+	tracked := &domain.Product{ID: 5, Price: 4, IsActive: true, TrackQuantity: true}
+
+	t.Run("more than available is rejected with the addable quantity", func(t *testing.T) {
+		cartRepo, service := setup(tracked, &domain.Inventory{ProductID: 5, AvailableQuantity: 3}, nil)
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 5})
+
+		assert.Nil(t, item)
+		var stockErr *InsufficientStockError
+		require.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, 5, stockErr.Requested)
+		assert.Equal(t, 3, stockErr.Available)
+		assert.Equal(t, 3, stockErr.MaxAddable)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("clamping adds what is available", func(t *testing.T) {
+		cartRepo, service := setup(tracked, &domain.Inventory{ProductID: 5, AvailableQuantity: 3}, nil)
+		cartRepo.On("AddItemToCart", ctx, mock.AnythingOfType("*domain.CartItem")).Return(nil)
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 5, ClampToStock: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, item.Quantity)
+		assert.Equal(t, 12.0, item.TotalPrice)
+	})
+
+	t.Run("clamping still rejects when nothing is available", func(t *testing.T) {
+		cartRepo, service := setup(tracked, &domain.Inventory{ProductID: 5, AvailableQuantity: 0}, nil)
+
+		_, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 5, ClampToStock: true})
+
+		var stockErr *InsufficientStockError
+		require.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, 0, stockErr.MaxAddable)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("tracked product without a stock record is rejected", func(t *testing.T) {
+		_, service := setup(tracked, nil, repository.ErrInventoryNotFound)
+
+		_, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 1})
+
+		var stockErr *InsufficientStockError
+		require.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, 0, stockErr.Available)
+	})
+}
+
+func TestCartService_MoveItemToCart(t *testing.T) {
+	ctx := userctx.WithUserID(context.Background(), 7)
+	variantID := int64(11)
+
+	setup := func(inCart *domain.CartItem, available int) (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+
+		cartRepo.On("GetWishlistItemByID", ctx, int64(4)).Return(&domain.WishlistItem{ID: 4, WishlistID: 2, ProductID: 5, ProductVariantID: &variantID}, nil)
+		cartRepo.On("GetWishlistByID", ctx, int64(2)).Return(&domain.Wishlist{ID: 2, UserID: 7}, nil)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		if inCart != nil {
+			cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(5), &variantID).Return(inCart, nil)
+		} else {
+			cartRepo.On("GetCartItemByProduct", ctx, int64(1), int64(5), &variantID).Return(nil, sql.ErrNoRows)
+		}
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 20, IsActive: true}, nil)
+		productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, Price: 24.5, IsActive: true}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), &variantID).Return(&domain.Inventory{AvailableQuantity: available}, nil)
+
+		return cartRepo, NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+
+	t.Run("moved item carries the current variant price", func(t *testing.T) {
+		cartRepo, service := setup(nil, 5)
+		cartRepo.On("MoveItemToCart", ctx, int64(4), int64(1), 24.5).Return(nil)
+
+		err := service.MoveItemToCart(ctx, 4, 1)
+
+		require.NoError(t, err)
+		cartRepo.AssertCalled(t, "MoveItemToCart", ctx, int64(4), int64(1), 24.5)
+	})
+
+	t.Run("stock covers the unit already in the cart", func(t *testing.T) {
+		cartRepo, service := setup(&domain.CartItem{ID: 3, Quantity: 2}, 2)
+
+		err := service.MoveItemToCart(ctx, 4, 1)
+
+		var stockErr *InsufficientStockError
+		require.True(t, errors.As(err, &stockErr))
+		assert.Equal(t, 3, stockErr.Requested)
+		assert.Equal(t, 0, stockErr.MaxAddable)
+		cartRepo.AssertNotCalled(t, "MoveItemToCart", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("items on another user's wishlist are not found", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+		cartRepo.On("GetWishlistItemByID", ctx, int64(4)).Return(&domain.WishlistItem{ID: 4, WishlistID: 3, ProductID: 5}, nil)
+		cartRepo.On("GetWishlistByID", ctx, int64(3)).Return(&domain.Wishlist{ID: 3, UserID: 8}, nil)
+
+		err := service.MoveItemToCart(ctx, 4, 1)
+
+		assert.ErrorIs(t, err, repository.ErrWishlistItemNotFound)
+		cartRepo.AssertNotCalled(t, "GetCartByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_UpdateCartItem_VariantStock(t *testing.T) {
+	ctx := context.Background()
+	variantID := int64(11)
+	quantity := 4
+
+	cartRepo := new(MockCartRepository)
+	productRepo := new(MockProductRepository)
+	inventoryRepo := new(MockInventoryRepository)
+	service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, ProductVariantID: &variantID, Quantity: 1}, nil)
+	productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, Price: 10}, nil)
+	inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), &variantID).Return(&domain.Inventory{AvailableQuantity: 2}, nil)
+
+	item, err := service.UpdateCartItem(ctx, 3, &dto.UpdateCartItemRequest{Quantity: &quantity})
+
+	assert.Nil(t, item)
+	var stockErr *InsufficientStockError
+	assert.True(t, errors.As(err, &stockErr))
+	inventoryRepo.AssertExpectations(t)
+	cartRepo.AssertNotCalled(t, "UpdateCartItem", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCartService_AdjustCartItem(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*cartService, *MockCartRepository, *MockProductRepository, *MockInventoryRepository) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+		return service.(*cartService), cartRepo, productRepo, inventoryRepo
+	}
+
+	t.Run("decrement to zero removes the item", func(t *testing.T) {
+		service, cartRepo, _, _ := newService()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 2, Version: 4}, nil)
+		cartRepo.On("DeleteCartItemAtVersion", ctx, int64(3), 4).Return(nil)
+
+		item, err := service.DecrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{Amount: 2})
+
+		require.NoError(t, err)
+		assert.Nil(t, item)
+		cartRepo.AssertExpectations(t)
+		cartRepo.AssertNotCalled(t, "UpdateCartItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("decrement by one", func(t *testing.T) {
+		service, cartRepo, productRepo, _ := newService()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 3, UnitPrice: 10}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 12, IsActive: true}, nil)
+		cartRepo.On("UpdateCartItem", ctx, int64(3), mock.Anything).Return(nil)
+
+		item, err := service.DecrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, item.Quantity)
+		assert.Equal(t, 24.0, item.TotalPrice)
+	})
+
+	t.Run("decrement retries after a concurrent change", func(t *testing.T) {
+		service, cartRepo, _, _ := newService()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 1, Version: 1}, nil).Once()
+		cartRepo.On("DeleteCartItemAtVersion", ctx, int64(3), 1).Return(repository.ErrStaleCartItem).Once()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 1, Version: 2}, nil).Once()
+		cartRepo.On("DeleteCartItemAtVersion", ctx, int64(3), 2).Return(nil).Once()
+
+		item, err := service.DecrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{})
+
+		require.NoError(t, err)
+		assert.Nil(t, item)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("increment beyond stock is rejected", func(t *testing.T) {
+		service, cartRepo, productRepo, inventoryRepo := newService()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 2}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 10, IsActive: true}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 3}, nil)
+
+		item, err := service.IncrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{Amount: 2})
+
+		assert.Nil(t, item)
+		var stockErr *InsufficientStockError
+		require.ErrorAs(t, err, &stockErr)
+		assert.Equal(t, 1, stockErr.MaxAddable)
+		cartRepo.AssertNotCalled(t, "UpdateCartItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("increment beyond stock is clamped", func(t *testing.T) {
+		service, cartRepo, productRepo, inventoryRepo := newService()
+		cartRepo.On("GetCartItemByID", ctx, int64(3)).Return(&domain.CartItem{ID: 3, ProductID: 5, Quantity: 2}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 10, IsActive: true}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 3}, nil)
+		cartRepo.On("UpdateCartItem", ctx, int64(3), mock.Anything).Return(nil)
+
+		item, err := service.IncrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{Amount: 5, ClampToStock: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, item.Quantity)
+		assert.Equal(t, 30.0, item.TotalPrice)
+	})
+
+	t.Run("negative amount is rejected", func(t *testing.T) {
+		service, cartRepo, _, _ := newService()
+
+		_, err := service.IncrementCartItem(ctx, 3, &dto.AdjustCartItemQuantityRequest{Amount: -1})
+
+		var quantityErr *QuantityError
+		assert.ErrorAs(t, err, &quantityErr)
+		cartRepo.AssertNotCalled(t, "GetCartItemByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_RecalculateAllActiveCarts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("walks carts in batches", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		service := &cartService{cartRepo: cartRepo, batchSize: 2}
+
+		// Three active carts with a batch size of two take two batches
+		cartRepo.On("ListActiveCartIDs", ctx, int64(0), 2).Return([]int64{1, 2}, nil)
+		cartRepo.On("RecalculateCartItemPrices", ctx, []int64{1, 2}).Return(1, 3, nil)
+		cartRepo.On("ListActiveCartIDs", ctx, int64(2), 2).Return([]int64{5}, nil)
+		cartRepo.On("RecalculateCartItemPrices", ctx, []int64{5}).Return(1, 1, nil)
+
+		result, err := service.RecalculateAllActiveCarts(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.RecalculateCartsResponse{CartsScanned: 3, CartsUpdated: 2, ItemsChanged: 4}, result)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops on an empty batch", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		service := &cartService{cartRepo: cartRepo, batchSize: 2}
+
+		cartRepo.On("ListActiveCartIDs", ctx, int64(0), 2).Return([]int64{1, 2}, nil)
+		cartRepo.On("RecalculateCartItemPrices", ctx, []int64{1, 2}).Return(0, 0, nil)
+		cartRepo.On("ListActiveCartIDs", ctx, int64(2), 2).Return([]int64{}, nil)
+
+		result, err := service.RecalculateAllActiveCarts(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.CartsScanned)
+		assert.Zero(t, result.ItemsChanged)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("batch failure", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		service := &cartService{cartRepo: cartRepo, batchSize: 2}
+
+		cartRepo.On("ListActiveCartIDs", ctx, int64(0), 2).Return([]int64{1}, nil)
+		cartRepo.On("RecalculateCartItemPrices", ctx, []int64{1}).Return(0, 0, errors.New("db down"))
+
+		result, err := service.RecalculateAllActiveCarts(ctx)
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+}
+
+func TestCartService_InactiveProducts(t *testing.T) {
+	ctx := context.Background()
+	variantID := int64(11)
+
+	t.Run("inactive product cannot be added to cart", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 9, IsActive: false}, nil)
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 1})
+
+		assert.Nil(t, item)
+		var unavailableErr *ProductUnavailableError
+		assert.True(t, errors.As(err, &unavailableErr))
+		assert.Equal(t, int64(5), unavailableErr.ProductID)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("inactive variant cannot be added to cart", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, IsActive: true}, nil)
+		productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, IsActive: false}, nil)
+
+		_, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, ProductVariantID: &variantID, Quantity: 1})
+
+		var unavailableErr *ProductUnavailableError
+		assert.True(t, errors.As(err, &unavailableErr))
+		assert.Equal(t, &variantID, unavailableErr.ProductVariantID)
+	})
+
+	t.Run("inactive product cannot be added to wishlist", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		ctx := userctx.WithUserID(ctx, 7)
+		cartRepo.On("GetWishlistByID", ctx, int64(2)).Return(&domain.Wishlist{ID: 2, UserID: 7}, nil)
+		productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, IsActive: false}, nil)
+
+		item, err := service.AddItemToWishlist(ctx, 2, &dto.AddToWishlistRequest{ProductID: 5})
+
+		assert.Nil(t, item)
+		var unavailableErr *ProductUnavailableError
+		assert.True(t, errors.As(err, &unavailableErr))
+		cartRepo.AssertNotCalled(t, "AddItemToWishlist", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_ValidateCartForCheckout(t *testing.T) {
+	ctx := context.Background()
+	variantID := int64(21)
+
+	t.Run("flags items that became unavailable", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		items := []*domain.CartItem{
+			{ID: 1, ProductID: 10, Quantity: 1},
+			{ID: 2, ProductID: 20, Quantity: 1},
+			{ID: 3, ProductID: 30, Quantity: 1},
+			{ID: 4, ProductID: 40, ProductVariantID: &variantID, Quantity: 1},
+		}
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return(items, int64(4), nil)
+		// Product 30 has been deleted
+		productRepo.On("GetProductsByIDs", ctx, []int64{10, 20, 30, 40}).Return(map[int64]*domain.Product{
+			10: {ID: 10, IsActive: true},
+			20: {ID: 20, IsActive: false},
+			40: {ID: 40, IsActive: true},
+		}, nil)
+		productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, IsActive: false}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(10), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 5}, nil)
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{CartID: 1, Currency: "USD", Subtotal: 40}, nil)
+
+		result, err := service.ValidateCartForCheckout(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []dto.CartItemIssue{
+			{CartItemID: 2, ProductID: 20, Reason: CartIssueProductInactive},
+			{CartItemID: 3, ProductID: 30, Reason: CartIssueProductNotFound},
+			{CartItemID: 4, ProductID: 40, ProductVariantID: &variantID, Reason: CartIssueVariantInactive},
+		}, result.Issues)
+	})
+
+	t.Run("valid cart has no issues", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return([]*domain.CartItem{{ID: 1, ProductID: 10, Quantity: 2}}, int64(1), nil)
+		productRepo.On("GetProductsByIDs", ctx, []int64{10}).Return(map[int64]*domain.Product{10: {ID: 10, IsActive: true}}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(10), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 2}, nil)
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+			CartID:   1,
+			Currency: "USD",
+			Subtotal: 20,
+			Coupons:  []domain.Coupon{{Code: "SAVE5", Type: "fixed_amount", Value: 5, MinOrderAmount: 20, IsActive: true}},
+		}, nil)
+
+		result, err := service.ValidateCartForCheckout(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Issues)
+		assert.Empty(t, result.CouponIssues)
+	})
+
+	t.Run("flags coupons that no longer apply", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		expired := time.Now().Add(-time.Hour)
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return([]*domain.CartItem{{ID: 1, ProductID: 10, Quantity: 1}}, int64(1), nil)
+		productRepo.On("GetProductsByIDs", ctx, []int64{10}).Return(map[int64]*domain.Product{10: {ID: 10, IsActive: true}}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(10), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 1}, nil)
+		// Items were removed after BIGSPEND was applied, and SUMMER has since ended
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+			CartID:   1,
+			Currency: "USD",
+			Subtotal: 40,
+			Coupons: []domain.Coupon{
+				{Code: "BIGSPEND", Type: "fixed_amount", Value: 20, MinOrderAmount: 100, IsActive: true},
+				{Code: "SUMMER", Type: "percentage", Value: 10, IsActive: true, ExpiresAt: &expired},
+				{Code: "RETIRED", Type: "percentage", Value: 10},
+				{Code: "SAVE5", Type: "fixed_amount", Value: 5, IsActive: true},
+			},
+		}, nil)
+
+		result, err := service.ValidateCartForCheckout(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Empty(t, result.Issues)
+		assert.Equal(t, []dto.CartCouponIssue{
+			{CouponCode: "BIGSPEND", Reason: CartIssueCouponMinNotMet},
+			{CouponCode: "SUMMER", Reason: CartIssueCouponExpired},
+			{CouponCode: "RETIRED", Reason: CartIssueCouponInactive},
+		}, result.CouponIssues)
+	})
+}
+
+func TestCartPricingPolicy_Apply(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    CartPricingPolicy
+		summary   domain.CartSummary
+		wantTax   float64
+		wantTotal float64
+	}{
+		{
+			// 0.1+0.1+0.1 and 10% of it are not exact in binary floating point
+			name:      "three items at 0.10 with 10% tax",
+			policy:    CartPricingPolicy{TaxRate: 0.1},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 0.1 + 0.1 + 0.1, TaxableSubtotal: 0.1 + 0.1 + 0.1},
+			wantTax:   0.03,
+			wantTotal: 0.33,
+		},
+		{
+			name:      "half a cent of tax rounds up",
+			policy:    CartPricingPolicy{TaxRate: 0.1},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 0.05, TaxableSubtotal: 0.05},
+			wantTax:   0.01,
+			wantTotal: 0.06,
+		},
+		{
+			name:      "discount after tax",
+			policy:    CartPricingPolicy{TaxRate: 0.1},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 100, TaxableSubtotal: 100, DiscountAmount: 20, ShippingAmount: 5},
+			wantTax:   10,
+			wantTotal: 95,
+		},
+		{
+			name:      "discount before tax",
+			policy:    CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 100, TaxableSubtotal: 100, DiscountAmount: 20, ShippingAmount: 5},
+			wantTax:   8,
+			wantTotal: 93,
+		},
+		{
+			// Only the taxable half of the cart carries its share of the discount
+			name:      "discount before tax on a partly taxable cart",
+			policy:    CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 300, TaxableSubtotal: 200, DiscountAmount: 30},
+			wantTax:   18,
+			wantTotal: 288,
+		},
+		{
+			name:      "discount larger than subtotal",
+			policy:    CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 10, TaxableSubtotal: 10, DiscountAmount: 15, ShippingAmount: 4.99},
+			wantTax:   0,
+			wantTotal: 4.99,
+		},
+		{
+			name:      "tax exempt cart",
+			policy:    CartPricingPolicy{TaxRate: 0.1},
+			summary:   domain.CartSummary{Currency: "USD", Subtotal: 300, TaxableSubtotal: 200, TaxExempt: true},
+			wantTax:   0,
+			wantTotal: 300,
+		},
+		{
+			name:      "zero decimal currency",
+			policy:    CartPricingPolicy{TaxRate: 0.1},
+			summary:   domain.CartSummary{Currency: "JPY", Subtotal: 1005, TaxableSubtotal: 1005},
+			wantTax:   101,
+			wantTotal: 1106,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := tt.summary
+			tt.policy.Apply(&summary)
+
+			assert.Equal(t, tt.wantTax, summary.TaxAmount)
+			assert.Equal(t, tt.wantTotal, summary.TotalAmount)
+		})
+	}
+}
+
+func TestCartService_ValidateCartForCheckout_MinimumOrder(t *testing.T) {
+	ctx := context.Background()
+	pricing := CartPricingPolicy{MinimumOrderAmounts: map[string]float64{"USD": 25}}
+
+	tests := []struct {
+		name      string
+		subtotal  float64
+		valid     bool
+		shortfall float64
+	}{
+		{name: "just below the minimum", subtotal: 24.5, shortfall: 0.5},
+		{name: "at the minimum", subtotal: 25, valid: true},
+		{name: "just above the minimum", subtotal: 25.5, valid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := new(MockCartRepository)
+			productRepo := new(MockProductRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+			cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return([]*domain.CartItem{{ID: 1, ProductID: 10, Quantity: 1}}, int64(1), nil)
+			cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{CartID: 1, Currency: "USD", Subtotal: tt.subtotal}, nil)
+			productRepo.On("GetProductsByIDs", ctx, []int64{10}).Return(map[int64]*domain.Product{10: {ID: 10, IsActive: true}}, nil)
+			inventoryRepo.On("GetInventoryByProduct", ctx, int64(10), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 1}, nil)
+
+			result, err := service.ValidateCartForCheckout(ctx, 1)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.valid, result.Valid)
+			assert.Empty(t, result.Issues)
+			assert.Equal(t, tt.shortfall, result.MinimumOrderShortfall)
+			if tt.shortfall > 0 {
+				assert.Equal(t, 25.0, result.MinimumOrderAmount)
+			}
+		})
+	}
+}
+
+func TestCartService_GetCartSummary_AppliesPricingPolicy(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := new(MockCartRepository)
+	service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{TaxRate: 0.1, DiscountBeforeTax: true}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+		CartID:          1,
+		Currency:        "USD",
+		Subtotal:        300,
+		TaxableSubtotal: 200,
+		ShippingAmount:  10,
+		Coupons:         []domain.Coupon{{Code: "SAVE30", Type: "fixed_amount", Value: 30, IsActive: true}},
+	}, nil)
+
+	result, err := service.GetCartSummary(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, result.Tax.TaxRate)
+	assert.Equal(t, 18.0, result.Tax.TaxAmount)
+	assert.Equal(t, 298.0, result.TotalAmount)
+	cartRepo.AssertExpectations(t)
+}
+
+func TestCartService_GetCartSummary_TaxesByDestination(t *testing.T) {
+	ctx := context.Background()
+	taxes := NewStaticTaxService([]TaxJurisdiction{
+		{Name: "CA state tax", Country: "US", State: "CA", Rate: 0.0725},
+	})
+	summary := func(destination domain.ShippingDestination) *domain.CartSummary {
+		return &domain.CartSummary{CartID: 1, Currency: "USD", Subtotal: 150, TaxableSubtotal: 100, Destination: destination}
+	}
+
+	tests := []struct {
+		name        string
+		destination domain.ShippingDestination
+		lines       []dto.TaxLineResponse
+		taxAmount   float64
+	}{
+		{"covered address uses its rates", domain.ShippingDestination{Country: "US", State: "CA"},
+			[]dto.TaxLineResponse{{Name: "CA state tax", Rate: 0.0725, Amount: 7.25}}, 7.25},
+		{"uncovered address uses the flat rate", domain.ShippingDestination{Country: "US", State: "OR"},
+			[]dto.TaxLineResponse{{Name: "Tax", Rate: 0.1, Amount: 10}}, 10},
+		{"no address uses the flat rate", domain.ShippingDestination{},
+			[]dto.TaxLineResponse{{Name: "Tax", Rate: 0.1, Amount: 10}}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := new(MockCartRepository)
+			service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{TaxRate: 0.1}, CouponRedemptionPolicy{}, nil, taxes, CartExpiryPolicy{})
+			cartRepo.On("GetCartSummary", ctx, int64(1)).Return(summary(tt.destination), nil)
+
+			result, err := service.GetCartSummary(ctx, 1)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, result.Tax.Lines)
+			assert.Equal(t, tt.taxAmount, result.TaxAmount)
+		})
+	}
+}
+
+func TestCartService_SetCartAddress(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := new(MockCartRepository)
+	service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+	cartRepo.On("UpdateCart", ctx, mock.MatchedBy(func(cart *domain.Cart) bool {
+		return cart.ShipCountry == "US" && cart.ShipState == "CA" && cart.ShipPostalCode == "94105"
+	})).Return(nil)
+	cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{CartID: 1, Currency: "USD"}, nil)
+
+	result, err := service.SetCartAddress(ctx, 1, domain.ShippingDestination{Country: "US", State: "CA", PostalCode: "94105"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.CartID)
+	cartRepo.AssertExpectations(t)
+}
+
+func TestCartService_AddItemToCart_PriceGuard(t *testing.T) {
+	ctx := context.Background()
+	pricing := CartPricingPolicy{RejectNonPositivePrices: true}
+
+	setup := func(product *domain.Product) (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		cartRepo.On("GetCartItemByProduct", ctx, int64(1), product.ID, (*int64)(nil)).Return(nil, sql.ErrNoRows)
+		productRepo.On("GetProductByID", ctx, product.ID).Return(product, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, product.ID, (*int64)(nil)).Return(nil, repository.ErrInventoryNotFound)
+
+		return cartRepo, NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+
+	t.Run("zero-priced product is rejected", func(t *testing.T) {
+		cartRepo, service := setup(&domain.Product{ID: 5, Price: 0, IsActive: true})
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 1})
+
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, ErrInvalidPrice)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("product flagged free is allowed", func(t *testing.T) {
+		cartRepo, service := setup(&domain.Product{ID: 6, Price: 0, IsActive: true, IsFree: true})
+		cartRepo.On("AddItemToCart", ctx, mock.AnythingOfType("*domain.CartItem")).Return(nil)
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 6, Quantity: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, item.TotalPrice)
+		cartRepo.AssertCalled(t, "AddItemToCart", ctx, mock.AnythingOfType("*domain.CartItem"))
+	})
+
+	t.Run("negative price is rejected even when flagged free", func(t *testing.T) {
+		cartRepo, service := setup(&domain.Product{ID: 7, Price: -5, IsActive: true, IsFree: true})
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 7, Quantity: 1})
+
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, ErrInvalidPrice)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_RejectsNonPositiveQuantities(t *testing.T) {
+	ctx := context.Background()
+
+	for _, quantity := range []int{0, -3} {
+		cartRepo := new(MockCartRepository)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		_, addErr := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: quantity})
+		_, updateErr := service.UpdateCartItem(ctx, 10, &dto.UpdateCartItemRequest{Quantity: &quantity})
+
+		var quantityErr *QuantityError
+		assert.ErrorAs(t, addErr, &quantityErr)
+		assert.ErrorAs(t, updateErr, &quantityErr)
+		assert.Equal(t, 1, quantityErr.Min)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+		cartRepo.AssertNotCalled(t, "UpdateCartItem", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestCartService_CurrencyMismatch(t *testing.T) {
+	ctx := context.Background()
+	pricing := CartPricingPolicy{CatalogCurrency: "USD"}
+
+	t.Run("adding catalog items to a cart in another currency is rejected", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "EUR"}, nil)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		item, err := service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, Quantity: 1})
+
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, ErrCurrencyMismatch)
+		productRepo.AssertNotCalled(t, "GetProductByID", mock.Anything, mock.Anything)
+		cartRepo.AssertNotCalled(t, "AddItemToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("carts in different currencies are not merged", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "EUR"}, nil)
+		cartRepo.On("GetCartByID", ctx, int64(2)).Return(&domain.Cart{ID: 2, Currency: "USD"}, nil)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		err := service.MergeCarts(ctx, 1, 2)
+
+		assert.ErrorIs(t, err, ErrCurrencyMismatch)
+		cartRepo.AssertNotCalled(t, "MergeCarts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a cart holding items keeps its currency", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+		cartRepo.On("GetCartItemCount", ctx, int64(1)).Return(2, nil)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		eur := "EUR"
+		cart, err := service.UpdateCart(ctx, 1, &dto.UpdateCartRequest{Currency: &eur})
+
+		assert.Nil(t, cart)
+		assert.ErrorIs(t, err, ErrCurrencyMismatch)
+		cartRepo.AssertNotCalled(t, "UpdateCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("an empty cart changes currency", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+		cartRepo.On("GetCartItemCount", ctx, int64(1)).Return(0, nil)
+		cartRepo.On("UpdateCart", ctx, mock.AnythingOfType("*domain.Cart")).Return(nil)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), pricing, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+		eur := "eur"
+		cart, err := service.UpdateCart(ctx, 1, &dto.UpdateCartRequest{Currency: &eur})
+
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", cart.Currency)
+	})
+}
+
+// versionedCartItems is a cart repository keeping items in memory with the version
+// check and unique key of cart_items, so concurrent adds can race for real
+type versionedCartItems struct {
+	*MockCartRepository
+
+	mu     sync.Mutex
+	items  map[string]*domain.CartItem
+	nextID int64
+
+	// reads makes the first two item lookups wait for each other, so both adds read
+	// the item before either writes it
+	reads     sync.WaitGroup
+	readCalls int
+}
+
+func newVersionedCartItems() *versionedCartItems {
+	repo := &versionedCartItems{MockCartRepository: new(MockCartRepository), items: map[string]*domain.CartItem{}}
+	repo.reads.Add(2)
+	return repo
+}
+
+func cartItemKey(productID int64, variantID *int64) string {
+	if variantID == nil {
+		return fmt.Sprintf("%d", productID)
+	}
+	return fmt.Sprintf("%d/%d", productID, *variantID)
+}
+
+func (r *versionedCartItems) GetCartItemByProduct(ctx context.Context, cartID, productID int64, variantID *int64) (*domain.CartItem, error) {
+	r.mu.Lock()
+	r.readCalls++
+	racing := r.readCalls <= 2
+	item, ok := r.items[cartItemKey(productID, variantID)]
+	var found domain.CartItem
+	if ok {
+		found = *item
+	}
+	r.mu.Unlock()
+
+	if racing {
+		r.reads.Done()
+		r.reads.Wait()
+	}
+	if !ok {
+		return nil, errors.New("cart item not found")
+	}
+	return &found, nil
+}
+
+func (r *versionedCartItems) AddItemToCart(ctx context.Context, item *domain.CartItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := cartItemKey(item.ProductID, item.ProductVariantID)
+	if _, ok := r.items[key]; ok {
+		return &repository.AlreadyExistsError{Resource: "cart item"}
+	}
+	r.nextID++
+	item.ID = r.nextID
+	item.Version = 1
+	stored := *item
+	r.items[key] = &stored
+	return nil
+}
+
+func (r *versionedCartItems) UpdateCartItem(ctx context.Context, id int64, item *domain.CartItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.items[cartItemKey(item.ProductID, item.ProductVariantID)]
+	if stored.Version != item.Version {
+		return repository.ErrStaleCartItem
+	}
+	item.Version++
+	*stored = *item
+	return nil
+}
+
+func TestCartService_AddItemToCart_ConcurrentAdds(t *testing.T) {
+	ctx := context.Background()
+	variantID := int64(50)
+
+	tests := []struct {
+		name      string
+		variantID *int64
+		existing  int
+	}{
+		{"merging into an existing item", nil, 1},
+		{"both creating the item", &variantID, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := newVersionedCartItems()
+			productRepo := new(MockProductRepository)
+			inventoryRepo := new(MockInventoryRepository)
+			service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+			cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+			productRepo.On("GetProductByID", ctx, int64(5)).Return(&domain.Product{ID: 5, Price: 10, IsActive: true}, nil)
+			productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, Price: 10, IsActive: true}, nil)
+			inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), tt.variantID).Return(nil, repository.ErrInventoryNotFound)
+			if tt.existing > 0 {
+				require.NoError(t, cartRepo.AddItemToCart(ctx, &domain.CartItem{CartID: 1, ProductID: 5, ProductVariantID: tt.variantID, Quantity: tt.existing, UnitPrice: 10}))
+			}
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			for i, quantity := range []int{2, 3} {
+				wg.Add(1)
+				go func(i, quantity int) {
+					defer wg.Done()
+					_, errs[i] = service.AddItemToCart(ctx, 1, &dto.AddToCartRequest{ProductID: 5, ProductVariantID: tt.variantID, Quantity: quantity})
+				}(i, quantity)
+			}
+			wg.Wait()
+
+			require.NoError(t, errs[0])
+			require.NoError(t, errs[1])
+			item := cartRepo.items[cartItemKey(5, tt.variantID)]
+			assert.Equal(t, tt.existing+5, item.Quantity)
+			assert.Equal(t, float64(tt.existing+5)*10, item.TotalPrice)
+		})
+	}
+}
+
+func TestCartService_GetFullCart(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := new(MockCartRepository)
+	productRepo := new(MockProductRepository)
+	service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{TaxRate: 0.1}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+	cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+		CartID:          1,
+		Currency:        "USD",
+		ItemCount:       3,
+		Subtotal:        100,
+		TaxableSubtotal: 100,
+		ShippingAmount:  5,
+		Coupons:         []domain.Coupon{{Code: "SAVE10", Type: "percentage", Value: 10, IsActive: true}},
+		Items: []domain.CartItem{
+			{ID: 11, CartID: 1, ProductID: 5, Quantity: 2, UnitPrice: 20, TotalPrice: 40},
+			{ID: 12, CartID: 1, ProductID: 6, Quantity: 1, UnitPrice: 60, TotalPrice: 60},
+		},
+	}, nil)
+	cartRepo.On("GetCartCoupons", ctx, int64(1), 0, 0).Return([]*domain.CartCoupon{
+		{ID: 21, CartID: 1, CouponCode: "SAVE10", DiscountAmount: 10},
+	}, int64(1), nil)
+	cartRepo.On("GetCartShipping", ctx, int64(1)).Return(&domain.CartShipping{ID: 31, CartID: 1, ShippingMethod: "Standard", ShippingAmount: 5}, nil)
+	cartRepo.On("GetCartItemDetails", ctx, int64(1)).Return([]*domain.CartItemDetail{
+		{CartItem: domain.CartItem{ID: 11, CartID: 1, ProductID: 5, Quantity: 2, UnitPrice: 20, TotalPrice: 40}, ProductName: "Widget", SKU: "WID-1"},
+		{CartItem: domain.CartItem{ID: 12, CartID: 1, ProductID: 6, Quantity: 1, UnitPrice: 60, TotalPrice: 60}, ProductName: "Gadget", SKU: "GAD-1-RED"},
+	}, nil)
+	productRepo.On("GetPrimaryImages", ctx, []int64{5, 6}).Return(map[int64]*domain.ProductImage{
+		5: {ProductID: 5, URL: "https://cdn.example.com/widget.jpg"},
+	}, nil)
+
+	result, err := service.GetFullCart(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Cart.ID)
+
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "Widget", result.Items[0].ProductName)
+	assert.Equal(t, "WID-1", result.Items[0].SKU)
+	require.NotNil(t, result.Items[0].ProductImage)
+	assert.Equal(t, "https://cdn.example.com/widget.jpg", *result.Items[0].ProductImage)
+	assert.Equal(t, "Gadget", result.Items[1].ProductName)
+	assert.Equal(t, "GAD-1-RED", result.Items[1].SKU)
+	assert.Nil(t, result.Items[1].ProductImage)
+
+	require.Len(t, result.Coupons, 1)
+	assert.Equal(t, "SAVE10", result.Coupons[0].CouponCode)
+	assert.Equal(t, 10.0, result.Coupons[0].DiscountAmount)
+
+	require.NotNil(t, result.Shipping)
+	assert.Equal(t, "Standard", result.Shipping.ShippingMethod)
+
+	assert.Equal(t, 3, result.Summary.ItemCount)
+	assert.Equal(t, 10.0, result.Summary.TaxAmount)
+	assert.Equal(t, 105.0, result.Summary.TotalAmount)
+
+	cartRepo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+}
+
+func TestCartService_ApplyCouponToCart_Limits(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(42)
+	req := &dto.ApplyCouponRequest{CouponCode: "ONCE"}
+
+	setup := func(redemption CouponRedemptionPolicy, cart *domain.Cart, coupon *domain.Coupon) (CartService, *MockCartRepository, *MockCouponRepository) {
+		cartRepo := new(MockCartRepository)
+		couponRepo := new(MockCouponRepository)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), couponRepo, CartPricingPolicy{}, redemption, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, cart.ID).Return(cart, nil)
+		cartRepo.On("GetCartCouponByCode", ctx, cart.ID, "ONCE").Return(nil, errors.New("coupon ONCE not found in cart"))
+		cartRepo.On("GetCartSummary", ctx, cart.ID).Return(&domain.CartSummary{CartID: cart.ID, Subtotal: 100, Currency: "USD"}, nil).Maybe()
+		coupon.IsActive = true
+		couponRepo.On("GetCouponByCode", ctx, "ONCE").Return(coupon, nil)
+		return service, cartRepo, couponRepo
+	}
+
+	t.Run("per-user limit reached at checkout redemption", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", PerUserLimit: 1}
+		service, cartRepo, couponRepo := setup(CouponRedemptionPolicy{RedeemAtCheckout: true}, &domain.Cart{ID: 1, UserID: &userID}, coupon)
+		// The user already redeemed it with another cart
+		couponRepo.On("CountCouponRedemptions", ctx, int64(5), &userID).Return(int64(3), int64(1), nil)
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, repository.ErrCouponLimitReached)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("global limit reached at checkout redemption", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", UsageLimit: 3}
+		service, cartRepo, couponRepo := setup(CouponRedemptionPolicy{RedeemAtCheckout: true}, &domain.Cart{ID: 1, UserID: &userID}, coupon)
+		couponRepo.On("CountCouponRedemptions", ctx, int64(5), &userID).Return(int64(3), int64(0), nil)
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, repository.ErrCouponLimitReached)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("under the limits at checkout redemption", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", UsageLimit: 3, PerUserLimit: 1}
+		service, cartRepo, couponRepo := setup(CouponRedemptionPolicy{RedeemAtCheckout: true}, &domain.Cart{ID: 1, UserID: &userID}, coupon)
+		couponRepo.On("CountCouponRedemptions", ctx, int64(5), &userID).Return(int64(2), int64(0), nil)
+		cartRepo.On("ApplyCouponToCart", ctx, mock.AnythingOfType("*domain.CartCoupon")).Return(nil)
+
+		applied, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ONCE", applied.CouponCode)
+		couponRepo.AssertNotCalled(t, "RedeemCoupon", mock.Anything, mock.Anything)
+	})
+
+	t.Run("redeemed on apply", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", PerUserLimit: 1}
+		service, cartRepo, couponRepo := setup(CouponRedemptionPolicy{}, &domain.Cart{ID: 1, UserID: &userID}, coupon)
+		couponRepo.On("RedeemCoupon", ctx, &domain.CouponRedemption{CouponID: 5, UserID: &userID, CartID: 1}).Return(nil)
+		cartRepo.On("ApplyCouponToCart", ctx, mock.AnythingOfType("*domain.CartCoupon")).Return(nil)
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.NoError(t, err)
+		couponRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit reached when redeeming on apply", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", PerUserLimit: 1}
+		service, cartRepo, couponRepo := setup(CouponRedemptionPolicy{}, &domain.Cart{ID: 1, UserID: &userID}, coupon)
+		couponRepo.On("RedeemCoupon", ctx, mock.Anything).Return(repository.ErrCouponLimitReached)
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, repository.ErrCouponLimitReached)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("guest cart cannot use a per-user coupon", func(t *testing.T) {
+		coupon := &domain.Coupon{ID: 5, Code: "ONCE", PerUserLimit: 1}
+		service, _, couponRepo := setup(CouponRedemptionPolicy{}, &domain.Cart{ID: 1}, coupon)
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, ErrCouponRequiresAccount)
+		couponRepo.AssertNotCalled(t, "RedeemCoupon", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_ApplyCouponToCart_Validation(t *testing.T) {
+	ctx := context.Background()
+	req := &dto.ApplyCouponRequest{CouponCode: "SAVE"}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	setup := func(coupon *domain.Coupon, summary *domain.CartSummary) (CartService, *MockCartRepository) {
+		cartRepo := new(MockCartRepository)
+		couponRepo := new(MockCouponRepository)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), couponRepo, CartPricingPolicy{}, CouponRedemptionPolicy{RedeemAtCheckout: true}, nil, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1}, nil)
+		cartRepo.On("GetCartCouponByCode", ctx, int64(1), "SAVE").Return(nil, errors.New("coupon SAVE not found in cart"))
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(summary, nil).Maybe()
+		cartRepo.On("ApplyCouponToCart", ctx, mock.AnythingOfType("*domain.CartCoupon")).Return(nil).Maybe()
+		couponRepo.On("GetCouponByCode", ctx, "SAVE").Return(coupon, nil)
+		couponRepo.On("CountCouponRedemptions", ctx, coupon.ID, (*int64)(nil)).Return(int64(0), int64(0), nil).Maybe()
+		return service, cartRepo
+	}
+
+	t.Run("inactive coupon", func(t *testing.T) {
+		service, cartRepo := setup(&domain.Coupon{ID: 5, Code: "SAVE", Type: "fixed_amount", Value: 10}, &domain.CartSummary{Subtotal: 100})
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, ErrCouponInactive)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("coupon not started yet", func(t *testing.T) {
+		service, _ := setup(&domain.Coupon{ID: 5, Code: "SAVE", Type: "fixed_amount", Value: 10, IsActive: true, StartsAt: future}, &domain.CartSummary{Subtotal: 100})
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, ErrCouponInactive)
+	})
+
+	t.Run("expired coupon", func(t *testing.T) {
+		service, cartRepo := setup(&domain.Coupon{ID: 5, Code: "SAVE", Type: "fixed_amount", Value: 10, IsActive: true, ExpiresAt: &past}, &domain.CartSummary{Subtotal: 100})
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, ErrCouponExpired)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("minimum order not met", func(t *testing.T) {
+		service, cartRepo := setup(&domain.Coupon{ID: 5, Code: "SAVE", Type: "fixed_amount", Value: 10, MinOrderAmount: 50, IsActive: true}, &domain.CartSummary{Subtotal: 49.99, Currency: "USD"})
+
+		_, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		assert.ErrorIs(t, err, ErrCouponMinNotMet)
+		cartRepo.AssertNotCalled(t, "ApplyCouponToCart", mock.Anything, mock.Anything)
+	})
+
+	t.Run("percentage coupon priced against the subtotal", func(t *testing.T) {
+		service, _ := setup(&domain.Coupon{ID: 5, Code: "SAVE", Type: "percentage", Value: 15, MinOrderAmount: 50, IsActive: true, ExpiresAt: &future}, &domain.CartSummary{Subtotal: 80, Currency: "USD"})
+
+		applied, err := service.ApplyCouponToCart(ctx, 1, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 12.0, applied.DiscountAmount)
+	})
+}
+
+// uniqueCartRepository is an in-memory CartRepository that enforces one cart per
+// guest session like the database's unique index. The first two lookups wait for
+// each other so concurrent GetOrCreateCart calls both miss and race to insert.
+type uniqueCartRepository struct {
+	repository.CartRepository
+
+	mu      sync.Mutex
+	carts   []*domain.Cart
+	lookups int
+	bothIn  chan struct{}
+}
+
+func (r *uniqueCartRepository) GetCartBySessionOrUser(ctx context.Context, sessionID string, userID *int64) (*domain.Cart, error) {
+	r.mu.Lock()
+	r.lookups++
+	if r.lookups == 2 {
+		close(r.bothIn)
+	}
+	first := r.lookups <= 2
+	r.mu.Unlock()
+
+	if first {
+		<-r.bothIn
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cart := range r.carts {
+		if cart.SessionID == sessionID {
+			return cart, nil
+		}
+	}
+	return nil, repository.ErrCartNotFound
+}
+
+func (r *uniqueCartRepository) CreateCart(ctx context.Context, cart *domain.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.carts {
+		if existing.SessionID == cart.SessionID {
+			return repository.ErrCartExists
+		}
+	}
+	cart.ID = int64(len(r.carts) + 1)
+	r.carts = append(r.carts, cart)
+	return nil
+}
+
+func TestCartService_GetOrCreateCart_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := &uniqueCartRepository{bothIn: make(chan struct{})}
+	service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	var wg sync.WaitGroup
+	ids := make([]int64, 2)
+	errs := make([]error, 2)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cart, err := service.GetOrCreateCart(ctx, nil, "session-1", "USD")
+			errs[i] = err
+			if err == nil {
+				ids[i] = cart.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, ids[0], ids[1])
+	assert.Len(t, cartRepo.carts, 1)
+}
+
+func TestCartService_SetCartTaxExempt(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := new(MockCartRepository)
+	cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+	cartRepo.On("UpdateCart", ctx, mock.AnythingOfType("*domain.Cart")).Return(nil)
+	service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+
+	cart, err := service.SetCartTaxExempt(ctx, 1, true)
+
+	require.NoError(t, err)
+	assert.True(t, cart.TaxExempt)
+	cartRepo.AssertCalled(t, "UpdateCart", ctx, mock.MatchedBy(func(c *domain.Cart) bool {
+		return c.ID == 1 && c.TaxExempt
+	}))
+}
+
+func TestCartService_SetCartExpiry(t *testing.T) {
+	ctx := context.Background()
+	current := time.Now().Add(7 * 24 * time.Hour)
+
+	newService := func() (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, ExpiresAt: &current}, nil)
+		cartRepo.On("UpdateCart", ctx, mock.AnythingOfType("*domain.Cart")).Return(nil)
+		return cartRepo, NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{MaxExpiry: 90 * 24 * time.Hour})
+	}
+
+	t.Run("earlier expiry expires the cart", func(t *testing.T) {
+		cartRepo, service := newService()
+		earlier := time.Now().Add(-time.Minute)
+
+		cart, err := service.SetCartExpiry(ctx, 1, &earlier)
+
+		require.NoError(t, err)
+		assert.Equal(t, &earlier, cart.ExpiresAt)
+		cartRepo.AssertCalled(t, "UpdateCart", ctx, mock.MatchedBy(func(c *domain.Cart) bool {
+			return c.ExpiresAt != nil && c.ExpiresAt.Equal(earlier)
+		}))
+	})
+
+	t.Run("later expiry extends the cart", func(t *testing.T) {
+		cartRepo, service := newService()
+		later := time.Now().Add(60 * 24 * time.Hour)
+
+		cart, err := service.SetCartExpiry(ctx, 1, &later)
+
+		require.NoError(t, err)
+		assert.Equal(t, &later, cart.ExpiresAt)
+		cartRepo.AssertNumberOfCalls(t, "UpdateCart", 1)
+	})
+
+	t.Run("nil clears the expiry", func(t *testing.T) {
+		cartRepo, service := newService()
+
+		cart, err := service.SetCartExpiry(ctx, 1, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, cart.ExpiresAt)
+		cartRepo.AssertCalled(t, "UpdateCart", ctx, mock.MatchedBy(func(c *domain.Cart) bool {
+			return c.ExpiresAt == nil
+		}))
+	})
+
+	t.Run("expiry beyond the maximum is rejected", func(t *testing.T) {
+		cartRepo, service := newService()
+		tooFar := time.Now().Add(365 * 24 * time.Hour)
+
+		_, err := service.SetCartExpiry(ctx, 1, &tooFar)
+
+		assert.ErrorIs(t, err, ErrCartExpiryTooFar)
+		cartRepo.AssertNotCalled(t, "UpdateCart", mock.Anything, mock.Anything)
+	})
+}
+
+// mergingCartRepository is an in-memory CartRepository holding carts, their items and
+// current catalog prices, merging carts the way the database transaction does
+type mergingCartRepository struct {
+	repository.CartRepository
+
+	carts  []*domain.Cart
+	items  map[int64][]*domain.CartItem
+	prices map[int64]float64
+}
+
+func (r *mergingCartRepository) GetGuestCartBySession(ctx context.Context, sessionID string) (*domain.Cart, error) {
+	for _, cart := range r.carts {
+		if cart.UserID == nil && cart.SessionID == sessionID {
+			return cart, nil
+		}
+	}
+	return nil, repository.ErrCartNotFound
+}
+
+func (r *mergingCartRepository) GetCartBySessionOrUser(ctx context.Context, sessionID string, userID *int64) (*domain.Cart, error) {
+	for _, cart := range r.carts {
+		if userID != nil && cart.UserID != nil && *cart.UserID == *userID {
+			return cart, nil
+		}
+	}
+	return nil, repository.ErrCartNotFound
+}
+
+func (r *mergingCartRepository) CreateCart(ctx context.Context, cart *domain.Cart) error {
+	cart.ID = int64(len(r.carts) + 1)
+	r.carts = append(r.carts, cart)
+	return nil
+}
+
+func (r *mergingCartRepository) GetCartItemCount(ctx context.Context, cartID int64) (int, error) {
+	return len(r.items[cartID]), nil
+}
+
+func (r *mergingCartRepository) MergeCarts(ctx context.Context, sourceCartID, targetCartID int64) error {
+	for _, item := range r.items[sourceCartID] {
+		merged := false
+		for _, existing := range r.items[targetCartID] {
+			if existing.ProductID == item.ProductID {
+				existing.Quantity += item.Quantity
+				existing.TotalPrice = existing.UnitPrice * float64(existing.Quantity)
+				merged = true
+			}
+		}
+		if !merged {
+			item.CartID = targetCartID
+			r.items[targetCartID] = append(r.items[targetCartID], item)
+		}
+	}
+	delete(r.items, sourceCartID)
+	for i, cart := range r.carts {
+		if cart.ID == sourceCartID {
+			r.carts = append(r.carts[:i], r.carts[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *mergingCartRepository) RecalculateCartItemPrices(ctx context.Context, cartIDs []int64) (int, int, error) {
+	changed := 0
+	for _, cartID := range cartIDs {
+		for _, item := range r.items[cartID] {
+			if price := r.prices[item.ProductID]; price != item.UnitPrice {
+				item.UnitPrice = price
+				item.TotalPrice = price * float64(item.Quantity)
+				changed++
+			}
+		}
+	}
+	return len(cartIDs), changed, nil
+}
+
+func TestCartService_MergeGuestCartOnLogin(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(7)
+	newService := func(cartRepo *mergingCartRepository) CartService {
+		return NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+	quantities := func(items []*domain.CartItem) map[int64]int {
+		byProduct := make(map[int64]int)
+		for _, item := range items {
+			byProduct[item.ProductID] = item.Quantity
+		}
+		return byProduct
+	}
+
+	t.Run("overlapping items have their quantities summed and are repriced", func(t *testing.T) {
+		cartRepo := &mergingCartRepository{
+			carts: []*domain.Cart{
+				{ID: 1, SessionID: "session-1", Currency: "USD"},
+				{ID: 2, UserID: &userID, SessionID: "session-0", Currency: "USD"},
+			},
+			items: map[int64][]*domain.CartItem{
+				1: {{ID: 10, CartID: 1, ProductID: 100, Quantity: 2, UnitPrice: 9, TotalPrice: 18}},
+				2: {{ID: 20, CartID: 2, ProductID: 100, Quantity: 1, UnitPrice: 10, TotalPrice: 10}},
+			},
+			prices: map[int64]float64{100: 12},
+		}
+
+		cart, err := newService(cartRepo).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), cart.ID)
+		require.Len(t, cartRepo.items[2], 1)
+		assert.Equal(t, 3, cartRepo.items[2][0].Quantity)
+		assert.Equal(t, 12.0, cartRepo.items[2][0].UnitPrice)
+		assert.Equal(t, 36.0, cartRepo.items[2][0].TotalPrice)
+		assert.Len(t, cartRepo.carts, 1)
+		assert.NotContains(t, cartRepo.items, int64(1))
+	})
+
+	t.Run("non-overlapping items are moved into a newly created user cart", func(t *testing.T) {
+		cartRepo := &mergingCartRepository{
+			carts: []*domain.Cart{{ID: 1, SessionID: "session-1", Currency: "EUR"}},
+			items: map[int64][]*domain.CartItem{
+				1: {
+					{ID: 10, CartID: 1, ProductID: 100, Quantity: 2, UnitPrice: 9, TotalPrice: 18},
+					{ID: 11, CartID: 1, ProductID: 200, Quantity: 1, UnitPrice: 5, TotalPrice: 5},
+				},
+			},
+			prices: map[int64]float64{100: 9, 200: 6},
+		}
+
+		cart, err := newService(cartRepo).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, &userID, cart.UserID)
+		assert.Equal(t, "EUR", cart.Currency)
+		assert.Equal(t, map[int64]int{100: 2, 200: 1}, quantities(cartRepo.items[cart.ID]))
+		assert.Equal(t, 6.0, cartRepo.items[cart.ID][1].UnitPrice)
+		require.Len(t, cartRepo.carts, 1)
+		assert.Equal(t, cart, cartRepo.carts[0])
+	})
+
+	t.Run("non-overlapping items join the user's existing items", func(t *testing.T) {
+		cartRepo := &mergingCartRepository{
+			carts: []*domain.Cart{
+				{ID: 1, SessionID: "session-1", Currency: "USD"},
+				{ID: 2, UserID: &userID, Currency: "USD"},
+			},
+			items: map[int64][]*domain.CartItem{
+				1: {{ID: 10, CartID: 1, ProductID: 200, Quantity: 4, UnitPrice: 5, TotalPrice: 20}},
+				2: {{ID: 20, CartID: 2, ProductID: 100, Quantity: 1, UnitPrice: 10, TotalPrice: 10}},
+			},
+			prices: map[int64]float64{100: 10, 200: 5},
+		}
+
+		cart, err := newService(cartRepo).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), cart.ID)
+		assert.Equal(t, map[int64]int{100: 1, 200: 4}, quantities(cartRepo.items[2]))
+	})
+
+	t.Run("a user cart holding items in another currency is left alone", func(t *testing.T) {
+		cartRepo := &mergingCartRepository{
+			carts: []*domain.Cart{
+				{ID: 1, SessionID: "session-1", Currency: "EUR"},
+				{ID: 2, UserID: &userID, Currency: "USD"},
+			},
+			items: map[int64][]*domain.CartItem{
+				1: {{ID: 10, CartID: 1, ProductID: 200, Quantity: 1, UnitPrice: 5, TotalPrice: 5}},
+				2: {{ID: 20, CartID: 2, ProductID: 100, Quantity: 1, UnitPrice: 10, TotalPrice: 10}},
+			},
+		}
+
+		cart, err := newService(cartRepo).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		assert.Nil(t, cart)
+		assert.ErrorIs(t, err, ErrCurrencyMismatch)
+		assert.Len(t, cartRepo.carts, 2)
+		assert.Len(t, cartRepo.items[1], 1)
+	})
+
+	t.Run("without a guest cart the user's cart is returned as is", func(t *testing.T) {
+		userCart := &domain.Cart{ID: 2, UserID: &userID, Currency: "USD"}
+		cartRepo := &mergingCartRepository{carts: []*domain.Cart{userCart}}
+
+		cart, err := newService(cartRepo).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, userCart, cart)
+
+		cart, err = newService(&mergingCartRepository{}).MergeGuestCartOnLogin(ctx, "session-1", userID)
+
+		require.NoError(t, err)
+		assert.Nil(t, cart)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
 )
 
 type CategoryService interface {
@@ -20,6 +21,8 @@ type CategoryService interface {
 	ListCategories(ctx context.Context, req *ListCategoriesRequest) (*ListCategoriesResponse, error)
 	GetCategoryHierarchy(ctx context.Context) ([]*domain.CategoryHierarchy, error)
 	GetCategoryChildren(ctx context.Context, parentID int64) ([]*domain.Category, error)
+	GetCategoryTree(ctx context.Context) ([]*domain.CategoryHierarchy, error)
+	GetCategoryBreadcrumb(ctx context.Context, id int64) ([]*domain.Category, error)
 }
 
 type categoryService struct {
@@ -89,7 +92,7 @@ func (s *categoryService) GetCategory(ctx context.Context, id int64) (*domain.Ca
 		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
 	if category == nil {
-		return nil, fmt.Errorf("category with ID %d not found", id)
+		return nil, fmt.Errorf("%w: ID %d", repository.ErrCategoryNotFound, id)
 	}
 
 	return category, nil
@@ -101,7 +104,7 @@ func (s *categoryService) GetCategoryBySlug(ctx context.Context, slug string) (*
 		return nil, fmt.Errorf("failed to get category by slug: %w", err)
 	}
 	if category == nil {
-		return nil, fmt.Errorf("category with slug '%s' not found", slug)
+		return nil, fmt.Errorf("%w: slug %q", repository.ErrCategoryNotFound, slug)
 	}
 
 	return category, nil
@@ -114,14 +117,14 @@ func (s *categoryService) UpdateCategory(ctx context.Context, id int64, req *dto
 		return nil, fmt.Errorf("failed to get existing category: %w", err)
 	}
 	if existing == nil {
-		return nil, fmt.Errorf("category with ID %d not found", id)
+		return nil, fmt.Errorf("%w: ID %d", repository.ErrCategoryNotFound, id)
 	}
 
 	// Validate parent category exists if provided
 	if req.ParentID != nil {
 		// Prevent setting parent to self
 		if *req.ParentID == id {
-			return nil, fmt.Errorf("category cannot be its own parent")
+			return nil, fmt.Errorf("category cannot be its own parent: %w", repository.ErrCategoryCycle)
 		}
 
 		// check if the gived parent id exists...
@@ -132,6 +135,17 @@ func (s *categoryService) UpdateCategory(ctx context.Context, id int64, req *dto
 		if !exists {
 			return nil, fmt.Errorf("parent category with ID %d does not exist", *req.ParentID)
 		}
+
+		// Prevent moving a category under one of its own descendants
+		ancestors, err := s.categoryRepo.GetBreadcrumb(ctx, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate parent category: %w", err)
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.ID == id {
+				return nil, fmt.Errorf("category cannot be moved under its own descendant: %w", repository.ErrCategoryCycle)
+			}
+		}
 	}
 
 	// Validate slug uniqueness if provided
@@ -192,7 +206,7 @@ func (s *categoryService) DeleteCategory(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to check category existence: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("category with ID %d not found", id)
+		return fmt.Errorf("%w: ID %d", repository.ErrCategoryNotFound, id)
 	}
 
 	// Check if category has children
@@ -221,16 +235,7 @@ func (s *categoryService) DeleteCategory(ctx context.Context, id int64) error {
 }
 
 func (s *categoryService) ListCategories(ctx context.Context, req *ListCategoriesRequest) (*ListCategoriesResponse, error) {
-	// Set default pagination
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.Limit <= 0 {
-		req.Limit = 10
-	}
-	if req.Limit > 100 {
-		req.Limit = 100
-	}
+	req.Page, req.Limit = httpx.ClampPagination(req.Page, req.Limit)
 
 	filter := &domain.CategoryFilter{
 		ParentID: req.ParentID,
@@ -256,7 +261,7 @@ func (s *categoryService) ListCategories(ctx context.Context, req *ListCategorie
 	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
 
 	return &ListCategoriesResponse{
-		Categories: categories,
+		Categories: httpx.NonNilSlice(categories),
 		Total:      total,
 		Page:       req.Page,
 		Limit:      req.Limit,
@@ -282,6 +287,26 @@ func (s *categoryService) GetCategoryChildren(ctx context.Context, parentID int6
 	return children, nil
 }
 
+// GetCategoryTree returns every category nested under its parent
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]*domain.CategoryHierarchy, error) {
+	tree, err := s.categoryRepo.GetTree(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category tree: %w", err)
+	}
+
+	return tree, nil
+}
+
+// GetCategoryBreadcrumb returns the path from the root category down to a category
+func (s *categoryService) GetCategoryBreadcrumb(ctx context.Context, id int64) ([]*domain.Category, error) {
+	breadcrumb, err := s.categoryRepo.GetBreadcrumb(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category breadcrumb: %w", err)
+	}
+
+	return breadcrumb, nil
+}
+
 // generateSlug creates a URL-friendly slug from a name
 func (s *categoryService) generateSlug(name string) string {
 	// Convert to lowercase and replace spaces with hyphens
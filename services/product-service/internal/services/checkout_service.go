@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+)
+
+type CheckoutService interface {
+	CreateOrderFromCart(ctx context.Context, cartID int64, sessionID string) (*dto.OrderResponse, error)
+}
+
+// CartNotCheckoutableError is returned when a cart fails checkout validation. Validation
+// lists the items that cannot be bought and any minimum order shortfall.
+type CartNotCheckoutableError struct {
+	Validation *dto.CartValidationResponse
+}
+
+func (e *CartNotCheckoutableError) Error() string {
+	return "cart cannot be checked out"
+}
+
+type checkoutService struct {
+	orderRepo  repository.OrderRepository
+	carts      CartService
+	inventory  InventoryService
+	redemption CouponRedemptionPolicy
+	events     EventPublisher
+	now        func() time.Time
+}
+
+func NewCheckoutService(orderRepo repository.OrderRepository, cartService CartService, inventoryService InventoryService, redemption CouponRedemptionPolicy, events EventPublisher) CheckoutService {
+	return &checkoutService{
+		orderRepo:  orderRepo,
+		carts:      cartService,
+		inventory:  inventoryService,
+		redemption: redemption,
+		events:     events,
+		now:        time.Now,
+	}
+}
+
+// CreateOrderFromCart places an order for everything in a cart. Only the cart's owner can
+// check it out: the signed-in user for a user's cart, or the guest session sessionID for
+// a guest cart; other carts are reported as not found. The cart is validated,
+// its lines and priced summary are recorded as a pending order and stock is reserved for
+// every line. The order is then placed in one transaction that commits the order's
+// stock, redeems the cart's coupons when redemptions are deferred to checkout, empties
+// the cart and confirms the order. If any line cannot be reserved or placing fails, the
+// reservations made so far are released and the order is removed, leaving stock, coupons
+// and the cart as they were.
+func (s *checkoutService) CreateOrderFromCart(ctx context.Context, cartID int64, sessionID string) (*dto.OrderResponse, error) {
+	cart, err := s.carts.GetFullCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownsCart(ctx, cart.Cart, sessionID) {
+		return nil, &repository.NotFoundError{Message: fmt.Sprintf("cart with ID %d not found", cartID), Kind: repository.ErrCartNotFound}
+	}
+	if len(cart.Items) == 0 {
+		return nil, repository.ErrCartEmpty
+	}
+
+	validation, err := s.carts.ValidateCartForCheckout(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		return nil, &CartNotCheckoutableError{Validation: validation}
+	}
+
+	number, err := randomHex(6)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate order number: %w", err)
+	}
+
+	now := s.now()
+	order := &domain.Order{
+		OrderNumber:       "ORD-" + strings.ToUpper(number),
+		CartID:            cartID,
+		UserID:            cart.Cart.UserID,
+		Status:            domain.OrderStatusPending,
+		PaymentStatus:     "pending",
+		FulfillmentStatus: "unfulfilled",
+		Subtotal:          cart.Summary.Subtotal,
+		TaxAmount:         cart.Summary.TaxAmount,
+		ShippingAmount:    cart.Summary.ShippingAmount,
+		DiscountAmount:    cart.Summary.DiscountAmount,
+		TotalAmount:       cart.Summary.TotalAmount,
+		Currency:          cart.Summary.Currency,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	items := make([]*domain.OrderItem, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = &domain.OrderItem{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			ProductName:      item.ProductName,
+			ProductSKU:       item.SKU,
+			Quantity:         item.Quantity,
+			UnitPrice:        item.UnitPrice,
+			TotalPrice:       item.TotalPrice,
+		}
+	}
+
+	if err := s.orderRepo.CreateOrder(ctx, order, items); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for _, item := range items {
+		_, err := s.inventory.ReserveStock(ctx, &dto.ReserveStockRequest{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			OrderID:          order.ID,
+			Quantity:         item.Quantity,
+		})
+		if err != nil {
+			s.abandonOrder(ctx, order.ID)
+			return nil, err
+		}
+	}
+
+	// Placing consumes the reservations and takes the units out of stock
+	movements, err := s.orderRepo.PlaceOrder(ctx, order, s.redemption.RedeemAtCheckout, now)
+	if err != nil {
+		s.abandonOrder(ctx, order.ID)
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
+	order.Status = domain.OrderStatusConfirmed
+	order.ConfirmedAt = &now
+
+	s.inventory.PublishCommittedStock(ctx, movements)
+
+	response := orderResponse(order, items)
+	if s.events != nil {
+		s.events.PublishEvent(ctx, domain.EventOrderPlaced, response)
+	}
+
+	return response, nil
+}
+
+// ownsCart reports whether the caller owns a cart: a user's cart belongs to that user
+// when signed in, and a guest cart to the guest session it was created for
+func ownsCart(ctx context.Context, cart dto.CartResponse, sessionID string) bool {
+	if cart.UserID != nil {
+		userID, ok := userctx.UserID(ctx)
+		return ok && userID == *cart.UserID
+	}
+	return sessionID != "" && cart.SessionID == sessionID
+}
+
+// abandonOrder releases the stock reserved for an order that could not be placed and
+// removes the order
+func (s *checkoutService) abandonOrder(ctx context.Context, orderID int64) {
+	if err := s.inventory.ReleaseStock(ctx, &dto.ReleaseStockRequest{OrderID: &orderID}); err != nil {
+		logger.FromContext(ctx).Error("failed to release stock for abandoned order", "order_id", orderID, "error", err)
+	}
+	if err := s.orderRepo.DeleteOrder(ctx, orderID); err != nil {
+		logger.FromContext(ctx).Error("failed to remove abandoned order", "order_id", orderID, "error", err)
+	}
+}
+
+func orderResponse(order *domain.Order, items []*domain.OrderItem) *dto.OrderResponse {
+	response := &dto.OrderResponse{
+		ID:             order.ID,
+		OrderNumber:    order.OrderNumber,
+		CartID:         order.CartID,
+		UserID:         order.UserID,
+		Status:         order.Status,
+		Subtotal:       order.Subtotal,
+		TaxAmount:      order.TaxAmount,
+		ShippingAmount: order.ShippingAmount,
+		DiscountAmount: order.DiscountAmount,
+		TotalAmount:    order.TotalAmount,
+		Currency:       order.Currency,
+		Items:          make([]dto.OrderItemResponse, len(items)),
+		CreatedAt:      httpx.FormatTime(order.CreatedAt),
+	}
+	if order.ConfirmedAt != nil {
+		confirmedAt := httpx.FormatTime(*order.ConfirmedAt)
+		response.ConfirmedAt = &confirmedAt
+	}
+
+	for i, item := range items {
+		response.Items[i] = dto.OrderItemResponse{
+			ID:               item.ID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			ProductName:      item.ProductName,
+			ProductSKU:       item.ProductSKU,
+			Quantity:         item.Quantity,
+			UnitPrice:        item.UnitPrice,
+			TotalPrice:       item.TotalPrice,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOrderRepository is a mock implementation of OrderRepository
+type MockOrderRepository struct {
+	mock.Mock
+	repository.OrderRepository
+}
+
+func (m *MockOrderRepository) CreateOrder(ctx context.Context, order *domain.Order, items []*domain.OrderItem) error {
+	args := m.Called(ctx, order, items)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) PlaceOrder(ctx context.Context, order *domain.Order, redeemCoupons bool, confirmedAt time.Time) ([]*domain.InventoryMovement, error) {
+	args := m.Called(ctx, order, redeemCoupons, confirmedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InventoryMovement), args.Error(1)
+}
+
+func (m *MockOrderRepository) DeleteOrder(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockCheckoutCartService is a mock of the cart service calls made at checkout
+type MockCheckoutCartService struct {
+	mock.Mock
+	CartService
+}
+
+func (m *MockCheckoutCartService) GetFullCart(ctx context.Context, cartID int64) (*dto.FullCartResponse, error) {
+	args := m.Called(ctx, cartID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FullCartResponse), args.Error(1)
+}
+
+func (m *MockCheckoutCartService) ValidateCartForCheckout(ctx context.Context, cartID int64) (*dto.CartValidationResponse, error) {
+	args := m.Called(ctx, cartID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CartValidationResponse), args.Error(1)
+}
+
+// MockCheckoutInventoryService is a mock of the inventory service calls made at checkout
+type MockCheckoutInventoryService struct {
+	mock.Mock
+	InventoryService
+}
+
+func (m *MockCheckoutInventoryService) ReserveStock(ctx context.Context, req *dto.ReserveStockRequest) (*domain.StockReservation, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+
+func (m *MockCheckoutInventoryService) ReleaseStock(ctx context.Context, req *dto.ReleaseStockRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutInventoryService) PublishCommittedStock(ctx context.Context, movements []*domain.InventoryMovement) {
+	m.Called(ctx, movements)
+}
+
+func TestCheckoutService_CreateOrderFromCart(t *testing.T) {
+	userID := int64(7)
+	ctx := userctx.WithUserID(context.Background(), userID)
+	variantID := int64(3)
+
+	fullCart := &dto.FullCartResponse{
+		Cart: dto.CartResponse{ID: 9, UserID: &userID, Currency: "USD"},
+		Items: []dto.FullCartItemResponse{
+			{CartItemResponse: dto.CartItemResponse{ID: 1, CartID: 9, ProductID: 1, Quantity: 2, UnitPrice: 10, TotalPrice: 20}, ProductName: "Chain", SKU: "CH-1"},
+			{CartItemResponse: dto.CartItemResponse{ID: 2, CartID: 9, ProductID: 2, ProductVariantID: &variantID, Quantity: 1, UnitPrice: 15, TotalPrice: 15}, ProductName: "Gear", SKU: "GR-1-L"},
+		},
+		Summary: dto.CartTotalsResponse{ItemCount: 3, Subtotal: 35, TaxAmount: 2.8, ShippingAmount: 5, TotalAmount: 42.8, Currency: "USD"},
+	}
+	valid := &dto.CartValidationResponse{CartID: 9, Valid: true, Issues: []dto.CartItemIssue{}}
+
+	createOrder := func(orderRepo *MockOrderRepository) {
+		orderRepo.On("CreateOrder", ctx, mock.AnythingOfType("*domain.Order"), mock.AnythingOfType("[]*domain.OrderItem")).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(*domain.Order).ID = 100
+				for i, item := range args.Get(2).([]*domain.OrderItem) {
+					item.ID = int64(i + 1)
+					item.OrderID = 100
+				}
+			}).
+			Return(nil)
+	}
+	reservation := func(productID int64, variantID *int64, quantity int) *dto.ReserveStockRequest {
+		return &dto.ReserveStockRequest{ProductID: productID, ProductVariantID: variantID, OrderID: 100, Quantity: quantity}
+	}
+
+	t.Run("reserves stock and places the order", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		carts := new(MockCheckoutCartService)
+		inventory := new(MockCheckoutInventoryService)
+		service := NewCheckoutService(orderRepo, carts, inventory, CouponRedemptionPolicy{RedeemAtCheckout: true}, nil)
+
+		carts.On("GetFullCart", ctx, int64(9)).Return(fullCart, nil)
+		carts.On("ValidateCartForCheckout", ctx, int64(9)).Return(valid, nil)
+		createOrder(orderRepo)
+		inventory.On("ReserveStock", ctx, reservation(1, nil, 2)).Return(&domain.StockReservation{ID: 1}, nil)
+		inventory.On("ReserveStock", ctx, reservation(2, &variantID, 1)).Return(&domain.StockReservation{ID: 2}, nil)
+		movements := []*domain.InventoryMovement{{ID: 101, ProductID: 1}, {ID: 102, ProductID: 2, ProductVariantID: &variantID}}
+		orderRepo.On("PlaceOrder", ctx, mock.AnythingOfType("*domain.Order"), true, mock.AnythingOfType("time.Time")).Return(movements, nil)
+		inventory.On("PublishCommittedStock", ctx, movements).Return()
+
+		order, err := service.CreateOrderFromCart(ctx, 9, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), order.ID)
+		assert.Equal(t, domain.OrderStatusConfirmed, order.Status)
+		assert.NotNil(t, order.ConfirmedAt)
+		assert.Equal(t, &userID, order.UserID)
+		assert.Equal(t, 35.0, order.Subtotal)
+		assert.Equal(t, 42.8, order.TotalAmount)
+		require.Len(t, order.Items, 2)
+		assert.Equal(t, dto.OrderItemResponse{ID: 2, ProductID: 2, ProductVariantID: &variantID, ProductName: "Gear", ProductSKU: "GR-1-L", Quantity: 1, UnitPrice: 15, TotalPrice: 15}, order.Items[1])
+
+		created := orderRepo.Calls[0].Arguments.Get(1).(*domain.Order)
+		assert.Regexp(t, `^ORD-[0-9A-F]{12}$`, created.OrderNumber)
+		assert.Equal(t, int64(9), created.CartID)
+		inventory.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+		orderRepo.AssertNotCalled(t, "DeleteOrder", mock.Anything, mock.Anything)
+		orderRepo.AssertExpectations(t)
+		carts.AssertExpectations(t)
+		inventory.AssertExpectations(t)
+	})
+
+	t.Run("insufficient stock releases reservations and removes the order", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		carts := new(MockCheckoutCartService)
+		inventory := new(MockCheckoutInventoryService)
+		service := NewCheckoutService(orderRepo, carts, inventory, CouponRedemptionPolicy{}, nil)
+
+		orderID := int64(100)
+		shortErr := &repository.InsufficientInventoryError{ProductID: 2, ProductVariantID: &variantID, Requested: 1, Available: 0}
+
+		carts.On("GetFullCart", ctx, int64(9)).Return(fullCart, nil)
+		carts.On("ValidateCartForCheckout", ctx, int64(9)).Return(valid, nil)
+		createOrder(orderRepo)
+		inventory.On("ReserveStock", ctx, reservation(1, nil, 2)).Return(&domain.StockReservation{ID: 1}, nil)
+		inventory.On("ReserveStock", ctx, reservation(2, &variantID, 1)).Return(nil, fmt.Errorf("failed to reserve stock: %w", shortErr))
+		inventory.On("ReleaseStock", ctx, &dto.ReleaseStockRequest{OrderID: &orderID}).Return(nil)
+		orderRepo.On("DeleteOrder", ctx, int64(100)).Return(nil)
+
+		order, err := service.CreateOrderFromCart(ctx, 9, "")
+
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, repository.ErrInsufficientStock)
+		orderRepo.AssertNotCalled(t, "PlaceOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		inventory.AssertNotCalled(t, "PublishCommittedStock", mock.Anything, mock.Anything)
+		orderRepo.AssertExpectations(t)
+		inventory.AssertExpectations(t)
+	})
+
+	t.Run("a failed placement releases reservations and removes the order", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		carts := new(MockCheckoutCartService)
+		inventory := new(MockCheckoutInventoryService)
+		service := NewCheckoutService(orderRepo, carts, inventory, CouponRedemptionPolicy{RedeemAtCheckout: true}, nil)
+
+		orderID := int64(100)
+		carts.On("GetFullCart", ctx, int64(9)).Return(fullCart, nil)
+		carts.On("ValidateCartForCheckout", ctx, int64(9)).Return(valid, nil)
+		createOrder(orderRepo)
+		inventory.On("ReserveStock", ctx, reservation(1, nil, 2)).Return(&domain.StockReservation{ID: 1}, nil)
+		inventory.On("ReserveStock", ctx, reservation(2, &variantID, 1)).Return(&domain.StockReservation{ID: 2}, nil)
+		orderRepo.On("PlaceOrder", ctx, mock.AnythingOfType("*domain.Order"), true, mock.AnythingOfType("time.Time")).Return(nil, repository.ErrCouponLimitReached)
+		inventory.On("ReleaseStock", ctx, &dto.ReleaseStockRequest{OrderID: &orderID}).Return(nil)
+		orderRepo.On("DeleteOrder", ctx, int64(100)).Return(nil)
+
+		order, err := service.CreateOrderFromCart(ctx, 9, "")
+
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, repository.ErrCouponLimitReached)
+		inventory.AssertNotCalled(t, "PublishCommittedStock", mock.Anything, mock.Anything)
+		orderRepo.AssertExpectations(t)
+		inventory.AssertExpectations(t)
+	})
+
+	t.Run("invalid cart places no order", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		carts := new(MockCheckoutCartService)
+		inventory := new(MockCheckoutInventoryService)
+		service := NewCheckoutService(orderRepo, carts, inventory, CouponRedemptionPolicy{}, nil)
+
+		invalid := &dto.CartValidationResponse{CartID: 9, Issues: []dto.CartItemIssue{{CartItemID: 2, Reason: CartIssueInsufficientStock}}}
+		carts.On("GetFullCart", ctx, int64(9)).Return(fullCart, nil)
+		carts.On("ValidateCartForCheckout", ctx, int64(9)).Return(invalid, nil)
+
+		_, err := service.CreateOrderFromCart(ctx, 9, "")
+
+		var checkoutErr *CartNotCheckoutableError
+		require.ErrorAs(t, err, &checkoutErr)
+		assert.Equal(t, invalid, checkoutErr.Validation)
+		orderRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty cart", func(t *testing.T) {
+		carts := new(MockCheckoutCartService)
+		service := NewCheckoutService(new(MockOrderRepository), carts, new(MockCheckoutInventoryService), CouponRedemptionPolicy{}, nil)
+
+		carts.On("GetFullCart", ctx, int64(9)).Return(&dto.FullCartResponse{Cart: dto.CartResponse{ID: 9, UserID: &userID}}, nil)
+
+		_, err := service.CreateOrderFromCart(ctx, 9, "")
+
+		assert.ErrorIs(t, err, repository.ErrCartEmpty)
+	})
+
+	t.Run("only the cart's owner can check it out", func(t *testing.T) {
+		guestCart := &dto.FullCartResponse{Cart: dto.CartResponse{ID: 9, SessionID: "guest-session"}, Items: fullCart.Items}
+
+		tests := []struct {
+			name      string
+			ctx       context.Context
+			cart      *dto.FullCartResponse
+			sessionID string
+		}{
+			{name: "another user's cart", ctx: userctx.WithUserID(context.Background(), 8), cart: fullCart},
+			{name: "a user's cart without signing in", ctx: context.Background(), cart: fullCart, sessionID: "guest-session"},
+			{name: "another session's guest cart", ctx: context.Background(), cart: guestCart, sessionID: "other-session"},
+			{name: "a guest cart without a session", ctx: context.Background(), cart: guestCart},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				orderRepo := new(MockOrderRepository)
+				carts := new(MockCheckoutCartService)
+				service := NewCheckoutService(orderRepo, carts, new(MockCheckoutInventoryService), CouponRedemptionPolicy{}, nil)
+
+				carts.On("GetFullCart", tt.ctx, int64(9)).Return(tt.cart, nil)
+
+				order, err := service.CreateOrderFromCart(tt.ctx, 9, tt.sessionID)
+
+				assert.Nil(t, order)
+				assert.ErrorIs(t, err, repository.ErrCartNotFound)
+				carts.AssertNotCalled(t, "ValidateCartForCheckout", mock.Anything, mock.Anything)
+				orderRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
@@ -2,12 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
 )
 
 type InventoryService interface {
@@ -19,35 +23,97 @@ type InventoryService interface {
 	DeleteInventory(ctx context.Context, id int64) error
 	ListInventory(ctx context.Context, req *dto.ListInventoryRequest) (*dto.ListInventoryResponse, error)
 	GetInventorySummary(ctx context.Context) (*dto.InventorySummaryResponse, error)
+	ListProductsNeedingRestock(ctx context.Context, page, limit int) (*dto.ListRestockCandidatesResponse, error)
 
 	// Stock Movements
 	RecordStockMovement(ctx context.Context, req *dto.StockMovementRequest) (*domain.InventoryMovement, error)
 	GetStockMovements(ctx context.Context, req *dto.ListStockMovementsRequest) (*dto.ListStockMovementsResponse, error)
 	GetStockMovementByID(ctx context.Context, id int64) (*domain.InventoryMovement, error)
+	SetInventoryQuantity(ctx context.Context, req *dto.SetInventoryQuantityRequest) (*domain.InventoryMovement, error)
+	Restock(ctx context.Context, req *dto.RestockRequest) (*domain.InventoryMovement, error)
+	AdjustStock(ctx context.Context, req *dto.AdjustStockRequest) (*domain.InventoryMovement, error)
 
 	// Stock Reservations
 	ReserveStock(ctx context.Context, req *dto.ReserveStockRequest) (*domain.StockReservation, error)
 	ReleaseStock(ctx context.Context, req *dto.ReleaseStockRequest) error
+	ReleaseExpiredReservations(ctx context.Context) error
 	GetStockReservations(ctx context.Context, orderID int64) ([]*domain.StockReservation, error)
+	ExtendReservation(ctx context.Context, reservationID int64, extraDuration time.Duration) (*domain.StockReservation, error)
+
+	// Stock Notifications
+	SubscribeStockNotification(ctx context.Context, userID *int64, req *dto.StockNotificationRequest) (*domain.StockNotification, error)
+	UnsubscribeStockNotification(ctx context.Context, id, userID int64) error
+
+	// Checkout
+	PublishCommittedStock(ctx context.Context, movements []*domain.InventoryMovement)
 
 	// Inventory Alerts
 	GetInventoryAlerts(ctx context.Context, resolved *bool) ([]*domain.InventoryAlert, error)
 	ResolveInventoryAlert(ctx context.Context, alertID int64) error
 	CheckLowStockAlerts(ctx context.Context) error
 
+	// Consistency
+	RecomputeAvailable(ctx context.Context, productID int64) ([]*domain.InventoryDiscrepancy, error)
+	RepairAvailableQuantities(ctx context.Context) error
+
 	// Bulk Operations
 	BulkUpdateStock(ctx context.Context, req *dto.BulkStockUpdateRequest) (*dto.BulkStockUpdateResponse, error)
 }
 
+// ErrReservationExpired is returned when extending a reservation that has already lapsed
+var ErrReservationExpired = errors.New("stock reservation has expired")
+
+// ErrZeroAdjustment is returned when a stock adjustment would not change the quantity
+var ErrZeroAdjustment = errors.New("adjustment delta must not be zero")
+
+// ErrProductInStock is returned when subscribing to stock notifications for an item that is available
+var ErrProductInStock = errors.New("item is in stock")
+
+// StockNotifier tells subscribers that an item they were waiting for is back in stock
+type StockNotifier interface {
+	SendBackInStock(ctx context.Context, notification *domain.StockNotification) error
+}
+
+// StockChangePublisher is told about stock level changes, for example to push live
+// availability to storefronts. Publishing must not block the stock change.
+type StockChangePublisher interface {
+	PublishStockChange(ctx context.Context, change domain.StockChange)
+}
+
+// EventPublisher is told about events other services react to, such as stock being
+// reserved or running low, products changing or orders being placed; see the
+// domain.Event* types. Publishing must not block or fail the change.
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, eventType string, data interface{})
+}
+
+// ReservationPolicy bounds how long stock can be held by a reservation
+type ReservationPolicy struct {
+	// DefaultTTL applies when a reservation request has no expiry
+	DefaultTTL time.Duration
+	// MaxTTL caps the lifetime of a reservation, measured from its creation
+	MaxTTL time.Duration
+}
+
 type inventoryService struct {
-	inventoryRepo repository.InventoryRepository
-	productRepo   repository.ProductRepository
+	inventoryRepo     repository.InventoryRepository
+	productRepo       repository.ProductRepository
+	reservationPolicy ReservationPolicy
+	stockNotifier     StockNotifier
+	stockChanges      StockChangePublisher
+	events            EventPublisher
+	now               func() time.Time
 }
 
-func NewInventoryService(inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository) InventoryService {
+func NewInventoryService(inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository, reservationPolicy ReservationPolicy, stockNotifier StockNotifier, stockChanges StockChangePublisher, events EventPublisher) InventoryService {
 	return &inventoryService{
-		inventoryRepo: inventoryRepo,
-		productRepo:   productRepo,
+		inventoryRepo:     inventoryRepo,
+		productRepo:       productRepo,
+		reservationPolicy: reservationPolicy,
+		stockNotifier:     stockNotifier,
+		stockChanges:      stockChanges,
+		events:            events,
+		now:               time.Now,
 	}
 }
 
@@ -55,6 +121,10 @@ func NewInventoryService(inventoryRepo repository.InventoryRepository, productRe
 
 // CreateInventory creates a new inventory record
 func (s *inventoryService) CreateInventory(ctx context.Context, req *dto.CreateInventoryRequest) (*domain.Inventory, error) {
+	if err := checkQuantity(req.Quantity, minStockQuantity); err != nil {
+		return nil, err
+	}
+
 	// Validate product exists
 	_, err := s.productRepo.GetProductByID(ctx, req.ProductID)
 	if err != nil {
@@ -72,7 +142,10 @@ func (s *inventoryService) CreateInventory(ctx context.Context, req *dto.CreateI
 	// Check if inventory already exists
 	_, err = s.inventoryRepo.GetInventoryByProduct(ctx, req.ProductID, req.ProductVariantID)
 	if err == nil {
-		return nil, fmt.Errorf("inventory already exists for this product/variant combination")
+		return nil, repository.ErrInventoryExists
+	}
+	if !errors.Is(err, repository.ErrInventoryNotFound) {
+		return nil, fmt.Errorf("failed to check existing inventory: %w", err)
 	}
 
 	now := time.Now()
@@ -90,32 +163,46 @@ func (s *inventoryService) CreateInventory(ctx context.Context, req *dto.CreateI
 		UpdatedAt:         now,
 	}
 
-	err = s.inventoryRepo.CreateInventory(ctx, inventory)
+	err = createInitialInventory(ctx, s.inventoryRepo, inventory)
 	if err != nil {
+		if errors.Is(err, repository.ErrInventoryExists) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create inventory: %w", err)
 	}
 
+	return inventory, nil
+}
+
+// createInitialInventory creates an inventory row and records its opening stock as a
+// movement. A failed movement is logged rather than undoing the row.
+func createInitialInventory(ctx context.Context, inventoryRepo repository.InventoryRepository, inventory *domain.Inventory) error {
+	if err := inventoryRepo.CreateInventory(ctx, inventory); err != nil {
+		return err
+	}
+
 	// Record initial stock movement
 	movement := &domain.InventoryMovement{
-		ProductID:        req.ProductID,
-		ProductVariantID: req.ProductVariantID,
+		ProductID:        inventory.ProductID,
+		ProductVariantID: inventory.ProductVariantID,
 		MovementType:     "in",
-		Quantity:         req.Quantity,
+		Quantity:         inventory.Quantity,
 		PreviousQuantity: 0,
-		NewQuantity:      req.Quantity,
+		NewQuantity:      inventory.Quantity,
 		Reference:        "initial_stock",
 		ReferenceType:    "setup",
 		Reason:           "Initial inventory setup",
-		CreatedAt:        now,
+		CreatedAt:        inventory.CreatedAt,
 	}
 
-	err = s.inventoryRepo.RecordStockMovement(ctx, movement)
+	err := inventoryRepo.RecordStockMovement(ctx, movement)
 	if err != nil {
 		// Log error but don't fail the inventory creation
-		fmt.Printf("Warning: failed to record initial stock movement: %v\n", err)
+		logger.FromContext(ctx).Warn("failed to record initial stock movement",
+			"product_id", inventory.ProductID, "variant_id", inventory.ProductVariantID, "error", err)
 	}
 
-	return inventory, nil
+	return nil
 }
 
 // GetInventoryByID retrieves inventory by ID
@@ -140,6 +227,12 @@ func (s *inventoryService) GetInventoryByProduct(ctx context.Context, productID
 
 // UpdateInventory updates an existing inventory record
 func (s *inventoryService) UpdateInventory(ctx context.Context, id int64, req *dto.UpdateInventoryRequest) (*domain.Inventory, error) {
+	if req.Quantity != nil {
+		if err := checkQuantity(*req.Quantity, minStockQuantity); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get existing inventory
 	existing, err := s.inventoryRepo.GetInventoryByID(ctx, id)
 	if err != nil {
@@ -164,12 +257,17 @@ func (s *inventoryService) UpdateInventory(ctx context.Context, id int64, req *d
 	}
 
 	updateInventory.UpdatedAt = time.Now()
+	updateInventory.Version = *req.Version
 
 	err = s.inventoryRepo.UpdateInventory(ctx, &updateInventory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update inventory: %w", err)
 	}
 
+	if updateInventory.AvailableQuantity != existing.AvailableQuantity {
+		s.publishStockChange(ctx, &updateInventory)
+	}
+
 	return &updateInventory, nil
 }
 
@@ -191,6 +289,8 @@ func (s *inventoryService) DeleteInventory(ctx context.Context, id int64) error
 
 // ListInventory lists inventory with filters
 func (s *inventoryService) ListInventory(ctx context.Context, req *dto.ListInventoryRequest) (*dto.ListInventoryResponse, error) {
+	req.Page, req.Limit = httpx.ClampPagination(req.Page, req.Limit)
+
 	// Convert DTO request to repository request
 	repoReq := &repository.ListInventoryRequest{
 		ProductID:        req.ProductID,
@@ -207,7 +307,7 @@ func (s *inventoryService) ListInventory(ctx context.Context, req *dto.ListInven
 	}
 
 	// Convert domain models to DTOs
-	var inventoryResponses []dto.InventoryResponse
+	inventoryResponses := make([]dto.InventoryResponse, 0, len(inventory))
 	for _, inv := range inventory {
 		response := dto.InventoryResponse{
 			ID:                inv.ID,
@@ -219,9 +319,10 @@ func (s *inventoryService) ListInventory(ctx context.Context, req *dto.ListInven
 			MinStockLevel:     inv.MinStockLevel,
 			MaxStockLevel:     getIntPointer(inv.MaxStockLevel),
 			ReorderPoint:      inv.ReorderPoint,
-			LastRestocked:     inv.LastRestocked.Format("2006-01-02T15:04:05Z07:00"),
-			CreatedAt:         inv.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:         inv.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			LastRestocked:     httpx.FormatTime(inv.LastRestocked),
+			CreatedAt:         httpx.FormatTime(inv.CreatedAt),
+			UpdatedAt:         httpx.FormatTime(inv.UpdatedAt),
+			Version:           inv.Version,
 		}
 		inventoryResponses = append(inventoryResponses, response)
 	}
@@ -257,10 +358,49 @@ func (s *inventoryService) GetInventorySummary(ctx context.Context) (*dto.Invent
 	}, nil
 }
 
+// ListProductsNeedingRestock lists active products' stock records that are out of stock or
+// at or below their reorder point, most urgent first
+func (s *inventoryService) ListProductsNeedingRestock(ctx context.Context, page, limit int) (*dto.ListRestockCandidatesResponse, error) {
+	page, limit = httpx.ClampPagination(page, limit)
+
+	candidates, total, err := s.inventoryRepo.ListProductsNeedingRestock(ctx, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products needing restock: %w", err)
+	}
+
+	items := make([]dto.RestockCandidateResponse, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, dto.RestockCandidateResponse{
+			InventoryID:       c.InventoryID,
+			ProductID:         c.ProductID,
+			ProductVariantID:  c.ProductVariantID,
+			ProductName:       c.ProductName,
+			SKU:               c.SKU,
+			AvailableQuantity: c.AvailableQuantity,
+			ReorderPoint:      c.ReorderPoint,
+			OutOfStock:        c.AvailableQuantity <= 0,
+		})
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &dto.ListRestockCandidatesResponse{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
 // Stock Movements
 
 // RecordStockMovement records a stock movement
 func (s *inventoryService) RecordStockMovement(ctx context.Context, req *dto.StockMovementRequest) (*domain.InventoryMovement, error) {
+	if err := checkMovementQuantity(req.MovementType, req.Quantity); err != nil {
+		return nil, err
+	}
+
 	// Get current inventory
 	inventory, err := s.inventoryRepo.GetInventoryByProduct(ctx, req.ProductID, req.ProductVariantID)
 	if err != nil {
@@ -311,7 +451,6 @@ func (s *inventoryService) RecordStockMovement(ctx context.Context, req *dto.Sto
 		ReferenceType:    getStringValue(req.ReferenceType),
 		Reason:           getStringValue(req.Reason),
 		Notes:            getStringValue(req.Notes),
-		CreatedBy:        getInt64Value(req.CreatedBy),
 		CreatedAt:        time.Now(),
 	}
 
@@ -320,11 +459,16 @@ func (s *inventoryService) RecordStockMovement(ctx context.Context, req *dto.Sto
 		return nil, fmt.Errorf("failed to record stock movement: %w", err)
 	}
 
+	s.publishStockChange(ctx, inventory)
+	s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+
 	return movement, nil
 }
 
 // GetStockMovements retrieves stock movements with filters
 func (s *inventoryService) GetStockMovements(ctx context.Context, req *dto.ListStockMovementsRequest) (*dto.ListStockMovementsResponse, error) {
+	req.Page, req.Limit = httpx.ClampPagination(req.Page, req.Limit)
+
 	// Convert DTO request to repository request
 	repoReq := &repository.ListStockMovementsRequest{
 		ProductID:        req.ProductID,
@@ -342,7 +486,7 @@ func (s *inventoryService) GetStockMovements(ctx context.Context, req *dto.ListS
 	}
 
 	// Convert domain models to DTOs
-	var movementResponses []dto.StockMovementResponse
+	movementResponses := make([]dto.StockMovementResponse, 0, len(movements))
 	for _, movement := range movements {
 		response := dto.StockMovementResponse{
 			ID:               movement.ID,
@@ -356,8 +500,8 @@ func (s *inventoryService) GetStockMovements(ctx context.Context, req *dto.ListS
 			ReferenceType:    movement.ReferenceType,
 			Reason:           movement.Reason,
 			Notes:            movement.Notes,
-			CreatedBy:        getInt64Pointer(movement.CreatedBy),
-			CreatedAt:        movement.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			CreatedBy:        movement.CreatedBy,
+			CreatedAt:        httpx.FormatTime(movement.CreatedAt),
 		}
 		movementResponses = append(movementResponses, response)
 	}
@@ -383,66 +527,122 @@ func (s *inventoryService) GetStockMovementByID(ctx context.Context, id int64) (
 	return movement, nil
 }
 
-// Stock Reservations
+// SetInventoryQuantity sets inventory to an exact counted quantity and logs the adjustment
+func (s *inventoryService) SetInventoryQuantity(ctx context.Context, req *dto.SetInventoryQuantityRequest) (*domain.InventoryMovement, error) {
+	if err := checkQuantity(req.Quantity, minStockQuantity); err != nil {
+		return nil, err
+	}
 
-// ReserveStock reserves stock for an order
-func (s *inventoryService) ReserveStock(ctx context.Context, req *dto.ReserveStockRequest) (*domain.StockReservation, error) {
-	// Parse expiration time
-	expiresAt, err := time.Parse("2006-01-02T15:04:05Z07:00", req.ExpiresAt)
+	movement, err := s.inventoryRepo.SetInventoryQuantity(ctx, req.ProductID, req.ProductVariantID, req.Quantity, req.Reason)
 	if err != nil {
-		return nil, fmt.Errorf("invalid expiration time format: %w", err)
+		return nil, fmt.Errorf("failed to set inventory quantity: %w", err)
 	}
 
-	// Get current inventory
-	inventory, err := s.inventoryRepo.GetInventoryByProduct(ctx, req.ProductID, req.ProductVariantID)
+	if movement.NewQuantity > movement.PreviousQuantity {
+		s.notifyBackInStock(ctx, req.ProductID, req.ProductVariantID)
+	}
+	if movement.NewQuantity != movement.PreviousQuantity {
+		s.publishStockLevel(ctx, req.ProductID, req.ProductVariantID)
+		s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+	}
+
+	return movement, nil
+}
+
+// Restock receives new stock for a product or variant and logs it as an "in" movement
+func (s *inventoryService) Restock(ctx context.Context, req *dto.RestockRequest) (*domain.InventoryMovement, error) {
+	if err := checkQuantity(req.Quantity, 1); err != nil {
+		return nil, err
+	}
+
+	movement, err := s.inventoryRepo.Restock(ctx, req.ProductID, req.ProductVariantID, req.Quantity, req.Reference)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get inventory: %w", err)
+		return nil, fmt.Errorf("failed to restock inventory: %w", err)
 	}
 
-	// Check if enough stock is available
-	if inventory.AvailableQuantity < req.Quantity {
-		return nil, fmt.Errorf("insufficient stock: requested %d, available %d", req.Quantity, inventory.AvailableQuantity)
+	s.notifyBackInStock(ctx, req.ProductID, req.ProductVariantID)
+	s.publishStockLevel(ctx, req.ProductID, req.ProductVariantID)
+	s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+
+	return movement, nil
+}
+
+// AdjustStock changes stock by a signed delta and logs it as an "in" or "out" movement,
+// atomically. Stock that is reserved cannot be adjusted away.
+func (s *inventoryService) AdjustStock(ctx context.Context, req *dto.AdjustStockRequest) (*domain.InventoryMovement, error) {
+	if req.Delta == 0 {
+		return nil, ErrZeroAdjustment
 	}
 
-	// Create reservation
+	movement, err := s.inventoryRepo.AdjustStock(ctx, req.ProductID, req.ProductVariantID, req.Delta, req.Reason, req.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust stock: %w", err)
+	}
+
+	if req.Delta > 0 {
+		s.notifyBackInStock(ctx, req.ProductID, req.ProductVariantID)
+	}
+	s.publishStockLevel(ctx, req.ProductID, req.ProductVariantID)
+	s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+
+	return movement, nil
+}
+
+// Stock Reservations
+
+// ReserveStock reserves stock for an order
+func (s *inventoryService) ReserveStock(ctx context.Context, req *dto.ReserveStockRequest) (*domain.StockReservation, error) {
+	now := s.now()
+
+	// Apply the default TTL when no expiry is given and never hold past the max TTL
+	expiresAt := now.Add(s.reservationPolicy.DefaultTTL)
+	if req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiration time format: %w", err)
+		}
+		if !parsed.After(now) {
+			return nil, fmt.Errorf("expiration time must be in the future")
+		}
+		expiresAt = parsed
+	}
+	if maxExpiry := now.Add(s.reservationPolicy.MaxTTL); expiresAt.After(maxExpiry) {
+		expiresAt = maxExpiry
+	}
+
+	// The repository checks availability and moves the units to reserved stock atomically
 	reservation := &domain.StockReservation{
 		ProductID:        req.ProductID,
 		ProductVariantID: req.ProductVariantID,
 		OrderID:          req.OrderID,
 		Quantity:         req.Quantity,
 		ExpiresAt:        expiresAt,
-		CreatedAt:        time.Now(),
+		CreatedAt:        now,
 	}
 
-	err = s.inventoryRepo.ReserveStock(ctx, reservation)
-	if err != nil {
+	if err := s.inventoryRepo.ReserveStock(ctx, reservation); err != nil {
 		return nil, fmt.Errorf("failed to reserve stock: %w", err)
 	}
 
-	// Update inventory
-	inventory.ReservedQuantity += req.Quantity
-	inventory.AvailableQuantity -= req.Quantity
-	inventory.UpdatedAt = time.Now()
-
-	err = s.inventoryRepo.UpdateInventory(ctx, inventory)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update inventory after reservation: %w", err)
-	}
+	s.publishStockLevel(ctx, req.ProductID, req.ProductVariantID)
+	s.publishEvent(ctx, domain.EventStockReserved, reservation)
 
 	return reservation, nil
 }
 
 // ReleaseStock releases reserved stock
 func (s *inventoryService) ReleaseStock(ctx context.Context, req *dto.ReleaseStockRequest) error {
+	var changes []*domain.StockChange
+	var err error
 	if req.ReservationID != nil {
 		// Release by reservation ID
-		err := s.inventoryRepo.ReleaseStock(ctx, *req.ReservationID)
+		changes, err = s.inventoryRepo.ReleaseStock(ctx, *req.ReservationID)
 		if err != nil {
 			return fmt.Errorf("failed to release stock by reservation ID: %w", err)
 		}
 	} else if req.OrderID != nil {
 		// Release by order ID
-		err := s.inventoryRepo.ReleaseStockByOrderID(ctx, *req.OrderID)
+		changes, err = s.inventoryRepo.ReleaseStockByOrderID(ctx, *req.OrderID)
 		if err != nil {
 			return fmt.Errorf("failed to release stock by order ID: %w", err)
 		}
@@ -450,6 +650,24 @@ func (s *inventoryService) ReleaseStock(ctx context.Context, req *dto.ReleaseSto
 		return fmt.Errorf("either reservation_id or order_id must be provided")
 	}
 
+	s.publishStockChanges(ctx, changes)
+	s.publishEvent(ctx, domain.EventStockReleased, domain.StockRelease{
+		ReservationID: req.ReservationID,
+		OrderID:       req.OrderID,
+	})
+
+	return nil
+}
+
+// ReleaseExpiredReservations hands the stock of lapsed reservations back to inventory
+// and publishes the stock now available for each item they held
+func (s *inventoryService) ReleaseExpiredReservations(ctx context.Context) error {
+	changes, err := s.inventoryRepo.CleanupExpiredReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.publishStockChanges(ctx, changes)
 	return nil
 }
 
@@ -463,6 +681,171 @@ func (s *inventoryService) GetStockReservations(ctx context.Context, orderID int
 	return reservations, nil
 }
 
+// ExtendReservation pushes a reservation's expiry forward, for example while a
+// checkout is still in progress. The new expiry is capped at the max TTL from creation.
+func (s *inventoryService) ExtendReservation(ctx context.Context, reservationID int64, extraDuration time.Duration) (*domain.StockReservation, error) {
+	if extraDuration <= 0 {
+		return nil, fmt.Errorf("extension must be positive")
+	}
+
+	reservation, err := s.inventoryRepo.GetStockReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock reservation: %w", err)
+	}
+
+	now := s.now()
+	if !reservation.ExpiresAt.After(now) {
+		return nil, ErrReservationExpired
+	}
+
+	expiresAt := reservation.ExpiresAt.Add(extraDuration)
+	if maxExpiry := reservation.CreatedAt.Add(s.reservationPolicy.MaxTTL); expiresAt.After(maxExpiry) {
+		expiresAt = maxExpiry
+	}
+
+	if expiresAt.After(reservation.ExpiresAt) {
+		err = s.inventoryRepo.UpdateReservationExpiry(ctx, reservationID, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend stock reservation: %w", err)
+		}
+		reservation.ExpiresAt = expiresAt
+	}
+
+	return reservation, nil
+}
+
+// Stock Notifications
+
+// SubscribeStockNotification asks to be notified when an out-of-stock item is available
+// again. userID is the signed-in subscriber, or nil for a guest subscribing by email.
+func (s *inventoryService) SubscribeStockNotification(ctx context.Context, userID *int64, req *dto.StockNotificationRequest) (*domain.StockNotification, error) {
+	email := strings.TrimSpace(req.Email)
+	if userID == nil && email == "" {
+		return nil, fmt.Errorf("either a signed-in user or email must be provided")
+	}
+
+	inventory, err := s.inventoryRepo.GetInventoryByProduct(ctx, req.ProductID, req.ProductVariantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+	if inventory.AvailableQuantity > 0 {
+		return nil, ErrProductInStock
+	}
+
+	notification := &domain.StockNotification{
+		UserID:           userID,
+		Email:            email,
+		ProductID:        req.ProductID,
+		ProductVariantID: req.ProductVariantID,
+		CreatedAt:        s.now(),
+	}
+
+	if err := s.inventoryRepo.CreateStockNotification(ctx, notification); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to stock notifications: %w", err)
+	}
+
+	return notification, nil
+}
+
+// UnsubscribeStockNotification cancels userID's stock notification subscription. Another
+// user's subscription is reported as not found.
+func (s *inventoryService) UnsubscribeStockNotification(ctx context.Context, id, userID int64) error {
+	if err := s.inventoryRepo.DeleteStockNotification(ctx, id, userID); err != nil {
+		return fmt.Errorf("failed to unsubscribe from stock notifications: %w", err)
+	}
+
+	return nil
+}
+
+// notifyBackInStock sends back-in-stock notifications to an item's pending subscribers
+// once it has stock available. Subscribers are marked notified before sending, so a
+// failed send is logged rather than retried and never fails the stock change.
+func (s *inventoryService) notifyBackInStock(ctx context.Context, productID int64, variantID *int64) {
+	if s.stockNotifier == nil {
+		return
+	}
+
+	notifications, err := s.inventoryRepo.ClaimStockNotifications(ctx, productID, variantID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to claim stock notifications",
+			"product_id", productID, "variant_id", variantID, "error", err)
+		return
+	}
+
+	for _, notification := range notifications {
+		if err := s.stockNotifier.SendBackInStock(ctx, notification); err != nil {
+			logger.FromContext(ctx).Warn("failed to send back in stock notification",
+				"notification_id", notification.ID, "product_id", productID, "error", err)
+		}
+	}
+}
+
+// publishStockChange reports an inventory record's available quantity to the stock
+// change publisher, if one is configured
+func (s *inventoryService) publishStockChange(ctx context.Context, inventory *domain.Inventory) {
+	if s.stockChanges == nil {
+		return
+	}
+
+	s.stockChanges.PublishStockChange(ctx, domain.StockChange{
+		ProductID:         inventory.ProductID,
+		ProductVariantID:  inventory.ProductVariantID,
+		AvailableQuantity: inventory.AvailableQuantity,
+		OccurredAt:        s.now(),
+	})
+}
+
+// publishStockChanges reports the available quantities the repository returned after
+// changing several items at once, such as releasing reservations
+func (s *inventoryService) publishStockChanges(ctx context.Context, changes []*domain.StockChange) {
+	if s.stockChanges == nil {
+		return
+	}
+
+	for _, change := range changes {
+		published := *change
+		published.OccurredAt = s.now()
+		s.stockChanges.PublishStockChange(ctx, published)
+	}
+}
+
+// publishStockLevel reloads an item's inventory after a change made in the repository and
+// publishes its available quantity. A failed lookup is logged and never fails the change.
+func (s *inventoryService) publishStockLevel(ctx context.Context, productID int64, variantID *int64) {
+	if s.stockChanges == nil {
+		return
+	}
+
+	inventory, err := s.inventoryRepo.GetInventoryByProduct(ctx, productID, variantID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to load inventory for stock change",
+			"product_id", productID, "variant_id", variantID, "error", err)
+		return
+	}
+
+	s.publishStockChange(ctx, inventory)
+}
+
+// publishEvent reports an inventory event to the event publisher, if one is configured
+func (s *inventoryService) publishEvent(ctx context.Context, eventType string, data interface{}) {
+	if s.events == nil {
+		return
+	}
+
+	s.events.PublishEvent(ctx, eventType, data)
+}
+
+// Checkout
+
+// PublishCommittedStock reports the stock an order took at checkout to stock level
+// subscribers and as inventory events
+func (s *inventoryService) PublishCommittedStock(ctx context.Context, movements []*domain.InventoryMovement) {
+	for _, movement := range movements {
+		s.publishStockLevel(ctx, movement.ProductID, movement.ProductVariantID)
+		s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+	}
+}
+
 // Inventory Alerts
 
 // GetInventoryAlerts gets inventory alerts
@@ -485,23 +868,72 @@ func (s *inventoryService) ResolveInventoryAlert(ctx context.Context, alertID in
 	return nil
 }
 
-// CheckLowStockAlerts checks for low stock and creates alerts
+// CheckLowStockAlerts raises alerts for items low on or out of stock, resolves those that
+// have recovered and publishes an event for each alert raised
 func (s *inventoryService) CheckLowStockAlerts(ctx context.Context) error {
-	err := s.inventoryRepo.CheckLowStockAlerts(ctx)
+	alerts, err := s.inventoryRepo.CheckLowStockAlerts(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check low stock alerts: %w", err)
 	}
 
+	for _, alert := range alerts {
+		s.publishEvent(ctx, domain.EventLowStockAlert, alert)
+	}
+
 	return nil
 }
 
+// Consistency
+
+// RecomputeAvailable recalculates a product's reserved and available stock from its
+// reservations, correcting any record that had drifted, and returns the corrections
+func (s *inventoryService) RecomputeAvailable(ctx context.Context, productID int64) ([]*domain.InventoryDiscrepancy, error) {
+	discrepancies, err := s.inventoryRepo.RecomputeReservedQuantities(ctx, &productID)
+	if err != nil {
+		return nil, err
+	}
+
+	logDiscrepancies(ctx, discrepancies)
+	return discrepancies, nil
+}
+
+// RepairAvailableQuantities recomputes the reserved and available stock of every
+// product. It is run periodically to undo drift between the counters and reservations.
+func (s *inventoryService) RepairAvailableQuantities(ctx context.Context) error {
+	discrepancies, err := s.inventoryRepo.RecomputeReservedQuantities(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	logDiscrepancies(ctx, discrepancies)
+	return nil
+}
+
+func logDiscrepancies(ctx context.Context, discrepancies []*domain.InventoryDiscrepancy) {
+	for _, d := range discrepancies {
+		logger.FromContext(ctx).Warn("corrected drifted inventory counts",
+			"inventory_id", d.InventoryID,
+			"product_id", d.ProductID,
+			"product_variant_id", d.ProductVariantID,
+			"reserved_quantity", d.PreviousReservedQuantity,
+			"corrected_reserved_quantity", d.ReservedQuantity,
+			"available_quantity", d.PreviousAvailableQuantity,
+			"corrected_available_quantity", d.AvailableQuantity,
+		)
+	}
+}
+
 // Bulk Operations
 
-// BulkUpdateStock performs bulk stock updates
+// BulkUpdateStock applies a batch of stock updates. Failed items are reported in the
+// response; with req.Atomic set any failure leaves all stock unchanged.
 func (s *inventoryService) BulkUpdateStock(ctx context.Context, req *dto.BulkStockUpdateRequest) (*dto.BulkStockUpdateResponse, error) {
 	// Convert DTO items to repository items
 	var repoUpdates []repository.StockUpdateItem
 	for _, item := range req.Updates {
+		if err := checkMovementQuantity(item.MovementType, item.Quantity); err != nil {
+			return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+		}
 		repoUpdates = append(repoUpdates, repository.StockUpdateItem{
 			ProductID:        item.ProductID,
 			ProductVariantID: item.ProductVariantID,
@@ -512,11 +944,21 @@ func (s *inventoryService) BulkUpdateStock(ctx context.Context, req *dto.BulkSto
 		})
 	}
 
-	response, err := s.inventoryRepo.BulkUpdateStock(ctx, repoUpdates)
+	response, err := s.inventoryRepo.BulkUpdateStock(ctx, repoUpdates, req.Atomic)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform bulk stock update: %w", err)
 	}
 
+	for _, movement := range response.Movements {
+		if movement.NewQuantity > movement.PreviousQuantity {
+			s.notifyBackInStock(ctx, movement.ProductID, movement.ProductVariantID)
+		}
+		if movement.NewQuantity != movement.PreviousQuantity {
+			s.publishStockLevel(ctx, movement.ProductID, movement.ProductVariantID)
+			s.publishEvent(ctx, domain.EventInventoryAdjusted, movement)
+		}
+	}
+
 	// Convert repository response to DTO response
 	dtoResponse := &dto.BulkStockUpdateResponse{
 		UpdatedItems: response.UpdatedItems,
@@ -556,17 +998,3 @@ func getIntPointer(i int) *int {
 	}
 	return &i
 }
-
-func getInt64Value(i *int64) int64 {
-	if i == nil {
-		return 0
-	}
-	return *i
-}
-
-func getInt64Pointer(i int64) *int64 {
-	if i == 0 {
-		return nil
-	}
-	return &i
-}
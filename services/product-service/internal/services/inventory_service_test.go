@@ -0,0 +1,787 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockInventoryRepository is a mock implementation of InventoryRepository.
+// Methods not overridden here fall through to the embedded nil interface.
+type MockInventoryRepository struct {
+	mock.Mock
+	repository.InventoryRepository
+}
+
+func (m *MockInventoryRepository) CreateInventory(ctx context.Context, inventory *domain.Inventory) error {
+	args := m.Called(ctx, inventory)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) GetInventoryByProduct(ctx context.Context, productID int64, variantID *int64) (*domain.Inventory, error) {
+	args := m.Called(ctx, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryRepository) RecordStockMovement(ctx context.Context, movement *domain.InventoryMovement) error {
+	args := m.Called(ctx, movement)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) UpdateInventory(ctx context.Context, inventory *domain.Inventory) error {
+	args := m.Called(ctx, inventory)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) ReserveStock(ctx context.Context, reservation *domain.StockReservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) GetStockReservationByID(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+
+func (m *MockInventoryRepository) UpdateReservationExpiry(ctx context.Context, id int64, expiresAt time.Time) error {
+	args := m.Called(ctx, id, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) ListInventory(ctx context.Context, req *repository.ListInventoryRequest) ([]*domain.Inventory, int64, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Inventory), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInventoryRepository) GetStockMovements(ctx context.Context, req *repository.ListStockMovementsRequest) ([]*domain.InventoryMovement, int64, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.InventoryMovement), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockInventoryRepository) Restock(ctx context.Context, productID int64, variantID *int64, qty int, reference string) (*domain.InventoryMovement, error) {
+	args := m.Called(ctx, productID, variantID, qty, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InventoryMovement), args.Error(1)
+}
+
+func (m *MockInventoryRepository) AdjustStock(ctx context.Context, productID int64, variantID *int64, delta int, reason, reference string) (*domain.InventoryMovement, error) {
+	args := m.Called(ctx, productID, variantID, delta, reason, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InventoryMovement), args.Error(1)
+}
+
+func (m *MockInventoryRepository) BulkUpdateStock(ctx context.Context, updates []repository.StockUpdateItem, atomic bool) (*repository.BulkStockUpdateResponse, error) {
+	args := m.Called(ctx, updates, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.BulkStockUpdateResponse), args.Error(1)
+}
+
+func (m *MockInventoryRepository) CreateStockNotification(ctx context.Context, notification *domain.StockNotification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) ClaimStockNotifications(ctx context.Context, productID int64, variantID *int64) ([]*domain.StockNotification, error) {
+	args := m.Called(ctx, productID, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockNotification), args.Error(1)
+}
+
+func (m *MockInventoryRepository) ReleaseStock(ctx context.Context, reservationID int64) ([]*domain.StockChange, error) {
+	args := m.Called(ctx, reservationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockChange), args.Error(1)
+}
+
+func (m *MockInventoryRepository) ReleaseStockByOrderID(ctx context.Context, orderID int64) ([]*domain.StockChange, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockChange), args.Error(1)
+}
+
+func (m *MockInventoryRepository) CleanupExpiredReservations(ctx context.Context) ([]*domain.StockChange, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StockChange), args.Error(1)
+}
+
+func (m *MockInventoryRepository) CheckLowStockAlerts(ctx context.Context) ([]*domain.InventoryAlert, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InventoryAlert), args.Error(1)
+}
+
+func (m *MockInventoryRepository) ListProductsNeedingRestock(ctx context.Context, page, limit int) ([]*domain.RestockCandidate, int64, error) {
+	args := m.Called(ctx, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.RestockCandidate), args.Get(1).(int64), args.Error(2)
+}
+
+// MockStockNotifier is a mock implementation of StockNotifier
+type MockStockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockStockNotifier) SendBackInStock(ctx context.Context, notification *domain.StockNotification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+type MockStockChangePublisher struct {
+	mock.Mock
+}
+
+func (m *MockStockChangePublisher) PublishStockChange(ctx context.Context, change domain.StockChange) {
+	m.Called(ctx, change)
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) PublishEvent(ctx context.Context, eventType string, data interface{}) {
+	m.Called(ctx, eventType, data)
+}
+
+func TestInventoryService_CreateInventory(t *testing.T) {
+	req := &dto.CreateInventoryRequest{ProductID: 1, Quantity: 10}
+
+	t.Run("creates inventory", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		productRepo := new(MockProductRepository)
+
+		productRepo.On("GetProductByID", mock.Anything, int64(1)).Return(&domain.Product{ID: 1}, nil)
+		inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).Return(nil, repository.ErrInventoryNotFound)
+		inventoryRepo.On("CreateInventory", mock.Anything, mock.AnythingOfType("*domain.Inventory")).Return(nil)
+		inventoryRepo.On("RecordStockMovement", mock.Anything, mock.AnythingOfType("*domain.InventoryMovement")).Return(nil)
+
+		service := NewInventoryService(inventoryRepo, productRepo, ReservationPolicy{}, nil, nil, nil)
+		inventory, err := service.CreateInventory(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, inventory.AvailableQuantity)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("duplicate create returns ErrInventoryExists", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		productRepo := new(MockProductRepository)
+
+		productRepo.On("GetProductByID", mock.Anything, int64(1)).Return(&domain.Product{ID: 1}, nil)
+		inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).Return(&domain.Inventory{ID: 5, ProductID: 1}, nil)
+
+		service := NewInventoryService(inventoryRepo, productRepo, ReservationPolicy{}, nil, nil, nil)
+		inventory, err := service.CreateInventory(context.Background(), req)
+
+		assert.Nil(t, inventory)
+		assert.ErrorIs(t, err, repository.ErrInventoryExists)
+		inventoryRepo.AssertNotCalled(t, "CreateInventory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent duplicate caught by unique index", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		productRepo := new(MockProductRepository)
+
+		productRepo.On("GetProductByID", mock.Anything, int64(1)).Return(&domain.Product{ID: 1}, nil)
+		inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).Return(nil, repository.ErrInventoryNotFound)
+		inventoryRepo.On("CreateInventory", mock.Anything, mock.Anything).Return(repository.ErrInventoryExists)
+
+		service := NewInventoryService(inventoryRepo, productRepo, ReservationPolicy{}, nil, nil, nil)
+		_, err := service.CreateInventory(context.Background(), req)
+
+		assert.ErrorIs(t, err, repository.ErrInventoryExists)
+		inventoryRepo.AssertNotCalled(t, "RecordStockMovement", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_RejectsNegativeQuantities(t *testing.T) {
+	ctx := context.Background()
+	inventoryRepo := new(MockInventoryRepository)
+	service := NewInventoryService(inventoryRepo, new(MockProductRepository), ReservationPolicy{}, nil, nil, nil)
+
+	negative := -3
+	_, createErr := service.CreateInventory(ctx, &dto.CreateInventoryRequest{ProductID: 1, Quantity: negative})
+	_, updateErr := service.UpdateInventory(ctx, 1, &dto.UpdateInventoryRequest{Quantity: &negative})
+	_, setErr := service.SetInventoryQuantity(ctx, &dto.SetInventoryQuantityRequest{ProductID: 1, Quantity: negative})
+	_, inErr := service.RecordStockMovement(ctx, &dto.StockMovementRequest{ProductID: 1, MovementType: "in", Quantity: negative})
+
+	for _, err := range []error{createErr, updateErr, setErr, inErr} {
+		var quantityErr *QuantityError
+		assert.ErrorAs(t, err, &quantityErr)
+	}
+	inventoryRepo.AssertNotCalled(t, "UpdateInventory", mock.Anything, mock.Anything)
+}
+
+func newTestInventoryService(inventoryRepo *MockInventoryRepository, now time.Time) *inventoryService {
+	policy := ReservationPolicy{DefaultTTL: 15 * time.Minute, MaxTTL: time.Hour}
+	service := NewInventoryService(inventoryRepo, new(MockProductRepository), policy, nil, nil, nil).(*inventoryService)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestInventoryService_ReserveStock_TTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	expectReservation := func(inventoryRepo *MockInventoryRepository) {
+		inventoryRepo.On("ReserveStock", mock.Anything, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
+	}
+
+	t.Run("applies default TTL when expiry omitted", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		expectReservation(inventoryRepo)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		reservation, err := service.ReserveStock(context.Background(), &dto.ReserveStockRequest{ProductID: 1, OrderID: 9, Quantity: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(15*time.Minute), reservation.ExpiresAt)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("caps expiry at max TTL", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		expectReservation(inventoryRepo)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		req := &dto.ReserveStockRequest{ProductID: 1, OrderID: 9, Quantity: 2, ExpiresAt: now.Add(24 * time.Hour).Format(time.RFC3339)}
+		reservation, err := service.ReserveStock(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), reservation.ExpiresAt)
+	})
+
+	t.Run("rejects expiry in the past", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		req := &dto.ReserveStockRequest{ProductID: 1, OrderID: 9, Quantity: 2, ExpiresAt: now.Add(-time.Minute).Format(time.RFC3339)}
+		_, err := service.ReserveStock(context.Background(), req)
+
+		assert.Error(t, err)
+		inventoryRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything)
+	})
+}
+
+// driftedStock is an inventory repository holding one stock record and the reservations
+// against it in memory, so its counters can be seeded out of step with the reservations
+type driftedStock struct {
+	*MockInventoryRepository
+
+	inventory    domain.Inventory
+	reservations []domain.StockReservation
+}
+
+func (r *driftedStock) RecomputeReservedQuantities(ctx context.Context, productID *int64) ([]*domain.InventoryDiscrepancy, error) {
+	if productID != nil && *productID != r.inventory.ProductID {
+		return []*domain.InventoryDiscrepancy{}, nil
+	}
+
+	reserved := 0
+	for _, reservation := range r.reservations {
+		reserved += reservation.Quantity
+	}
+	if reserved == r.inventory.ReservedQuantity && r.inventory.AvailableQuantity == r.inventory.Quantity-reserved {
+		return []*domain.InventoryDiscrepancy{}, nil
+	}
+
+	discrepancy := &domain.InventoryDiscrepancy{
+		InventoryID:               r.inventory.ID,
+		ProductID:                 r.inventory.ProductID,
+		Quantity:                  r.inventory.Quantity,
+		PreviousReservedQuantity:  r.inventory.ReservedQuantity,
+		PreviousAvailableQuantity: r.inventory.AvailableQuantity,
+		ReservedQuantity:          reserved,
+		AvailableQuantity:         r.inventory.Quantity - reserved,
+	}
+	r.inventory.ReservedQuantity = discrepancy.ReservedQuantity
+	r.inventory.AvailableQuantity = discrepancy.AvailableQuantity
+	return []*domain.InventoryDiscrepancy{discrepancy}, nil
+}
+
+func TestInventoryService_RecomputeAvailable(t *testing.T) {
+	ctx := context.Background()
+	newStock := func() *driftedStock {
+		// A released reservation of 4 was never taken off the counters
+		return &driftedStock{
+			MockInventoryRepository: new(MockInventoryRepository),
+			inventory:               domain.Inventory{ID: 5, ProductID: 1, Quantity: 10, ReservedQuantity: 6, AvailableQuantity: 4},
+			reservations:            []domain.StockReservation{{ID: 1, ProductID: 1, Quantity: 2}},
+		}
+	}
+
+	t.Run("corrects a drifted product", func(t *testing.T) {
+		stock := newStock()
+		service := NewInventoryService(stock, new(MockProductRepository), ReservationPolicy{}, nil, nil, nil)
+
+		discrepancies, err := service.RecomputeAvailable(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, discrepancies, 1)
+		assert.Equal(t, 6, discrepancies[0].PreviousReservedQuantity)
+		assert.Equal(t, 2, stock.inventory.ReservedQuantity)
+		assert.Equal(t, 8, stock.inventory.AvailableQuantity)
+
+		// A second pass has nothing left to fix
+		discrepancies, err = service.RecomputeAvailable(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, discrepancies)
+	})
+
+	t.Run("repair pass covers every product", func(t *testing.T) {
+		stock := newStock()
+		service := NewInventoryService(stock, new(MockProductRepository), ReservationPolicy{}, nil, nil, nil)
+
+		require.NoError(t, service.RepairAvailableQuantities(ctx))
+
+		assert.Equal(t, 2, stock.inventory.ReservedQuantity)
+		assert.Equal(t, 8, stock.inventory.AvailableQuantity)
+	})
+}
+
+func TestInventoryService_ExtendReservation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("pushes expiry forward", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		reservation := &domain.StockReservation{ID: 5, CreatedAt: now.Add(-10 * time.Minute), ExpiresAt: now.Add(5 * time.Minute)}
+		inventoryRepo.On("GetStockReservationByID", mock.Anything, int64(5)).Return(reservation, nil)
+		inventoryRepo.On("UpdateReservationExpiry", mock.Anything, int64(5), now.Add(15*time.Minute)).Return(nil)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		extended, err := service.ExtendReservation(context.Background(), 5, 10*time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(15*time.Minute), extended.ExpiresAt)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("caps extension at max TTL from creation", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		createdAt := now.Add(-50 * time.Minute)
+		reservation := &domain.StockReservation{ID: 5, CreatedAt: createdAt, ExpiresAt: now.Add(5 * time.Minute)}
+		inventoryRepo.On("GetStockReservationByID", mock.Anything, int64(5)).Return(reservation, nil)
+		inventoryRepo.On("UpdateReservationExpiry", mock.Anything, int64(5), createdAt.Add(time.Hour)).Return(nil)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		extended, err := service.ExtendReservation(context.Background(), 5, 30*time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt.Add(time.Hour), extended.ExpiresAt)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects expired reservation", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		reservation := &domain.StockReservation{ID: 5, CreatedAt: now.Add(-20 * time.Minute), ExpiresAt: now.Add(-time.Minute)}
+		inventoryRepo.On("GetStockReservationByID", mock.Anything, int64(5)).Return(reservation, nil)
+
+		service := newTestInventoryService(inventoryRepo, now)
+		_, err := service.ExtendReservation(context.Background(), 5, 10*time.Minute)
+
+		assert.ErrorIs(t, err, ErrReservationExpired)
+		inventoryRepo.AssertNotCalled(t, "UpdateReservationExpiry", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_EmptyListsSerializeAsArrays(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("list inventory", func(t *testing.T) {
+		mockRepo := new(MockInventoryRepository)
+		service := NewInventoryService(mockRepo, nil, ReservationPolicy{}, nil, nil, nil)
+
+		mockRepo.On("ListInventory", ctx, mock.AnythingOfType("*repository.ListInventoryRequest")).Return(nil, int64(0), nil)
+
+		result, err := service.ListInventory(ctx, &dto.ListInventoryRequest{})
+
+		assert.NoError(t, err)
+		body, err := json.Marshal(result)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"inventory":[]`)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stock movements", func(t *testing.T) {
+		mockRepo := new(MockInventoryRepository)
+		service := NewInventoryService(mockRepo, nil, ReservationPolicy{}, nil, nil, nil)
+
+		mockRepo.On("GetStockMovements", ctx, mock.AnythingOfType("*repository.ListStockMovementsRequest")).Return(nil, int64(0), nil)
+
+		result, err := service.GetStockMovements(ctx, &dto.ListStockMovementsRequest{})
+
+		assert.NoError(t, err)
+		body, err := json.Marshal(result)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"movements":[]`)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestInventoryService_ListProductsNeedingRestock(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockInventoryRepository)
+	service := NewInventoryService(mockRepo, nil, ReservationPolicy{}, nil, nil, nil)
+
+	brakePadsID, chainID := int64(3), int64(4)
+	mockRepo.On("ListProductsNeedingRestock", ctx, 2, 2).Return([]*domain.RestockCandidate{
+		{InventoryID: &brakePadsID, ProductID: 30, ProductName: "Brake Pads", SKU: "BP-1", AvailableQuantity: 0, ReorderPoint: 5},
+		{InventoryID: &chainID, ProductID: 40, ProductName: "Chain", SKU: "CH-1", AvailableQuantity: 2, ReorderPoint: 10},
+	}, int64(5), nil)
+
+	result, err := service.ListProductsNeedingRestock(ctx, 2, 2)
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.True(t, result.Items[0].OutOfStock)
+	assert.False(t, result.Items[1].OutOfStock)
+	assert.Equal(t, "CH-1", result.Items[1].SKU)
+	assert.Equal(t, int64(5), result.Total)
+	assert.Equal(t, 3, result.TotalPages)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInventoryService_SubscribeStockNotification(t *testing.T) {
+	userID := int64(42)
+
+	t.Run("subscribes while out of stock", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).
+			Return(&domain.Inventory{ID: 5, ProductID: 1, AvailableQuantity: 0}, nil)
+		inventoryRepo.On("CreateStockNotification", mock.Anything, mock.AnythingOfType("*domain.StockNotification")).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(*domain.StockNotification).ID = 9
+			}).
+			Return(nil)
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, nil)
+		notification, err := service.SubscribeStockNotification(context.Background(), &userID, &dto.StockNotificationRequest{
+			ProductID: 1,
+			Email:     " shopper@example.com ",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), notification.ID)
+		assert.Equal(t, &userID, notification.UserID)
+		assert.Equal(t, "shopper@example.com", notification.Email)
+		assert.Nil(t, notification.NotifiedAt)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects items that are in stock", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).
+			Return(&domain.Inventory{ID: 5, ProductID: 1, AvailableQuantity: 3}, nil)
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, nil)
+		_, err := service.SubscribeStockNotification(context.Background(), &userID, &dto.StockNotificationRequest{ProductID: 1})
+
+		assert.ErrorIs(t, err, ErrProductInStock)
+		inventoryRepo.AssertNotCalled(t, "CreateStockNotification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a user or email", func(t *testing.T) {
+		service := NewInventoryService(new(MockInventoryRepository), nil, ReservationPolicy{}, nil, nil, nil)
+		_, err := service.SubscribeStockNotification(context.Background(), nil, &dto.StockNotificationRequest{ProductID: 1})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInventoryService_Restock_NotifiesSubscribersOnce(t *testing.T) {
+	userID := int64(42)
+	pending := []*domain.StockNotification{
+		{ID: 1, UserID: &userID, ProductID: 1},
+		{ID: 2, Email: "guest@example.com", ProductID: 1},
+	}
+
+	inventoryRepo := new(MockInventoryRepository)
+	inventoryRepo.On("Restock", mock.Anything, int64(1), (*int64)(nil), 5, "PO-1").
+		Return(&domain.InventoryMovement{ProductID: 1, MovementType: "in", Quantity: 5, NewQuantity: 5}, nil)
+	// The repository hands pending subscribers out once; later claims find none
+	inventoryRepo.On("ClaimStockNotifications", mock.Anything, int64(1), (*int64)(nil)).Return(pending, nil).Once()
+	inventoryRepo.On("ClaimStockNotifications", mock.Anything, int64(1), (*int64)(nil)).Return([]*domain.StockNotification{}, nil)
+
+	notifier := new(MockStockNotifier)
+	notifier.On("SendBackInStock", mock.Anything, mock.AnythingOfType("*domain.StockNotification")).Return(nil)
+
+	service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, notifier, nil, nil)
+	req := &dto.RestockRequest{ProductID: 1, Quantity: 5, Reference: "PO-1"}
+
+	_, err := service.Restock(context.Background(), req)
+	assert.NoError(t, err)
+	_, err = service.Restock(context.Background(), req)
+	assert.NoError(t, err)
+
+	notifier.AssertNumberOfCalls(t, "SendBackInStock", 2)
+	notifier.AssertCalled(t, "SendBackInStock", mock.Anything, pending[0])
+	notifier.AssertCalled(t, "SendBackInStock", mock.Anything, pending[1])
+	inventoryRepo.AssertNumberOfCalls(t, "ClaimStockNotifications", 2)
+}
+
+func TestInventoryService_Restock_PublishesStockChange(t *testing.T) {
+	variantID := int64(3)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	inventoryRepo := new(MockInventoryRepository)
+	inventoryRepo.On("Restock", mock.Anything, int64(1), &variantID, 5, "PO-1").
+		Return(&domain.InventoryMovement{ProductID: 1, ProductVariantID: &variantID, MovementType: "in", Quantity: 5, NewQuantity: 7}, nil)
+	inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), &variantID).
+		Return(&domain.Inventory{ProductID: 1, ProductVariantID: &variantID, Quantity: 7, ReservedQuantity: 2, AvailableQuantity: 5}, nil)
+
+	publisher := new(MockStockChangePublisher)
+	publisher.On("PublishStockChange", mock.Anything, mock.Anything).Return()
+
+	service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, publisher, nil).(*inventoryService)
+	service.now = func() time.Time { return now }
+
+	_, err := service.Restock(context.Background(), &dto.RestockRequest{ProductID: 1, ProductVariantID: &variantID, Quantity: 5, Reference: "PO-1"})
+
+	assert.NoError(t, err)
+	publisher.AssertCalled(t, "PublishStockChange", mock.Anything, domain.StockChange{
+		ProductID:         1,
+		ProductVariantID:  &variantID,
+		AvailableQuantity: 5,
+		OccurredAt:        now,
+	})
+}
+
+func TestInventoryService_ReleasingReservationsPublishesStockChanges(t *testing.T) {
+	variantID := int64(3)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	released := []*domain.StockChange{
+		{ProductID: 1, AvailableQuantity: 4},
+		{ProductID: 2, ProductVariantID: &variantID, AvailableQuantity: 9},
+	}
+
+	newService := func(inventoryRepo *MockInventoryRepository, publisher *MockStockChangePublisher) InventoryService {
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, publisher, nil).(*inventoryService)
+		service.now = func() time.Time { return now }
+		return service
+	}
+	assertPublished := func(t *testing.T, publisher *MockStockChangePublisher) {
+		publisher.AssertNumberOfCalls(t, "PublishStockChange", 2)
+		publisher.AssertCalled(t, "PublishStockChange", mock.Anything, domain.StockChange{ProductID: 1, AvailableQuantity: 4, OccurredAt: now})
+		publisher.AssertCalled(t, "PublishStockChange", mock.Anything, domain.StockChange{ProductID: 2, ProductVariantID: &variantID, AvailableQuantity: 9, OccurredAt: now})
+	}
+
+	t.Run("released by order", func(t *testing.T) {
+		orderID := int64(9)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("ReleaseStockByOrderID", mock.Anything, orderID).Return(released, nil)
+		publisher := new(MockStockChangePublisher)
+		publisher.On("PublishStockChange", mock.Anything, mock.Anything).Return()
+
+		err := newService(inventoryRepo, publisher).ReleaseStock(context.Background(), &dto.ReleaseStockRequest{OrderID: &orderID})
+
+		require.NoError(t, err)
+		assertPublished(t, publisher)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CleanupExpiredReservations", mock.Anything).Return(released, nil)
+		publisher := new(MockStockChangePublisher)
+		publisher.On("PublishStockChange", mock.Anything, mock.Anything).Return()
+
+		err := newService(inventoryRepo, publisher).ReleaseExpiredReservations(context.Background())
+
+		require.NoError(t, err)
+		assertPublished(t, publisher)
+	})
+
+	t.Run("failed cleanup publishes nothing", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CleanupExpiredReservations", mock.Anything).Return(nil, errors.New("connection reset"))
+		publisher := new(MockStockChangePublisher)
+
+		err := newService(inventoryRepo, publisher).ReleaseExpiredReservations(context.Background())
+
+		assert.Error(t, err)
+		publisher.AssertNotCalled(t, "PublishStockChange", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_AdjustStock(t *testing.T) {
+	t.Run("publishes the adjustment", func(t *testing.T) {
+		movement := &domain.InventoryMovement{ProductID: 1, MovementType: "out", Quantity: 2, PreviousQuantity: 10, NewQuantity: 8}
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("AdjustStock", mock.Anything, int64(1), (*int64)(nil), -2, "damaged", "").Return(movement, nil)
+		events := new(MockEventPublisher)
+		events.On("PublishEvent", mock.Anything, domain.EventInventoryAdjusted, movement).Return()
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, events)
+		got, err := service.AdjustStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Delta: -2, Reason: "damaged"})
+
+		require.NoError(t, err)
+		assert.Equal(t, movement, got)
+		events.AssertExpectations(t)
+		// Stock went down, so nobody is told it is back
+		inventoryRepo.AssertNotCalled(t, "ClaimStockNotifications", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an oversell", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("AdjustStock", mock.Anything, int64(1), (*int64)(nil), -7, "damaged", "").
+			Return(nil, &repository.ReservedStockConflictError{Requested: 3, Reserved: 4})
+		events := new(MockEventPublisher)
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, events)
+		movement, err := service.AdjustStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Delta: -7, Reason: "damaged"})
+
+		assert.Nil(t, movement)
+		var conflictErr *repository.ReservedStockConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		events.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a zero delta", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, nil)
+		_, err := service.AdjustStock(context.Background(), &dto.AdjustStockRequest{ProductID: 1, Reason: "recount"})
+
+		assert.ErrorIs(t, err, ErrZeroAdjustment)
+		inventoryRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_PublishesEvents(t *testing.T) {
+	t.Run("stock reserved", func(t *testing.T) {
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("ReserveStock", mock.Anything, mock.AnythingOfType("*domain.StockReservation")).Return(nil)
+		events := new(MockEventPublisher)
+		events.On("PublishEvent", mock.Anything, mock.Anything, mock.Anything).Return()
+
+		service := newTestInventoryService(inventoryRepo, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		service.events = events
+		reservation, err := service.ReserveStock(context.Background(), &dto.ReserveStockRequest{ProductID: 1, OrderID: 9, Quantity: 2})
+
+		require.NoError(t, err)
+		events.AssertCalled(t, "PublishEvent", mock.Anything, domain.EventStockReserved, reservation)
+	})
+
+	t.Run("stock released", func(t *testing.T) {
+		orderID := int64(9)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("ReleaseStockByOrderID", mock.Anything, orderID).Return([]*domain.StockChange{}, nil)
+		events := new(MockEventPublisher)
+		events.On("PublishEvent", mock.Anything, mock.Anything, mock.Anything).Return()
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, events)
+		err := service.ReleaseStock(context.Background(), &dto.ReleaseStockRequest{OrderID: &orderID})
+
+		require.NoError(t, err)
+		events.AssertCalled(t, "PublishEvent", mock.Anything, domain.EventStockReleased, domain.StockRelease{OrderID: &orderID})
+	})
+
+	t.Run("one event per alert raised", func(t *testing.T) {
+		alerts := []*domain.InventoryAlert{
+			{ID: 1, ProductID: 1, AlertType: domain.AlertTypeLowStock},
+			{ID: 2, ProductID: 2, AlertType: domain.AlertTypeOutOfStock},
+		}
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CheckLowStockAlerts", mock.Anything).Return(alerts, nil)
+		events := new(MockEventPublisher)
+		events.On("PublishEvent", mock.Anything, mock.Anything, mock.Anything).Return()
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, events)
+		err := service.CheckLowStockAlerts(context.Background())
+
+		require.NoError(t, err)
+		events.AssertNumberOfCalls(t, "PublishEvent", 2)
+		events.AssertCalled(t, "PublishEvent", mock.Anything, domain.EventLowStockAlert, alerts[0])
+		events.AssertCalled(t, "PublishEvent", mock.Anything, domain.EventLowStockAlert, alerts[1])
+	})
+
+	t.Run("failed release publishes nothing", func(t *testing.T) {
+		reservationID := int64(5)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("ReleaseStock", mock.Anything, reservationID).Return(nil, errors.New("stock reservation with ID 5 not found"))
+		events := new(MockEventPublisher)
+
+		service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, nil, events)
+		err := service.ReleaseStock(context.Background(), &dto.ReleaseStockRequest{ReservationID: &reservationID})
+
+		assert.Error(t, err)
+		events.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_BulkUpdateStock(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	req := &dto.BulkStockUpdateRequest{Updates: []dto.StockUpdateItem{
+		{ProductID: 1, Quantity: 5, MovementType: "in", Reason: "delivery"},
+		{ProductID: 99, Quantity: 3, MovementType: "out", Reason: "damaged"},
+	}}
+
+	inventoryRepo := new(MockInventoryRepository)
+	inventoryRepo.On("BulkUpdateStock", mock.Anything, mock.AnythingOfType("[]repository.StockUpdateItem"), false).
+		Return(&repository.BulkStockUpdateResponse{
+			UpdatedItems: 1,
+			FailedItems:  []repository.FailedStockUpdateItem{{ProductID: 99, Error: "inventory for product 99 not found"}},
+			Movements:    []*domain.InventoryMovement{{ProductID: 1, MovementType: "in", Quantity: 5, PreviousQuantity: 10, NewQuantity: 15}},
+		}, nil)
+	inventoryRepo.On("GetInventoryByProduct", mock.Anything, int64(1), (*int64)(nil)).
+		Return(&domain.Inventory{ProductID: 1, Quantity: 15, ReservedQuantity: 2, AvailableQuantity: 13}, nil)
+
+	publisher := new(MockStockChangePublisher)
+	publisher.On("PublishStockChange", mock.Anything, mock.Anything).Return()
+
+	service := NewInventoryService(inventoryRepo, nil, ReservationPolicy{}, nil, publisher, nil).(*inventoryService)
+	service.now = func() time.Time { return now }
+
+	response, err := service.BulkUpdateStock(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, int64(1), response.UpdatedItems)
+	require.Len(t, response.FailedItems, 1)
+	assert.Equal(t, int64(99), response.FailedItems[0].ProductID)
+	// Only the committed item is published
+	publisher.AssertNumberOfCalls(t, "PublishStockChange", 1)
+	publisher.AssertCalled(t, "PublishStockChange", mock.Anything, domain.StockChange{
+		ProductID:         1,
+		AvailableQuantity: 13,
+		OccurredAt:        now,
+	})
+	inventoryRepo.AssertNotCalled(t, "GetInventoryByProduct", mock.Anything, int64(99), mock.Anything)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/cache"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
+)
+
+// cachedProductService serves product reads by ID and SKU from a cache and invalidates
+// entries when a product changes. All other methods go straight to the wrapped service.
+type cachedProductService struct {
+	ProductService
+	cache cache.Cache[*domain.Product]
+}
+
+// NewCachedProductService wraps a product service with a read cache
+func NewCachedProductService(inner ProductService, productCache cache.Cache[*domain.Product]) ProductService {
+	return &cachedProductService{
+		ProductService: inner,
+		cache:          productCache,
+	}
+}
+
+func productIDKey(id int64) string {
+	return fmt.Sprintf("product:id:%d", id)
+}
+
+func productSKUKey(sku string) string {
+	return "product:sku:" + sku
+}
+
+// GetProductByID returns the cached product or loads and caches it
+func (s *cachedProductService) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
+	if product, ok := s.cache.Get(ctx, productIDKey(id)); ok {
+		return copyProduct(product), nil
+	}
+
+	product, err := s.ProductService.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(ctx, product)
+	return product, nil
+}
+
+// GetProductBySKU returns the cached product or loads and caches it. A SKU entry only
+// counts as a hit while the product's ID entry is still cached under the same SKU, so
+// invalidating the ID also invalidates lookups by SKU.
+func (s *cachedProductService) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	sku = validation.NormalizeSKU(sku)
+	if bySKU, ok := s.cache.Get(ctx, productSKUKey(sku)); ok {
+		if product, ok := s.cache.Get(ctx, productIDKey(bySKU.ID)); ok && product.SKU == sku {
+			return copyProduct(product), nil
+		}
+	}
+
+	product, err := s.ProductService.GetProductBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(ctx, product)
+	return product, nil
+}
+
+// UpdateProduct updates the product and drops its cached entries
+func (s *cachedProductService) UpdateProduct(ctx context.Context, id int64, req *dto.UpdateProductRequest) (*domain.Product, error) {
+	defer s.invalidate(ctx, id)
+	return s.ProductService.UpdateProduct(ctx, id, req)
+}
+
+// DeleteProduct deletes the product and drops its cached entries
+func (s *cachedProductService) DeleteProduct(ctx context.Context, id int64) error {
+	defer s.invalidate(ctx, id)
+	return s.ProductService.DeleteProduct(ctx, id)
+}
+
+// BulkSetActive updates the products and drops their cached entries
+func (s *cachedProductService) BulkSetActive(ctx context.Context, ids []int64, active bool) (*dto.BulkProductResponse, error) {
+	defer s.invalidate(ctx, ids...)
+	return s.ProductService.BulkSetActive(ctx, ids, active)
+}
+
+// BulkDeleteProducts deletes the products and drops their cached entries
+func (s *cachedProductService) BulkDeleteProducts(ctx context.Context, ids []int64) (*dto.BulkProductResponse, error) {
+	defer s.invalidate(ctx, ids...)
+	return s.ProductService.BulkDeleteProducts(ctx, ids)
+}
+
+// UpdateProductQuantity updates the stored quantity and drops the product's cached entries
+func (s *cachedProductService) UpdateProductQuantity(ctx context.Context, id int64, quantity int) error {
+	defer s.invalidate(ctx, id)
+	return s.ProductService.UpdateProductQuantity(ctx, id, quantity)
+}
+
+// AddProductImage adds the image and drops the product's cached entries
+func (s *cachedProductService) AddProductImage(ctx context.Context, productID int64, req *dto.AddProductImageRequest) (*domain.ProductImage, error) {
+	defer s.invalidate(ctx, productID)
+	return s.ProductService.AddProductImage(ctx, productID, req)
+}
+
+// SetPrimaryImage changes the primary image and drops the product's cached entries
+func (s *cachedProductService) SetPrimaryImage(ctx context.Context, productID, imageID int64) error {
+	defer s.invalidate(ctx, productID)
+	return s.ProductService.SetPrimaryImage(ctx, productID, imageID)
+}
+
+// ReorderImages reorders the images and drops the product's cached entries
+func (s *cachedProductService) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	defer s.invalidate(ctx, productID)
+	return s.ProductService.ReorderImages(ctx, productID, imageIDs)
+}
+
+// DeleteProductImage deletes the image and drops the product's cached entries
+func (s *cachedProductService) DeleteProductImage(ctx context.Context, productID, imageID int64) error {
+	defer s.invalidate(ctx, productID)
+	return s.ProductService.DeleteProductImage(ctx, productID, imageID)
+}
+
+// store caches a copy of product under its ID and SKU
+func (s *cachedProductService) store(ctx context.Context, product *domain.Product) {
+	cached := copyProduct(product)
+	s.cache.Set(ctx, productIDKey(product.ID), cached)
+	s.cache.Set(ctx, productSKUKey(product.SKU), cached)
+}
+
+// invalidate drops the products' cached entries. Invalidation runs even when the change
+// fails, since a failed write may still have partly applied.
+func (s *cachedProductService) invalidate(ctx context.Context, ids ...int64) {
+	keys := make([]string, 0, 2*len(ids))
+	for _, id := range ids {
+		keys = append(keys, productIDKey(id))
+		if product, ok := s.cache.Get(ctx, productIDKey(id)); ok {
+			keys = append(keys, productSKUKey(product.SKU))
+		}
+	}
+	s.cache.Delete(ctx, keys...)
+}
+
+// copyProduct returns a copy so callers cannot modify cached values
+func copyProduct(product *domain.Product) *domain.Product {
+	copied := *product
+	copied.Images = append([]domain.ProductImage(nil), product.Images...)
+	copied.PrimaryImageURL = nil
+	for i := range copied.Images {
+		if copied.Images[i].IsPrimary {
+			copied.PrimaryImageURL = &copied.Images[i].URL
+		}
+	}
+	return &copied
+}
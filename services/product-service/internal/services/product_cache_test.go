@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/cache"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeProductService is an in-memory ProductService that counts reads
+type storeProductService struct {
+	ProductService
+	products map[int64]*domain.Product
+	reads    int
+}
+
+func newStoreProductService(products ...*domain.Product) *storeProductService {
+	s := &storeProductService{products: make(map[int64]*domain.Product)}
+	for _, product := range products {
+		s.products[product.ID] = product
+	}
+	return s
+}
+
+func (s *storeProductService) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
+	s.reads++
+	product, ok := s.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product with ID %d not found", id)
+	}
+	copied := *product
+	return &copied, nil
+}
+
+func (s *storeProductService) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	s.reads++
+	for _, product := range s.products {
+		if product.SKU == sku {
+			copied := *product
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("product with SKU %s not found", sku)
+}
+
+func (s *storeProductService) UpdateProduct(ctx context.Context, id int64, req *dto.UpdateProductRequest) (*domain.Product, error) {
+	product := s.products[id]
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.SKU != nil {
+		product.SKU = *req.SKU
+	}
+	copied := *product
+	return &copied, nil
+}
+
+func (s *storeProductService) DeleteProduct(ctx context.Context, id int64) error {
+	delete(s.products, id)
+	return nil
+}
+
+func (s *storeProductService) BulkDeleteProducts(ctx context.Context, ids []int64) (*dto.BulkProductResponse, error) {
+	for _, id := range ids {
+		delete(s.products, id)
+	}
+	return &dto.BulkProductResponse{Affected: len(ids)}, nil
+}
+
+func TestCachedProductService(t *testing.T) {
+	ctx := context.Background()
+	newProduct := func() *domain.Product {
+		return &domain.Product{ID: 1, Name: "Widget", SKU: "WIDGET-1"}
+	}
+
+	t.Run("miss then hit by ID", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		first, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		second, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, inner.reads)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("lookup by SKU shares entries with lookup by ID", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		_, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		product, err := service.GetProductBySKU(ctx, " WIDGET-1 ")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, inner.reads)
+		assert.Equal(t, int64(1), product.ID)
+	})
+
+	t.Run("callers cannot modify cached values", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		product, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		product.Name = "Changed"
+
+		cached, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Widget", cached.Name)
+	})
+
+	t.Run("update invalidates lookups by ID and SKU", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		_, err := service.GetProductBySKU(ctx, "WIDGET-1")
+		require.NoError(t, err)
+
+		name, sku := "Widget Pro", "WIDGET-2"
+		_, err = service.UpdateProduct(ctx, 1, &dto.UpdateProductRequest{Name: &name, SKU: &sku})
+		require.NoError(t, err)
+
+		product, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Widget Pro", product.Name)
+
+		_, err = service.GetProductBySKU(ctx, "WIDGET-1")
+		assert.Error(t, err)
+		assert.Equal(t, 3, inner.reads)
+	})
+
+	t.Run("delete invalidates the product", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		_, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		require.NoError(t, service.DeleteProduct(ctx, 1))
+
+		_, err = service.GetProductByID(ctx, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("bulk delete invalidates every listed product", func(t *testing.T) {
+		inner := newStoreProductService(newProduct(), &domain.Product{ID: 2, Name: "Gadget", SKU: "GADGET-1"})
+		service := NewCachedProductService(inner, cache.NewLRU[*domain.Product](10, time.Minute))
+
+		_, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		_, err = service.GetProductBySKU(ctx, "GADGET-1")
+		require.NoError(t, err)
+		_, err = service.BulkDeleteProducts(ctx, []int64{1, 2})
+		require.NoError(t, err)
+
+		_, err = service.GetProductByID(ctx, 1)
+		assert.Error(t, err)
+		_, err = service.GetProductBySKU(ctx, "GADGET-1")
+		assert.Error(t, err)
+	})
+
+	t.Run("no-op cache always reads through", func(t *testing.T) {
+		inner := newStoreProductService(newProduct())
+		service := NewCachedProductService(inner, cache.NewNoop[*domain.Product]())
+
+		_, err := service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+		_, err = service.GetProductByID(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, inner.reads)
+	})
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// ErrInvalidProductCursor is returned when an after cursor was not issued by ListProducts
+var ErrInvalidProductCursor = errors.New("invalid product cursor")
+
+// ErrProductCursorSort is returned when cursor pagination is combined with a custom sort.
+// Cursors only follow the default newest-first order.
+var ErrProductCursorSort = errors.New("cursor pagination only supports sorting by created_at descending")
+
+// encodeProductCursor returns an opaque cursor pointing just after product
+func encodeProductCursor(product *domain.Product) string {
+	raw := product.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(product.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeProductCursor parses a cursor made by encodeProductCursor
+func decodeProductCursor(cursor string) (*domain.ProductCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidProductCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidProductCursor
+	}
+
+	parsedAt, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidProductCursor
+	}
+	parsedID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || parsedID <= 0 {
+		return nil, ErrInvalidProductCursor
+	}
+
+	return &domain.ProductCursor{CreatedAt: parsedAt, ID: parsedID}, nil
+}
+
+// isDefaultProductOrder reports whether a sort is the newest-first order cursors follow
+func isDefaultProductOrder(sortBy, sortOrder string) bool {
+	return (sortBy == "" || sortBy == "created_at") && (sortOrder == "" || strings.EqualFold(sortOrder, "desc"))
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+)
+
+// Product Images
+
+// AddProductImage adds an image after the product's existing ones
+func (s *productService) AddProductImage(ctx context.Context, productID int64, req *dto.AddProductImageRequest) (*domain.ProductImage, error) {
+	image := &domain.ProductImage{
+		ProductID: productID,
+		URL:       req.URL,
+		Alt:       req.Alt,
+		IsPrimary: req.IsPrimary,
+	}
+
+	err := s.productRepo.AddProductImage(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add product image: %w", err)
+	}
+
+	return image, nil
+}
+
+// ListProductImages retrieves a product's images in display order
+func (s *productService) ListProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error) {
+	// Check if product exists
+	_, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	images, err := s.productRepo.ListProductImages(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+
+	return images, nil
+}
+
+// SetPrimaryImage makes one of a product's images its primary image
+func (s *productService) SetPrimaryImage(ctx context.Context, productID, imageID int64) error {
+	err := s.productRepo.SetPrimaryImage(ctx, productID, imageID)
+	if err != nil {
+		return fmt.Errorf("failed to set primary image: %w", err)
+	}
+
+	return nil
+}
+
+// ReorderImages sets the display order of a product's images
+func (s *productService) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	err := s.productRepo.ReorderImages(ctx, productID, imageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to reorder product images: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteProductImage removes one of a product's images
+func (s *productService) DeleteProductImage(ctx context.Context, productID, imageID int64) error {
+	err := s.productRepo.DeleteProductImage(ctx, productID, imageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+
+	return nil
+}
+
+// loadImages attaches a product's images and primary image URL
+func (s *productService) loadImages(ctx context.Context, product *domain.Product) error {
+	images, err := s.productRepo.ListProductImages(ctx, product.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get product images: %w", err)
+	}
+
+	setProductImages(product, images)
+	return nil
+}
+
+// setProductImages attaches images, already in display order, and the primary one's URL
+func setProductImages(product *domain.Product, images []*domain.ProductImage) {
+	product.Images = make([]domain.ProductImage, len(images))
+	product.PrimaryImageURL = nil
+	for i, image := range images {
+		product.Images[i] = *image
+		if image.IsPrimary {
+			product.PrimaryImageURL = &product.Images[i].URL
+		}
+	}
+}
+
+// productResponses converts products to response DTOs, loading their images in one query
+func (s *productService) productResponses(ctx context.Context, products []*domain.Product) ([]dto.ProductResponse, error) {
+	productIDs := make([]int64, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	images, err := s.productRepo.ListImagesForProducts(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+
+	responses := make([]dto.ProductResponse, len(products))
+	for i, product := range products {
+		responses[i] = productResponse(product, images[product.ID])
+	}
+
+	return responses, nil
+}
+
+// productResponse converts a product and its images, in display order, to a response DTO
+func productResponse(product *domain.Product, images []*domain.ProductImage) dto.ProductResponse {
+	response := dto.ProductResponse{
+		ID:               product.ID,
+		Name:             product.Name,
+		Description:      product.Description,
+		ShortDesc:        product.ShortDesc,
+		SKU:              product.SKU,
+		Price:            product.Price,
+		ComparePrice:     product.ComparePrice,
+		CostPrice:        product.CostPrice,
+		Weight:           product.Weight,
+		Dimensions:       product.Dimensions,
+		IsActive:         product.IsActive,
+		IsDigital:        product.IsDigital,
+		IsFree:           product.IsFree,
+		RequiresShipping: product.RequiresShipping,
+		Taxable:          product.Taxable,
+		TrackQuantity:    product.TrackQuantity,
+		Quantity:         product.Quantity,
+		MinQuantity:      product.MinQuantity,
+		MaxQuantity:      product.MaxQuantity,
+		MetaTitle:        product.MetaTitle,
+		MetaDescription:  product.MetaDesc,
+		Tags:             product.Tags,
+		CreatedAt:        httpx.FormatTime(product.CreatedAt),
+		UpdatedAt:        httpx.FormatTime(product.UpdatedAt),
+		Version:          product.Version,
+		Images:           make([]dto.ProductImageResponse, len(images)),
+	}
+
+	for i, image := range images {
+		response.Images[i] = dto.ProductImageResponse{
+			ID:        image.ID,
+			URL:       image.URL,
+			Alt:       image.Alt,
+			Position:  image.Position,
+			IsPrimary: image.IsPrimary,
+		}
+		if image.IsPrimary {
+			response.PrimaryImageURL = &response.Images[i].URL
+		}
+	}
+
+	return response
+}
@@ -2,54 +2,133 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
 	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/validation"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
 )
 
 type ProductService interface {
 	CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*domain.Product, error)
 	GetProductByID(ctx context.Context, id int64) (*domain.Product, error)
 	GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []int64) ([]dto.ProductResponse, error)
+	IsSKUAvailable(ctx context.Context, sku string) (bool, error)
 	UpdateProduct(ctx context.Context, id int64, req *dto.UpdateProductRequest) (*domain.Product, error)
 	DeleteProduct(ctx context.Context, id int64) error
+	BulkSetActive(ctx context.Context, ids []int64, active bool) (*dto.BulkProductResponse, error)
+	BulkDeleteProducts(ctx context.Context, ids []int64) (*dto.BulkProductResponse, error)
 	ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ListProductsResponse, error)
 	GetProductsByCategory(ctx context.Context, categoryID int64, page, limit int) (*dto.ListProductsResponse, error)
-	SearchProducts(ctx context.Context, query string, page, limit int) (*dto.ListProductsResponse, error)
+	SearchProducts(ctx context.Context, query, mode string, page, limit int) (*dto.ListProductsResponse, error)
+	SuggestProducts(ctx context.Context, prefix string, limit int) ([]*domain.ProductSuggestion, error)
 	UpdateProductQuantity(ctx context.Context, id int64, quantity int) error
 	GetProductsByTags(ctx context.Context, tags []string, page, limit int) (*dto.ListProductsResponse, error)
+	CloneProduct(ctx context.Context, id int64, includeImages bool) (*domain.Product, error)
 
 	// Product Variants
 	CreateProductVariant(ctx context.Context, req *dto.CreateProductVariantRequest) (*domain.ProductVariant, error)
 	GetProductVariantByID(ctx context.Context, id int64) (*domain.ProductVariant, error)
 	GetProductVariantsByProductID(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
-	GetProductVariantsByProductIDAndSKU(ctx context.Context, productID int64, sku string) ([]*domain.ProductVariant, error)
 	UpdateProductVariant(ctx context.Context, id int64, req *dto.UpdateProductVariantRequest) (*domain.ProductVariant, error)
 	DeleteProductVariant(ctx context.Context, id int64) error
+	GetVariantByAttributes(ctx context.Context, productID int64, attrs map[string]string) (*domain.ProductVariant, error)
+	GetVariantAttributeOptions(ctx context.Context, productID int64) ([]*domain.VariantAttributeOption, error)
 
 	// Product Categories
 	AddProductToCategory(ctx context.Context, productID, categoryID int64, isPrimary bool) error
 	RemoveProductFromCategory(ctx context.Context, productID, categoryID int64) error
 	GetProductCategories(ctx context.Context, productID int64) ([]*domain.Category, error)
-	UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64) error
+	UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64, primaryCategoryID *int64) error
+
+	// Product Images
+	AddProductImage(ctx context.Context, productID int64, req *dto.AddProductImageRequest) (*domain.ProductImage, error)
+	ListProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error)
+	SetPrimaryImage(ctx context.Context, productID, imageID int64) error
+	ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error
+	DeleteProductImage(ctx context.Context, productID, imageID int64) error
+}
+
+// ErrInvalidProductUpdate is returned when an update request is internally inconsistent
+var ErrInvalidProductUpdate = errors.New("invalid product update")
+
+// ErrCategoriesRequired is returned when a product's category list would be emptied and
+// the category policy does not allow uncategorized products
+var ErrCategoriesRequired = errors.New("product must belong to at least one category")
+
+// ErrPrimaryCategoryNotListed is returned when the chosen primary category is not one of
+// the product's categories
+var ErrPrimaryCategoryNotListed = errors.New("primary category must be one of the category IDs")
+
+// CategoryPolicy controls how products may be categorized
+type CategoryPolicy struct {
+	// AllowUncategorized lets a product's category list be emptied
+	AllowUncategorized bool
 }
 
 type productService struct {
-	productRepo repository.ProductRepository
+	productRepo    repository.ProductRepository
+	inventoryRepo  repository.InventoryRepository
+	categoryPolicy CategoryPolicy
+	events         EventPublisher
 }
 
-func NewProductService(productRepo repository.ProductRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, inventoryRepo repository.InventoryRepository, categoryPolicy CategoryPolicy, events EventPublisher) ProductService {
 	return &productService{
-		productRepo: productRepo,
+		productRepo:    productRepo,
+		inventoryRepo:  inventoryRepo,
+		categoryPolicy: categoryPolicy,
+		events:         events,
 	}
 }
 
+// publishEvent reports a product event to the event publisher, if one is configured
+func (s *productService) publishEvent(ctx context.Context, eventType string, data interface{}) {
+	if s.events == nil {
+		return
+	}
+
+	s.events.PublishEvent(ctx, eventType, data)
+}
+
+// publishProductsUpdated reports a product updated event for each of ids that still exists
+func (s *productService) publishProductsUpdated(ctx context.Context, ids []int64) {
+	if s.events == nil || len(ids) == 0 {
+		return
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to load updated products for events", "error", err)
+		return
+	}
+	for _, id := range ids {
+		if product, ok := products[id]; ok {
+			s.events.PublishEvent(ctx, domain.EventProductUpdated, product)
+		}
+	}
+}
+
+// defaultReorderPoint is the reorder point of inventory created along with a product or
+// variant; staff can tune it through the inventory API
+const defaultReorderPoint = 10
+
 // CreateProduct creates a new product
 func (s *productService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*domain.Product, error) {
+	if err := checkQuantity(req.Quantity, minStockQuantity); err != nil {
+		return nil, err
+	}
+	req.SKU = validation.NormalizeSKU(req.SKU)
+
 	// Check if SKU already exists
 	existingProduct, err := s.productRepo.GetProductBySKU(ctx, req.SKU)
 	if err == nil && existingProduct != nil {
@@ -69,6 +148,7 @@ func (s *productService) CreateProduct(ctx context.Context, req *dto.CreateProdu
 		Dimensions:       req.Dimensions,
 		IsActive:         req.IsActive,
 		IsDigital:        req.IsDigital,
+		IsFree:           req.IsFree,
 		RequiresShipping: req.RequiresShipping,
 		Taxable:          req.Taxable,
 		TrackQuantity:    req.TrackQuantity,
@@ -77,7 +157,7 @@ func (s *productService) CreateProduct(ctx context.Context, req *dto.CreateProdu
 		MaxQuantity:      req.MaxQuantity,
 		MetaTitle:        req.MetaTitle,
 		MetaDesc:         req.MetaDescription,
-		Tags:             req.Tags,
+		Tags:             normalizeTags(req.Tags),
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
@@ -88,15 +168,28 @@ func (s *productService) CreateProduct(ctx context.Context, req *dto.CreateProdu
 		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
+	if req.CreateInventory {
+		err = s.provisionInventory(ctx, product.ID, nil, product.Quantity, product.MinQuantity, product.MaxQuantity)
+		if err != nil {
+			// Remove the product so a retry does not hit its SKU
+			if delErr := s.productRepo.DeleteProduct(ctx, product.ID); delErr != nil {
+				logger.FromContext(ctx).Error("failed to remove product after inventory creation failed", "product_id", product.ID, "error", delErr)
+			}
+			return nil, err
+		}
+	}
+
 	// Add product to categories if provided
 	if len(req.CategoryIDs) > 0 {
-		err = s.productRepo.UpdateProductCategories(ctx, product.ID, req.CategoryIDs)
+		err = s.productRepo.UpdateProductCategories(ctx, product.ID, req.CategoryIDs, nil)
 		if err != nil {
 			// Log error but don't fail the product creation
-			fmt.Printf("Warning: failed to add product to categories: %v\n", err)
+			logger.FromContext(ctx).Warn("failed to add product to categories", "product_id", product.ID, "error", err)
 		}
 	}
 
+	s.publishEvent(ctx, domain.EventProductCreated, product)
+
 	return product, nil
 }
 
@@ -106,22 +199,54 @@ func (s *productService) GetProductByID(ctx context.Context, id int64) (*domain.
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
+	if err := s.loadImages(ctx, product); err != nil {
+		return nil, err
+	}
 
 	return product, nil
 }
 
 // GetProductBySKU retrieves a product by SKU
 func (s *productService) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
-	product, err := s.productRepo.GetProductBySKU(ctx, sku)
+	product, err := s.productRepo.GetProductBySKU(ctx, validation.NormalizeSKU(sku))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
+	if err := s.loadImages(ctx, product); err != nil {
+		return nil, err
+	}
 
 	return product, nil
 }
 
+// IsSKUAvailable reports whether a SKU is unused by any product or variant
+func (s *productService) IsSKUAvailable(ctx context.Context, sku string) (bool, error) {
+	sku = validation.NormalizeSKU(sku)
+	if sku == "" {
+		return false, fmt.Errorf("SKU is required")
+	}
+
+	taken, err := s.productRepo.IsSKUTaken(ctx, sku)
+	if err != nil {
+		return false, fmt.Errorf("failed to check SKU availability: %w", err)
+	}
+
+	return !taken, nil
+}
+
 // UpdateProduct updates an existing product
 func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.UpdateProductRequest) (*domain.Product, error) {
+	if req.Quantity != nil {
+		if err := checkQuantity(*req.Quantity, minStockQuantity); err != nil {
+			return nil, err
+		}
+	}
+	if req.CategoryIDs != nil {
+		if err := s.checkCategoryIDs(req.CategoryIDs, nil); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get existing product
 	existingProduct, err := s.productRepo.GetProductByID(ctx, id)
 	if err != nil {
@@ -129,6 +254,9 @@ func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.U
 	}
 
 	// Check SKU uniqueness if SKU is being updated
+	if req.SKU != nil {
+		*req.SKU = validation.NormalizeSKU(*req.SKU)
+	}
 	if req.SKU != nil && *req.SKU != existingProduct.SKU {
 		skuProduct, err := s.productRepo.GetProductBySKU(ctx, *req.SKU)
 		if err == nil && skuProduct != nil && skuProduct.ID != id {
@@ -136,6 +264,10 @@ func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.U
 		}
 	}
 
+	if err := validateClearFields(req); err != nil {
+		return nil, err
+	}
+
 	// Update fields that are provided
 	updateProduct := *existingProduct
 
@@ -172,6 +304,9 @@ func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.U
 	if req.IsDigital != nil {
 		updateProduct.IsDigital = *req.IsDigital
 	}
+	if req.IsFree != nil {
+		updateProduct.IsFree = *req.IsFree
+	}
 	if req.RequiresShipping != nil {
 		updateProduct.RequiresShipping = *req.RequiresShipping
 	}
@@ -197,10 +332,33 @@ func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.U
 		updateProduct.MetaDesc = *req.MetaDescription
 	}
 	if req.Tags != nil {
-		updateProduct.Tags = *req.Tags
+		updateProduct.Tags = normalizeTags(req.Tags)
+	}
+
+	// Reset explicitly cleared fields
+	for _, field := range req.ClearFields {
+		switch field {
+		case "short_description":
+			updateProduct.ShortDesc = ""
+		case "compare_price":
+			updateProduct.ComparePrice = 0
+		case "cost_price":
+			updateProduct.CostPrice = 0
+		case "weight":
+			updateProduct.Weight = 0
+		case "dimensions":
+			updateProduct.Dimensions = ""
+		case "meta_title":
+			updateProduct.MetaTitle = ""
+		case "meta_description":
+			updateProduct.MetaDesc = ""
+		case "tags":
+			updateProduct.Tags = []string{}
+		}
 	}
 
 	updateProduct.UpdatedAt = time.Now()
+	updateProduct.Version = *req.Version
 
 	// Update product in repository
 	err = s.productRepo.UpdateProduct(ctx, id, &updateProduct)
@@ -210,16 +368,40 @@ func (s *productService) UpdateProduct(ctx context.Context, id int64, req *dto.U
 
 	// Update categories if provided
 	if req.CategoryIDs != nil {
-		err = s.productRepo.UpdateProductCategories(ctx, id, req.CategoryIDs)
+		err = s.productRepo.UpdateProductCategories(ctx, id, req.CategoryIDs, nil)
 		if err != nil {
 			// Log error but don't fail the product update
-			fmt.Printf("Warning: failed to update product categories: %v\n", err)
+			logger.FromContext(ctx).Warn("failed to update product categories", "product_id", id, "error", err)
 		}
 	}
 
+	s.publishEvent(ctx, domain.EventProductUpdated, &updateProduct)
+
 	return &updateProduct, nil
 }
 
+// validateClearFields rejects requests that both set and clear the same field
+func validateClearFields(req *dto.UpdateProductRequest) error {
+	provided := map[string]bool{
+		"short_description": req.ShortDesc != nil,
+		"compare_price":     req.ComparePrice != nil,
+		"cost_price":        req.CostPrice != nil,
+		"weight":            req.Weight != nil,
+		"dimensions":        req.Dimensions != nil,
+		"meta_title":        req.MetaTitle != nil,
+		"meta_description":  req.MetaDescription != nil,
+		"tags":              req.Tags != nil,
+	}
+
+	for _, field := range req.ClearFields {
+		if provided[field] {
+			return fmt.Errorf("%w: field %s cannot be both set and cleared", ErrInvalidProductUpdate, field)
+		}
+	}
+
+	return nil
+}
+
 // DeleteProduct deletes a product
 func (s *productService) DeleteProduct(ctx context.Context, id int64) error {
 	// Check if product exists
@@ -234,72 +416,179 @@ func (s *productService) DeleteProduct(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
+	s.publishEvent(ctx, domain.EventProductDeleted, domain.ProductDeleted{ProductID: id})
+
 	return nil
 }
 
-// ListProducts retrieves products with filters
-func (s *productService) ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ListProductsResponse, error) {
-	// Set default values
-	if req.Page <= 0 {
-		req.Page = 1
+// BulkSetActive activates or deactivates the listed products in one transaction
+func (s *productService) BulkSetActive(ctx context.Context, ids []int64, active bool) (*dto.BulkProductResponse, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one product ID is required")
+	}
+
+	updated, err := s.productRepo.BulkSetProductsActive(ctx, ids, active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update products: %w", err)
+	}
+
+	s.publishProductsUpdated(ctx, updated)
+
+	return bulkProductResponse(ids, updated, dto.BulkProductUpdated), nil
+}
+
+// BulkDeleteProducts deletes the listed products in one transaction. Either every
+// existing product is deleted or, on error, none are.
+func (s *productService) BulkDeleteProducts(ctx context.Context, ids []int64) (*dto.BulkProductResponse, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one product ID is required")
+	}
+
+	deleted, err := s.productRepo.BulkDeleteProducts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete products: %w", err)
+	}
+
+	for _, id := range deleted {
+		s.publishEvent(ctx, domain.EventProductDeleted, domain.ProductDeleted{ProductID: id})
+	}
+
+	return bulkProductResponse(ids, deleted, dto.BulkProductDeleted), nil
+}
+
+// GetProductsByIDs retrieves several products in one query, in the order their IDs are
+// given. Repeated IDs are returned once and missing ones are left out, as are inactive
+// ones unless the caller is catalog staff.
+func (s *productService) GetProductsByIDs(ctx context.Context, ids []int64) ([]dto.ProductResponse, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one product ID is required")
 	}
-	if req.Limit <= 0 {
-		req.Limit = 10
+
+	found, err := s.productRepo.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
 	}
-	if req.Limit > 100 {
-		req.Limit = 100 // Max limit
+
+	staff := isCatalogStaff(ctx)
+	products := make([]*domain.Product, 0, len(found))
+	for _, id := range ids {
+		if product, ok := found[id]; ok && (product.IsActive || staff) {
+			products = append(products, product)
+		}
 	}
 
-	offset := (req.Page - 1) * req.Limit
+	return s.productResponses(ctx, products)
+}
+
+// uniqueIDs drops duplicate IDs while keeping the caller's order
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// bulkProductResponse reports each requested ID as changed or not found
+func bulkProductResponse(requested, changed []int64, status string) *dto.BulkProductResponse {
+	changedSet := make(map[int64]bool, len(changed))
+	for _, id := range changed {
+		changedSet[id] = true
+	}
+
+	response := &dto.BulkProductResponse{Results: make([]dto.BulkProductResult, 0, len(requested))}
+	for _, id := range requested {
+		result := dto.BulkProductResult{ID: id, Status: dto.BulkProductNotFound}
+		if changedSet[id] {
+			result.Status = status
+			response.Affected++
+		} else {
+			response.NotFound++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response
+}
+
+// ListProducts retrieves products with filters. A request with an After cursor is
+// served by keyset pagination and is not counted; otherwise Page selects an offset page.
+// Both return a NextCursor while more products follow in the default newest-first order.
+func (s *productService) ListProducts(ctx context.Context, req *dto.ListProductsRequest) (*dto.ListProductsResponse, error) {
+	// Customers only see active products; catalog staff may ask for inactive ones or,
+	// by leaving is_active unset, for all of them
+	isActive := req.IsActive
+	if !isCatalogStaff(ctx) {
+		active := true
+		isActive = &active
+	}
 
 	// Build filter
 	filter := &domain.ProductFilter{
 		CategoryID: req.CategoryID,
-		IsActive:   req.IsActive,
+		IsActive:   isActive,
 		IsDigital:  req.IsDigital,
 		MinPrice:   req.MinPrice,
 		MaxPrice:   req.MaxPrice,
 		InStock:    req.InStock,
 		Search:     req.Search,
-		Tags:       req.Tags,
+		Tags:       normalizeTags(req.Tags),
 		SortBy:     req.SortBy,
 		SortOrder:  req.SortOrder,
 	}
 
-	// Get products from repository
-	products, total, err := s.productRepo.ListProducts(ctx, filter, offset, req.Limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list products: %w", err)
+	var products []*domain.Product
+	var total int64
+	var nextCursor string
+	if req.After != "" {
+		after, err := decodeProductCursor(req.After)
+		if err != nil {
+			return nil, err
+		}
+		if !isDefaultProductOrder(req.SortBy, req.SortOrder) {
+			return nil, ErrProductCursorSort
+		}
+
+		// Cursor pages have no page number
+		_, req.Limit = httpx.ClampPagination(1, req.Limit)
+		req.Page = 0
+
+		// Fetch one extra product to learn whether another page follows
+		products, err = s.productRepo.ListProductsAfter(ctx, filter, after, req.Limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list products: %w", err)
+		}
+		if len(products) > req.Limit {
+			products = products[:req.Limit]
+			nextCursor = encodeProductCursor(products[len(products)-1])
+		}
+	} else {
+		req.Page, req.Limit = httpx.ClampPagination(req.Page, req.Limit)
+
+		offset := (req.Page - 1) * req.Limit
+
+		// Get products from repository
+		var err error
+		products, total, err = s.productRepo.ListProducts(ctx, filter, offset, req.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list products: %w", err)
+		}
+		if int64(offset+len(products)) < total && len(products) > 0 && isDefaultProductOrder(req.SortBy, req.SortOrder) {
+			nextCursor = encodeProductCursor(products[len(products)-1])
+		}
 	}
 
 	// Convert to response DTOs
-	productResponses := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = dto.ProductResponse{
-			ID:               product.ID,
-			Name:             product.Name,
-			Description:      product.Description,
-			ShortDesc:        product.ShortDesc,
-			SKU:              product.SKU,
-			Price:            product.Price,
-			ComparePrice:     product.ComparePrice,
-			CostPrice:        product.CostPrice,
-			Weight:           product.Weight,
-			Dimensions:       product.Dimensions,
-			IsActive:         product.IsActive,
-			IsDigital:        product.IsDigital,
-			RequiresShipping: product.RequiresShipping,
-			Taxable:          product.Taxable,
-			TrackQuantity:    product.TrackQuantity,
-			Quantity:         product.Quantity,
-			MinQuantity:      product.MinQuantity,
-			MaxQuantity:      product.MaxQuantity,
-			MetaTitle:        product.MetaTitle,
-			MetaDescription:  product.MetaDesc,
-			Tags:             product.Tags,
-			CreatedAt:        product.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:        product.UpdatedAt.Format(time.RFC3339),
-		}
+	productResponses, err := s.productResponses(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -311,58 +600,26 @@ func (s *productService) ListProducts(ctx context.Context, req *dto.ListProducts
 		Page:       req.Page,
 		Limit:      req.Limit,
 		TotalPages: totalPages,
+		NextCursor: nextCursor,
 	}, nil
 }
 
 // GetProductsByCategory retrieves products by category
 func (s *productService) GetProductsByCategory(ctx context.Context, categoryID int64, page, limit int) (*dto.ListProductsResponse, error) {
-	// Set default values
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	page, limit = httpx.ClampPagination(page, limit)
 
 	offset := (page - 1) * limit
 
 	// Get products from repository
-	products, total, err := s.productRepo.GetProductsByCategory(ctx, categoryID, offset, limit)
+	products, total, err := s.productRepo.GetProductsByCategory(ctx, categoryID, !isCatalogStaff(ctx), offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get products by category: %w", err)
 	}
 
 	// Convert to response DTOs
-	productResponses := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = dto.ProductResponse{
-			ID:               product.ID,
-			Name:             product.Name,
-			Description:      product.Description,
-			ShortDesc:        product.ShortDesc,
-			SKU:              product.SKU,
-			Price:            product.Price,
-			ComparePrice:     product.ComparePrice,
-			CostPrice:        product.CostPrice,
-			Weight:           product.Weight,
-			Dimensions:       product.Dimensions,
-			IsActive:         product.IsActive,
-			IsDigital:        product.IsDigital,
-			RequiresShipping: product.RequiresShipping,
-			Taxable:          product.Taxable,
-			TrackQuantity:    product.TrackQuantity,
-			Quantity:         product.Quantity,
-			MinQuantity:      product.MinQuantity,
-			MaxQuantity:      product.MaxQuantity,
-			MetaTitle:        product.MetaTitle,
-			MetaDescription:  product.MetaDesc,
-			Tags:             product.Tags,
-			CreatedAt:        product.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:        product.UpdatedAt.Format(time.RFC3339),
-		}
+	productResponses, err := s.productResponses(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -377,55 +634,26 @@ func (s *productService) GetProductsByCategory(ctx context.Context, categoryID i
 	}, nil
 }
 
-// SearchProducts searches products by query
-func (s *productService) SearchProducts(ctx context.Context, query string, page, limit int) (*dto.ListProductsResponse, error) {
-	// Set default values
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100
+// SearchProducts searches products by query in the given mode; an empty mode is full-text
+func (s *productService) SearchProducts(ctx context.Context, query, mode string, page, limit int) (*dto.ListProductsResponse, error) {
+	if mode == "" {
+		mode = domain.ProductSearchFullText
 	}
 
+	page, limit = httpx.ClampPagination(page, limit)
+
 	offset := (page - 1) * limit
 
 	// Get products from repository
-	products, total, err := s.productRepo.SearchProducts(ctx, query, offset, limit)
+	products, total, err := s.productRepo.SearchProducts(ctx, query, mode, !isCatalogStaff(ctx), offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 
 	// Convert to response DTOs
-	productResponses := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = dto.ProductResponse{
-			ID:               product.ID,
-			Name:             product.Name,
-			Description:      product.Description,
-			ShortDesc:        product.ShortDesc,
-			SKU:              product.SKU,
-			Price:            product.Price,
-			ComparePrice:     product.ComparePrice,
-			CostPrice:        product.CostPrice,
-			Weight:           product.Weight,
-			Dimensions:       product.Dimensions,
-			IsActive:         product.IsActive,
-			IsDigital:        product.IsDigital,
-			RequiresShipping: product.RequiresShipping,
-			Taxable:          product.Taxable,
-			TrackQuantity:    product.TrackQuantity,
-			Quantity:         product.Quantity,
-			MinQuantity:      product.MinQuantity,
-			MaxQuantity:      product.MaxQuantity,
-			MetaTitle:        product.MetaTitle,
-			MetaDescription:  product.MetaDesc,
-			Tags:             product.Tags,
-			CreatedAt:        product.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:        product.UpdatedAt.Format(time.RFC3339),
-		}
+	productResponses, err := s.productResponses(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -440,8 +668,39 @@ func (s *productService) SearchProducts(ctx context.Context, query string, page,
 	}, nil
 }
 
+// SuggestProducts returns autocomplete suggestions for a name prefix
+func (s *productService) SuggestProducts(ctx context.Context, prefix string, limit int) ([]*domain.ProductSuggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []*domain.ProductSuggestion{}, nil
+	}
+
+	// Keep suggestion lists small so the search box stays responsive
+	if limit <= 0 {
+		limit = 8
+	}
+	if limit > 20 {
+		limit = 20
+	}
+
+	suggestions, err := s.productRepo.SuggestProducts(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
+	}
+
+	if suggestions == nil {
+		suggestions = []*domain.ProductSuggestion{}
+	}
+
+	return suggestions, nil
+}
+
 // UpdateProductQuantity updates product quantity
 func (s *productService) UpdateProductQuantity(ctx context.Context, id int64, quantity int) error {
+	if err := checkQuantity(quantity, minStockQuantity); err != nil {
+		return err
+	}
+
 	// Check if product exists
 	_, err := s.productRepo.GetProductByID(ctx, id)
 	if err != nil {
@@ -459,53 +718,20 @@ func (s *productService) UpdateProductQuantity(ctx context.Context, id int64, qu
 
 // GetProductsByTags retrieves products by tags
 func (s *productService) GetProductsByTags(ctx context.Context, tags []string, page, limit int) (*dto.ListProductsResponse, error) {
-	// Set default values
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	page, limit = httpx.ClampPagination(page, limit)
 
 	offset := (page - 1) * limit
 
 	// Get products from repository
-	products, total, err := s.productRepo.GetProductsByTags(ctx, tags, offset, limit)
+	products, total, err := s.productRepo.GetProductsByTags(ctx, normalizeTags(tags), !isCatalogStaff(ctx), offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get products by tags: %w", err)
 	}
 
 	// Convert to response DTOs
-	productResponses := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = dto.ProductResponse{
-			ID:               product.ID,
-			Name:             product.Name,
-			Description:      product.Description,
-			ShortDesc:        product.ShortDesc,
-			SKU:              product.SKU,
-			Price:            product.Price,
-			ComparePrice:     product.ComparePrice,
-			CostPrice:        product.CostPrice,
-			Weight:           product.Weight,
-			Dimensions:       product.Dimensions,
-			IsActive:         product.IsActive,
-			IsDigital:        product.IsDigital,
-			RequiresShipping: product.RequiresShipping,
-			Taxable:          product.Taxable,
-			TrackQuantity:    product.TrackQuantity,
-			Quantity:         product.Quantity,
-			MinQuantity:      product.MinQuantity,
-			MaxQuantity:      product.MaxQuantity,
-			MetaTitle:        product.MetaTitle,
-			MetaDescription:  product.MetaDesc,
-			Tags:             product.Tags,
-			CreatedAt:        product.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:        product.UpdatedAt.Format(time.RFC3339),
-		}
+	productResponses, err := s.productResponses(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -520,6 +746,18 @@ func (s *productService) GetProductsByTags(ctx context.Context, tags []string, p
 	}, nil
 }
 
+// CloneProduct creates an inactive copy of a product as a starting point for a new listing
+func (s *productService) CloneProduct(ctx context.Context, id int64, includeImages bool) (*domain.Product, error) {
+	product, err := s.productRepo.CloneProduct(ctx, id, includeImages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone product: %w", err)
+	}
+
+	s.publishEvent(ctx, domain.EventProductCreated, product)
+
+	return product, nil
+}
+
 // Product Variant methods
 
 // CreateProductVariant creates a new product variant
@@ -530,10 +768,10 @@ func (s *productService) CreateProductVariant(ctx context.Context, req *dto.Crea
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
-	// Check if SKU already exists
-	existingVariant, err := s.productRepo.GetProductVariantsByProductIDAndSKU(ctx, req.ProductID, req.SKU)
-	if err == nil && existingVariant != nil {
-		return nil, fmt.Errorf("variant with SKU %s already exists", req.SKU)
+	// Check if SKU already exists on any product or variant
+	req.SKU = validation.NormalizeSKU(req.SKU)
+	if err := s.checkVariantSKU(ctx, req.SKU, 0); err != nil {
+		return nil, err
 	}
 
 	// Create variant domain object
@@ -556,11 +794,65 @@ func (s *productService) CreateProductVariant(ctx context.Context, req *dto.Crea
 		return nil, fmt.Errorf("failed to create product variant: %w", err)
 	}
 
-	_ = existingVariant // Suppress unused variable warning
+	if len(req.Attributes) > 0 {
+		if err := s.productRepo.SetVariantAttributes(ctx, variant.ID, req.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to set variant attributes: %w", err)
+		}
+	}
+
+	if req.CreateInventory {
+		if err := s.provisionInventory(ctx, variant.ProductID, &variant.ID, variant.Quantity, 0, 0); err != nil {
+			// Remove the variant so a retry does not hit its SKU
+			if delErr := s.productRepo.DeleteProductVariant(ctx, variant.ID); delErr != nil {
+				logger.FromContext(ctx).Error("failed to remove variant after inventory creation failed", "variant_id", variant.ID, "error", delErr)
+			}
+			return nil, err
+		}
+	}
 
 	return variant, nil
 }
 
+// provisionInventory creates the inventory record of a new product or variant with its
+// opening quantity, stock levels and the default reorder point
+func (s *productService) provisionInventory(ctx context.Context, productID int64, variantID *int64, quantity, minStock, maxStock int) error {
+	now := time.Now()
+	inventory := &domain.Inventory{
+		ProductID:         productID,
+		ProductVariantID:  variantID,
+		Quantity:          quantity,
+		AvailableQuantity: quantity,
+		MinStockLevel:     minStock,
+		MaxStockLevel:     maxStock,
+		ReorderPoint:      defaultReorderPoint,
+		LastRestocked:     now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := createInitialInventory(ctx, s.inventoryRepo, inventory); err != nil {
+		return fmt.Errorf("failed to create inventory: %w", err)
+	}
+
+	return nil
+}
+
+// checkVariantSKU returns an AlreadyExistsError when a product, or a variant other than
+// variantID, already uses sku. Products and variants share one SKU namespace.
+func (s *productService) checkVariantSKU(ctx context.Context, sku string, variantID int64) error {
+	product, err := s.productRepo.GetProductBySKU(ctx, sku)
+	if err == nil && product != nil {
+		return &repository.AlreadyExistsError{Resource: fmt.Sprintf("product with SKU %s", sku)}
+	}
+
+	variant, err := s.productRepo.GetProductVariantBySKU(ctx, sku)
+	if err == nil && variant != nil && variant.ID != variantID {
+		return &repository.AlreadyExistsError{Resource: fmt.Sprintf("product variant with SKU %s", sku)}
+	}
+
+	return nil
+}
+
 // GetProductVariantByID retrieves a product variant by ID
 func (s *productService) GetProductVariantByID(ctx context.Context, id int64) (*domain.ProductVariant, error) {
 	variant, err := s.productRepo.GetProductVariantByID(ctx, id)
@@ -596,7 +888,12 @@ func (s *productService) UpdateProductVariant(ctx context.Context, id int64, req
 		updateVariant.Name = *req.Name
 	}
 	if req.SKU != nil {
-		updateVariant.SKU = *req.SKU
+		updateVariant.SKU = validation.NormalizeSKU(*req.SKU)
+		if updateVariant.SKU != existingVariant.SKU {
+			if err := s.checkVariantSKU(ctx, updateVariant.SKU, id); err != nil {
+				return nil, err
+			}
+		}
 	}
 	if req.Price != nil {
 		updateVariant.Price = *req.Price
@@ -626,9 +923,35 @@ func (s *productService) UpdateProductVariant(ctx context.Context, id int64, req
 		return nil, fmt.Errorf("failed to update product variant: %w", err)
 	}
 
+	if req.Attributes != nil {
+		if err := s.productRepo.SetVariantAttributes(ctx, id, req.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to set variant attributes: %w", err)
+		}
+	}
+
 	return &updateVariant, nil
 }
 
+// GetVariantByAttributes resolves a variant from an attribute selection
+func (s *productService) GetVariantByAttributes(ctx context.Context, productID int64, attrs map[string]string) (*domain.ProductVariant, error) {
+	variant, err := s.productRepo.GetVariantByAttributes(ctx, productID, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant by attributes: %w", err)
+	}
+
+	return variant, nil
+}
+
+// GetVariantAttributeOptions retrieves the selectable attribute values for a product
+func (s *productService) GetVariantAttributeOptions(ctx context.Context, productID int64) ([]*domain.VariantAttributeOption, error) {
+	options, err := s.productRepo.GetVariantAttributeOptions(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant attribute options: %w", err)
+	}
+
+	return options, nil
+}
+
 // DeleteProductVariant deletes a product variant
 func (s *productService) DeleteProductVariant(ctx context.Context, id int64) error {
 	// Check if variant exists
@@ -699,8 +1022,14 @@ func (s *productService) GetProductCategories(ctx context.Context, productID int
 	return categories, nil
 }
 
-// UpdateProductCategories updates all categories for a product
-func (s *productService) UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64) error {
+// UpdateProductCategories replaces the categories of a product. primaryCategoryID, when
+// set, must be one of categoryIDs; otherwise the current primary category is kept if
+// it is still listed.
+func (s *productService) UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64, primaryCategoryID *int64) error {
+	if err := s.checkCategoryIDs(categoryIDs, primaryCategoryID); err != nil {
+		return err
+	}
+
 	// Check if product exists
 	_, err := s.productRepo.GetProductByID(ctx, productID)
 	if err != nil {
@@ -708,7 +1037,7 @@ func (s *productService) UpdateProductCategories(ctx context.Context, productID
 	}
 
 	// Update categories
-	err = s.productRepo.UpdateProductCategories(ctx, productID, categoryIDs)
+	err = s.productRepo.UpdateProductCategories(ctx, productID, categoryIDs, primaryCategoryID)
 	if err != nil {
 		return fmt.Errorf("failed to update product categories: %w", err)
 	}
@@ -716,19 +1045,21 @@ func (s *productService) UpdateProductCategories(ctx context.Context, productID
 	return nil
 }
 
-// GetProductVariantsByProductIDAndSKU retrieves all variants for a product and SKU
-func (s *productService) GetProductVariantsByProductIDAndSKU(ctx context.Context, productID int64, sku string) ([]*domain.ProductVariant, error) {
-	// Check if product exists
-	_, err := s.productRepo.GetProductByID(ctx, productID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
+// checkCategoryIDs rejects a category list the policy does not allow and a primary
+// category that is not in the list
+func (s *productService) checkCategoryIDs(categoryIDs []int64, primaryCategoryID *int64) error {
+	if len(categoryIDs) == 0 && !s.categoryPolicy.AllowUncategorized {
+		return ErrCategoriesRequired
 	}
-
-	// Get variants
-	variants, err := s.productRepo.GetProductVariantsByProductIDAndSKU(ctx, productID, sku)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	if primaryCategoryID != nil && !slices.Contains(categoryIDs, *primaryCategoryID) {
+		return ErrPrimaryCategoryNotListed
 	}
+	return nil
+}
 
-	return variants, nil
+// isCatalogStaff reports whether the request was made by someone who manages the
+// catalog and so may see inactive products
+func isCatalogStaff(ctx context.Context) bool {
+	role := userctx.Role(ctx)
+	return role == userctx.RoleAdmin || role == userctx.RoleEditor
 }
\ No newline at end of file
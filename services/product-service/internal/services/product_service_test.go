@@ -0,0 +1,1006 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockProductRepository is a mock implementation of ProductRepository.
+// Methods not overridden here fall through to the embedded nil interface.
+type MockProductRepository struct {
+	mock.Mock
+	repository.ProductRepository
+}
+
+func (m *MockProductRepository) GetProductByID(ctx context.Context, id int64) (*domain.Product, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	args := m.Called(ctx, sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) IsSKUTaken(ctx context.Context, sku string) (bool, error) {
+	args := m.Called(ctx, sku)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateProduct(ctx context.Context, id int64, product *domain.Product) error {
+	args := m.Called(ctx, id, product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) SuggestProducts(ctx context.Context, prefix string, limit int) ([]*domain.ProductSuggestion, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ProductSuggestion), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductVariantByID(ctx context.Context, id int64) (*domain.ProductVariant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductVariantsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.ProductVariant, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductRepository) GetProductVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	args := m.Called(ctx, sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProductVariant), args.Error(1)
+}
+
+func (m *MockProductRepository) CreateProduct(ctx context.Context, product *domain.Product) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) DeleteProduct(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) CreateProductVariant(ctx context.Context, variant *domain.ProductVariant) error {
+	args := m.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) UpdateProductVariant(ctx context.Context, id int64, variant *domain.ProductVariant) error {
+	args := m.Called(ctx, id, variant)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetProductsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Product, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*domain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) SearchProducts(ctx context.Context, query, mode string, activeOnly bool, offset, limit int) ([]*domain.Product, int64, error) {
+	args := m.Called(ctx, query, mode, activeOnly, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Product), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductRepository) GetPrimaryImages(ctx context.Context, productIDs []int64) (map[int64]*domain.ProductImage, error) {
+	args := m.Called(ctx, productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*domain.ProductImage), args.Error(1)
+}
+
+func (m *MockProductRepository) ListImagesForProducts(ctx context.Context, productIDs []int64) (map[int64][]*domain.ProductImage, error) {
+	args := m.Called(ctx, productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64][]*domain.ProductImage), args.Error(1)
+}
+
+func (m *MockProductRepository) ListProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ProductImage), args.Error(1)
+}
+
+func (m *MockProductRepository) BulkSetProductsActive(ctx context.Context, ids []int64, active bool) ([]int64, error) {
+	args := m.Called(ctx, ids, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockProductRepository) BulkDeleteProducts(ctx context.Context, ids []int64) ([]int64, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateProductCategories(ctx context.Context, productID int64, categoryIDs []int64, primaryCategoryID *int64) error {
+	args := m.Called(ctx, productID, categoryIDs, primaryCategoryID)
+	return args.Error(0)
+}
+
+func TestProductService_IsSKUAvailable(t *testing.T) {
+	t.Run("taken SKU", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("IsSKUTaken", mock.Anything, "SKU-123").Return(true, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		available, err := service.IsSKUAvailable(context.Background(), "SKU-123")
+
+		assert.NoError(t, err)
+		assert.False(t, available)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("free SKU", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("IsSKUTaken", mock.Anything, "SKU-456").Return(false, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		available, err := service.IsSKUAvailable(context.Background(), "SKU-456")
+
+		assert.NoError(t, err)
+		assert.True(t, available)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("whitespace is trimmed before lookup", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("IsSKUTaken", mock.Anything, "SKU-123").Return(true, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		available, err := service.IsSKUAvailable(context.Background(), "  SKU-123\t")
+
+		assert.NoError(t, err)
+		assert.False(t, available)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lookup is case-insensitive", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("IsSKUTaken", mock.Anything, "SKU-123").Return(true, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		available, err := service.IsSKUAvailable(context.Background(), "sku-123")
+
+		assert.NoError(t, err)
+		assert.False(t, available)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("blank SKU", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.IsSKUAvailable(context.Background(), "   ")
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "IsSKUTaken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("IsSKUTaken", mock.Anything, "SKU-123").Return(false, errors.New("database error"))
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.IsSKUAvailable(context.Background(), "SKU-123")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to check SKU availability")
+	})
+}
+
+func TestProductService_UpdateProduct_ComparePrice(t *testing.T) {
+	existing := func() *domain.Product {
+		return &domain.Product{ID: 1, Name: "Widget", SKU: "WIDGET-1", Price: 20, ComparePrice: 25, MetaTitle: "Widget", Version: 3}
+	}
+	version := 3
+
+	t.Run("set", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(1)).Return(existing(), nil)
+		mockRepo.On("UpdateProduct", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+		comparePrice := 30.0
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		product, err := service.UpdateProduct(context.Background(), 1, &dto.UpdateProductRequest{ComparePrice: &comparePrice, Version: &version})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, product.ComparePrice)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(1)).Return(existing(), nil)
+		mockRepo.On("UpdateProduct", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+		name := "Widget Pro"
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		product, err := service.UpdateProduct(context.Background(), 1, &dto.UpdateProductRequest{Name: &name, Version: &version})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Widget Pro", product.Name)
+		assert.Equal(t, 25.0, product.ComparePrice)
+		assert.Equal(t, "Widget", product.MetaTitle)
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(1)).Return(existing(), nil)
+		mockRepo.On("UpdateProduct", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		product, err := service.UpdateProduct(context.Background(), 1, &dto.UpdateProductRequest{
+			ClearFields: []string{"compare_price", "meta_title"},
+			Version:     &version,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, product.ComparePrice)
+		assert.Equal(t, "", product.MetaTitle)
+		assert.Equal(t, 20.0, product.Price)
+	})
+
+	t.Run("set and cleared together", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(1)).Return(existing(), nil)
+
+		comparePrice := 30.0
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.UpdateProduct(context.Background(), 1, &dto.UpdateProductRequest{
+			ComparePrice: &comparePrice,
+			ClearFields:  []string{"compare_price"},
+			Version:      &version,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidProductUpdate)
+		mockRepo.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(1)).Return(existing(), nil)
+		mockRepo.On("UpdateProduct", mock.Anything, int64(1), mock.MatchedBy(func(p *domain.Product) bool {
+			return p.Version == 2
+		})).Return(repository.ErrVersionConflict)
+
+		stale := 2
+		name := "Widget Pro"
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.UpdateProduct(context.Background(), 1, &dto.UpdateProductRequest{Name: &name, Version: &stale})
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	})
+}
+
+func TestProductService_ProductVariantSKU(t *testing.T) {
+	notFound := errors.New("not found")
+
+	t.Run("create rejects a SKU used by another product's variant", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(2)).Return(&domain.Product{ID: 2, SKU: "OF-2"}, nil)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1-RED").Return(nil, notFound)
+		mockRepo.On("GetProductVariantBySKU", mock.Anything, "BP-1-RED").Return(&domain.ProductVariant{ID: 7, ProductID: 1, SKU: "BP-1-RED"}, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.CreateProductVariant(context.Background(), &dto.CreateProductVariantRequest{ProductID: 2, Name: "Red", SKU: " bp-1-red "})
+
+		var existsErr *repository.AlreadyExistsError
+		require.ErrorAs(t, err, &existsErr)
+		assert.Equal(t, "product variant with SKU BP-1-RED already exists", err.Error())
+		mockRepo.AssertNotCalled(t, "CreateProductVariant", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create rejects a SKU used by a product", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(2)).Return(&domain.Product{ID: 2, SKU: "OF-2"}, nil)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1").Return(&domain.Product{ID: 1, SKU: "BP-1"}, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.CreateProductVariant(context.Background(), &dto.CreateProductVariantRequest{ProductID: 2, Name: "Red", SKU: "BP-1"})
+
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+		assert.Equal(t, "product with SKU BP-1 already exists", err.Error())
+		mockRepo.AssertNotCalled(t, "CreateProductVariant", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create with an unused SKU", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(2)).Return(&domain.Product{ID: 2, SKU: "OF-2"}, nil)
+		mockRepo.On("GetProductBySKU", mock.Anything, "OF-2-XL").Return(nil, notFound)
+		mockRepo.On("GetProductVariantBySKU", mock.Anything, "OF-2-XL").Return(nil, notFound)
+		mockRepo.On("CreateProductVariant", mock.Anything, mock.Anything).Return(nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		variant, err := service.CreateProductVariant(context.Background(), &dto.CreateProductVariantRequest{ProductID: 2, Name: "XL", SKU: "of-2-xl"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "OF-2-XL", variant.SKU)
+	})
+
+	t.Run("update rejects a SKU used by another product's variant", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductVariantByID", mock.Anything, int64(8)).Return(&domain.ProductVariant{ID: 8, ProductID: 2, SKU: "OF-2-XL"}, nil)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1-RED").Return(nil, notFound)
+		mockRepo.On("GetProductVariantBySKU", mock.Anything, "BP-1-RED").Return(&domain.ProductVariant{ID: 7, ProductID: 1, SKU: "BP-1-RED"}, nil)
+
+		sku := "BP-1-RED"
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		_, err := service.UpdateProductVariant(context.Background(), 8, &dto.UpdateProductVariantRequest{SKU: &sku})
+
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+		mockRepo.AssertNotCalled(t, "UpdateProductVariant", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update keeping its own SKU skips the check", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductVariantByID", mock.Anything, int64(8)).Return(&domain.ProductVariant{ID: 8, ProductID: 2, SKU: "OF-2-XL"}, nil)
+		mockRepo.On("UpdateProductVariant", mock.Anything, int64(8), mock.Anything).Return(nil)
+
+		sku := "of-2-xl"
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		variant, err := service.UpdateProductVariant(context.Background(), 8, &dto.UpdateProductVariantRequest{SKU: &sku})
+
+		require.NoError(t, err)
+		assert.Equal(t, "OF-2-XL", variant.SKU)
+		mockRepo.AssertNotCalled(t, "GetProductVariantBySKU", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductService_CreateInventory(t *testing.T) {
+	notFound := errors.New("not found")
+
+	t.Run("product with inventory", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1").Return(nil, notFound)
+		mockRepo.On("CreateProduct", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Product).ID = 5
+		}).Return(nil)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CreateInventory", mock.Anything, mock.Anything).Return(nil)
+		inventoryRepo.On("RecordStockMovement", mock.Anything, mock.Anything).Return(nil)
+
+		service := NewProductService(mockRepo, inventoryRepo, CategoryPolicy{}, nil)
+		_, err := service.CreateProduct(context.Background(), &dto.CreateProductRequest{
+			Name: "Brake Pad", SKU: "BP-1", Price: 19.99, Quantity: 25, MinQuantity: 2, CreateInventory: true,
+		})
+
+		require.NoError(t, err)
+		inventoryRepo.AssertCalled(t, "CreateInventory", mock.Anything, mock.MatchedBy(func(inv *domain.Inventory) bool {
+			return inv.ProductID == 5 && inv.ProductVariantID == nil && inv.Quantity == 25 &&
+				inv.AvailableQuantity == 25 && inv.MinStockLevel == 2 && inv.ReorderPoint == defaultReorderPoint
+		}))
+		inventoryRepo.AssertCalled(t, "RecordStockMovement", mock.Anything, mock.MatchedBy(func(m *domain.InventoryMovement) bool {
+			return m.ProductID == 5 && m.NewQuantity == 25 && m.Reference == "initial_stock"
+		}))
+	})
+
+	t.Run("product without inventory", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1").Return(nil, notFound)
+		mockRepo.On("CreateProduct", mock.Anything, mock.Anything).Return(nil)
+		inventoryRepo := new(MockInventoryRepository)
+
+		service := NewProductService(mockRepo, inventoryRepo, CategoryPolicy{}, nil)
+		_, err := service.CreateProduct(context.Background(), &dto.CreateProductRequest{Name: "Brake Pad", SKU: "BP-1", Price: 19.99, Quantity: 25})
+
+		require.NoError(t, err)
+		inventoryRepo.AssertNotCalled(t, "CreateInventory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed inventory removes the product", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1").Return(nil, notFound)
+		mockRepo.On("CreateProduct", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Product).ID = 5
+		}).Return(nil)
+		mockRepo.On("DeleteProduct", mock.Anything, int64(5)).Return(nil)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CreateInventory", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+		service := NewProductService(mockRepo, inventoryRepo, CategoryPolicy{}, nil)
+		_, err := service.CreateProduct(context.Background(), &dto.CreateProductRequest{
+			Name: "Brake Pad", SKU: "BP-1", Price: 19.99, Quantity: 25, CreateInventory: true,
+		})
+
+		assert.EqualError(t, err, "failed to create inventory: connection reset")
+		mockRepo.AssertCalled(t, "DeleteProduct", mock.Anything, int64(5))
+	})
+
+	t.Run("variant with inventory", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", mock.Anything, int64(5)).Return(&domain.Product{ID: 5, SKU: "BP-1"}, nil)
+		mockRepo.On("GetProductBySKU", mock.Anything, "BP-1-RED").Return(nil, notFound)
+		mockRepo.On("GetProductVariantBySKU", mock.Anything, "BP-1-RED").Return(nil, notFound)
+		mockRepo.On("CreateProductVariant", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ProductVariant).ID = 9
+		}).Return(nil)
+		inventoryRepo := new(MockInventoryRepository)
+		inventoryRepo.On("CreateInventory", mock.Anything, mock.Anything).Return(nil)
+		inventoryRepo.On("RecordStockMovement", mock.Anything, mock.Anything).Return(nil)
+
+		service := NewProductService(mockRepo, inventoryRepo, CategoryPolicy{}, nil)
+		_, err := service.CreateProductVariant(context.Background(), &dto.CreateProductVariantRequest{
+			ProductID: 5, Name: "Red", SKU: "BP-1-RED", Quantity: 8, CreateInventory: true,
+		})
+
+		require.NoError(t, err)
+		inventoryRepo.AssertCalled(t, "CreateInventory", mock.Anything, mock.MatchedBy(func(inv *domain.Inventory) bool {
+			return inv.ProductID == 5 && inv.ProductVariantID != nil && *inv.ProductVariantID == 9 && inv.AvailableQuantity == 8
+		}))
+	})
+}
+
+func TestProductService_SuggestProducts(t *testing.T) {
+	t.Run("caps the limit", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		suggestions := []*domain.ProductSuggestion{{ID: 1, Name: "Gear Shifter", SKU: "GS-001"}}
+		mockRepo.On("SuggestProducts", mock.Anything, "gear", 20).Return(suggestions, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		result, err := service.SuggestProducts(context.Background(), "  gear ", 500)
+
+		assert.NoError(t, err)
+		assert.Equal(t, suggestions, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("applies default limit", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("SuggestProducts", mock.Anything, "gear", 8).Return(nil, nil)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		result, err := service.SuggestProducts(context.Background(), "gear", 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		assert.NotNil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("blank prefix skips the repository", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		result, err := service.SuggestProducts(context.Background(), "   ", 5)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		mockRepo.AssertNotCalled(t, "SuggestProducts", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductService_GetProductsByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps request order and drops repeated and missing ids", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		// The repository returns a map, so its order carries no meaning
+		mockRepo.On("GetProductsByIDs", ctx, []int64{30, 10, 99, 20}).Return(map[int64]*domain.Product{
+			10: {ID: 10, Name: "Gloves", IsActive: true},
+			20: {ID: 20, Name: "Helmet", IsActive: true},
+			30: {ID: 30, Name: "Jacket", IsActive: true},
+		}, nil)
+		mockRepo.On("ListImagesForProducts", ctx, []int64{30, 10, 20}).Return(map[int64][]*domain.ProductImage{
+			20: {{ID: 1, ProductID: 20, URL: "https://cdn.example.com/helmet.jpg", IsPrimary: true}},
+		}, nil)
+
+		products, err := service.GetProductsByIDs(ctx, []int64{30, 10, 30, 99, 20, 10})
+
+		require.NoError(t, err)
+		ids := make([]int64, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+		assert.Equal(t, []int64{30, 10, 20}, ids)
+		require.NotNil(t, products[2].PrimaryImageURL)
+		assert.Equal(t, "https://cdn.example.com/helmet.jpg", *products[2].PrimaryImageURL)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("hides inactive products from everyone but staff", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		mockRepo.On("GetProductsByIDs", mock.Anything, []int64{10, 20}).Return(map[int64]*domain.Product{
+			10: {ID: 10, Name: "Gloves", IsActive: true},
+			20: {ID: 20, Name: "Retired helmet"},
+		}, nil)
+		mockRepo.On("ListImagesForProducts", mock.Anything, mock.Anything).Return(map[int64][]*domain.ProductImage{}, nil)
+
+		customer, err := service.GetProductsByIDs(userctx.WithRole(ctx, userctx.RoleUser), []int64{10, 20})
+		require.NoError(t, err)
+		require.Len(t, customer, 1)
+		assert.Equal(t, int64(10), customer[0].ID)
+
+		staff, err := service.GetProductsByIDs(userctx.WithRole(ctx, userctx.RoleEditor), []int64{10, 20})
+		require.NoError(t, err)
+		assert.Len(t, staff, 2)
+	})
+
+	t.Run("rejects an empty id list", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		_, err := service.GetProductsByIDs(ctx, nil)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetProductsByIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductService_BulkSetActive(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reports updated and missing ids in request order", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		mockRepo.On("BulkSetProductsActive", ctx, []int64{3, 99, 1}, false).Return([]int64{1, 3}, nil)
+
+		response, err := service.BulkSetActive(ctx, []int64{3, 99, 1, 3}, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.BulkProductResult{
+			{ID: 3, Status: dto.BulkProductUpdated},
+			{ID: 99, Status: dto.BulkProductNotFound},
+			{ID: 1, Status: dto.BulkProductUpdated},
+		}, response.Results)
+		assert.Equal(t, 2, response.Affected)
+		assert.Equal(t, 1, response.NotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("publishes an update event for each changed product", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		events := new(MockEventPublisher)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, events)
+
+		first := &domain.Product{ID: 1, IsActive: true}
+		second := &domain.Product{ID: 3, IsActive: true}
+		mockRepo.On("BulkSetProductsActive", ctx, []int64{1, 3, 99}, true).Return([]int64{1, 3}, nil)
+		mockRepo.On("GetProductsByIDs", ctx, []int64{1, 3}).Return(map[int64]*domain.Product{1: first, 3: second}, nil)
+		events.On("PublishEvent", ctx, domain.EventProductUpdated, first).Once()
+		events.On("PublishEvent", ctx, domain.EventProductUpdated, second).Once()
+
+		_, err := service.BulkSetActive(ctx, []int64{1, 3, 99}, true)
+
+		assert.NoError(t, err)
+		events.AssertExpectations(t)
+	})
+
+	t.Run("rejects an empty id list", func(t *testing.T) {
+		service := NewProductService(new(MockProductRepository), nil, CategoryPolicy{}, nil)
+
+		_, err := service.BulkSetActive(ctx, nil, true)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestProductService_UpdateProductCategories(t *testing.T) {
+	ctx := context.Background()
+	product := &domain.Product{ID: 10, Name: "Chain", SKU: "CH-1"}
+
+	t.Run("reports invalid category ids", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		mockRepo.On("GetProductByID", ctx, int64(10)).Return(product, nil)
+		mockRepo.On("UpdateProductCategories", ctx, int64(10), []int64{1, 98, 99}, (*int64)(nil)).
+			Return(&repository.InvalidCategoryIDsError{IDs: []int64{98, 99}})
+
+		err := service.UpdateProductCategories(ctx, 10, []int64{1, 98, 99}, nil)
+
+		var invalidErr *repository.InvalidCategoryIDsError
+		require.ErrorAs(t, err, &invalidErr)
+		assert.Equal(t, []int64{98, 99}, invalidErr.IDs)
+		assert.ErrorContains(t, err, "invalid category IDs: 98, 99")
+	})
+
+	t.Run("passes an explicit primary category on", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		primaryID := int64(2)
+		mockRepo.On("GetProductByID", ctx, int64(10)).Return(product, nil)
+		mockRepo.On("UpdateProductCategories", ctx, int64(10), []int64{1, 2}, &primaryID).Return(nil)
+
+		err := service.UpdateProductCategories(ctx, 10, []int64{1, 2}, &primaryID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a primary category that is not listed", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		primaryID := int64(3)
+		err := service.UpdateProductCategories(ctx, 10, []int64{1, 2}, &primaryID)
+
+		assert.ErrorIs(t, err, ErrPrimaryCategoryNotListed)
+		mockRepo.AssertNotCalled(t, "UpdateProductCategories", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an empty list unless uncategorized products are allowed", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+
+		err := NewProductService(mockRepo, nil, CategoryPolicy{}, nil).UpdateProductCategories(ctx, 10, []int64{}, nil)
+		assert.ErrorIs(t, err, ErrCategoriesRequired)
+
+		mockRepo.On("GetProductByID", ctx, int64(10)).Return(product, nil)
+		mockRepo.On("UpdateProductCategories", ctx, int64(10), []int64{}, (*int64)(nil)).Return(nil)
+
+		err = NewProductService(mockRepo, nil, CategoryPolicy{AllowUncategorized: true}, nil).UpdateProductCategories(ctx, 10, []int64{}, nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProductService_BulkDeleteProducts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reports deleted and missing ids", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		mockRepo.On("BulkDeleteProducts", ctx, []int64{1, 2, 99}).Return([]int64{1, 2}, nil)
+
+		response, err := service.BulkDeleteProducts(ctx, []int64{1, 2, 99})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.BulkProductResult{
+			{ID: 1, Status: dto.BulkProductDeleted},
+			{ID: 2, Status: dto.BulkProductDeleted},
+			{ID: 99, Status: dto.BulkProductNotFound},
+		}, response.Results)
+		assert.Equal(t, 2, response.Affected)
+		assert.Equal(t, 1, response.NotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the repository error without results", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+
+		mockRepo.On("BulkDeleteProducts", ctx, []int64{1, 2}).Return(nil, errors.New("foreign key violation"))
+
+		response, err := service.BulkDeleteProducts(ctx, []int64{1, 2})
+
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// seededProductRepository lists a fixed set of products the way the SQL queries do:
+// newest first by created_at, then id
+type seededProductRepository struct {
+	repository.ProductRepository
+	products []*domain.Product
+	images   map[int64][]*domain.ProductImage
+}
+
+func newSeededProductRepository(count int) *seededProductRepository {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &seededProductRepository{}
+	for id := 1; id <= count; id++ {
+		// Every three products share a timestamp so only the id tie-break orders them
+		createdAt := base.Add(time.Duration((id-1)/3) * time.Minute)
+		repo.products = append(repo.products, &domain.Product{ID: int64(id), IsActive: true, CreatedAt: createdAt})
+	}
+	sort.Slice(repo.products, func(i, j int) bool {
+		return productBefore(repo.products[i], repo.products[j])
+	})
+	return repo
+}
+
+// productBefore reports whether a is listed before b in the newest-first order
+func productBefore(a, b *domain.Product) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+func (r *seededProductRepository) ListProducts(ctx context.Context, filter *domain.ProductFilter, offset, limit int) ([]*domain.Product, int64, error) {
+	products := r.matching(filter)
+	end := offset + limit
+	if offset > len(products) {
+		offset = len(products)
+	}
+	if end > len(products) {
+		end = len(products)
+	}
+	return products[offset:end], int64(len(products)), nil
+}
+
+// matching returns the seeded products that pass the filter's is_active condition
+func (r *seededProductRepository) matching(filter *domain.ProductFilter) []*domain.Product {
+	if filter.IsActive == nil {
+		return r.products
+	}
+	var products []*domain.Product
+	for _, product := range r.products {
+		if product.IsActive == *filter.IsActive {
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+func (r *seededProductRepository) ListProductsAfter(ctx context.Context, filter *domain.ProductFilter, after *domain.ProductCursor, limit int) ([]*domain.Product, error) {
+	var page []*domain.Product
+	for _, product := range r.matching(filter) {
+		if after != nil && !productBefore(&domain.Product{ID: after.ID, CreatedAt: after.CreatedAt}, product) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, product)
+	}
+	return page, nil
+}
+
+func (r *seededProductRepository) ListImagesForProducts(ctx context.Context, productIDs []int64) (map[int64][]*domain.ProductImage, error) {
+	images := make(map[int64][]*domain.ProductImage)
+	for _, id := range productIDs {
+		if productImages, ok := r.images[id]; ok {
+			images[id] = productImages
+		}
+	}
+	return images, nil
+}
+
+func TestProductService_ListProducts_Cursor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cursor pages match offset pages", func(t *testing.T) {
+		service := NewProductService(newSeededProductRepository(23), nil, CategoryPolicy{}, nil)
+
+		var offsetIDs []int64
+		for page := 1; ; page++ {
+			response, err := service.ListProducts(ctx, &dto.ListProductsRequest{Page: page, Limit: 5})
+			require.NoError(t, err)
+			for _, product := range response.Products {
+				offsetIDs = append(offsetIDs, product.ID)
+			}
+			if page >= response.TotalPages {
+				assert.Empty(t, response.NextCursor)
+				break
+			}
+			assert.NotEmpty(t, response.NextCursor)
+		}
+
+		// The first page comes from offset mode, later pages follow its cursor
+		first, err := service.ListProducts(ctx, &dto.ListProductsRequest{Page: 1, Limit: 5})
+		require.NoError(t, err)
+		var cursorIDs []int64
+		for _, product := range first.Products {
+			cursorIDs = append(cursorIDs, product.ID)
+		}
+		for cursor := first.NextCursor; cursor != ""; {
+			response, err := service.ListProducts(ctx, &dto.ListProductsRequest{After: cursor, Limit: 5})
+			require.NoError(t, err)
+			assert.Zero(t, response.Page)
+			for _, product := range response.Products {
+				cursorIDs = append(cursorIDs, product.ID)
+			}
+			cursor = response.NextCursor
+		}
+
+		assert.Len(t, offsetIDs, 23)
+		assert.Equal(t, offsetIDs, cursorIDs)
+	})
+
+	t.Run("orders products sharing a timestamp by id", func(t *testing.T) {
+		service := NewProductService(newSeededProductRepository(6), nil, CategoryPolicy{}, nil)
+
+		first, err := service.ListProducts(ctx, &dto.ListProductsRequest{Page: 1, Limit: 2})
+		require.NoError(t, err)
+		second, err := service.ListProducts(ctx, &dto.ListProductsRequest{After: first.NextCursor, Limit: 2})
+		require.NoError(t, err)
+		third, err := service.ListProducts(ctx, &dto.ListProductsRequest{After: second.NextCursor, Limit: 2})
+		require.NoError(t, err)
+
+		var ids []int64
+		for _, response := range []*dto.ListProductsResponse{first, second, third} {
+			for _, product := range response.Products {
+				ids = append(ids, product.ID)
+			}
+		}
+		assert.Equal(t, []int64{6, 5, 4, 3, 2, 1}, ids)
+		assert.Empty(t, third.NextCursor)
+	})
+
+	t.Run("omits the cursor for custom sorts", func(t *testing.T) {
+		service := NewProductService(newSeededProductRepository(10), nil, CategoryPolicy{}, nil)
+
+		response, err := service.ListProducts(ctx, &dto.ListProductsRequest{Page: 1, Limit: 5, SortBy: "price"})
+
+		require.NoError(t, err)
+		assert.Empty(t, response.NextCursor)
+	})
+
+	t.Run("rejects a cursor with a custom sort", func(t *testing.T) {
+		service := NewProductService(newSeededProductRepository(10), nil, CategoryPolicy{}, nil)
+		first, err := service.ListProducts(ctx, &dto.ListProductsRequest{Page: 1, Limit: 5})
+		require.NoError(t, err)
+
+		_, err = service.ListProducts(ctx, &dto.ListProductsRequest{After: first.NextCursor, SortBy: "name", SortOrder: "asc"})
+
+		assert.ErrorIs(t, err, ErrProductCursorSort)
+	})
+
+	t.Run("rejects a malformed cursor", func(t *testing.T) {
+		service := NewProductService(newSeededProductRepository(10), nil, CategoryPolicy{}, nil)
+
+		_, err := service.ListProducts(ctx, &dto.ListProductsRequest{After: "not-a-cursor"})
+
+		assert.ErrorIs(t, err, ErrInvalidProductCursor)
+	})
+}
+
+func TestProductService_ListProducts_ActiveFilter(t *testing.T) {
+	repo := newSeededProductRepository(6)
+	for _, product := range repo.products {
+		product.IsActive = product.ID%2 == 0
+	}
+	service := NewProductService(repo, nil, CategoryPolicy{}, nil)
+	inactive := false
+
+	listIDs := func(t *testing.T, ctx context.Context, isActive *bool) []int64 {
+		t.Helper()
+		response, err := service.ListProducts(ctx, &dto.ListProductsRequest{IsActive: isActive, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		ids := []int64{}
+		for _, product := range response.Products {
+			ids = append(ids, product.ID)
+		}
+		return ids
+	}
+
+	t.Run("anonymous listing hides inactive products", func(t *testing.T) {
+		assert.Equal(t, []int64{6, 4, 2}, listIDs(t, context.Background(), nil))
+	})
+
+	t.Run("customers cannot ask for inactive products", func(t *testing.T) {
+		ctx := userctx.WithRole(context.Background(), userctx.RoleUser)
+		assert.Equal(t, []int64{6, 4, 2}, listIDs(t, ctx, &inactive))
+	})
+
+	t.Run("admins see every product by default", func(t *testing.T) {
+		ctx := userctx.WithRole(context.Background(), userctx.RoleAdmin)
+		assert.Equal(t, []int64{6, 5, 4, 3, 2, 1}, listIDs(t, ctx, nil))
+	})
+
+	t.Run("editors can list only inactive products", func(t *testing.T) {
+		ctx := userctx.WithRole(context.Background(), userctx.RoleEditor)
+		assert.Equal(t, []int64{5, 3, 1}, listIDs(t, ctx, &inactive))
+	})
+}
+
+func TestProductService_SearchProducts_ActiveFilter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("shoppers only search active products", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		mockRepo.On("SearchProducts", ctx, "gearbox", domain.ProductSearchFullText, true, 0, 20).Return([]*domain.Product{}, int64(0), nil)
+		mockRepo.On("ListImagesForProducts", mock.Anything, mock.Anything).Return(map[int64][]*domain.ProductImage{}, nil)
+
+		_, err := service.SearchProducts(ctx, "gearbox", "", 1, 20)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("staff search every product", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, nil, CategoryPolicy{}, nil)
+		staffCtx := userctx.WithRole(ctx, userctx.RoleAdmin)
+		mockRepo.On("SearchProducts", staffCtx, "gearbox", domain.ProductSearchFullText, false, 0, 20).Return([]*domain.Product{}, int64(0), nil)
+		mockRepo.On("ListImagesForProducts", mock.Anything, mock.Anything).Return(map[int64][]*domain.ProductImage{}, nil)
+
+		_, err := service.SearchProducts(staffCtx, "gearbox", "", 1, 20)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"pro", "gear box"}, normalizeTags([]string{" Pro", "gear box", "PRO", "  "}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestProductService_ProductImages(t *testing.T) {
+	ctx := context.Background()
+	images := func() []*domain.ProductImage {
+		return []*domain.ProductImage{
+			{ID: 7, ProductID: 1, URL: "https://cdn.example.com/side.jpg", Position: 0},
+			{ID: 4, ProductID: 1, URL: "https://cdn.example.com/front.jpg", Position: 1, IsPrimary: true},
+		}
+	}
+
+	t.Run("product carries its ordered images and primary URL", func(t *testing.T) {
+		mockRepo := new(MockProductRepository)
+		mockRepo.On("GetProductByID", ctx, int64(1)).Return(&domain.Product{ID: 1}, nil)
+		mockRepo.On("ListProductImages", ctx, int64(1)).Return(images(), nil)
+
+		product, err := NewProductService(mockRepo, nil, CategoryPolicy{}, nil).GetProductByID(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, product.Images, 2)
+		assert.Equal(t, int64(7), product.Images[0].ID)
+		require.NotNil(t, product.PrimaryImageURL)
+		assert.Equal(t, "https://cdn.example.com/front.jpg", *product.PrimaryImageURL)
+	})
+
+	t.Run("listed products carry their images", func(t *testing.T) {
+		repo := newSeededProductRepository(2)
+		repo.images = map[int64][]*domain.ProductImage{1: images()}
+
+		response, err := NewProductService(repo, nil, CategoryPolicy{}, nil).ListProducts(ctx, &dto.ListProductsRequest{Page: 1, Limit: 10})
+
+		require.NoError(t, err)
+		require.Len(t, response.Products, 2)
+		withImages, withoutImages := response.Products[1], response.Products[0]
+		assert.Equal(t, int64(1), withImages.ID)
+		assert.Equal(t, []dto.ProductImageResponse{
+			{ID: 7, URL: "https://cdn.example.com/side.jpg", Position: 0},
+			{ID: 4, URL: "https://cdn.example.com/front.jpg", Position: 1, IsPrimary: true},
+		}, withImages.Images)
+		require.NotNil(t, withImages.PrimaryImageURL)
+		assert.Equal(t, "https://cdn.example.com/front.jpg", *withImages.PrimaryImageURL)
+		assert.Empty(t, withoutImages.Images)
+		assert.NotNil(t, withoutImages.Images)
+		assert.Nil(t, withoutImages.PrimaryImageURL)
+	})
+}
@@ -0,0 +1,20 @@
+package services
+
+import "strings"
+
+// normalizeTags trims and lowercases tags and drops blanks and repeats, keeping the
+// first occurrence's position. Tags are matched exactly, so every write and filter goes
+// through here. The result is never nil: the tags column is NOT NULL.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,46 @@
+package services
+
+import "fmt"
+
+// Smallest quantities the services accept. A cart item holds at least one unit; an item
+// is removed by deleting it, not by setting its quantity to zero. Stock may run out but
+// never goes negative.
+const (
+	minCartItemQuantity = 1
+	minStockQuantity    = 0
+)
+
+// QuantityError is returned when a requested quantity is below what the operation allows
+type QuantityError struct {
+	Quantity int
+	Min      int
+}
+
+func (e *QuantityError) Error() string {
+	return fmt.Sprintf("quantity must be at least %d, got %d", e.Min, e.Quantity)
+}
+
+// checkQuantity returns a *QuantityError when quantity is below min
+func checkQuantity(quantity, min int) error {
+	if quantity < min {
+		return &QuantityError{Quantity: quantity, Min: min}
+	}
+	return nil
+}
+
+// checkMovementQuantity checks the quantity of a stock movement. Adjustments set the stock
+// level, which may be zero; every other movement moves at least one unit.
+func checkMovementQuantity(movementType string, quantity int) error {
+	if movementType == "adjustment" {
+		return checkQuantity(quantity, minStockQuantity)
+	}
+	return checkQuantity(quantity, 1)
+}
+
+// checkCartItemQuantity is checkQuantity for the units of a cart item
+func checkCartItemQuantity(quantity int) error {
+	if err := checkQuantity(quantity, minCartItemQuantity); err != nil {
+		return fmt.Errorf("%w; delete the item to remove it", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// ShippingCart is what a rate provider needs to know about a cart to quote it
+type ShippingCart struct {
+	CartID    int64
+	Currency  string
+	Subtotal  float64
+	ItemCount int
+	// ShippableItems counts the units that need shipping; digital products are left out
+	ShippableItems int
+	// Weight is the sum of each item's billable weight times its quantity, in the unit
+	// product weights use. Bulky items count at their dimensional weight.
+	Weight float64
+}
+
+// ShippingRateProvider quotes the shipping methods available for a cart
+type ShippingRateProvider interface {
+	GetRates(ctx context.Context, cart *ShippingCart, destination domain.ShippingDestination) ([]domain.ShippingRate, error)
+}
+
+// ShippingMethod is a shipping method priced by cart weight
+type ShippingMethod struct {
+	ID            int64
+	Name          string
+	EstimatedDays int
+	// Countries limits the method to these ISO country codes; empty ships everywhere
+	Countries []string
+	// Tiers price the method by weight. A cart uses the first tier whose MaxWeight it
+	// does not exceed; a MaxWeight of 0 has no upper bound. Carts heavier than every
+	// tier cannot use the method.
+	Tiers []ShippingRateTier
+}
+
+// ShippingRateTier is the cost of a shipping method up to a cart weight
+type ShippingRateTier struct {
+	MaxWeight float64
+	Cost      float64
+}
+
+type tieredShippingRateProvider struct {
+	methods []ShippingMethod
+}
+
+// NewTieredShippingRateProvider returns a provider that prices each method by the
+// weight tier the cart falls into. A method with a single unbounded tier is a flat rate.
+func NewTieredShippingRateProvider(methods []ShippingMethod) ShippingRateProvider {
+	sorted := make([]ShippingMethod, len(methods))
+	for i, method := range methods {
+		tiers := append([]ShippingRateTier(nil), method.Tiers...)
+		sort.SliceStable(tiers, func(a, b int) bool {
+			return tierLimit(tiers[a]) < tierLimit(tiers[b])
+		})
+		method.Tiers = tiers
+		sorted[i] = method
+	}
+	return &tieredShippingRateProvider{methods: sorted}
+}
+
+// GetRates returns the methods that ship to the destination and can carry the cart
+func (p *tieredShippingRateProvider) GetRates(ctx context.Context, cart *ShippingCart, destination domain.ShippingDestination) ([]domain.ShippingRate, error) {
+	rates := []domain.ShippingRate{}
+	for _, method := range p.methods {
+		if !shipsTo(method, destination.Country) {
+			continue
+		}
+
+		tier, ok := tierFor(method.Tiers, cart.Weight)
+		if !ok {
+			continue
+		}
+
+		rates = append(rates, domain.ShippingRate{
+			MethodID:      method.ID,
+			Method:        method.Name,
+			Amount:        domain.FromMinorUnits(domain.ToMinorUnits(tier.Cost, cart.Currency), cart.Currency),
+			EstimatedDays: method.EstimatedDays,
+		})
+	}
+
+	return rates, nil
+}
+
+func shipsTo(method ShippingMethod, country string) bool {
+	if len(method.Countries) == 0 {
+		return true
+	}
+	for _, allowed := range method.Countries {
+		if strings.EqualFold(allowed, country) {
+			return true
+		}
+	}
+	return false
+}
+
+func tierFor(tiers []ShippingRateTier, weight float64) (ShippingRateTier, bool) {
+	for _, tier := range tiers {
+		if tier.MaxWeight == 0 || weight <= tier.MaxWeight {
+			return tier, true
+		}
+	}
+	return ShippingRateTier{}, false
+}
+
+// tierLimit orders unbounded tiers after every bounded one
+func tierLimit(tier ShippingRateTier) float64 {
+	if tier.MaxWeight == 0 {
+		return math.MaxFloat64
+	}
+	return tier.MaxWeight
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredShippingRateProvider_GetRates(t *testing.T) {
+	ctx := context.Background()
+	provider := NewTieredShippingRateProvider([]ShippingMethod{
+		// Tiers deliberately out of order; the provider sorts them
+		{ID: 1, Name: "Standard", EstimatedDays: 5, Tiers: []ShippingRateTier{
+			{Cost: 25}, {MaxWeight: 5, Cost: 9}, {MaxWeight: 1, Cost: 5},
+		}},
+		{ID: 2, Name: "Express", EstimatedDays: 2, Countries: []string{"US", "CA"}, Tiers: []ShippingRateTier{
+			{MaxWeight: 1, Cost: 12}, {MaxWeight: 5, Cost: 20.005},
+		}},
+	})
+	us := domain.ShippingDestination{Country: "us"}
+
+	tests := []struct {
+		name     string
+		weight   float64
+		expected []domain.ShippingRate
+	}{
+		{"light cart uses the first tier", 0.5, []domain.ShippingRate{
+			{MethodID: 1, Method: "Standard", Amount: 5, EstimatedDays: 5},
+			{MethodID: 2, Method: "Express", Amount: 12, EstimatedDays: 2},
+		}},
+		{"tier limits are inclusive", 1, []domain.ShippingRate{
+			{MethodID: 1, Method: "Standard", Amount: 5, EstimatedDays: 5},
+			{MethodID: 2, Method: "Express", Amount: 12, EstimatedDays: 2},
+		}},
+		{"middle tier is rounded to cents", 3, []domain.ShippingRate{
+			{MethodID: 1, Method: "Standard", Amount: 9, EstimatedDays: 5},
+			{MethodID: 2, Method: "Express", Amount: 20.01, EstimatedDays: 2},
+		}},
+		{"heavy cart only fits the unbounded tier", 40, []domain.ShippingRate{
+			{MethodID: 1, Method: "Standard", Amount: 25, EstimatedDays: 5},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := provider.GetRates(ctx, &ShippingCart{Currency: "USD", Weight: tt.weight}, us)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rates)
+		})
+	}
+
+	t.Run("methods limited to other countries are not offered", func(t *testing.T) {
+		rates, err := provider.GetRates(ctx, &ShippingCart{Currency: "USD", Weight: 0.5}, domain.ShippingDestination{Country: "DE"})
+
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "Standard", rates[0].Method)
+	})
+}
+
+func TestCartService_GetShippingOptions(t *testing.T) {
+	ctx := context.Background()
+	variantID := int64(20)
+	provider := NewTieredShippingRateProvider([]ShippingMethod{
+		{ID: 1, Name: "Standard", EstimatedDays: 5, Tiers: []ShippingRateTier{{MaxWeight: 5, Cost: 9}, {Cost: 25}}},
+	})
+	destination := domain.ShippingDestination{Country: "US"}
+
+	t.Run("prices by item weight times quantity, preferring variant weight", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, provider, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return([]*domain.CartItem{
+			{ProductID: 5, Quantity: 2, TotalPrice: 20},
+			{ProductID: 6, ProductVariantID: &variantID, Quantity: 1, TotalPrice: 30},
+		}, int64(2), nil)
+		productRepo.On("GetProductsByIDs", ctx, []int64{5, 6}).Return(map[int64]*domain.Product{
+			5: {ID: 5, Weight: 1.5},
+			6: {ID: 6, Weight: 0.5},
+		}, nil)
+		productRepo.On("GetProductVariantByID", ctx, variantID).Return(&domain.ProductVariant{ID: variantID, Weight: 2.5}, nil)
+
+		// 2 × 1.5 + 1 × 2.5 = 5.5, just over the first tier
+		rates, err := service.GetShippingOptions(ctx, 1, destination)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ShippingRate{{MethodID: 1, Method: "Standard", Amount: 25, EstimatedDays: 5}}, rates)
+	})
+
+	t.Run("empty cart has no shipping options", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		service := NewCartService(cartRepo, new(MockProductRepository), new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, provider, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD"}, nil)
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return([]*domain.CartItem{}, int64(0), nil)
+
+		_, err := service.GetShippingOptions(ctx, 1, destination)
+
+		assert.ErrorIs(t, err, repository.ErrCartEmpty)
+	})
+}
+
+func TestProduct_ShippingProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		product  domain.Product
+		weight   float64
+		divisor  float64
+		expected domain.ShippingProfile
+	}{
+		{"heavy for its size ships on weight", domain.Product{Dimensions: "10x10x10"}, 2, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassStandard, Weight: 2, DimensionalWeight: 0.2}},
+		{"bulky for its weight ships on size", domain.Product{Dimensions: "40 x 30 x 20 cm"}, 1, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassDimensional, Weight: 4.8, DimensionalWeight: 4.8}},
+		{"sides in other units are converted to centimetres", domain.Product{Dimensions: "400x300x200mm"}, 1, 6000,
+			domain.ShippingProfile{Class: domain.ShippingClassDimensional, Weight: 4, DimensionalWeight: 4}},
+		{"each side may carry its unit", domain.Product{Dimensions: "40cm x 30cm x 20cm"}, 1, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassDimensional, Weight: 4.8, DimensionalWeight: 4.8}},
+		{"no dimensions ships on weight", domain.Product{}, 1.5, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassStandard, Weight: 1.5}},
+		{"unreadable dimensions are ignored", domain.Product{Dimensions: "large"}, 1.5, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassStandard, Weight: 1.5}},
+		{"digital products weigh nothing", domain.Product{IsDigital: true, Dimensions: "40x30x20"}, 1, 0,
+			domain.ShippingProfile{Class: domain.ShippingClassDigital}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := tt.product.ShippingProfile(tt.weight, tt.divisor)
+
+			assert.Equal(t, tt.expected.Class, profile.Class)
+			assert.InDelta(t, tt.expected.Weight, profile.Weight, 1e-9)
+			assert.InDelta(t, tt.expected.DimensionalWeight, profile.DimensionalWeight, 1e-9)
+		})
+	}
+}
+
+func TestCartService_SetCartShipping(t *testing.T) {
+	ctx := context.Background()
+	provider := NewTieredShippingRateProvider([]ShippingMethod{
+		{ID: 1, Name: "Standard", EstimatedDays: 5, Tiers: []ShippingRateTier{{MaxWeight: 5, Cost: 9}, {Cost: 25}}},
+		{ID: 2, Name: "Express", EstimatedDays: 2, Tiers: []ShippingRateTier{{MaxWeight: 5, Cost: 20}}},
+	})
+	request := &dto.SetShippingRequest{ShippingMethodID: 1, ShippingMethod: "Standard", EstimatedDays: 9}
+
+	setup := func(products map[int64]*domain.Product, items []*domain.CartItem) (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, provider, nil, CartExpiryPolicy{})
+
+		productIDs := make([]int64, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(&domain.Cart{ID: 1, Currency: "USD", ShipCountry: "US"}, nil)
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return(items, int64(len(items)), nil)
+		productRepo.On("GetProductsByIDs", ctx, productIDs).Return(products, nil)
+		return cartRepo, service
+	}
+
+	t.Run("bulky items are quoted at their dimensional weight", func(t *testing.T) {
+		cartRepo, service := setup(map[int64]*domain.Product{
+			5: {ID: 5, Weight: 1, Dimensions: "40x30x20"},
+		}, []*domain.CartItem{{ProductID: 5, Quantity: 2, TotalPrice: 40}})
+		cartRepo.On("SetCartShipping", ctx, mock.AnythingOfType("*domain.CartShipping")).Return(nil)
+
+		// 2 × 4.8 dimensional weight = 9.6, past the first tier; actual weight 2 would fit it
+		shipping, err := service.SetCartShipping(ctx, 1, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, shipping.ShippingAmount)
+		assert.Equal(t, 5, shipping.EstimatedDays)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("a cart of digital products ships free", func(t *testing.T) {
+		cartRepo, service := setup(map[int64]*domain.Product{
+			5: {ID: 5, Weight: 1, Dimensions: "40x30x20", IsDigital: true},
+			6: {ID: 6, IsDigital: true},
+		}, []*domain.CartItem{{ProductID: 5, Quantity: 2, TotalPrice: 40}, {ProductID: 6, Quantity: 1, TotalPrice: 10}})
+		cartRepo.On("SetCartShipping", ctx, mock.AnythingOfType("*domain.CartShipping")).Return(nil)
+
+		shipping, err := service.SetCartShipping(ctx, 1, request)
+
+		require.NoError(t, err)
+		assert.Zero(t, shipping.ShippingAmount)
+		cartRepo.AssertExpectations(t)
+
+		options, err := service.GetShippingOptions(ctx, 1, domain.ShippingDestination{Country: "US"})
+
+		require.NoError(t, err)
+		assert.Empty(t, options)
+	})
+
+	t.Run("digital items add no weight to a mixed cart", func(t *testing.T) {
+		cartRepo, service := setup(map[int64]*domain.Product{
+			5: {ID: 5, Weight: 2},
+			6: {ID: 6, Weight: 50, IsDigital: true},
+		}, []*domain.CartItem{{ProductID: 5, Quantity: 1, TotalPrice: 20}, {ProductID: 6, Quantity: 1, TotalPrice: 10}})
+		cartRepo.On("SetCartShipping", ctx, mock.AnythingOfType("*domain.CartShipping")).Return(nil)
+
+		shipping, err := service.SetCartShipping(ctx, 1, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, 9.0, shipping.ShippingAmount)
+	})
+
+	t.Run("a method that cannot carry the cart is refused", func(t *testing.T) {
+		cartRepo, service := setup(map[int64]*domain.Product{
+			5: {ID: 5, Weight: 1, Dimensions: "40x30x20"},
+		}, []*domain.CartItem{{ProductID: 5, Quantity: 2, TotalPrice: 40}})
+
+		_, err := service.SetCartShipping(ctx, 1, &dto.SetShippingRequest{ShippingMethodID: 2, ShippingMethod: "Express", EstimatedDays: 2})
+
+		assert.ErrorIs(t, err, ErrShippingMethodUnavailable)
+		cartRepo.AssertNotCalled(t, "SetCartShipping", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCartService_ShippingIsQuotedForTheCurrentCart(t *testing.T) {
+	ctx := context.Background()
+	provider := NewTieredShippingRateProvider([]ShippingMethod{
+		{ID: 1, Name: "Standard", EstimatedDays: 5, Tiers: []ShippingRateTier{{MaxWeight: 5, Cost: 9}, {Cost: 25}}},
+		{ID: 2, Name: "Express", EstimatedDays: 2, Tiers: []ShippingRateTier{{MaxWeight: 5, Cost: 20}}},
+	})
+	cart := &domain.Cart{ID: 1, Currency: "USD", ShipCountry: "US"}
+
+	setup := func(items []*domain.CartItem) (*MockCartRepository, CartService) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		service := NewCartService(cartRepo, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, provider, nil, CartExpiryPolicy{})
+
+		cartRepo.On("GetCartByID", ctx, int64(1)).Return(cart, nil).Maybe()
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return(items, int64(len(items)), nil)
+		productRepo.On("GetProductsByIDs", ctx, mock.Anything).Return(map[int64]*domain.Product{5: {ID: 5, Weight: 2}}, nil).Maybe()
+		return cartRepo, service
+	}
+
+	t.Run("an empty cart is not charged the amount a client asks for", func(t *testing.T) {
+		cartRepo, service := setup(nil)
+		cartRepo.On("SetCartShipping", ctx, mock.AnythingOfType("*domain.CartShipping")).Return(nil)
+
+		shipping, err := service.SetCartShipping(ctx, 1, &dto.SetShippingRequest{ShippingMethodID: 1, ShippingMethod: "Standard", EstimatedDays: 5})
+
+		require.NoError(t, err)
+		assert.Zero(t, shipping.ShippingAmount)
+	})
+
+	t.Run("changing the method quotes it again", func(t *testing.T) {
+		cartRepo, service := setup([]*domain.CartItem{{ProductID: 5, Quantity: 1, TotalPrice: 20}})
+		cartRepo.On("GetCartShipping", ctx, int64(1)).Return(&domain.CartShipping{CartID: 1, ShippingMethodID: 1, ShippingMethod: "Standard", ShippingAmount: 9, EstimatedDays: 5}, nil)
+		cartRepo.On("UpdateCartShipping", ctx, int64(1), mock.AnythingOfType("*domain.CartShipping")).Return(nil)
+
+		express := int64(2)
+		shipping, err := service.UpdateCartShipping(ctx, 1, &dto.UpdateShippingRequest{ShippingMethodID: &express})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Express", shipping.ShippingMethod)
+		assert.Equal(t, 20.0, shipping.ShippingAmount)
+		assert.Equal(t, 2, shipping.EstimatedDays)
+	})
+
+	t.Run("the summary charges for the items in the cart now", func(t *testing.T) {
+		// Standard was quoted at 9 for one item; three items weigh 6 and fall in the next tier
+		cartRepo, service := setup([]*domain.CartItem{{ProductID: 5, Quantity: 3, TotalPrice: 60}})
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+			CartID: 1, Currency: "USD", Subtotal: 60, ShippingAmount: 9, ShippingMethodID: 1,
+			Destination: domain.ShippingDestination{Country: "US"},
+		}, nil)
+
+		summary, err := service.GetCartSummary(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, summary.ShippingAmount)
+		assert.Equal(t, 85.0, summary.TotalAmount)
+	})
+
+	t.Run("a method that can no longer carry the cart fails validation", func(t *testing.T) {
+		cartRepo := new(MockCartRepository)
+		productRepo := new(MockProductRepository)
+		inventoryRepo := new(MockInventoryRepository)
+		service := NewCartService(cartRepo, productRepo, inventoryRepo, new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, provider, nil, CartExpiryPolicy{})
+
+		items := []*domain.CartItem{{ID: 1, ProductID: 5, Quantity: 3, TotalPrice: 60}}
+		cartRepo.On("GetCartItems", ctx, int64(1), 0, 0).Return(items, int64(1), nil)
+		productRepo.On("GetProductsByIDs", ctx, []int64{5}).Return(map[int64]*domain.Product{5: {ID: 5, Weight: 2, IsActive: true}}, nil)
+		inventoryRepo.On("GetInventoryByProduct", ctx, int64(5), (*int64)(nil)).Return(&domain.Inventory{AvailableQuantity: 3}, nil)
+		// Express carries up to 5; the cart now weighs 6
+		cartRepo.On("GetCartSummary", ctx, int64(1)).Return(&domain.CartSummary{
+			CartID: 1, Currency: "USD", Subtotal: 60, ShippingAmount: 20, ShippingMethodID: 2,
+			Destination: domain.ShippingDestination{Country: "US"},
+		}, nil)
+
+		result, err := service.ValidateCartForCheckout(ctx, 1)
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.True(t, result.ShippingUnavailable)
+		assert.Empty(t, result.Issues)
+	})
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+)
+
+// TaxService resolves the tax rates charged on a cart shipped to an address. Several
+// rates can apply at once, such as a state and a city rate. No rates means the
+// address is not covered and the flat default rate applies.
+type TaxService interface {
+	GetTaxRates(ctx context.Context, address domain.ShippingDestination) ([]domain.TaxRate, error)
+}
+
+// TaxJurisdiction is a tax rate charged on carts shipped within an area
+type TaxJurisdiction struct {
+	Name string
+	// Country is the ISO country code the rate applies in
+	Country string
+	// State limits the rate to a state or province; empty covers the whole country
+	State string
+	// PostalPrefix limits the rate to postal codes starting with it; empty covers all
+	PostalPrefix string
+	Rate         float64
+}
+
+type staticTaxService struct {
+	jurisdictions []TaxJurisdiction
+}
+
+// NewStaticTaxService returns a tax service backed by a fixed table of jurisdictions.
+// Every jurisdiction covering an address contributes its rate.
+func NewStaticTaxService(jurisdictions []TaxJurisdiction) TaxService {
+	return &staticTaxService{jurisdictions: append([]TaxJurisdiction(nil), jurisdictions...)}
+}
+
+// GetTaxRates returns the rates of the jurisdictions covering the address, in table order
+func (s *staticTaxService) GetTaxRates(ctx context.Context, address domain.ShippingDestination) ([]domain.TaxRate, error) {
+	var rates []domain.TaxRate
+	for _, j := range s.jurisdictions {
+		if !covers(j, address) {
+			continue
+		}
+		rates = append(rates, domain.TaxRate{Name: j.Name, Rate: j.Rate})
+	}
+	return rates, nil
+}
+
+// covers reports whether a jurisdiction includes an address
+func covers(j TaxJurisdiction, address domain.ShippingDestination) bool {
+	if !strings.EqualFold(j.Country, address.Country) {
+		return false
+	}
+	if j.State != "" && !strings.EqualFold(j.State, address.State) {
+		return false
+	}
+	postalCode := strings.ToUpper(strings.ReplaceAll(address.PostalCode, " ", ""))
+	prefix := strings.ToUpper(strings.ReplaceAll(j.PostalPrefix, " ", ""))
+	return strings.HasPrefix(postalCode, prefix)
+}
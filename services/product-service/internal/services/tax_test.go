@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticTaxService_GetTaxRates(t *testing.T) {
+	ctx := context.Background()
+	service := NewStaticTaxService([]TaxJurisdiction{
+		{Name: "CA state tax", Country: "US", State: "CA", Rate: 0.0725},
+		{Name: "SF city tax", Country: "US", State: "CA", PostalPrefix: "941", Rate: 0.015},
+		{Name: "UK VAT", Country: "GB", Rate: 0.2},
+	})
+
+	tests := []struct {
+		name     string
+		address  domain.ShippingDestination
+		expected []domain.TaxRate
+	}{
+		{"state rate", domain.ShippingDestination{Country: "US", State: "CA", PostalCode: "90001"}, []domain.TaxRate{
+			{Name: "CA state tax", Rate: 0.0725},
+		}},
+		{"state and city rates stack", domain.ShippingDestination{Country: "us", State: "ca", PostalCode: "94105"}, []domain.TaxRate{
+			{Name: "CA state tax", Rate: 0.0725},
+			{Name: "SF city tax", Rate: 0.015},
+		}},
+		{"country wide rate ignores state and postal code", domain.ShippingDestination{Country: "GB", PostalCode: "SW1A 1AA"}, []domain.TaxRate{
+			{Name: "UK VAT", Rate: 0.2},
+		}},
+		{"other state is not covered", domain.ShippingDestination{Country: "US", State: "OR", PostalCode: "97201"}, nil},
+		{"other country is not covered", domain.ShippingDestination{Country: "DE"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := service.GetTaxRates(ctx, tt.address)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rates)
+		})
+	}
+}
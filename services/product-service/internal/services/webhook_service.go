@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+type WebhookService interface {
+	// Subscriptions
+	RegisterSubscription(ctx context.Context, req *dto.CreateWebhookSubscriptionRequest) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DisableSubscription(ctx context.Context, id int64) error
+
+	// Events
+	Publish(ctx context.Context, eventType string, data interface{}) error
+	PublishEvent(ctx context.Context, eventType string, data interface{})
+}
+
+// WebhookDeliveryQueue accepts deliveries for asynchronous sending
+type WebhookDeliveryQueue interface {
+	Enqueue(delivery *domain.WebhookDelivery) error
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	queue       WebhookDeliveryQueue
+	now         func() time.Time
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, queue WebhookDeliveryQueue) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		queue:       queue,
+		now:         time.Now,
+	}
+}
+
+// Subscriptions
+
+// RegisterSubscription stores a new subscription, generating a signing secret if none was given
+func (s *webhookService) RegisterSubscription(ctx context.Context, req *dto.CreateWebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	secret := req.Secret
+	if secret == "" {
+		generated, err := randomHex(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = generated
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		IsActive:   true,
+	}
+
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to register webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DisableSubscription stops deliveries to a subscription
+func (s *webhookService) DisableSubscription(ctx context.Context, id int64) error {
+	if err := s.webhookRepo.DisableSubscription(ctx, id); err != nil {
+		return fmt.Errorf("failed to disable webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Events
+
+// Publish queues an event for every active subscription interested in eventType.
+// Delivery happens in the background; a full queue drops the delivery with a warning.
+func (s *webhookService) Publish(ctx context.Context, eventType string, data interface{}) error {
+	subscriptions, err := s.webhookRepo.GetActiveSubscriptionsForEvent(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	eventID, err := randomHex(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	payload, err := json.Marshal(domain.WebhookEvent{
+		ID:         eventID,
+		Type:       eventType,
+		OccurredAt: s.now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		delivery := &domain.WebhookDelivery{
+			Subscription: subscription,
+			EventID:      eventID,
+			EventType:    eventType,
+			Payload:      payload,
+		}
+		if err := s.queue.Enqueue(delivery); err != nil {
+			logger.FromContext(ctx).Warn("dropping webhook",
+				"event_id", eventID, "subscription_id", subscription.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// PublishEvent is Publish for the services that report domain events, which must not
+// fail because of webhooks. It satisfies EventPublisher; errors are logged.
+func (s *webhookService) PublishEvent(ctx context.Context, eventType string, data interface{}) {
+	if err := s.Publish(ctx, eventType, data); err != nil {
+		logger.FromContext(ctx).Warn("dropping event", "event_type", eventType, "error", err)
+	}
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/dto"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/repository"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sharingWishlistRepository is an in-memory CartRepository holding wishlists and their
+// items, looking up shared wishlists the way the database query does
+type sharingWishlistRepository struct {
+	repository.CartRepository
+
+	wishlists map[int64]*domain.Wishlist
+	items     map[int64][]*domain.WishlistItem
+}
+
+func newSharingWishlistRepository() *sharingWishlistRepository {
+	return &sharingWishlistRepository{
+		wishlists: map[int64]*domain.Wishlist{},
+		items:     map[int64][]*domain.WishlistItem{},
+	}
+}
+
+func (r *sharingWishlistRepository) CreateWishlist(ctx context.Context, wishlist *domain.Wishlist) error {
+	wishlist.ID = int64(len(r.wishlists) + 1)
+	stored := *wishlist
+	r.wishlists[wishlist.ID] = &stored
+	return nil
+}
+
+func (r *sharingWishlistRepository) GetWishlistByID(ctx context.Context, id int64) (*domain.Wishlist, error) {
+	wishlist, ok := r.wishlists[id]
+	if !ok {
+		return nil, repository.ErrWishlistNotFound
+	}
+	stored := *wishlist
+	return &stored, nil
+}
+
+func (r *sharingWishlistRepository) UpdateWishlist(ctx context.Context, id int64, wishlist *domain.Wishlist) error {
+	stored := *wishlist
+	r.wishlists[id] = &stored
+	return nil
+}
+
+func (r *sharingWishlistRepository) GetPublicWishlistByShareToken(ctx context.Context, token string) (*domain.Wishlist, error) {
+	for _, wishlist := range r.wishlists {
+		if wishlist.IsPublic && wishlist.ShareToken != nil && *wishlist.ShareToken == token {
+			return wishlist, nil
+		}
+	}
+	return nil, repository.ErrWishlistNotFound
+}
+
+func (r *sharingWishlistRepository) GetWishlistItems(ctx context.Context, wishlistID int64, offset, limit int) ([]*domain.WishlistItem, int64, error) {
+	items := r.items[wishlistID]
+	return items, int64(len(items)), nil
+}
+
+func TestCartService_WishlistSharing(t *testing.T) {
+	// The owner is signed in; the shared view is read by anyone
+	ctx := userctx.WithUserID(context.Background(), 7)
+	public, private := true, false
+
+	newService := func(wishlists *sharingWishlistRepository, productRepo *MockProductRepository) CartService {
+		return NewCartService(wishlists, productRepo, new(MockInventoryRepository), new(MockCouponRepository), CartPricingPolicy{}, CouponRedemptionPolicy{}, nil, nil, CartExpiryPolicy{})
+	}
+
+	t.Run("private wishlists have no share token", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		service := newService(wishlists, new(MockProductRepository))
+
+		wishlist, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday"})
+
+		require.NoError(t, err)
+		assert.Nil(t, wishlist.ShareToken)
+		assert.Nil(t, wishlists.wishlists[wishlist.ID].ShareToken)
+	})
+
+	t.Run("public wishlists get an unguessable token", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		service := newService(wishlists, new(MockProductRepository))
+
+		first, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday", IsPublic: true})
+		require.NoError(t, err)
+		second, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Holidays", IsPublic: true})
+		require.NoError(t, err)
+
+		require.NotNil(t, first.ShareToken)
+		require.NotNil(t, second.ShareToken)
+		assert.Regexp(t, `^[0-9a-f]{32}$`, *first.ShareToken)
+		assert.NotEqual(t, *first.ShareToken, *second.ShareToken)
+		assert.Equal(t, first.ShareToken, wishlists.wishlists[first.ID].ShareToken)
+	})
+
+	t.Run("renaming a public wishlist keeps its token", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		service := newService(wishlists, new(MockProductRepository))
+
+		wishlist, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday", IsPublic: true})
+		require.NoError(t, err)
+
+		name := "Birthday 2026"
+		updated, err := service.UpdateWishlist(ctx, wishlist.ID, &dto.UpdateWishlistRequest{Name: &name, IsPublic: &public})
+
+		require.NoError(t, err)
+		assert.Equal(t, wishlist.ShareToken, updated.ShareToken)
+	})
+
+	t.Run("shared view lists current product details", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		productRepo := new(MockProductRepository)
+		service := newService(wishlists, productRepo)
+
+		wishlist, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday", IsPublic: true})
+		require.NoError(t, err)
+		variantID := int64(31)
+		wishlists.items[wishlist.ID] = []*domain.WishlistItem{
+			{ID: 1, WishlistID: wishlist.ID, ProductID: 5, Notes: "Blue please"},
+			{ID: 2, WishlistID: wishlist.ID, ProductID: 6, ProductVariantID: &variantID},
+			{ID: 3, WishlistID: wishlist.ID, ProductID: 8},
+		}
+
+		productRepo.On("GetProductsByIDs", context.Background(), []int64{5, 6, 8}).Return(map[int64]*domain.Product{
+			5: {ID: 5, Name: "Chain", SKU: "CH-1", Price: 20, IsActive: true},
+			6: {ID: 6, Name: "Jersey", SKU: "JE-1", Price: 40, IsActive: true},
+			8: {ID: 8, Name: "Retired", SKU: "RE-1", Price: 10, IsActive: false},
+		}, nil)
+		productRepo.On("GetPrimaryImages", context.Background(), []int64{5, 6, 8}).Return(map[int64]*domain.ProductImage{
+			5: {ProductID: 5, URL: "https://cdn.example.com/chain.jpg"},
+		}, nil)
+		productRepo.On("GetProductVariantsByIDs", context.Background(), []int64{variantID}).Return(map[int64]*domain.ProductVariant{
+			variantID: {ID: variantID, SKU: "JE-1-M", Price: 45, IsActive: true},
+		}, nil)
+
+		shared, err := service.GetSharedWishlist(context.Background(), *wishlist.ShareToken, 1, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Birthday", shared.Name)
+		assert.Equal(t, int64(3), shared.Total)
+		require.Len(t, shared.Items, 3)
+		image := "https://cdn.example.com/chain.jpg"
+		assert.Equal(t, dto.SharedWishlistItemResponse{ProductID: 5, ProductName: "Chain", SKU: "CH-1", Price: 20, ProductImage: &image, Available: true, Notes: "Blue please"}, shared.Items[0])
+		assert.Equal(t, dto.SharedWishlistItemResponse{ProductID: 6, ProductVariantID: &variantID, ProductName: "Jersey", SKU: "JE-1-M", Price: 45, Available: true}, shared.Items[1])
+		assert.Equal(t, dto.SharedWishlistItemResponse{ProductID: 8}, shared.Items[2])
+	})
+
+	t.Run("making a wishlist private revokes its link", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		productRepo := new(MockProductRepository)
+		service := newService(wishlists, productRepo)
+		productRepo.On("GetProductsByIDs", ctx, mock.Anything).Return(map[int64]*domain.Product{}, nil)
+		productRepo.On("GetProductVariantsByIDs", ctx, mock.Anything).Return(map[int64]*domain.ProductVariant{}, nil)
+		productRepo.On("GetPrimaryImages", ctx, mock.Anything).Return(map[int64]*domain.ProductImage{}, nil)
+
+		wishlist, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday", IsPublic: true})
+		require.NoError(t, err)
+		oldToken := *wishlist.ShareToken
+
+		unpublished, err := service.UpdateWishlist(ctx, wishlist.ID, &dto.UpdateWishlistRequest{IsPublic: &private})
+		require.NoError(t, err)
+		assert.Nil(t, unpublished.ShareToken)
+
+		_, err = service.GetSharedWishlist(ctx, oldToken, 1, 20)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		republished, err := service.UpdateWishlist(ctx, wishlist.ID, &dto.UpdateWishlistRequest{IsPublic: &public})
+		require.NoError(t, err)
+		require.NotNil(t, republished.ShareToken)
+		assert.NotEqual(t, oldToken, *republished.ShareToken)
+
+		// The old link stays dead and only the new one works
+		_, err = service.GetSharedWishlist(ctx, oldToken, 1, 20)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = service.GetSharedWishlist(ctx, *republished.ShareToken, 1, 20)
+		assert.NoError(t, err)
+	})
+
+	t.Run("other users cannot read or change a wishlist", func(t *testing.T) {
+		wishlists := newSharingWishlistRepository()
+		service := newService(wishlists, new(MockProductRepository))
+
+		wishlist, err := service.CreateWishlist(ctx, 7, &dto.CreateWishlistRequest{Name: "Birthday", IsPublic: true})
+		require.NoError(t, err)
+
+		for name, other := range map[string]context.Context{
+			"another user": userctx.WithUserID(context.Background(), 8),
+			"no user":      context.Background(),
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := service.GetWishlistByID(other, wishlist.ID)
+				assert.ErrorIs(t, err, repository.ErrWishlistNotFound)
+
+				_, err = service.UpdateWishlist(other, wishlist.ID, &dto.UpdateWishlistRequest{IsPublic: &private})
+				assert.ErrorIs(t, err, repository.ErrWishlistNotFound)
+
+				_, err = service.GetWishlistItems(other, wishlist.ID, 1, 20)
+				assert.ErrorIs(t, err, repository.ErrWishlistNotFound)
+			})
+		}
+
+		// The wishlist and its link are untouched
+		assert.True(t, wishlists.wishlists[wishlist.ID].IsPublic)
+		assert.Equal(t, wishlist.ShareToken, wishlists.wishlists[wishlist.ID].ShareToken)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		service := newService(newSharingWishlistRepository(), new(MockProductRepository))
+
+		shared, err := service.GetSharedWishlist(ctx, "not-a-token", 1, 20)
+
+		assert.Nil(t, shared)
+		assert.ErrorIs(t, err, repository.ErrWishlistNotFound)
+	})
+}
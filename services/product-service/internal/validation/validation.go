@@ -2,10 +2,13 @@ package validation
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/jattinmanhas/GearboxV2/services/product-service/internal/domain"
+	"github.com/jattinmanhas/GearboxV2/services/shared/httpx"
 )
 
 // Validator instance
@@ -14,6 +17,15 @@ var validate *validator.Validate
 func init() {
 	validate = validator.New()
 
+	// Report fields by their JSON names so clients can match errors to request fields
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
 	// Register custom validators
 	_ = validate.RegisterValidation("name", validateName)
 	_ = validate.RegisterValidation("slug", validateSlug)
@@ -28,7 +40,7 @@ func init() {
 	_ = validate.RegisterValidation("price", validatePrice)
 	_ = validate.RegisterValidation("weight", validateWeight)
 	_ = validate.RegisterValidation("dimensions", validateDimensions)
-	_ = validate.RegisterValidation("tags", validateTags)
+	_ = validate.RegisterValidation("tag", validateTag)
 
 	// Cart validation functions
 	_ = validate.RegisterValidation("currency", validateCurrency)
@@ -58,6 +70,15 @@ func (v ValidatorErrors) Error() string {
 	return strings.Join(messages, "; ")
 }
 
+// FieldErrors returns the errors in the shape httpx.ValidationError writes
+func (v ValidatorErrors) FieldErrors() []httpx.FieldError {
+	fields := make([]httpx.FieldError, len(v))
+	for i, err := range v {
+		fields[i] = httpx.FieldError{Field: err.Field, Message: err.Message, Tag: err.Tag}
+	}
+	return fields
+}
+
 func ValidateStruct(s any) ValidatorErrors {
 	err := validate.Struct(s)
 	if err == nil {
@@ -215,15 +236,17 @@ func getErrorMessage(field, tag string, value interface{}) string {
 	case "sort_order":
 		return fmt.Sprintf("%s must be between 0 and 999999", field)
 	case "sku":
-		return fmt.Sprintf("%s must be alphanumeric with hyphens and underscores", field)
+		return fmt.Sprintf("%s must be 1 to %d letters, numbers, hyphens or underscores", field, MaxSKULength)
 	case "price":
 		return fmt.Sprintf("%s must be non-negative", field)
 	case "weight":
 		return fmt.Sprintf("%s must be non-negative", field)
 	case "dimensions":
-		return fmt.Sprintf("%s must be in the format 'length x width x height'", field)
-	case "tags":
-		return fmt.Sprintf("%s must be a comma-separated list of tags", field)
+		return fmt.Sprintf("%s must be in the format 'length x width x height' with an optional unit of mm, cm, m or in", field)
+	case "currency":
+		return fmt.Sprintf("%s must be a valid ISO 4217 currency code", field)
+	case "tag":
+		return fmt.Sprintf("%s must be 1 to 50 letters, numbers, spaces or hyphens", field)
 	default:
 		return fmt.Sprintf("%s is invalid", field)
 	}
@@ -263,16 +286,26 @@ func ValidateSlug(slug string) error {
 
 // Product validation functions
 
-// validateSKU validates SKU format (alphanumeric, hyphens, underscores allowed)
+// MaxSKULength matches the width of the sku columns
+const MaxSKULength = 100
+
+var skuRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validateSKU validates SKU format (alphanumeric, hyphens, underscores allowed).
+// Case is not checked since SKUs are upper-cased by NormalizeSKU before they are stored.
 func validateSKU(fl validator.FieldLevel) bool {
 	sku := fl.Field().String()
-	if sku == "" {
+	if sku == "" || len(sku) > MaxSKULength {
 		return false
 	}
 
-	// SKU should be alphanumeric with hyphens and underscores
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, sku)
-	return matched
+	// Spaces and any other punctuation are rejected
+	return skuRegex.MatchString(sku)
+}
+
+// NormalizeSKU returns the stored form of a SKU: trimmed and upper-cased
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
 }
 
 // validatePrice validates price (must be non-negative)
@@ -287,59 +320,64 @@ func validateWeight(fl validator.FieldLevel) bool {
 	return weight >= 0
 }
 
-// validateDimensions validates dimensions format (e.g., "10x20x30", "10 x 20 x 30")
+// validateDimensions validates dimensions format: three positive sides with an optional
+// unit, e.g. "10x20x30", "10 x 20 x 30 cm", "10cm x 20cm x 30cm" or "4x6x2.5in"
 func validateDimensions(fl validator.FieldLevel) bool {
 	dimensions := fl.Field().String()
 	if dimensions == "" {
 		return true // Optional field
 	}
 
-	// Allow formats like "10x20x30", "10 x 20 x 30", "10cm x 20cm x 30cm"
-	matched, _ := regexp.MatchString(`^[\d\s\.xX×\*]+(cm|in|mm|m)?$`, dimensions)
-	return matched
+	_, err := domain.ParseDimensions(dimensions)
+	return err == nil
 }
 
-// validateTags validates tags format (comma-separated, no special characters)
-func validateTags(fl validator.FieldLevel) bool {
-	tags := fl.Field().String()
-	if tags == "" {
-		return true // Optional field
+// validateTag validates a single tag: letters, numbers, spaces and hyphens, up to 50 characters
+func validateTag(fl validator.FieldLevel) bool {
+	tag := strings.TrimSpace(fl.Field().String())
+	if tag == "" || len(tag) > 50 {
+		return false
 	}
 
-	// Tags should be alphanumeric with spaces, commas, and hyphens
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9\s,-]+$`, tags)
+	matched, _ := regexp.MatchString(`^[a-zA-Z0-9 -]+$`, tag)
 	return matched
 }
 
-// ValidateProductFilters validates product filter parameters
-func ValidateProductFilters(filters map[string]interface{}) ValidatorErrors {
+// SortOrders lists the accepted values of a sort_order parameter
+var SortOrders = []string{"asc", "desc"}
+
+// ValidateSort checks sort_by against allowedFields and sort_order against SortOrders.
+// Empty values are accepted and leave the default ordering in place.
+func ValidateSort(sortBy, sortOrder string, allowedFields []string) ValidatorErrors {
 	var errors ValidatorErrors
 
-	// Validate sort_by parameter
-	if sortBy, ok := filters["sort_by"].(string); ok && sortBy != "" {
-		validSortFields := []string{"name", "price", "created_at", "updated_at", "sku"}
-		if !contains(validSortFields, sortBy) {
-			errors = append(errors, ValidatorError{
-				Field:   "sort_by",
-				Tag:     "invalid",
-				Value:   sortBy,
-				Message: "sort_by must be one of: name, price, created_at, updated_at, sku",
-			})
-		}
+	if sortBy != "" && !contains(allowedFields, sortBy) {
+		errors = append(errors, ValidatorError{
+			Field:   "sort_by",
+			Tag:     "oneof",
+			Value:   sortBy,
+			Message: fmt.Sprintf("sort_by must be one of: %s", strings.Join(allowedFields, ", ")),
+		})
 	}
 
-	// Validate sort_order parameter
-	if sortOrder, ok := filters["sort_order"].(string); ok && sortOrder != "" {
-		if sortOrder != "asc" && sortOrder != "desc" {
-			errors = append(errors, ValidatorError{
-				Field:   "sort_order",
-				Tag:     "invalid",
-				Value:   sortOrder,
-				Message: "sort_order must be either 'asc' or 'desc'",
-			})
-		}
+	if sortOrder != "" && !contains(SortOrders, sortOrder) {
+		errors = append(errors, ValidatorError{
+			Field:   "sort_order",
+			Tag:     "oneof",
+			Value:   sortOrder,
+			Message: fmt.Sprintf("sort_order must be one of: %s", strings.Join(SortOrders, ", ")),
+		})
 	}
 
+	return errors
+}
+
+// ValidateProductFilters validates product filter parameters
+func ValidateProductFilters(filters map[string]interface{}) ValidatorErrors {
+	sortBy, _ := filters["sort_by"].(string)
+	sortOrder, _ := filters["sort_order"].(string)
+	errors := ValidateSort(sortBy, sortOrder, domain.ProductSortFields)
+
 	// Validate price range
 	if minPrice, ok := filters["min_price"].(float64); ok && minPrice < 0 {
 		errors = append(errors, ValidatorError{
@@ -388,18 +426,7 @@ func contains(slice []string, item string) bool {
 
 // validateCurrency validates currency code (ISO 4217)
 func validateCurrency(fl validator.FieldLevel) bool {
-	currency := fl.Field().String()
-	if currency == "" {
-		return false
-	}
-
-	// Common currency codes
-	validCurrencies := []string{
-		"USD", "EUR", "GBP", "JPY", "CAD", "AUD", "CHF", "CNY", "SEK", "NZD",
-		"INR", "BRL", "RUB", "KRW", "SGD", "HKD", "NOK", "MXN", "TRY", "ZAR",
-	}
-
-	return contains(validCurrencies, strings.ToUpper(currency))
+	return IsCurrencyCode(fl.Field().String())
 }
 
 // validateSessionID validates session ID format
@@ -444,12 +471,7 @@ func ValidateCartFilters(filters map[string]interface{}) ValidatorErrors {
 
 	// Validate currency parameter
 	if currency, ok := filters["currency"].(string); ok && currency != "" {
-		// Simple currency validation without using validator.FieldLevel
-		validCurrencies := []string{
-			"USD", "EUR", "GBP", "JPY", "CAD", "AUD", "CHF", "CNY", "SEK", "NZD",
-			"INR", "BRL", "RUB", "KRW", "SGD", "HKD", "NOK", "MXN", "TRY", "ZAR",
-		}
-		if !contains(validCurrencies, strings.ToUpper(currency)) {
+		if !IsCurrencyCode(currency) {
 			errors = append(errors, ValidatorError{
 				Field:   "currency",
 				Tag:     "invalid",
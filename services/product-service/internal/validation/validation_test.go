@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSort(t *testing.T) {
+	allowed := []string{"name", "price", "created_at"}
+
+	t.Run("valid sort", func(t *testing.T) {
+		assert.Empty(t, ValidateSort("price", "asc", allowed))
+		assert.Empty(t, ValidateSort("", "", allowed))
+	})
+
+	t.Run("invalid field lists the allowed fields", func(t *testing.T) {
+		errs := ValidateSort("popularity", "desc", allowed)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "sort_by", errs[0].Field)
+		assert.Equal(t, "sort_by must be one of: name, price, created_at", errs[0].Message)
+	})
+
+	t.Run("invalid order lists the allowed orders", func(t *testing.T) {
+		errs := ValidateSort("name", "up", allowed)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "sort_order", errs[0].Field)
+		assert.Equal(t, "sort_order must be one of: asc, desc", errs[0].Message)
+	})
+}
+
+func TestValidateSKU(t *testing.T) {
+	type product struct {
+		SKU string `json:"sku" validate:"sku"`
+	}
+
+	valid := []string{"GS-001", "gs_001", "A", "ABC123", strings.Repeat("X", MaxSKULength)}
+	for _, sku := range valid {
+		t.Run("valid "+sku, func(t *testing.T) {
+			assert.Empty(t, ValidateStruct(product{SKU: sku}))
+		})
+	}
+
+	invalid := []string{"", "GS 001", " GS-001", "GS/001", "GS.001", "GS#1", "ÄBC", strings.Repeat("X", MaxSKULength+1)}
+	for _, sku := range invalid {
+		t.Run("invalid "+sku, func(t *testing.T) {
+			errs := ValidateStruct(product{SKU: sku})
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, "sku", errs[0].Field)
+			assert.Equal(t, "sku must be 1 to 100 letters, numbers, hyphens or underscores", errs[0].Message)
+		})
+	}
+}
+
+func TestValidateWeightAndDimensions(t *testing.T) {
+	type product struct {
+		Weight     float64 `json:"weight" validate:"omitempty,weight"`
+		Dimensions string  `json:"dimensions" validate:"omitempty,dimensions"`
+	}
+
+	valid := []product{
+		{Weight: 1.5, Dimensions: "10x20x30"},
+		{Dimensions: "10 x 20 x 30 cm"},
+		{Dimensions: "4*6*2.5in"},
+		{Dimensions: "100×200×50mm"},
+		{Dimensions: "10cm x 20cm x 30cm"},
+		{},
+	}
+	for _, p := range valid {
+		t.Run("valid "+p.Dimensions, func(t *testing.T) {
+			assert.Empty(t, ValidateStruct(p))
+		})
+	}
+
+	t.Run("negative weight", func(t *testing.T) {
+		errs := ValidateStruct(product{Weight: -1})
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "weight", errs[0].Field)
+		assert.Equal(t, "weight must be non-negative", errs[0].Message)
+	})
+
+	invalid := []string{"10x20", "10x20x30x40", "10x0x30", "10cm x 20mm x 30cm", "10x20x30 ft", "xx", "..."}
+	for _, dimensions := range invalid {
+		t.Run("invalid "+dimensions, func(t *testing.T) {
+			errs := ValidateStruct(product{Dimensions: dimensions})
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, "dimensions", errs[0].Field)
+			assert.Equal(t, "dimensions must be in the format 'length x width x height' with an optional unit of mm, cm, m or in", errs[0].Message)
+		})
+	}
+}
+
+func TestNormalizeSKU(t *testing.T) {
+	assert.Equal(t, "GS-001", NormalizeSKU(" gs-001 "))
+	assert.Equal(t, "AB_12", NormalizeSKU("Ab_12"))
+}
+
+func TestValidateCurrency(t *testing.T) {
+	type cart struct {
+		Currency string `json:"currency" validate:"required,currency"`
+	}
+
+	for _, currency := range []string{"USD", "EUR", "JPY", "eur"} {
+		assert.Empty(t, ValidateStruct(cart{Currency: currency}), currency)
+	}
+
+	for _, currency := range []string{"US", "USDX", "XYZ", "XAU", "123"} {
+		errs := ValidateStruct(cart{Currency: currency})
+
+		require.Len(t, errs, 1, currency)
+		assert.Equal(t, "currency", errs[0].Field)
+		assert.Equal(t, "currency must be a valid ISO 4217 currency code", errs[0].Message)
+	}
+}
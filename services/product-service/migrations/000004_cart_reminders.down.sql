@@ -0,0 +1,5 @@
+-- Drop abandoned cart reminder tracking
+
+DROP INDEX IF EXISTS idx_carts_last_reminder_at;
+
+ALTER TABLE carts DROP COLUMN IF EXISTS last_reminder_at;
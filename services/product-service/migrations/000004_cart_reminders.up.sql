@@ -0,0 +1,6 @@
+-- Track abandoned cart reminders
+-- last_reminder_at is compared against cart activity so a cart is reminded at most once per idle period
+
+ALTER TABLE carts ADD COLUMN last_reminder_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_carts_last_reminder_at ON carts(last_reminder_at);
@@ -0,0 +1,3 @@
+-- Drop variant_attributes table
+
+DROP TABLE IF EXISTS variant_attributes;
@@ -0,0 +1,14 @@
+-- Create variant_attributes table
+-- Each row holds one attribute (size, color, ...) of a product variant
+CREATE TABLE variant_attributes (
+    id BIGSERIAL PRIMARY KEY,
+    variant_id BIGINT NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    attribute_name VARCHAR(100) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(variant_id, attribute_name)
+);
+
+-- Create indexes for variant_attributes
+CREATE INDEX idx_variant_attributes_variant_id ON variant_attributes(variant_id);
+CREATE INDEX idx_variant_attributes_name_value ON variant_attributes(attribute_name, value);
@@ -0,0 +1,3 @@
+-- Drop the product-level inventory uniqueness index
+
+DROP INDEX IF EXISTS idx_inventory_product_no_variant;
@@ -0,0 +1,31 @@
+-- Enforce a single inventory row per product when no variant is set
+-- UNIQUE(product_id, product_variant_id) does not cover NULL variants
+
+-- Fold any duplicate product-level rows into the oldest one first, adding up their
+-- stock, so the index can be created on a database that already has duplicates
+UPDATE inventory keeper
+SET quantity = merged.quantity,
+    reserved_quantity = merged.reserved_quantity,
+    available_quantity = merged.available_quantity,
+    last_restocked = merged.last_restocked
+FROM (
+    SELECT MIN(id) AS id,
+           SUM(quantity) AS quantity,
+           SUM(reserved_quantity) AS reserved_quantity,
+           SUM(available_quantity) AS available_quantity,
+           MAX(last_restocked) AS last_restocked
+    FROM inventory
+    WHERE product_variant_id IS NULL
+    GROUP BY product_id
+    HAVING COUNT(*) > 1
+) merged
+WHERE keeper.id = merged.id;
+
+DELETE FROM inventory i
+USING inventory keeper
+WHERE i.product_variant_id IS NULL
+  AND keeper.product_variant_id IS NULL
+  AND keeper.product_id = i.product_id
+  AND keeper.id < i.id;
+
+CREATE UNIQUE INDEX idx_inventory_product_no_variant ON inventory(product_id) WHERE product_variant_id IS NULL;
@@ -0,0 +1,3 @@
+-- Drop the product name prefix index
+
+DROP INDEX IF EXISTS idx_products_name_prefix;
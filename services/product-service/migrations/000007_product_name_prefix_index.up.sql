@@ -0,0 +1,4 @@
+-- Support case-insensitive prefix lookups for search suggestions
+-- text_pattern_ops lets lower(name) LIKE 'abc%' use the index regardless of collation
+
+CREATE INDEX idx_products_name_prefix ON products (lower(name) text_pattern_ops);
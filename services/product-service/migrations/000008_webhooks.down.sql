@@ -0,0 +1,4 @@
+-- Drop webhook tables
+
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,33 @@
+-- Create webhook_subscriptions table
+-- Partners subscribe a URL to one or more event types ('*' matches every event)
+CREATE TABLE webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for webhook_subscriptions
+CREATE INDEX idx_webhook_subscriptions_is_active ON webhook_subscriptions(is_active);
+CREATE INDEX idx_webhook_subscriptions_event_types ON webhook_subscriptions USING GIN (event_types);
+
+-- Create webhook_delivery_attempts table
+-- One row per HTTP attempt, including retries
+CREATE TABLE webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    success BOOLEAN NOT NULL DEFAULT false,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for webhook_delivery_attempts
+CREATE INDEX idx_webhook_delivery_attempts_subscription_id ON webhook_delivery_attempts(subscription_id);
+CREATE INDEX idx_webhook_delivery_attempts_event_id ON webhook_delivery_attempts(event_id);
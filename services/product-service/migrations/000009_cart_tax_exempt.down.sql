@@ -0,0 +1,3 @@
+-- Drop tax-exempt carts
+
+ALTER TABLE carts DROP COLUMN IF EXISTS tax_exempt;
@@ -0,0 +1,4 @@
+-- Tax-exempt carts
+-- Exempt carts are charged no tax; the summary still reports the taxable subtotal
+
+ALTER TABLE carts ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- Drop coupon tables
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,34 @@
+-- Create coupons table
+-- A usage_limit or per_user_limit of 0 means unlimited
+CREATE TABLE coupons (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL DEFAULT 'fixed_amount' CHECK (type IN ('percentage', 'fixed_amount', 'free_shipping')),
+    value DECIMAL(10,2) NOT NULL DEFAULT 0,
+    min_order_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    max_discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    per_user_limit INTEGER NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    used_count INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create coupon_redemptions table
+-- cart_id has no foreign key so redemptions outlive expired and deleted carts
+CREATE TABLE coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id BIGINT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    user_id BIGINT,
+    cart_id BIGINT NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(coupon_id, cart_id)
+);
+
+-- Create indexes for coupon_redemptions
+CREATE INDEX idx_coupon_redemptions_coupon_user ON coupon_redemptions(coupon_id, user_id);
@@ -0,0 +1,4 @@
+-- Drop the cart owner uniqueness indexes
+
+DROP INDEX IF EXISTS idx_carts_guest_session_unique;
+DROP INDEX IF EXISTS idx_carts_user_id_unique;
@@ -0,0 +1,16 @@
+-- Enforce a single cart per user and per guest session
+-- Guest carts are keyed by session; a user's cart may share its session with the guest cart it replaces
+
+-- Lookups already return the newest cart, so older duplicates are unreachable; drop them first
+DELETE FROM carts c
+USING carts newer
+WHERE c.user_id IS NOT NULL AND newer.user_id = c.user_id
+AND (newer.created_at, newer.id) > (c.created_at, c.id);
+
+DELETE FROM carts c
+USING carts newer
+WHERE c.user_id IS NULL AND newer.user_id IS NULL AND newer.session_id = c.session_id
+AND (newer.created_at, newer.id) > (c.created_at, c.id);
+
+CREATE UNIQUE INDEX idx_carts_user_id_unique ON carts(user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_carts_guest_session_unique ON carts(session_id) WHERE user_id IS NULL;
@@ -0,0 +1,3 @@
+-- Drop stock_notifications table
+
+DROP TABLE IF EXISTS stock_notifications;
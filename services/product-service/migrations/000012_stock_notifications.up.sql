@@ -0,0 +1,21 @@
+-- Create stock_notifications table
+-- Subscribers are identified by user_id or, for guests, by email.
+-- notified_at is set once the subscriber has been told the item is back in stock.
+CREATE TABLE stock_notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_variant_id BIGINT REFERENCES product_variants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    notified_at TIMESTAMP WITH TIME ZONE,
+    CHECK (user_id IS NOT NULL OR email <> '')
+);
+
+-- A subscriber can only wait once per item
+CREATE UNIQUE INDEX idx_stock_notifications_pending_unique ON stock_notifications (
+    product_id, COALESCE(product_variant_id, 0), COALESCE(user_id, 0), LOWER(email)
+) WHERE notified_at IS NULL;
+
+-- Create index for looking up pending subscribers of an item
+CREATE INDEX idx_stock_notifications_item ON stock_notifications(product_id, product_variant_id) WHERE notified_at IS NULL;
@@ -0,0 +1,4 @@
+-- Drop updated_by from products and inventory
+
+ALTER TABLE inventory DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE products DROP COLUMN IF EXISTS updated_by;
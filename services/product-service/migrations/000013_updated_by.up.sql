@@ -0,0 +1,5 @@
+-- Record who last changed products and inventory
+-- NULL means the change was made by the system or a background job
+
+ALTER TABLE products ADD COLUMN updated_by BIGINT;
+ALTER TABLE inventory ADD COLUMN updated_by BIGINT;
@@ -0,0 +1,4 @@
+-- Drop version from products and inventory
+
+ALTER TABLE inventory DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
@@ -0,0 +1,5 @@
+-- Optimistic concurrency control for products and inventory
+-- Every write bumps version; client updates must send the version they read
+
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE inventory ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
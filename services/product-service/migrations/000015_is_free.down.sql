@@ -0,0 +1,3 @@
+-- Drop is_free from products
+
+ALTER TABLE products DROP COLUMN IF EXISTS is_free;
@@ -0,0 +1,4 @@
+-- Mark products that are intentionally free
+-- Carts reject zero-priced products unless they carry this flag
+
+ALTER TABLE products ADD COLUMN is_free BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- Drop the shipping address from carts
+
+ALTER TABLE carts DROP COLUMN IF EXISTS ship_postal_code;
+ALTER TABLE carts DROP COLUMN IF EXISTS ship_state;
+ALTER TABLE carts DROP COLUMN IF EXISTS ship_country;
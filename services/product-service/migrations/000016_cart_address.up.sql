@@ -0,0 +1,6 @@
+-- Record where a cart ships to so tax can be resolved per jurisdiction
+-- Empty values mean the address is not known yet
+
+ALTER TABLE carts ADD COLUMN ship_country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE carts ADD COLUMN ship_state VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE carts ADD COLUMN ship_postal_code VARCHAR(20) NOT NULL DEFAULT '';
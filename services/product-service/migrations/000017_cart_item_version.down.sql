@@ -0,0 +1,3 @@
+-- Drop version from cart_items
+
+ALTER TABLE cart_items DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Optimistic concurrency control for cart items
+-- Every write bumps version so concurrent quantity merges cannot overwrite each other
+
+ALTER TABLE cart_items ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Drop the product keyset pagination index
+
+DROP INDEX IF EXISTS idx_products_created_at_id;
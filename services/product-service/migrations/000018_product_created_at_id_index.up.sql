@@ -0,0 +1,4 @@
+-- Support keyset pagination of products, newest first
+-- Matches ORDER BY created_at DESC, id DESC so each cursor page is an index range scan
+
+CREATE INDEX idx_products_created_at_id ON products (created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+-- Drop product full-text search and the trigram indexes
+-- pg_trgm is left installed since other schemas may use it
+
+DROP INDEX IF EXISTS idx_products_description_trgm;
+DROP INDEX IF EXISTS idx_products_tags_trgm;
+DROP INDEX IF EXISTS idx_products_sku_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,19 @@
+-- Full-text search over products, ranked by where the terms appear
+-- Name and SKU hits weigh most, then tags, then the descriptions
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(sku, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(tags, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(short_description, '') || ' ' || coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
+
+-- Trigram indexes serve the ILIKE fallback used for short prefixes
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_products_sku_trgm ON products USING GIN (sku gin_trgm_ops);
+CREATE INDEX idx_products_tags_trgm ON products USING GIN (tags gin_trgm_ops);
+CREATE INDEX idx_products_description_trgm ON products USING GIN (description gin_trgm_ops);
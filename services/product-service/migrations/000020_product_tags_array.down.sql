@@ -0,0 +1,22 @@
+-- Return product tags to a comma-separated text column
+
+ALTER TABLE products DROP COLUMN search_vector;
+DROP INDEX IF EXISTS idx_products_tags_trgm;
+DROP INDEX IF EXISTS idx_products_tags;
+
+ALTER TABLE products ADD COLUMN tag_text TEXT;
+UPDATE products SET tag_text = array_to_string(tags, ',');
+ALTER TABLE products DROP COLUMN tags;
+ALTER TABLE products RENAME COLUMN tag_text TO tags;
+
+DROP FUNCTION IF EXISTS product_tags_text(TEXT[]);
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(sku, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(tags, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(short_description, '') || ' ' || coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX idx_products_tags_trgm ON products USING GIN (tags gin_trgm_ops);
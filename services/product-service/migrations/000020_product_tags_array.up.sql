@@ -0,0 +1,38 @@
+-- Store product tags as a text array so filters match whole tags
+-- The comma-separated text column let a search for "pro" match "professional"
+
+-- search_vector and the tags trigram index read the old column, so rebuild them below
+ALTER TABLE products DROP COLUMN search_vector;
+DROP INDEX IF EXISTS idx_products_tags_trgm;
+
+-- Split existing tags on commas, trimmed, lowercased and without duplicates or blanks
+ALTER TABLE products ADD COLUMN tag_list TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE products SET tag_list = ARRAY(
+    SELECT DISTINCT lower(btrim(tag))
+    FROM unnest(string_to_array(tags, ',')) AS tag
+    WHERE btrim(tag) <> ''
+    ORDER BY 1
+)
+WHERE tags IS NOT NULL;
+
+ALTER TABLE products DROP COLUMN tags;
+ALTER TABLE products RENAME COLUMN tag_list TO tags;
+
+CREATE INDEX idx_products_tags ON products USING GIN (tags);
+
+-- array_to_string is only STABLE, which generated columns and indexes reject; joining
+-- text is immutable in practice, so wrap it
+CREATE FUNCTION product_tags_text(tags TEXT[]) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT array_to_string($1, ' ') $$;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(sku, '')), 'A') ||
+    setweight(to_tsvector('english', product_tags_text(tags)), 'B') ||
+    setweight(to_tsvector('english', coalesce(short_description, '') || ' ' || coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX idx_products_tags_trgm ON products USING GIN (product_tags_text(tags) gin_trgm_ops);
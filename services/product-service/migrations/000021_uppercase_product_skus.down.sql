@@ -0,0 +1,2 @@
+-- The original casing of SKUs is not kept, so there is nothing to restore.
+-- Upper-cased SKUs keep working after rolling back.
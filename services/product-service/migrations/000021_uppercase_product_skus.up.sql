@@ -0,0 +1,5 @@
+-- Product and variant SKUs are stored upper-cased so lookups are case-insensitive
+-- Fails on a unique constraint if two SKUs differ only by case; rename one first
+
+UPDATE products SET sku = UPPER(sku) WHERE sku <> UPPER(sku);
+UPDATE product_variants SET sku = UPPER(sku) WHERE sku <> UPPER(sku);
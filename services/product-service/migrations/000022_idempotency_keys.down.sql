@@ -0,0 +1,3 @@
+-- Drop idempotency keys table
+
+DROP TABLE IF EXISTS idempotency_keys;
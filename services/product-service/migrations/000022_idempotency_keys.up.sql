@@ -0,0 +1,19 @@
+-- Create idempotency_keys table
+-- Remembers the response to a POST sent with an Idempotency-Key header so a retry gets
+-- the same response instead of repeating the request. status_code is NULL while the
+-- first request is still being handled.
+CREATE TABLE idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    scope VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(scope, idempotency_key)
+);
+
+-- Expired keys are deleted in batches by expiry
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
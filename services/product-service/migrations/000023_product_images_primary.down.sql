@@ -0,0 +1,5 @@
+-- Allow products to have any number of primary images again
+
+DROP INDEX IF EXISTS idx_product_images_one_primary;
+ALTER TABLE product_images ALTER COLUMN alt DROP NOT NULL;
+ALTER TABLE product_images ALTER COLUMN alt DROP DEFAULT;
@@ -0,0 +1,30 @@
+-- Keep exactly one primary image per product that has images
+
+-- Alt text is optional but never NULL
+UPDATE product_images SET alt = '' WHERE alt IS NULL;
+ALTER TABLE product_images ALTER COLUMN alt SET DEFAULT '';
+ALTER TABLE product_images ALTER COLUMN alt SET NOT NULL;
+
+-- Where a product has several primary images, keep the first by position
+UPDATE product_images pi
+SET is_primary = false
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY position, id) AS rn
+    FROM product_images
+    WHERE is_primary
+) ranked
+WHERE pi.id = ranked.id AND ranked.rn > 1;
+
+-- Where a product has images but no primary, promote its first by position
+UPDATE product_images pi
+SET is_primary = true
+FROM (
+    SELECT DISTINCT ON (product_id) id
+    FROM product_images
+    WHERE product_id NOT IN (SELECT product_id FROM product_images WHERE is_primary)
+    ORDER BY product_id, position, id
+) first_image
+WHERE pi.id = first_image.id;
+
+-- At most one primary image per product
+CREATE UNIQUE INDEX idx_product_images_one_primary ON product_images(product_id) WHERE is_primary;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_inventory_alerts_one_open;
+
+DELETE FROM inventory_alerts WHERE alert_type = 'restock_recommended';
+
+ALTER TABLE inventory_alerts DROP CONSTRAINT IF EXISTS inventory_alerts_alert_type_check;
+ALTER TABLE inventory_alerts ADD CONSTRAINT inventory_alerts_alert_type_check
+    CHECK (alert_type IN ('low_stock', 'out_of_stock', 'reorder_point'));
@@ -0,0 +1,23 @@
+-- Inventory alerts: add restock_recommended and keep one open alert per item and type
+
+ALTER TABLE inventory_alerts DROP CONSTRAINT IF EXISTS inventory_alerts_alert_type_check;
+ALTER TABLE inventory_alerts ADD CONSTRAINT inventory_alerts_alert_type_check
+    CHECK (alert_type IN ('low_stock', 'out_of_stock', 'restock_recommended', 'reorder_point'));
+
+-- Where an item has several open alerts of one type, keep the oldest open
+UPDATE inventory_alerts ia
+SET is_resolved = true, resolved_at = NOW()
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY product_id, COALESCE(product_variant_id, 0), alert_type
+        ORDER BY created_at, id
+    ) AS rn
+    FROM inventory_alerts
+    WHERE NOT is_resolved
+) ranked
+WHERE ia.id = ranked.id AND ranked.rn > 1;
+
+-- At most one open alert per item and type
+CREATE UNIQUE INDEX idx_inventory_alerts_one_open
+    ON inventory_alerts(product_id, COALESCE(product_variant_id, 0), alert_type)
+    WHERE NOT is_resolved;
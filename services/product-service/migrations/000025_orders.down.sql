@@ -0,0 +1,4 @@
+-- Drop order tables
+
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
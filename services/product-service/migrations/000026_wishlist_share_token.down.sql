@@ -0,0 +1,4 @@
+-- Drop wishlist share tokens
+
+DROP INDEX IF EXISTS idx_wishlists_share_token;
+ALTER TABLE wishlists DROP COLUMN IF EXISTS share_token;
@@ -0,0 +1,12 @@
+-- Wishlist sharing: public wishlists are reachable through an unguessable token
+
+ALTER TABLE wishlists ADD COLUMN share_token VARCHAR(64);
+
+-- Give wishlists that are already public a token of their own, 32 hex characters like
+-- the ones the service issues. md5 over random() and the row ID works on every
+-- PostgreSQL version, unlike gen_random_uuid() which needs 13 or pgcrypto.
+UPDATE wishlists
+SET share_token = md5(random()::text || clock_timestamp()::text || id::text)
+WHERE is_public AND share_token IS NULL;
+
+CREATE UNIQUE INDEX idx_wishlists_share_token ON wishlists(share_token) WHERE share_token IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Restore the shared updated_at trigger on carts
+
+DROP TRIGGER IF EXISTS update_carts_updated_at ON carts;
+CREATE TRIGGER update_carts_updated_at BEFORE UPDATE ON carts FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+DROP FUNCTION IF EXISTS update_carts_updated_at_column();
@@ -0,0 +1,25 @@
+-- Abandoned cart reminders: recording a reminder must not count as cart activity.
+-- The shared updated_at trigger bumped updated_at on every update, so marking a
+-- reminder sent made the cart look freshly active and it was reminded again later.
+
+CREATE OR REPLACE FUNCTION update_carts_updated_at_column()
+RETURNS TRIGGER AS $$
+DECLARE
+    unchanged carts;
+BEGIN
+    -- Compare the rows with the reminder time and updated_at taken out
+    unchanged := OLD;
+    unchanged.last_reminder_at := NEW.last_reminder_at;
+    unchanged.updated_at := NEW.updated_at;
+
+    IF unchanged IS DISTINCT FROM NEW THEN
+        NEW.updated_at = NOW();
+    ELSE
+        NEW.updated_at = OLD.updated_at;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_carts_updated_at ON carts;
+CREATE TRIGGER update_carts_updated_at BEFORE UPDATE ON carts FOR EACH ROW EXECUTE FUNCTION update_carts_updated_at_column();
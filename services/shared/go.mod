@@ -2,7 +2,10 @@ module github.com/jattinmanhas/GearboxV2/services/shared
 
 go 1.21
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/go-chi/chi/v5 v5.2.2
+	github.com/stretchr/testify v1.11.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -0,0 +1,90 @@
+package httpx
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+)
+
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// AccessLog logs one line per request with its method, path, status, size, duration,
+// request ID and, for authenticated requests, user ID. It must run after RequestID and
+// outside Recover; a panic that reaches it is logged as a 500 and re-raised rather than
+// swallowed.
+//
+// Handlers further down get a logger tagged with the request ID through the context.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := middleware.GetReqID(r.Context())
+
+		l := logger.FromContext(r.Context())
+		if requestID != "" {
+			l = l.With(slog.String("request_id", requestID))
+		}
+
+		ctx, trackedUser := userctx.Track(logger.WithContext(r.Context(), l))
+		rec := &statusRecorder{ResponseWriter: w}
+
+		defer func() {
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			p := recover()
+			if p != nil {
+				status = http.StatusInternalServerError
+			}
+
+			attrs := []any{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", rec.bytes),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if userID, ok := trackedUser(); ok {
+				attrs = append(attrs, slog.Int64("user_id", userID))
+			}
+			l.Info("request completed", attrs...)
+
+			if p != nil {
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+	})
+}
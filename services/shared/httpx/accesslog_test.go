@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/jattinmanhas/GearboxV2/services/shared/userctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAccessLog tests the request logging middleware
+func TestAccessLog(t *testing.T) {
+	// 🎯 Test Strategy: serve a request through the middleware and decode the single JSON line it logs
+
+	serve := func(handler http.Handler) (map[string]any, *httptest.ResponseRecorder) {
+		var buf bytes.Buffer
+		ctx := logger.WithContext(context.Background(), logger.New(logger.Config{}, &buf))
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/7", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+
+		middleware.RequestID(AccessLog(handler)).ServeHTTP(rr, req)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		return record, rr
+	}
+
+	t.Run("should log the captured status, size and request details", func(t *testing.T) {
+		// 🔧 Setup
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Authentication runs further down the chain than the access log
+			_ = userctx.WithUserID(r.Context(), 42)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("missing"))
+		})
+
+		// 🚀 Action
+		record, rr := serve(handler)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "request completed", record["msg"])
+		assert.Equal(t, "GET", record["method"])
+		assert.Equal(t, "/api/v1/products/7", record["path"])
+		assert.Equal(t, float64(http.StatusNotFound), record["status"])
+		assert.Equal(t, float64(len("missing")), record["bytes"])
+		assert.Equal(t, float64(42), record["user_id"])
+		assert.NotEmpty(t, record["request_id"])
+		assert.Contains(t, record, "duration")
+	})
+
+	t.Run("should default to 200 and omit the user for anonymous requests", func(t *testing.T) {
+		// 🔧 Setup
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+
+		// 🚀 Action
+		record, _ := serve(handler)
+
+		// ✅ Assertions
+		assert.Equal(t, float64(http.StatusOK), record["status"])
+		assert.NotContains(t, record, "user_id")
+	})
+
+	t.Run("should log a recovered panic as 500", func(t *testing.T) {
+		// 🔧 Setup
+		handler := middleware.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		// 🚀 Action
+		record, rr := serve(handler)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, float64(http.StatusInternalServerError), record["status"])
+	})
+
+	t.Run("should re-raise a panic it did not see recovered", func(t *testing.T) {
+		// 🔧 Setup
+		var buf bytes.Buffer
+		ctx := logger.WithContext(context.Background(), logger.New(logger.Config{}, &buf))
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		handler := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		// 🚀 Action & ✅ Assertions
+		assert.PanicsWithValue(t, "boom", func() {
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+		assert.Contains(t, buf.String(), `"status":500`)
+	})
+}
@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBodyBytes bounds request bodies when a BodyPolicy leaves MaxBytes unset
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// BodyPolicy configures the JSONBody middleware
+type BodyPolicy struct {
+	// MaxBytes is the largest request body accepted; larger bodies are refused with 413
+	MaxBytes int64
+	// DisallowUnknownFields makes DecodeJSON reject objects carrying fields the target
+	// type does not declare, instead of silently dropping them
+	DisallowUnknownFields bool
+}
+
+type strictJSONKey struct{}
+
+// UnknownFieldError is returned by a strict DecodeJSON for a field the target type does
+// not declare, typically a misspelt one
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// ErrTrailingJSON is returned by a strict DecodeJSON when the body holds more than one
+// JSON value
+var ErrTrailingJSON = errors.New("request body must hold a single JSON value")
+
+// JSONBody guards handlers that decode JSON request bodies. A POST, PUT or PATCH that
+// carries a body must declare it as application/json or is refused with 415, and a body
+// larger than policy.MaxBytes is refused with 413. The body is read here, through
+// http.MaxBytesReader, so an oversized request never reaches a handler and a handler
+// reading the body cannot hold more than the limit in memory.
+func JSONBody(policy BodyPolicy) func(http.Handler) http.Handler {
+	maxBytes := policy.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.DisallowUnknownFields {
+				r = r.WithContext(context.WithValue(r.Context(), strictJSONKey{}, true))
+			}
+
+			// No body to check; a length of -1 means one is coming in chunks
+			if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > maxBytes {
+				Error(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+				return
+			}
+
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				if !isJSONContentType(r.Header.Get("Content-Type")) {
+					Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json", nil)
+					return
+				}
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					Error(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+					return
+				}
+				Error(w, http.StatusBadRequest, "Failed to read request body", err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isJSONContentType accepts application/json with any parameters, such as a charset
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// DecodeJSON decodes the JSON request body into v. When the request passed through a
+// JSONBody configured with DisallowUnknownFields, a field v does not declare fails with
+// *UnknownFieldError and anything after the first value with ErrTrailingJSON.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	strict, _ := r.Context().Value(strictJSONKey{}).(bool)
+
+	decoder := json.NewDecoder(r.Body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		// encoding/json reports unknown fields only through the error text
+		if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if field, unquoteErr := strconv.Unquote(quoted); unquoteErr == nil {
+				return &UnknownFieldError{Field: field}
+			}
+		}
+		return err
+	}
+
+	if strict {
+		if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+			return ErrTrailingJSON
+		}
+	}
+	return nil
+}
+
+// InvalidBody writes the 400 for a request body DecodeJSON could not decode. An unknown
+// field or trailing data is named in the message so the client can see what to fix.
+func InvalidBody(w http.ResponseWriter, err error) {
+	var unknownField *UnknownFieldError
+	if errors.As(err, &unknownField) || errors.Is(err, ErrTrailingJSON) {
+		Error(w, http.StatusBadRequest, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+	Error(w, http.StatusBadRequest, "Invalid request body", err)
+}
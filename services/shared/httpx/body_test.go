@@ -0,0 +1,180 @@
+package httpx
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONBody tests the request body size and content type middleware
+func TestJSONBody(t *testing.T) {
+	// 🎯 Test Strategy: bodies past the limit get 413 whether or not their length is
+	// declared, non-JSON bodies on writes get 415, and everything else reaches the handler
+
+	// 🔧 Setup
+	var received string
+	handler := JSONBody(BodyPolicy{MaxBytes: 16})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(body)
+		OK(w, "ok", nil)
+	}))
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		received = ""
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	t.Run("should refuse a body declared larger than the limit", func(t *testing.T) {
+		// 🚀 Action
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a long product name"}`))
+		r.Header.Set("Content-Type", "application/json")
+		rec := serve(r)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Empty(t, received)
+	})
+
+	t.Run("should refuse an oversized body sent without a length", func(t *testing.T) {
+		// 🚀 Action
+		r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"a long product name"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.ContentLength = -1
+		rec := serve(r)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Empty(t, received)
+	})
+
+	t.Run("should refuse a body that is not JSON", func(t *testing.T) {
+		for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded", "application/json-seq"} {
+			// 🚀 Action
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"a":1}`))
+			if contentType != "" {
+				r.Header.Set("Content-Type", contentType)
+			}
+			rec := serve(r)
+
+			// ✅ Assertions
+			assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code, contentType)
+			assert.Empty(t, received)
+		}
+	})
+
+	t.Run("should pass JSON bodies within the limit through", func(t *testing.T) {
+		// 🚀 Action
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := serve(r)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"a":1}`, received)
+	})
+
+	t.Run("should not require a content type without a body or on reads", func(t *testing.T) {
+		// 🚀 Action
+		empty := serve(httptest.NewRequest(http.MethodPost, "/", nil))
+		read := serve(httptest.NewRequest(http.MethodDelete, "/", strings.NewReader(`ids=1`)))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, empty.Code)
+		assert.Equal(t, http.StatusOK, read.Code)
+		assert.Equal(t, "ids=1", received)
+	})
+}
+
+// TestDecodeJSON tests decoding request bodies with and without unknown field checks
+func TestDecodeJSON(t *testing.T) {
+	// 🎯 Test Strategy: unknown fields are dropped by default and rejected when the
+	// JSONBody policy asks for it
+
+	type request struct {
+		Name string `json:"name"`
+	}
+	decode := func(policy BodyPolicy, body string) (request, error) {
+		var req request
+		var err error
+		handler := JSONBody(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err = DecodeJSON(r, &req)
+		}))
+
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		return req, err
+	}
+
+	t.Run("should ignore unknown fields by default", func(t *testing.T) {
+		// 🚀 Action
+		req, err := decode(BodyPolicy{}, `{"name":"chain","colour":"red"} {}`)
+
+		// ✅ Assertions
+		require.NoError(t, err)
+		assert.Equal(t, "chain", req.Name)
+	})
+
+	t.Run("should name an unknown field when configured to reject it", func(t *testing.T) {
+		// 🚀 Action
+		_, err := decode(BodyPolicy{DisallowUnknownFields: true}, `{"name":"chain","colour":"red"}`)
+
+		// ✅ Assertions
+		var unknownField *UnknownFieldError
+		require.ErrorAs(t, err, &unknownField)
+		assert.Equal(t, "colour", unknownField.Field)
+		assert.Equal(t, `unknown field "colour"`, err.Error())
+	})
+
+	t.Run("should reject a second value after the body when configured to", func(t *testing.T) {
+		// 🚀 Action
+		req, err := decode(BodyPolicy{DisallowUnknownFields: true}, "{\"name\":\"chain\"}\n{\"name\":\"gear\"}")
+		_, trailingErr := decode(BodyPolicy{DisallowUnknownFields: true}, "{\"name\":\"chain\"} }")
+		_, whitespaceErr := decode(BodyPolicy{DisallowUnknownFields: true}, "{\"name\":\"chain\"}\n")
+
+		// ✅ Assertions
+		assert.ErrorIs(t, err, ErrTrailingJSON)
+		assert.Equal(t, "chain", req.Name)
+		assert.ErrorIs(t, trailingErr, ErrTrailingJSON)
+		assert.NoError(t, whitespaceErr)
+	})
+}
+
+// TestInvalidBody tests the 400 written for undecodable request bodies
+func TestInvalidBody(t *testing.T) {
+	// 🎯 Test Strategy: strict decoding failures are named in the message, other decode
+	// errors keep the generic message with the error as detail
+
+	t.Run("should name the unknown field in the message", func(t *testing.T) {
+		// 🚀 Action
+		rec := httptest.NewRecorder()
+		InvalidBody(rec, &UnknownFieldError{Field: "quantites"})
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, `Invalid request body: unknown field "quantites"`, response.Message)
+	})
+
+	t.Run("should keep the generic message for malformed JSON", func(t *testing.T) {
+		// 🚀 Action
+		rec := httptest.NewRecorder()
+		InvalidBody(rec, io.ErrUnexpectedEOF)
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid request body", response.Message)
+		assert.Equal(t, map[string]interface{}{"message": "Invalid request body", "detail": "unexpected EOF"}, response.Error)
+	})
+}
@@ -0,0 +1,60 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// Built-in page size policy, used until SetPageSizeLimits is called.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	defaultPageSize atomic.Int64
+	maxPageSize     atomic.Int64
+)
+
+func init() {
+	defaultPageSize.Store(DefaultPageSize)
+	maxPageSize.Store(MaxPageSize)
+}
+
+// SetPageSizeLimits replaces the page size policy used by ClampPagination.
+// Non-positive values are ignored, and the default never exceeds the maximum.
+func SetPageSizeLimits(defaultSize, maxSize int) {
+	if maxSize > 0 {
+		maxPageSize.Store(int64(maxSize))
+	}
+	if defaultSize > 0 {
+		defaultPageSize.Store(int64(defaultSize))
+	}
+	if defaultPageSize.Load() > maxPageSize.Load() {
+		defaultPageSize.Store(maxPageSize.Load())
+	}
+}
+
+// ClampPagination applies the page size policy: pages start at 1, a missing
+// limit falls back to the default, and limits above the maximum are capped.
+func ClampPagination(page, limit int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = int(defaultPageSize.Load())
+	}
+	if max := int(maxPageSize.Load()); limit > max {
+		limit = max
+	}
+	return page, limit
+}
+
+// PaginationFromRequest reads the page and limit query parameters and clamps them.
+// Missing or malformed values fall back to the defaults.
+func PaginationFromRequest(r *http.Request) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	return ClampPagination(page, limit)
+}
@@ -0,0 +1,92 @@
+package httpx
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClampPagination tests the page size policy at its boundaries
+func TestClampPagination(t *testing.T) {
+	// 🎯 Test Strategy: Table-driven checks around zero, negative, default and maximum values
+
+	tests := []struct {
+		name          string
+		page, limit   int
+		expectedPage  int
+		expectedLimit int
+	}{
+		{"zero values use defaults", 0, 0, 1, DefaultPageSize},
+		{"negative values use defaults", -3, -10, 1, DefaultPageSize},
+		{"limit of one is kept", 2, 1, 2, 1},
+		{"limit at maximum is kept", 5, MaxPageSize, 5, MaxPageSize},
+		{"limit above maximum is capped", 1, MaxPageSize + 1, 1, MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 🚀 Execute
+			page, limit := ClampPagination(tt.page, tt.limit)
+
+			// ✅ Assertions
+			assert.Equal(t, tt.expectedPage, page)
+			assert.Equal(t, tt.expectedLimit, limit)
+		})
+	}
+}
+
+// TestSetPageSizeLimits tests overriding the page size policy
+func TestSetPageSizeLimits(t *testing.T) {
+	// 🔧 Setup: Restore the built-in policy after each case
+	t.Cleanup(func() { SetPageSizeLimits(DefaultPageSize, MaxPageSize) })
+
+	t.Run("should apply configured limits", func(t *testing.T) {
+		SetPageSizeLimits(10, 50)
+
+		_, limit := ClampPagination(1, 0)
+		assert.Equal(t, 10, limit)
+
+		_, limit = ClampPagination(1, 51)
+		assert.Equal(t, 50, limit)
+	})
+
+	t.Run("should keep default within maximum", func(t *testing.T) {
+		SetPageSizeLimits(80, 30)
+
+		_, limit := ClampPagination(1, 0)
+		assert.Equal(t, 30, limit)
+	})
+
+	t.Run("should ignore non-positive values", func(t *testing.T) {
+		SetPageSizeLimits(15, 60)
+		SetPageSizeLimits(0, -1)
+
+		_, limit := ClampPagination(1, 0)
+		assert.Equal(t, 15, limit)
+
+		_, limit = ClampPagination(1, 1000)
+		assert.Equal(t, 60, limit)
+	})
+}
+
+// TestPaginationFromRequest tests query parameter parsing
+func TestPaginationFromRequest(t *testing.T) {
+	t.Run("should parse and clamp query parameters", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/items?page=3&limit=1000", nil)
+
+		page, limit := PaginationFromRequest(req)
+
+		assert.Equal(t, 3, page)
+		assert.Equal(t, MaxPageSize, limit)
+	})
+
+	t.Run("should fall back to defaults for malformed values", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/items?page=abc&limit=", nil)
+
+		page, limit := PaginationFromRequest(req)
+
+		assert.Equal(t, 1, page)
+		assert.Equal(t, DefaultPageSize, limit)
+	})
+}
@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+)
+
+// Recover turns a panic in a downstream handler into a generic 500 response and logs
+// the panic value with its stack trace. Placed inside AccessLog, the log line carries
+// the request ID and the access log still records the 500.
+//
+// http.ErrAbortHandler is re-raised so net/http can abort the connection as intended.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			logger.FromContext(r.Context()).Error("panic recovered",
+				slog.String("panic", fmt.Sprint(p)),
+				slog.String("stack", string(debug.Stack())),
+			)
+			Error(w, http.StatusInternalServerError, "Internal server error", nil)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,115 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from server goroutines while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestRecover tests the panic recovery middleware
+func TestRecover(t *testing.T) {
+	// 🎯 Test Strategy: run a real server whose handler panics on one route and check
+	// the client gets a clean 500 while the server keeps serving other requests
+
+	// 🔧 Setup
+	var buf syncBuffer
+	log := logger.New(logger.Config{}, &buf)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"]++
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		OK(w, "fine", nil)
+	})
+
+	withLogger := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context(), log)))
+		})
+	}
+	server := httptest.NewServer(withLogger(middleware.RequestID(AccessLog(Recover(mux)))))
+	defer server.Close()
+
+	t.Run("should answer a panicking handler with a generic 500", func(t *testing.T) {
+		// 🚀 Action
+		resp, err := http.Get(server.URL + "/panic")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body APIResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.False(t, body.Success)
+		assert.Equal(t, "Internal server error", body.Message)
+		assert.Equal(t, map[string]interface{}{"message": "Internal server error"}, body.Error)
+	})
+
+	t.Run("should log the panic with its stack and request id", func(t *testing.T) {
+		// ✅ Assertions
+		var record map[string]any
+		line, _, _ := strings.Cut(buf.String(), "\n")
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		assert.Equal(t, "panic recovered", record["msg"])
+		assert.Contains(t, record["panic"], "nil map")
+		assert.Contains(t, record["stack"], "goroutine")
+		assert.NotEmpty(t, record["request_id"])
+	})
+
+	t.Run("should keep serving after a panic", func(t *testing.T) {
+		// 🚀 Action
+		resp, err := http.Get(server.URL + "/ok")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+// TestRecover_AbortHandler tests that http.ErrAbortHandler is passed through
+func TestRecover_AbortHandler(t *testing.T) {
+	// 🔧 Setup
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background())
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	// 🚀 Action & ✅ Assertions
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
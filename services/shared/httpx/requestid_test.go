@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jattinmanhas/GearboxV2/services/shared/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestID tests the request ID middleware
+func TestRequestID(t *testing.T) {
+	// 🎯 Test Strategy: serve requests through RequestID and AccessLog and compare the
+	// response header with the ID the handler saw and the ID that was logged
+
+	serve := func(header string) (*httptest.ResponseRecorder, string, map[string]any) {
+		var buf bytes.Buffer
+		ctx := logger.WithContext(context.Background(), logger.New(logger.Config{}, &buf))
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil).WithContext(ctx)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		rr := httptest.NewRecorder()
+
+		var handlerLine map[string]any
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.FromContext(r.Context()).Info("loading products")
+		})
+		RequestID(AccessLog(handler)).ServeHTTP(rr, req)
+
+		// The handler's line comes first, then the access log line
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerLine))
+		var accessLine map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &accessLine))
+		assert.Equal(t, handlerLine["request_id"], accessLine["request_id"])
+
+		requestID, _ := handlerLine["request_id"].(string)
+		return rr, requestID, accessLine
+	}
+
+	t.Run("should generate an ID and return it", func(t *testing.T) {
+		// 🚀 Action
+		rr, loggedID, accessLine := serve("")
+
+		// ✅ Assertions
+		assert.Regexp(t, `^[0-9a-f]{32}$`, rr.Header().Get(RequestIDHeader))
+		assert.Equal(t, rr.Header().Get(RequestIDHeader), loggedID)
+		assert.Equal(t, "GET", accessLine["method"])
+		assert.Equal(t, "/api/v1/products", accessLine["path"])
+		assert.Equal(t, float64(http.StatusOK), accessLine["status"])
+		assert.Contains(t, accessLine, "duration")
+	})
+
+	t.Run("should echo a provided ID", func(t *testing.T) {
+		// 🚀 Action
+		rr, loggedID, _ := serve("gateway-7f3a.1")
+
+		// ✅ Assertions
+		assert.Equal(t, "gateway-7f3a.1", rr.Header().Get(RequestIDHeader))
+		assert.Equal(t, "gateway-7f3a.1", loggedID)
+	})
+
+	t.Run("should replace a malformed ID", func(t *testing.T) {
+		// 🚀 Action: a newline would let the caller forge log lines in text format
+		rr, loggedID, _ := serve("abc\nlevel=ERROR")
+
+		// ✅ Assertions
+		assert.Regexp(t, `^[0-9a-f]{32}$`, rr.Header().Get(RequestIDHeader))
+		assert.Equal(t, rr.Header().Get(RequestIDHeader), loggedID)
+	})
+
+	t.Run("should replace an overlong ID", func(t *testing.T) {
+		// 🚀 Action
+		rr, _, _ := serve(strings.Repeat("a", maxRequestIDLength+1))
+
+		// ✅ Assertions
+		assert.Len(t, rr.Header().Get(RequestIDHeader), 32)
+	})
+}
@@ -43,3 +43,35 @@ func Error(w http.ResponseWriter, status int, message string, err error) {
 	}
 	WriteJSON(w, status, false, message, nil, payload)
 }
+
+// FieldError describes one invalid request field. Field is the name the client sent,
+// so it can be shown next to the matching form input.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Tag     string `json:"tag"`
+}
+
+// ValidationError writes a 422 whose error payload lists every invalid field under
+// "fields", alongside the summary message.
+func ValidationError(w http.ResponseWriter, message string, fields []FieldError) {
+	payload := map[string]interface{}{
+		"message": message,
+		"fields":  NonNilSlice(fields),
+	}
+	WriteJSON(w, http.StatusUnprocessableEntity, false, message, nil, payload)
+}
+
+// OKList writes a 200 response for a list endpoint. Nil slices are sent as an
+// empty JSON array so clients never receive null where they expect a list.
+func OKList[T any](w http.ResponseWriter, message string, items []T) {
+	OK(w, message, NonNilSlice(items))
+}
+
+// NonNilSlice returns items, or an empty slice when items is nil.
+func NonNilSlice[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -172,6 +172,50 @@ func TestOK(t *testing.T) {
 	})
 }
 
+// TestOKList tests the OKList helper function
+func TestOKList(t *testing.T) {
+	// 🎯 Test Strategy: List data must always serialize as a JSON array
+
+	t.Run("should write nil slice as empty array", func(t *testing.T) {
+		// 🔧 Setup: Create response recorder and a nil slice
+		rr := httptest.NewRecorder()
+		var items []string
+
+		// 🚀 Action: Write list response
+		OKList(rr, "Items retrieved", items)
+
+		// ✅ Assertions: Data should be [] not null
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var raw map[string]json.RawMessage
+		err := json.Unmarshal(rr.Body.Bytes(), &raw)
+		require.NoError(t, err)
+		assert.Equal(t, "[]", string(raw["data"]))
+	})
+
+	t.Run("should write populated slice unchanged", func(t *testing.T) {
+		// 🔧 Setup: Create response recorder
+		rr := httptest.NewRecorder()
+
+		// 🚀 Action: Write list response
+		OKList(rr, "Items retrieved", []int{1, 2})
+
+		// ✅ Assertions: Data should contain the items
+		var raw map[string]json.RawMessage
+		err := json.Unmarshal(rr.Body.Bytes(), &raw)
+		require.NoError(t, err)
+		assert.Equal(t, "[1,2]", string(raw["data"]))
+	})
+}
+
+// TestNonNilSlice tests the NonNilSlice helper function
+func TestNonNilSlice(t *testing.T) {
+	// ✅ Assertions: nil becomes empty, non-nil is returned as-is
+	assert.NotNil(t, NonNilSlice[string](nil))
+	assert.Empty(t, NonNilSlice[string](nil))
+	assert.Equal(t, []string{"a"}, NonNilSlice([]string{"a"}))
+}
+
 // TestCreated tests the Created helper function
 func TestCreated(t *testing.T) {
 	// 🎯 Test Strategy: Test the Created helper function
@@ -298,6 +342,51 @@ func TestError(t *testing.T) {
 	})
 }
 
+// TestValidationError tests the ValidationError helper function
+func TestValidationError(t *testing.T) {
+	// 🎯 Test Strategy: Decode the 422 body and check each field error
+
+	t.Run("should list each invalid field", func(t *testing.T) {
+		// 🔧 Setup: Create response recorder
+		rr := httptest.NewRecorder()
+
+		// 🚀 Action: Write validation error response
+		ValidationError(rr, "name is required", []FieldError{
+			{Field: "name", Message: "name is required", Tag: "required"},
+		})
+
+		// ✅ Assertions: Verify status and field list
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var response struct {
+			Status  int    `json:"status"`
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+			Error   struct {
+				Message string       `json:"message"`
+				Fields  []FieldError `json:"fields"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Status)
+		assert.False(t, response.Success)
+		assert.Equal(t, "name is required", response.Error.Message)
+		assert.Equal(t, []FieldError{{Field: "name", Message: "name is required", Tag: "required"}}, response.Error.Fields)
+	})
+
+	t.Run("should send an empty list rather than null", func(t *testing.T) {
+		// 🔧 Setup: Create response recorder
+		rr := httptest.NewRecorder()
+
+		// 🚀 Action: Write validation error response without fields
+		ValidationError(rr, "invalid request", nil)
+
+		// ✅ Assertions: fields is an empty array
+		assert.Contains(t, rr.Body.String(), `"fields":[]`)
+	})
+}
+
 // TestResponseHeaders tests response headers
 func TestResponseHeaders(t *testing.T) {
 	// 🎯 Test Strategy: Test that proper headers are set
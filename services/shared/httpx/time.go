@@ -0,0 +1,8 @@
+package httpx
+
+import "time"
+
+// FormatTime renders t as RFC3339 in UTC so responses never leak the server's local zone.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormatTime tests the FormatTime helper
+func TestFormatTime(t *testing.T) {
+	// 🎯 Test Strategy: Verify timestamps are always rendered as UTC RFC3339
+
+	t.Run("should convert non-UTC time to UTC", func(t *testing.T) {
+		// 🔧 Setup: A time in a +05:30 offset zone
+		zone := time.FixedZone("IST", 5*60*60+30*60)
+		local := time.Date(2025, 3, 10, 15, 30, 0, 0, zone)
+
+		// 🚀 Execute
+		formatted := FormatTime(local)
+
+		// ✅ Assertions: Same instant, emitted with the Z suffix
+		assert.Equal(t, "2025-03-10T10:00:00Z", formatted)
+	})
+
+	t.Run("should keep UTC time unchanged", func(t *testing.T) {
+		// 🔧 Setup
+		utc := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+
+		// 🚀 Execute & ✅ Assert
+		assert.Equal(t, "2025-03-10T10:00:00Z", FormatTime(utc))
+	})
+}
@@ -0,0 +1,173 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CheckTimeout bounds each readiness check so a hung dependency fails the probe instead
+// of stalling it
+const CheckTimeout = 2 * time.Second
+
+// Check reports whether a dependency, such as the database, is usable
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness tracks whether a service should receive new traffic
+type Readiness struct {
+	ready  atomic.Bool
+	checks []namedCheck
+}
+
+// NewReadiness returns a Readiness that starts out ready
+func NewReadiness() *Readiness {
+	r := &Readiness{}
+	r.ready.Store(true)
+	return r
+}
+
+// SetReady flips the readiness state
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+// IsReady reports whether the service is ready
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+// AddCheck adds a check that must pass for /readyz to report ready. Checks are added
+// while wiring the service, before it starts serving.
+func (r *Readiness) AddCheck(name string, check Check) {
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// readinessStatus is the /readyz response body
+type readinessStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Handler serves /readyz: 200 while ready and every check passes, 503 once shutdown has
+// begun or while a check fails. Each check reports "ok" or its error.
+func (r *Readiness) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !r.IsReady() {
+			writeStatus(w, http.StatusServiceUnavailable, readinessStatus{Status: "shutting_down"})
+			return
+		}
+
+		status := readinessStatus{Status: "ready"}
+		if len(r.checks) > 0 {
+			status.Checks = make(map[string]string, len(r.checks))
+		}
+		for _, c := range r.checks {
+			ctx, cancel := context.WithTimeout(req.Context(), CheckTimeout)
+			err := c.check(ctx)
+			cancel()
+
+			if err != nil {
+				status.Status = "not_ready"
+				status.Checks[c.name] = err.Error()
+				continue
+			}
+			status.Checks[c.name] = "ok"
+		}
+
+		code := http.StatusOK
+		if status.Status != "ready" {
+			code = http.StatusServiceUnavailable
+		}
+		writeStatus(w, code, status)
+	}
+}
+
+// LivenessHandler serves /healthz: 200 for as long as the process can serve requests
+func LivenessHandler(w http.ResponseWriter, _ *http.Request) {
+	writeStatus(w, http.StatusOK, readinessStatus{Status: "alive"})
+}
+
+func writeStatus(w http.ResponseWriter, code int, status readinessStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
+
+// Server is the part of *http.Server the coordinator needs
+type Server interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Coordinator runs the shutdown sequence: mark not ready, stop workers, then drain the server
+type Coordinator struct {
+	server     Server
+	readiness  *Readiness
+	drainDelay time.Duration
+
+	workerCtx    context.Context
+	stopWorkers  context.CancelFunc
+	workers      sync.WaitGroup
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// NewCoordinator creates a coordinator for server. drainDelay is how long to keep serving
+// after /readyz starts failing so load balancers can take the instance out of rotation.
+func NewCoordinator(server Server, readiness *Readiness, drainDelay time.Duration) *Coordinator {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	return &Coordinator{
+		server:      server,
+		readiness:   readiness,
+		drainDelay:  drainDelay,
+		workerCtx:   workerCtx,
+		stopWorkers: stopWorkers,
+	}
+}
+
+// Go runs a background worker whose context is cancelled during shutdown
+func (c *Coordinator) Go(worker func(ctx context.Context)) {
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		worker(c.workerCtx)
+	}()
+}
+
+// Shutdown flips readiness, stops background workers and then shuts the server down.
+// It is safe to call more than once; later calls return the first result.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.shutdownOnce.Do(func() {
+		c.readiness.SetReady(false)
+
+		if c.drainDelay > 0 {
+			select {
+			case <-time.After(c.drainDelay):
+			case <-ctx.Done():
+			}
+		}
+
+		c.stopWorkers()
+
+		done := make(chan struct{})
+		go func() {
+			c.workers.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+
+		c.shutdownErr = c.server.Shutdown(ctx)
+	})
+
+	return c.shutdownErr
+}
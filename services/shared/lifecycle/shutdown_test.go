@@ -0,0 +1,180 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer records the order of shutdown events
+type recordingServer struct {
+	mu     sync.Mutex
+	events *[]string
+}
+
+func (s *recordingServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.events = append(*s.events, "server shutdown")
+	return nil
+}
+
+// TestReadiness tests the readiness flag and handler
+func TestReadiness(t *testing.T) {
+	// 🎯 Test Strategy: /readyz reports 200 while ready and 503 once flipped
+
+	t.Run("should report ready by default", func(t *testing.T) {
+		// 🔧 Setup
+		readiness := NewReadiness()
+		w := httptest.NewRecorder()
+
+		// 🚀 Action
+		readiness.Handler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "ready")
+	})
+
+	t.Run("should report 503 when not ready", func(t *testing.T) {
+		// 🔧 Setup
+		readiness := NewReadiness()
+		readiness.SetReady(false)
+		w := httptest.NewRecorder()
+
+		// 🚀 Action
+		readiness.Handler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("should report each check", func(t *testing.T) {
+		// 🔧 Setup: The database answers but the schema is behind
+		readiness := NewReadiness()
+		readiness.AddCheck("database", func(ctx context.Context) error { return nil })
+		readiness.AddCheck("migrations", func(ctx context.Context) error {
+			return errors.New("schema at version 3, expected 4")
+		})
+		w := httptest.NewRecorder()
+
+		// 🚀 Action
+		readiness.Handler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"not_ready","checks":{"database":"ok","migrations":"schema at version 3, expected 4"}}`, w.Body.String())
+	})
+
+	t.Run("should report ready once every check passes", func(t *testing.T) {
+		// 🔧 Setup
+		readiness := NewReadiness()
+		readiness.AddCheck("database", func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			require.True(t, hasDeadline)
+			return nil
+		})
+		w := httptest.NewRecorder()
+
+		// 🚀 Action
+		readiness.Handler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// ✅ Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ready","checks":{"database":"ok"}}`, w.Body.String())
+	})
+}
+
+// TestLivenessHandler tests the /healthz handler
+func TestLivenessHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	LivenessHandler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"alive"}`, w.Body.String())
+}
+
+// TestCoordinator_Shutdown tests the shutdown ordering
+func TestCoordinator_Shutdown(t *testing.T) {
+	// 🎯 Test Strategy: readiness flips first, workers stop next, the server shuts down last
+
+	t.Run("should signal workers before shutting down the server", func(t *testing.T) {
+		// 🔧 Setup
+		var mu sync.Mutex
+		var events []string
+		record := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+
+		readiness := NewReadiness()
+		server := &recordingServer{events: &events}
+		coordinator := NewCoordinator(server, readiness, 0)
+
+		started := make(chan struct{})
+		coordinator.Go(func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			if !readiness.IsReady() {
+				record("worker stopped after readiness flip")
+			}
+		})
+		<-started
+
+		// 🚀 Action
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := coordinator.Shutdown(ctx)
+
+		// ✅ Assertions
+		require.NoError(t, err)
+		assert.False(t, readiness.IsReady())
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"worker stopped after readiness flip", "server shutdown"}, events)
+	})
+
+	t.Run("should only shut down once", func(t *testing.T) {
+		// 🔧 Setup
+		var events []string
+		server := &recordingServer{events: &events}
+		coordinator := NewCoordinator(server, NewReadiness(), 0)
+
+		// 🚀 Action
+		require.NoError(t, coordinator.Shutdown(context.Background()))
+		require.NoError(t, coordinator.Shutdown(context.Background()))
+
+		// ✅ Assertions
+		assert.Equal(t, []string{"server shutdown"}, events)
+	})
+
+	t.Run("should not wait past the context deadline for stuck workers", func(t *testing.T) {
+		// 🔧 Setup
+		var events []string
+		server := &recordingServer{events: &events}
+		coordinator := NewCoordinator(server, NewReadiness(), 0)
+
+		block := make(chan struct{})
+		defer close(block)
+		coordinator.Go(func(ctx context.Context) {
+			<-block
+		})
+
+		// 🚀 Action
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = coordinator.Shutdown(ctx)
+
+		// ✅ Assertions
+		assert.Equal(t, []string{"server shutdown"}, events)
+	})
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLevel tests level name parsing
+func TestParseLevel(t *testing.T) {
+	// 🎯 Test Strategy: known names map to their level, anything else is info
+
+	tests := []struct {
+		input    string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{" error ", slog.LevelError},
+		{"", slog.LevelInfo},
+		{"verbose", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			// ✅ Assertions
+			assert.Equal(t, tt.expected, ParseLevel(tt.input))
+		})
+	}
+}
+
+// TestNew tests logger construction
+func TestNew(t *testing.T) {
+	// 🎯 Test Strategy: JSON output carries level, message and fields; records below the level are dropped
+
+	t.Run("should write JSON records with fields", func(t *testing.T) {
+		// 🔧 Setup
+		var buf bytes.Buffer
+		l := New(Config{Level: "info", Format: "json"}, &buf)
+
+		// 🚀 Action
+		l.Warn("failed to delete expired cart", slog.Int64("cart_id", 7))
+
+		// ✅ Assertions
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "failed to delete expired cart", record["msg"])
+		assert.Equal(t, float64(7), record["cart_id"])
+	})
+
+	t.Run("should drop records below the configured level", func(t *testing.T) {
+		// 🔧 Setup
+		var buf bytes.Buffer
+		l := New(Config{Level: "warn"}, &buf)
+
+		// 🚀 Action
+		l.Info("ignored")
+		l.Debug("ignored")
+
+		// ✅ Assertions
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("should write text records when requested", func(t *testing.T) {
+		// 🔧 Setup
+		var buf bytes.Buffer
+		l := New(Config{Format: "text"}, &buf)
+
+		// 🚀 Action
+		l.Info("started", "port", "8080")
+
+		// ✅ Assertions
+		assert.Contains(t, buf.String(), "level=INFO")
+		assert.Contains(t, buf.String(), "port=8080")
+	})
+}
+
+// TestContext tests carrying a logger in a context
+func TestContext(t *testing.T) {
+	// 🎯 Test Strategy: FromContext returns the stored logger and falls back to the default
+
+	t.Run("should return the logger stored in the context", func(t *testing.T) {
+		// 🔧 Setup
+		l := New(Config{}, &bytes.Buffer{})
+		ctx := WithContext(context.Background(), l)
+
+		// ✅ Assertions
+		assert.Same(t, l, FromContext(ctx))
+	})
+
+	t.Run("should fall back to the default logger", func(t *testing.T) {
+		// ✅ Assertions
+		assert.Same(t, slog.Default(), FromContext(context.Background()))
+	})
+}
@@ -0,0 +1,70 @@
+// Package userctx carries the authenticated user's ID and role through a request
+// context so that code below the handlers can attribute writes and tailor reads to the
+// caller without threading them by hand.
+package userctx
+
+import "context"
+
+// Roles that auth-service assigns to users
+const (
+	RoleUser   = "user"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+type contextKey struct{}
+
+type roleKey struct{}
+
+type trackerKey struct{}
+
+type tracker struct {
+	userID int64
+	ok     bool
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user's ID
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	if t, ok := ctx.Value(trackerKey{}).(*tracker); ok {
+		t.userID, t.ok = userID, true
+	}
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// Track returns a copy of ctx in which a later WithUserID is also recorded, and a
+// func that reports it. Middleware wrapping authentication uses it to learn who made
+// a request, since values added further down the chain are otherwise invisible to it.
+func Track(ctx context.Context) (context.Context, func() (int64, bool)) {
+	t := &tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), func() (int64, bool) {
+		return t.userID, t.ok
+	}
+}
+
+// UserID returns the authenticated user's ID. ok is false for unauthenticated
+// requests and for system or background work.
+func UserID(ctx context.Context) (userID int64, ok bool) {
+	userID, ok = ctx.Value(contextKey{}).(int64)
+	return userID, ok
+}
+
+// UserIDPtr returns the authenticated user's ID, or nil when there is none. It suits
+// nullable created_by/updated_by columns.
+func UserIDPtr(ctx context.Context) *int64 {
+	if userID, ok := UserID(ctx); ok {
+		return &userID
+	}
+	return nil
+}
+
+// WithRole returns a copy of ctx carrying the authenticated user's role
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// Role returns the authenticated user's role, or "" for unauthenticated requests and
+// for system or background work
+func Role(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
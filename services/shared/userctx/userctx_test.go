@@ -0,0 +1,82 @@
+package userctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserID tests carrying a user ID in a context
+func TestUserID(t *testing.T) {
+	// 🎯 Test Strategy: the stored ID comes back; a context without one reports none
+
+	t.Run("should return the user ID stored in the context", func(t *testing.T) {
+		// 🔧 Setup
+		ctx := WithUserID(context.Background(), 42)
+
+		// 🚀 Action
+		userID, ok := UserID(ctx)
+
+		// ✅ Assertions
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), userID)
+		require.NotNil(t, UserIDPtr(ctx))
+		assert.Equal(t, int64(42), *UserIDPtr(ctx))
+	})
+
+	t.Run("should report no user for system work", func(t *testing.T) {
+		// 🚀 Action
+		_, ok := UserID(context.Background())
+
+		// ✅ Assertions
+		assert.False(t, ok)
+		assert.Nil(t, UserIDPtr(context.Background()))
+	})
+}
+
+// TestTrack tests reading a user ID set further down the chain
+func TestTrack(t *testing.T) {
+	// 🎯 Test Strategy: a WithUserID on a derived context is visible through the tracker
+
+	t.Run("should report the user ID set on a derived context", func(t *testing.T) {
+		// 🔧 Setup
+		ctx, trackedUser := Track(context.Background())
+
+		// 🚀 Action
+		_ = WithUserID(ctx, 42)
+
+		// ✅ Assertions
+		userID, ok := trackedUser()
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), userID)
+	})
+
+	t.Run("should report no user when none is set", func(t *testing.T) {
+		// 🔧 Setup
+		_, trackedUser := Track(context.Background())
+
+		// ✅ Assertions
+		_, ok := trackedUser()
+		assert.False(t, ok)
+	})
+}
+
+// TestRole tests carrying a role in a context
+func TestRole(t *testing.T) {
+	// 🎯 Test Strategy: the stored role comes back; a context without one reports none
+
+	t.Run("should return the role stored in the context", func(t *testing.T) {
+		// 🔧 Setup
+		ctx := WithRole(context.Background(), RoleAdmin)
+
+		// ✅ Assertions
+		assert.Equal(t, RoleAdmin, Role(ctx))
+	})
+
+	t.Run("should report no role for unauthenticated requests", func(t *testing.T) {
+		// ✅ Assertions
+		assert.Empty(t, Role(context.Background()))
+	})
+}